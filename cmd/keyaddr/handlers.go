@@ -11,6 +11,7 @@ package main
 
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"regexp"
@@ -72,6 +73,60 @@ func newKey(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// JS Usage: newSeed(strength, cb)
+func newSeed(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("newSeed")
+
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "newSeed")
+		if err != nil {
+			return
+		}
+
+		strength := remainder[0].Int()
+
+		// do work
+		seed, err := keyaddr.NewSeed(strength)
+		if err != nil {
+			jsLogReject(callback, "error creating new seed: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, seed)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: newEdKey(recoveryBytes, cb)
+func newEdKey(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("newEdKey")
+
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "newEdKey")
+		if err != nil {
+			return
+		}
+
+		recoveryBytes := remainder[0].String()
+
+		// do work
+		key, err := keyaddr.NewEdKey(recoveryBytes)
+		if err != nil {
+			jsLogReject(callback, "error creating new ed25519 key: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, key.Key)
+		return
+	}(args)
+	return nil
+}
+
 // JS Usage: wordsToBytes(language, words, cb)
 // language defaults to en if not specified.
 func wordsToBytes(this js.Value, args []js.Value) interface{} {
@@ -164,6 +219,39 @@ func wordsFromPrefix(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// JS Usage: validatePartialMnemonic(lang, wordsSoFar, cb)
+//
+// Resolves to {ok, expectedRemaining}; rejects if the words can't begin a
+// valid mnemonic.
+func validatePartialMnemonic(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("validatePartialMnemonic")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "validatePartialMnemonic")
+		if err != nil {
+			return
+		}
+
+		lang := remainder[0].String()
+		wordsSoFar := remainder[1].String()
+
+		// do work
+		ok, remaining, err := keyaddr.ValidatePartialMnemonic(lang, wordsSoFar)
+		if err != nil {
+			jsLogReject(callback, "error validating mnemonic: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, map[string]interface{}{
+			"ok":                ok,
+			"expectedRemaining": remaining,
+		})
+		return
+	}(args)
+	return nil
+}
+
 // JS Usage: isPrivate(key, cb)
 func isPrivate(this js.Value, args []js.Value) interface{} {
 	go func(args []js.Value) {
@@ -193,6 +281,45 @@ func isPrivate(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// JS Usage: keyDetails(key, cb)
+//
+// The result is an object:
+// {depth, childIndex, hardened, parentFingerprint, algorithm, isPrivate, address}
+func keyDetails(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("keyDetails")
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "keyDetails")
+		if err != nil {
+			return
+		}
+
+		k := &keyaddr.Key{
+			Key: remainder[0].String(),
+		}
+
+		// do work
+		details, err := k.Details()
+		if err != nil {
+			jsLogReject(callback, "error getting key details: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, map[string]interface{}{
+			"depth":             details.Depth,
+			"childIndex":        details.ChildIndex,
+			"hardened":          details.Hardened,
+			"parentFingerprint": details.ParentFingerprint,
+			"algorithm":         details.Algorithm,
+			"isPrivate":         details.IsPrivate,
+			"address":           details.Address,
+		})
+		return
+	}(args)
+	return nil
+}
+
 // constructs a key from a string. Possibly to check for validity?
 // JS Usage: fromString(key, cb)
 func fromString(this js.Value, args []js.Value) interface{} {
@@ -225,6 +352,39 @@ func fromString(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// constructs a key from a string in the old key serialization format.
+// The key is returned in the new format.
+// JS Usage: fromOldString(key, cb)
+func fromOldString(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("fromOldString")
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "fromOldString")
+		if err != nil {
+			return
+		}
+
+		str := remainder[0].String()
+
+		// do work
+		key, err := keyaddr.FromOldString(str)
+		if err != nil {
+			jsLogReject(callback, "error constructing a key from an old string: %s", err)
+			return
+		}
+
+		// Make an map[string]interface{} so syscall will turn it into a js object.
+		obj := make(map[string]interface{})
+		obj["key"] = key.Key
+
+		// return result
+		callback.Invoke(nil, js.ValueOf(obj))
+
+		return
+	}(args)
+	return nil
+}
+
 // JS Usage: wordsFromBytes(lang, base64bytes, cb)
 func wordsFromBytes(this js.Value, args []js.Value) interface{} {
 	go func(args []js.Value) {
@@ -281,6 +441,65 @@ func ndauAddress(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// JS Usage: addressOfKind(key, kindName, cb)
+func addressOfKind(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("addressOfKind")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "addressOfKind")
+		if err != nil {
+			return
+		}
+
+		k := &keyaddr.Key{
+			Key: remainder[0].String(),
+		}
+		kindName := remainder[1].String()
+
+		// do work
+		addr, err := k.AddressOfKind(kindName)
+		if err != nil {
+			jsLogReject(callback, "error getting address of kind %s: %s", kindName, err)
+			return
+		}
+		// return result
+		callback.Invoke(nil, addr.Address)
+
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: addressOfKindOn(key, kindName, chainID, cb)
+func addressOfKindOn(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("addressOfKindOn")
+		// clean args
+		callback, remainder, err := handleArgs(args, 3, "addressOfKindOn")
+		if err != nil {
+			return
+		}
+
+		k := &keyaddr.Key{
+			Key: remainder[0].String(),
+		}
+		kindName := remainder[1].String()
+		chainID := remainder[2].String()
+
+		// do work
+		addr, err := k.AddressOfKindOn(kindName, chainID)
+		if err != nil {
+			jsLogReject(callback, "error getting address of kind %s on %s: %s", kindName, chainID, err)
+			return
+		}
+		// return result
+		callback.Invoke(nil, addr.Address)
+
+		return
+	}(args)
+	return nil
+}
+
 // JS Usage: toPublic(privateKey, cb)
 func toPublic(this js.Value, args []js.Value) interface{} {
 	go func(args []js.Value) {
@@ -372,7 +591,7 @@ func sign(this js.Value, args []js.Value) interface{} {
 		// do work
 		sig, err := k.Sign(msg)
 		if err != nil {
-			logError(fmt.Sprintf("error creating signature: key length: %s, msg: %s, err: %s", len(k.Key), msg, err.Error()))
+			logError(fmt.Sprintf("error creating signature: key length: %d, msg: %s, err: %s", len(k.Key), msg, err.Error()))
 			jsLogReject(callback, "error creating signature: %s", err)
 			return
 		}
@@ -384,6 +603,253 @@ func sign(this js.Value, args []js.Value) interface{} {
 	return nil
 }
 
+// JS Usage: signWith(privateKey, algorithm, encoding, payload, cb)
+//
+// The result is an object: {signature, algorithm}
+func signWith(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("signWith")
+		// clean args
+		callback, remainder, err := handleArgs(args, 4, "signWith")
+		if err != nil {
+			return
+		}
+
+		k := keyaddr.Key{
+			Key: remainder[0].String(),
+		}
+		alg := remainder[1].String()
+		encoding := remainder[2].String()
+		payload := remainder[3].String()
+
+		// do work
+		sig, err := k.SignWith(alg, encoding, payload)
+		if err != nil {
+			jsLogReject(callback, "error creating signature: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, map[string]interface{}{
+			"signature": sig.Signature,
+			"algorithm": sig.Algorithm,
+		})
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: signBatch(privateKey, messages, cb)
+// messages is a JSON array of base64-encoded messages.
+//
+// The result is an array of signatures, in the order of the messages.
+func signBatch(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("signBatch")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "signBatch")
+		if err != nil {
+			return
+		}
+
+		key := remainder[0].String()
+		msgs := remainder[1].String()
+
+		// do work
+		sigsJSON, err := keyaddr.SignBatchJSON(key, msgs)
+		if err != nil {
+			jsLogReject(callback, "error creating signatures: %s", err)
+			return
+		}
+		var sigs []string
+		err = json.Unmarshal([]byte(sigsJSON), &sigs)
+		if err != nil {
+			jsLogReject(callback, "error decoding signatures: %s", err)
+			return
+		}
+
+		// return result
+		out := make([]interface{}, 0, len(sigs))
+		for _, sig := range sigs {
+			out = append(out, sig)
+		}
+		callback.Invoke(nil, out)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: signOwnershipChallenge(privateKey, challenge, cb)
+//
+// The result is an object: {signature, algorithm}
+func signOwnershipChallenge(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("signOwnershipChallenge")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "signOwnershipChallenge")
+		if err != nil {
+			return
+		}
+
+		key := remainder[0].String()
+		challenge := remainder[1].String()
+
+		// do work
+		sig, err := keyaddr.SignOwnershipChallenge(key, challenge)
+		if err != nil {
+			jsLogReject(callback, "error signing ownership challenge: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, map[string]interface{}{
+			"signature": sig.Signature,
+			"algorithm": sig.Algorithm,
+		})
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: verifyOwnershipChallenge(address, challenge, signature, publicKey, cb)
+func verifyOwnershipChallenge(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("verifyOwnershipChallenge")
+		// clean args
+		callback, remainder, err := handleArgs(args, 4, "verifyOwnershipChallenge")
+		if err != nil {
+			return
+		}
+
+		addr := remainder[0].String()
+		challenge := remainder[1].String()
+		sig := remainder[2].String()
+		pubkey := remainder[3].String()
+
+		// do work
+		ok, err := keyaddr.VerifyOwnershipChallenge(addr, challenge, sig, pubkey)
+		if err != nil {
+			jsLogReject(callback, "error verifying ownership challenge: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, ok)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: signRecoverable(privateKey, base64Message, cb)
+//
+// The result is the base64 encoding of a signature from which the signer's
+// public key can be recovered.
+func signRecoverable(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("signRecoverable")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "signRecoverable")
+		if err != nil {
+			return
+		}
+
+		k := keyaddr.Key{Key: remainder[0].String()}
+		msg := remainder[1].String()
+
+		// do work
+		sig, err := k.SignRecoverable(msg)
+		if err != nil {
+			jsLogReject(callback, "error creating recoverable signature: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, sig)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: recoverPublic(base64Message, base64Signature, cb)
+func recoverPublic(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("recoverPublic")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "recoverPublic")
+		if err != nil {
+			return
+		}
+
+		msg := remainder[0].String()
+		sig := remainder[1].String()
+
+		// do work
+		pub, err := keyaddr.RecoverPublic(msg, sig)
+		if err != nil {
+			jsLogReject(callback, "error recovering public key: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, pub)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: normalizeAddress(address, cb)
+func normalizeAddress(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("normalizeAddress")
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "normalizeAddress")
+		if err != nil {
+			return
+		}
+
+		addr := remainder[0].String()
+
+		// do work
+		normalized, err := keyaddr.NormalizeAddress(addr)
+		if err != nil {
+			jsLogReject(callback, "error normalizing address: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, normalized)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: recoverAddress(base64Message, base64Signature, cb)
+func recoverAddress(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("recoverAddress")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "recoverAddress")
+		if err != nil {
+			return
+		}
+
+		msg := remainder[0].String()
+		sig := remainder[1].String()
+
+		// do work
+		addr, err := keyaddr.RecoverAddress(msg, sig)
+		if err != nil {
+			jsLogReject(callback, "error recovering address: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, addr.Address)
+		return
+	}(args)
+	return nil
+}
+
 // JS Usage: hardenedChild(privateKey, n, cb)
 func hardenedChild(this js.Value, args []js.Value) interface{} {
 	go func(args []js.Value) {
@@ -424,3 +890,72 @@ func hardenedChild(this js.Value, args []js.Value) interface{} {
 	}(args)
 	return nil
 }
+
+// JS Usage: exportWallet(rootPrivateKey, accounts, cb)
+// accounts is a JSON array of objects like
+// {"Name": "savings", "Path": "/44'/20036'/100/1", "IncludePrivate": false}.
+func exportWallet(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("exportWallet")
+		// clean args
+		callback, remainder, err := handleArgs(args, 2, "exportWallet")
+		if err != nil {
+			return
+		}
+
+		rootKey := remainder[0].String()
+		var accounts []keyaddr.AccountSpec
+		err = json.Unmarshal([]byte(remainder[1].String()), &accounts)
+		if err != nil {
+			jsLogReject(callback, "error decoding accounts: %s", err)
+			return
+		}
+
+		// do work
+		doc, err := keyaddr.ExportWallet(rootKey, accounts)
+		if err != nil {
+			jsLogReject(callback, "error exporting wallet: %s", err)
+			return
+		}
+
+		// return result
+		callback.Invoke(nil, doc)
+		return
+	}(args)
+	return nil
+}
+
+// JS Usage: importWallet(doc, cb)
+// The result is an array of the wallet's accounts.
+func importWallet(this js.Value, args []js.Value) interface{} {
+	go func(args []js.Value) {
+		logDebug("importWallet")
+		// clean args
+		callback, remainder, err := handleArgs(args, 1, "importWallet")
+		if err != nil {
+			return
+		}
+
+		// do work
+		wallet, err := keyaddr.ImportWallet(remainder[0].String())
+		if err != nil {
+			jsLogReject(callback, "error importing wallet: %s", err)
+			return
+		}
+
+		// return result
+		accounts := make([]interface{}, 0, len(wallet.Accounts))
+		for _, account := range wallet.Accounts {
+			accounts = append(accounts, map[string]interface{}{
+				"name":    account.Name,
+				"path":    account.Path,
+				"public":  account.Public,
+				"address": account.Address,
+				"private": account.Private,
+			})
+		}
+		callback.Invoke(nil, accounts)
+		return
+	}(args)
+	return nil
+}
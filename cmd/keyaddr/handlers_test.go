@@ -0,0 +1,351 @@
+package main
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/json"
+	"strings"
+	"syscall/js"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/keyaddr"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests run only under js/wasm; the README says how to run them.
+
+// handlerCase is a call of a handler, and of the Go function it wraps,
+// with the same inputs
+type handlerCase struct {
+	name    string
+	handler func(js.Value, []js.Value) interface{}
+	args    []interface{}
+	// goFn returns the result of the Go function in the form the handler
+	// passes to its callback
+	goFn func() (interface{}, error)
+}
+
+// invoke calls a handler and waits for the error and result it passes to
+// its callback
+func invoke(handler func(js.Value, []js.Value) interface{}, args ...interface{}) (js.Value, js.Value) {
+	type outcome struct{ err, result js.Value }
+	done := make(chan outcome, 1)
+	cb := js.FuncOf(func(this js.Value, cbArgs []js.Value) interface{} {
+		done <- outcome{cbArgs[0], cbArgs[1]}
+		return nil
+	})
+	defer cb.Release()
+
+	jsArgs := make([]js.Value, 0, len(args)+1)
+	for _, arg := range args {
+		jsArgs = append(jsArgs, js.ValueOf(arg))
+	}
+	handler(js.Undefined(), append(jsArgs, cb.Value))
+	o := <-done
+	return o.err, o.result
+}
+
+// decoded returns a value as it decodes from its JSON, so that JS and Go
+// values can be compared
+func decoded(t *testing.T, data string) interface{} {
+	var out interface{}
+	require.NoError(t, json.Unmarshal([]byte(data), &out))
+	return out
+}
+
+func jsJSON(v js.Value) string {
+	return js.Global().Get("JSON").Call("stringify", v).String()
+}
+
+func goJSON(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func must(t *testing.T) func(*keyaddr.Key, error) string {
+	return func(k *keyaddr.Key, err error) string {
+		require.NoError(t, err)
+		return k.Key
+	}
+}
+
+func keyOf(s string) *keyaddr.Key {
+	return &keyaddr.Key{Key: s}
+}
+
+// TestHandlersMatchGo checks that each handler gives the result of the Go
+// function it wraps, or rejects with its error code, on the same inputs.
+// The inputs differ from each other, so that a handler which passed them
+// in the wrong order would fail.
+func TestHandlersMatchGo(t *testing.T) {
+	const seed = "AAECAwQFBgcICQoLDA0ODw=="
+	const path = "/44'/20036'/100/1"
+	const msg = "AQIDBA=="
+	const challenge = "deposit 1234 to exchange"
+	key := must(t)
+	master := key(keyaddr.NewKey(seed))
+	edMaster := key(keyaddr.NewEdKey(seed))
+	derived := key(keyaddr.DeriveFrom(master, "/", path))
+	public := key(keyOf(derived).ToPublic())
+	addr, err := keyOf(public).NdauAddress()
+	require.NoError(t, err)
+	words, err := keyaddr.WordsFromBytes("en", seed)
+	require.NoError(t, err)
+	ownership, err := keyaddr.SignOwnershipChallenge(derived, challenge)
+	require.NoError(t, err)
+	recoverable, err := keyOf(derived).SignRecoverable(msg)
+	require.NoError(t, err)
+	accounts := `[{"Name": "savings", "Path": "/44'/20036'/100/1"}]`
+	var specs []keyaddr.AccountSpec
+	require.NoError(t, json.Unmarshal([]byte(accounts), &specs))
+	wallet, err := keyaddr.ExportWallet(master, specs)
+	require.NoError(t, err)
+
+	keyResult := func(k *keyaddr.Key, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		return k.Key, nil
+	}
+	keyObject := func(k *keyaddr.Key, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": k.Key}, nil
+	}
+	addrResult := func(a *keyaddr.Address, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		return a.Address, nil
+	}
+	sigObject := func(s *keyaddr.Signature, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"signature": s.Signature, "algorithm": s.Algorithm}, nil
+	}
+	result := func(v interface{}, err error) (interface{}, error) {
+		if err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+
+	tests := []handlerCase{
+		{"newKey", newKey, []interface{}{seed}, func() (interface{}, error) {
+			return keyResult(keyaddr.NewKey(seed))
+		}},
+		{"newKey short seed", newKey, []interface{}{"AQIDBA=="}, func() (interface{}, error) {
+			return keyResult(keyaddr.NewKey("AQIDBA=="))
+		}},
+		{"newEdKey", newEdKey, []interface{}{seed}, func() (interface{}, error) {
+			return keyResult(keyaddr.NewEdKey(seed))
+		}},
+		{"newEdKey bad encoding", newEdKey, []interface{}{"not base64!"}, func() (interface{}, error) {
+			return keyResult(keyaddr.NewEdKey("not base64!"))
+		}},
+		// newSeed is random, so only its errors can be compared
+		{"newSeed bad strength", newSeed, []interface{}{100}, func() (interface{}, error) {
+			return result(keyaddr.NewSeed(100))
+		}},
+		{"wordsToBytes", wordsToBytes, []interface{}{"en", words}, func() (interface{}, error) {
+			return result(keyaddr.WordsToBytes("en", words))
+		}},
+		{"wordsToBytes bad words", wordsToBytes, []interface{}{"en", "ndau ndau"}, func() (interface{}, error) {
+			return result(keyaddr.WordsToBytes("en", "ndau ndau"))
+		}},
+		{"wordsFromBytes", wordsFromBytes, []interface{}{"en", seed}, func() (interface{}, error) {
+			return result(keyaddr.WordsFromBytes("en", seed))
+		}},
+		{"wordsFromBytes bad encoding", wordsFromBytes, []interface{}{"en", "not base64!"}, func() (interface{}, error) {
+			return result(keyaddr.WordsFromBytes("en", "not base64!"))
+		}},
+		{"deriveFrom", deriveFrom, []interface{}{master, "/44'", path}, func() (interface{}, error) {
+			return keyResult(keyaddr.DeriveFrom(master, "/44'", path))
+		}},
+		{"deriveFrom bad path", deriveFrom, []interface{}{master, "/", "44"}, func() (interface{}, error) {
+			return keyResult(keyaddr.DeriveFrom(master, "/", "44"))
+		}},
+		{"wordsFromPrefix", wordsFromPrefix, []interface{}{"en", "ab", 3}, func() (interface{}, error) {
+			return keyaddr.WordsFromPrefix("en", "ab", 3), nil
+		}},
+		{"validatePartialMnemonic", validatePartialMnemonic, []interface{}{"en", "abandon ability"}, func() (interface{}, error) {
+			ok, remaining, err := keyaddr.ValidatePartialMnemonic("en", "abandon ability")
+			return result(map[string]interface{}{"ok": ok, "expectedRemaining": remaining}, err)
+		}},
+		{"validatePartialMnemonic bad word", validatePartialMnemonic, []interface{}{"en", "abandon ndau"}, func() (interface{}, error) {
+			ok, remaining, err := keyaddr.ValidatePartialMnemonic("en", "abandon ndau")
+			return result(map[string]interface{}{"ok": ok, "expectedRemaining": remaining}, err)
+		}},
+		{"isPrivate", isPrivate, []interface{}{public}, func() (interface{}, error) {
+			return result(keyOf(public).IsPrivate())
+		}},
+		{"isPrivate bad key", isPrivate, []interface{}{"npvt"}, func() (interface{}, error) {
+			return result(keyOf("npvt").IsPrivate())
+		}},
+		{"keyDetails", keyDetails, []interface{}{derived}, func() (interface{}, error) {
+			d, err := keyOf(derived).Details()
+			if err != nil {
+				return nil, err
+			}
+			return map[string]interface{}{
+				"depth":             d.Depth,
+				"childIndex":        d.ChildIndex,
+				"hardened":          d.Hardened,
+				"parentFingerprint": d.ParentFingerprint,
+				"algorithm":         d.Algorithm,
+				"isPrivate":         d.IsPrivate,
+				"address":           d.Address,
+			}, nil
+		}},
+		{"fromString", fromString, []interface{}{edMaster}, func() (interface{}, error) {
+			return keyObject(keyaddr.FromString(edMaster))
+		}},
+		{"fromString bad key", fromString, []interface{}{"npvt"}, func() (interface{}, error) {
+			return keyObject(keyaddr.FromString("npvt"))
+		}},
+		{"fromOldString", fromOldString, []interface{}{public}, func() (interface{}, error) {
+			return keyObject(keyaddr.FromOldString(public))
+		}},
+		{"ndauAddress", ndauAddress, []interface{}{public}, func() (interface{}, error) {
+			return addrResult(keyOf(public).NdauAddress())
+		}},
+		{"addressOfKind", addressOfKind, []interface{}{public, "e"}, func() (interface{}, error) {
+			return addrResult(keyOf(public).AddressOfKind("e"))
+		}},
+		{"addressOfKind bad kind", addressOfKind, []interface{}{public, "zz"}, func() (interface{}, error) {
+			return addrResult(keyOf(public).AddressOfKind("zz"))
+		}},
+		{"addressOfKindOn", addressOfKindOn, []interface{}{public, "e", "tn"}, func() (interface{}, error) {
+			return addrResult(keyOf(public).AddressOfKindOn("e", "tn"))
+		}},
+		{"toPublic", toPublic, []interface{}{master}, func() (interface{}, error) {
+			return keyResult(keyOf(master).ToPublic())
+		}},
+		{"child", child, []interface{}{public, 3}, func() (interface{}, error) {
+			return keyResult(keyOf(public).Child(3))
+		}},
+		{"child of ed25519 key", child, []interface{}{edMaster, 3}, func() (interface{}, error) {
+			return keyResult(keyOf(edMaster).Child(3))
+		}},
+		{"hardenedChild", hardenedChild, []interface{}{master, 44}, func() (interface{}, error) {
+			return keyResult(keyOf(master).HardenedChild(44))
+		}},
+		{"hardenedChild of public key", hardenedChild, []interface{}{public, 44}, func() (interface{}, error) {
+			return keyResult(keyOf(public).HardenedChild(44))
+		}},
+		{"sign", sign, []interface{}{derived, msg}, func() (interface{}, error) {
+			s, err := keyOf(derived).Sign(msg)
+			if err != nil {
+				return nil, err
+			}
+			return s.Signature, nil
+		}},
+		{"sign with public key", sign, []interface{}{public, msg}, func() (interface{}, error) {
+			_, err := keyOf(public).Sign(msg)
+			return nil, err
+		}},
+		{"signWith", signWith, []interface{}{derived, keyaddr.AlgorithmSecp256k1, keyaddr.EncodingText, challenge}, func() (interface{}, error) {
+			return sigObject(keyOf(derived).SignWith(keyaddr.AlgorithmSecp256k1, keyaddr.EncodingText, challenge))
+		}},
+		{"signWith wrong algorithm", signWith, []interface{}{derived, keyaddr.AlgorithmEd25519, keyaddr.EncodingText, challenge}, func() (interface{}, error) {
+			return sigObject(keyOf(derived).SignWith(keyaddr.AlgorithmEd25519, keyaddr.EncodingText, challenge))
+		}},
+		{"signBatch", signBatch, []interface{}{derived, `["AQIDBA==", ""]`}, func() (interface{}, error) {
+			sigs, err := keyaddr.SignBatchJSON(derived, `["AQIDBA==", ""]`)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(sigs), nil
+		}},
+		{"signBatch bad messages", signBatch, []interface{}{derived, `["AQIDBA==", 1]`}, func() (interface{}, error) {
+			return result(keyaddr.SignBatchJSON(derived, `["AQIDBA==", 1]`))
+		}},
+		{"signOwnershipChallenge", signOwnershipChallenge, []interface{}{derived, challenge}, func() (interface{}, error) {
+			return sigObject(keyaddr.SignOwnershipChallenge(derived, challenge))
+		}},
+		{"signOwnershipChallenge with public key", signOwnershipChallenge, []interface{}{public, challenge}, func() (interface{}, error) {
+			return sigObject(keyaddr.SignOwnershipChallenge(public, challenge))
+		}},
+		{"verifyOwnershipChallenge", verifyOwnershipChallenge, []interface{}{addr.Address, challenge, ownership.Signature, public}, func() (interface{}, error) {
+			return result(keyaddr.VerifyOwnershipChallenge(addr.Address, challenge, ownership.Signature, public))
+		}},
+		{"verifyOwnershipChallenge bad address", verifyOwnershipChallenge, []interface{}{"nda", challenge, ownership.Signature, public}, func() (interface{}, error) {
+			return result(keyaddr.VerifyOwnershipChallenge("nda", challenge, ownership.Signature, public))
+		}},
+		{"signRecoverable", signRecoverable, []interface{}{derived, msg}, func() (interface{}, error) {
+			return result(keyOf(derived).SignRecoverable(msg))
+		}},
+		{"signRecoverable bad encoding", signRecoverable, []interface{}{derived, "not base64!"}, func() (interface{}, error) {
+			return result(keyOf(derived).SignRecoverable("not base64!"))
+		}},
+		{"recoverPublic", recoverPublic, []interface{}{msg, recoverable}, func() (interface{}, error) {
+			return result(keyaddr.RecoverPublic(msg, recoverable))
+		}},
+		{"recoverPublic bad signature", recoverPublic, []interface{}{msg, "AQID"}, func() (interface{}, error) {
+			return result(keyaddr.RecoverPublic(msg, "AQID"))
+		}},
+		{"recoverAddress", recoverAddress, []interface{}{msg, recoverable}, func() (interface{}, error) {
+			return addrResult(keyaddr.RecoverAddress(msg, recoverable))
+		}},
+		{"normalizeAddress", normalizeAddress, []interface{}{strings.ToUpper(addr.Address)}, func() (interface{}, error) {
+			return result(keyaddr.NormalizeAddress(strings.ToUpper(addr.Address)))
+		}},
+		{"normalizeAddress bad address", normalizeAddress, []interface{}{"ndaxxx"}, func() (interface{}, error) {
+			return result(keyaddr.NormalizeAddress("ndaxxx"))
+		}},
+		{"exportWallet", exportWallet, []interface{}{master, accounts}, func() (interface{}, error) {
+			return result(keyaddr.ExportWallet(master, specs))
+		}},
+		{"exportWallet with public key", exportWallet, []interface{}{public, accounts}, func() (interface{}, error) {
+			return result(keyaddr.ExportWallet(public, specs))
+		}},
+		{"importWallet", importWallet, []interface{}{wallet}, func() (interface{}, error) {
+			w, err := keyaddr.ImportWallet(wallet)
+			if err != nil {
+				return nil, err
+			}
+			accounts := make([]interface{}, 0, len(w.Accounts))
+			for _, a := range w.Accounts {
+				accounts = append(accounts, map[string]interface{}{
+					"name":    a.Name,
+					"path":    a.Path,
+					"public":  a.Public,
+					"address": a.Address,
+					"private": a.Private,
+				})
+			}
+			return accounts, nil
+		}},
+		{"importWallet bad document", importWallet, []interface{}{"{}"}, func() (interface{}, error) {
+			return result(keyaddr.ImportWallet("{}"))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, wantErr := tt.goFn()
+			jsErr, got := invoke(tt.handler, tt.args...)
+			if wantErr != nil {
+				require.Equal(t, js.TypeObject, jsErr.Type(), "handler didn't reject")
+				require.Equal(t, keyaddr.CodeOf(wantErr), jsErr.Get("code").Int(), "error code")
+				require.Contains(t, jsErr.Get("message").String(), wantErr.Error())
+				return
+			}
+			if jsErr.Type() != js.TypeNull {
+				t.Fatalf("handler rejected: %s", jsErr.Get("message").String())
+			}
+			require.Equal(t, decoded(t, goJSON(t, want)), decoded(t, jsJSON(got)))
+		})
+	}
+}
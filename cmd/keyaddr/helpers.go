@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"syscall/js"
 
+	"github.com/ndau/ndaumath/pkg/keyaddr"
 	"github.com/pkg/errors"
 )
 
@@ -108,9 +109,21 @@ func logInfo(msg string) {
 }
 
 // jsLogReject returns a JS error to the callback and logs the error
+//
+// The JS error has a numeric code property: the keyaddr error code of the
+// first error among sprintfArgs, or keyaddr.BadArgument if there is none,
+// as then the handler itself has rejected its arguments.
 func jsLogReject(cb js.Value, str string, sprintfArgs ...interface{}) {
 	msg := fmt.Sprintf(str, sprintfArgs...)
+	code := keyaddr.BadArgument
+	for _, arg := range sprintfArgs {
+		if err, ok := arg.(error); ok {
+			code = keyaddr.CodeOf(err)
+			break
+		}
+	}
 	jsErr := js.Global().Get("Error").Invoke(msg)
+	jsErr.Set("code", code)
 	logError(msg)
 	cb.Invoke(jsErr, nil)
 }
@@ -41,19 +41,36 @@ func main() {
 
 	// put go functions in a javascript object
 	obj := map[string]interface{}{
-		"newKey":          js.FuncOf(newKey),
-		"wordsToBytes":    js.FuncOf(wordsToBytes),
-		"deriveFrom":      js.FuncOf(deriveFrom),
-		"ndauAddress":     js.FuncOf(ndauAddress),
-		"toPublic":        js.FuncOf(toPublic),
-		"child":           js.FuncOf(child),
-		"sign":            js.FuncOf(sign),
-		"hardenedChild":   js.FuncOf(hardenedChild),
-		"wordsFromPrefix": js.FuncOf(wordsFromPrefix),
-		"isPrivate":       js.FuncOf(isPrivate),
-		"wordsFromBytes":  js.FuncOf(wordsFromBytes),
-		"fromString":      js.FuncOf(fromString),
-		"exit":            js.FuncOf(exit),
+		"newKey":                   js.FuncOf(newKey),
+		"newEdKey":                 js.FuncOf(newEdKey),
+		"newSeed":                  js.FuncOf(newSeed),
+		"wordsToBytes":             js.FuncOf(wordsToBytes),
+		"deriveFrom":               js.FuncOf(deriveFrom),
+		"ndauAddress":              js.FuncOf(ndauAddress),
+		"addressOfKind":            js.FuncOf(addressOfKind),
+		"addressOfKindOn":          js.FuncOf(addressOfKindOn),
+		"toPublic":                 js.FuncOf(toPublic),
+		"child":                    js.FuncOf(child),
+		"sign":                     js.FuncOf(sign),
+		"signWith":                 js.FuncOf(signWith),
+		"signBatch":                js.FuncOf(signBatch),
+		"signOwnershipChallenge":   js.FuncOf(signOwnershipChallenge),
+		"verifyOwnershipChallenge": js.FuncOf(verifyOwnershipChallenge),
+		"signRecoverable":          js.FuncOf(signRecoverable),
+		"recoverPublic":            js.FuncOf(recoverPublic),
+		"recoverAddress":           js.FuncOf(recoverAddress),
+		"normalizeAddress":         js.FuncOf(normalizeAddress),
+		"hardenedChild":            js.FuncOf(hardenedChild),
+		"wordsFromPrefix":          js.FuncOf(wordsFromPrefix),
+		"validatePartialMnemonic":  js.FuncOf(validatePartialMnemonic),
+		"isPrivate":                js.FuncOf(isPrivate),
+		"keyDetails":               js.FuncOf(keyDetails),
+		"wordsFromBytes":           js.FuncOf(wordsFromBytes),
+		"fromString":               js.FuncOf(fromString),
+		"fromOldString":            js.FuncOf(fromOldString),
+		"exportWallet":             js.FuncOf(exportWallet),
+		"importWallet":             js.FuncOf(importWallet),
+		"exit":                     js.FuncOf(exit),
 	}
 
 	// Register all functions globally under KeyaddrNS. Either `window` in browsers, or
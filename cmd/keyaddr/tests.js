@@ -49,17 +49,34 @@ before(() => {
     .then(() => {
       global.Keyaddr = {
         newKey: promisify(KeyaddrNS.newKey),
+        newEdKey: promisify(KeyaddrNS.newEdKey),
+        newSeed: promisify(KeyaddrNS.newSeed),
         wordsToBytes: promisify(KeyaddrNS.wordsToBytes),
         deriveFrom: promisify(KeyaddrNS.deriveFrom),
         ndauAddress: promisify(KeyaddrNS.ndauAddress),
+        addressOfKind: promisify(KeyaddrNS.addressOfKind),
+        addressOfKindOn: promisify(KeyaddrNS.addressOfKindOn),
         toPublic: promisify(KeyaddrNS.toPublic),
         child: promisify(KeyaddrNS.child),
         sign: promisify(KeyaddrNS.sign),
+        signWith: promisify(KeyaddrNS.signWith),
+        signBatch: promisify(KeyaddrNS.signBatch),
+        signOwnershipChallenge: promisify(KeyaddrNS.signOwnershipChallenge),
+        verifyOwnershipChallenge: promisify(KeyaddrNS.verifyOwnershipChallenge),
+        signRecoverable: promisify(KeyaddrNS.signRecoverable),
+        recoverPublic: promisify(KeyaddrNS.recoverPublic),
+        normalizeAddress: promisify(KeyaddrNS.normalizeAddress),
+        recoverAddress: promisify(KeyaddrNS.recoverAddress),
         hardenedChild: promisify(KeyaddrNS.hardenedChild),
         wordsFromPrefix: promisify(KeyaddrNS.wordsFromPrefix),
+        validatePartialMnemonic: promisify(KeyaddrNS.validatePartialMnemonic),
         isPrivate: promisify(KeyaddrNS.isPrivate),
+        keyDetails: promisify(KeyaddrNS.keyDetails),
         fromString: promisify(KeyaddrNS.fromString),
+        fromOldString: promisify(KeyaddrNS.fromOldString),
         wordsFromBytes: promisify(KeyaddrNS.wordsFromBytes),
+        exportWallet: promisify(KeyaddrNS.exportWallet),
+        importWallet: promisify(KeyaddrNS.importWallet),
         exit: promisify(KeyaddrNS.exit)
       }
     })
@@ -74,6 +91,10 @@ const recoveryPhraseDifferent =
 const privateKey =
   'npvta8jaftcjebhe9pi57hji5yrt3yc3f2gn3ih56rys38qxt52945vuf8xqu4jfkaaaaaaaaaaaacz6d28v6zwuqm6c7jt4yqcjk4ujvw53jqehafkm5xxvh39jjep58u7pw33dd7cc'
 const badPrivateKey = 'foo' + privateKey
+const oldChildPrivateKey =
+  'npvt8bartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuuaehcsawbid2ew7bg9b5h7i6vdwmzjcq4etc69m545z7nx847yvqyc4ayapm'
+const newChildPrivateKey =
+  'npvta8jaftcjecdtiakawb6ckqsvrs7v8wqjt4f5wthpcitqrx77p58yk9pq5jzmabartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuurm9dajmmwr4'
 const parentPath = `/`
 const childPath = `/44'/20036'/100/1`
 const firstChildPrivateKey =
@@ -117,6 +138,36 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('newSeed', () => {
+    it('generates a seed for a 24-word recovery phrase', async () => {
+      const seed = await Keyaddr.newSeed(256)
+      const words = await Keyaddr.wordsFromBytes(language, seed)
+      expect(words.split(' ')).to.have.lengthOf(24)
+      expect(await Keyaddr.wordsToBytes(language, words)).to.equal(seed)
+    })
+    it('errors with an invalid strength', () => {
+      return expect(Keyaddr.newSeed(100)).to.eventually.be.rejected
+    })
+    it('rejects with a stable error code', () => {
+      // keyaddr.BadSeed
+      return expect(Keyaddr.newSeed(100))
+        .to.eventually.be.rejected.and.have.property('code', 9)
+    })
+  })
+
+  describe('newEdKey', () => {
+    it('gets a new ed25519 key from recovery bytes', async () => {
+      const key = await Keyaddr.newEdKey(recoveryBytes)
+      expect(key).to.not.equal(privateKey)
+      const child = await Keyaddr.hardenedChild(key, 44)
+      expect(child).to.be.a('string')
+    })
+    it('refuses non-hardened derivation', async () => {
+      const key = await Keyaddr.newEdKey(recoveryBytes)
+      return await expect(Keyaddr.child(key, 44)).to.eventually.be.rejected
+    })
+  })
+
   describe('deriveFrom', () => {
     it('derives a new key from the root private key', async () => {
       const key = await Keyaddr.deriveFrom(privateKey, parentPath, childPath)
@@ -156,6 +207,61 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('normalizeAddress', () => {
+    it('normalizes an address URI', async () => {
+      const address = await Keyaddr.normalizeAddress(
+        ' ndau:' + firstChildAddress.toUpperCase() + '?amount=1 '
+      )
+      expect(address).to.equal(firstChildAddress)
+    })
+    it('errors with a bad address', async () => {
+      return await expect(Keyaddr.normalizeAddress('foo' + firstChildAddress))
+        .to.eventually.be.rejected
+    })
+  })
+
+  describe('addressOfKind', () => {
+    it(`gets the user address of the child's private key`, async () => {
+      const address = await Keyaddr.addressOfKind(firstChildPrivateKey, 'user')
+      expect(address).to.equal(firstChildAddress)
+    })
+    it(`gets an exchange address of the child's private key`, async () => {
+      const address = await Keyaddr.addressOfKind(
+        firstChildPrivateKey,
+        'exchange'
+      )
+      expect(address.slice(0, 3)).to.equal('ndx')
+    })
+    it(`errors with an unknown kind`, async () => {
+      return await expect(Keyaddr.addressOfKind(firstChildPrivateKey, 'xyz'))
+        .to.eventually.be.rejected
+    })
+  })
+
+  describe('addressOfKindOn', () => {
+    it(`gets the mainnet user address of the child's private key`, async () => {
+      const address = await Keyaddr.addressOfKindOn(
+        firstChildPrivateKey,
+        'user',
+        'nd'
+      )
+      expect(address).to.equal(firstChildAddress)
+    })
+    it(`gets a testnet exchange address of the child's private key`, async () => {
+      const address = await Keyaddr.addressOfKindOn(
+        firstChildPrivateKey,
+        'exchange',
+        'testnet'
+      )
+      expect(address.slice(0, 3)).to.equal('tnx')
+    })
+    it(`errors with an unknown chain id`, async () => {
+      return await expect(
+        Keyaddr.addressOfKindOn(firstChildPrivateKey, 'user', 'xx')
+      ).to.eventually.be.rejected
+    })
+  })
+
   describe('toPublic', () => {
     it('gets a public key from a private one', async () => {
       const pubKey = await Keyaddr.toPublic(firstChildPrivateKey)
@@ -189,6 +295,116 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('signBatch', () => {
+    it('signs several messages', async () => {
+      const sigs = await Keyaddr.signBatch(
+        firstGrandchildPrivateKey,
+        JSON.stringify([msg, msg])
+      )
+      expect(sigs).to.deep.equal([
+        firstGrandchildSignature,
+        firstGrandchildSignature
+      ])
+    })
+    it('signs no messages', async () => {
+      const sigs = await Keyaddr.signBatch(firstGrandchildPrivateKey, '[]')
+      expect(sigs).to.deep.equal([])
+    })
+    it(`errors with a bad private key`, async () => {
+      return await expect(
+        Keyaddr.signBatch(badPrivateKey, JSON.stringify([msg]))
+      ).to.eventually.be.rejected
+    })
+    it(`errors with messages which aren't a JSON array`, async () => {
+      return await expect(Keyaddr.signBatch(firstGrandchildPrivateKey, msg)).to
+        .eventually.be.rejected
+    })
+  })
+
+  describe('signWith', () => {
+    it('signs a message with explicit algorithm and encoding', async () => {
+      const sig = await Keyaddr.signWith(
+        firstGrandchildPrivateKey,
+        'secp256k1',
+        'base64',
+        msg
+      )
+      expect(sig.signature).to.equal(firstGrandchildSignature)
+      expect(sig.algorithm).to.equal('secp256k1')
+    })
+    it('signs the same message encoded as text', async () => {
+      const sig = await Keyaddr.signWith(
+        firstGrandchildPrivateKey,
+        'secp256k1',
+        'text',
+        'ndau is great\n'
+      )
+      expect(sig.signature).to.equal(firstGrandchildSignature)
+    })
+    it(`errors with the wrong algorithm`, async () => {
+      return await expect(
+        Keyaddr.signWith(firstGrandchildPrivateKey, 'ed25519', 'base64', msg)
+      ).to.eventually.be.rejected
+    })
+    it(`errors with an unknown encoding`, async () => {
+      return await expect(
+        Keyaddr.signWith(firstGrandchildPrivateKey, 'secp256k1', 'base58', msg)
+      ).to.eventually.be.rejected
+    })
+  })
+
+  describe('ownershipChallenge', () => {
+    const challenge = 'deposit 1234 to exchange'
+    it('proves ownership of an address', async () => {
+      const sig = await Keyaddr.signOwnershipChallenge(
+        firstChildPrivateKey,
+        challenge
+      )
+      expect(sig.algorithm).to.equal('secp256k1')
+      const ok = await Keyaddr.verifyOwnershipChallenge(
+        firstChildAddress,
+        challenge,
+        sig.signature,
+        firstChildPublicKey
+      )
+      expect(ok).to.equal(true)
+    })
+    it('fails for a different challenge', async () => {
+      const sig = await Keyaddr.signOwnershipChallenge(
+        firstChildPrivateKey,
+        challenge
+      )
+      const ok = await Keyaddr.verifyOwnershipChallenge(
+        firstChildAddress,
+        'something else',
+        sig.signature,
+        firstChildPublicKey
+      )
+      expect(ok).to.equal(false)
+    })
+    it(`errors with a bad private key`, async () => {
+      return await expect(
+        Keyaddr.signOwnershipChallenge(badPrivateKey, challenge)
+      ).to.eventually.be.rejected
+    })
+  })
+
+  describe('recoverAddress', () => {
+    const msg = 'AQIDBAUGBwg='
+    it('recovers the address of the signer', async () => {
+      const sig = await Keyaddr.signRecoverable(firstChildPrivateKey, msg)
+      const address = await Keyaddr.recoverAddress(msg, sig)
+      expect(address).to.equal(firstChildAddress)
+      const pub = await Keyaddr.recoverPublic(msg, sig)
+      expect(pub).to.match(/^npub/)
+    })
+    it(`errors with a bad signature`, async () => {
+      return await expect(
+        Keyaddr.recoverAddress(msg, 'AQID')
+      ).to.eventually.be.rejected
+    })
+  })
+
   describe('hardenedChild', () => {
     it('creates a hardened child private key', async () => {
       const key = await Keyaddr.hardenedChild(
@@ -215,6 +431,18 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('validatePartialMnemonic', () => {
+    it('counts the words still to be entered', async () => {
+      const resp = await Keyaddr.validatePartialMnemonic('en', 'abandon amount li')
+      expect(resp.ok).to.equal(true)
+      expect(resp.expectedRemaining).to.equal(10)
+    })
+    it('rejects a word not in the wordlist', async () => {
+      return await expect(Keyaddr.validatePartialMnemonic('en', 'abandon xyz amount'))
+        .to.eventually.be.rejected
+    })
+  })
+
   describe('isPrivate', () => {
     it('tests a public key for privacy', async () => {
       const isPrivate = await Keyaddr.isPrivate(firstChildPublicKey)
@@ -229,6 +457,29 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('keyDetails', () => {
+    it('describes a derived private key', async () => {
+      const details = await Keyaddr.keyDetails(firstChildPrivateKey)
+      expect(details).to.deep.equal({
+        depth: 4,
+        childIndex: 1,
+        hardened: false,
+        parentFingerprint: '3fb7c1',
+        algorithm: 'secp256k1',
+        isPrivate: true,
+        address: firstChildAddress
+      })
+    })
+    it('describes the corresponding public key', async () => {
+      const details = await Keyaddr.keyDetails(firstChildPublicKey)
+      expect(details.isPrivate).to.equal(false)
+      expect(details.address).to.equal(firstChildAddress)
+    })
+    it('errors on a bad key', () => {
+      return expect(Keyaddr.keyDetails(badPrivateKey)).to.eventually.be.rejected
+    })
+  })
+
   describe('fromString', () => {
     it('creates a key from a public key string', async () => {
       const key = await Keyaddr.fromString(firstChildPublicKey)
@@ -247,6 +498,18 @@ describe('Keyaddr', () => {
     })
   })
 
+  describe('fromOldString', () => {
+    it('converts an old private key string', async () => {
+      const key = await Keyaddr.fromOldString(oldChildPrivateKey)
+      expect(key).to.deep.equal({
+        key: newChildPrivateKey
+      })
+    })
+    it('errors trying to convert a new key string', () => {
+      return expect(Keyaddr.fromOldString(firstChildPrivateKey)).to.eventually.be.rejected
+    })
+  })
+
   it('converts bytes to words', async () => {
     const words = await Keyaddr.wordsFromBytes('en', recoveryBytes)
     expect(words).to.equal(recoveryPhrase)
@@ -260,6 +523,28 @@ describe('Keyaddr', () => {
   })
 })
 
+describe('wallet interchange', () => {
+  it('exports and imports a wallet', async () => {
+    const accounts = JSON.stringify([
+      { Name: 'savings', Path: `/44'/20036'/100/1` },
+      { Name: 'spending', Path: `/44'/20036'/100/2`, IncludePrivate: true }
+    ])
+    const doc = await Keyaddr.exportWallet(privateKey, accounts)
+    expect(doc).to.not.contain(privateKey)
+    const imported = await Keyaddr.importWallet(doc)
+    expect(imported).to.have.lengthOf(2)
+    expect(imported[0].name).to.equal('savings')
+    expect(imported[0].private).to.equal('')
+    expect(imported[1].private).to.not.equal('')
+    const addr = await Keyaddr.ndauAddress(imported[0].public)
+    expect(addr).to.equal(imported[0].address)
+  })
+  it('errors on a tampered wallet', async () => {
+    return await expect(Keyaddr.importWallet('{"version": 2, "accounts": []}'))
+      .to.eventually.be.rejected
+  })
+})
+
 describe('simple memory test', () => {
   it('should not run out of memory', async () => {
     const bytes = await Keyaddr.wordsToBytes(language, recoveryPhrase)
@@ -10,21 +10,12 @@ package address
 // - -- --- ---- -----
 
 
-import "encoding"
+import (
+	"encoding"
+	"strings"
 
-//========== I M P O R T A N T ====================
-// NOTE: These go generate commands are deliberately triple-commented.
-// The address field is private, but we want it to be serialized. This
-// requires a manual step -- make the field public (by naming it Addr)
-// remove the extra slash from these two lines, and do `go generate`.
-// Then change all occurences of Addr back to addr (including in the
-// generated code). And recomment those two lines.
-
-// Yes, this is horribly ugly.
-//=================================================
-
-///go:generate msgp
-///msgp:tuple Address
+	"github.com/tinylib/msgp/msgp"
+)
 
 // An Address is a 48-character string uniquely identifying an Ndau account
 //
@@ -32,12 +23,43 @@ import "encoding"
 // it difficult to accidentally pass in a wrong string or something: so long
 // as one gets an Address by means of the Generate or Validate functions,
 // it is known to be good.
+//
+// Unmarshaling an Address from text or JSON validates it, and keeps it as
+// given; use UnmarshalTextWith or Parse to choose a Strictness instead.
+// Decoding msgp preserves the serialized string exactly, so that structs
+// which are hashed or signed roundtrip; use DecodeMsgWith or
+// UnmarshalMsgWith to validate it. JSON uses the text form.
 type Address struct {
 	addr string
 }
 
 var _ encoding.TextMarshaler = (*Address)(nil)
 var _ encoding.TextUnmarshaler = (*Address)(nil)
+var _ msgp.Marshaler = (*Address)(nil)
+var _ msgp.Unmarshaler = (*Address)(nil)
+var _ msgp.Encodable = (*Address)(nil)
+var _ msgp.Decodable = (*Address)(nil)
+var _ msgp.Sizer = (*Address)(nil)
+
+// Strictness determines how Parse treats addresses which are valid, but not
+// in their canonical lower case
+type Strictness int
+
+const (
+	// Lenient parsing accepts addresses in any case, and normalizes them
+	// to lower case
+	Lenient Strictness = iota
+	// Strict parsing rejects addresses which are not in lower case
+	Strict
+)
+
+// Parse validates an address being unmarshaled, with the given Strictness
+func Parse(addr string, strictness Strictness) (Address, error) {
+	if strictness == Strict && addr != strings.ToLower(addr) {
+		return emptyA(), newError("address is not in lower case")
+	}
+	return Validate(addr)
+}
 
 // MarshalText implements encoding.TextMarshaler
 func (a Address) MarshalText() ([]byte, error) {
@@ -45,6 +67,8 @@ func (a Address) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
+//
+// It validates the address in any case, but keeps it exactly as given.
 func (a *Address) UnmarshalText(text []byte) error {
 	s := string(text)
 	_, err := Validate(s)
@@ -54,3 +78,113 @@ func (a *Address) UnmarshalText(text []byte) error {
 	a.addr = s
 	return nil
 }
+
+// UnmarshalTextWith unmarshals an address, validating it with the given
+// Strictness as Parse does
+func (a *Address) UnmarshalTextWith(text []byte, strictness Strictness) error {
+	addr, err := Parse(string(text), strictness)
+	if err != nil {
+		return err
+	}
+	*a = addr
+	return nil
+}
+
+// An Address serializes with msgp as a tuple of its one string
+
+// EncodeMsg implements msgp.Encodable
+func (a Address) EncodeMsg(en *msgp.Writer) error {
+	err := en.WriteArrayHeader(1)
+	if err != nil {
+		return err
+	}
+	err = en.WriteString(a.addr)
+	if err != nil {
+		return msgp.WrapError(err, "addr")
+	}
+	return nil
+}
+
+// DecodeMsg implements msgp.Decodable
+//
+// It doesn't validate the address.
+func (a *Address) DecodeMsg(dc *msgp.Reader) error {
+	n, err := dc.ReadArrayHeader()
+	if err != nil {
+		return msgp.WrapError(err)
+	}
+	if n != 1 {
+		return msgp.ArrayError{Wanted: 1, Got: n}
+	}
+	a.addr, err = dc.ReadString()
+	if err != nil {
+		return msgp.WrapError(err, "addr")
+	}
+	return nil
+}
+
+// DecodeMsgWith decodes an address as DecodeMsg does, then validates it
+// with the given Strictness.
+//
+// The address is left unchanged if it is invalid. Lenient validation
+// normalizes its case, so it may not re-encode to the same bytes.
+func (a *Address) DecodeMsgWith(dc *msgp.Reader, strictness Strictness) error {
+	var raw Address
+	err := raw.DecodeMsg(dc)
+	if err != nil {
+		return err
+	}
+	addr, err := Parse(raw.addr, strictness)
+	if err != nil {
+		return msgp.WrapError(err, "addr")
+	}
+	*a = addr
+	return nil
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (a Address) MarshalMsg(b []byte) ([]byte, error) {
+	o := msgp.Require(b, a.Msgsize())
+	o = msgp.AppendArrayHeader(o, 1)
+	o = msgp.AppendString(o, a.addr)
+	return o, nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+//
+// It doesn't validate the address.
+func (a *Address) UnmarshalMsg(bts []byte) ([]byte, error) {
+	n, bts, err := msgp.ReadArrayHeaderBytes(bts)
+	if err != nil {
+		return bts, msgp.WrapError(err)
+	}
+	if n != 1 {
+		return bts, msgp.ArrayError{Wanted: 1, Got: n}
+	}
+	a.addr, bts, err = msgp.ReadStringBytes(bts)
+	if err != nil {
+		return bts, msgp.WrapError(err, "addr")
+	}
+	return bts, nil
+}
+
+// UnmarshalMsgWith unmarshals an address as UnmarshalMsg does, then
+// validates it with the given Strictness, as DecodeMsgWith does
+func (a *Address) UnmarshalMsgWith(bts []byte, strictness Strictness) ([]byte, error) {
+	var raw Address
+	bts, err := raw.UnmarshalMsg(bts)
+	if err != nil {
+		return bts, err
+	}
+	addr, err := Parse(raw.addr, strictness)
+	if err != nil {
+		return bts, msgp.WrapError(err, "addr")
+	}
+	*a = addr
+	return bts, nil
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (a Address) Msgsize() int {
+	return 1 + msgp.StringPrefixSize + len(a.addr)
+}
@@ -1,87 +0,0 @@
-package address
-
-// Code generated by github.com/tinylib/msgp DO NOT EDIT.
-
-// ----- ---- --- -- -
-// Copyright 2020 The Axiom Foundation. All Rights Reserved.
-//
-// Licensed under the Apache License 2.0 (the "License").  You may not use
-// this file except in compliance with the License.  You can obtain a copy
-// in the file LICENSE in the source distribution or at
-// https://www.apache.org/licenses/LICENSE-2.0.txt
-// - -- --- ---- -----
-
-import (
-	"github.com/tinylib/msgp/msgp"
-)
-
-// DecodeMsg implements msgp.Decodable
-func (z *Address) DecodeMsg(dc *msgp.Reader) (err error) {
-	var zb0001 uint32
-	zb0001, err = dc.ReadArrayHeader()
-	if err != nil {
-		err = msgp.WrapError(err)
-		return
-	}
-	if zb0001 != 1 {
-		err = msgp.ArrayError{Wanted: 1, Got: zb0001}
-		return
-	}
-	z.addr, err = dc.ReadString()
-	if err != nil {
-		err = msgp.WrapError(err, "addr")
-		return
-	}
-	return
-}
-
-// EncodeMsg implements msgp.Encodable
-func (z Address) EncodeMsg(en *msgp.Writer) (err error) {
-	// array header, size 1
-	err = en.Append(0x91)
-	if err != nil {
-		return
-	}
-	err = en.WriteString(z.addr)
-	if err != nil {
-		err = msgp.WrapError(err, "addr")
-		return
-	}
-	return
-}
-
-// MarshalMsg implements msgp.Marshaler
-func (z Address) MarshalMsg(b []byte) (o []byte, err error) {
-	o = msgp.Require(b, z.Msgsize())
-	// array header, size 1
-	o = append(o, 0x91)
-	o = msgp.AppendString(o, z.addr)
-	return
-}
-
-// UnmarshalMsg implements msgp.Unmarshaler
-func (z *Address) UnmarshalMsg(bts []byte) (o []byte, err error) {
-	var zb0001 uint32
-	zb0001, bts, err = msgp.ReadArrayHeaderBytes(bts)
-	if err != nil {
-		err = msgp.WrapError(err)
-		return
-	}
-	if zb0001 != 1 {
-		err = msgp.ArrayError{Wanted: 1, Got: zb0001}
-		return
-	}
-	z.addr, bts, err = msgp.ReadStringBytes(bts)
-	if err != nil {
-		err = msgp.WrapError(err, "addr")
-		return
-	}
-	o = bts
-	return
-}
-
-// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z Address) Msgsize() (s int) {
-	s = 1 + msgp.StringPrefixSize + len(z.addr)
-	return
-}
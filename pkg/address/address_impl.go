@@ -13,9 +13,11 @@ package address
 import (
 	"crypto/sha256"
 	"fmt"
+	"io"
 	"strings"
 
 	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/ndau/ndaumath/pkg/constants"
 )
 
 // An ndau address is the result of a mathematical process over a public key. It
@@ -25,13 +27,6 @@ import (
 // prefix (nd for the main chain and tn for the testnet), plus one more
 // character that specifies the type of address.
 
-// Kind indicates the type of address in use; this is an external indication
-// designed to help users evaluate their own actions; it may or may not be
-// enforced by the blockchain.
-// We don't define a type for it so that it serializes naturally.  We give up
-// type safety to avoid msgp hassles.  See address.go for details.
-//type Kind byte
-
 func emptyA() Address {
 	return Address{}
 }
@@ -49,73 +44,25 @@ func newError(msg string) error {
 	return &Error{msg}
 }
 
-// All addresses start with this 2-byte prefix, followed by a kind byte.
+// All main network addresses start with this 2-byte prefix, followed by a
+// kind byte.
 const addrPrefix string = "nd"
 const kindOffset int = len(addrPrefix)
 
-// predefined address kinds
-const (
-	KindUser        byte = 'a'
-	KindNdau        byte = 'n'
-	KindEndowment   byte = 'e'
-	KindExchange    byte = 'x'
-	KindBPC         byte = 'b'
-	KindMarketMaker byte = 'm'
-)
-
-// IsValidKind returns true if the last letter of a is one of the currently-valid kinds
-func IsValidKind(k byte) bool {
-	switch k {
-	case KindUser,
-		KindNdau,
-		KindEndowment,
-		KindExchange,
-		KindBPC,
-		KindMarketMaker:
-		return true
-	}
-	return false
-}
-
-// ParseKind returns a Kind or an explanation of why the supplied value is not one.
-func ParseKind(i interface{}) (byte, error) {
-	b := byte(0)
-	switch v := i.(type) {
-	case string:
-		if v == "" {
-			return b, fmt.Errorf("empty string is not a valid Kind")
-		}
-		v = strings.ToLower(v)
-		switch v {
-		case "u", "user":
-			b = KindUser
-		case "ndau":
-			b = KindNdau
-		case "endowment":
-			b = KindEndowment
-		case "exchange":
-			b = KindExchange
-		case "bpc":
-			b = KindBPC
-		case "marketmaker":
-			b = KindMarketMaker
-		default:
-			b = byte(v[0])
-		}
-	case rune:
-		b = byte(strings.ToLower(string(v))[0])
-	case byte:
-		b = v
-	case int8:
-		b = byte(v)
-	default:
-		return b, fmt.Errorf("Kind cannot be parsed from %T", i)
-	}
+// networkPrefixes are the address prefixes of each network. They must all
+// have the same length as addrPrefix.
+var networkPrefixes = map[constants.Network]string{
+	constants.MainNet: addrPrefix,
+	constants.TestNet: "tn",
+}
 
-	if !IsValidKind(b) {
-		return b, fmt.Errorf("%q is not a valid Kind", string(b))
+// NetworkPrefix returns the 2-character prefix of addresses on a network
+func NetworkPrefix(net constants.Network) (string, error) {
+	prefix, ok := networkPrefixes[net]
+	if !ok {
+		return "", newError(fmt.Sprintf("unknown network: %d", net))
 	}
-	return b, nil
+	return prefix, nil
 }
 
 // HashTrim is the number of bytes that we trim the input hash to.
@@ -145,22 +92,79 @@ const MinDataLength = 12
 // Since length changes are explicitly disallowed, we can use a relatively simple
 // crc model to have a short (16-bit) checksum and still be quite safe against
 // transposition and typos.
+//
+// The address is on the main network, so it starts with "nd".
 func Generate(kind byte, data []byte) (Address, error) {
+	return GenerateForNetwork(constants.MainNet, kind, data)
+}
+
+// GenerateForNetwork creates an address of a given kind on a given network
+//
+// Addresses on the test network start with "tn"; otherwise, it is identical
+// to Generate.
+func GenerateForNetwork(net constants.Network, kind byte, data []byte) (Address, error) {
+	prefix, err := NetworkPrefix(net)
+	if err != nil {
+		return emptyA(), err
+	}
 	if !IsValidKind(kind) {
 		return emptyA(), newError(fmt.Sprintf("invalid kind: %x", kind))
 	}
 	if len(data) < MinDataLength {
 		return emptyA(), newError("insufficient quantity of data")
 	}
+	return fromHash(prefix, kind, sha256.Sum256(data)), nil
+}
+
+// MaxStreamLength is the greatest quantity of data, in bytes, which
+// GenerateFromReader will read
+const MaxStreamLength = 1 << 30
+
+// GenerateFromReader creates an address of a given kind from all the data
+// read from r, without holding it all in memory.
+//
+// The address is the same as Generate would create from the same data. It
+// is intended for data other than keys, such as content-addressed
+// attachments. It is an error if r supplies fewer than MinDataLength or more
+// than MaxStreamLength bytes, or if kind is not a valid kind.
+func GenerateFromReader(kind byte, r io.Reader) (Address, error) {
+	return generateFromReader(kind, r, MaxStreamLength)
+}
+
+// generateFromReader is GenerateFromReader with an arbitrary length limit
+func generateFromReader(kind byte, r io.Reader, limit int64) (Address, error) {
+	if !IsValidKind(kind) {
+		return emptyA(), newError(fmt.Sprintf("invalid kind: %x", kind))
+	}
+	hasher := sha256.New()
+	// read one byte too many, so that we can tell when there is too much
+	n, err := io.Copy(hasher, io.LimitReader(r, limit+1))
+	if err != nil {
+		return emptyA(), newError(fmt.Sprintf("reading data: %s", err))
+	}
+	if n < MinDataLength {
+		return emptyA(), newError("insufficient quantity of data")
+	}
+	if n > limit {
+		return emptyA(), newError(fmt.Sprintf("data exceeds %d bytes", limit))
+	}
+	var h [sha256.Size]byte
+	hasher.Sum(h[:0])
+	return fromHash(addrPrefix, kind, h), nil
+}
+
+// fromHash creates an address with the given network prefix and kind from
+// the sha256 of its data
+func fromHash(netPrefix string, kind byte, h [sha256.Size]byte) Address {
 	// the hash contains the last HashTrim bytes of the sha256 of the data
-	h := sha256.Sum256(data)
 	h1 := h[len(h)-HashTrim:]
 
-	// an ndau address always starts with nd and a "kind" character
-	// so we figure out what characters we want and build that into a header
+	// an ndau address always starts with its network prefix and a "kind"
+	// character so we figure out what characters we want and build that
+	// into a header
 	prefix :=
-		b32.Index(addrPrefix[0:1])<<11 +
-			b32.Index(addrPrefix[1:2])<<6 +
+		b32.Index(netPrefix[0:1])<<11 +
+			b32.Index(netPrefix[1:2])<<6 +
 			b32.Index(string(kind))<<1
 	hdr := []byte{byte((prefix >> 8) & 0xFF), byte(prefix & 0xFF)}
 	h2 := append(hdr, h1...)
@@ -168,13 +172,13 @@ func Generate(kind byte, data []byte) (Address, error) {
 	h2 = append(h2, b32.Checksum16(h2)...)
 
 	r := b32.Encode(h2)
-	return Address{addr: r}, nil
+	return Address{addr: r}
 }
 
 // Validate tests if an address is valid on its face.
 // It checks the address kind, and the checksum.
 // It does NOT test the nd prefix, as that may vary -- clients should test that
-// themselves.
+// themselves, or use ValidateForNetwork.
 func Validate(addr string) (Address, error) {
 	addr = strings.ToLower(addr)
 	// if !strings.HasPrefix(addr, "nd") {
@@ -205,6 +209,34 @@ func Validate(addr string) (Address, error) {
 	return Address{addr: addr}, nil
 }
 
+// ValidateForNetwork tests if an address is valid on its face, as Validate
+// does, and also that it belongs to the given network.
+func ValidateForNetwork(addr string, net constants.Network) (Address, error) {
+	prefix, err := NetworkPrefix(net)
+	if err != nil {
+		return emptyA(), err
+	}
+	a, err := Validate(addr)
+	if err != nil {
+		return emptyA(), err
+	}
+	if !strings.HasPrefix(a.addr, prefix) {
+		return emptyA(), newError(fmt.Sprintf("not a %s address: must start with %q", net, prefix))
+	}
+	return a, nil
+}
+
+// Network returns the network to which the address belongs, according to
+// its prefix. ok is false if the prefix is not that of any known network.
+func (z Address) Network() (net constants.Network, ok bool) {
+	for net, prefix := range networkPrefixes {
+		if strings.HasPrefix(z.addr, prefix) {
+			return net, true
+		}
+	}
+	return 0, false
+}
+
 // String gives us a human-readable form of an address, because sometimes we just need that.
 func (z Address) String() string {
 	return z.addr
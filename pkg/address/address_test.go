@@ -11,11 +11,18 @@ package address
 
 
 import (
+	"bytes"
 	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"testing/iotest"
 
+	"github.com/ndau/ndaumath/pkg/constants"
 	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
 )
 
 func getKinds() []byte {
@@ -81,6 +88,10 @@ func TestKnownKeyGeneratesKnownValue(t *testing.T) {
 	address, err := Generate(KindUser, key)
 	require.NoError(t, err)
 	require.Equal(t, "ndadprx764ciigti8d8whtw2kct733r85qvjukhqhke3dka4", address.String())
+
+	address, err = GenerateForNetwork(constants.TestNet, KindUser, key)
+	require.NoError(t, err)
+	require.Equal(t, "tnadprx764ciigti8d8whtw2kct733r85qvjukhqhke3d7ay", address.String())
 }
 
 func TestKnownKeyValidates(t *testing.T) {
@@ -91,6 +102,41 @@ func TestKnownKeyValidates(t *testing.T) {
 	require.Error(t, err)
 }
 
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestGenerateFromReader(t *testing.T) {
+	for _, size := range []int{MinDataLength, 32, 100000} {
+		data := make([]byte, size)
+		_, err := rand.Read(data)
+		require.NoError(t, err)
+		for _, kind := range getKinds() {
+			want, err := Generate(kind, data)
+			require.NoError(t, err)
+			// a reader returning one byte at a time is the most thorough
+			// test of streaming
+			got, err := GenerateFromReader(kind, iotest.OneByteReader(bytes.NewReader(data)))
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		}
+	}
+
+	_, err := GenerateFromReader(KindUser, bytes.NewReader(make([]byte, MinDataLength-1)))
+	require.Error(t, err)
+	_, err = GenerateFromReader('!', bytes.NewReader(make([]byte, 32)))
+	require.Error(t, err)
+	_, err = GenerateFromReader(KindUser, errReader{})
+	require.Error(t, err)
+
+	_, err = generateFromReader(KindUser, bytes.NewReader(make([]byte, 64)), 64)
+	require.NoError(t, err)
+	_, err = generateFromReader(KindUser, bytes.NewReader(make([]byte, 65)), 64)
+	require.Error(t, err)
+}
+
 func BenchmarkGeneration(b *testing.B) {
 	key := make([]byte, 32)
 	kinds := getKinds()
@@ -110,3 +156,186 @@ func BenchmarkGeneration(b *testing.B) {
 		}
 	}
 }
+
+func TestGenerateForNetwork(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	for _, kind := range getKinds() {
+		main, err := GenerateForNetwork(constants.MainNet, kind, key)
+		require.NoError(t, err)
+		plain, err := Generate(kind, key)
+		require.NoError(t, err)
+		require.Equal(t, plain, main)
+		require.Equal(t, "nd"+string(kind), main.String()[:3])
+
+		test, err := GenerateForNetwork(constants.TestNet, kind, key)
+		require.NoError(t, err)
+		require.Equal(t, "tn"+string(kind), test.String()[:3])
+		require.Equal(t, kind, test.Kind())
+		// only the header differs
+		require.Equal(t, main.String()[4:AddrLength-4], test.String()[4:AddrLength-4])
+
+		for _, tt := range []struct {
+			addr Address
+			net  constants.Network
+		}{
+			{main, constants.MainNet},
+			{test, constants.TestNet},
+		} {
+			net, ok := tt.addr.Network()
+			require.True(t, ok)
+			require.Equal(t, tt.net, net)
+
+			_, err = Validate(tt.addr.String())
+			require.NoError(t, err)
+			_, err = ValidateForNetwork(tt.addr.String(), tt.net)
+			require.NoError(t, err)
+		}
+		_, err = ValidateForNetwork(main.String(), constants.TestNet)
+		require.Error(t, err)
+		_, err = ValidateForNetwork(test.String(), constants.MainNet)
+		require.Error(t, err)
+	}
+
+	_, err = GenerateForNetwork(constants.Network(99), KindUser, key)
+	require.Error(t, err)
+	_, err = ValidateForNetwork("ndadprx764ciigti8d8whtw2kct733r85qvjukhqhke3dka4", constants.Network(99))
+	require.Error(t, err)
+}
+
+func TestAddressUnmarshal(t *testing.T) {
+	addr, err := Generate(KindUser, []byte("address unmarshal test key"))
+	require.NoError(t, err)
+	upper := strings.ToUpper(addr.String())
+	corrupt := []byte(addr.String())
+	corrupt[20] = 'a' + 'b' - corrupt[20]
+
+	type holder struct {
+		Addr Address `json:"addr"`
+	}
+
+	// unmarshal decodes the given address text, with the given strictness,
+	// by every means which takes one
+	unmarshal := map[string]func(s string, strictness Strictness) (Address, error){
+		"parse": Parse,
+		"text": func(s string, strictness Strictness) (Address, error) {
+			var a Address
+			return a, a.UnmarshalTextWith([]byte(s), strictness)
+		},
+		"msgp bytes": func(s string, strictness Strictness) (Address, error) {
+			var a Address
+			_, err := a.UnmarshalMsgWith(uncheckedMsg(t, s), strictness)
+			return a, err
+		},
+		"msgp stream": func(s string, strictness Strictness) (Address, error) {
+			var a Address
+			err := a.DecodeMsgWith(msgp.NewReader(bytes.NewReader(uncheckedMsg(t, s))), strictness)
+			return a, err
+		},
+	}
+
+	for name, fn := range unmarshal {
+		for _, strictness := range []Strictness{Lenient, Strict} {
+			t.Run(fmt.Sprintf("%s %d", name, strictness), func(t *testing.T) {
+				got, err := fn(addr.String(), strictness)
+				require.NoError(t, err)
+				require.Equal(t, addr, got)
+
+				_, err = fn("", strictness)
+				require.Error(t, err)
+				_, err = fn(string(corrupt), strictness)
+				require.Error(t, err)
+				_, err = fn("ndaaaaaa", strictness)
+				require.Error(t, err)
+
+				got, err = fn(upper, strictness)
+				if strictness == Strict {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+					require.Equal(t, addr, got, "lenient unmarshaling normalizes case")
+				}
+			})
+		}
+	}
+
+	// UnmarshalText, and so JSON, validates, but keeps the address as given
+	plain := map[string]func(s string) (Address, error){
+		"text": func(s string) (Address, error) {
+			var a Address
+			return a, a.UnmarshalText([]byte(s))
+		},
+		"json": func(s string) (Address, error) {
+			var h holder
+			data, err := json.Marshal(map[string]string{"addr": s})
+			require.NoError(t, err)
+			err = json.Unmarshal(data, &h)
+			return h.Addr, err
+		},
+	}
+	for name, fn := range plain {
+		t.Run(name, func(t *testing.T) {
+			got, err := fn(addr.String())
+			require.NoError(t, err)
+			require.Equal(t, addr, got)
+
+			got, err = fn(upper)
+			require.NoError(t, err)
+			require.Equal(t, upper, got.String())
+
+			_, err = fn("")
+			require.Error(t, err)
+			_, err = fn(string(corrupt))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAddressMsgpPreservesBytes(t *testing.T) {
+	addr, err := Generate(KindUser, []byte("address msgp test key"))
+	require.NoError(t, err)
+	corrupt := []byte(addr.String())
+	corrupt[20] = 'a' + 'b' - corrupt[20]
+
+	for _, s := range []string{addr.String(), strings.ToUpper(addr.String()), string(corrupt), "ndaaaaaa", ""} {
+		b := uncheckedMsg(t, s)
+
+		var a Address
+		rest, err := a.UnmarshalMsg(b)
+		require.NoError(t, err)
+		require.Empty(t, rest)
+		require.Equal(t, s, a.String())
+		again, err := a.MarshalMsg(nil)
+		require.NoError(t, err)
+		require.Equal(t, b, again)
+
+		var d Address
+		require.NoError(t, msgp.Decode(bytes.NewReader(b), &d))
+		require.Equal(t, a, d)
+	}
+}
+
+func TestAddressMarshal(t *testing.T) {
+	addr, err := Generate(KindExchange, []byte("address marshal test key"))
+	require.NoError(t, err)
+
+	data, err := json.Marshal(addr)
+	require.NoError(t, err)
+	require.Equal(t, `"`+addr.String()+`"`, string(data))
+
+	var buf bytes.Buffer
+	require.NoError(t, msgp.Encode(&buf, addr))
+	b, err := addr.MarshalMsg(nil)
+	require.NoError(t, err)
+	require.Equal(t, b, buf.Bytes())
+	require.True(t, addr.Msgsize() >= len(b))
+}
+
+// uncheckedMsg serializes an address without validating it
+func uncheckedMsg(t *testing.T, addr string) []byte {
+	b, err := Address{addr: addr}.MarshalMsg(nil)
+	require.NoError(t, err)
+	return b
+}
@@ -0,0 +1,199 @@
+package address
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ndau/ndaumath/pkg/b32"
+)
+
+//go:generate msgp
+
+// Kind indicates the type of address in use; this is an external indication
+// designed to help users evaluate their own actions; it may or may not be
+// enforced by the blockchain.
+//
+// Most of this package's API deals in plain bytes, so that kinds serialize
+// naturally; Kind is a convenience for naming them. Its msgp encoding is
+// identical to that of a byte.
+type Kind byte
+
+// predefined address kinds
+const (
+	KindUser        byte = 'a'
+	KindNdau        byte = 'n'
+	KindEndowment   byte = 'e'
+	KindExchange    byte = 'x'
+	KindBPC         byte = 'b'
+	KindMarketMaker byte = 'm'
+)
+
+// KindInfo describes a registered address kind.
+type KindInfo struct {
+	Byte        byte
+	Name        string
+	Description string
+	// Reserved kinds have claimed their byte and name, but are not yet valid:
+	// addresses of a reserved kind can neither be generated nor validated.
+	Reserved bool
+}
+
+// the registry is read on every address validation, but written only at
+// registration time
+var (
+	kindLock    sync.RWMutex
+	kinds       []KindInfo
+	kindIndex   map[byte]int
+	kindNameMap map[string]byte
+)
+
+func init() {
+	kindIndex = make(map[byte]int)
+	// "u" is accepted by ParseKind as an abbreviation of "user"
+	kindNameMap = map[string]byte{"u": KindUser}
+
+	for _, builtin := range []KindInfo{
+		{KindUser, "user", "an ordinary user account", false},
+		{KindNdau, "ndau", "an account operated by the ndau foundation", false},
+		{KindEndowment, "endowment", "an account belonging to the ndau endowment", false},
+		{KindExchange, "exchange", "an account operated by an exchange", false},
+		{KindBPC, "bpc", "an account belonging to the blockchain policy council", false},
+		{KindMarketMaker, "marketmaker", "an account operated by a market maker", false},
+	} {
+		err := RegisterKind(builtin)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterKind adds an address kind to the registry.
+//
+// The kind byte must be a character of the address alphabet, and the name
+// must be a lowercase word of at least two characters. It is an error if
+// either is already registered to something else.
+//
+// Repeating an identical registration is harmless. A reserved kind can be
+// activated by registering it again with the same name and Reserved unset.
+func RegisterKind(info KindInfo) error {
+	if b32.Index(string(info.Byte)) < 0 {
+		return fmt.Errorf("kind %q is not in the address alphabet", string(info.Byte))
+	}
+	if len(info.Name) < 2 {
+		return fmt.Errorf("kind name %q is too short", info.Name)
+	}
+	for _, r := range info.Name {
+		if r < 'a' || r > 'z' {
+			return fmt.Errorf("kind name %q must contain only lowercase letters", info.Name)
+		}
+	}
+
+	kindLock.Lock()
+	defer kindLock.Unlock()
+
+	if b, ok := kindNameMap[string(info.Byte)]; ok && b != info.Byte {
+		// ParseKind would read the single character as the other kind's name
+		return fmt.Errorf("kind %q is ambiguous with %q", string(info.Byte), string(b))
+	}
+	if b, ok := kindNameMap[info.Name]; ok && b != info.Byte {
+		return fmt.Errorf("kind name %q already in use by %q", info.Name, string(b))
+	}
+	if idx, ok := kindIndex[info.Byte]; ok {
+		existing := kinds[idx]
+		if existing.Name != info.Name {
+			return fmt.Errorf("kind %q already registered as %s", string(info.Byte), existing.Name)
+		}
+		if existing.Reserved {
+			kinds[idx] = info
+			return nil
+		}
+		if info != existing {
+			return fmt.Errorf("kind %s already registered differently", existing.Name)
+		}
+		return nil
+	}
+
+	kindIndex[info.Byte] = len(kinds)
+	kinds = append(kinds, info)
+	kindNameMap[info.Name] = info.Byte
+	return nil
+}
+
+// Kinds returns all registered address kinds, in order of registration.
+func Kinds() []KindInfo {
+	kindLock.RLock()
+	defer kindLock.RUnlock()
+	return append([]KindInfo(nil), kinds...)
+}
+
+// lookupKind returns the registry entry for a kind byte
+func lookupKind(k byte) (KindInfo, bool) {
+	kindLock.RLock()
+	defer kindLock.RUnlock()
+	idx, ok := kindIndex[k]
+	if !ok {
+		return KindInfo{}, false
+	}
+	return kinds[idx], true
+}
+
+// String returns the registered name of the kind
+func (k Kind) String() string {
+	info, ok := lookupKind(byte(k))
+	if !ok {
+		return fmt.Sprintf("Kind(%q)", string(k))
+	}
+	return info.Name
+}
+
+// IsValidKind returns true if the last letter of a is one of the currently-valid kinds
+func IsValidKind(k byte) bool {
+	info, ok := lookupKind(k)
+	return ok && !info.Reserved
+}
+
+// ParseKind returns a Kind or an explanation of why the supplied value is not one.
+func ParseKind(i interface{}) (byte, error) {
+	b := byte(0)
+	switch v := i.(type) {
+	case string:
+		if v == "" {
+			return b, fmt.Errorf("empty string is not a valid Kind")
+		}
+		v = strings.ToLower(v)
+		kindLock.RLock()
+		k, ok := kindNameMap[v]
+		kindLock.RUnlock()
+		if ok {
+			b = k
+		} else {
+			b = byte(v[0])
+		}
+	case rune:
+		b = byte(strings.ToLower(string(v))[0])
+	case byte:
+		b = v
+	case int8:
+		b = byte(v)
+	case Kind:
+		b = byte(v)
+	default:
+		return b, fmt.Errorf("Kind cannot be parsed from %T", i)
+	}
+
+	if !IsValidKind(b) {
+		return b, fmt.Errorf("%q is not a valid Kind", string(b))
+	}
+	return b, nil
+}
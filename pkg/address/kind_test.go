@@ -0,0 +1,126 @@
+package address
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestBuiltinKinds(t *testing.T) {
+	var got []byte
+	for _, info := range Kinds() {
+		got = append(got, info.Byte)
+		require.Equal(t, info.Name, Kind(info.Byte).String())
+	}
+	require.Subset(t, got, []byte{
+		KindUser, KindNdau, KindEndowment, KindExchange, KindBPC, KindMarketMaker,
+	})
+	require.Equal(t, byte(KindUser), Kinds()[0].Byte)
+	require.Equal(t, "user", Kind(KindUser).String())
+	require.Equal(t, "marketmaker", Kind(KindMarketMaker).String())
+	require.Equal(t, `Kind("q")`, Kind('q').String())
+
+	for _, info := range Kinds() {
+		k, err := ParseKind(info.Name)
+		require.NoError(t, err)
+		require.Equal(t, info.Byte, k)
+	}
+	k, err := ParseKind("u")
+	require.NoError(t, err)
+	require.Equal(t, KindUser, k)
+	k, err = ParseKind(Kind(KindBPC))
+	require.NoError(t, err)
+	require.Equal(t, KindBPC, k)
+}
+
+func TestRegisterKindInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		info KindInfo
+	}{
+		{"outside alphabet", KindInfo{Byte: 'l', Name: "ledger"}},
+		{"digit outside alphabet", KindInfo{Byte: '0', Name: "zero"}},
+		{"uppercase byte", KindInfo{Byte: 'Q', Name: "queue"}},
+		{"short name", KindInfo{Byte: 'q', Name: "q"}},
+		{"uppercase name", KindInfo{Byte: 'q', Name: "Queue"}},
+		{"byte in use", KindInfo{Byte: KindUser, Name: "person"}},
+		{"name in use", KindInfo{Byte: 'q', Name: "exchange"}},
+		{"byte is an abbreviation", KindInfo{Byte: 'u', Name: "utility"}},
+		{"builtin redescribed", KindInfo{Byte: KindUser, Name: "user", Description: "someone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, RegisterKind(tt.info))
+		})
+	}
+}
+
+func TestRegisterKindIdempotent(t *testing.T) {
+	count := len(Kinds())
+	for _, info := range Kinds() {
+		require.NoError(t, RegisterKind(info))
+	}
+	require.Equal(t, count, len(Kinds()))
+}
+
+func TestRegisterReservedKind(t *testing.T) {
+	info := KindInfo{
+		Byte:        'v',
+		Name:        "validator",
+		Description: "an account operated by a validator",
+		Reserved:    true,
+	}
+	require.NoError(t, RegisterKind(info))
+	require.False(t, IsValidKind('v'))
+	require.Equal(t, "validator", Kind('v').String())
+	require.Contains(t, Kinds(), info)
+
+	key := []byte("this is a sufficiently long key")
+	_, err := Generate('v', key)
+	require.Error(t, err)
+	_, err = ParseKind("validator")
+	require.Error(t, err)
+
+	// the reserved name cannot be taken by another kind
+	require.Error(t, RegisterKind(KindInfo{Byte: 'w', Name: "validator"}))
+
+	info.Reserved = false
+	require.NoError(t, RegisterKind(info))
+	require.True(t, IsValidKind('v'))
+	k, err := ParseKind("validator")
+	require.NoError(t, err)
+	require.Equal(t, byte('v'), k)
+
+	addr, err := Generate('v', key)
+	require.NoError(t, err)
+	require.Equal(t, byte('v'), addr.Kind())
+	_, err = Validate(addr.String())
+	require.NoError(t, err)
+
+	// once active, a kind cannot return to reserved
+	info.Reserved = true
+	require.Error(t, RegisterKind(info))
+}
+
+func TestKindMsgpIsByte(t *testing.T) {
+	k := Kind(KindExchange)
+	bts, err := k.MarshalMsg(nil)
+	require.NoError(t, err)
+	require.Equal(t, msgp.AppendByte(nil, KindExchange), bts)
+
+	var got Kind
+	_, err = got.UnmarshalMsg(bts)
+	require.NoError(t, err)
+	require.Equal(t, k, got)
+}
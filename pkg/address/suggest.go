@@ -0,0 +1,115 @@
+package address
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/b32"
+)
+
+// MaxSuggestEdits is the largest edit distance Suggest will explore.
+//
+// Each additional edit multiplies the search space by roughly 1500, so
+// anything beyond 2 is impractically slow; larger requests are clamped.
+const MaxSuggestEdits = 2
+
+// neighbors calls fn with every string at edit distance 1 from s, where an
+// edit is either the substitution of a single character by another member
+// of the ndau alphabet, or the transposition of two adjacent characters.
+func neighbors(s string, fn func(string)) {
+	buf := []byte(s)
+	for i := range buf {
+		orig := buf[i]
+		for j := 0; j < len(b32.NdauAlphabet); j++ {
+			c := b32.NdauAlphabet[j]
+			if c == orig {
+				continue
+			}
+			buf[i] = c
+			fn(string(buf))
+		}
+		buf[i] = orig
+	}
+	for i := 0; i < len(buf)-1; i++ {
+		if buf[i] == buf[i+1] {
+			continue
+		}
+		buf[i], buf[i+1] = buf[i+1], buf[i]
+		fn(string(buf))
+		buf[i], buf[i+1] = buf[i+1], buf[i]
+	}
+}
+
+// Suggest attempts to repair an invalid address.
+//
+// It explores every string within maxEdits single-character substitutions or
+// adjacent transpositions of addr, and returns those which are valid
+// addresses. Candidates are ranked by edit distance, and lexically within
+// each distance.
+//
+// If addr is already valid, it is returned as the only candidate. If it has
+// the wrong length, no repair is attempted and nil is returned.
+//
+// Typically at most one candidate will be returned for a single-character
+// error, but the 16-bit checksum makes a spurious match possible, so callers
+// should always confirm a suggestion with the user rather than applying it
+// blindly.
+func Suggest(addr string, maxEdits int) []Address {
+	addr = strings.ToLower(strings.TrimSpace(addr))
+	if len(addr) != AddrLength || maxEdits < 0 {
+		return nil
+	}
+	if a, err := Validate(addr); err == nil {
+		return []Address{a}
+	}
+	if maxEdits > MaxSuggestEdits {
+		maxEdits = MaxSuggestEdits
+	}
+
+	// seen only tracks the non-final frontiers: storing every string at the
+	// final distance would cost far more memory than it could ever save.
+	seen := map[string]struct{}{addr: {}}
+	found := make(map[string]struct{})
+	frontier := []string{addr}
+	var out []Address
+
+	for dist := 1; dist <= maxEdits; dist++ {
+		final := dist == maxEdits
+		var next []string
+		var atDist []Address
+		for _, s := range frontier {
+			neighbors(s, func(n string) {
+				if !final {
+					if _, ok := seen[n]; ok {
+						return
+					}
+					seen[n] = struct{}{}
+					next = append(next, n)
+				}
+				if _, ok := found[n]; ok {
+					return
+				}
+				if a, err := Validate(n); err == nil {
+					found[n] = struct{}{}
+					atDist = append(atDist, a)
+				}
+			})
+		}
+		sort.Slice(atDist, func(i, j int) bool {
+			return atDist[i].addr < atDist[j].addr
+		})
+		out = append(out, atDist...)
+		frontier = next
+	}
+	return out
+}
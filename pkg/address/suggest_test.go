@@ -0,0 +1,81 @@
+package address
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const knownAddr = "ndadprx764ciigti8d8whtw2kct733r85qvjukhqhke3dka4"
+
+func requireSuggests(t *testing.T, input string, maxEdits int) {
+	got := Suggest(input, maxEdits)
+	require.NotEmpty(t, got)
+	found := false
+	for _, a := range got {
+		require.NoError(t, a.Revalidate())
+		if a.String() == knownAddr {
+			found = true
+		}
+	}
+	require.True(t, found, "expected %s among %v", knownAddr, got)
+}
+
+func TestSuggestValidAddress(t *testing.T) {
+	got := Suggest(knownAddr, 1)
+	require.Len(t, got, 1)
+	require.Equal(t, knownAddr, got[0].String())
+}
+
+func TestSuggestSubstitution(t *testing.T) {
+	for _, i := range []int{3, 17, 47} {
+		b := []byte(knownAddr)
+		if b[i] == 'z' {
+			b[i] = 'y'
+		} else {
+			b[i] = 'z'
+		}
+		requireSuggests(t, string(b), 1)
+	}
+}
+
+func TestSuggestCharacterOutsideAlphabet(t *testing.T) {
+	// '0' is not in the alphabet; it's a common mistyping of 'o'-ish glyphs
+	b := []byte(knownAddr)
+	b[10] = '0'
+	requireSuggests(t, string(b), 1)
+}
+
+func TestSuggestTransposition(t *testing.T) {
+	b := []byte(knownAddr)
+	b[20], b[21] = b[21], b[20]
+	require.NotEqual(t, knownAddr, string(b))
+	requireSuggests(t, string(b), 1)
+}
+
+func TestSuggestCaseAndWhitespace(t *testing.T) {
+	b := []byte(knownAddr)
+	b[30] = 'z'
+	requireSuggests(t, "  "+string(b)+"\n", 1)
+}
+
+func TestSuggestWrongLength(t *testing.T) {
+	require.Nil(t, Suggest(knownAddr[1:], 1))
+	require.Nil(t, Suggest(knownAddr+"a", 1))
+}
+
+func TestSuggestZeroEdits(t *testing.T) {
+	b := []byte(knownAddr)
+	b[30] = 'z'
+	require.Empty(t, Suggest(string(b), 0))
+}
@@ -0,0 +1,179 @@
+package addressset
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/bits"
+
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/pkg/errors"
+)
+
+// The addressset package implements a compact probabilistic set of
+// addresses: a bloom filter. Contains never reports that an address which
+// was added is absent, but may, with a configurable small probability,
+// report that an address which was never added is present.
+//
+// It is intended for indexers, which must check every address they see
+// against the set of addresses they watch, and for which an occasional false
+// positive only costs a lookup. A set of a million addresses, at a false
+// positive rate of 1%, occupies about 1.2 MB.
+
+// MaxHashes is the greatest number of hash functions a Set may use
+const MaxHashes = 32
+
+// A Set is a bloom filter of addresses
+//
+// Each address is hashed with SHA-256, and the digest is split into two
+// 64-bit hashes, from which the bit indices of the address are derived by
+// double hashing. Sets with the same parameters and the same addresses are
+// therefore identical, and serialize identically, whatever the order in
+// which the addresses were added.
+//
+// Contains is safe to call concurrently, but Add is not safe to call
+// concurrently with any other method.
+type Set struct {
+	// m is the number of bits, which is always a multiple of 64
+	m uint64
+	// k is the number of hash functions
+	k uint8
+	// words stores the bits, in little-endian order
+	words []uint64
+}
+
+// New creates an empty Set sized to hold the expected number of addresses
+// with at most the given false positive rate
+func New(expected uint64, falsePositiveRate float64) (*Set, error) {
+	if expected == 0 {
+		return nil, errors.New("expected number of addresses must be positive")
+	}
+	if !(falsePositiveRate > 0 && falsePositiveRate < 1) {
+		return nil, errors.Errorf("false positive rate must be between 0 and 1; got %g", falsePositiveRate)
+	}
+	// the optimal parameters are m = -n ln p / (ln 2)^2 and k = (m / n) ln 2
+	m := math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	if m > float64(math.MaxInt32)*64 {
+		return nil, errors.Errorf("%d addresses at %g is too large a set", expected, falsePositiveRate)
+	}
+	k := math.Round(m / float64(expected) * math.Ln2)
+	if k < 1 {
+		k = 1
+	}
+	if k > MaxHashes {
+		k = MaxHashes
+	}
+	return NewWithParams(uint64(m), uint8(k))
+}
+
+// NewWithParams creates an empty Set of m bits, rounded up to a multiple of
+// 64, using k hash functions
+func NewWithParams(m uint64, k uint8) (*Set, error) {
+	if m == 0 {
+		return nil, errors.New("number of bits must be positive")
+	}
+	if k == 0 || k > MaxHashes {
+		return nil, errors.Errorf("number of hashes must be between 1 and %d; got %d", MaxHashes, k)
+	}
+	words := (m + 63) / 64
+	if words > math.MaxInt32 {
+		return nil, errors.Errorf("%d bits is too large a set", m)
+	}
+	return &Set{
+		m:     words * 64,
+		k:     k,
+		words: make([]uint64, words),
+	}, nil
+}
+
+// hashes returns the two hashes from which the bit indices of addr derive
+//
+// The second is made odd, so that successive indices never coincide.
+func hashes(addr address.Address) (h1, h2 uint64) {
+	digest := sha256.Sum256([]byte(addr.String()))
+	h1 = binary.LittleEndian.Uint64(digest[:8])
+	h2 = binary.LittleEndian.Uint64(digest[8:16]) | 1
+	return
+}
+
+// Add adds addr to the set
+func (s *Set) Add(addr address.Address) {
+	h1, h2 := hashes(addr)
+	for i := uint64(0); i < uint64(s.k); i++ {
+		idx := (h1 + i*h2) % s.m
+		s.words[idx/64] |= 1 << (idx % 64)
+	}
+}
+
+// Contains is true if addr may have been added to the set, and false if it
+// certainly has not been
+func (s *Set) Contains(addr address.Address) bool {
+	h1, h2 := hashes(addr)
+	for i := uint64(0); i < uint64(s.k); i++ {
+		idx := (h1 + i*h2) % s.m
+		if s.words[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Bits returns the number of bits in the set
+func (s *Set) Bits() uint64 {
+	return s.m
+}
+
+// Hashes returns the number of hash functions the set uses
+func (s *Set) Hashes() uint8 {
+	return s.k
+}
+
+// ones returns the number of bits set
+func (s *Set) ones() uint64 {
+	var n int
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return uint64(n)
+}
+
+// EstimatedCount estimates the number of distinct addresses added to the set
+//
+// It is computed from the proportion of bits set, so it is not exact, and
+// grows without bound as the set saturates.
+func (s *Set) EstimatedCount() float64 {
+	m, k := float64(s.m), float64(s.k)
+	return -m / k * math.Log1p(-float64(s.ones())/m)
+}
+
+// FalsePositiveRate estimates the probability that Contains is true of an
+// address which was never added, given the addresses added so far
+func (s *Set) FalsePositiveRate() float64 {
+	return math.Pow(float64(s.ones())/float64(s.m), float64(s.k))
+}
+
+// Union adds every address in other to s
+//
+// It is an error if the sets' parameters differ.
+func (s *Set) Union(other *Set) error {
+	if s.m != other.m || s.k != other.k {
+		return errors.Errorf(
+			"can't union a set of %d bits and %d hashes with one of %d bits and %d hashes",
+			s.m, s.k, other.m, other.k,
+		)
+	}
+	for i, w := range other.words {
+		s.words[i] |= w
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package addressset
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/stretchr/testify/require"
+)
+
+// addr deterministically generates the nth test address
+func addr(t testing.TB, n uint64) address.Address {
+	data := make([]byte, address.MinDataLength)
+	binary.BigEndian.PutUint64(data, n)
+	a, err := address.Generate(address.KindUser, data)
+	require.NoError(t, err)
+	return a
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(1000000, 0.01)
+	require.NoError(t, err)
+	// about 9.6 bits and 7 hashes per address
+	require.InDelta(t, 9585059, s.Bits(), 64)
+	require.Equal(t, uint64(0), s.Bits()%64)
+	require.Equal(t, uint8(7), s.Hashes())
+
+	for _, tt := range []struct {
+		expected uint64
+		rate     float64
+	}{
+		{0, 0.01},
+		{1000, 0},
+		{1000, 1},
+		{1000, -0.5},
+		{1 << 62, 0.01},
+	} {
+		_, err := New(tt.expected, tt.rate)
+		require.Error(t, err, "%d at %g", tt.expected, tt.rate)
+	}
+
+	_, err = NewWithParams(0, 1)
+	require.Error(t, err)
+	_, err = NewWithParams(64, 0)
+	require.Error(t, err)
+	_, err = NewWithParams(64, MaxHashes+1)
+	require.Error(t, err)
+	s, err = NewWithParams(65, 3)
+	require.NoError(t, err)
+	require.Equal(t, uint64(128), s.Bits())
+}
+
+func TestContains(t *testing.T) {
+	const n = 10000
+	const rate = 0.01
+	s, err := New(n, rate)
+	require.NoError(t, err)
+	for i := uint64(0); i < n; i++ {
+		s.Add(addr(t, i))
+	}
+
+	// no false negatives
+	for i := uint64(0); i < n; i++ {
+		require.True(t, s.Contains(addr(t, i)), "address %d", i)
+	}
+
+	// false positives at about the expected rate
+	var positives int
+	for i := uint64(n); i < 11*n; i++ {
+		if s.Contains(addr(t, i)) {
+			positives++
+		}
+	}
+	require.InDelta(t, rate, float64(positives)/(10*n), rate/4)
+	require.InDelta(t, rate, s.FalsePositiveRate(), rate/4)
+	require.InEpsilon(t, n, s.EstimatedCount(), 0.02)
+
+	// adding an address again changes nothing
+	before, err := s.MarshalBinary()
+	require.NoError(t, err)
+	s.Add(addr(t, 0))
+	after, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, before, after)
+}
+
+func TestEmpty(t *testing.T) {
+	s, err := New(100, 0.001)
+	require.NoError(t, err)
+	require.False(t, s.Contains(addr(t, 1)))
+	require.Equal(t, float64(0), s.EstimatedCount())
+	require.Equal(t, float64(0), s.FalsePositiveRate())
+}
+
+func TestUnion(t *testing.T) {
+	a, err := New(100, 0.01)
+	require.NoError(t, err)
+	b, err := New(100, 0.01)
+	require.NoError(t, err)
+	all, err := New(100, 0.01)
+	require.NoError(t, err)
+	for i := uint64(0); i < 50; i++ {
+		a.Add(addr(t, i))
+		b.Add(addr(t, i+50))
+		all.Add(addr(t, i))
+		all.Add(addr(t, i+50))
+	}
+
+	// the union is the set of all the addresses, in any order
+	require.NoError(t, a.Union(b))
+	require.Equal(t, all, a)
+
+	other, err := New(1000, 0.01)
+	require.NoError(t, err)
+	require.Error(t, a.Union(other))
+}
+
+func BenchmarkContains(b *testing.B) {
+	s, err := New(1000000, 0.01)
+	require.NoError(b, err)
+	a := addr(b, 1)
+	s.Add(a)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Contains(a)
+	}
+}
@@ -0,0 +1,167 @@
+package addressset
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// The binary form of a Set is:
+//
+//   version  1 byte, currently 1
+//   k        1 byte
+//   m        8 bytes, big-endian
+//   words    m / 8 bytes: each word of bits, little-endian
+//
+// The text form is the standard base64 encoding of the binary form, and the
+// msgp form is a msgpack bin containing it.
+
+const (
+	version    = 1
+	headerSize = 10
+)
+
+// ensure that Set implements the standard marshal types
+//
+// Because it implements the text interfaces, encoding/json serializes a
+// Set as a JSON string containing its text form.
+var _ encoding.BinaryMarshaler = (*Set)(nil)
+var _ encoding.BinaryUnmarshaler = (*Set)(nil)
+var _ encoding.TextMarshaler = (*Set)(nil)
+var _ encoding.TextUnmarshaler = (*Set)(nil)
+var _ msgp.Marshaler = (*Set)(nil)
+var _ msgp.Unmarshaler = (*Set)(nil)
+var _ msgp.Encodable = (*Set)(nil)
+var _ msgp.Decodable = (*Set)(nil)
+var _ msgp.Sizer = (*Set)(nil)
+
+// binarySize is the length of the binary form
+func (s *Set) binarySize() int {
+	return headerSize + 8*len(s.words)
+}
+
+// appendBinary appends the binary form to out
+func (s *Set) appendBinary(out []byte) []byte {
+	var word [8]byte
+	out = append(out, version, s.k)
+	binary.BigEndian.PutUint64(word[:], s.m)
+	out = append(out, word[:]...)
+	for _, w := range s.words {
+		binary.LittleEndian.PutUint64(word[:], w)
+		out = append(out, word[:]...)
+	}
+	return out
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (s *Set) MarshalBinary() ([]byte, error) {
+	return s.appendBinary(make([]byte, 0, s.binarySize())), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler
+//
+// On error, s is unchanged.
+func (s *Set) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize {
+		return errors.New("address set data too short")
+	}
+	if data[0] != version {
+		return errors.Errorf("unknown address set version %d", data[0])
+	}
+	m := binary.BigEndian.Uint64(data[2:headerSize])
+	if m%64 != 0 {
+		return errors.Errorf("address set size %d is not a multiple of 64 bits", m)
+	}
+	if uint64(len(data)-headerSize) != m/8 {
+		return errors.Errorf("address set of %d bits has %d bytes of data", m, len(data)-headerSize)
+	}
+	set, err := NewWithParams(m, data[1])
+	if err != nil {
+		return errors.Wrap(err, "decoding address set")
+	}
+	for i := range set.words {
+		set.words[i] = binary.LittleEndian.Uint64(data[headerSize+8*i:])
+	}
+	*s = *set
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s *Set) MarshalText() ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	text := make([]byte, base64.StdEncoding.EncodedLen(len(data)))
+	base64.StdEncoding.Encode(text, data)
+	return text, nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Set) UnmarshalText(text []byte) error {
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
+	n, err := base64.StdEncoding.Decode(data, text)
+	if err != nil {
+		return errors.Wrap(err, "decoding address set base64")
+	}
+	return s.UnmarshalBinary(data[:n])
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (s *Set) MarshalMsg(in []byte) ([]byte, error) {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return in, err
+	}
+	return msgp.AppendBytes(in, data), nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (s *Set) UnmarshalMsg(in []byte) ([]byte, error) {
+	data, leftover, err := msgp.ReadBytesZC(in)
+	if err != nil {
+		return in, errors.Wrap(err, "reading address set bytes")
+	}
+	err = s.UnmarshalBinary(data)
+	if err != nil {
+		return in, err
+	}
+	return leftover, nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (s *Set) EncodeMsg(en *msgp.Writer) error {
+	data, err := s.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return en.WriteBytes(data)
+}
+
+// DecodeMsg implements msgp.Decodable
+func (s *Set) DecodeMsg(dc *msgp.Reader) error {
+	data, err := dc.ReadBytes(nil)
+	if err != nil {
+		return errors.Wrap(err, "reading address set bytes")
+	}
+	return s.UnmarshalBinary(data)
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+// Msgsize implements msgp.Sizer
+func (s *Set) Msgsize() int {
+	return msgp.BytesPrefixSize + s.binarySize()
+}
@@ -0,0 +1,115 @@
+package addressset
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// filled returns a set containing n test addresses
+func filled(t *testing.T, n uint64) *Set {
+	s, err := New(n, 0.01)
+	require.NoError(t, err)
+	for i := uint64(0); i < n; i++ {
+		s.Add(addr(t, i))
+	}
+	return s
+}
+
+func TestBinary(t *testing.T) {
+	s := filled(t, 100)
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	require.Len(t, data, headerSize+int(s.Bits()/8))
+
+	var got Set
+	require.NoError(t, got.UnmarshalBinary(data))
+	require.Equal(t, s, &got)
+	for i := uint64(0); i < 100; i++ {
+		require.True(t, got.Contains(addr(t, i)))
+	}
+}
+
+func TestBinaryInvalid(t *testing.T) {
+	data, err := filled(t, 100).MarshalBinary()
+	require.NoError(t, err)
+	corrupt := func(f func(d []byte) []byte) []byte {
+		return f(append([]byte(nil), data...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", data[:headerSize-1]},
+		{"truncated", data[:len(data)-1]},
+		{"extended", append(append([]byte(nil), data...), 0)},
+		{"version", corrupt(func(d []byte) []byte { d[0] = 2; return d })},
+		{"no hashes", corrupt(func(d []byte) []byte { d[1] = 0; return d })},
+		{"too many hashes", corrupt(func(d []byte) []byte { d[1] = MaxHashes + 1; return d })},
+		{"ragged size", corrupt(func(d []byte) []byte { d[headerSize-1]++; return d })},
+		{"no bits", []byte{version, 1, 0, 0, 0, 0, 0, 0, 0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := filled(t, 3)
+			before := *s
+			require.Error(t, s.UnmarshalBinary(tt.data))
+			require.Equal(t, before, *s)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type wrapper struct {
+		Watched *Set
+	}
+	w := wrapper{Watched: filled(t, 20)}
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+
+	var got wrapper
+	require.NoError(t, json.Unmarshal(data, &got))
+	require.Equal(t, w, got)
+
+	require.Error(t, json.Unmarshal([]byte(`{"Watched":"not base64!"}`), &got))
+}
+
+func TestMsgp(t *testing.T) {
+	s := filled(t, 50)
+	bts, err := s.MarshalMsg(nil)
+	require.NoError(t, err)
+	require.True(t, len(bts) <= s.Msgsize())
+
+	var got Set
+	leftover, err := got.UnmarshalMsg(append(bts, 0xc0))
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xc0}, leftover)
+	require.Equal(t, s, &got)
+
+	var buf bytes.Buffer
+	en := msgp.NewWriter(&buf)
+	require.NoError(t, s.EncodeMsg(en))
+	require.NoError(t, en.Flush())
+	require.Equal(t, bts, buf.Bytes())
+
+	var decoded Set
+	require.NoError(t, decoded.DecodeMsg(msgp.NewReader(&buf)))
+	require.Equal(t, s, &decoded)
+
+	_, err = got.UnmarshalMsg(bts[:len(bts)-1])
+	require.Error(t, err)
+}
@@ -12,7 +12,9 @@ package b32
 
 import (
 	"encoding/base32"
+	"fmt"
 	"strings"
+	"unicode"
 )
 
 // NdauAlphabet is the encoding alphabet we use for byte32 encoding
@@ -32,8 +34,92 @@ func Encode(b []byte) string {
 	return r
 }
 
+// A CorruptInputError reports the first character of the input which could
+// not be decoded, so that users can be told where their typo is.
+type CorruptInputError struct {
+	// Index is the position of the offending character, counting characters
+	// rather than bytes from 0. It equals the length of the input when the
+	// input is too short.
+	Index int
+	// Char is the offending character, or 0 if the input is too short
+	Char rune
+}
+
+func (e CorruptInputError) Error() string {
+	if e.Char == 0 {
+		return fmt.Sprintf("b32 input truncated at character %d", e.Index)
+	}
+	return fmt.Sprintf("illegal b32 character %q at character %d", e.Char, e.Index)
+}
+
+// ambiguous maps characters excluded from the alphabet to the character of
+// the alphabet they most resemble. There is no mapping for 0 or o: both are
+// excluded, and nothing in the alphabet looks enough like them.
+var ambiguous = map[rune]rune{
+	'1': 'i',
+	'l': 'i',
+}
+
 // Decode converts a string back to a byte stream; case is insignificant.
+//
+// If s is not valid, the error is a CorruptInputError.
 func Decode(s string) ([]byte, error) {
+	return decode(s, false)
+}
+
+// DecodeLenient is like Decode, but first replaces the characters which look
+// like characters of the alphabet, such as l for i, with those characters.
+//
+// It is for decoding strings typed in by people; strings which were copied
+// or generated should use Decode.
+func DecodeLenient(s string) ([]byte, error) {
+	return decode(s, true)
+}
+
+func decode(s string, lenient bool) ([]byte, error) {
+	// checking each character first lets us report errors by character,
+	// regardless of how many bytes it takes
+	var normal strings.Builder
+	normal.Grow(len(s))
+	// at holds the index in s of each character of normal
+	at := make([]int, 0, len(s))
+	index := 0
+	for _, c := range s {
+		if c == '\r' || c == '\n' {
+			// like encoding/base32, ignore line breaks, so that keys
+			// wrapped when pasted still decode
+			index++
+			continue
+		}
+		lc := unicode.ToLower(c)
+		if r, ok := ambiguous[lc]; lenient && ok {
+			lc = r
+		}
+		if lc != base32.StdPadding && !strings.ContainsRune(NdauAlphabet, lc) {
+			return nil, CorruptInputError{Index: index, Char: c}
+		}
+		normal.WriteRune(lc)
+		at = append(at, index)
+		index++
+	}
+
+	// encoded data, with any padding, is always a whole number of 8
+	// character blocks
+	if len(at)%8 != 0 {
+		return nil, CorruptInputError{Index: index}
+	}
+
 	enc := base32.NewEncoding(NdauAlphabet)
-	return enc.DecodeString(strings.ToLower(s))
+	b, err := enc.DecodeString(normal.String())
+	if cie, ok := err.(base32.CorruptInputError); ok {
+		// the normalized string is all ASCII, so its byte offsets are also
+		// its character indices
+		e := CorruptInputError{Index: index}
+		if int(cie) < len(at) {
+			e.Index = at[cie]
+			e.Char = []rune(s)[e.Index]
+		}
+		return nil, e
+	}
+	return b, err
 }
@@ -11,8 +11,10 @@ package b32
 
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestEncode(t *testing.T) {
@@ -50,6 +52,8 @@ func TestDecode(t *testing.T) {
 		{"e", "npvt9999", []byte{99, 103, 31, 255, 255}, false},
 		{"f", "tpubaaaa", []byte{139, 100, 16, 0, 0}, false},
 		{"g", "tpvt9999", []byte{139, 103, 31, 255, 255}, false},
+		{"wrapped", "aeba\ngbaf\r\n", []byte{1, 2, 3, 4, 5}, false},
+		{"wrapped between blocks", "aaaaaaaa\r\naebagbaf", []byte{0, 0, 0, 0, 0, 1, 2, 3, 4, 5}, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -64,3 +68,90 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want CorruptInputError
+	}{
+		{"excluded letter", "aebagbof", CorruptInputError{6, 'o'}},
+		{"excluded digit", "aeba0baf", CorruptInputError{4, '0'}},
+		{"ambiguous is strict", "aeb1gbaf", CorruptInputError{3, '1'}},
+		{"uppercase reported as typed", "AEBAGBAL", CorruptInputError{7, 'L'}},
+		{"punctuation", "aeba-gbaf", CorruptInputError{4, '-'}},
+		{"indexed by character", "é1", CorruptInputError{0, 'é'}},
+		{"after multibyte", "aebagbafé", CorruptInputError{8, 'é'}},
+		{"truncated", "aebagba", CorruptInputError{Index: 7}},
+		{"misplaced padding", "ae=agbaf", CorruptInputError{2, '='}},
+		{"indexed across line breaks", "aeba\r\ngbao", CorruptInputError{9, 'o'}},
+		{"truncated across line breaks", "aeba\ngba", CorruptInputError{Index: 8}},
+		{"misplaced padding across line breaks", "ae\n=agbaf", CorruptInputError{3, '='}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(tt.s)
+			if err != tt.want {
+				t.Errorf("Decode() error = %#v, want %#v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeLenient(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []byte
+		wantErr error
+	}{
+		{"exact", "aebagbaf", []byte{1, 2, 3, 4, 5}, nil},
+		{"one for i", "1aaaaaaa", []byte{0x40, 0, 0, 0, 0}, nil},
+		{"l for i", "laaaaaaa", []byte{0x40, 0, 0, 0, 0}, nil},
+		{"L for i", "Laaaaaaa", []byte{0x40, 0, 0, 0, 0}, nil},
+		{"o has no mapping", "oaaaaaaa", nil, CorruptInputError{0, 'o'}},
+		{"0 has no mapping", "aaaaaaa0", nil, CorruptInputError{7, '0'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeLenient(tt.s)
+			if err != tt.wantErr {
+				t.Errorf("DecodeLenient() error = %#v, want %#v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodeLenient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzDecode checks that Decode either reports where its input is corrupt,
+// or returns bytes which roundtrip, and that DecodeLenient accepts all that
+// Decode does
+func FuzzDecode(f *testing.F) {
+	for _, s := range []string{"", "aebagbaf", "aaaaaaaa", "npubaaaa", "npvt9999", "NPVT9999", "aebagba", "aeba0baf", "aebl1baf", "ae======", "aeb=gbaf", "aeba\ngbaf"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		b, err := Decode(s)
+		if err != nil {
+			cie, ok := err.(CorruptInputError)
+			if !ok {
+				t.Fatalf("Decode(%q) error is %T, not CorruptInputError: %s", s, err, err)
+			}
+			if cie.Index < 0 || cie.Index > utf8.RuneCountInString(s) {
+				t.Errorf("Decode(%q) reported index %d out of range", s, cie.Index)
+			}
+			return
+		}
+		got, err := Decode(Encode(b))
+		if err != nil || !bytes.Equal(got, b) {
+			t.Errorf("roundtrip of %q: got %x, %v; want %x", s, got, err, b)
+		}
+		lenient, err := DecodeLenient(s)
+		if err != nil || !bytes.Equal(lenient, b) {
+			t.Errorf("DecodeLenient(%q) = %x, %v; want %x", s, lenient, err, b)
+		}
+	})
+}
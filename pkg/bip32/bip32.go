@@ -59,14 +59,26 @@ var (
 
 // masterKey is the master key used along with a random seed used to generate
 // the master node in the hierarchical tree.
+//
+// [bip32] specifies "Bitcoin seed"; ndau keys are not interchangeable with
+// bitcoin keys.
 var masterKey = []byte("ndau seed")
 
 // NewMaster creates a master secret key and a master chain code per the
-// procedure described [in BIP32][bip32-master].
+// procedure described [in BIP32][bip32-master], using the ndau master key.
 //
 // [bip32]: https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki
 // [bip32-master]: https://github.com/bitcoin/bips/blob/master/bip-0032.mediawiki#Master_key_generation
 func NewMaster(seed []byte) (Il, Ir [32]byte, err error) {
+	return NewMasterWithKey(seed, masterKey)
+}
+
+// NewMasterWithKey is NewMaster, with the given HMAC key in place of the
+// ndau master key.
+//
+// With the key "Bitcoin seed", it computes the master keys of [bip32] itself,
+// which is how its test vectors are checked.
+func NewMasterWithKey(seed, hmacKey []byte) (Il, Ir [32]byte, err error) {
 	// Per [bip32], the seed must be in range [MinSeedBytes, MaxSeedBytes].
 	if len(seed) < MinSeedBytes || len(seed) > MaxSeedBytes {
 		err = ErrInvalidSeedLen
@@ -75,7 +87,7 @@ func NewMaster(seed []byte) (Il, Ir [32]byte, err error) {
 
 	// First take the HMAC-SHA512 of the master key and the seed data:
 	//   I = HMAC-SHA512(Key = "ndau seed", Data = S)
-	hmac512 := hmac.New(sha512.New, masterKey)
+	hmac512 := hmac.New(sha512.New, hmacKey)
 	hmac512.Write(seed)
 	I := hmac512.Sum(nil)
 
@@ -84,11 +96,11 @@ func NewMaster(seed []byte) (Il, Ir [32]byte, err error) {
 	//   Ir = master chain code
 	size := copy(Il[:], I[:len(I)/2])
 	if size != len(Il) {
-		panic("programming error in NewMaster: Il")
+		panic("programming error in NewMasterWithKey: Il")
 	}
 	size = copy(Ir[:], I[len(I)/2:])
 	if size != len(Ir) {
-		panic("programming error in NewMaster: Ir")
+		panic("programming error in NewMasterWithKey: Ir")
 	}
 
 	// Ensure the key in usable.
@@ -129,7 +141,8 @@ func GenerateSeed(length uint8, rng io.Reader) ([]byte, error) {
 	return seed, nil
 }
 
-// PrivateToPublic implements the Private -> Public derivation
+// PrivateToPublic implements the Private -> Public derivation, returning the
+// compressed public key of a private key
 func PrivateToPublic(private []byte) []byte {
 	pkx, pky := btcec.S256().ScalarBaseMult(private)
 	pubKey := btcec.PublicKey{Curve: btcec.S256(), X: pkx, Y: pky}
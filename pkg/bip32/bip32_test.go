@@ -0,0 +1,204 @@
+package bip32
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// bitcoinKey is the master HMAC key of bip32 itself
+var bitcoinKey = []byte("Bitcoin seed")
+
+type vectorStep struct {
+	path      string
+	index     uint32
+	chainCode string
+	private   string
+	public    string
+}
+
+// the test vectors of bip32
+var vectors = []struct {
+	name  string
+	seed  string
+	steps []vectorStep
+}{
+	{"vector 1", "000102030405060708090a0b0c0d0e0f", []vectorStep{
+		{"m", 0,
+			"873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508",
+			"e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+			"0339a36013301597daef41fbe593a02cc513d0b55527ec2df1050e2e8ff49c85c2",
+		},
+		{"m/0H", HardenedKeyStart + 0,
+			"47fdacbd0f1097043b78c63c20c34ef4ed9a111d980047ad16282c7ae6236141",
+			"edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+			"035a784662a4a20a65bf6aab9ae98a6c068a81c52e4b032c0fb5400c706cfccc56",
+		},
+		{"m/0H/1", 1,
+			"2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19",
+			"3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+			"03501e454bf00751f24b1b489aa925215d66af2234e3891c3b21a52bedb3cd711c",
+		},
+		{"m/0H/1/2H", HardenedKeyStart + 2,
+			"04466b9cc8e161e966409ca52986c584f07e9dc81f735db683c3ff6ec7b1503f",
+			"cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca",
+			"0357bfe1e341d01c69fe5654309956cbea516822fba8a601743a012a7896ee8dc2",
+		},
+		{"m/0H/1/2H/2", 2,
+			"cfb71883f01676f587d023cc53a35bc7f88f724b1f8c2892ac1275ac822a3edd",
+			"0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4",
+			"02e8445082a72f29b75ca48748a914df60622a609cacfce8ed0e35804560741d29",
+		},
+		{"m/0H/1/2H/2/1000000000", 1000000000,
+			"c783e67b921d2beb8f6b389cc646d7263b4145701dadd2161548a8b078e65e9e",
+			"471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8",
+			"022a471424da5e657499d1ff51cb43c47481a03b1e77f951fe64cec9f5a48f7011",
+		},
+	}},
+	{"vector 2", "fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542", []vectorStep{
+		{"m", 0,
+			"60499f801b896d83179a4374aeb7822aaeaceaa0db1f85ee3e904c4defbd9689",
+			"4b03d6fc340455b363f51020ad3ecca4f0850280cf436c70c727923f6db46c3e",
+			"03cbcaa9c98c877a26977d00825c956a238e8dddfbd322cce4f74b0b5bd6ace4a7",
+		},
+		{"m/0", 0,
+			"f0909affaa7ee7abe5dd4e100598d4dc53cd709d5a5c2cac40e7412f232f7c9c",
+			"abe74a98f6c7eabee0428f53798f0ab8aa1bd37873999041703c742f15ac7e1e",
+			"02fc9e5af0ac8d9b3cecfe2a888e2117ba3d089d8585886c9c826b6b22a98d12ea",
+		},
+	}},
+	// the leading zeros of private keys are retained
+	{"vector 3", "4b381541583be4423346c643850da4b320e46a87ae3d2a4e6da11eba819cd4acba45d239319ac14f863b8d5ab5a0d0c64d2e8a1e7d1457df2e5a3c51c73235be", []vectorStep{
+		{"m", 0,
+			"01d28a3e53cffa419ec122c968b3259e16b65076495494d97cae10bbfec3c36f",
+			"00ddb80b067e0d4993197fe10f2657a844a384589847602d56f0c629c81aae32",
+			"03683af1ba5743bdfc798cf814efeeab2735ec52d95eced528e692b8e34c4e5669",
+		},
+		{"m/0H", HardenedKeyStart + 0,
+			"e5fea12a97b927fc9dc3d2cb0d1ea1cf50aa5a1fdc1f933e8906bb38df3377bd",
+			"491f7a2eebc7b57028e0d3faa0acda02e75c33b03c48fb288c41e2ea44e1daef",
+			"026557fdda1d5d43d79611f784780471f086d58e8126b8c40acb82272a7712e7f2",
+		},
+	}},
+}
+
+func TestVectors(t *testing.T) {
+	for _, v := range vectors {
+		t.Run(v.name, func(t *testing.T) {
+			il, ir, err := NewMasterWithKey(unhex(t, v.seed), bitcoinKey)
+			require.NoError(t, err)
+			private, chainCode := il[:], ir[:]
+			public := PrivateToPublic(private)
+			for i, step := range v.steps {
+				if i > 0 {
+					childChainCode := chainCode
+					if step.index < HardenedKeyStart {
+						// public derivation agrees with private derivation
+						var childPublic []byte
+						childPublic, childChainCode, err = CKDpub(public, chainCode, step.index)
+						require.NoError(t, err, step.path)
+						require.Equal(t, step.public, hex.EncodeToString(childPublic), step.path)
+					}
+					private, chainCode, err = CKDpriv(private, chainCode, step.index)
+					require.NoError(t, err, step.path)
+					if step.index < HardenedKeyStart {
+						require.Equal(t, childChainCode, chainCode, step.path)
+					}
+					public = PrivateToPublic(private)
+				}
+				require.Equal(t, step.chainCode, hex.EncodeToString(chainCode), step.path)
+				require.Equal(t, step.private, hex.EncodeToString(private), step.path)
+				require.Equal(t, step.public, hex.EncodeToString(public), step.path)
+			}
+		})
+	}
+}
+
+func TestNewMaster(t *testing.T) {
+	seed := unhex(t, vectors[0].seed)
+	il, ir, err := NewMaster(seed)
+	require.NoError(t, err)
+	ndauIl, ndauIr, err := NewMasterWithKey(seed, []byte("ndau seed"))
+	require.NoError(t, err)
+	require.Equal(t, ndauIl, il)
+	require.Equal(t, ndauIr, ir)
+
+	// ndau master keys are not bitcoin master keys
+	bitcoinIl, _, err := NewMasterWithKey(seed, bitcoinKey)
+	require.NoError(t, err)
+	require.NotEqual(t, bitcoinIl, il)
+
+	for _, length := range []int{0, MinSeedBytes - 1, MaxSeedBytes + 1} {
+		_, _, err = NewMaster(make([]byte, length))
+		require.Equal(t, ErrInvalidSeedLen, err)
+	}
+}
+
+func TestDeriver(t *testing.T) {
+	il, ir, err := NewMaster(unhex(t, vectors[0].seed))
+	require.NoError(t, err)
+	private, chainCode := il[:], ir[:]
+	public := PrivateToPublic(private)
+
+	privateD, err := NewDeriver(private, nil, chainCode)
+	require.NoError(t, err)
+	require.Equal(t, public, privateD.PublicKey())
+	publicD, err := NewDeriver(nil, public, chainCode)
+	require.NoError(t, err)
+	require.Equal(t, public, publicD.PublicKey())
+
+	for _, i := range []uint32{0, 1, 2, 7, HardenedKeyStart, HardenedKeyStart + 3} {
+		key, code, err := privateD.Child(i)
+		require.NoError(t, err)
+		wantKey, wantCode, err := CKDpriv(private, chainCode, i)
+		require.NoError(t, err)
+		require.Equal(t, wantKey, key)
+		require.Equal(t, wantCode, code)
+
+		pub, pubCode, err := publicD.Child(i)
+		if i >= HardenedKeyStart {
+			require.Equal(t, ErrDeriveHardFromPublic, err)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, PrivateToPublic(key), pub)
+		require.Equal(t, code, pubCode)
+	}
+
+	_, _, err = CKDpub(public, chainCode, HardenedKeyStart)
+	require.Equal(t, ErrDeriveHardFromPublic, err)
+	_, _, err = CKDpriv(private[1:], chainCode, 0)
+	require.Equal(t, ErrInvalidKey, err)
+	_, _, err = CKDpriv(nil, chainCode, 0)
+	require.Equal(t, ErrInvalidKey, err)
+	_, _, err = CKDpub(public, chainCode[1:], 0)
+	require.Equal(t, ErrInvalidKey, err)
+	_, _, err = CKDpub(bytes.Repeat([]byte{1}, PublicKeyLen), chainCode, 0)
+	require.Error(t, err)
+}
+
+func TestFingerprint(t *testing.T) {
+	public := unhex(t, vectors[0].steps[0].public)
+	fp := Fingerprint(public)
+	require.Len(t, fp, FingerprintLen)
+	require.Equal(t, fp, Fingerprint(public))
+	require.NotEqual(t, fp, Fingerprint(unhex(t, vectors[0].steps[1].public)))
+}
@@ -0,0 +1,243 @@
+package bip32
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ndau/ndaumath/pkg/b32"
+)
+
+const (
+	// PrivateKeyLen is the length in bytes of a private key
+	PrivateKeyLen = 32
+
+	// PublicKeyLen is the length in bytes of a compressed public key
+	PublicKeyLen = 33
+
+	// ChainCodeLen is the length in bytes of a chain code
+	ChainCodeLen = 32
+
+	// FingerprintLen is the length in bytes of a key fingerprint
+	FingerprintLen = 3
+)
+
+var (
+	// ErrDeriveHardFromPublic describes an error in which the caller
+	// attempted to derive a hardened child from a public key.
+	ErrDeriveHardFromPublic = errors.New(
+		"cannot derive a hardened key from a public key")
+
+	// ErrInvalidChild describes an error in which the child at a specific
+	// index is invalid due to the derived key falling outside of the valid
+	// range for secp256k1 private keys.  This error indicates the caller
+	// should simply ignore the invalid child at this index and increment to
+	// the next index.
+	ErrInvalidChild = errors.New("the extended key at this index is invalid")
+
+	// ErrInvalidKey describes an error in which a key or chain code passed
+	// to a derivation function is not of the expected length.
+	ErrInvalidKey = errors.New("invalid key or chain code length")
+)
+
+// Fingerprint returns the fingerprint of a public key, by which the children
+// of the key identify their parent.
+//
+// Unlike in [bip32], this is the 24-bit checksum of SHA-256(public), as used
+// throughout ndau, rather than the first 32 bits of HASH160(public).
+func Fingerprint(public []byte) []byte {
+	sum := sha256.Sum256(public)
+	return b32.Checksum24(sum[:])
+}
+
+// A Deriver derives children of a single parent key.
+//
+// Deriving several children with one Deriver is equivalent to calling CKDpriv
+// or CKDpub for each, but state which depends only on the parent is computed
+// once and reused. A Deriver is not safe for concurrent use.
+type Deriver struct {
+	private []byte // nil for public parents
+	public  []byte // computed on demand for private parents
+	// hmac512 is keyed with the parent's chain code
+	hmac512 hash.Hash
+	data    []byte
+	ilr     []byte
+	// pubKey is the parsed parent key; it is only set for public parents
+	pubKey *btcec.PublicKey
+}
+
+// NewDeriver prepares to derive children of the key with the given chain
+// code.
+//
+// If private is nil, public children of the public key are derived, as by
+// CKDpub. Otherwise, private children of the private key are derived, as by
+// CKDpriv; public may then be nil, or the public key of private, which saves
+// computing it again.
+func NewDeriver(private, public, chainCode []byte) (*Deriver, error) {
+	if len(chainCode) != ChainCodeLen {
+		return nil, ErrInvalidKey
+	}
+	d := Deriver{
+		private: private,
+		public:  public,
+		hmac512: hmac.New(sha512.New, chainCode),
+		data:    make([]byte, PublicKeyLen+4),
+		ilr:     make([]byte, 0, sha512.Size),
+	}
+	if private != nil {
+		if len(private) != PrivateKeyLen {
+			return nil, ErrInvalidKey
+		}
+		return &d, nil
+	}
+	// Convert the serialized compressed parent public key into X and Y
+	// coordinates so it can be added to the intermediate public keys.
+	pubKey, err := btcec.ParsePubKey(public, btcec.S256())
+	if err != nil {
+		return nil, errors.New("could not parse public key: " + err.Error())
+	}
+	d.pubKey = pubKey
+	return &d, nil
+}
+
+// PublicKey returns the compressed public key of the parent
+func (d *Deriver) PublicKey() []byte {
+	if d.public == nil {
+		d.public = PrivateToPublic(d.private)
+	}
+	return d.public
+}
+
+// Child derives the key and chain code of the child at index i.
+//
+// The key is private if the parent is. Indices at or above HardenedKeyStart
+// derive hardened children, which public parents can't.
+func (d *Deriver) Child(i uint32) (key, chainCode []byte, err error) {
+	isChildHardened := i >= HardenedKeyStart
+	if d.private == nil && isChildHardened {
+		return nil, nil, ErrDeriveHardFromPublic
+	}
+
+	// The data used to derive the child key depends on whether or not the
+	// child is hardened per [BIP32].
+	//
+	// For hardened children:
+	//   0x00 || ser256(parentKey) || ser32(i)
+	//
+	// For normal children:
+	//   serP(parentPubKey) || ser32(i)
+	data := d.data
+	if isChildHardened {
+		// When the child is a hardened child, the key is known to be a
+		// private key due to the above early return.  Pad it with a
+		// leading zero as required by [BIP32] for deriving the child.
+		data[0] = 0
+		copy(data[1:], d.private)
+	} else {
+		// This is either a public or private key, but in either case, the
+		// data which is used to derive the child key starts with the
+		// secp256k1 compressed public key bytes.
+		copy(data, d.PublicKey())
+	}
+	binary.BigEndian.PutUint32(data[PublicKeyLen:], i)
+
+	// Take the HMAC-SHA512 of the current key's chain code and the derived
+	// data:
+	//   I = HMAC-SHA512(Key = chainCode, Data = data)
+	d.hmac512.Reset()
+	d.hmac512.Write(data)
+	ilr := d.hmac512.Sum(d.ilr[:0])
+
+	// Split "I" into two 32-byte sequences Il and Ir where:
+	//   Il = intermediate key used to derive the child
+	//   Ir = child chain code
+	il := ilr[:len(ilr)/2]
+	chainCode = append([]byte(nil), ilr[len(ilr)/2:]...)
+
+	// Both derived public or private keys rely on treating the left 32-byte
+	// sequence calculated above (Il) as a 256-bit integer that must be
+	// within the valid range for a secp256k1 private key.  There is a small
+	// chance (< 1 in 2^127) this condition will not hold, and in that case,
+	// a child can't be created for this index and the caller should simply
+	// increment to the next index.
+	ilNum := new(big.Int).SetBytes(il)
+	if ilNum.Cmp(btcec.S256().N) >= 0 || ilNum.Sign() == 0 {
+		return nil, nil, ErrInvalidChild
+	}
+
+	// The algorithm used to derive the child key depends on whether or not
+	// a private or public child is being derived.
+	//
+	// For private children:
+	//   childKey = parse256(Il) + parentKey
+	//
+	// For public children:
+	//   childKey = serP(point(parse256(Il)) + parentKey)
+	if d.private != nil {
+		// Add the parent private key to the intermediate private key to
+		// derive the final child key.
+		keyNum := new(big.Int).SetBytes(d.private)
+		ilNum.Add(ilNum, keyNum)
+		ilNum.Mod(ilNum, btcec.S256().N)
+		// Bytes returns a minimum-length big-endian buffer, trimming leading
+		// zeros, but all keys must be the same length, so they might need to
+		// be put back.
+		key = make([]byte, PrivateKeyLen)
+		b := ilNum.Bytes()
+		copy(key[PrivateKeyLen-len(b):], b)
+		return key, chainCode, nil
+	}
+
+	// Calculate the corresponding intermediate public key for the
+	// intermediate private key.
+	ilx, ily := btcec.S256().ScalarBaseMult(il)
+	if ilx.Sign() == 0 || ily.Sign() == 0 {
+		return nil, nil, ErrInvalidChild
+	}
+
+	// Add the intermediate public key to the parent public key to derive
+	// the final child key.
+	childX, childY := btcec.S256().Add(ilx, ily, d.pubKey.X, d.pubKey.Y)
+	pk := btcec.PublicKey{Curve: btcec.S256(), X: childX, Y: childY}
+	return pk.SerializeCompressed(), chainCode, nil
+}
+
+// CKDpriv derives the private key and chain code of the child at index i of
+// the given private key and chain code, per [bip32].
+func CKDpriv(private, chainCode []byte, i uint32) (childKey, childChainCode []byte, err error) {
+	if private == nil {
+		return nil, nil, ErrInvalidKey
+	}
+	d, err := NewDeriver(private, nil, chainCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.Child(i)
+}
+
+// CKDpub derives the compressed public key and chain code of the child at
+// index i of the given compressed public key and chain code, per [bip32].
+//
+// i must be less than HardenedKeyStart.
+func CKDpub(public, chainCode []byte, i uint32) (childKey, childChainCode []byte, err error) {
+	d, err := NewDeriver(nil, public, chainCode)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d.Child(i)
+}
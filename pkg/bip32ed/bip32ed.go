@@ -0,0 +1,122 @@
+package bip32ed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+// This package implements hierarchical deterministic derivation of Ed25519
+// keys per SLIP-0010:
+// https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+//
+// Unlike secp256k1, Ed25519 has no public derivation: only hardened children
+// can be derived, and only from private keys.
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+
+	"github.com/ndau/ndaumath/pkg/bip32"
+	impl "golang.org/x/crypto/ed25519"
+)
+
+const (
+	// HardenedKeyStart is the index at which a hardened key starts.
+	HardenedKeyStart = bip32.HardenedKeyStart
+
+	// KeyLen is the length in bytes of a private key, which is the seed of
+	// an Ed25519 keypair, and also of a public key.
+	KeyLen = 32
+)
+
+// ErrHardenedOnly describes an error in which the caller attempted to derive
+// a non-hardened Ed25519 child.
+var ErrHardenedOnly = errors.New("ed25519 keys can only derive hardened children")
+
+// masterKey is the HMAC key specified by [slip10] for Ed25519 master
+// generation.
+//
+// Unlike in bip32, we use the standard value, so that keys derived with this
+// package interoperate with other SLIP-0010 implementations.
+var masterKey = []byte("ed25519 seed")
+
+// split divides an HMAC-SHA512 output into its left and right halves
+func split(I []byte) (Il, Ir [32]byte) {
+	copy(Il[:], I[:len(I)/2])
+	copy(Ir[:], I[len(I)/2:])
+	return
+}
+
+// NewMaster creates a master secret key and a master chain code per the
+// procedure described in [slip10].
+//
+// Every 32-byte string is a valid Ed25519 private key, so unlike with
+// secp256k1, no seed is unusable.
+func NewMaster(seed []byte) (Il, Ir [32]byte, err error) {
+	if len(seed) < bip32.MinSeedBytes || len(seed) > bip32.MaxSeedBytes {
+		err = bip32.ErrInvalidSeedLen
+		return
+	}
+
+	//   I = HMAC-SHA512(Key = "ed25519 seed", Data = S)
+	hmac512 := hmac.New(sha512.New, masterKey)
+	hmac512.Write(seed)
+	Il, Ir = split(hmac512.Sum(nil))
+	return
+}
+
+// Child derives the private key and chain code of the child at index i of
+// the given private key and chain code.
+//
+// i must be at least HardenedKeyStart.
+func Child(private, chainCode []byte, i uint32) (Il, Ir [32]byte, err error) {
+	if i < HardenedKeyStart {
+		err = ErrHardenedOnly
+		return
+	}
+	if len(private) != KeyLen {
+		err = errors.New("ed25519 private key must be 32 bytes")
+		return
+	}
+
+	//   I = HMAC-SHA512(Key = chainCode, Data = 0x00 || ser256(private) || ser32(i))
+	data := make([]byte, 1+KeyLen+4)
+	copy(data[1:], private)
+	binary.BigEndian.PutUint32(data[1+KeyLen:], i)
+	hmac512 := hmac.New(sha512.New, chainCode)
+	hmac512.Write(data)
+	Il, Ir = split(hmac512.Sum(nil))
+	return
+}
+
+// expand computes the keypair whose seed is the given private key
+func expand(private []byte) (impl.PublicKey, impl.PrivateKey) {
+	// GenerateKey uses the first 32 bytes it reads as the seed
+	public, expanded, err := impl.GenerateKey(bytes.NewReader(private))
+	if err != nil {
+		// this can only happen if private is too short
+		panic("bip32ed: " + err.Error())
+	}
+	return public, expanded
+}
+
+// Expand returns the 64-byte form of a 32-byte private key, as used by the
+// signature package
+func Expand(private []byte) []byte {
+	_, expanded := expand(private)
+	return expanded
+}
+
+// PrivateToPublic implements the Private -> Public derivation
+func PrivateToPublic(private []byte) []byte {
+	public, _ := expand(private)
+	return public
+}
@@ -0,0 +1,83 @@
+package bip32ed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// SLIP-0010 test vector 1 for ed25519
+func TestVector1(t *testing.T) {
+	steps := []struct {
+		path      string
+		index     uint32
+		chainCode string
+		private   string
+		public    string
+	}{
+		{"m", 0,
+			"90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed",
+		},
+		{"m/0H", HardenedKeyStart + 0,
+			"8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c",
+		},
+		{"m/0H/1H", HardenedKeyStart + 1,
+			"a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14",
+			"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+		},
+	}
+
+	private, chainCode, err := NewMaster(unhex(t, "000102030405060708090a0b0c0d0e0f"))
+	require.NoError(t, err)
+	for i, step := range steps {
+		t.Run(step.path, func(t *testing.T) {
+			if i > 0 {
+				private, chainCode, err = Child(private[:], chainCode[:], step.index)
+				require.NoError(t, err)
+			}
+			require.Equal(t, step.chainCode, hex.EncodeToString(chainCode[:]))
+			require.Equal(t, step.private, hex.EncodeToString(private[:]))
+			require.Equal(t, step.public, hex.EncodeToString(PrivateToPublic(private[:])))
+		})
+	}
+}
+
+func TestChildHardenedOnly(t *testing.T) {
+	private, chainCode, err := NewMaster(make([]byte, 16))
+	require.NoError(t, err)
+	_, _, err = Child(private[:], chainCode[:], 0)
+	require.Equal(t, ErrHardenedOnly, err)
+	_, _, err = Child(private[:], chainCode[:], HardenedKeyStart-1)
+	require.Equal(t, ErrHardenedOnly, err)
+	_, _, err = Child(private[:], chainCode[:], HardenedKeyStart)
+	require.NoError(t, err)
+}
+
+func TestNewMasterSeedLen(t *testing.T) {
+	_, _, err := NewMaster(make([]byte, 15))
+	require.Error(t, err)
+	_, _, err = NewMaster(make([]byte, 65))
+	require.Error(t, err)
+}
@@ -0,0 +1,103 @@
+package bitset256
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// byteLen is the length of a bitset serialized by AsBytes
+const byteLen = 32
+
+// ensure that Bitset256 implements the standard marshal types
+//
+// Because it implements the text interfaces, encoding/json serializes a
+// Bitset256 as a JSON string containing its hex form.
+var _ encoding.TextMarshaler = (*Bitset256)(nil)
+var _ encoding.TextUnmarshaler = (*Bitset256)(nil)
+var _ msgp.Marshaler = (*Bitset256)(nil)
+var _ msgp.Unmarshaler = (*Bitset256)(nil)
+var _ msgp.Encodable = (*Bitset256)(nil)
+var _ msgp.Decodable = (*Bitset256)(nil)
+var _ msgp.Sizer = (*Bitset256)(nil)
+
+// MarshalText implements encoding.TextMarshaler
+//
+// The text form is the same as that of AsHex.
+func (b Bitset256) MarshalText() ([]byte, error) {
+	return []byte(b.AsHex()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *Bitset256) UnmarshalText(text []byte) error {
+	c, err := FromHex(string(text))
+	if err != nil {
+		return errors.Wrap(err, "parsing bitset hex")
+	}
+	*b = *c
+	return nil
+}
+
+// set b from the serialized form produced by AsBytes
+func (b *Bitset256) setBytes(ba []byte) error {
+	c, err := FromBytes(ba)
+	if err != nil {
+		return err
+	}
+	*b = *c
+	return nil
+}
+
+// MarshalMsg implements msgp.Marshaler
+//
+// A Bitset256 is serialized as a msgpack bin of the 32 bytes returned by
+// AsBytes.
+func (b Bitset256) MarshalMsg(in []byte) ([]byte, error) {
+	return msgp.AppendBytes(in, b.AsBytes()), nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (b *Bitset256) UnmarshalMsg(in []byte) ([]byte, error) {
+	var scratch [byteLen]byte
+	ba, leftover, err := msgp.ReadBytesBytes(in, scratch[:0])
+	if err != nil {
+		return in, errors.Wrap(err, "reading bitset bytes")
+	}
+	err = b.setBytes(ba)
+	if err != nil {
+		return in, err
+	}
+	return leftover, nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (b Bitset256) EncodeMsg(en *msgp.Writer) error {
+	return en.WriteBytes(b.AsBytes())
+}
+
+// DecodeMsg implements msgp.Decodable
+func (b *Bitset256) DecodeMsg(dc *msgp.Reader) error {
+	var scratch [byteLen]byte
+	ba, err := dc.ReadBytes(scratch[:0])
+	if err != nil {
+		return errors.Wrap(err, "reading bitset bytes")
+	}
+	return b.setBytes(ba)
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+// Msgsize implements msgp.Sizer
+func (b Bitset256) Msgsize() int {
+	return msgp.BytesPrefixSize + byteLen
+}
@@ -0,0 +1,92 @@
+package bitset256
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestText(t *testing.T) {
+	b := setMultiples(7)
+	text, err := b.MarshalText()
+	assert.Nil(t, err)
+	assert.Equal(t, b.AsHex(), string(text))
+
+	c := New()
+	err = c.UnmarshalText(text)
+	assert.Nil(t, err)
+	assert.Equal(t, b, c)
+
+	err = c.UnmarshalText(text[:len(text)-2])
+	assert.NotNil(t, err)
+	err = c.UnmarshalText([]byte("not hex"))
+	assert.NotNil(t, err)
+}
+
+func TestJSON(t *testing.T) {
+	type wrapper struct {
+		Opcodes Bitset256
+		Ptr     *Bitset256
+	}
+	w := wrapper{Opcodes: *setMultiples(3), Ptr: New(1, 2, 255)}
+	data, err := json.Marshal(w)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"`+w.Ptr.AsHex()+`"`)
+
+	var got wrapper
+	err = json.Unmarshal(data, &got)
+	assert.Nil(t, err)
+	assert.Equal(t, w, got)
+}
+
+func TestMsgp(t *testing.T) {
+	b := setMultiples(5)
+	bts, err := b.MarshalMsg(nil)
+	assert.Nil(t, err)
+	assert.True(t, len(bts) <= b.Msgsize())
+
+	c := New()
+	left, err := c.UnmarshalMsg(append(bts, 0xc0))
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xc0}, left)
+	assert.Equal(t, b, c)
+
+	// truncated data must not be accepted
+	_, err = c.UnmarshalMsg(bts[:len(bts)-1])
+	assert.NotNil(t, err)
+	// the wrong number of bytes must not be accepted
+	_, err = c.UnmarshalMsg(msgp.AppendBytes(nil, make([]byte, 31)))
+	assert.NotNil(t, err)
+}
+
+func TestEncodeDecode(t *testing.T) {
+	b := setMultiples(11)
+	buf := new(bytes.Buffer)
+	w := msgp.NewWriter(buf)
+	err := b.EncodeMsg(w)
+	assert.Nil(t, err)
+	assert.Nil(t, w.Flush())
+
+	// the streaming and buffer forms agree
+	bts, err := b.MarshalMsg(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, bts, buf.Bytes())
+
+	c := New()
+	err = c.DecodeMsg(msgp.NewReader(buf))
+	assert.Nil(t, err)
+	assert.Equal(t, b, c)
+}
@@ -31,6 +31,12 @@ const (
 	// NapuPerNdau is a more human-friendly synonym of QuantaPerUnit
 	NapuPerNdau = QuantaPerUnit
 
+	// Millindau is the number of napu in one thousandth of an ndau
+	Millindau = NapuPerNdau / 1000
+
+	// Microndau is the number of napu in one millionth of an ndau
+	Microndau = NapuPerNdau / 1000000
+
 	// RateDenominator is the implied denominator for interest rates.
 	//
 	// EAI rates are expressed as integers, and integer math is performed
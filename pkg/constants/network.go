@@ -0,0 +1,34 @@
+package constants
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+// Network identifies an ndau network
+type Network int
+
+const (
+	// MainNet is the main ndau network
+	MainNet Network = iota
+	// TestNet is the ndau test network
+	TestNet
+)
+
+// NetworkNames are the names of the networks, as returned by String
+var NetworkNames = map[Network]string{
+	MainNet: "mainnet",
+	TestNet: "testnet",
+}
+
+func (n Network) String() string {
+	if name, ok := NetworkNames[n]; ok {
+		return name
+	}
+	return "unknown network"
+}
@@ -0,0 +1,120 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// A BoundedRate is a Rate which must lie within [Min, Max].
+//
+// Like types.BoundedDuration, it is intended for decoding system variables
+// safely: set the bounds, then decode into it. Decoding fails, leaving Value
+// unchanged, if the decoded rate is out of bounds.
+//
+// Only the Value is serialized, exactly as a plain Rate. Its text form is
+// that of Rate.String, e.g. "1.5%".
+type BoundedRate struct {
+	Value Rate
+	Min   Rate
+	Max   Rate
+}
+
+// ensure BoundedRate implements encoding.Text(Un)Marshaler and the msgp interfaces
+var _ encoding.TextMarshaler = (*BoundedRate)(nil)
+var _ encoding.TextUnmarshaler = (*BoundedRate)(nil)
+var _ msgp.Marshaler = (*BoundedRate)(nil)
+var _ msgp.Unmarshaler = (*BoundedRate)(nil)
+var _ msgp.Encodable = (*BoundedRate)(nil)
+var _ msgp.Decodable = (*BoundedRate)(nil)
+var _ msgp.Sizer = (*BoundedRate)(nil)
+
+// Check returns an error if r is not within the bounds
+func (b BoundedRate) Check(r Rate) error {
+	if b.Min > b.Max {
+		return fmt.Errorf("invalid bounds: min %s exceeds max %s", b.Min, b.Max)
+	}
+	if r < b.Min || r > b.Max {
+		return fmt.Errorf("rate %s out of bounds [%s, %s]", r, b.Min, b.Max)
+	}
+	return nil
+}
+
+// Set the Value to r, if it is within the bounds
+func (b *BoundedRate) Set(r Rate) error {
+	err := b.Check(r)
+	if err != nil {
+		return err
+	}
+	b.Value = r
+	return nil
+}
+
+// String writes the Value as a string
+func (b BoundedRate) String() string {
+	return b.Value.String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b BoundedRate) MarshalText() ([]byte, error) {
+	return []byte(b.Value.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *BoundedRate) UnmarshalText(text []byte) error {
+	r, err := ParseRate(string(text))
+	if err != nil {
+		return err
+	}
+	return b.Set(r)
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (b BoundedRate) MarshalMsg(in []byte) ([]byte, error) {
+	return b.Value.MarshalMsg(in)
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (b *BoundedRate) UnmarshalMsg(in []byte) ([]byte, error) {
+	var r Rate
+	leftover, err := r.UnmarshalMsg(in)
+	if err != nil {
+		return in, err
+	}
+	err = b.Set(r)
+	if err != nil {
+		return in, err
+	}
+	return leftover, nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (b BoundedRate) EncodeMsg(en *msgp.Writer) error {
+	return b.Value.EncodeMsg(en)
+}
+
+// DecodeMsg implements msgp.Decodable
+func (b *BoundedRate) DecodeMsg(dc *msgp.Reader) error {
+	var r Rate
+	err := r.DecodeMsg(dc)
+	if err != nil {
+		return err
+	}
+	return b.Set(r)
+}
+
+// Msgsize implements msgp.Sizer
+func (b BoundedRate) Msgsize() int {
+	return b.Value.Msgsize()
+}
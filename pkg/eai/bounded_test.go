@@ -0,0 +1,78 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestBoundedRateMsgp(t *testing.T) {
+	bounds := BoundedRate{Min: RateFromPercent(1), Max: RateFromPercent(10)}
+	tests := []struct {
+		name    string
+		value   Rate
+		wantErr bool
+	}{
+		{"min", RateFromPercent(1), false},
+		{"max", RateFromPercent(10), false},
+		{"within", RateFromPercent(4), false},
+		{"below", RateFromPercent(1) - 1, true},
+		{"above", RateFromPercent(10) + 1, true},
+		{"negative", -RateFromPercent(4), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bts, err := tt.value.MarshalMsg(nil)
+			require.NoError(t, err)
+
+			b := bounds
+			b.Value = RateFromPercent(2)
+			leftover, err := b.UnmarshalMsg(bts)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, RateFromPercent(2), b.Value)
+			} else {
+				require.NoError(t, err)
+				require.Empty(t, leftover)
+				require.Equal(t, tt.value, b.Value)
+
+				out, err := b.MarshalMsg(nil)
+				require.NoError(t, err)
+				require.Equal(t, bts, out)
+			}
+
+			s := bounds
+			err = s.DecodeMsg(msgp.NewReader(bytes.NewReader(bts)))
+			require.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestBoundedRateText(t *testing.T) {
+	b := BoundedRate{Min: RateFromPercent(1), Max: RateFromPercent(10)}
+	require.NoError(t, b.UnmarshalText([]byte("1.5%")))
+	require.Equal(t, RateFromPercent(3)/2, b.Value)
+	text, err := b.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "1.5%", string(text))
+
+	require.Error(t, b.UnmarshalText([]byte("11%")))
+	require.Error(t, b.UnmarshalText([]byte("0.5%")))
+	require.Error(t, b.UnmarshalText([]byte("lots")))
+	require.Equal(t, RateFromPercent(3)/2, b.Value)
+
+	inverted := BoundedRate{Min: RateFromPercent(10), Max: RateFromPercent(1)}
+	require.Error(t, inverted.Set(RateFromPercent(5)))
+}
@@ -0,0 +1,89 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// An Impact describes the effect of replacing one unlocked rate table with
+// another on an account of a given weighted average age
+type Impact struct {
+	WAA math.Duration
+	// OldRate and NewRate are the rates of each table at WAA
+	OldRate Rate
+	NewRate Rate
+	// OldEAI and NewEAI are the EAI which one ndau, in an unlocked account
+	// aged WAA, earns over the following year under each table.
+	//
+	// The account ages over the year, so it may pass into later rows.
+	OldEAI math.Ndau
+	NewEAI math.Ndau
+}
+
+// RateDelta is the change in rate at WAA
+func (i Impact) RateDelta() Rate {
+	return i.NewRate - i.OldRate
+}
+
+// EAIDelta is the change in annual EAI per ndau
+func (i Impact) EAIDelta() math.Ndau {
+	return i.NewEAI - i.OldEAI
+}
+
+// CompareTables reports the impact of replacing the unlocked rate table
+// oldTable with newTable, at each of the given account ages.
+//
+// The EAI is computed by CalculateWith under opts, exactly as the chain
+// computes it for an account credited once at the end of the year, so
+// published figures match chain behavior.
+func CompareTables(oldTable, newTable RateTable, sampleWAAs []math.Duration, opts CalcOptions) ([]Impact, error) {
+	var scratch CalcScratch
+	annualEAI := func(table RateTable, waa math.Duration) (math.Ndau, error) {
+		// Calculate takes the account's age as of the block time
+		return CalculateWith(
+			&scratch,
+			constants.NapuPerNdau,
+			math.Timestamp(math.Year), 0,
+			waa+math.Year, nil,
+			table,
+			opts,
+		)
+	}
+
+	impacts := make([]Impact, 0, len(sampleWAAs))
+	for _, waa := range sampleWAAs {
+		if waa < 0 {
+			return nil, ErrNegativeWAA
+		}
+		if waa > constants.MaxDuration-math.Year {
+			return nil, errors.Errorf("account age %s too large", waa)
+		}
+		impact := Impact{
+			WAA:     waa,
+			OldRate: oldTable.RateAt(waa),
+			NewRate: newTable.RateAt(waa),
+		}
+		var err error
+		impact.OldEAI, err = annualEAI(oldTable, waa)
+		if err != nil {
+			return nil, errors.Wrapf(err, "old table at %s", waa)
+		}
+		impact.NewEAI, err = annualEAI(newTable, waa)
+		if err != nil {
+			return nil, errors.Wrapf(err, "new table at %s", waa)
+		}
+		impacts = append(impacts, impact)
+	}
+	return impacts, nil
+}
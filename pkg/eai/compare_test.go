@@ -0,0 +1,90 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareTablesFlat(t *testing.T) {
+	oldTable := RateTable{{From: 0, Rate: RateFromPercent(1)}}
+	newTable := RateTable{{From: 0, Rate: RateFromPercent(2)}}
+
+	impacts, err := CompareTables(oldTable, newTable, []math.Duration{0, math.Year}, spec)
+	require.NoError(t, err)
+	require.Len(t, impacts, 2)
+
+	oldEAI, err := ContinuousEAI(constants.NapuPerNdau, RateFromPercent(1), math.Year, spec)
+	require.NoError(t, err)
+	newEAI, err := ContinuousEAI(constants.NapuPerNdau, RateFromPercent(2), math.Year, spec)
+	require.NoError(t, err)
+	for _, impact := range impacts {
+		require.Equal(t, RateFromPercent(1), impact.OldRate)
+		require.Equal(t, RateFromPercent(2), impact.NewRate)
+		require.Equal(t, RateFromPercent(1), impact.RateDelta())
+		// e^0.01 - 1
+		require.Equal(t, math.Ndau(1005017), impact.OldEAI)
+		require.Equal(t, oldEAI, impact.OldEAI)
+		require.Equal(t, newEAI, impact.NewEAI)
+		require.Equal(t, newEAI-oldEAI, impact.EAIDelta())
+	}
+}
+
+func TestCompareTablesMatchesCalculate(t *testing.T) {
+	newTable := append(RateTable(nil), DefaultUnlockedEAI...)
+	for idx := range newTable {
+		newTable[idx].Rate -= RateFromPercent(1)
+	}
+	waas := []math.Duration{0, 3 * math.Month, 1 * math.Year, 5 * math.Year}
+
+	impacts, err := CompareTables(DefaultUnlockedEAI, newTable, waas, spec)
+	require.NoError(t, err)
+	require.Len(t, impacts, len(waas))
+	for idx, impact := range impacts {
+		require.Equal(t, waas[idx], impact.WAA)
+		require.Equal(t, DefaultUnlockedEAI.RateAt(impact.WAA), impact.OldRate)
+		require.Equal(t, newTable.RateAt(impact.WAA), impact.NewRate)
+
+		for _, tt := range []struct {
+			table RateTable
+			eai   math.Ndau
+		}{
+			{DefaultUnlockedEAI, impact.OldEAI},
+			{newTable, impact.NewEAI},
+		} {
+			expect, err := CalculateWith(
+				nil,
+				constants.NapuPerNdau,
+				math.Timestamp(math.Year), 0,
+				impact.WAA+math.Year, nil,
+				tt.table, spec,
+			)
+			require.NoError(t, err)
+			require.Equal(t, expect, tt.eai)
+		}
+		require.True(t, impact.EAIDelta() < 0, impact.WAA)
+	}
+}
+
+func TestCompareTablesErrors(t *testing.T) {
+	impacts, err := CompareTables(DefaultUnlockedEAI, DefaultUnlockedEAI, nil, spec)
+	require.NoError(t, err)
+	require.Empty(t, impacts)
+
+	_, err = CompareTables(DefaultUnlockedEAI, DefaultUnlockedEAI, []math.Duration{-1}, spec)
+	require.Error(t, err)
+	_, err = CompareTables(DefaultUnlockedEAI, DefaultUnlockedEAI, []math.Duration{constants.MaxDuration}, spec)
+	require.Error(t, err)
+}
@@ -0,0 +1,342 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/ndau/ndaumath/pkg/unsigned"
+)
+
+// This file contains utilities which compare EAI, which is continuously
+// compounded, with traditional interest compounded a discrete number of
+// times per year. They exist to help explain EAI; they are not used to
+// calculate it.
+//
+// All factors returned here have an implied denominator of
+// constants.RateDenominator: a factor of 1.0 is returned as RateDenominator.
+
+// ContinuousFactor returns the factor by which a balance grows when
+// continuously compounded at the given rate for the given duration:
+//
+//   factor = e ^ (rate * duration)
+//
+// This is exactly the computation used by Calculate for each period at a
+// constant rate, so results agree with the chain. Negative rates give
+// factors below 1. However, it is an error if the magnitude of
+// rate * duration exceeds 50% over a year: beyond that, the fixed point
+// exponential loses accuracy, and eventually fails.
+func ContinuousFactor(rate Rate, duration math.Duration) (uint64, error) {
+	if duration < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+	exponent, err := unsigned.MulDiv(rateMagnitude(rate), uint64(duration), math.Year)
+	if err != nil {
+		return 0, err
+	}
+	if exponent > constants.RateDenominator/2 {
+		return 0, errors.New("rate * duration too large for accurate calculation")
+	}
+	return continuousFactor(rate, duration, CalcOptions{LongPeriods: true})
+}
+
+// MaxFactorDuration is the longest period at a constant rate for which an
+// EAI factor can be computed. No period on a real chain comes anywhere near
+// it; it exists to bound the work done for malformed inputs, such as those
+// which replay tools may produce.
+const MaxFactorDuration = 1000 * math.Year
+
+// maxExponent bounds rate * duration, with implied denominator
+// constants.RateDenominator: e ^ 17 exceeds the greatest factor representable
+// in a uint64, which is about e ^ 16.73.
+const maxExponent = 17 * constants.RateDenominator
+
+// expFracLimit is the greatest exponent, with implied denominator
+// constants.RateDenominator, for which unsigned.ExpFrac succeeds: beyond
+// about 0.638, its series doesn't converge before its factorial overflows.
+const expFracLimit = 637858346856
+
+// maxDecayPart is the greatest part of an exponent whose factor decayFactor
+// divides by at once: the factor of maxExponent itself doesn't fit.
+const maxDecayPart = 16 * constants.RateDenominator
+
+// rateMagnitude returns the absolute value of r
+//
+// Negating the least Rate wraps around to itself, but converted to a uint64
+// that is 2 ^ 63, which is still the correct magnitude.
+func rateMagnitude(r Rate) uint64 {
+	if r < 0 {
+		return uint64(-r)
+	}
+	return uint64(r)
+}
+
+// continuousFactor computes e ^ (rate * duration)
+//
+// Exponents up to expFracLimit are computed directly by unsigned.ExpFrac, so
+// every factor which could ever be computed is unchanged. Larger exponents,
+// which arise only over periods of many years, are split into nearly equal
+// parts of at most 1/2, whose factors are multiplied together with wide
+// intermediates.
+//
+// Negative rates are computed as the reciprocal of the factor for the
+// corresponding positive rate, unless opts.RejectNegativeRates is set.
+//
+// Unless opts.LongPeriods is set, other rates are computed as they
+// historically were: by ExpFrac alone, with no caps.
+func continuousFactor(rate Rate, duration math.Duration, opts CalcOptions) (uint64, error) {
+	if !opts.LongPeriods && rate >= 0 {
+		exponent, err := unsigned.MulDiv(uint64(rate), uint64(duration), math.Year)
+		if err != nil {
+			return 0, err
+		}
+		return unsigned.ExpFrac(exponent, constants.RateDenominator)
+	}
+	if duration < 0 {
+		return 0, ErrNegativeFactorInput
+	}
+	if err := checkRate(rate, opts); err != nil {
+		return 0, err
+	}
+	if duration > MaxFactorDuration {
+		return 0, ErrDurationTooLong
+	}
+	// see calculateEAIBands for why this is computed in two stages
+	exponent, err := unsigned.MulDiv(rateMagnitude(rate), uint64(duration), math.Year)
+	if rate < 0 {
+		if err != nil {
+			// the exponent doesn't fit in a uint64, so the factor is
+			// far too small to represent
+			return 0, nil
+		}
+		return decayFactor(exponent)
+	}
+	if err != nil || exponent > maxExponent {
+		return 0, ErrFactorOverflow
+	}
+	return expFactor(exponent)
+}
+
+// expFactor computes e ^ exponent, for exponents up to maxExponent
+func expFactor(exponent uint64) (uint64, error) {
+	if exponent <= expFracLimit {
+		return unsigned.ExpFrac(exponent, constants.RateDenominator)
+	}
+	return splitFactor(exponent)
+}
+
+// decayFactor computes e ^ -exponent
+//
+// The exponent is taken in parts of at most maxDecayPart, dividing by the
+// factor of each in turn. Unlike growth, decay can't overflow: past an
+// exponent of about 27.6, the factor simply truncates to 0, which ends the
+// loop however large the exponent.
+func decayFactor(exponent uint64) (uint64, error) {
+	factor := uint64(constants.RateDenominator)
+	for exponent > 0 && factor > 0 {
+		part := exponent
+		if part > maxDecayPart {
+			part = maxDecayPart
+		}
+		partFactor, err := expFactor(part)
+		if err != nil {
+			return 0, err
+		}
+		factor, err = unsigned.MulDiv(factor, constants.RateDenominator, partFactor)
+		if err != nil {
+			return 0, err
+		}
+		exponent -= part
+	}
+	return factor, nil
+}
+
+// splitFactor computes e ^ exponent, for exponents too large for ExpFrac
+func splitFactor(exponent uint64) (uint64, error) {
+	const half = constants.RateDenominator / 2
+	parts := (exponent + half - 1) / half
+	// the first rem parts are one greater than the rest
+	part, rem := exponent/parts, exponent%parts
+
+	partFactor, err := unsigned.ExpFrac(part, constants.RateDenominator)
+	if err != nil {
+		return 0, err
+	}
+	bigFactor, err := unsigned.ExpFrac(part+1, constants.RateDenominator)
+	if err != nil {
+		return 0, err
+	}
+
+	denom := new(big.Int).SetUint64(constants.RateDenominator)
+	factor := new(big.Int).Set(denom)
+	pf := new(big.Int).SetUint64(partFactor)
+	bf := new(big.Int).SetUint64(bigFactor)
+	for i := uint64(0); i < parts; i++ {
+		if i < rem {
+			factor.Mul(factor, bf)
+		} else {
+			factor.Mul(factor, pf)
+		}
+		factor.Quo(factor, denom)
+	}
+	if !factor.IsUint64() {
+		return 0, ErrFactorOverflow
+	}
+	return factor.Uint64(), nil
+}
+
+// PeriodicFactor returns the factor by which a balance grows when
+// compounded periodsPerYear times per year at the given rate for the given
+// duration:
+//
+//   factor = (1 + rate / periodsPerYear) ^ periods
+//
+// If the duration does not contain a whole number of periods, simple
+// interest is applied to the final partial period, which is the usual
+// convention. Negative rates are accepted as they are by ContinuousFactor,
+// provided that the rate per period is no less than -100%.
+func PeriodicFactor(rate Rate, duration math.Duration, periodsPerYear uint64) (uint64, error) {
+	if duration < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+	if periodsPerYear == 0 {
+		return 0, errors.New("periodsPerYear must be positive")
+	}
+	// the magnitude of the rate per period, with implied denominator
+	// RateDenominator
+	periodRate := rateMagnitude(rate) / periodsPerYear
+	periodFactor := constants.RateDenominator + periodRate
+	if rate < 0 {
+		if periodRate > constants.RateDenominator {
+			return 0, errors.New("rate per period must not be less than -100%")
+		}
+		periodFactor = constants.RateDenominator - periodRate
+	}
+
+	// periods = duration * periodsPerYear / Year, split into whole periods
+	// and the fraction of a period remaining
+	whole, part := new(big.Int), new(big.Int)
+	whole.Mul(big.NewInt(int64(duration)), new(big.Int).SetUint64(periodsPerYear))
+	whole.DivMod(whole, big.NewInt(math.Year), part)
+	if !whole.IsUint64() {
+		return 0, errors.New("too many periods")
+	}
+
+	factor, err := powFactor(periodFactor, whole.Uint64())
+	if err != nil {
+		return 0, err
+	}
+	if part.Sign() > 0 {
+		partialRate, err := unsigned.MulDiv(periodRate, part.Uint64(), math.Year)
+		if err != nil {
+			return 0, err
+		}
+		partialFactor := constants.RateDenominator + partialRate
+		if rate < 0 {
+			partialFactor = constants.RateDenominator - partialRate
+		}
+		factor, err = unsigned.MulDiv(factor, partialFactor, constants.RateDenominator)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return factor, nil
+}
+
+// powFactor raises a factor to an integer power by repeated squaring,
+// truncating to the factor denominator at each step
+func powFactor(factor, exp uint64) (uint64, error) {
+	result := uint64(constants.RateDenominator)
+	var err error
+	for exp > 0 {
+		if exp&1 == 1 {
+			result, err = unsigned.MulDiv(result, factor, constants.RateDenominator)
+			if err != nil {
+				return 0, err
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			factor, err = unsigned.MulDiv(factor, factor, constants.RateDenominator)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// checkRate returns ErrNegativeRate if opts forbid the rate
+func checkRate(rate Rate, opts CalcOptions) error {
+	if rate < 0 && opts.RejectNegativeRates {
+		return ErrNegativeRate
+	}
+	return nil
+}
+
+// growth returns the amount by which balance grows under a factor, which is
+// negative if the factor is less than 1, rounded as opts requires
+func growth(balance math.Ndau, factor uint64, opts CalcOptions) (math.Ndau, error) {
+	if balance < 0 {
+		return 0, errors.New("balance must not be negative")
+	}
+	return factorEAI(balance, factor, opts.Rounding)
+}
+
+// ContinuousEAI returns the EAI earned by balance at a constant rate over
+// the given duration.
+//
+// It is identical to the result of CalculateWith, under the same opts, for an
+// unlocked account whose rate did not change during the duration.
+func ContinuousEAI(balance math.Ndau, rate Rate, duration math.Duration, opts CalcOptions) (math.Ndau, error) {
+	err := checkRate(rate, opts)
+	if err != nil {
+		return 0, err
+	}
+	factor, err := ContinuousFactor(rate, duration)
+	if err != nil {
+		return 0, err
+	}
+	return growth(balance, factor, opts)
+}
+
+// PeriodicEAI returns the amount balance would grow at a constant rate
+// over the given duration, if compounded periodsPerYear times per year,
+// rounded as opts requires.
+func PeriodicEAI(balance math.Ndau, rate Rate, duration math.Duration, periodsPerYear uint64, opts CalcOptions) (math.Ndau, error) {
+	err := checkRate(rate, opts)
+	if err != nil {
+		return 0, err
+	}
+	factor, err := PeriodicFactor(rate, duration, periodsPerYear)
+	if err != nil {
+		return 0, err
+	}
+	return growth(balance, factor, opts)
+}
+
+// CompoundingDifference returns how much more balance grows under
+// continuous compounding, as EAI does, than when compounded periodsPerYear
+// times per year, at the same rate over the same duration.
+func CompoundingDifference(balance math.Ndau, rate Rate, duration math.Duration, periodsPerYear uint64, opts CalcOptions) (math.Ndau, error) {
+	continuous, err := ContinuousEAI(balance, rate, duration, opts)
+	if err != nil {
+		return 0, err
+	}
+	periodic, err := PeriodicEAI(balance, rate, duration, periodsPerYear, opts)
+	if err != nil {
+		return 0, err
+	}
+	return continuous - periodic, nil
+}
@@ -0,0 +1,210 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/ndau/ndaumath/pkg/unsigned"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContinuousEAIMatchesCalculate(t *testing.T) {
+	const blockTime = math.Timestamp(10 * math.Year)
+	balance := math.Ndau(12345 * constants.NapuPerNdau)
+	for _, pct := range []uint64{1, 4, 10, 15} {
+		for _, rate := range []Rate{RateFromPercent(pct), -RateFromPercent(pct)} {
+			for _, duration := range []math.Duration{math.Day, 90 * math.Day, math.Year, 3 * math.Year} {
+				table := RateTable{{From: 0, Rate: rate}}
+				want, err := CalculateWith(nil, balance, blockTime, blockTime.Sub(duration), duration, nil, table, spec)
+				require.NoError(t, err)
+				got, err := ContinuousEAI(balance, rate, duration, spec)
+				require.NoError(t, err)
+				require.Equal(t, want, got, "%s for %s", rate, duration)
+			}
+		}
+	}
+
+	// out of the accurate range of the fixed point exponential
+	_, err := ContinuousEAI(balance, RateFromPercent(20), 3*math.Year, spec)
+	require.Error(t, err)
+	_, err = ContinuousEAI(balance, -RateFromPercent(20), 3*math.Year, spec)
+	require.Error(t, err)
+}
+
+func TestPeriodicFactor(t *testing.T) {
+	rate := RateFromPercent(5)
+	tests := []struct {
+		name           string
+		duration       math.Duration
+		periodsPerYear uint64
+		want           float64
+	}{
+		{"annual", math.Year, 1, 1.05},
+		{"two years annual", 2 * math.Year, 1, 1.05 * 1.05},
+		{"monthly", math.Year, 12, gomath.Pow(1+0.05/12, 12)},
+		{"daily", math.Year, 365, gomath.Pow(1+0.05/365, 365)},
+		{"half year annual", math.Year / 2, 1, 1.025},
+		{"18 months annual", 3 * math.Year / 2, 1, 1.05 * 1.025},
+		{"zero", 0, 12, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PeriodicFactor(rate, tt.duration, tt.periodsPerYear)
+			require.NoError(t, err)
+			require.InDelta(t, tt.want, float64(got)/constants.RateDenominator, 1e-9)
+		})
+	}
+
+	// negative rates compound as positive rates do
+	got, err := PeriodicFactor(-rate, math.Year, 12)
+	require.NoError(t, err)
+	require.InDelta(t, gomath.Pow(1-0.05/12, 12), float64(got)/constants.RateDenominator, 1e-9)
+	got, err = PeriodicFactor(-rate, 3*math.Year/2, 1)
+	require.NoError(t, err)
+	require.InDelta(t, 0.95*0.975, float64(got)/constants.RateDenominator, 1e-9)
+
+	_, err = PeriodicFactor(rate, math.Year, 0)
+	require.Error(t, err)
+	_, err = PeriodicFactor(rate, -math.Year, 1)
+	require.Error(t, err)
+	_, err = PeriodicFactor(-RateFromPercent(200), math.Year, 1)
+	require.Error(t, err)
+}
+
+func TestCompoundingDifference(t *testing.T) {
+	balance := math.Ndau(100 * constants.NapuPerNdau)
+	rate := RateFromPercent(5)
+
+	// a single annual period is simple interest: exactly 5 ndau
+	annual, err := PeriodicEAI(balance, rate, math.Year, 1, spec)
+	require.NoError(t, err)
+	require.Equal(t, math.Ndau(5*constants.NapuPerNdau), annual)
+
+	continuous, err := ContinuousEAI(balance, rate, math.Year, spec)
+	require.NoError(t, err)
+	require.InDelta(t, 100*gomath.Expm1(0.05), float64(continuous)/constants.NapuPerNdau, 1e-7)
+
+	// more frequent compounding approaches continuous compounding
+	prev := continuous
+	for _, periods := range []uint64{1, 4, 12, 365, 365 * 24} {
+		diff, err := CompoundingDifference(balance, rate, math.Year, periods, spec)
+		require.NoError(t, err)
+		require.True(t, diff >= 0, "%d periods: %d", periods, diff)
+		require.True(t, diff < prev, "%d periods: %d not less than %d", periods, diff, prev)
+		prev = diff
+	}
+}
+
+func TestContinuousFactorSplits(t *testing.T) {
+	_, err := unsigned.ExpFrac(expFracLimit, constants.RateDenominator)
+	require.NoError(t, err)
+
+	// at 100%, the exponent is the duration in years. Exponents either side
+	// of the limit, and the much larger exponents of long durations, agree
+	// with floating point.
+	rate := RateFromPercent(100)
+	for _, exponent := range []uint64{
+		expFracLimit - 1, expFracLimit, expFracLimit + 1,
+		constants.RateDenominator, 5 * constants.RateDenominator, 16 * constants.RateDenominator,
+	} {
+		duration, err := unsigned.MulDiv(exponent, math.Year, constants.RateDenominator)
+		require.NoError(t, err)
+		got, err := continuousFactor(rate, math.Duration(duration), spec)
+		require.NoError(t, err)
+		want := gomath.Exp(float64(duration) / math.Year)
+		require.InEpsilon(t, want, float64(got)/constants.RateDenominator, 1e-9, "exponent %d", exponent)
+	}
+}
+
+func TestExpFracLimitCertified(t *testing.T) {
+	// every EAI factor computed directly by ExpFrac is within epsilon of the
+	// exact value, whatever the exponent, and much closer than that
+	bound, err := unsigned.CertifyExpFrac(expFracLimit, constants.RateDenominator)
+	require.NoError(t, err)
+	relative, _ := bound.Float64()
+	require.True(t, relative < epsilon, "bound %g is not less than %g", relative, epsilon)
+	require.True(t, relative < 1e-9, "bound %g", relative)
+
+	// the limit is as great as it can be
+	_, err = unsigned.CertifyExpFrac(expFracLimit+1, constants.RateDenominator)
+	require.Error(t, err)
+}
+
+func TestContinuousFactorNegative(t *testing.T) {
+	// at -100%, the exponent is minus the duration in years
+	rate := -RateFromPercent(100)
+	for _, exponent := range []uint64{
+		constants.RateDenominator / 1000000, constants.RateDenominator / 100, expFracLimit, expFracLimit + 1,
+		constants.RateDenominator, 5 * constants.RateDenominator,
+		maxDecayPart, maxDecayPart + 1, 20 * constants.RateDenominator,
+	} {
+		duration, err := unsigned.MulDiv(exponent, math.Year, constants.RateDenominator)
+		require.NoError(t, err)
+		got, err := continuousFactor(rate, math.Duration(duration), spec)
+		require.NoError(t, err)
+		require.True(t, got < constants.RateDenominator, "exponent %d", exponent)
+		want := gomath.Exp(-float64(duration) / math.Year)
+		require.InDelta(t, want, float64(got)/constants.RateDenominator, 1e-11, "exponent %d", exponent)
+
+		// it is the reciprocal of the factor at the positive rate
+		if exponent <= maxDecayPart {
+			growth, err := continuousFactor(-rate, math.Duration(duration), spec)
+			require.NoError(t, err)
+			product, err := unsigned.MulDiv(got, growth, constants.RateDenominator)
+			require.NoError(t, err)
+			require.InDelta(t, constants.RateDenominator, product, float64(growth)/constants.RateDenominator+1, "exponent %d", exponent)
+		}
+	}
+
+	// strong enough decay truncates to nothing, rather than overflowing
+	for _, rate := range []Rate{-RateFromPercent(100), gomath.MinInt64} {
+		got, err := continuousFactor(rate, MaxFactorDuration, spec)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), got)
+	}
+
+	got, err := ContinuousFactor(-RateFromPercent(10), math.Year)
+	require.NoError(t, err)
+	require.InDelta(t, gomath.Exp(-0.1), float64(got)/constants.RateDenominator, 1e-11)
+
+	_, err = continuousFactor(rate, -1, spec)
+	require.Equal(t, ErrNegativeFactorInput, err)
+	_, err = ContinuousFactor(rate, -1)
+	require.Error(t, err)
+}
+
+func TestRejectNegativeRates(t *testing.T) {
+	reject := spec
+	reject.RejectNegativeRates = true
+
+	_, err := continuousFactor(-1, math.Year, reject)
+	require.Equal(t, ErrNegativeRate, err)
+	_, err = ContinuousEAI(100*constants.NapuPerNdau, -RateFromPercent(1), math.Year, reject)
+	require.Equal(t, ErrNegativeRate, err)
+	_, err = PeriodicEAI(100*constants.NapuPerNdau, -RateFromPercent(1), math.Year, 12, reject)
+	require.Equal(t, ErrNegativeRate, err)
+
+	// positive rates are unaffected
+	_, err = continuousFactor(RateFromPercent(1), math.Year, reject)
+	require.NoError(t, err)
+
+	// the policy is the caller's, not the package's
+	_, err = continuousFactor(-1, math.Year, spec)
+	require.NoError(t, err)
+	_, err = ContinuousEAI(100*constants.NapuPerNdau, -RateFromPercent(1), math.Year, spec)
+	require.NoError(t, err)
+	_, err = PeriodicFactor(-RateFromPercent(1), math.Year, 12)
+	require.NoError(t, err)
+}
@@ -0,0 +1,132 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// DefaultTables are the default rate tables, used when the corresponding
+// system variable is absent.
+//
+// The defaults have changed over the life of the chain. Each set of defaults
+// is in effect from its ActiveFrom timestamp until the next set's, so that
+// historical blocks can be replayed deterministically with the defaults which
+// applied when they were created, in the same way that PriceAtUnit10000
+// preserves the original price curve.
+type DefaultTables struct {
+	ActiveFrom   math.Timestamp
+	UnlockedEAI  RateTable
+	LockBonusEAI RateTable
+}
+
+// copy returns a deep copy of these tables
+func (dt DefaultTables) copy() DefaultTables {
+	dt.UnlockedEAI = append(RateTable(nil), dt.UnlockedEAI...)
+	dt.LockBonusEAI = append(RateTable(nil), dt.LockBonusEAI...)
+	return dt
+}
+
+// the eras are sorted by ActiveFrom. The genesis era takes its own copy of
+// the exported defaults, so that modifying those can't rewrite history.
+var (
+	erasLock sync.RWMutex
+	eras     = []DefaultTables{
+		{
+			ActiveFrom:   0,
+			UnlockedEAI:  genesisUnlockedEAI(),
+			LockBonusEAI: genesisLockBonusEAI(),
+		},
+	}
+)
+
+// RegisterDefaultTables adds a new set of default rate tables, which are in
+// effect from their ActiveFrom timestamp until that of the next set.
+//
+// It is an error if a set is already registered at the same timestamp, or if
+// either table is not sorted in strictly increasing order by From, or if the
+// lock bonus table contains a negative rate: see Rate.
+func RegisterDefaultTables(tables DefaultTables) error {
+	if tables.ActiveFrom < 0 {
+		return fmt.Errorf("activation timestamp must not be negative; got %d", tables.ActiveFrom)
+	}
+	err := tables.UnlockedEAI.checkSorted()
+	if err != nil {
+		return errors.Wrap(err, "unlocked EAI")
+	}
+	err = tables.LockBonusEAI.checkSorted()
+	if err != nil {
+		return errors.Wrap(err, "lock bonus EAI")
+	}
+	err = tables.LockBonusEAI.checkBonusRates()
+	if err != nil {
+		return errors.Wrap(err, "lock bonus EAI")
+	}
+
+	erasLock.Lock()
+	defer erasLock.Unlock()
+
+	idx := sort.Search(len(eras), func(i int) bool {
+		return eras[i].ActiveFrom >= tables.ActiveFrom
+	})
+	if idx < len(eras) && eras[idx].ActiveFrom == tables.ActiveFrom {
+		return fmt.Errorf("default tables already registered at %s", tables.ActiveFrom)
+	}
+	eras = append(eras, DefaultTables{})
+	copy(eras[idx+1:], eras[idx:])
+	eras[idx] = tables.copy()
+	return nil
+}
+
+// DefaultTablesAt returns the default rate tables in effect at ts
+//
+// The returned tables are a copy, which the caller may modify freely.
+func DefaultTablesAt(ts math.Timestamp) DefaultTables {
+	erasLock.RLock()
+	defer erasLock.RUnlock()
+
+	// find the first era which activates after ts; the one before it is
+	// in effect. The genesis era is at 0, before any valid timestamp.
+	idx := sort.Search(len(eras), func(i int) bool {
+		return eras[i].ActiveFrom > ts
+	})
+	if idx == 0 {
+		idx = 1
+	}
+	return eras[idx-1].copy()
+}
+
+// checkSorted returns an error if the table is not sorted in strictly
+// increasing order by From
+func (rt RateTable) checkSorted() error {
+	for idx := 1; idx < len(rt); idx++ {
+		if rt[idx].From <= rt[idx-1].From {
+			return fmt.Errorf("row %d: rows must be sorted in increasing order of From", idx)
+		}
+	}
+	return nil
+}
+
+// checkBonusRates returns ErrNegativeBonusRate if any rate in the table,
+// which is a table of lock bonuses, is negative
+func (rt RateTable) checkBonusRates() error {
+	for idx, row := range rt {
+		if row.Rate < 0 {
+			return errors.Wrapf(ErrNegativeBonusRate, "row %d", idx)
+		}
+	}
+	return nil
+}
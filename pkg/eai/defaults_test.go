@@ -0,0 +1,111 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTablesAtGenesis(t *testing.T) {
+	for _, ts := range []math.Timestamp{0, 1, math.Timestamp(20 * math.Year)} {
+		dt := DefaultTablesAt(ts)
+		require.Equal(t, math.Timestamp(0), dt.ActiveFrom)
+		require.Equal(t, DefaultUnlockedEAI, dt.UnlockedEAI)
+		require.Equal(t, DefaultLockBonusEAI, dt.LockBonusEAI)
+	}
+
+	// the caller's copy is independent of the registry
+	dt := DefaultTablesAt(0)
+	dt.UnlockedEAI[0].Rate = 0
+	require.Equal(t, DefaultUnlockedEAI, DefaultTablesAt(0).UnlockedEAI)
+}
+
+func TestRegisterDefaultTables(t *testing.T) {
+	// far enough in the future not to affect other tests
+	const (
+		first  = math.Timestamp(200 * math.Year)
+		second = math.Timestamp(100 * math.Year)
+	)
+	// registered out of order, to check sorting
+	laterTables := DefaultTables{
+		ActiveFrom:   first,
+		UnlockedEAI:  RateTable{{From: 0, Rate: RateFromPercent(3)}},
+		LockBonusEAI: RateTable{{From: math.Duration(math.Year), Rate: RateFromPercent(1)}},
+	}
+	require.NoError(t, RegisterDefaultTables(laterTables))
+	earlierTables := DefaultTables{
+		ActiveFrom:   second,
+		UnlockedEAI:  RateTable{{From: 0, Rate: RateFromPercent(2)}},
+		LockBonusEAI: DefaultLockBonusEAI,
+	}
+	require.NoError(t, RegisterDefaultTables(earlierTables))
+
+	tests := []struct {
+		name string
+		ts   math.Timestamp
+		want math.Timestamp
+	}{
+		{"before second", second - 1, 0},
+		{"at second", second, second},
+		{"between", first - 1, second},
+		{"at first", first, first},
+		{"after first", first + 1, first},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, DefaultTablesAt(tt.ts).ActiveFrom)
+		})
+	}
+	require.Equal(t, laterTables, DefaultTablesAt(first))
+	require.Equal(t, earlierTables, DefaultTablesAt(second))
+
+	// modifying the registered tables afterwards has no effect
+	laterTables.UnlockedEAI[0].Rate = 0
+	require.Equal(t, RateFromPercent(3), DefaultTablesAt(first).UnlockedEAI[0].Rate)
+}
+
+func TestRegisterDefaultTablesInvalid(t *testing.T) {
+	unsorted := RateTable{
+		{From: math.Duration(math.Year), Rate: RateFromPercent(2)},
+		{From: math.Duration(math.Day), Rate: RateFromPercent(1)},
+	}
+	tests := []struct {
+		name   string
+		tables DefaultTables
+	}{
+		{"genesis collision", DefaultTables{ActiveFrom: 0}},
+		{"negative", DefaultTables{ActiveFrom: -1}},
+		{"unsorted unlocked", DefaultTables{ActiveFrom: math.Timestamp(300 * math.Year), UnlockedEAI: unsorted}},
+		{"unsorted lock bonus", DefaultTables{ActiveFrom: math.Timestamp(300 * math.Year), LockBonusEAI: unsorted}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Error(t, RegisterDefaultTables(tt.tables))
+		})
+	}
+}
+
+func TestRegisterDefaultTablesNegativeRates(t *testing.T) {
+	penalty := RateTable{{From: 0, Rate: -RateFromPercent(1)}}
+
+	// lock bonus rates are never negative
+	err := RegisterDefaultTables(DefaultTables{ActiveFrom: math.Timestamp(400 * math.Year), LockBonusEAI: penalty})
+	require.Equal(t, ErrNegativeBonusRate, errors.Cause(err))
+
+	// far enough in the future not to affect other tests
+	err = RegisterDefaultTables(DefaultTables{ActiveFrom: math.Timestamp(400 * math.Year), UnlockedEAI: penalty})
+	require.NoError(t, err)
+	require.Equal(t, penalty, DefaultTablesAt(math.Timestamp(400*math.Year)).UnlockedEAI)
+}
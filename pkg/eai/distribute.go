@@ -0,0 +1,82 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"sort"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/ndau/ndaumath/pkg/unsigned"
+	"github.com/pkg/errors"
+)
+
+// Distribute apportions total among recipients in proportion to weights,
+// such that the outputs always sum exactly to total.
+//
+// It uses the largest remainder method: each recipient first receives the
+// truncated quotient of its share, and the few napu left over are then given,
+// one each, to the recipients whose shares had the largest remainders. Ties
+// go to the recipient which appears first in weights, so the result is
+// deterministic.
+//
+// Recipients with a weight of 0 receive nothing. Errors if weights is empty,
+// all weights are 0, their sum overflows, or total is negative.
+func Distribute(total math.Ndau, weights []uint64) ([]math.Ndau, error) {
+	if len(weights) == 0 {
+		return nil, ndauerr.ErrEmpty
+	}
+	if total < 0 {
+		return nil, errors.New("cannot distribute a negative total")
+	}
+	var sum uint64
+	var err error
+	for _, w := range weights {
+		sum, err = unsigned.Add(sum, w)
+		if err != nil {
+			return nil, errors.Wrap(err, "summing weights")
+		}
+	}
+	if sum == 0 {
+		return nil, errors.New("cannot distribute among weights summing to 0")
+	}
+
+	out := make([]math.Ndau, len(weights))
+	rems := make([]uint64, len(weights))
+	distributed := math.Ndau(0)
+	for i, w := range weights {
+		// w <= sum, so the quotient never exceeds total
+		quot, rem, err := unsigned.MulDivRem(uint64(total), w, sum)
+		if err != nil {
+			return nil, errors.Wrapf(err, "share %d", i)
+		}
+		out[i] = math.Ndau(quot)
+		rems[i] = rem
+		distributed += out[i]
+	}
+
+	// the sum of the remainders is a multiple of sum less than
+	// len(weights) * sum, so fewer than len(weights) napu remain
+	leftover := int(total - distributed)
+	if leftover > 0 {
+		order := make([]int, len(weights))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return rems[order[i]] > rems[order[j]]
+		})
+		for _, i := range order[:leftover] {
+			out[i]++
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDistribute(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   math.Ndau
+		weights []uint64
+		want    []math.Ndau
+		wantErr bool
+	}{
+		{"even", 100, []uint64{1, 1, 1, 1}, []math.Ndau{25, 25, 25, 25}, false},
+		{"thirds", 100, []uint64{1, 1, 1}, []math.Ndau{34, 33, 33}, false},
+		{"largest remainder", 10, []uint64{1, 2, 4}, []math.Ndau{1, 3, 6}, false},
+		{"zero weight", 10, []uint64{0, 1, 1}, []math.Ndau{0, 5, 5}, false},
+		{"single", 12345, []uint64{7}, []math.Ndau{12345}, false},
+		{"zero total", 0, []uint64{1, 2}, []math.Ndau{0, 0}, false},
+		{"less than recipients", 2, []uint64{1, 1, 1}, []math.Ndau{1, 1, 0}, false},
+		{"max total", math.Ndau(1<<63 - 1), []uint64{1, 1}, []math.Ndau{1 << 62, 1<<62 - 1}, false},
+		{"huge weights", 10, []uint64{1<<63 - 1, 1<<63 - 1}, []math.Ndau{5, 5}, false},
+		{"empty", 10, nil, nil, true},
+		{"all zero", 10, []uint64{0, 0}, nil, true},
+		{"negative", -1, []uint64{1}, nil, true},
+		{"weights overflow", 10, []uint64{1 << 63, 1 << 63}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Distribute(tt.total, tt.weights)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDistributeConserves(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 1000; i++ {
+		total := math.Ndau(r.Int63() >> uint(r.Intn(63)))
+		weights := make([]uint64, 1+r.Intn(20))
+		for j := range weights {
+			weights[j] = r.Uint64() >> uint(r.Intn(64)+5)
+		}
+		weights[0]++
+
+		got, err := Distribute(total, weights)
+		require.NoError(t, err)
+		require.Len(t, got, len(weights))
+		sum := math.Ndau(0)
+		for j, n := range got {
+			require.True(t, n >= 0)
+			if weights[j] == 0 {
+				require.Equal(t, math.Ndau(0), n)
+			}
+			sum += n
+		}
+		require.Equal(t, total, sum, "total %d weights %v", total, weights)
+	}
+}
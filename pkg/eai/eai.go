@@ -12,11 +12,156 @@ package eai
 
 import (
 	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
 	math "github.com/ndau/ndaumath/pkg/types"
 	"github.com/ndau/ndaumath/pkg/unsigned"
 	"github.com/pkg/errors"
 )
 
+// Strictness determines how EAI calculations treat account states which
+// can't arise on a well-formed chain. It is set by CalcOptions.Strictness.
+type Strictness int
+
+const (
+	// Lenient calculations treat malformed states as well as they can; for
+	// example, an account whose last EAI calculation is in the future earns
+	// no EAI. This has always been the behavior of this package.
+	Lenient Strictness = iota
+	// Strict calculations reject malformed states with an error
+	Strict
+)
+
+// CalcOptions selects the rules by which EAI is calculated
+//
+// A fix which changes the EAI a calculation produces must take effect on the
+// chain at a chosen height, so that replaying earlier blocks reproduces the
+// EAI they credited. The zero value disables every such fix, calculating EAI
+// as the chain always has; chain code enables each from the height at which
+// it was activated. The options also carry the caller's policy for inputs
+// which the historical rules accept, but which it may choose to reject.
+type CalcOptions struct {
+	// FixUnlockBug ignores a lock once EAI has been calculated since it
+	// expired. It is the fixUnlockBug argument of Calculate.
+	FixUnlockBug bool
+	// Rounding is the rounding of EAI dust to a whole napu. Historically,
+	// it was truncated; the ndau spec requires unsigned.HalfEven.
+	Rounding unsigned.RoundingMode
+	// LongPeriods computes the factors of periods at a constant rate whose
+	// rate * duration is beyond the range of unsigned.ExpFrac, about 0.638,
+	// which arise only over many years, and caps the inputs of every
+	// period, failing with ErrNegativeFactorInput, ErrDurationTooLong, or
+	// ErrFactorOverflow. Historically, every exponent was passed to ExpFrac
+	// as it was, which for such exponents failed, returned nonsense, or
+	// panicked.
+	LongPeriods bool
+	// Strictness is the treatment of malformed account states. The zero
+	// value, Lenient, is the historical behavior.
+	Strictness Strictness
+	// RejectNegativeRates fails every EAI factor calculation whose
+	// effective rate is negative with ErrNegativeRate, where policy forbids
+	// penalty rates. See Rate for which rates may be negative.
+	RejectNegativeRates bool
+}
+
+// These errors are returned by Strict calculations
+var (
+	// ErrFutureLastCalc means that the last EAI calculation is after the
+	// block time
+	ErrFutureLastCalc = errors.New("last EAI calculation is after block time")
+	// ErrNegativeWAA means that the weighted average age is negative
+	ErrNegativeWAA = errors.New("weighted average age is negative")
+	// ErrNegativeTimestamp means that the block time or last EAI calculation
+	// precedes the epoch
+	ErrNegativeTimestamp = errors.New("timestamp precedes epoch")
+	// ErrNegativeNoticePeriod means that a lock's notice period is negative
+	ErrNegativeNoticePeriod = errors.New("lock notice period is negative")
+	// ErrNegativeBonusRate means that a lock's bonus rate is negative
+	ErrNegativeBonusRate = errors.New("lock bonus rate is negative")
+	// ErrNoticeBeforeAccount means that a notified lock's notice was given
+	// before the start of the account implied by its weighted average age:
+	// its UnlocksOn is too early
+	ErrNoticeBeforeAccount = errors.New("lock notified before account creation")
+	// ErrFutureNotice means that a notified lock's notice was given after the
+	// block time: its UnlocksOn is too late
+	ErrFutureNotice = errors.New("lock notified after block time")
+)
+
+// These errors are returned when an EAI factor can't be computed
+var (
+	// ErrNegativeFactorInput means that a duration is negative
+	ErrNegativeFactorInput = errors.New("duration must not be negative")
+	// ErrNegativeRate means that an effective rate is negative while
+	// CalcOptions.RejectNegativeRates is set
+	ErrNegativeRate = errors.New("negative EAI rate rejected by policy")
+	// ErrDurationTooLong means that a period at a constant rate exceeds
+	// MaxFactorDuration
+	ErrDurationTooLong = errors.New("duration too long to compute EAI factor")
+	// ErrFactorOverflow means that an EAI factor is too large to represent.
+	// It is a kind of ndauerr.ErrOverflow.
+	ErrFactorOverflow = ndauerr.Specialize(ndauerr.ErrOverflow, "EAI factor overflows")
+)
+
+// checkState returns an error if opts are Strict and the account state is
+// malformed
+func checkState(blockTime, lastEAICalc math.Timestamp, weightedAverageAge math.Duration, lock Lock, opts CalcOptions) error {
+	if opts.Strictness != Strict {
+		return nil
+	}
+	switch {
+	case blockTime < 0 || lastEAICalc < 0:
+		return ErrNegativeTimestamp
+	case lastEAICalc > blockTime:
+		return ErrFutureLastCalc
+	case weightedAverageAge < 0:
+		return ErrNegativeWAA
+	}
+	return ValidateLockState(lock, weightedAverageAge, blockTime)
+}
+
+// ValidateLockState returns an error if the lock of an account can't have
+// arisen on a well-formed chain, given the account's weighted average age at
+// blockTime. A nil lock is always valid.
+//
+// Notified accounts accept no transfers, so once notice is given, the
+// account's weighted average age grows in step with the block time. Notice
+// must therefore have been given no earlier than blockTime - waa, and no
+// later than blockTime.
+//
+// Strict calculations call this; transaction validation may call it
+// directly, whatever the Strictness of its calculations. Bonus rates are
+// never negative: see Rate.
+func ValidateLockState(lock Lock, waa math.Duration, blockTime math.Timestamp) error {
+	if lock == nil {
+		return nil
+	}
+	switch {
+	case blockTime < 0:
+		return ErrNegativeTimestamp
+	case waa < 0:
+		return ErrNegativeWAA
+	case lock.GetNoticePeriod() < 0:
+		return ErrNegativeNoticePeriod
+	case lock.GetBonusRate() < 0:
+		return ErrNegativeBonusRate
+	}
+	unlocksOn := lock.GetUnlocksOn()
+	if unlocksOn == nil {
+		return nil
+	}
+	if *unlocksOn < 0 {
+		return ErrNegativeTimestamp
+	}
+	// none of the times and durations is negative, so these can't overflow
+	notified := int64(*unlocksOn) - int64(lock.GetNoticePeriod())
+	switch {
+	case notified < int64(blockTime)-int64(waa):
+		return ErrNoticeBeforeAccount
+	case notified > int64(blockTime):
+		return ErrFutureNotice
+	}
+	return nil
+}
+
 // Calculate the EAI due for a given account
 //
 // EAI is not interest. Ndau never earns interest. However,
@@ -38,6 +183,9 @@ import (
 // Continuously compounded interest avoids that issue: both accounts will
 // see the same rate of return; the benefit of the one registered to the
 // frequent node is that it sees the increase more often.
+//
+// Calculate applies the historical rules, apart from fixUnlockBug: see
+// CalcOptions. In particular, it treats malformed account states leniently.
 func Calculate(
 	balance math.Ndau,
 	blockTime, lastEAICalc math.Timestamp,
@@ -46,24 +194,146 @@ func Calculate(
 	ageTable RateTable,
 	fixUnlockBug bool,
 ) (math.Ndau, error) {
-	factor, err := calculateEAIFactor(
+	return CalculateWith(
+		nil,
+		balance,
+		blockTime, lastEAICalc,
+		weightedAverageAge, lock,
+		ageTable,
+		CalcOptions{FixUnlockBug: fixUnlockBug},
+	)
+}
+
+// CalcScratch is reusable working space for CalculateWith
+//
+// The zero value is ready to use. A CalcScratch must not be used by more than
+// one goroutine at a time.
+type CalcScratch struct {
+	slice RateSlice
+	bands eaiBands
+}
+
+// CalculateWith calculates the EAI due for a given account, as Calculate
+// does, but under the rules of opts, and keeping its working data in
+// scratch.
+//
+// Once scratch has grown to fit the age table, it performs no heap
+// allocation at all unless the account's lock has expired since the last EAI
+// calculation. This makes it suitable for crediting EAI to many accounts in
+// turn, reusing the same scratch. If scratch is nil, it works in freshly
+// allocated space.
+func CalculateWith(
+	scratch *CalcScratch,
+	balance math.Ndau,
+	blockTime, lastEAICalc math.Timestamp,
+	weightedAverageAge math.Duration,
+	lock Lock,
+	ageTable RateTable,
+	opts CalcOptions,
+) (math.Ndau, error) {
+	err := checkState(blockTime, lastEAICalc, weightedAverageAge, lock, opts)
+	if err != nil {
+		return 0, err
+	}
+	bands, err := calculateEAIBands(
+		scratch,
 		blockTime,
 		lastEAICalc, weightedAverageAge, lock,
 		ageTable,
-		fixUnlockBug,
+		opts,
 	)
 	if err != nil {
 		return 0, err
 	}
+	return factorEAI(balance, bands.factor(), opts.Rounding)
+}
 
+// factorEAI returns the EAI due on balance under factor, rounding its dust
+// to a whole napu in the given mode
+//
+// Factors below 1 arise from negative rates, and give negative EAI.
+func factorEAI(balance math.Ndau, factor uint64, mode unsigned.RoundingMode) (math.Ndau, error) {
 	// subtract 1 from the factor: we want just the EAI, not the new balance
 	// remember that the factor has an implied divisor of RateDivisor
-	factor -= constants.RateDenominator
-	eai, err := unsigned.MulDiv(uint64(balance), factor, constants.RateDenominator)
+	if factor >= constants.RateDenominator {
+		eai, err := unsigned.MulDivRound(uint64(balance), factor-constants.RateDenominator, constants.RateDenominator, mode)
+		if err != nil {
+			return 0, err
+		}
+		return math.Ndau(eai), nil
+	}
+	// round the magnitude of negative EAI in its place: the modes which
+	// aren't symmetric about 0 swap
+	switch mode {
+	case unsigned.Ceil:
+		mode = unsigned.Floor
+	case unsigned.Floor:
+		mode = unsigned.Ceil
+	}
+	loss, err := unsigned.MulDivRound(uint64(balance), constants.RateDenominator-factor, constants.RateDenominator, mode)
 	if err != nil {
 		return 0, err
 	}
-	return math.Ndau(eai), nil
+	return -math.Ndau(loss), nil
+}
+
+// Attribution is the portion of an EAI calculation earned at a single rate
+type Attribution struct {
+	Rate     Rate
+	Duration math.Duration
+	EAI      math.Ndau
+}
+
+// CalculateAttributed calculates the EAI due for a given account, attributing
+// it to each rate band which contributed to it.
+//
+// The arguments and total are exactly those of CalculateWith. Bands are listed
+// in chronological order, and the EAI of all bands always sums to the total.
+//
+// Because EAI compounds, each band earns not only on the initial balance,
+// but also on the EAI from all previous bands. A band's EAI is the change
+// in the total EAI over the course of that band, which is negative at
+// negative rates.
+func CalculateAttributed(
+	balance math.Ndau,
+	blockTime, lastEAICalc math.Timestamp,
+	weightedAverageAge math.Duration,
+	lock Lock,
+	ageTable RateTable,
+	opts CalcOptions,
+) (math.Ndau, []Attribution, error) {
+	err := checkState(blockTime, lastEAICalc, weightedAverageAge, lock, opts)
+	if err != nil {
+		return 0, nil, err
+	}
+	bands, err := calculateEAIBands(
+		nil,
+		blockTime,
+		lastEAICalc, weightedAverageAge, lock,
+		ageTable,
+		opts,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	attributions := make([]Attribution, 0, len(bands))
+	var total math.Ndau
+	for _, band := range bands {
+		// computing each band's EAI from the cumulative factor keeps the
+		// rounding identical to Calculate, so the bands sum to the total
+		cumulative, err := factorEAI(balance, band.factor, opts.Rounding)
+		if err != nil {
+			return 0, nil, err
+		}
+		attributions = append(attributions, Attribution{
+			Rate:     band.rate,
+			Duration: band.duration,
+			EAI:      cumulative - total,
+		})
+		total = cumulative
+	}
+	return total, attributions, nil
 }
 
 // calculateEAIFactor calculates the EAI factor for a given table
@@ -88,58 +358,120 @@ func calculateEAIFactor(
 	weightedAverageAge math.Duration,
 	lock Lock,
 	unlockedTable RateTable,
-	fixUnlockBug bool,
+	opts CalcOptions,
 ) (uint64, error) {
+	bands, err := calculateEAIBands(
+		nil,
+		blockTime, lastEAICalc,
+		weightedAverageAge, lock,
+		unlockedTable,
+		opts,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return bands.factor(), nil
+}
+
+// an eaiBand is a single period at a constant effective rate
+//
+// factor is cumulative: it is the product of this band's factor and
+// those of all the bands before it.
+type eaiBand struct {
+	rate     Rate
+	duration math.Duration
+	factor   uint64
+}
+
+type eaiBands []eaiBand
+
+// factor returns the cumulative factor of all bands
+func (bs eaiBands) factor() uint64 {
+	if len(bs) == 0 {
+		return constants.RateDenominator // 1.0, effectively
+	}
+	return bs[len(bs)-1].factor
+}
+
+// calculateEAIBands computes the EAI factor band by band
+//
+// See calculateEAIFactor for details. If scratch is not nil, the bands
+// returned are stored in it, and are only valid until its next use.
+func calculateEAIBands(
+	scratch *CalcScratch,
+	blockTime, lastEAICalc math.Timestamp,
+	weightedAverageAge math.Duration,
+	lock Lock,
+	unlockedTable RateTable,
+	opts CalcOptions,
+) (eaiBands, error) {
 	if lock != nil && lock.GetUnlocksOn() != nil && *lock.GetUnlocksOn() < blockTime {
 		// we may need to treat this as two nested calls and return their product
 		// however, we can ignore the lock entirely if we've already calculated EAI
 		// since it unlocked
 		unlockTs := *lock.GetUnlocksOn()
-		if fixUnlockBug && lastEAICalc > unlockTs {
-			return calculateEAIFactor(
+		if opts.FixUnlockBug && lastEAICalc > unlockTs {
+			return calculateEAIBands(
+				scratch,
 				blockTime, lastEAICalc,
 				weightedAverageAge,
 				nil,
 				unlockedTable,
-				fixUnlockBug,
+				opts,
 			)
 		}
 
-		atUnlock, err := calculateEAIFactor(
+		// the two halves can't share scratch space, so they allocate
+		preUnlock, err := calculateEAIBands(
+			nil,
 			unlockTs, lastEAICalc,
 			weightedAverageAge-blockTime.Since(unlockTs),
 			lock,
 			unlockedTable,
-			fixUnlockBug,
+			opts,
 		)
 		if err != nil {
-			return 0, errors.Wrap(err, "calculating preUnlock")
+			return nil, errors.Wrap(err, "calculating preUnlock")
 		}
 
-		postUnlock, err := calculateEAIFactor(
+		postUnlock, err := calculateEAIBands(
+			nil,
 			blockTime, unlockTs,
 			weightedAverageAge,
 			nil,
 			unlockedTable,
-			fixUnlockBug,
+			opts,
 		)
 		if err != nil {
-			return 0, errors.Wrap(err, "calculating postUnlock")
+			return nil, errors.Wrap(err, "calculating postUnlock")
 		}
 
-		factor, err := unsigned.MulDiv(
-			atUnlock,
-			postUnlock,
-			constants.RateDenominator,
-		)
-		if err != nil {
-			return factor, errors.Wrap(err, "calculating composite factor")
+		// the post-unlock bands compound on top of the pre-unlock factor
+		atUnlock := preUnlock.factor()
+		bands := preUnlock
+		for _, band := range postUnlock {
+			band.factor, err = unsigned.MulDiv(
+				atUnlock,
+				band.factor,
+				constants.RateDenominator,
+			)
+			if err != nil {
+				return nil, errors.Wrap(ErrFactorOverflow, "calculating composite factor")
+			}
+			bands = append(bands, band)
 		}
 
-		return factor, err
+		return bands, nil
 	}
 
 	factor := uint64(constants.RateDenominator) // 1.0, effectively
+	var bands eaiBands
+	var rateSlice RateSlice
+	if scratch != nil {
+		bands, rateSlice = scratch.bands[:0], scratch.slice
+	} else {
+		bands = make(eaiBands, 0, len(unlockedTable))
+	}
 
 	lastEAICalcAge := blockTime.Since(lastEAICalc)
 	var offset math.Duration
@@ -155,14 +487,12 @@ func calculateEAIFactor(
 		// at all. Therefore, we set it to 0 to get the correct rate period.
 		from = 0
 	}
-	var rateSlice RateSlice
+	var freeze math.Duration
 	if lock != nil && lock.GetUnlocksOn() != nil {
 		notify := lock.GetUnlocksOn().Sub(lock.GetNoticePeriod())
-		freeze := blockTime.Since(notify)
-		rateSlice = unlockedTable.SliceF(from, weightedAverageAge, offset, freeze)
-	} else {
-		rateSlice = unlockedTable.Slice(from, weightedAverageAge, offset)
+		freeze = blockTime.Since(notify)
 	}
+	rateSlice = unlockedTable.sliceInto(rateSlice, from, weightedAverageAge, offset, freeze)
 	for _, row := range rateSlice {
 		// fmt.Printf("%s @ %s\n", row.Duration.String(), row.Rate.String())
 
@@ -188,21 +518,43 @@ func calculateEAIFactor(
 		if lock != nil {
 			effectiveRate += lock.GetBonusRate()
 		}
-		divisor, err := unsigned.MulDiv(uint64(effectiveRate), uint64(row.Duration), math.Year)
-		if err != nil {
-			return 0, err
-		}
-		rowFactor, err := unsigned.ExpFrac(divisor, constants.RateDenominator)
+		rowFactor, err := continuousFactor(effectiveRate, row.Duration, opts)
 		if err != nil {
-			return 0, err
+			return nil, errors.Wrapf(err, "%s at %s", row.Duration, effectiveRate)
 		}
 		factor, err = unsigned.MulDiv(factor, rowFactor, constants.RateDenominator)
 		if err != nil {
-			return 0, err
+			return nil, errors.Wrapf(ErrFactorOverflow, "compounding %s at %s", row.Duration, effectiveRate)
 		}
+		bands = append(bands, eaiBand{
+			rate:     effectiveRate,
+			duration: row.Duration,
+			factor:   factor,
+		})
+	}
+
+	if scratch != nil {
+		scratch.slice, scratch.bands = rateSlice, bands
 	}
+	return bands, nil
+}
+
+// An EAIRate is an EAI rate broken down into its parts
+type EAIRate struct {
+	// Base is the rate of the unlocked table at EffectiveWAA
+	Base Rate
+	// Bonus is the bonus rate of the lock, or 0 if the account is not
+	// locked, or its notice period has expired
+	Bonus Rate
+	// EffectiveWAA is the WAA at which the base rate is looked up: the WAA,
+	// plus the notice period of a lock, or the time remaining until a
+	// notified lock unlocks
+	EffectiveWAA math.Duration
+}
 
-	return factor, nil
+// Total is the EAI rate: the sum of the base and bonus rates
+func (r EAIRate) Total() Rate {
+	return r.Base + r.Bonus
 }
 
 // CalculateEAIRate accepts a WAA, a lock, a rate table, and a calculation
@@ -218,6 +570,17 @@ func CalculateEAIRate(
 	unlockedTable RateTable,
 	at math.Timestamp,
 ) Rate {
+	return CalculateEAIRateDetails(weightedAverageAge, lock, unlockedTable, at).Total()
+}
+
+// CalculateEAIRateDetails is like CalculateEAIRate, but returns the base
+// rate, bonus rate, and effective WAA separately.
+func CalculateEAIRateDetails(
+	weightedAverageAge math.Duration,
+	lock Lock,
+	unlockedTable RateTable,
+	at math.Timestamp,
+) EAIRate {
 	effectiveWAA := weightedAverageAge
 	if lock != nil {
 		if lock.GetUnlocksOn() == nil {
@@ -235,9 +598,36 @@ func CalculateEAIRate(
 			}
 		}
 	}
-	effectiveRate := unlockedTable.RateAt(effectiveWAA)
+	rate := EAIRate{
+		Base:         unlockedTable.RateAt(effectiveWAA),
+		EffectiveWAA: effectiveWAA,
+	}
 	if lock != nil {
-		effectiveRate += lock.GetBonusRate()
+		rate.Bonus = lock.GetBonusRate()
 	}
-	return effectiveRate
+	return rate
+}
+
+// CalculateEAIRateBP is like CalculateEAIRate, but returns the rate in
+// basis points, truncated: 200 for 2%.
+func CalculateEAIRateBP(
+	weightedAverageAge math.Duration,
+	lock Lock,
+	unlockedTable RateTable,
+	at math.Timestamp,
+) BasisPoints {
+	return CalculateEAIRate(weightedAverageAge, lock, unlockedTable, at).BasisPoints()
+}
+
+// CalculateEAIRateFloat is like CalculateEAIRate, but returns the nominal
+// rate in percent, as a float: 2.0 for 2%.
+//
+// It is intended only for display.
+func CalculateEAIRateFloat(
+	weightedAverageAge math.Duration,
+	lock Lock,
+	unlockedTable RateTable,
+	at math.Timestamp,
+) float64 {
+	return CalculateEAIRate(weightedAverageAge, lock, unlockedTable, at).Percent()
 }
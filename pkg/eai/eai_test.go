@@ -13,6 +13,7 @@ package eai
 import (
 	"encoding/csv"
 	"fmt"
+	gomath "math"
 	"os"
 	"strconv"
 	"testing"
@@ -22,6 +23,8 @@ import (
 	dmath "github.com/ericlagergren/decimal/math"
 	"github.com/ndau/ndaumath/pkg/constants"
 	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/ndau/ndaumath/pkg/unsigned"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,42 +33,13 @@ import (
 // our error values are relatively low
 const epsilon = 1.0 / 1000000
 
-type testLock struct {
-	NoticePeriod math.Duration
-	UnlocksOn    *math.Timestamp
-	Rate         Rate
+// spec is the rules of the ndau spec, with every fix enabled
+var spec = CalcOptions{
+	FixUnlockBug: true,
+	Rounding:     unsigned.HalfEven,
+	LongPeriods:  true,
 }
 
-func newTestLock(period math.Duration, bonusRateTable RateTable) *testLock {
-	return &testLock{
-		NoticePeriod: period,
-		Rate:         bonusRateTable.RateAt(period),
-	}
-}
-
-func (l *testLock) GetNoticePeriod() math.Duration {
-	if l != nil {
-		return l.NoticePeriod
-	}
-	return 0
-}
-
-func (l *testLock) GetUnlocksOn() *math.Timestamp {
-	if l != nil {
-		return l.UnlocksOn
-	}
-	return nil
-}
-
-func (l *testLock) GetBonusRate() Rate {
-	if l != nil {
-		return l.Rate
-	}
-	return Rate(0)
-}
-
-var _ Lock = (*testLock)(nil)
-
 func TestEAIFactorUnlocked(t *testing.T) {
 	// simple tests that the eai factor for unlocked accounts is e ** (rate * time)
 	// there is no period in the rate table shorter than a month, so using a few days
@@ -114,7 +88,7 @@ func TestEAIFactorUnlocked(t *testing.T) {
 	t.Run("0 rate", func(t *testing.T) {
 		zero, err := calculateEAIFactor(
 			blockTime, lastEAICalc, weightedAverageAge, nil,
-			DefaultUnlockedEAI, true,
+			DefaultUnlockedEAI, spec,
 		)
 		require.NoError(t, err)
 		require.InEpsilon(t, expect(t, 0), zero, epsilon)
@@ -134,7 +108,7 @@ func TestEAIFactorUnlocked(t *testing.T) {
 
 			factor, err := calculateEAIFactor(
 				blockTime, lastEAICalc, weightedAverageAge, nil,
-				DefaultUnlockedEAI, true,
+				DefaultUnlockedEAI, spec,
 			)
 			require.NoError(t, err)
 			expectValue := expect(t, rate.Rate)
@@ -207,11 +181,11 @@ func TestEAIFactorLocked(t *testing.T) {
 	}
 
 	// special case for 0 lock rate
-	lock := newTestLock(DefaultLockBonusEAI[0].From-math.Day, DefaultLockBonusEAI)
+	lock := NewBasicLock(DefaultLockBonusEAI[0].From-math.Day, DefaultLockBonusEAI)
 	t.Run("no lock bonus", func(t *testing.T) {
 		factor, err := calculateEAIFactor(
 			blockTime, lastEAICalc, weightedAverageAge, lock,
-			DefaultUnlockedEAI, true,
+			DefaultUnlockedEAI, spec,
 		)
 		require.NoError(t, err)
 		require.InEpsilon(t, expect(lock), factor, epsilon)
@@ -220,10 +194,10 @@ func TestEAIFactorLocked(t *testing.T) {
 	// now test each particular lock bonus rate
 	for idx, lockRate := range DefaultLockBonusEAI {
 		t.Run(fmt.Sprintf("%d", idx), func(t *testing.T) {
-			lock = newTestLock(lockRate.From, DefaultLockBonusEAI)
+			lock = NewBasicLock(lockRate.From, DefaultLockBonusEAI)
 			factor, err := calculateEAIFactor(
 				blockTime, lastEAICalc, weightedAverageAge, lock,
-				DefaultUnlockedEAI, true,
+				DefaultUnlockedEAI, spec,
 			)
 			require.NoError(t, err)
 			require.InEpsilon(t, expect(lock), factor, epsilon)
@@ -500,9 +474,9 @@ func TestEAIFactorSoundness(t *testing.T) {
 			blockTime := math.Timestamp(1 * math.Year)
 			lastEAICalc := blockTime.Sub(scase.lastEAIOffset)
 			weightedAverageAge := math.Duration(123 * math.Day)
-			var lock *testLock
+			var lock *BasicLock
 			if scase.lockPeriod != nil {
-				lock = newTestLock(*scase.lockPeriod, DefaultLockBonusEAI)
+				lock = NewBasicLock(*scase.lockPeriod, DefaultLockBonusEAI)
 				if scase.lockNotifyOffset != nil {
 					uo := blockTime.Add(*scase.lockNotifyOffset)
 					lock.UnlocksOn = &uo
@@ -512,7 +486,7 @@ func TestEAIFactorSoundness(t *testing.T) {
 				blockTime,
 				lastEAICalc, weightedAverageAge,
 				lock,
-				DefaultUnlockedEAI, true,
+				DefaultUnlockedEAI, spec,
 			)
 			require.NoError(t, err)
 
@@ -725,22 +699,20 @@ func TestSoundnessCustomDates(t *testing.T) {
 
 			// calculate the actual value
 			lastEAICalc := scase.blockTime.Sub(scase.lastEAIOffset)
-			var lock *testLock
+			var lock *BasicLock
 			if scase.lockPeriod != nil {
-				lock = newTestLock(*scase.lockPeriod, DefaultLockBonusEAI)
+				lock = NewBasicLock(*scase.lockPeriod, DefaultLockBonusEAI)
 				if scase.unlocksOnOffset != nil {
 					uo := scase.blockTime.Add(*scase.unlocksOnOffset)
-					if uo < scase.blockTime.Sub(scase.weightedAverageAge).Add(lock.NoticePeriod) {
-						t.Fatal("malformed test case: lock older than waa")
-					}
 					lock.UnlocksOn = &uo
+					require.NoError(t, ValidateLockState(lock, scase.weightedAverageAge, scase.blockTime), "malformed test case")
 				}
 			}
 			actual, err := calculateEAIFactor(
 				scase.blockTime,
 				lastEAICalc, scase.weightedAverageAge,
 				lock,
-				DefaultUnlockedEAI, true,
+				DefaultUnlockedEAI, spec,
 			)
 			require.NoError(t, err)
 
@@ -786,18 +758,55 @@ func TestCalculate(t *testing.T) {
 	actual, err := Calculate(
 		1*constants.QuantaPerUnit,
 		blockTime, lastEAICalc, weightedAverageAge,
-		newTestLock(90*math.Day, DefaultLockBonusEAI),
+		NewBasicLock(90*math.Day, DefaultLockBonusEAI),
 		DefaultUnlockedEAI, true,
 	)
 	require.NoError(t, err)
 
 	require.InEpsilon(t, uint64(expected), uint64(actual), epsilon)
+	// historically, the dust was truncated
+	require.Equal(t, expected-1, actual)
+
+	actual, err = CalculateWith(
+		nil,
+		1*constants.QuantaPerUnit,
+		blockTime, lastEAICalc, weightedAverageAge,
+		NewBasicLock(90*math.Day, DefaultLockBonusEAI),
+		DefaultUnlockedEAI, spec,
+	)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func TestFactorEAIRounding(t *testing.T) {
+	const half = constants.RateDenominator / 2
+	tests := []struct {
+		mode       unsigned.RoundingMode
+		gain, loss math.Ndau
+	}{
+		{unsigned.Truncate, 1, -1},
+		{unsigned.HalfEven, 2, -2},
+		{unsigned.HalfUp, 2, -2},
+		{unsigned.Ceil, 2, -1},
+		{unsigned.Floor, 1, -2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			// 3 napu grow and decay by 1.5 napu
+			gain, err := factorEAI(3, constants.RateDenominator+half, tt.mode)
+			require.NoError(t, err)
+			require.Equal(t, tt.gain, gain)
+			loss, err := factorEAI(3, half, tt.mode)
+			require.NoError(t, err)
+			require.Equal(t, tt.loss, loss)
+		})
+	}
 }
 
 func TestCalculateEAIRate(t *testing.T) {
 	type args struct {
 		weightedAverageAge math.Duration
-		lock               *testLock
+		lock               *BasicLock
 		unlockedTable      RateTable
 	}
 	tests := []struct {
@@ -809,12 +818,12 @@ func TestCalculateEAIRate(t *testing.T) {
 		{"65 days unlocked", args{65 * math.Day, nil, DefaultUnlockedEAI}, RateFromPercent(3)},
 		{"90 days unlocked", args{90 * math.Day, nil, DefaultUnlockedEAI}, RateFromPercent(4)},
 		// lock bonus: 1%. effective WAA: 155d -> 5m -> 6%. Expect 7%.
-		{"65 days locked 90", args{65 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(7)},
-		{"90 days locked 90", args{90 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(8)},
+		{"65 days locked 90", args{65 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(7)},
+		{"90 days locked 90", args{90 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(8)},
 		// lock bonus: 1%. Effective WAA: 90d -> 3m -> 4%. Expect 5%.
-		{"0 days locked 90", args{0 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(5)},
+		{"0 days locked 90", args{0 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(5)},
 		// lock bonus: 4%. Effective WAA: 1000d -> 2y -> 10%. Expect 14%.
-		{"0 days locked 1000", args{0 * math.Day, newTestLock(1000*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(14)},
+		{"0 days locked 1000", args{0 * math.Day, NewBasicLock(1000*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI}, RateFromPercent(14)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -829,7 +838,7 @@ func TestCalculateEAIRate(t *testing.T) {
 func TestCalculateEAIRateNotified(t *testing.T) {
 	type args struct {
 		weightedAverageAge math.Duration
-		lock               *testLock
+		lock               *BasicLock
 		unlockedTable      RateTable
 	}
 	tests := []struct {
@@ -842,7 +851,7 @@ func TestCalculateEAIRateNotified(t *testing.T) {
 		{
 			"five-line chart",
 			// lock bonus: 1%. effective WAA at unlock: 252d -> 8m -> 9%. Expect 10%.
-			args{200 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
+			args{200 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
 			RateFromPercent(10),
 			252 * math.Day,
 			200 * math.Day,
@@ -850,7 +859,7 @@ func TestCalculateEAIRateNotified(t *testing.T) {
 		{
 			"five-line chart 1000 days later",
 			// lock bonus: 1%. effective WAA at unlock: 252d -> 8m -> 9%. Expect 10%.
-			args{200 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
+			args{200 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
 			RateFromPercent(10),
 			(1000 + 252) * math.Day,
 			(1000 + 200) * math.Day,
@@ -858,7 +867,7 @@ func TestCalculateEAIRateNotified(t *testing.T) {
 		{
 			"five-line chart after unlock",
 			// unlocks @ 252. Day 260. effective WAA 260 -> 8m -> 9%. Expect 9%.
-			args{260 * math.Day, newTestLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
+			args{260 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI), DefaultUnlockedEAI},
 			RateFromPercent(9),
 			252 * math.Day,
 			260 * math.Day,
@@ -866,7 +875,7 @@ func TestCalculateEAIRateNotified(t *testing.T) {
 		{
 			"max rate",
 			// unlocks at 3y; bonus 5%. Day 0. Effective WAA 3y -> 10%. Expect 15%
-			args{0, newTestLock(3*math.Year, DefaultLockBonusEAI), DefaultUnlockedEAI},
+			args{0, NewBasicLock(3*math.Year, DefaultLockBonusEAI), DefaultUnlockedEAI},
 			RateFromPercent(15),
 			3 * math.Year,
 			0,
@@ -883,6 +892,36 @@ func TestCalculateEAIRateNotified(t *testing.T) {
 	}
 }
 
+func TestCalculateEAIRateDetails(t *testing.T) {
+	lock := NewBasicLock(90*math.Day, DefaultLockBonusEAI)
+
+	// unnotified: effective WAA 200d + 90d -> 9m -> 10%; bonus 1%
+	rate := CalculateEAIRateDetails(200*math.Day, lock, DefaultUnlockedEAI, 0)
+	require.Equal(t, EAIRate{
+		Base:         RateFromPercent(10),
+		Bonus:        RateFromPercent(1),
+		EffectiveWAA: 290 * math.Day,
+	}, rate)
+	require.Equal(t, RateFromPercent(11), rate.Total())
+	require.Equal(t, rate.Total(), CalculateEAIRate(200*math.Day, lock, DefaultUnlockedEAI, 0))
+	require.Equal(t, BasisPoints(1100), CalculateEAIRateBP(200*math.Day, lock, DefaultUnlockedEAI, 0))
+	require.InDelta(t, 11.0, CalculateEAIRateFloat(200*math.Day, lock, DefaultUnlockedEAI, 0), 1e-9)
+
+	// past the unlock: 260d -> 8m -> 9%, with no bonus
+	unlocksOn := math.Timestamp(252 * math.Day)
+	lock.UnlocksOn = &unlocksOn
+	rate = CalculateEAIRateDetails(260*math.Day, lock, DefaultUnlockedEAI, 260*math.Day)
+	require.Equal(t, EAIRate{
+		Base:         RateFromPercent(9),
+		EffectiveWAA: 260 * math.Day,
+	}, rate)
+
+	// unlocked
+	rate = CalculateEAIRateDetails(10*math.Day, nil, DefaultUnlockedEAI, 0)
+	require.Equal(t, EAIRate{EffectiveWAA: 10 * math.Day}, rate)
+	require.Equal(t, BasisPoints(0), CalculateEAIRateBP(10*math.Day, nil, DefaultUnlockedEAI, 0))
+}
+
 const datefmt = "1/2/06"
 
 // there was an assertion made that simple interest was the correct way to calculate
@@ -978,7 +1017,7 @@ func TestCalculateRealWorld(t *testing.T) {
 			got, err := Calculate(
 				tt.quantity,
 				endts, tt.chainDate, waa,
-				newTestLock(tt.lockDuration, DefaultLockBonusEAI),
+				NewBasicLock(tt.lockDuration, DefaultLockBonusEAI),
 				DefaultUnlockedEAI, true,
 			)
 			if err != nil {
@@ -1013,7 +1052,7 @@ func TestCalculateDebug(t *testing.T) {
 			got, err := Calculate(
 				tt.quantity,
 				enddate, tt.chainDate, waa,
-				newTestLock(tt.lockDuration, DefaultLockBonusEAI),
+				NewBasicLock(tt.lockDuration, DefaultLockBonusEAI),
 				DefaultUnlockedEAI, true,
 			)
 			if err != nil {
@@ -1065,7 +1104,7 @@ func TestCalculateSequence(t *testing.T) {
 			eai, err := Calculate(
 				tt.quantity,
 				thistime, tt.lastEAICalc, waa,
-				newTestLock(tt.lockDuration, DefaultLockBonusEAI),
+				NewBasicLock(tt.lockDuration, DefaultLockBonusEAI),
 				DefaultUnlockedEAI, true,
 			)
 			if err != nil {
@@ -1088,7 +1127,7 @@ func TestCalculateSequence(t *testing.T) {
 			eai, err := Calculate(
 				tt.quantity,
 				endtime, tt.lastEAICalc, waa,
-				newTestLock(tt.lockDuration, DefaultLockBonusEAI),
+				NewBasicLock(tt.lockDuration, DefaultLockBonusEAI),
 				DefaultUnlockedEAI, true,
 			)
 			if err != nil {
@@ -1118,7 +1157,7 @@ func TestUnlockTimeIgnoredWhenEAICalcWasMoreRecent(t *testing.T) {
 	//
 	// Given this setup, we expect that the account earns the max unlocked rate
 	// (10%) for 1 month; the factor must be `e^(10%*30d)`
-	lock := newTestLock(math.Year, DefaultLockBonusEAI)
+	lock := NewBasicLock(math.Year, DefaultLockBonusEAI)
 	uo := math.Timestamp(math.Year)
 	lock.UnlocksOn = &uo
 
@@ -1127,7 +1166,7 @@ func TestUnlockTimeIgnoredWhenEAICalcWasMoreRecent(t *testing.T) {
 		13*math.Month,
 		14*math.Month,
 		lock,
-		DefaultUnlockedEAI, true,
+		DefaultUnlockedEAI, spec,
 	)
 	require.NoError(t, err)
 
@@ -1155,3 +1194,457 @@ func TestUnlockTimeIgnoredWhenEAICalcWasMoreRecent(t *testing.T) {
 
 	require.InEpsilon(t, expectedValue, factor, epsilon)
 }
+
+func TestCalculateAttributed(t *testing.T) {
+	unlocksOn := math.Timestamp(200 * math.Day)
+	notified := NewBasicLock(90*math.Day, DefaultLockBonusEAI)
+	notified.UnlocksOn = &unlocksOn
+
+	type args struct {
+		blockTime          math.Timestamp
+		lastEAICalc        math.Timestamp
+		weightedAverageAge math.Duration
+		lock               Lock
+	}
+	tests := []struct {
+		name  string
+		args  args
+		bands int
+	}{
+		{"no time elapsed", args{100 * math.Day, 100 * math.Day, 100 * math.Day, nil}, 0},
+		{"unlocked, single band", args{20 * math.Day, 10 * math.Day, 20 * math.Day, nil}, 1},
+		{"unlocked, several bands", args{math.Year, 0, math.Year, nil}, 10},
+		{"locked", args{123 * math.Day, 39 * math.Day, 123 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI)}, 4},
+		{"lock expired during period", args{300 * math.Day, 0, 300 * math.Day, notified}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balance := math.Ndau(1234 * constants.QuantaPerUnit)
+			expect, err := CalculateWith(
+				nil,
+				balance,
+				tt.args.blockTime, tt.args.lastEAICalc, tt.args.weightedAverageAge,
+				tt.args.lock,
+				DefaultUnlockedEAI, spec,
+			)
+			require.NoError(t, err)
+
+			total, attributions, err := CalculateAttributed(
+				balance,
+				tt.args.blockTime, tt.args.lastEAICalc, tt.args.weightedAverageAge,
+				tt.args.lock,
+				DefaultUnlockedEAI, spec,
+			)
+			require.NoError(t, err)
+			require.Equal(t, expect, total)
+			if tt.bands > 0 {
+				require.Len(t, attributions, tt.bands)
+			}
+
+			var sum math.Ndau
+			var duration math.Duration
+			for _, a := range attributions {
+				require.True(t, a.EAI >= 0)
+				sum += a.EAI
+				duration += a.Duration
+			}
+			require.Equal(t, total, sum)
+			require.Equal(t, tt.args.blockTime.Since(tt.args.lastEAICalc), duration)
+		})
+	}
+}
+
+func TestCalculateAttributedRates(t *testing.T) {
+	// an unlocked account aged from 0 to 100 days spends 30 days at each of
+	// 0%, 2%, and 3%, and 10 days at 4%
+	_, attributions, err := CalculateAttributed(
+		1000*constants.QuantaPerUnit,
+		100*math.Day, 0, 100*math.Day,
+		nil,
+		DefaultUnlockedEAI, spec,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []Rate{
+		RateFromPercent(0),
+		RateFromPercent(2),
+		RateFromPercent(3),
+		RateFromPercent(4),
+	}, []Rate{
+		attributions[0].Rate,
+		attributions[1].Rate,
+		attributions[2].Rate,
+		attributions[3].Rate,
+	})
+	require.Equal(t, math.Ndau(0), attributions[0].EAI)
+	// the 3% band earns more than the 2% band over the same duration
+	require.True(t, attributions[2].EAI > attributions[1].EAI)
+}
+
+// calcCases are accounts in each lock state, for comparing Calculate with
+// CalculateWith
+func calcCases() map[string]struct {
+	blockTime, lastEAICalc math.Timestamp
+	weightedAverageAge     math.Duration
+	lock                   Lock
+} {
+	unlocksOn := math.Timestamp(200 * math.Day)
+	notified := NewBasicLock(90*math.Day, DefaultLockBonusEAI)
+	notified.UnlocksOn = &unlocksOn
+	return map[string]struct {
+		blockTime, lastEAICalc math.Timestamp
+		weightedAverageAge     math.Duration
+		lock                   Lock
+	}{
+		"no time elapsed": {100 * math.Day, 100 * math.Day, 100 * math.Day, nil},
+		"unlocked":        {math.Year, 0, math.Year, nil},
+		"locked":          {123 * math.Day, 39 * math.Day, 123 * math.Day, NewBasicLock(90*math.Day, DefaultLockBonusEAI)},
+		"notified":        {150 * math.Day, 20 * math.Day, 150 * math.Day, notified},
+		"lock expired":    {300 * math.Day, 0, 300 * math.Day, notified},
+	}
+}
+
+func TestCalculateWith(t *testing.T) {
+	balance := math.Ndau(1234 * constants.QuantaPerUnit)
+	// sharing the scratch between cases ensures that stale data never leaks
+	// from one calculation into the next
+	var scratch CalcScratch
+	for i := 0; i < 2; i++ {
+		for name, c := range calcCases() {
+			t.Run(name, func(t *testing.T) {
+				expect, err := Calculate(
+					balance,
+					c.blockTime, c.lastEAICalc, c.weightedAverageAge,
+					c.lock,
+					DefaultUnlockedEAI, true,
+				)
+				require.NoError(t, err)
+				actual, err := CalculateWith(
+					&scratch,
+					balance,
+					c.blockTime, c.lastEAICalc, c.weightedAverageAge,
+					c.lock,
+					DefaultUnlockedEAI, CalcOptions{FixUnlockBug: true},
+				)
+				require.NoError(t, err)
+				require.Equal(t, expect, actual)
+			})
+		}
+	}
+}
+
+func TestCalculateWithAllocs(t *testing.T) {
+	balance := math.Ndau(1234 * constants.QuantaPerUnit)
+	for name, c := range calcCases() {
+		if name == "lock expired" {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			var scratch CalcScratch
+			allocs := testing.AllocsPerRun(100, func() {
+				_, err := CalculateWith(
+					&scratch,
+					balance,
+					c.blockTime, c.lastEAICalc, c.weightedAverageAge,
+					c.lock,
+					DefaultUnlockedEAI, spec,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
+			require.Zero(t, allocs)
+		})
+	}
+}
+
+func BenchmarkCalculate(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, err := Calculate(
+			1234*constants.QuantaPerUnit,
+			math.Year, 0, math.Year,
+			nil,
+			DefaultUnlockedEAI, true,
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCalculateWith(b *testing.B) {
+	b.ReportAllocs()
+	var scratch CalcScratch
+	for i := 0; i < b.N; i++ {
+		_, err := CalculateWith(
+			&scratch,
+			1234*constants.QuantaPerUnit,
+			math.Year, 0, math.Year,
+			nil,
+			DefaultUnlockedEAI, spec,
+		)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCalculateStrict(t *testing.T) {
+	balance := math.Ndau(1234 * constants.QuantaPerUnit)
+	tests := []struct {
+		name                   string
+		blockTime, lastEAICalc math.Timestamp
+		weightedAverageAge     math.Duration
+		err                    error
+	}{
+		{"well formed", 100 * math.Day, 50 * math.Day, 80 * math.Day, nil},
+		{"young account", 100 * math.Day, 50 * math.Day, 10 * math.Day, nil},
+		{"future last calc", 50 * math.Day, 100 * math.Day, 80 * math.Day, ErrFutureLastCalc},
+		{"negative WAA", 100 * math.Day, 50 * math.Day, -1, ErrNegativeWAA},
+		{"negative block time", -1, -2, 80 * math.Day, ErrNegativeTimestamp},
+		{"negative last calc", 100 * math.Day, -1, 80 * math.Day, ErrNegativeTimestamp},
+	}
+	strictSpec := spec
+	strictSpec.Strictness = Strict
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lenient, err := Calculate(
+				balance,
+				tt.blockTime, tt.lastEAICalc, tt.weightedAverageAge,
+				nil,
+				DefaultUnlockedEAI, true,
+			)
+			require.NoError(t, err)
+
+			strict, err := CalculateWith(
+				nil,
+				balance,
+				tt.blockTime, tt.lastEAICalc, tt.weightedAverageAge,
+				nil,
+				DefaultUnlockedEAI, CalcOptions{FixUnlockBug: true, Strictness: Strict},
+			)
+			require.Equal(t, tt.err, err)
+			_, _, err = CalculateAttributed(
+				balance,
+				tt.blockTime, tt.lastEAICalc, tt.weightedAverageAge,
+				nil,
+				DefaultUnlockedEAI, strictSpec,
+			)
+			require.Equal(t, tt.err, err)
+			if tt.err == nil {
+				// strictness never changes a result
+				require.Equal(t, lenient, strict)
+			}
+		})
+	}
+}
+
+func TestValidateLockState(t *testing.T) {
+	blockTime := math.Timestamp(400 * math.Day)
+	waa := math.Duration(300 * math.Day)
+	notified := func(period math.Duration, at math.Timestamp) *BasicLock {
+		lock := NewBasicLock(period, DefaultLockBonusEAI)
+		unlocksOn := at.Add(period)
+		lock.UnlocksOn = &unlocksOn
+		return lock
+	}
+	var nilLock *BasicLock
+	negative := math.Timestamp(-1)
+	tests := []struct {
+		name string
+		lock Lock
+		err  error
+	}{
+		{"no lock", nil, nil},
+		{"nil lock", nilLock, nil},
+		{"locked", NewBasicLock(math.Year, DefaultLockBonusEAI), nil},
+		{"notified", notified(math.Year, 200*math.Day), nil},
+		{"notified at creation", notified(math.Year, 100*math.Day), nil},
+		{"notified at block time", notified(math.Year, 400*math.Day), nil},
+		{"unlocked", notified(30*math.Day, 200*math.Day), nil},
+		{"notified before creation", notified(math.Year, 100*math.Day-1), ErrNoticeBeforeAccount},
+		{"notified in future", notified(math.Year, 400*math.Day+1), ErrFutureNotice},
+		{"negative notice period", &BasicLock{NoticePeriod: -1}, ErrNegativeNoticePeriod},
+		{"negative bonus rate", &BasicLock{NoticePeriod: math.Year, BonusRate: -1}, ErrNegativeBonusRate},
+		{"negative unlocks on", &BasicLock{UnlocksOn: &negative}, ErrNegativeTimestamp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.err, ValidateLockState(tt.lock, waa, blockTime))
+
+			_, err := Calculate(
+				1000*constants.QuantaPerUnit,
+				blockTime, blockTime.Sub(10*math.Day), waa,
+				tt.lock,
+				DefaultUnlockedEAI, true,
+			)
+			require.NoError(t, err)
+
+			_, err = CalculateWith(
+				nil,
+				1000*constants.QuantaPerUnit,
+				blockTime, blockTime.Sub(10*math.Day), waa,
+				tt.lock,
+				DefaultUnlockedEAI, CalcOptions{FixUnlockBug: true, Strictness: Strict},
+			)
+			require.Equal(t, tt.err, err)
+		})
+	}
+
+	lock := NewBasicLock(math.Year, DefaultLockBonusEAI)
+	require.Equal(t, ErrNegativeWAA, ValidateLockState(lock, -1, blockTime))
+	require.Equal(t, ErrNegativeTimestamp, ValidateLockState(lock, waa, -1))
+}
+
+func TestEAIFactorLongDurations(t *testing.T) {
+	// expectedFactor computes e ^ (sum of rate * duration over the table)
+	expectedFactor := func(table RateTable, waa math.Duration) uint64 {
+		exponent := decimal.WithContext(decimal.Context128)
+		term := decimal.WithContext(decimal.Context128)
+		for i, row := range table {
+			end := waa
+			if i+1 < len(table) && table[i+1].From < waa {
+				end = table[i+1].From
+			}
+			if end <= row.From {
+				break
+			}
+			term.SetMantScale(int64(row.Rate), 0)
+			term.Mul(term, decimal.New(int64(end-row.From), 0))
+			term.Quo(term, decimal.New(constants.RateDenominator, 0))
+			term.Quo(term, decimal.New(math.Year, 0))
+			exponent.Add(exponent, term)
+		}
+		dmath.Exp(exponent, exponent)
+		exponent.Mul(exponent, decimal.New(constants.RateDenominator, 0))
+		out, ok := exponent.Uint64()
+		require.True(t, ok)
+		return out
+	}
+
+	for _, years := range []math.Duration{50, 100} {
+		for name, table := range map[string]RateTable{
+			"2%":      {{From: 0, Rate: RateFromPercent(2)}},
+			"10%":     {{From: 0, Rate: RateFromPercent(10)}},
+			"default": DefaultUnlockedEAI,
+		} {
+			t.Run(fmt.Sprintf("%s for %d years", name, years), func(t *testing.T) {
+				waa := years * math.Year
+				factor, err := calculateEAIFactor(
+					math.Timestamp(waa), 0, waa,
+					nil,
+					table, spec,
+				)
+				require.NoError(t, err)
+				require.InEpsilon(t, expectedFactor(table, waa), factor, epsilon)
+			})
+		}
+	}
+
+	// e ^ 20 doesn't fit
+	_, err := calculateEAIFactor(
+		math.Timestamp(100*math.Year), 0, 100*math.Year,
+		nil,
+		RateTable{{From: 0, Rate: RateFromPercent(20)}}, spec,
+	)
+	require.Equal(t, ErrFactorOverflow, errors.Cause(err))
+
+	_, err = calculateEAIFactor(
+		math.Timestamp(1001*math.Year), 0, 1001*math.Year,
+		nil,
+		RateTable{{From: 0, Rate: 0}}, spec,
+	)
+	require.Equal(t, ErrDurationTooLong, errors.Cause(err))
+
+	// decay can't overflow, however long it lasts
+	factor, err := calculateEAIFactor(
+		math.Timestamp(200*math.Year), 0, 200*math.Year,
+		nil,
+		RateTable{{From: 0, Rate: -RateFromPercent(20)}}, spec,
+	)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), factor)
+}
+
+func TestEAIFactorLongPeriodsGate(t *testing.T) {
+	historical := CalcOptions{FixUnlockBug: true}
+
+	// within the range of ExpFrac, nothing changes
+	for _, years := range []math.Duration{1, 2, 5} {
+		waa := years * math.Year
+		want, err := calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, DefaultUnlockedEAI, historical)
+		require.NoError(t, err)
+		got, err := calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, DefaultUnlockedEAI, spec)
+		require.NoError(t, err)
+		require.Equal(t, want, got, "%d years", years)
+	}
+
+	// an exponent of 10 failed, and succeeds only with LongPeriods
+	waa := math.Duration(100 * math.Year)
+	table := RateTable{{From: 0, Rate: RateFromPercent(10)}}
+	_, err := calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, table, historical)
+	require.Error(t, err)
+	_, err = calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, table, spec)
+	require.NoError(t, err)
+
+	// a period at 0% was never capped
+	waa = 1001 * math.Year
+	table = RateTable{{From: 0, Rate: 0}}
+	factor, err := calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, table, historical)
+	require.NoError(t, err)
+	require.Equal(t, uint64(constants.RateDenominator), factor)
+	_, err = calculateEAIFactor(math.Timestamp(waa), 0, waa, nil, table, spec)
+	require.Equal(t, ErrDurationTooLong, errors.Cause(err))
+}
+
+func TestCalculateNegativeRates(t *testing.T) {
+	// a penalty of 3% follows a year at 2%
+	table := RateTable{
+		{From: 0, Rate: RateFromPercent(2)},
+		{From: math.Year, Rate: -RateFromPercent(3)},
+	}
+	balance := math.Ndau(1000 * constants.QuantaPerUnit)
+	blockTime := math.Timestamp(2 * math.Year)
+
+	eai, err := Calculate(balance, blockTime, 0, 2*math.Year, nil, table, true)
+	require.NoError(t, err)
+	require.True(t, eai < 0)
+	require.InDelta(t, 1000*gomath.Expm1(0.02-0.03), float64(eai)/constants.QuantaPerUnit, 1e-7)
+
+	// the bands of attributed EAI earn, then lose, and sum to the total
+	total, attributions, err := CalculateAttributed(balance, blockTime, 0, 2*math.Year, nil, table, spec)
+	require.NoError(t, err)
+	require.Equal(t, eai, total)
+	require.Len(t, attributions, 2)
+	require.Equal(t, -RateFromPercent(3), attributions[1].Rate)
+	require.True(t, attributions[0].EAI > 0)
+	require.True(t, attributions[1].EAI < 0)
+	require.Equal(t, total, attributions[0].EAI+attributions[1].EAI)
+
+	// a lock bonus is added to the penalty, and may outweigh it: a year's
+	// notice puts the whole period in the penalty band
+	lock := &BasicLock{NoticePeriod: math.Year, BonusRate: RateFromPercent(4)}
+	eai, err = Calculate(balance, blockTime, 0, 2*math.Year, lock, table, true)
+	require.NoError(t, err)
+	require.InDelta(t, 1000*gomath.Expm1(2*0.01), float64(eai)/constants.QuantaPerUnit, 1e-7)
+
+	// the whole balance can't be lost
+	eai, err = Calculate(balance, math.Timestamp(1000*math.Year), 0, 1000*math.Year, nil, table, true)
+	require.NoError(t, err)
+	require.Equal(t, -balance, eai)
+
+	// policy may forbid penalties
+	reject := CalcOptions{FixUnlockBug: true, RejectNegativeRates: true}
+	_, err = CalculateWith(nil, balance, blockTime, 0, 2*math.Year, nil, table, reject)
+	require.Equal(t, ErrNegativeRate, errors.Cause(err))
+	rejectSpec := spec
+	rejectSpec.RejectNegativeRates = true
+	_, _, err = CalculateAttributed(balance, blockTime, 0, 2*math.Year, nil, table, rejectSpec)
+	require.Equal(t, ErrNegativeRate, errors.Cause(err))
+	_, err = CalculateWith(nil, balance, math.Timestamp(300*math.Day), 0, 300*math.Day, nil, table, reject)
+	require.NoError(t, err)
+
+	// even where it is forbidden, a lock bonus may outweigh the penalty
+	_, err = CalculateWith(nil, balance, blockTime, 0, 2*math.Year, lock, table, reject)
+	require.NoError(t, err)
+}
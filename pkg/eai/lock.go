@@ -10,7 +10,17 @@ package eai
 // - -- --- ---- -----
 
 
-import math "github.com/ndau/ndaumath/pkg/types"
+import (
+	"encoding"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+//go:generate msgp
 
 // Lock is anything which acts like a lock struct.
 //
@@ -21,9 +31,146 @@ import math "github.com/ndau/ndaumath/pkg/types"
 //
 // We started with option 1, but a noms dependency from this low in the
 // stack is not great. Instead, let's define a Lock interface which we can
-// implement elsewhere.
+// implement elsewhere. BasicLock is a plain implementation, for consumers
+// which need no more.
 type Lock interface {
 	GetNoticePeriod() math.Duration
 	GetUnlocksOn() *math.Timestamp
 	GetBonusRate() Rate
 }
+
+// A BasicLock is a Lock with no storage dependencies
+//
+// A lock begins locked, with UnlocksOn nil. Once notified, it remains locked
+// until UnlocksOn, after which it is unlocked.
+type BasicLock struct {
+	NoticePeriod math.Duration
+	UnlocksOn    *math.Timestamp
+	BonusRate    Rate
+}
+
+var _ Lock = (*BasicLock)(nil)
+var _ encoding.TextMarshaler = (*BasicLock)(nil)
+var _ encoding.TextUnmarshaler = (*BasicLock)(nil)
+
+// NewBasicLock creates a BasicLock with the given notice period
+//
+// Its bonus rate is the rate of the bonus table at the notice period.
+func NewBasicLock(period math.Duration, bonusRateTable RateTable) *BasicLock {
+	return &BasicLock{
+		NoticePeriod: period,
+		BonusRate:    bonusRateTable.RateAt(period),
+	}
+}
+
+// GetNoticePeriod implements Lock
+func (l *BasicLock) GetNoticePeriod() math.Duration {
+	if l != nil {
+		return l.NoticePeriod
+	}
+	return 0
+}
+
+// GetUnlocksOn implements Lock
+func (l *BasicLock) GetUnlocksOn() *math.Timestamp {
+	if l != nil {
+		return l.UnlocksOn
+	}
+	return nil
+}
+
+// GetBonusRate implements Lock
+func (l *BasicLock) GetBonusRate() Rate {
+	if l != nil {
+		return l.BonusRate
+	}
+	return Rate(0)
+}
+
+// IsNotified is true when notice has been given to unlock the lock
+func (l *BasicLock) IsNotified() bool {
+	return l.GetUnlocksOn() != nil
+}
+
+// IsLocked is true until the notice period of a notified lock has elapsed
+//
+// A nil lock is never locked.
+func (l *BasicLock) IsLocked(blockTime math.Timestamp) bool {
+	if l == nil {
+		return false
+	}
+	return l.UnlocksOn == nil || blockTime < *l.UnlocksOn
+}
+
+// Notify gives notice to unlock the lock at blockTime
+//
+// It unlocks once the notice period has elapsed. It is an error to notify a
+// lock which has already been notified.
+func (l *BasicLock) Notify(blockTime math.Timestamp) error {
+	if l == nil {
+		return errors.New("cannot notify nil lock")
+	}
+	if l.UnlocksOn != nil {
+		return fmt.Errorf("lock already notified; unlocks on %s", *l.UnlocksOn)
+	}
+	unlocksOn := blockTime.Add(l.NoticePeriod)
+	l.UnlocksOn = &unlocksOn
+	return nil
+}
+
+// Unlock checks that the lock is unlocked at blockTime
+//
+// The lock itself is unchanged: an unlocked account simply has no lock, so
+// on success the caller should discard it. It is an error if the lock has
+// not been notified, or its notice period has not yet elapsed.
+func (l *BasicLock) Unlock(blockTime math.Timestamp) error {
+	if l == nil {
+		return errors.New("cannot unlock nil lock")
+	}
+	if l.UnlocksOn == nil {
+		return errors.New("lock has not been notified")
+	}
+	if l.IsLocked(blockTime) {
+		return fmt.Errorf("lock remains locked until %s", *l.UnlocksOn)
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+//
+// The text is the notice period and bonus rate, as in a rate table row,
+// followed by an @ and the unlock time once notified: for example,
+// `3m:2%` or `3m:2%@2020-06-01T00:00:00.000000Z`.
+func (l BasicLock) MarshalText() ([]byte, error) {
+	text := fmt.Sprintf("%s:%s", l.NoticePeriod, l.BonusRate)
+	if l.UnlocksOn != nil {
+		text += "@" + l.UnlocksOn.String()
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (l *BasicLock) UnmarshalText(text []byte) error {
+	if !utf8.Valid(text) {
+		return errors.New("text was not utf-8")
+	}
+	s := string(text)
+	var unlocksOn *math.Timestamp
+	if at := strings.IndexByte(s, '@'); at >= 0 {
+		ts, err := math.ParseTimestamp(s[at+1:])
+		if err != nil {
+			return errors.Wrap(err, "parsing unlocks on")
+		}
+		unlocksOn = &ts
+		s = s[:at]
+	}
+	var row RTRow
+	err := row.UnmarshalText([]byte(s))
+	if err != nil {
+		return err
+	}
+	l.NoticePeriod = row.From
+	l.BonusRate = row.Rate
+	l.UnlocksOn = unlocksOn
+	return nil
+}
@@ -0,0 +1,79 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBasicLock(t *testing.T) {
+	lock := NewBasicLock(6*math.Month, DefaultLockBonusEAI)
+	require.Equal(t, math.Duration(6*math.Month), lock.GetNoticePeriod())
+	require.Equal(t, DefaultLockBonusEAI.RateAt(6*math.Month), lock.GetBonusRate())
+	require.Nil(t, lock.GetUnlocksOn())
+	require.False(t, lock.IsNotified())
+}
+
+func TestNilBasicLock(t *testing.T) {
+	var lock *BasicLock
+	require.Equal(t, math.Duration(0), lock.GetNoticePeriod())
+	require.Nil(t, lock.GetUnlocksOn())
+	require.Equal(t, Rate(0), lock.GetBonusRate())
+	require.False(t, lock.IsNotified())
+	require.False(t, lock.IsLocked(0))
+	require.Error(t, lock.Notify(0))
+	require.Error(t, lock.Unlock(0))
+}
+
+func TestBasicLockNotifyUnlock(t *testing.T) {
+	now := math.Timestamp(math.Year)
+	lock := NewBasicLock(3*math.Month, DefaultLockBonusEAI)
+
+	require.True(t, lock.IsLocked(now))
+	require.Error(t, lock.Unlock(now.Add(10*math.Year)))
+
+	require.NoError(t, lock.Notify(now))
+	require.True(t, lock.IsNotified())
+	require.Equal(t, now.Add(3*math.Month), *lock.GetUnlocksOn())
+	require.Error(t, lock.Notify(now.Add(math.Day)))
+
+	require.True(t, lock.IsLocked(now.Add(3*math.Month-1)))
+	require.Error(t, lock.Unlock(now.Add(3*math.Month-1)))
+	require.False(t, lock.IsLocked(now.Add(3*math.Month)))
+	require.NoError(t, lock.Unlock(now.Add(3*math.Month)))
+}
+
+func TestBasicLockText(t *testing.T) {
+	unlocksOn, err := math.ParseTimestamp("2020-06-01T00:00:00Z")
+	require.NoError(t, err)
+	for _, lock := range []BasicLock{
+		{NoticePeriod: 3 * math.Month, BonusRate: RateFromPercent(2)},
+		{NoticePeriod: math.Year, BonusRate: RateFromPercent(4), UnlocksOn: &unlocksOn},
+		{},
+	} {
+		text, err := lock.MarshalText()
+		require.NoError(t, err)
+		t.Log(string(text))
+		var got BasicLock
+		require.NoError(t, got.UnmarshalText(text))
+		require.Equal(t, lock, got)
+	}
+
+	var lock BasicLock
+	require.NoError(t, lock.UnmarshalText([]byte("3m:2%")))
+	require.Equal(t, BasicLock{NoticePeriod: 3 * math.Month, BonusRate: RateFromPercent(2)}, lock)
+	for _, bad := range []string{"", "3m", "3m:2%@", "3m:2%@yesterday", "3m@2020-06-01T00:00:00Z"} {
+		require.Error(t, lock.UnmarshalText([]byte(bad)), bad)
+	}
+}
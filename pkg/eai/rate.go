@@ -12,11 +12,15 @@ package eai
 
 import (
 	"encoding"
+	"encoding/json"
 	"fmt"
+	"io"
 	gomath "math"
 	"regexp"
 	"strconv"
 	"strings"
+	"text/tabwriter"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/ndau/ndaumath/pkg/constants"
@@ -48,6 +52,14 @@ import (
 // see the same rate of return; the benefit of the one registered to the
 // frequent node is that it sees the increase more often.
 //
+// The rates of unlocked accounts may also be negative, as penalties: a
+// negative rate has a factor e ^ (rate * time) less than 1, and so produces
+// negative EAI, by which the balance decays. Callers whose policy forbids
+// them set CalcOptions.RejectNegativeRates. Lock bonus rates, however, are
+// never negative: RegisterDefaultTables rejects lock bonus tables with
+// negative rates, and ValidateLockState locks with negative bonus rates. A
+// lock may therefore reduce a penalty, but never add to it.
+//
 // We use a signed int so that json2msgp won't need type hints for encoding
 // rate tables in system variables.  If we use a rate denominator of 1e12,
 // corresponding to a rate of 100%, then 63 bits gives us enough room to
@@ -70,20 +82,25 @@ func init() {
 	ratefmt = fmt.Sprintf("%%d.%%0%dd", fracdigits)
 	// ratere: parse a rate into pct (before the decimal) and frac (after the decimal)
 	// strings, which can be used to regenerate the rate
-	ratere = regexp.MustCompile(fmt.Sprintf(`^\s*(?P<pct>\d+)(\.(?P<frac>\d{1,%d}))?%%\s*$`, fracdigits))
+	ratere = regexp.MustCompile(fmt.Sprintf(`^\s*(?P<sign>-)?(?P<pct>\d+)(\.(?P<frac>\d{1,%d}))?%%\s*$`, fracdigits))
 }
 
 // String writes this Rate as a string
 func (r Rate) String() string {
-	onePct := RateFromPercent(1)
-	rs := fmt.Sprintf(ratefmt, r/onePct, r%onePct)
+	sign := ""
+	if r < 0 {
+		sign = "-"
+	}
+	magnitude := rateMagnitude(r)
+	onePct := uint64(RateFromPercent(1))
+	rs := fmt.Sprintf(ratefmt, magnitude/onePct, magnitude%onePct)
 	for rs[len(rs)-1] == '0' {
 		rs = rs[:len(rs)-1]
 	}
 	if rs[len(rs)-1] == '.' {
 		rs = rs[:len(rs)-1]
 	}
-	return rs + "%"
+	return sign + rs + "%"
 }
 
 // ParseRate attempts to parse a Rate from the provided string
@@ -124,6 +141,9 @@ func ParseRate(s string) (Rate, error) {
 		out += Rate(frac)
 	}
 
+	if result["sign"] != "" {
+		out = -out
+	}
 	return out, nil
 }
 
@@ -151,6 +171,9 @@ func (r RTRow) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
+//
+// The rate may be written as a percentage or in basis points: "1y:4.5%" and
+// "1y:450bp" are the same row.
 func (r *RTRow) UnmarshalText(text []byte) error {
 	if !utf8.Valid(text) {
 		return errors.New("text was not utf-8")
@@ -164,7 +187,7 @@ func (r *RTRow) UnmarshalText(text []byte) error {
 	if err != nil {
 		return errors.Wrap(err, "parsing from")
 	}
-	r.Rate, err = ParseRate(parts[1])
+	r.Rate, err = parseAnyRate(parts[1])
 	if err != nil {
 		return errors.Wrap(err, "parsing rate")
 	}
@@ -201,6 +224,140 @@ func (rt RateTable) RateAt(point math.Duration) Rate {
 	return rate
 }
 
+// NextBoundaryAfter returns the point after waa at which the rate next
+// changes, and the rate which takes effect there.
+//
+// Rows which don't change the rate are skipped. If the rate never changes
+// after waa, ok is false. For an account whose weighted average age
+// advances in real time, the change happens after boundary - waa.
+func (rt RateTable) NextBoundaryAfter(waa math.Duration) (boundary math.Duration, rate Rate, ok bool) {
+	current := rt.RateAt(waa)
+	for _, row := range rt {
+		if row.From > waa && row.Rate != current {
+			return row.From, row.Rate, true
+		}
+	}
+	return 0, 0, false
+}
+
+var _ encoding.TextMarshaler = (*RateTable)(nil)
+var _ encoding.TextUnmarshaler = (*RateTable)(nil)
+var _ json.Marshaler = (*RateTable)(nil)
+var _ json.Unmarshaler = (*RateTable)(nil)
+
+// MarshalText implements encoding.TextMarshaler
+//
+// Rows are written in their RTRow form, separated by ", ": for example,
+// "3m:1%, 6m:2%, 1y:3%".
+func (rt RateTable) MarshalText() ([]byte, error) {
+	rows := make([]string, 0, len(rt))
+	for _, row := range rt {
+		text, err := row.MarshalText()
+		if err != nil {
+			return nil, errors.Wrap(err, "marshaling row")
+		}
+		rows = append(rows, string(text))
+	}
+	return []byte(strings.Join(rows, ", ")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+//
+// Rows may be separated by commas, whitespace, or both. It is an error if
+// the rows are not sorted in strictly increasing order by their From field.
+func (rt *RateTable) UnmarshalText(text []byte) error {
+	if !utf8.Valid(text) {
+		return errors.New("text was not utf-8")
+	}
+	fields := strings.FieldsFunc(string(text), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	table := make(RateTable, 0, len(fields))
+	for idx, field := range fields {
+		var row RTRow
+		err := row.UnmarshalText([]byte(field))
+		if err != nil {
+			return errors.Wrapf(err, "row %d", idx)
+		}
+		if idx > 0 && row.From <= table[idx-1].From {
+			return fmt.Errorf("row %d: rows must be sorted in increasing order of From", idx)
+		}
+		table = append(table, row)
+	}
+	*rt = table
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+//
+// Without this, encoding/json would prefer MarshalText, changing the
+// established JSON form of a rate table, which is a list of rows.
+func (rt RateTable) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]RTRow(rt))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+//
+// Both the list form written by MarshalJSON and the string form written by
+// MarshalText are accepted.
+func (rt *RateTable) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		return rt.UnmarshalText([]byte(text))
+	}
+	var rows []RTRow
+	err := json.Unmarshal(data, &rows)
+	if err != nil {
+		return err
+	}
+	*rt = RateTable(rows)
+	return nil
+}
+
+// EffectiveRate returns the annual percentage yield of this rate, in percent.
+//
+// Rates are continuously compounded, so a nominal rate r yields e^r - 1 over
+// the course of a year. This is computed with floating point and is intended
+// only for display.
+func (r Rate) EffectiveRate() float64 {
+	return 100 * gomath.Expm1(float64(r)/constants.RateDenominator)
+}
+
+// BasisPoints returns this rate in basis points, truncated toward zero:
+// 200 for 2%.
+//
+// Use BasisPointsFrom to convert a rate exactly.
+func (r Rate) BasisPoints() BasisPoints {
+	return BasisPoints(r / ratePerBasisPoint)
+}
+
+// Percent returns the nominal value of this rate, in percent: 2.0 for 2%.
+//
+// This is computed with floating point and is intended only for display.
+func (r Rate) Percent() float64 {
+	return 100 * float64(r) / constants.RateDenominator
+}
+
+// Render writes a human-readable, aligned table of this RateTable to w.
+//
+// Each row shows the span over which its rate is effective, its nominal
+// rate, and its effective annual yield.
+func (rt RateTable) Render(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "From\tUntil\tNominal\tEffective\t")
+	for idx, row := range rt {
+		until := "-"
+		if idx+1 < len(rt) {
+			until = rt[idx+1].From.String()
+		}
+		fmt.Fprintf(
+			tw, "%s\t%s\t%s\t%.4f%%\t\n",
+			row.From, until, row.Rate, row.Rate.EffectiveRate(),
+		)
+	}
+	return errors.Wrap(tw.Flush(), "rendering rate table")
+}
+
 //msgp:tuple RSRow
 
 // RSRow is a single row of a rate slice.
@@ -267,112 +424,24 @@ func (rt RateTable) Slice(from, to, offset math.Duration) RateSlice {
 //   R0  ────────────┘    | / / / / / / / / /|/ / / /|
 //                   (from+offset)           |    (to+offset)
 //                                 (to+offset-freeze)
+//
+// That is, it slices the Timeline of this table, frozen at
+// (to+offset-freeze) unless freeze is 0, from (from+offset) to (to+offset).
+// The sign of freeze is ignored.
 func (rt RateTable) SliceF(from, to, offset, freeze math.Duration) RateSlice {
-	if to <= from {
-		// when actual duration is 0, it's fine to fake that the actual
-		// rate is also 0
-		return RateSlice{RSRow{}}
-	}
+	return rt.sliceInto(nil, from, to, offset, freeze)
+}
 
+// sliceInto is SliceF, but reuses the storage of dst when it is large enough
+func (rt RateTable) sliceInto(dst RateSlice, from, to, offset, freeze math.Duration) RateSlice {
 	if freeze < 0 {
 		freeze = -freeze
 	}
-
-	fromEffective := from + offset
-	toEffective := to + offset
-	notify := toEffective - freeze
-
-	// the computation can't result in -2, so if after the loop
-	// this remains, we know we never touched this var
-	const uninitialized = -2
-	fromI := uninitialized
-	toI := uninitialized
-	notifyI := uninitialized
-
-	for index, row := range rt {
-		if fromI == uninitialized && fromEffective < row.From {
-			fromI = index - 1
-		}
-		if toI == uninitialized && toEffective < row.From {
-			toI = index - 1
-		}
-		if notifyI == uninitialized && notify < row.From {
-			notifyI = index - 1
-		}
-		if fromI != uninitialized && toI != uninitialized && notifyI != uninitialized {
-			break
-		}
-	}
-	// if either variable comes out of the loop wihtout being initialized,
-	// the appropriate row index is the highest in the table
-	if fromI == uninitialized {
-		fromI = len(rt) - 1
+	tl := Timeline{bands: rt}
+	if freeze != 0 {
+		tl.Freeze(to + offset - freeze)
 	}
-	if toI == uninitialized {
-		toI = len(rt) - 1
-	}
-	if notifyI == uninitialized {
-		notifyI = len(rt) - 1
-	}
-
-	rateFor := func(idx int) Rate {
-		if idx == -1 {
-			return Rate(0)
-		}
-		return rt[idx].Rate
-	}
-
-	// if we froze before the from point, we have one period at the frozen rate
-	if freeze != 0 && notify < fromEffective {
-		return RateSlice{RSRow{Rate: rateFor(notifyI), Duration: to - from}}
-	}
-
-	// if from and to are in the same rate block, or
-	// from and the freeze point are in the same rate block, we have one period
-	// at the from rate
-	if fromI == toI || fromI == notifyI {
-		return RateSlice{RSRow{Rate: rateFor(fromI), Duration: to - from}}
-	}
-	numRows := 1 - fromI
-	if toI <= notifyI {
-		numRows += toI
-	} else {
-		numRows += notifyI
-	}
-
-	// ok, the rest is relatively straightforward. We need special
-	// handling for the first and last rate, because they have partial
-	// durations; the rest are just copies from the rate table
-	rs := make(RateSlice, numRows)
-	// - it's safe to index rt[fromI+1] because if fromI were the max value,
-	//   then we would have already returned: fromI must equal toI
-	// - we know that freezePoint > rt[fromI+1].From, because if fromI == notifyI,
-	//   we would have already returned. As we're here, we know that the
-	//   freeze point isn't in this first block.
-	rs[0] = RSRow{Rate: rateFor(fromI), Duration: rt[fromI+1].From - (from + offset)}
-	// freezing within the final rate block has no effect on the calculation
-	if notifyI == toI {
-		// it's safe to index rt[toI] because if toI were -1,
-		// then we would have already returned: fromI must equal toI
-		rs[numRows-1] = RSRow{Rate: rateFor(toI), Duration: toEffective - rt[toI].From}
-	} else {
-		rs[numRows-1] = RSRow{Rate: rateFor(notifyI), Duration: freeze + notify - rt[notifyI].From}
-	}
-
-	upperBoundI := toI
-	if notifyI < toI {
-		upperBoundI = notifyI
-	}
-
-	// indexing rt[fromI+i+1] is safe because fromI+i+1 == toI at max i
-	for i := 1; i < upperBoundI-fromI; i++ {
-		rs[i] = RSRow{
-			Rate:     rt[fromI+i].Rate,
-			Duration: rt[fromI+i+1].From - rt[fromI+i].From,
-		}
-	}
-
-	return rs
+	return tl.sliceInto(dst, from+offset, to+offset)
 }
 
 var (
@@ -384,7 +453,10 @@ var (
 	//
 	// Defaults drawn from https://tresor.it/p#0041o9iot7hm4kb5y707es7o/Oneiro%20Company%20Info/Whitepapers%20and%20Presentations/ndau%20Whitepaper%201.3%2020180425%20Final.pdf
 	// page 15.
-	DefaultUnlockedEAI RateTable
+	//
+	// These are the defaults in effect since genesis; to find the defaults
+	// in effect at a particular time, use DefaultTablesAt.
+	DefaultUnlockedEAI = genesisUnlockedEAI()
 
 	// DefaultLockBonusEAI is the bonus rate for locks of varying length
 	//
@@ -394,20 +466,30 @@ var (
 	//
 	// Defaults drawn from https://tresor.it/p#0041o9iot7hm4kb5y707es7o/Oneiro%20Company%20Info/Whitepapers%20and%20Presentations/ndau%20Whitepaper%201.3%2020180425%20Final.pdf
 	// page 15.
-	DefaultLockBonusEAI RateTable
+	//
+	// Like DefaultUnlockedEAI, these are the genesis defaults.
+	DefaultLockBonusEAI = genesisLockBonusEAI()
 )
 
-func init() {
+// genesisUnlockedEAI constructs the default unlocked rate table in effect
+// since genesis
+func genesisUnlockedEAI() RateTable {
+	var rt RateTable
 	for i := uint64(1); i < 10; i++ {
-		DefaultUnlockedEAI = append(DefaultUnlockedEAI, RTRow{
+		rt = append(rt, RTRow{
 			Rate: RateFromPercent(uint64(i + 1)),
 			// the wrapper here for uint64 serves as a notice to gomobile that it should
 			// assume this constant is 64 bits. Otherwise this breaks gomobile.
 			From: math.Duration(i * 30 * uint64(math.Day)),
 		})
 	}
+	return rt
+}
 
-	DefaultLockBonusEAI = RateTable{
+// genesisLockBonusEAI constructs the default lock bonus rate table in effect
+// since genesis
+func genesisLockBonusEAI() RateTable {
+	return RateTable{
 		RTRow{
 			From: math.Duration(3 * 30 * math.Day),
 			Rate: RateFromPercent(1),
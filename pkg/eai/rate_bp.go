@@ -0,0 +1,270 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/signed"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+//go:generate msgp
+//msgp:tuple RTRowBP
+
+// BasisPointSuffix always follows the number in the text form of BasisPoints
+const BasisPointSuffix = "bp"
+
+// ratePerBasisPoint is the Rate equal to one basis point
+const ratePerBasisPoint = constants.RateDenominator / 10000
+
+// ErrFractionalBasisPoints is returned when converting a Rate which is not a
+// whole number of basis points
+var ErrFractionalBasisPoints = errors.New("rate is not a whole number of basis points")
+
+// BasisPoints is an EAI rate in hundredths of a percent: 450 for 4.5%.
+//
+// It is the unit in which rates are usually discussed; Rate remains the unit
+// of calculation. Conversions between the two are exact or fail.
+type BasisPoints int64
+
+// BasisPointsFrom returns the rate in basis points.
+//
+// Unlike Rate.BasisPoints, which truncates, it is an error if the rate is
+// not a whole number of basis points.
+func BasisPointsFrom(r Rate) (BasisPoints, error) {
+	if r%ratePerBasisPoint != 0 {
+		return 0, errors.Wrap(ErrFractionalBasisPoints, r.String())
+	}
+	return BasisPoints(r / ratePerBasisPoint), nil
+}
+
+// Rate returns the Rate equal to these basis points
+func (bp BasisPoints) Rate() (Rate, error) {
+	r, err := signed.Mul(int64(bp), ratePerBasisPoint)
+	if err != nil {
+		return 0, errors.Wrapf(err, "converting %s to rate", bp)
+	}
+	return Rate(r), nil
+}
+
+// String writes these basis points as a string: "450bp"
+func (bp BasisPoints) String() string {
+	return strconv.FormatInt(int64(bp), 10) + BasisPointSuffix
+}
+
+// ParseBasisPoints parses basis points from the provided string.
+//
+// The suffix is required, so that "450" can't be mistaken for 450%.
+func ParseBasisPoints(s string) (BasisPoints, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasSuffix(s, BasisPointSuffix) {
+		return 0, errors.Errorf("failed to parse basis points: %q lacks suffix %q", s, BasisPointSuffix)
+	}
+	bp, err := strconv.ParseInt(strings.TrimSuffix(s, BasisPointSuffix), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing basis points")
+	}
+	return BasisPoints(bp), nil
+}
+
+var _ encoding.TextMarshaler = (*BasisPoints)(nil)
+var _ encoding.TextUnmarshaler = (*BasisPoints)(nil)
+
+// MarshalText implements encoding.TextMarshaler
+func (bp BasisPoints) MarshalText() ([]byte, error) {
+	return []byte(bp.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (bp *BasisPoints) UnmarshalText(text []byte) error {
+	var err error
+	*bp, err = ParseBasisPoints(string(text))
+	return err
+}
+
+// parseAnyRate parses a rate written either as a percentage or in basis
+// points, so that rate tables in either form can be read as either
+// representation while configuration migrates.
+func parseAnyRate(s string) (Rate, error) {
+	if strings.HasSuffix(strings.TrimSpace(s), BasisPointSuffix) {
+		bp, err := ParseBasisPoints(s)
+		if err != nil {
+			return 0, err
+		}
+		return bp.Rate()
+	}
+	return ParseRate(s)
+}
+
+// RTRowBP is a single row of a rate table in basis points
+type RTRowBP struct {
+	From math.Duration
+	Rate BasisPoints
+}
+
+var _ encoding.TextMarshaler = (*RTRowBP)(nil)
+var _ encoding.TextUnmarshaler = (*RTRowBP)(nil)
+
+// MarshalText implements encoding.TextMarshaler
+func (r RTRowBP) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%s:%s", r.From, r.Rate)), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+//
+// The rate may also be written as a percentage, if it is a whole number of
+// basis points.
+func (r *RTRowBP) UnmarshalText(text []byte) error {
+	var row RTRow
+	err := row.UnmarshalText(text)
+	if err != nil {
+		return err
+	}
+	*r, err = row.ToBP()
+	return err
+}
+
+// ToBP converts this row to basis points.
+//
+// It is an error if its rate is not a whole number of basis points.
+func (r RTRow) ToBP() (RTRowBP, error) {
+	bp, err := BasisPointsFrom(r.Rate)
+	if err != nil {
+		return RTRowBP{}, errors.Wrapf(err, "row from %s", r.From)
+	}
+	return RTRowBP{From: r.From, Rate: bp}, nil
+}
+
+// ToRTRow converts this row to a Rate
+func (r RTRowBP) ToRTRow() (RTRow, error) {
+	rate, err := r.Rate.Rate()
+	if err != nil {
+		return RTRow{}, errors.Wrapf(err, "row from %s", r.From)
+	}
+	return RTRow{From: r.From, Rate: rate}, nil
+}
+
+// A RateTableBP is a RateTable whose rates are in basis points.
+//
+// It is the form in which to write and review rate tables; convert it with
+// ToRateTable for calculation. As with RateTable, it is a logic error if its
+// elements are not sorted in increasing order by their From field.
+type RateTableBP []RTRowBP
+
+// ToBP converts this rate table to basis points.
+//
+// It is an error if any rate is not a whole number of basis points.
+func (rt RateTable) ToBP() (RateTableBP, error) {
+	out := make(RateTableBP, 0, len(rt))
+	for idx, row := range rt {
+		bpRow, err := row.ToBP()
+		if err != nil {
+			return nil, errors.Wrapf(err, "row %d", idx)
+		}
+		out = append(out, bpRow)
+	}
+	return out, nil
+}
+
+// ToRateTable converts this rate table to a RateTable
+func (rt RateTableBP) ToRateTable() (RateTable, error) {
+	out := make(RateTable, 0, len(rt))
+	for idx, row := range rt {
+		rtRow, err := row.ToRTRow()
+		if err != nil {
+			return nil, errors.Wrapf(err, "row %d", idx)
+		}
+		out = append(out, rtRow)
+	}
+	return out, nil
+}
+
+var _ encoding.TextMarshaler = (*RateTableBP)(nil)
+var _ encoding.TextUnmarshaler = (*RateTableBP)(nil)
+var _ json.Marshaler = (*RateTableBP)(nil)
+var _ json.Unmarshaler = (*RateTableBP)(nil)
+
+// MarshalText implements encoding.TextMarshaler
+//
+// Rows are written in their RTRowBP form, separated by ", ": for example,
+// "3m:100bp, 6m:200bp, 1y:300bp".
+func (rt RateTableBP) MarshalText() ([]byte, error) {
+	rows := make([]string, 0, len(rt))
+	for _, row := range rt {
+		text, err := row.MarshalText()
+		if err != nil {
+			return nil, errors.Wrap(err, "marshaling row")
+		}
+		rows = append(rows, string(text))
+	}
+	return []byte(strings.Join(rows, ", ")), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+//
+// As with RateTable, rows may be separated by commas, whitespace, or both,
+// and must be sorted in strictly increasing order by their From field.
+func (rt *RateTableBP) UnmarshalText(text []byte) error {
+	if !utf8.Valid(text) {
+		return errors.New("text was not utf-8")
+	}
+	fields := strings.FieldsFunc(string(text), func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+	table := make(RateTableBP, 0, len(fields))
+	for idx, field := range fields {
+		var row RTRowBP
+		err := row.UnmarshalText([]byte(field))
+		if err != nil {
+			return errors.Wrapf(err, "row %d", idx)
+		}
+		if idx > 0 && row.From <= table[idx-1].From {
+			return fmt.Errorf("row %d: rows must be sorted in increasing order of From", idx)
+		}
+		table = append(table, row)
+	}
+	*rt = table
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler
+//
+// Like RateTable, a RateTableBP is a list of rows in JSON.
+func (rt RateTableBP) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]RTRowBP(rt))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+//
+// Both the list form written by MarshalJSON and the string form written by
+// MarshalText are accepted.
+func (rt *RateTableBP) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		return rt.UnmarshalText([]byte(text))
+	}
+	var rows []RTRowBP
+	err := json.Unmarshal(data, &rows)
+	if err != nil {
+		return err
+	}
+	*rt = RateTableBP(rows)
+	return nil
+}
@@ -0,0 +1,129 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/json"
+	gomath "math"
+	"testing"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBasisPoints(t *testing.T) {
+	tests := []struct {
+		bp   BasisPoints
+		rate Rate
+		text string
+	}{
+		{0, 0, "0bp"},
+		{1, RateFromPercent(1) / 100, "1bp"},
+		{450, RateFromPercent(9) / 2, "450bp"},
+		{10000, RateFromPercent(100), "10000bp"},
+		{-25, -RateFromPercent(1) / 4, "-25bp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			rate, err := tt.bp.Rate()
+			require.NoError(t, err)
+			require.Equal(t, tt.rate, rate)
+
+			bp, err := BasisPointsFrom(tt.rate)
+			require.NoError(t, err)
+			require.Equal(t, tt.bp, bp)
+			require.Equal(t, tt.bp, tt.rate.BasisPoints())
+
+			require.Equal(t, tt.text, tt.bp.String())
+			parsed, err := ParseBasisPoints(" " + tt.text + " ")
+			require.NoError(t, err)
+			require.Equal(t, tt.bp, parsed)
+		})
+	}
+}
+
+func TestBasisPointsErrors(t *testing.T) {
+	_, err := BasisPointsFrom(RateFromPercent(1)/100 + 1)
+	require.Equal(t, ErrFractionalBasisPoints, errors.Cause(err))
+
+	_, err = BasisPoints(gomath.MaxInt64).Rate()
+	require.Error(t, err)
+
+	for _, s := range []string{"", "450", "bp", "4.5bp", "450%", "450 bp", "450BP"} {
+		_, err := ParseBasisPoints(s)
+		require.Error(t, err, "%q", s)
+	}
+}
+
+func TestRateTableBP(t *testing.T) {
+	bp, err := DefaultLockBonusEAI.ToBP()
+	require.NoError(t, err)
+	require.Equal(t, RateTableBP{
+		{From: 3 * math.Month, Rate: 100},
+		{From: 6 * math.Month, Rate: 200},
+		{From: 1 * math.Year, Rate: 300},
+		{From: 2 * math.Year, Rate: 400},
+		{From: 3 * math.Year, Rate: 500},
+	}, bp)
+	back, err := bp.ToRateTable()
+	require.NoError(t, err)
+	require.Equal(t, DefaultLockBonusEAI, back)
+
+	text, err := bp.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "3m:100bp, 6m:200bp, 1y:300bp, 2y:400bp, 3y:500bp", string(text))
+	var parsed RateTableBP
+	require.NoError(t, parsed.UnmarshalText(text))
+	require.Equal(t, bp, parsed)
+
+	// the JSON form is a list of rows, and the text form is also accepted
+	data, err := json.Marshal(bp)
+	require.NoError(t, err)
+	require.Equal(t, `["3m:100bp","6m:200bp","1y:300bp","2y:400bp","3y:500bp"]`, string(data))
+	parsed = nil
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	require.Equal(t, bp, parsed)
+	parsed = nil
+	require.NoError(t, json.Unmarshal([]byte(`"3m:100bp 6m:200bp"`), &parsed))
+	require.Equal(t, bp[:2], parsed)
+
+	// rates which aren't whole basis points don't convert
+	_, err = RateTable{{From: 0, Rate: RateFromPercent(1) + 1}}.ToBP()
+	require.Equal(t, ErrFractionalBasisPoints, errors.Cause(err))
+	_, err = RateTableBP{{From: 0, Rate: gomath.MaxInt64}}.ToRateTable()
+	require.Error(t, err)
+
+	require.Error(t, parsed.UnmarshalText([]byte("6m:200bp 3m:100bp")))
+}
+
+func TestRateTableEitherSyntax(t *testing.T) {
+	// while configuration migrates, either table reads either syntax
+	const mixed = "3m:1%, 6m:200bp, 1y:3.00%, 2y:400bp, 3y:5%"
+
+	var rt RateTable
+	require.NoError(t, rt.UnmarshalText([]byte(mixed)))
+	require.Equal(t, DefaultLockBonusEAI, rt)
+
+	var bp RateTableBP
+	require.NoError(t, bp.UnmarshalText([]byte(mixed)))
+	want, err := DefaultLockBonusEAI.ToBP()
+	require.NoError(t, err)
+	require.Equal(t, want, bp)
+
+	rt = nil
+	require.NoError(t, rt.UnmarshalText([]byte("1y:450bp")))
+	require.Equal(t, RateTable{{From: math.Year, Rate: RateFromPercent(9) / 2}}, rt)
+
+	// but a percentage finer than a basis point can't be read as basis points
+	err = bp.UnmarshalText([]byte("1y:4.505%"))
+	require.Equal(t, ErrFractionalBasisPoints, errors.Cause(err))
+}
@@ -91,3 +91,22 @@ func TestRateSliceSpanningThreePeriodsReturnsThreeElements(t *testing.T) {
 		})
 	}
 }
+
+func TestRateSliceNegativeRates(t *testing.T) {
+	table := RateTable{
+		{From: 0, Rate: RateFromPercent(1)},
+		{From: 30 * math.Day, Rate: -RateFromPercent(2)},
+		{From: 60 * math.Day, Rate: -RateFromPercent(1)},
+	}
+	require.Equal(t, RateSlice{
+		{Rate: RateFromPercent(1), Duration: 15 * math.Day},
+		{Rate: -RateFromPercent(2), Duration: 30 * math.Day},
+		{Rate: -RateFromPercent(1), Duration: 15 * math.Day},
+	}, table.Slice(15*math.Day, 75*math.Day, 0))
+
+	// freezing holds a negative rate as it does any other
+	require.Equal(t, RateSlice{
+		{Rate: RateFromPercent(1), Duration: 15 * math.Day},
+		{Rate: -RateFromPercent(2), Duration: 45 * math.Day},
+	}, table.SliceF(15*math.Day, 75*math.Day, 0, 30*math.Day))
+}
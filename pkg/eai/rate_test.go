@@ -11,6 +11,9 @@ package eai
 
 
 import (
+	"bytes"
+	"encoding/json"
+	gomath "math"
 	"reflect"
 	"testing"
 
@@ -29,6 +32,11 @@ func TestRate_String(t *testing.T) {
 		{"1000", RateFromPercent(1000), "1000%"},
 		{"0.5", RateFromPercent(1) / 2, "0.5%"},
 		{"0.001", RateFromPercent(1) / 1000, "0.001%"},
+		{"0", 0, "0%"},
+		{"-1", -RateFromPercent(1), "-1%"},
+		{"-0.5", -RateFromPercent(1) / 2, "-0.5%"},
+		{"-1000.001", -RateFromPercent(1000) - RateFromPercent(1)/1000, "-1000.001%"},
+		{"least", gomath.MinInt64, "-922337203.6854775808%"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -64,6 +72,12 @@ func TestParseRate(t *testing.T) {
 		{"0.5t", args{"0.5%"}, RateFromPercent(1) / 2, false},
 		{"0.001t", args{"0.001%"}, RateFromPercent(1) / 1000, false},
 		{"too much precision", args{"1.00000000001%"}, RateFromPercent(0), true},
+		{"-1", args{"-1%"}, -RateFromPercent(1), false},
+		{"-0.5", args{" -0.5% "}, -RateFromPercent(1) / 2, false},
+		{"-0", args{"-0%"}, 0, false},
+		{"plus", args{"+1%"}, Rate(0), true},
+		{"spaced sign", args{"- 1%"}, Rate(0), true},
+		{"double sign", args{"--1%"}, Rate(0), true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -137,3 +151,145 @@ func TestRTRow_UnmarshalText(t *testing.T) {
 		})
 	}
 }
+
+func TestRate_BasisPointsPercent(t *testing.T) {
+	tests := []struct {
+		in      Rate
+		bp      BasisPoints
+		percent float64
+	}{
+		{0, 0, 0},
+		{RateFromPercent(2), 200, 2},
+		{RateFromPercent(15), 1500, 15},
+		{RateFromPercent(1) / 4, 25, 0.25},
+		{RateFromPercent(1)/100 - 1, 0, 0.01},
+		{-RateFromPercent(3), -300, -3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in.String(), func(t *testing.T) {
+			if got := tt.in.BasisPoints(); got != tt.bp {
+				t.Errorf("Rate.BasisPoints() = %d, want %d", got, tt.bp)
+			}
+			if got := tt.in.Percent(); gomath.Abs(got-tt.percent) > 1e-9 {
+				t.Errorf("Rate.Percent() = %v, want %v", got, tt.percent)
+			}
+		})
+	}
+}
+
+func TestRateTable_MarshalText(t *testing.T) {
+	text, err := DefaultLockBonusEAI.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "3m:1%, 6m:2%, 1y:3%, 2y:4%, 3y:5%", string(text))
+
+	var rt RateTable
+	err = rt.UnmarshalText(text)
+	require.NoError(t, err)
+	require.Equal(t, DefaultLockBonusEAI, rt)
+
+	text, err = RateTable{}.MarshalText()
+	require.NoError(t, err)
+	require.Empty(t, text)
+}
+
+func TestRateTable_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    RateTable
+		wantErr bool
+	}{
+		{"empty", "", RateTable{}, false},
+		{"single", "90d:4%", RateTable{{From: 90 * math.Day, Rate: RateFromPercent(4)}}, false},
+		{"whitespace separated", "90d:4%\n1y:5.5%", RateTable{
+			{From: 90 * math.Day, Rate: RateFromPercent(4)},
+			{From: math.Year, Rate: RateFromPercent(11) / 2},
+		}, false},
+		{"comma separated", "90d:4%,1y:5%,", RateTable{
+			{From: 90 * math.Day, Rate: RateFromPercent(4)},
+			{From: math.Year, Rate: RateFromPercent(5)},
+		}, false},
+		{"unsorted", "1y:5%, 90d:4%", nil, true},
+		{"duplicate from", "90d:4%, 90d:5%", nil, true},
+		{"bad row", "90d:4%, 1y", nil, true},
+		{"bad rate", "90d:4", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got RateTable
+			err := got.UnmarshalText([]byte(tt.text))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RateTable.UnmarshalText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RateTable.UnmarshalText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateTable_JSON(t *testing.T) {
+	// the JSON form of a rate table remains a list of rows
+	data, err := json.Marshal(DefaultLockBonusEAI)
+	require.NoError(t, err)
+	require.Equal(t, `["3m:1%","6m:2%","1y:3%","2y:4%","3y:5%"]`, string(data))
+
+	var rt RateTable
+	err = json.Unmarshal(data, &rt)
+	require.NoError(t, err)
+	require.Equal(t, DefaultLockBonusEAI, rt)
+
+	// the text form is also accepted
+	rt = nil
+	err = json.Unmarshal([]byte(`"3m:1%, 6m:2%, 1y:3%, 2y:4%, 3y:5%"`), &rt)
+	require.NoError(t, err)
+	require.Equal(t, DefaultLockBonusEAI, rt)
+}
+
+func TestRateTable_Render(t *testing.T) {
+	buf := new(bytes.Buffer)
+	err := DefaultLockBonusEAI[:2].Render(buf)
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"  From  Until  Nominal  Effective\n"+
+		"    3m     6m       1%    1.0050%\n"+
+		"    6m      -       2%    2.0201%\n",
+		buf.String(),
+	)
+}
+
+func TestRateTable_NextBoundaryAfter(t *testing.T) {
+	rt := RateTable{
+		{From: 1 * math.Month, Rate: RateFromPercent(2)},
+		{From: 2 * math.Month, Rate: RateFromPercent(3)},
+		{From: 3 * math.Month, Rate: RateFromPercent(3)},
+		{From: 4 * math.Month, Rate: RateFromPercent(7)},
+	}
+	tests := []struct {
+		name         string
+		waa          math.Duration
+		wantBoundary math.Duration
+		wantRate     Rate
+		wantOk       bool
+	}{
+		{"before table", 0, 1 * math.Month, RateFromPercent(2), true},
+		{"at boundary", 1 * math.Month, 2 * math.Month, RateFromPercent(3), true},
+		{"within row", 1*math.Month + 9*math.Day, 2 * math.Month, RateFromPercent(3), true},
+		{"skips unchanged rate", 2*math.Month + 1, 4 * math.Month, RateFromPercent(7), true},
+		{"in unchanged row", 3 * math.Month, 4 * math.Month, RateFromPercent(7), true},
+		{"last row", 4 * math.Month, 0, 0, false},
+		{"after table", 10 * math.Year, 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			boundary, rate, ok := rt.NextBoundaryAfter(tt.waa)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.wantBoundary, boundary)
+			require.Equal(t, tt.wantRate, rate)
+		})
+	}
+
+	_, _, ok := RateTable{}.NextBoundaryAfter(0)
+	require.False(t, ok)
+}
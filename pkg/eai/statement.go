@@ -0,0 +1,312 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// An EventKind is a kind of AccountEvent
+type EventKind string
+
+// These are the kinds of AccountEvent
+const (
+	// EventTransfer changes the balance by the event's Amount: positive when
+	// ndau are received, negative when they are sent
+	EventTransfer EventKind = "transfer"
+	// EventCreditEAI credits all EAI accrued so far to the balance
+	EventCreditEAI EventKind = "credit-eai"
+	// EventLock locks the account with the event's NoticePeriod
+	EventLock EventKind = "lock"
+	// EventNotify gives notice to unlock the account's lock
+	EventNotify EventKind = "notify"
+	// EventUnlock removes a lock whose notice period has elapsed
+	EventUnlock EventKind = "unlock"
+)
+
+// An AccountEvent is a change to an account which affects its EAI
+type AccountEvent struct {
+	At   math.Timestamp
+	Kind EventKind
+	// Amount is the change in balance of an EventTransfer
+	Amount math.Ndau `json:",omitempty"`
+	// NoticePeriod is the notice period of an EventLock
+	NoticePeriod math.Duration `json:",omitempty"`
+}
+
+// StatementTables are the rate tables in effect for a Statement
+type StatementTables struct {
+	// Unlocked is the table of rates by weighted average age
+	Unlocked RateTable
+	// LockBonus is the table of lock bonus rates by notice period
+	LockBonus RateTable
+}
+
+// A StatementPeriod is a span of a Statement during which the account was
+// unchanged
+type StatementPeriod struct {
+	From, To math.Timestamp
+	// Events are the events which began the period, if any
+	Events []AccountEvent `json:",omitempty"`
+	// Balance is the balance throughout the period
+	Balance math.Ndau
+	// WeightedAverageAge is the weighted average age at the start of the
+	// period; it grows in step with time until the period's end.
+	WeightedAverageAge math.Duration
+	// Lock is the account's lock during the period, or nil if it had none
+	Lock *BasicLock `json:",omitempty"`
+	// EAIAccrued is the EAI earned during the period
+	EAIAccrued math.Ndau
+	// Bands attribute the period's EAI to the rates at which it was earned
+	Bands []Attribution `json:",omitempty"`
+}
+
+// A Statement reports the EAI an account earned between two times
+type Statement struct {
+	From, To math.Timestamp
+	// These are the balances and EAI not yet credited at From and To
+	OpeningBalance, ClosingBalance       math.Ndau
+	OpeningUncredited, ClosingUncredited math.Ndau
+	// EAIAccrued is the EAI earned between From and To, whenever credited
+	EAIAccrued math.Ndau
+	// EAICredited is the EAI credited to the balance between From and To,
+	// whenever earned
+	EAICredited math.Ndau
+	// Periods are the spans between From, To, and each event between them,
+	// in order
+	Periods []StatementPeriod
+}
+
+// statementAccount is the state of an account as a Statement replays its
+// events
+type statementAccount struct {
+	balance    math.Ndau
+	waa        math.Duration
+	lock       *BasicLock
+	uncredited math.Ndau
+	// at is the time to which the account has been brought up to date
+	at math.Timestamp
+}
+
+// accrue brings the account up to date at the given time, returning the EAI
+// earned since it was last brought up to date
+func (s *statementAccount) accrue(at math.Timestamp, tables StatementTables, opts CalcOptions) (math.Ndau, []Attribution, error) {
+	s.waa += at.Since(s.at)
+	var lock Lock
+	if s.lock != nil {
+		lock = s.lock
+	}
+	eai, bands, err := CalculateAttributed(
+		s.balance,
+		at, s.at,
+		s.waa, lock,
+		tables.Unlocked,
+		opts,
+	)
+	if err != nil {
+		return 0, nil, err
+	}
+	s.at = at
+	s.uncredited, err = s.uncredited.Add(eai)
+	if err != nil {
+		return 0, nil, errors.Wrap(err, "accruing EAI")
+	}
+	return eai, bands, nil
+}
+
+// apply applies an event to an account which is up to date at its time
+func (s *statementAccount) apply(event AccountEvent, tables StatementTables) (credited math.Ndau, err error) {
+	switch event.Kind {
+	case EventTransfer:
+		if event.Amount > 0 && s.lock.IsNotified() {
+			return 0, errors.New("notified accounts can't receive transfers")
+		}
+		err = s.waa.UpdateWeightedAverageAge(0, event.Amount, s.balance)
+		if err != nil {
+			return 0, errors.Wrap(err, "updating weighted average age")
+		}
+		s.balance, err = s.balance.Add(event.Amount)
+		if err != nil {
+			return 0, err
+		}
+		if s.balance < 0 {
+			return 0, errors.New("balance is negative")
+		}
+	case EventCreditEAI:
+		// credited EAI doesn't change the weighted average age
+		s.balance, err = s.balance.Add(s.uncredited)
+		if err != nil {
+			return 0, err
+		}
+		credited, s.uncredited = s.uncredited, 0
+	case EventLock:
+		if s.lock != nil {
+			return 0, errors.New("account is already locked")
+		}
+		if event.NoticePeriod < 0 {
+			return 0, ErrNegativeNoticePeriod
+		}
+		s.lock = NewBasicLock(event.NoticePeriod, tables.LockBonus)
+	case EventNotify:
+		if s.lock == nil {
+			return 0, errors.New("account is not locked")
+		}
+		err = s.lock.Notify(event.At)
+	case EventUnlock:
+		err = s.lock.Unlock(event.At)
+		s.lock = nil
+	default:
+		return 0, errors.Errorf("unknown event kind %q", event.Kind)
+	}
+	return credited, err
+}
+
+// lockCopy returns a copy of the account's lock, so that a StatementPeriod
+// isn't changed by later events
+func (s *statementAccount) lockCopy() *BasicLock {
+	if s.lock == nil {
+		return nil
+	}
+	lock := *s.lock
+	if lock.UnlocksOn != nil {
+		unlocksOn := *lock.UnlocksOn
+		lock.UnlocksOn = &unlocksOn
+	}
+	return &lock
+}
+
+// GenerateStatement reports the EAI an account earned between from and to,
+// as for an annual tax statement.
+//
+// events is the account's whole history, in order, from its creation: the
+// events before from establish its state at from, and those from from until
+// to are reported. Events after to are ignored. The account is created with
+// no balance at its first event.
+//
+// EAI is accrued exactly as CalculateWith calculates it under opts, over
+// each period between events, so a statement can be reconciled with the
+// chain. Locks are given the bonus rate of tables.LockBonus at their notice
+// period.
+func GenerateStatement(events []AccountEvent, from, to math.Timestamp, tables StatementTables, opts CalcOptions) (*Statement, error) {
+	if to < from {
+		return nil, errors.New("statement ends before it begins")
+	}
+	statement := Statement{From: from, To: to}
+	var account *statementAccount
+	// period is the period of the statement in progress; it is nil until
+	// the replay reaches from
+	var period *StatementPeriod
+
+	// advance brings the account up to date at the given time, completing
+	// the period in progress there
+	advance := func(at math.Timestamp) error {
+		var eai math.Ndau
+		var bands []Attribution
+		var err error
+		if account != nil {
+			eai, bands, err = account.accrue(at, tables, opts)
+			if err != nil {
+				return errors.Wrapf(err, "accruing EAI until %s", at)
+			}
+		}
+		if period == nil {
+			return nil
+		}
+		period.To = at
+		period.EAIAccrued = eai
+		period.Bands = bands
+		statement.EAIAccrued, err = statement.EAIAccrued.Add(eai)
+		if err != nil {
+			return errors.Wrap(err, "summing EAI")
+		}
+		statement.Periods = append(statement.Periods, *period)
+		return nil
+	}
+	// open begins a period at the account's current state
+	open := func(at math.Timestamp) {
+		period = &StatementPeriod{From: at}
+		if account != nil {
+			period.Balance = account.balance
+			period.WeightedAverageAge = account.waa
+			period.Lock = account.lockCopy()
+		}
+	}
+	// begin begins the statement
+	begin := func() error {
+		err := advance(from)
+		if err != nil {
+			return err
+		}
+		if account != nil {
+			statement.OpeningBalance = account.balance
+			statement.OpeningUncredited = account.uncredited
+		}
+		open(from)
+		return nil
+	}
+
+	for idx, event := range events {
+		if idx > 0 && event.At < events[idx-1].At {
+			return nil, errors.Errorf("event %d: events are not in order", idx)
+		}
+		if event.At >= to {
+			break
+		}
+		if period == nil && event.At >= from {
+			if err := begin(); err != nil {
+				return nil, err
+			}
+		}
+		// simultaneous events begin the same period
+		if period == nil || period.From < event.At {
+			if err := advance(event.At); err != nil {
+				return nil, err
+			}
+			if period != nil {
+				open(event.At)
+			}
+		}
+
+		if account == nil {
+			account = &statementAccount{at: event.At}
+		}
+		credited, err := account.apply(event, tables)
+		if err != nil {
+			return nil, errors.Wrapf(err, "event %d (%s at %s)", idx, event.Kind, event.At)
+		}
+		if period != nil {
+			statement.EAICredited, err = statement.EAICredited.Add(credited)
+			if err != nil {
+				return nil, errors.Wrap(err, "summing credited EAI")
+			}
+			// the period reflects the effects of the events which began it
+			began := append(period.Events, event)
+			open(event.At)
+			period.Events = began
+		}
+	}
+
+	if period == nil {
+		// no event fell within the statement
+		if err := begin(); err != nil {
+			return nil, err
+		}
+	}
+	if err := advance(to); err != nil {
+		return nil, err
+	}
+	if account != nil {
+		statement.ClosingBalance = account.balance
+		statement.ClosingUncredited = account.uncredited
+	}
+	return &statement, nil
+}
@@ -0,0 +1,202 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+var statementTables = StatementTables{
+	Unlocked:  DefaultUnlockedEAI,
+	LockBonus: DefaultLockBonusEAI,
+}
+
+func day(n int64) math.Timestamp {
+	return math.Timestamp(n * int64(math.Day))
+}
+
+// requireReconciles checks the statement's totals against its periods
+func requireReconciles(t *testing.T, s *Statement) {
+	var accrued math.Ndau
+	net := s.OpeningBalance
+	for idx, p := range s.Periods {
+		if idx == 0 {
+			require.Equal(t, s.From, p.From)
+		} else {
+			require.Equal(t, s.Periods[idx-1].To, p.From)
+		}
+		var bands math.Ndau
+		for _, b := range p.Bands {
+			bands += b.EAI
+		}
+		require.Equal(t, p.EAIAccrued, bands, "period %d", idx)
+		accrued += p.EAIAccrued
+		for _, e := range p.Events {
+			if e.Kind == EventTransfer {
+				net += e.Amount
+			}
+		}
+	}
+	require.Equal(t, s.To, s.Periods[len(s.Periods)-1].To)
+	require.Equal(t, s.EAIAccrued, accrued)
+	require.Equal(t, s.ClosingUncredited, s.OpeningUncredited+s.EAIAccrued-s.EAICredited)
+	require.Equal(t, s.ClosingBalance, net+s.EAICredited)
+}
+
+func TestStatementWithoutEvents(t *testing.T) {
+	balance := 1000 * math.Ndau(constants.QuantaPerUnit)
+	events := []AccountEvent{{At: day(0), Kind: EventTransfer, Amount: balance}}
+
+	s, err := GenerateStatement(events, day(365), day(730), statementTables, spec)
+	require.NoError(t, err)
+	requireReconciles(t, s)
+	require.Len(t, s.Periods, 1)
+	p := s.Periods[0]
+	require.Empty(t, p.Events)
+	require.Equal(t, balance, p.Balance)
+	require.Equal(t, math.Duration(365*math.Day), p.WeightedAverageAge)
+	require.Nil(t, p.Lock)
+
+	// the EAI of the statement is that of each step of the account's history
+	before, err := CalculateWith(nil, balance, day(365), day(0), 365*math.Day, nil, DefaultUnlockedEAI, spec)
+	require.NoError(t, err)
+	during, err := CalculateWith(nil, balance, day(730), day(365), 730*math.Day, nil, DefaultUnlockedEAI, spec)
+	require.NoError(t, err)
+	require.Equal(t, before, s.OpeningUncredited)
+	require.Equal(t, during, s.EAIAccrued)
+	require.Equal(t, balance, s.OpeningBalance)
+	require.Equal(t, balance, s.ClosingBalance)
+	require.Equal(t, math.Ndau(0), s.EAICredited)
+}
+
+func TestStatement(t *testing.T) {
+	unit := math.Ndau(constants.QuantaPerUnit)
+	events := []AccountEvent{
+		{At: day(0), Kind: EventTransfer, Amount: 1000 * unit},
+		{At: day(30), Kind: EventLock, NoticePeriod: 180 * math.Day},
+		{At: day(100), Kind: EventCreditEAI},
+		// the statement runs from day 200
+		{At: day(250), Kind: EventCreditEAI},
+		{At: day(250), Kind: EventTransfer, Amount: -100 * unit},
+		{At: day(300), Kind: EventNotify},
+		{At: day(500), Kind: EventUnlock},
+		{At: day(520), Kind: EventTransfer, Amount: 500 * unit},
+		// after the statement
+		{At: day(600), Kind: EventCreditEAI},
+	}
+
+	s, err := GenerateStatement(events, day(200), day(565), statementTables, spec)
+	require.NoError(t, err)
+	requireReconciles(t, s)
+	require.Len(t, s.Periods, 5)
+	require.Equal(t, []math.Timestamp{day(200), day(250), day(300), day(500), day(520)}, []math.Timestamp{
+		s.Periods[0].From, s.Periods[1].From, s.Periods[2].From, s.Periods[3].From, s.Periods[4].From,
+	})
+
+	// simultaneous events begin a single period
+	require.Empty(t, s.Periods[0].Events)
+	require.Equal(t, events[3:5], s.Periods[1].Events)
+	require.Equal(t, s.Periods[0].Balance+s.EAICredited-100*unit, s.Periods[1].Balance)
+
+	// the lock is followed through notice and unlock
+	bonus := DefaultLockBonusEAI.RateAt(180 * math.Day)
+	require.NotZero(t, bonus)
+	for _, p := range s.Periods[:2] {
+		require.NotNil(t, p.Lock)
+		require.False(t, p.Lock.IsNotified())
+		require.Equal(t, bonus, p.Lock.BonusRate)
+	}
+	require.True(t, s.Periods[2].Lock.IsNotified())
+	require.Equal(t, day(480), *s.Periods[2].Lock.UnlocksOn)
+	require.Nil(t, s.Periods[3].Lock)
+	for _, p := range s.Periods {
+		require.NotZero(t, p.EAIAccrued)
+	}
+
+	// only EAI credited within the statement counts as credited
+	require.NotZero(t, s.OpeningUncredited)
+	require.NotZero(t, s.EAICredited)
+	require.NotZero(t, s.ClosingUncredited)
+	require.Equal(t, s.OpeningBalance+s.EAICredited+400*unit, s.ClosingBalance)
+
+	// the statement survives a roundtrip through JSON
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	var decoded Statement
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, *s, decoded)
+}
+
+func TestStatementBeforeAccount(t *testing.T) {
+	unit := math.Ndau(constants.QuantaPerUnit)
+	events := []AccountEvent{{At: day(100), Kind: EventTransfer, Amount: 10 * unit}}
+	s, err := GenerateStatement(events, day(0), day(200), statementTables, spec)
+	require.NoError(t, err)
+	requireReconciles(t, s)
+	require.Len(t, s.Periods, 2)
+	require.Equal(t, math.Ndau(0), s.Periods[0].Balance)
+	require.Equal(t, math.Ndau(0), s.Periods[0].EAIAccrued)
+	require.Equal(t, math.Ndau(0), s.OpeningBalance)
+	require.Equal(t, 10*unit, s.ClosingBalance)
+
+	s, err = GenerateStatement(nil, day(0), day(200), statementTables, spec)
+	require.NoError(t, err)
+	require.Len(t, s.Periods, 1)
+	require.Equal(t, Statement{
+		From: day(0), To: day(200),
+		Periods: []StatementPeriod{{From: day(0), To: day(200)}},
+	}, *s)
+}
+
+func TestStatementErrors(t *testing.T) {
+	unit := math.Ndau(constants.QuantaPerUnit)
+	funded := AccountEvent{At: day(0), Kind: EventTransfer, Amount: 10 * unit}
+	tests := []struct {
+		name   string
+		events []AccountEvent
+	}{
+		{"out of order", []AccountEvent{funded, {At: day(20)}, {At: day(10)}}},
+		{"overdrawn", []AccountEvent{funded, {At: day(10), Kind: EventTransfer, Amount: -11 * unit}}},
+		{"unknown kind", []AccountEvent{funded, {At: day(10), Kind: "stake"}}},
+		{"relock", []AccountEvent{
+			funded,
+			{At: day(10), Kind: EventLock, NoticePeriod: 90 * math.Day},
+			{At: day(20), Kind: EventLock, NoticePeriod: 90 * math.Day},
+		}},
+		{"notify unlocked", []AccountEvent{funded, {At: day(10), Kind: EventNotify}}},
+		{"early unlock", []AccountEvent{
+			funded,
+			{At: day(10), Kind: EventLock, NoticePeriod: 90 * math.Day},
+			{At: day(20), Kind: EventNotify},
+			{At: day(30), Kind: EventUnlock},
+		}},
+		{"transfer to notified", []AccountEvent{
+			funded,
+			{At: day(10), Kind: EventLock, NoticePeriod: 90 * math.Day},
+			{At: day(20), Kind: EventNotify},
+			{At: day(30), Kind: EventTransfer, Amount: unit},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateStatement(tt.events, day(0), day(365), statementTables, spec)
+			require.Error(t, err)
+		})
+	}
+
+	_, err := GenerateStatement([]AccountEvent{funded}, day(10), day(5), statementTables, spec)
+	require.Error(t, err)
+}
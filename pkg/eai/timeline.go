@@ -0,0 +1,220 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+)
+
+// A Timeline is a stepped sequence of rate bands over effective account
+// age, which may be frozen at some age. It is the engine behind
+// RateTable.SliceF, and so behind every EAI calculation.
+//
+// Each band begins at its From age and lasts until the next band begins;
+// the rate before the first band is 0. A Timeline is sliced between two
+// ages into the rates in effect and the time spent at each:
+//
+//   R2                            ┌────────────|───...
+//   R1              ┌────|────────┘ / / / / / /|
+//   R0  ────────────┘    | / / / / / / / / / / |
+//                      from                    to
+//
+//   Slice(from, to) = [R1 for (b2 - from)] [R2 for (to - b2)]
+//
+// Once frozen, time continues to pass, but the rate stays at that of the
+// freeze point. This is what happens to a notified account: its effective
+// age stops advancing once notice is given, so that its lock bonus no longer
+// grows, but it continues to earn EAI at the rate it had reached.
+//
+//   R3                                      b3 ┌ ─ ─ ─ ─ ─ ─ ─...
+//   R2                        ┌────────────────────────────────|─
+//   R1              ┌────|────┘ / / / / / / / | / / / / / / / /|
+//   R0  ────────────┘    | / / / / / / / / / /|/ / / / / / / / |
+//                      from   b2           frozen              to
+//
+//   Slice(from, to) = [R1 for (b2 - from)] [R2 for (to - b2)]
+//
+// From the freeze point to its end, the slice above stays at R2, although
+// the rate would have risen to R3 at b3. Freezing at or after the end of a
+// slice has no effect on it; freezing before its start gives a single band
+// at the rate of the freeze point.
+//
+// The zero value is an empty Timeline, whose rate is always 0.
+type Timeline struct {
+	bands    RateTable
+	frozen   bool
+	frozenAt math.Duration
+}
+
+// Timeline returns a Timeline of the rows of this table
+//
+// The Timeline has its own copy of the rows, so adding bands to it leaves
+// the table unchanged.
+func (rt RateTable) Timeline() Timeline {
+	return Timeline{bands: append(RateTable(nil), rt...)}
+}
+
+// AddBand adds a band at the given rate, starting at the given age
+//
+// Bands must be added in strictly increasing order of age.
+func (tl *Timeline) AddBand(from math.Duration, rate Rate) error {
+	if n := len(tl.bands); n > 0 && from <= tl.bands[n-1].From {
+		return fmt.Errorf("band at %s must follow the last band, at %s", from, tl.bands[n-1].From)
+	}
+	tl.bands = append(tl.bands, RTRow{From: from, Rate: rate})
+	return nil
+}
+
+// Freeze the timeline at the given age, replacing any earlier freeze
+func (tl *Timeline) Freeze(at math.Duration) {
+	tl.frozen, tl.frozenAt = true, at
+}
+
+// Unfreeze the timeline
+func (tl *Timeline) Unfreeze() {
+	tl.frozen = false
+}
+
+// FrozenAt returns the age at which the timeline is frozen, if it is
+func (tl Timeline) FrozenAt() (at math.Duration, ok bool) {
+	return tl.frozenAt, tl.frozen
+}
+
+// RateAt returns the rate in effect at the given age, ignoring any freeze
+func (tl Timeline) RateAt(age math.Duration) Rate {
+	return tl.bands.RateAt(age)
+}
+
+// Slice the timeline between two ages
+//
+// The durations of the slice sum to to - from. If to <= from, the slice is
+// a single band of 0 duration at rate 0. A band which begins exactly at to
+// appears at the end of the slice with 0 duration; EAI calculations
+// reproduce this, for compatibility with those on the chain.
+func (tl Timeline) Slice(from, to math.Duration) RateSlice {
+	return tl.sliceInto(nil, from, to)
+}
+
+// sliceInto is Slice, but reuses the storage of dst when it is large enough
+func (tl Timeline) sliceInto(dst RateSlice, from, to math.Duration) RateSlice {
+	if to <= from {
+		// when actual duration is 0, it's fine to fake that the actual
+		// rate is also 0
+		return append(dst[:0], RSRow{})
+	}
+
+	rt := tl.bands
+	notify := to
+	if tl.frozen && tl.frozenAt < to {
+		notify = tl.frozenAt
+	}
+
+	// the computation can't result in -2, so if after the loop
+	// this remains, we know we never touched this var
+	const uninitialized = -2
+	fromI := uninitialized
+	toI := uninitialized
+	notifyI := uninitialized
+
+	for index, row := range rt {
+		if fromI == uninitialized && from < row.From {
+			fromI = index - 1
+		}
+		if toI == uninitialized && to < row.From {
+			toI = index - 1
+		}
+		if notifyI == uninitialized && notify < row.From {
+			notifyI = index - 1
+		}
+		if fromI != uninitialized && toI != uninitialized && notifyI != uninitialized {
+			break
+		}
+	}
+	// if either variable comes out of the loop wihtout being initialized,
+	// the appropriate row index is the highest in the table
+	if fromI == uninitialized {
+		fromI = len(rt) - 1
+	}
+	if toI == uninitialized {
+		toI = len(rt) - 1
+	}
+	if notifyI == uninitialized {
+		notifyI = len(rt) - 1
+	}
+
+	rateFor := func(idx int) Rate {
+		if idx == -1 {
+			return Rate(0)
+		}
+		return rt[idx].Rate
+	}
+
+	// if we froze before the from point, we have one period at the frozen rate
+	if notify < from {
+		return append(dst[:0], RSRow{Rate: rateFor(notifyI), Duration: to - from})
+	}
+
+	// if from and to are in the same rate block, or
+	// from and the freeze point are in the same rate block, we have one period
+	// at the from rate
+	if fromI == toI || fromI == notifyI {
+		return append(dst[:0], RSRow{Rate: rateFor(fromI), Duration: to - from})
+	}
+	numRows := 1 - fromI
+	if toI <= notifyI {
+		numRows += toI
+	} else {
+		numRows += notifyI
+	}
+
+	// ok, the rest is relatively straightforward. We need special
+	// handling for the first and last rate, because they have partial
+	// durations; the rest are just copies from the rate table
+	rs := dst[:0]
+	if cap(rs) < numRows {
+		rs = make(RateSlice, numRows)
+	}
+	// every row is assigned below
+	rs = rs[:numRows]
+	// - it's safe to index rt[fromI+1] because if fromI were the max value,
+	//   then we would have already returned: fromI must equal toI
+	// - we know that the freeze point > rt[fromI+1].From, because if
+	//   fromI == notifyI, we would have already returned. As we're here, we
+	//   know that the freeze point isn't in this first block.
+	rs[0] = RSRow{Rate: rateFor(fromI), Duration: rt[fromI+1].From - from}
+	// freezing within the final rate block has no effect on the calculation
+	//
+	// it's safe to index rt[toI] and rt[notifyI] because if either were -1,
+	// then we would have already returned: fromI must equal it
+	if notifyI == toI {
+		rs[numRows-1] = RSRow{Rate: rateFor(toI), Duration: to - rt[toI].From}
+	} else {
+		// the frozen block lasts from its start to the end of the slice
+		rs[numRows-1] = RSRow{Rate: rateFor(notifyI), Duration: to - rt[notifyI].From}
+	}
+
+	upperBoundI := toI
+	if notifyI < toI {
+		upperBoundI = notifyI
+	}
+
+	// indexing rt[fromI+i+1] is safe because fromI+i+1 == toI at max i
+	for i := 1; i < upperBoundI-fromI; i++ {
+		rs[i] = RSRow{
+			Rate:     rt[fromI+i].Rate,
+			Duration: rt[fromI+i+1].From - rt[fromI+i].From,
+		}
+	}
+
+	return rs
+}
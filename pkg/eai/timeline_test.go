@@ -0,0 +1,212 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+// exampleTimeline has bands of 1% from day 10, 2% from day 20, and 3% from
+// day 30:
+//
+//   3%                          ┌──────...
+//   2%                ┌─────────┘
+//   1%      ┌─────────┘
+//   0%  ────┘
+//       0   10        20        30
+func exampleTimeline(t *testing.T) Timeline {
+	var tl Timeline
+	for i, pct := range []uint64{1, 2, 3} {
+		require.NoError(t, tl.AddBand(math.Duration(10*(i+1))*math.Day, RateFromPercent(pct)))
+	}
+	return tl
+}
+
+// rs is a shorthand for a slice of rates in percent and durations in days
+func rs(pctDays ...int64) RateSlice {
+	var out RateSlice
+	for i := 0; i < len(pctDays); i += 2 {
+		out = append(out, RSRow{
+			Rate:     Rate(pctDays[i]) * RateFromPercent(1),
+			Duration: math.Duration(pctDays[i+1]) * math.Day,
+		})
+	}
+	return out
+}
+
+func TestTimelineSlice(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to int64
+		// the day at which the timeline is frozen, or -1
+		frozen int64
+		want   RateSlice
+	}{
+		// 1%      ┌──|───────|─┐
+		//         10 15     20 25
+		{"across a boundary", 15, 25, -1, rs(1, 5, 2, 5)},
+		// 0%  ─|──┐ ...   ┌───┐ ...  ┌──|───
+		//      5  10      20  30      30 35
+		{"across every boundary", 5, 35, -1, rs(0, 5, 1, 10, 2, 10, 3, 5)},
+		{"within a band", 21, 29, -1, rs(2, 8)},
+		{"before the first band", 0, 8, -1, rs(0, 8)},
+		{"beyond the last band", 40, 50, -1, rs(3, 10)},
+		// a band beginning at the end of the slice appears with no duration
+		{"ending at a boundary", 15, 30, -1, rs(1, 5, 2, 10, 3, 0)},
+		{"empty", 15, 15, -1, RateSlice{{}}},
+		{"backwards", 25, 15, -1, RateSlice{{}}},
+		// frozen at 25, the 2% band continues past 30
+		//
+		// 2%            ┌───|────*────────|──
+		// 1%      ┌──|──┘   |    *        |
+		//         10 15  20      25 (30)  35
+		{"frozen", 15, 35, 25, rs(1, 5, 2, 15)},
+		{"frozen across several bands", 5, 45, 25, rs(0, 5, 1, 10, 2, 25)},
+		{"frozen in the first band of the slice", 15, 35, 18, rs(1, 20)},
+		{"frozen at a boundary", 15, 35, 20, rs(1, 5, 2, 15)},
+		{"frozen in the last band of the slice", 15, 35, 32, rs(1, 5, 2, 10, 3, 5)},
+		{"frozen before the slice", 25, 35, 15, rs(1, 10)},
+		{"frozen at the end of the slice", 15, 25, 25, rs(1, 5, 2, 5)},
+		{"frozen after the slice", 15, 25, 40, rs(1, 5, 2, 5)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tl := exampleTimeline(t)
+			if tt.frozen >= 0 {
+				tl.Freeze(math.Duration(tt.frozen) * math.Day)
+			}
+			from, to := math.Duration(tt.from)*math.Day, math.Duration(tt.to)*math.Day
+			got := tl.Slice(from, to)
+			require.Equal(t, tt.want, got)
+
+			// slicing a table is slicing its timeline
+			table := RateTable(tl.bands)
+			freeze := math.Duration(0)
+			if at, ok := tl.FrozenAt(); ok && at < to {
+				freeze = to - at
+			}
+			require.Equal(t, got, table.SliceF(from, to, 0, freeze))
+		})
+	}
+}
+
+func TestTimelineBands(t *testing.T) {
+	tl := exampleTimeline(t)
+	require.Error(t, tl.AddBand(30*math.Day, RateFromPercent(4)))
+	require.Error(t, tl.AddBand(25*math.Day, RateFromPercent(4)))
+	require.NoError(t, tl.AddBand(31*math.Day, RateFromPercent(4)))
+	require.Equal(t, RateFromPercent(2), tl.RateAt(20*math.Day))
+	require.Equal(t, RateFromPercent(4), tl.RateAt(40*math.Day))
+
+	_, ok := tl.FrozenAt()
+	require.False(t, ok)
+	tl.Freeze(22 * math.Day)
+	tl.Freeze(25 * math.Day)
+	at, ok := tl.FrozenAt()
+	require.True(t, ok)
+	require.Equal(t, math.Duration(25*math.Day), at)
+	// freezing doesn't change the rate of the bands
+	require.Equal(t, RateFromPercent(4), tl.RateAt(40*math.Day))
+	tl.Unfreeze()
+	require.Equal(t, rs(2, 5, 3, 1, 4, 4), tl.Slice(25*math.Day, 35*math.Day))
+
+	var empty Timeline
+	require.Equal(t, rs(0, 10), empty.Slice(0, 10*math.Day))
+
+	// a table's timeline doesn't share its rows
+	table := RateTable{{From: 0, Rate: RateFromPercent(1)}}
+	fromTable := table[:0:1].Timeline()
+	require.NoError(t, fromTable.AddBand(0, RateFromPercent(5)))
+	require.Equal(t, RateFromPercent(1), table[0].Rate)
+	require.Equal(t, rs(1, 10), table.Timeline().Slice(0, 10*math.Day))
+}
+
+// referenceSlice slices a timeline by brute force, in a canonical form: with
+// no bands of 0 duration, and no adjacent bands at the same rate
+func referenceSlice(tl Timeline, from, to math.Duration) RateSlice {
+	if to <= from {
+		return nil
+	}
+	notify := to
+	if at, ok := tl.FrozenAt(); ok && at < to {
+		notify = at
+	}
+	var out RateSlice
+	add := func(rate Rate, duration math.Duration) {
+		if duration > 0 {
+			out = append(out, RSRow{Rate: rate, Duration: duration})
+		}
+	}
+	if notify < from {
+		add(tl.RateAt(notify), to-from)
+		return canonicalSlice(out)
+	}
+	at := from
+	for _, band := range tl.bands {
+		if band.From > at && band.From < notify {
+			add(tl.RateAt(at), band.From-at)
+			at = band.From
+		}
+	}
+	add(tl.RateAt(at), notify-at)
+	add(tl.RateAt(notify), to-notify)
+	return canonicalSlice(out)
+}
+
+// canonicalSlice drops bands of 0 duration and merges adjacent bands at the
+// same rate
+func canonicalSlice(slice RateSlice) RateSlice {
+	var out RateSlice
+	for _, row := range slice {
+		switch {
+		case row.Duration == 0:
+		case len(out) > 0 && out[len(out)-1].Rate == row.Rate:
+			out[len(out)-1].Duration += row.Duration
+		default:
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+func TestTimelineMatchesReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(4675))
+	for i := 0; i < 20000; i++ {
+		var tl Timeline
+		from := math.Duration(0)
+		for n := rng.Intn(6); n > 0; n-- {
+			from += math.Duration(1 + rng.Intn(30))
+			require.NoError(t, tl.AddBand(from, Rate(rng.Intn(4))))
+		}
+		if rng.Intn(2) == 0 {
+			tl.Freeze(math.Duration(rng.Intn(120) - 10))
+		}
+		lo := math.Duration(rng.Intn(120) - 10)
+		hi := math.Duration(rng.Intn(120) - 10)
+
+		got := tl.Slice(lo, hi)
+		name := fmt.Sprintf("%v frozen %v: %d to %d", tl.bands, tl.frozenAt, lo, hi)
+		var total math.Duration
+		for _, row := range got {
+			require.True(t, row.Duration >= 0, name)
+			total += row.Duration
+		}
+		if hi > lo {
+			require.Equal(t, hi-lo, total, name)
+		}
+		require.Equal(t, referenceSlice(tl, lo, hi), canonicalSlice(got), name)
+	}
+}
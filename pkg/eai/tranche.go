@@ -0,0 +1,67 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// A Tranche is a portion of an account's balance with its own lock and
+// weighted average age.
+//
+// An account whose balance is partially locked holds several tranches: for
+// example, one locked for a year, and one unlocked.
+type Tranche struct {
+	Balance            math.Ndau
+	WeightedAverageAge math.Duration
+	// Lock is nil if the tranche is unlocked
+	Lock Lock
+}
+
+// CalculateTranches calculates the EAI due for each tranche of an account
+//
+// EAI is calculated for all tranches of an account at once, so they share
+// the account's block time and last EAI calculation. Each tranche earns
+// exactly what CalculateWith, under opts, would credit to an account holding
+// only that tranche; the returned EAI is listed in the same order as
+// tranches.
+//
+// Because each tranche's EAI is rounded separately, an account holding
+// several identical tranches may earn a napu or so more or less than one
+// holding their combined balance as a single tranche.
+func CalculateTranches(
+	tranches []Tranche,
+	blockTime, lastEAICalc math.Timestamp,
+	ageTable RateTable,
+	opts CalcOptions,
+) ([]math.Ndau, error) {
+	var scratch CalcScratch
+	eais := make([]math.Ndau, len(tranches))
+	for i, tranche := range tranches {
+		if tranche.Balance < 0 {
+			return nil, errors.Errorf("tranche %d: balance is negative", i)
+		}
+		eai, err := CalculateWith(
+			&scratch,
+			tranche.Balance,
+			blockTime, lastEAICalc,
+			tranche.WeightedAverageAge, tranche.Lock,
+			ageTable,
+			opts,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "tranche %d", i)
+		}
+		eais[i] = eai
+	}
+	return eais, nil
+}
@@ -0,0 +1,86 @@
+package eai
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateTranches(t *testing.T) {
+	blockTime := math.Timestamp(250 * math.Day)
+	lastEAICalc := math.Timestamp(40 * math.Day)
+	unlocksOn := math.Timestamp(200 * math.Day)
+	expired := NewBasicLock(90*math.Day, DefaultLockBonusEAI)
+	expired.UnlocksOn = &unlocksOn
+
+	tranches := []Tranche{
+		{Balance: 1000 * constants.QuantaPerUnit, WeightedAverageAge: 250 * math.Day},
+		{
+			Balance:            500 * constants.QuantaPerUnit,
+			WeightedAverageAge: 100 * math.Day,
+			Lock:               NewBasicLock(math.Year, DefaultLockBonusEAI),
+		},
+		{
+			Balance:            250 * constants.QuantaPerUnit,
+			WeightedAverageAge: 250 * math.Day,
+			Lock:               expired,
+		},
+		{Balance: 0, WeightedAverageAge: 10 * math.Day},
+	}
+
+	for _, fixUnlockBug := range []bool{false, true} {
+		got, err := CalculateTranches(tranches, blockTime, lastEAICalc, DefaultUnlockedEAI, CalcOptions{FixUnlockBug: fixUnlockBug})
+		require.NoError(t, err)
+		require.Len(t, got, len(tranches))
+		for i, tranche := range tranches {
+			expect, err := Calculate(
+				tranche.Balance,
+				blockTime, lastEAICalc,
+				tranche.WeightedAverageAge, tranche.Lock,
+				DefaultUnlockedEAI,
+				fixUnlockBug,
+			)
+			require.NoError(t, err)
+			require.Equal(t, expect, got[i], "tranche %d", i)
+		}
+		require.Zero(t, got[3])
+		// the locked tranche earns the lock bonus
+		require.True(t, got[1]*2 > got[0], "locked tranche earned %s; unlocked %s", got[1], got[0])
+	}
+}
+
+func TestCalculateTranchesEmpty(t *testing.T) {
+	got, err := CalculateTranches(nil, math.Year, 0, DefaultUnlockedEAI, spec)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func TestCalculateTranchesErrors(t *testing.T) {
+	_, err := CalculateTranches(
+		[]Tranche{{Balance: 1, WeightedAverageAge: math.Day}, {Balance: -1, WeightedAverageAge: math.Day}},
+		math.Year, 0, DefaultUnlockedEAI, spec,
+	)
+	require.Error(t, err)
+
+	strict := spec
+	strict.Strictness = Strict
+	_, err = CalculateTranches(
+		[]Tranche{{Balance: 1, WeightedAverageAge: math.Day}, {Balance: 1, WeightedAverageAge: -math.Day}},
+		math.Year, 0, DefaultUnlockedEAI, strict,
+	)
+	require.Error(t, err)
+	require.Equal(t, ErrNegativeWAA, errors.Cause(err))
+}
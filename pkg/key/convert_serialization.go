@@ -14,39 +14,135 @@ import (
 	"bytes"
 	"encoding/binary"
 	"math/big"
+	"strings"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/ndau/ndaumath/pkg/signature"
 )
 
-// FromOldSerialization attempts to produce an ExtendedKey from the old
-// (pre-october-2018) format.
+// Before October 2018, extended keys were serialized as the b32 encoding of
+// these fields, in order:
 //
-// If successful, it produces an ExtendedKey object
-// whose MarshalText method will produce the new serialization.
-func FromOldSerialization(key string) (*ExtendedKey, error) {
-	const serializedKeyLen = 3 + 1 + 3 + 4 + 32 + 33
+//   field | bytes
+//   ------|-------
+//   version | 3: NdauPrivateKeyID, NdauPublicKeyID, TestPrivateKeyID, or TestPublicKeyID
+//   depth | 1
+//   parent fingerprint | 3
+//   child num | 4, big-endian
+//   chain code | 32
+//   key data | 33 or 65
+//   checksum | 4: the head of the double sha256 of the preceding fields
+//
+// The first byte of the key data is a flag giving its form:
+//
+//   flag | key data | bytes | format
+//   -----|----------|-------|-------
+//   0x00 | private key, after the flag | 33 | FormatOld
+//   0x02, 0x03 | compressed public key | 33 | FormatOld
+//   0x04 | uncompressed public key | 65 | FormatOldUncompressed
+//   0x06, 0x07 | hybrid public key | 65 | FormatOldUncompressed
+//
+// A key in FormatOld fills 128 characters exactly. One in FormatOldUncompressed
+// needs 4 characters of b32 padding, which some tools truncated; it is accepted
+// with or without them.
+const (
+	oldHeaderLen       = 3 + 1 + 3 + 4 + 32
+	oldChecksumLen     = 4
+	oldCompressedLen   = oldHeaderLen + btcec.PubKeyBytesLenCompressed + oldChecksumLen
+	oldUncompressedLen = oldHeaderLen + btcec.PubKeyBytesLenUncompressed + oldChecksumLen
+)
 
+// A KeyFormat identifies one of the serializations an extended key has had
+type KeyFormat int
+
+// These are the serializations an extended key has had
+const (
+	// FormatUnknown is not a serialization of an extended key
+	FormatUnknown KeyFormat = iota
+	// FormatCurrent is the serialization produced by ExtendedKey.MarshalText
+	FormatCurrent
+	// FormatOld is the pre-october-2018 serialization, with 33 bytes of key
+	// data: a private or compressed public key
+	FormatOld
+	// FormatOldUncompressed is the pre-october-2018 serialization of a public
+	// key, with 65 bytes of key data: an uncompressed or hybrid public key
+	FormatOldUncompressed
+)
+
+func (f KeyFormat) String() string {
+	switch f {
+	case FormatCurrent:
+		return "current"
+	case FormatOld:
+		return "old"
+	case FormatOldUncompressed:
+		return "old uncompressed"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectKeyFormat classifies the serialization of an extended key.
+//
+// Old formats are recognized by their length and checksum; the key itself is
+// not validated until it is converted with FromOldSerialization.
+func DetectKeyFormat(s string) KeyFormat {
+	if _, err := signature.ParseKey(s); err == nil {
+		return FormatCurrent
+	}
+	_, format, err := decodeOld(s)
+	if err != nil {
+		return FormatUnknown
+	}
+	return format
+}
+
+// decodeOld decodes an old serialization and checks its checksum, returning
+// the payload without the checksum
+func decodeOld(key string) ([]byte, KeyFormat, error) {
+	if rem := len(key) % 8; rem != 0 {
+		// restore truncated padding
+		key += strings.Repeat("=", 8-rem)
+	}
 	// The base32-decoded extended key must consist of a serialized payload
 	// plus an additional 4 bytes for the checksum.
 	decoded, err := b32.Decode(key)
 	if err != nil {
-		return nil, ErrInvalidKeyEncoding
+		return nil, FormatUnknown, ErrInvalidKeyEncoding
 	}
-	if len(decoded) != serializedKeyLen+4 {
-		return nil, ErrInvalidKeyLen
+	var format KeyFormat
+	switch len(decoded) {
+	case oldCompressedLen:
+		format = FormatOld
+	case oldUncompressedLen:
+		format = FormatOldUncompressed
+	default:
+		return nil, FormatUnknown, ErrInvalidKeyLen
 	}
 
-	// The serialized format is:
-	//   version (3) || depth (1) || parent fingerprint (3)) ||
-	//   child num (4) || chain code (32) || key data (33) || checksum (4)
-
 	// Split the payload and checksum up and ensure the checksum matches.
-	payload := decoded[:len(decoded)-4]
-	checkSum := decoded[len(decoded)-4:]
-	expectedCheckSum := doubleHashB(payload)[:4]
+	payload := decoded[:len(decoded)-oldChecksumLen]
+	checkSum := decoded[len(decoded)-oldChecksumLen:]
+	expectedCheckSum := doubleHashB(payload)[:oldChecksumLen]
 	if !bytes.Equal(checkSum, expectedCheckSum) {
-		return nil, ErrBadChecksum
+		return nil, FormatUnknown, ErrBadChecksum
+	}
+	return payload, format, nil
+}
+
+// FromOldSerialization attempts to produce an ExtendedKey from the old
+// (pre-october-2018) format.
+//
+// If successful, it produces an ExtendedKey object
+// whose MarshalText method will produce the new serialization.
+//
+// It accepts both FormatOld and FormatOldUncompressed. Public keys are
+// always converted to their compressed form.
+func FromOldSerialization(key string) (*ExtendedKey, error) {
+	payload, _, err := decodeOld(key)
+	if err != nil {
+		return nil, err
 	}
 
 	// Deserialize each of the payload fields.
@@ -54,12 +150,16 @@ func FromOldSerialization(key string) (*ExtendedKey, error) {
 	parentFP := payload[4:7]
 	childNum := binary.BigEndian.Uint32(payload[7:11])
 	chainCode := payload[11:43]
-	keyData := payload[43:76]
+	keyData := payload[oldHeaderLen:]
 
 	// The key data is a private key if it starts with 0x00.  Serialized
-	// compressed pubkeys either start with 0x02 or 0x03.
+	// public keys start with 0x02 or 0x03 if compressed, 0x04 if
+	// uncompressed, and 0x06 or 0x07 if hybrid.
 	isPrivate := keyData[0] == 0x00
 	if isPrivate {
+		if len(keyData) != btcec.PubKeyBytesLenCompressed {
+			return nil, ErrInvalidKeyLen
+		}
 		// Ensure the private key is valid.  It must be within the range
 		// of the order of the secp256k1 curve and not be 0.
 		keyData = keyData[1:]
@@ -70,10 +170,11 @@ func FromOldSerialization(key string) (*ExtendedKey, error) {
 	} else {
 		// Ensure the public key parses correctly and is actually on the
 		// secp256k1 curve.
-		_, err := btcec.ParsePubKey(keyData, btcec.S256())
+		pk, err := btcec.ParsePubKey(keyData, btcec.S256())
 		if err != nil {
 			return nil, err
 		}
+		keyData = pk.SerializeCompressed()
 	}
 
 	return NewExtendedKey(keyData, chainCode, parentFP, depth, childNum, isPrivate), nil
@@ -0,0 +1,118 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// these fixtures are old serializations of the master key of the seed
+// "abcdefghijklmnopqrstuvwxyz123456" and of its child /44'/20036'/100/1
+const (
+	oldChildPrivate      = "npvt8bartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuuaehcsawbid2ew7bg9b5h7i6vdwmzjcq4etc69m545z7nx847yvqyc4ayapm"
+	oldChildPublic       = "npubabartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuua5bd8cxv4egudhajjv4wxtvpjbk5fvcjs62ctstqcfhk9xr2kh498atzea3"
+	oldChildUncompressed = "npubabartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuubdbd8cxv4egudhajjv4wxtvpjbk5fvcjs62ctstqcfhk9xr2kh4985mwz5vc6axcgvqctmjy8bxe23jf2w56pd6t8zyu6vydegerqyd84ccuy7a===="
+	oldChildHybrid       = "npubabartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuub5bd8cxv4egudhajjv4wxtvpjbk5fvcjs62ctstqcfhk9xr2kh4985mwz5vc6axcgvqctmjy8bxe23jf2w56pd6t8zyu6vydegerqyd927f92ca===="
+	oldTestMasterPrivate = "tpvt8aaaaaaaaaaaabtf5rspjdkv49y3vkiiyhjsmgm8u85h7cvzsqiwvasyk3rdycmh6ab68jgpd6bv6rg2acbwhuerpcrwuipnd85gsag4ju9b2eg9yddnqxdfgrvq"
+
+	newChildPrivate  = "npvta8jaftcjecdtiakawb6ckqsvrs7v8wqjt4f5wthpcitqrx77p58yk9pq5jzmabartgzaaaaaafij37mtm8vigeqqwr8zpg39tfc7zvvha4wwyaeddz5hyqcetgxuurm9dajmmwr4"
+	newChildPublic   = "npuba4jaftckeebych6fmhwipegqauvhxjmdg4ucxymgevb3sfdbc6ekqx9k9swrx92eb8e46aaaaaaxbhhxqfr4pa2t34t947w5h8ewmy8qn6dkuu2asnq9n832iue4ykn7nf584mz3"
+	newMasterPrivate = "npvta8jaftcjea8revgt8a38hvnaba4d3chyth4jegyb9pviadpe3rs6cdr5btyhkaaaaaaaaaaaabtf5rspjdkv49y3vkiiyhjsmgm8u85h7cvzsqiwvasyk3rdycmh6hm7nd8ezxvt"
+)
+
+func TestFromOldSerialization(t *testing.T) {
+	tests := []struct {
+		name   string
+		old    string
+		format KeyFormat
+		want   string
+	}{
+		{"private", oldChildPrivate, FormatOld, newChildPrivate},
+		{"public", oldChildPublic, FormatOld, newChildPublic},
+		{"uncompressed", oldChildUncompressed, FormatOldUncompressed, newChildPublic},
+		{"uncompressed truncated", strings.TrimRight(oldChildUncompressed, "="), FormatOldUncompressed, newChildPublic},
+		{"hybrid", oldChildHybrid, FormatOldUncompressed, newChildPublic},
+		{"hybrid truncated", strings.TrimRight(oldChildHybrid, "="), FormatOldUncompressed, newChildPublic},
+		{"test net master", oldTestMasterPrivate, FormatOld, newMasterPrivate},
+		{"upper case", strings.ToUpper(oldChildPrivate), FormatOld, newChildPrivate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.format, DetectKeyFormat(tt.old))
+			k, err := FromOldSerialization(tt.old)
+			require.NoError(t, err)
+			text, err := k.MarshalText()
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(text))
+		})
+	}
+}
+
+func TestFromOldSerializationMatchesDerivation(t *testing.T) {
+	master, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	child, err := master.DeriveFrom("/", "/44'/20036'/100/1")
+	require.NoError(t, err)
+	pub, err := child.Public()
+	require.NoError(t, err)
+
+	k, err := FromOldSerialization(oldChildPrivate)
+	require.NoError(t, err)
+	require.Equal(t, child.key, k.key)
+	require.Equal(t, child.chainCode, k.chainCode)
+	require.Equal(t, child.Depth(), k.Depth())
+	require.Equal(t, child.ParentFingerprint(), k.ParentFingerprint())
+	require.Equal(t, child.childNum, k.childNum)
+
+	k, err = FromOldSerialization(oldChildHybrid)
+	require.NoError(t, err)
+	require.False(t, k.IsPrivate())
+	require.Equal(t, pub.key, k.key)
+}
+
+func TestFromOldSerializationErrors(t *testing.T) {
+	// change a character in the middle of the key data
+	corrupt := []byte(oldChildPublic)
+	corrupt[100] = 'a' + 'b' - corrupt[100]
+
+	tests := []struct {
+		name    string
+		old     string
+		wantErr error
+	}{
+		{"empty", "", ErrInvalidKeyLen},
+		{"not b32", strings.Replace(oldChildPrivate, "a", "0", 1), ErrInvalidKeyEncoding},
+		{"short", oldChildPrivate[:120], ErrInvalidKeyLen},
+		{"long", oldChildPrivate + "aaaaaaaa", ErrInvalidKeyLen},
+		{"checksum", string(corrupt), ErrBadChecksum},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := FromOldSerialization(tt.old)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, FormatUnknown, DetectKeyFormat(tt.old))
+		})
+	}
+}
+
+func TestDetectKeyFormat(t *testing.T) {
+	for _, s := range []string{newChildPrivate, newChildPublic, newMasterPrivate} {
+		require.Equal(t, FormatCurrent, DetectKeyFormat(s), s)
+		_, err := FromOldSerialization(s)
+		require.Equal(t, ErrInvalidKeyLen, err)
+	}
+	require.Equal(t, FormatUnknown, DetectKeyFormat("npvt"))
+	require.Equal(t, "old uncompressed", FormatOldUncompressed.String())
+	require.Equal(t, "unknown", KeyFormat(-1).String())
+}
@@ -0,0 +1,221 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/ndau/ndaumath/pkg/bip32ed"
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// ensure ExtendedKey implements the msgp interfaces
+var _ msgp.Marshaler = (*ExtendedKey)(nil)
+var _ msgp.Unmarshaler = (*ExtendedKey)(nil)
+var _ msgp.Encodable = (*ExtendedKey)(nil)
+var _ msgp.Decodable = (*ExtendedKey)(nil)
+var _ msgp.Sizer = (*ExtendedKey)(nil)
+
+// An ExtendedKey is serialized as a msgpack array of its fields, in this order:
+//
+//   field | msgpack type
+//   ------|-------
+//   key | bin
+//   chain code | bin
+//   depth | uint8
+//   parent fingerprint | bin
+//   child num | uint32
+//   is private | bool
+//   is ed25519 | bool
+//
+// The memoized public key of a private key is not serialized.
+const (
+	extendedKeyFields = 7
+	chainCodeLen      = 32
+	parentFPLen       = 3
+)
+
+// validate ensures that the fields of a decoded key are of a usable size
+func (k *ExtendedKey) validate() error {
+	keyLen := btcec.PubKeyBytesLenCompressed
+	if k.isPrivate {
+		keyLen = btcec.PrivKeyBytesLen
+	}
+	if k.isEd {
+		keyLen = bip32ed.KeyLen
+	}
+	if len(k.key) != keyLen {
+		return ErrInvalidKeyLen
+	}
+	if len(k.chainCode) != chainCodeLen {
+		return fmt.Errorf("chain code must have %d bytes; got %d", chainCodeLen, len(k.chainCode))
+	}
+	if len(k.parentFP) != parentFPLen {
+		return fmt.Errorf("parent fingerprint must have %d bytes; got %d", parentFPLen, len(k.parentFP))
+	}
+	return nil
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (k ExtendedKey) MarshalMsg(in []byte) ([]byte, error) {
+	out := msgp.AppendArrayHeader(in, extendedKeyFields)
+	out = msgp.AppendBytes(out, k.key)
+	out = msgp.AppendBytes(out, k.chainCode)
+	out = msgp.AppendUint8(out, k.depth)
+	out = msgp.AppendBytes(out, k.parentFP)
+	out = msgp.AppendUint32(out, k.childNum)
+	out = msgp.AppendBool(out, k.isPrivate)
+	out = msgp.AppendBool(out, k.isEd)
+	return out, nil
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+//
+// On error, the key is left unchanged.
+func (k *ExtendedKey) UnmarshalMsg(in []byte) ([]byte, error) {
+	var d ExtendedKey
+	fields, bts, err := msgp.ReadArrayHeaderBytes(in)
+	if err != nil {
+		return in, errors.Wrap(err, "reading extended key header")
+	}
+	if fields != extendedKeyFields {
+		return in, msgp.ArrayError{Wanted: extendedKeyFields, Got: fields}
+	}
+	d.key, bts, err = msgp.ReadBytesBytes(bts, nil)
+	if err != nil {
+		return in, errors.Wrap(err, "reading key")
+	}
+	d.chainCode, bts, err = msgp.ReadBytesBytes(bts, nil)
+	if err != nil {
+		return in, errors.Wrap(err, "reading chain code")
+	}
+	d.depth, bts, err = msgp.ReadUint8Bytes(bts)
+	if err != nil {
+		return in, errors.Wrap(err, "reading depth")
+	}
+	d.parentFP, bts, err = msgp.ReadBytesBytes(bts, nil)
+	if err != nil {
+		return in, errors.Wrap(err, "reading parent fingerprint")
+	}
+	d.childNum, bts, err = msgp.ReadUint32Bytes(bts)
+	if err != nil {
+		return in, errors.Wrap(err, "reading child num")
+	}
+	d.isPrivate, bts, err = msgp.ReadBoolBytes(bts)
+	if err != nil {
+		return in, errors.Wrap(err, "reading is private")
+	}
+	d.isEd, bts, err = msgp.ReadBoolBytes(bts)
+	if err != nil {
+		return in, errors.Wrap(err, "reading is ed25519")
+	}
+	err = d.validate()
+	if err != nil {
+		return in, err
+	}
+	*k = d
+	return bts, nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (k ExtendedKey) EncodeMsg(en *msgp.Writer) error {
+	err := en.WriteArrayHeader(extendedKeyFields)
+	if err != nil {
+		return err
+	}
+	err = en.WriteBytes(k.key)
+	if err != nil {
+		return err
+	}
+	err = en.WriteBytes(k.chainCode)
+	if err != nil {
+		return err
+	}
+	err = en.WriteUint8(k.depth)
+	if err != nil {
+		return err
+	}
+	err = en.WriteBytes(k.parentFP)
+	if err != nil {
+		return err
+	}
+	err = en.WriteUint32(k.childNum)
+	if err != nil {
+		return err
+	}
+	err = en.WriteBool(k.isPrivate)
+	if err != nil {
+		return err
+	}
+	return en.WriteBool(k.isEd)
+}
+
+// DecodeMsg implements msgp.Decodable
+//
+// On error, the key is left unchanged.
+func (k *ExtendedKey) DecodeMsg(dc *msgp.Reader) error {
+	var d ExtendedKey
+	fields, err := dc.ReadArrayHeader()
+	if err != nil {
+		return errors.Wrap(err, "reading extended key header")
+	}
+	if fields != extendedKeyFields {
+		return msgp.ArrayError{Wanted: extendedKeyFields, Got: fields}
+	}
+	d.key, err = dc.ReadBytes(nil)
+	if err != nil {
+		return errors.Wrap(err, "reading key")
+	}
+	d.chainCode, err = dc.ReadBytes(nil)
+	if err != nil {
+		return errors.Wrap(err, "reading chain code")
+	}
+	d.depth, err = dc.ReadUint8()
+	if err != nil {
+		return errors.Wrap(err, "reading depth")
+	}
+	d.parentFP, err = dc.ReadBytes(nil)
+	if err != nil {
+		return errors.Wrap(err, "reading parent fingerprint")
+	}
+	d.childNum, err = dc.ReadUint32()
+	if err != nil {
+		return errors.Wrap(err, "reading child num")
+	}
+	d.isPrivate, err = dc.ReadBool()
+	if err != nil {
+		return errors.Wrap(err, "reading is private")
+	}
+	d.isEd, err = dc.ReadBool()
+	if err != nil {
+		return errors.Wrap(err, "reading is ed25519")
+	}
+	err = d.validate()
+	if err != nil {
+		return err
+	}
+	*k = d
+	return nil
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+// Msgsize implements msgp.Sizer
+func (k ExtendedKey) Msgsize() int {
+	return msgp.ArrayHeaderSize +
+		msgp.BytesPrefixSize + len(k.key) +
+		msgp.BytesPrefixSize + len(k.chainCode) +
+		msgp.Uint8Size +
+		msgp.BytesPrefixSize + len(k.parentFP) +
+		msgp.Uint32Size +
+		2*msgp.BoolSize
+}
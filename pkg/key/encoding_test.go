@@ -0,0 +1,130 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func msgpTestKeys(t *testing.T) map[string]*ExtendedKey {
+	seed := []byte("abcdefghijklmnopqrstuvwxyz123456")
+	master, err := NewMaster(seed)
+	require.NoError(t, err)
+	child, err := master.Child(HardenedKeyStart + 44)
+	require.NoError(t, err)
+	pub, err := child.Public()
+	require.NoError(t, err)
+	edMaster, err := NewMasterEd(seed)
+	require.NoError(t, err)
+	edChild, err := edMaster.Child(HardenedKeyStart + 44)
+	require.NoError(t, err)
+	edPub, err := edChild.Public()
+	require.NoError(t, err)
+
+	return map[string]*ExtendedKey{
+		"master":        master,
+		"private child": child,
+		"public child":  pub,
+		"ed master":     edMaster,
+		"ed private":    edChild,
+		"ed public":     edPub,
+	}
+}
+
+func requireSameKey(t *testing.T, want, got *ExtendedKey) {
+	require.Equal(t, want.Bytes(), got.Bytes())
+	require.Equal(t, want.PubKeyBytes(), got.PubKeyBytes())
+	require.Equal(t, want.chainCode, got.chainCode)
+	require.Equal(t, want.Depth(), got.Depth())
+	require.Equal(t, want.ParentFingerprint(), got.ParentFingerprint())
+	require.Equal(t, want.ChildNum(), got.ChildNum())
+	require.Equal(t, want.IsPrivate(), got.IsPrivate())
+	require.Equal(t, want.IsEd(), got.IsEd())
+}
+
+func TestExtendedKeyMsgpRoundtrip(t *testing.T) {
+	for name, k := range msgpTestKeys(t) {
+		t.Run(name, func(t *testing.T) {
+			bts, err := k.MarshalMsg(nil)
+			require.NoError(t, err)
+			require.True(t, len(bts) <= k.Msgsize())
+
+			got := new(ExtendedKey)
+			leftover, err := got.UnmarshalMsg(append(bts, 0xc0))
+			require.NoError(t, err)
+			require.Equal(t, []byte{0xc0}, leftover)
+			requireSameKey(t, k, got)
+
+			// the streaming interfaces produce the same encoding
+			var buf bytes.Buffer
+			require.NoError(t, msgp.Encode(&buf, k))
+			require.Equal(t, bts, buf.Bytes())
+			got = new(ExtendedKey)
+			require.NoError(t, msgp.Decode(&buf, got))
+			requireSameKey(t, k, got)
+
+			// a decoded key can continue the derivation
+			if k.IsPrivate() {
+				want, err := k.Child(HardenedKeyStart + 1)
+				require.NoError(t, err)
+				gotChild, err := got.Child(HardenedKeyStart + 1)
+				require.NoError(t, err)
+				requireSameKey(t, want, gotChild)
+			}
+		})
+	}
+}
+
+func TestExtendedKeyMsgpErrors(t *testing.T) {
+	k := msgpTestKeys(t)["private child"]
+	good, err := k.MarshalMsg(nil)
+	require.NoError(t, err)
+
+	edit := func(f func(k *ExtendedKey)) []byte {
+		bad := *k
+		f(&bad)
+		bts, err := bad.MarshalMsg(nil)
+		require.NoError(t, err)
+		return bts
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", good[:len(good)-1]},
+		{"not an array", msgp.AppendBytes(nil, k.Bytes())},
+		{"too few fields", append(msgp.AppendArrayHeader(nil, extendedKeyFields-1), good[1:]...)},
+		{"short key", edit(func(k *ExtendedKey) { k.key = k.key[:31] })},
+		{"public key length for private", edit(func(k *ExtendedKey) { k.isPrivate = false })},
+		{"short chain code", edit(func(k *ExtendedKey) { k.chainCode = k.chainCode[:16] })},
+		{"long parent fingerprint", edit(func(k *ExtendedKey) { k.parentFP = append(k.parentFP, 0) })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewExtendedKey(nil, nil, nil, 7, 0, false)
+			leftover, err := got.UnmarshalMsg(tt.data)
+			require.Error(t, err)
+			require.Equal(t, tt.data, leftover)
+			// the target is unchanged
+			require.Equal(t, uint8(7), got.Depth())
+
+			err = got.DecodeMsg(msgp.NewReader(bytes.NewReader(tt.data)))
+			require.Error(t, err)
+			require.Equal(t, uint8(7), got.Depth())
+		})
+	}
+}
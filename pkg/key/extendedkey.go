@@ -27,18 +27,15 @@ package key
 //   https://github.com/btcsuite/btcd
 
 import (
-	"crypto/hmac"
 	"crypto/sha256"
-	"crypto/sha512"
 	"encoding"
 	"encoding/binary"
 	"fmt"
-	"math/big"
 	"unicode/utf8"
 
 	"github.com/btcsuite/btcd/btcec"
-	"github.com/ndau/ndaumath/pkg/b32"
 	"github.com/ndau/ndaumath/pkg/bip32"
+	"github.com/ndau/ndaumath/pkg/bip32ed"
 	"github.com/ndau/ndaumath/pkg/signature"
 	"github.com/pkg/errors"
 )
@@ -57,8 +54,7 @@ const (
 var (
 	// ErrDeriveHardFromPublic describes an error in which the caller
 	// attempted to derive a hardened extended key from a public key.
-	ErrDeriveHardFromPublic = errors.New(
-		"cannot derive a hardened key from a public key")
+	ErrDeriveHardFromPublic = bip32.ErrDeriveHardFromPublic
 
 	// ErrDeriveBeyondMaxDepth describes an error in which the caller
 	// has attempted to derive more than 255 keys from a root key.
@@ -75,7 +71,15 @@ var (
 	// range for secp256k1 private keys.  This error indicates the caller
 	// should simply ignore the invalid child extended key at this index and
 	// increment to the next index.
-	ErrInvalidChild = errors.New("the extended key at this index is invalid")
+	ErrInvalidChild = bip32.ErrInvalidChild
+
+	// ErrHardenedOnly describes an error in which the caller attempted to
+	// derive a non-hardened child of an Ed25519 extended key.
+	ErrHardenedOnly = bip32ed.ErrHardenedOnly
+
+	// ErrNotSecp256k1 describes an error in which the caller attempted a
+	// secp256k1-specific operation on an Ed25519 extended key.
+	ErrNotSecp256k1 = errors.New("extended key is not a secp256k1 key")
 
 	// ErrUnusableSeed describes an error in which the provided seed is not
 	// usable due to the derived key falling outside of the valid range for
@@ -115,13 +119,6 @@ var (
 	TestPublicKeyID = [3]byte{139, 100, 16} // tpub
 )
 
-// fingerprint calculates the checksum of sha256(b).
-func fingerprint(buf []byte) []byte {
-	hasher := sha256.New()
-	hasher.Write(buf)
-	return b32.Checksum24(hasher.Sum(nil))
-}
-
 // doubleHashB calculates hash(hash(b)) and returns the resulting bytes.
 func doubleHashB(b []byte) []byte {
 	first := sha256.Sum256(b)
@@ -140,6 +137,9 @@ type ExtendedKey struct {
 	parentFP  []byte
 	childNum  uint32
 	isPrivate bool
+	isEd      bool // true for Ed25519 keys, which follow SLIP-0010
+	policy    *Policy
+	trailer   []byte // extra data following ours, such as a usage tag
 }
 
 // ensure ExtendedKey implements Text(Un)Marshaller
@@ -183,7 +183,11 @@ func (k *ExtendedKey) PubKeyBytes() []byte {
 	// This is a private extended key, so calculate and memoize the public
 	// key if needed.
 	if len(k.pubKey) == 0 {
-		k.pubKey = bip32.PrivateToPublic(k.key)
+		if k.isEd {
+			k.pubKey = bip32ed.PrivateToPublic(k.key)
+		} else {
+			k.pubKey = bip32.PrivateToPublic(k.key)
+		}
 	}
 
 	return k.pubKey
@@ -198,6 +202,14 @@ func (k *ExtendedKey) IsPrivate() bool {
 	return k.isPrivate
 }
 
+// IsEd returns whether or not the extended key is an Ed25519 key.
+//
+// Ed25519 extended keys are derived per SLIP-0010, which only supports
+// hardened derivation. Otherwise, the key is a secp256k1 key.
+func (k *ExtendedKey) IsEd() bool {
+	return k.isEd
+}
+
 // Depth returns the current derivation level with respect to the root.
 //
 // The root key has depth zero, and the field has a maximum of 255 due to
@@ -206,6 +218,13 @@ func (k *ExtendedKey) Depth() uint8 {
 	return k.depth
 }
 
+// ChildNum returns the index at which this key was derived from its parent.
+//
+// Indices at or above HardenedKeyStart denote hardened children.
+func (k *ExtendedKey) ChildNum() uint32 {
+	return k.childNum
+}
+
 // ParentFingerprint returns a fingerprint of the parent extended key from which
 // this one was derived.
 // Since the fingerprint is 3 bytes, we set the high byte to 0 before returning it
@@ -255,120 +274,146 @@ func (k *ExtendedKey) Child(i uint32) (*ExtendedKey, error) {
 	if !k.isPrivate && isChildHardened {
 		return nil, ErrDeriveHardFromPublic
 	}
+	err := k.checkPolicy(i)
+	if err != nil {
+		return nil, err
+	}
 
-	// The data used to derive the child key depends on whether or not the
-	// child is hardened per [BIP32].
-	//
-	// For hardened children:
-	//   0x00 || ser256(parentKey) || ser32(i)
-	//
-	// For normal children:
-	//   serP(parentPubKey) || ser32(i)
-	keyLen := 33
-	data := make([]byte, keyLen+4)
-	if isChildHardened {
-		// Case #1.
-		// When the child is a hardened child, the key is known to be a
-		// private key due to the above early return.  Pad it with a
-		// leading zero as required by [BIP32] for deriving the child.
-		copy(data[1:], k.key)
+	var child *ExtendedKey
+	if k.isEd {
+		child, err = k.edChild(i)
 	} else {
-		// Case #2 or #3.
-		// This is either a public or private extended key, but in
-		// either case, the data which is used to derive the child key
-		// starts with the secp256k1 compressed public key bytes.
-		copy(data, k.PubKeyBytes())
-	}
-	binary.BigEndian.PutUint32(data[keyLen:], i)
-
-	// Take the HMAC-SHA512 of the current key's chain code and the derived
-	// data:
-	//   I = HMAC-SHA512(Key = chainCode, Data = data)
-	hmac512 := hmac.New(sha512.New, k.chainCode)
-	hmac512.Write(data)
-	ilr := hmac512.Sum(nil)
-
-	// Split "I" into two 32-byte sequences Il and Ir where:
-	//   Il = intermediate key used to derive the child
-	//   Ir = child chain code
-	il := ilr[:len(ilr)/2]
-	childChainCode := ilr[len(ilr)/2:]
-
-	// Both derived public or private keys rely on treating the left 32-byte
-	// sequence calculated above (Il) as a 256-bit integer that must be
-	// within the valid range for a secp256k1 private key.  There is a small
-	// chance (< 1 in 2^127) this condition will not hold, and in that case,
-	// a child extended key can't be created for this index and the caller
-	// should simply increment to the next index.
-	ilNum := new(big.Int).SetBytes(il)
-	if ilNum.Cmp(btcec.S256().N) >= 0 || ilNum.Sign() == 0 {
-		return nil, ErrInvalidChild
-	}
-
-	// The algorithm used to derive the child key depends on whether or not
-	// a private or public child is being derived.
-	//
-	// For private children:
-	//   childKey = parse256(Il) + parentKey
-	//
-	// For public children:
-	//   childKey = serP(point(parse256(Il)) + parentKey)
-	var isPrivate bool
-	var childKey []byte
-	if k.isPrivate {
-		// Case #1 or #2.
-		// Add the parent private key to the intermediate private key to
-		// derive the final child key.
-		//
-		// childKey = parse256(Il) + parenKey
-		keyNum := new(big.Int).SetBytes(k.key)
-		ilNum.Add(ilNum, keyNum)
-		ilNum.Mod(ilNum, btcec.S256().N)
-		// the bytes function here returns a minimum-length buffer to represent a big-endian
-		// value. It actually works to trim leading zeros, but we definitely don't want that
-		// as we have an assumption that all keys are the same length. So we might need to
-		// put the zeros back on the front.
-		childKey = ilNum.Bytes()
-		if len(childKey) < 32 {
-			buf := make([]byte, 32)
-			offset := 32 - len(childKey)
-			for i := range childKey {
-				buf[i+offset] = childKey[i]
-			}
-			childKey = buf
+		var d *childDeriver
+		d, err = newChildDeriver(k)
+		if err != nil {
+			return nil, err
 		}
-		isPrivate = true
+		child, err = d.child(i)
+	}
+	if err != nil {
+		return nil, err
+	}
+	child.policy = k.policy
+	return child, nil
+}
+
+// childDeriver derives secp256k1 children of a single parent
+type childDeriver struct {
+	parent   *ExtendedKey
+	deriver  *bip32.Deriver
+	parentFP []byte
+}
+
+func newChildDeriver(k *ExtendedKey) (*childDeriver, error) {
+	public := k.PubKeyBytes()
+	var private []byte
+	if k.isPrivate {
+		private = k.key
+	}
+	d, err := bip32.NewDeriver(private, public, k.chainCode)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not prepare derivation")
+	}
+	return &childDeriver{
+		parent:   k,
+		deriver:  d,
+		parentFP: bip32.Fingerprint(public),
+	}, nil
+}
+
+// child derives the child at index i, which the caller has already checked
+// is derivable from the parent
+func (d *childDeriver) child(i uint32) (*ExtendedKey, error) {
+	k := d.parent
+	childKey, childChainCode, err := d.deriver.Child(i)
+	if err != nil {
+		return nil, err
+	}
+	parentFP := append([]byte(nil), d.parentFP...)
+	return NewExtendedKey(childKey, childChainCode, parentFP,
+		k.depth+1, i, k.isPrivate), nil
+}
+
+// edChild derives an Ed25519 child per SLIP-0010
+func (k *ExtendedKey) edChild(i uint32) (*ExtendedKey, error) {
+	if !k.isPrivate {
+		// Ed25519 has no public derivation at all
+		return nil, ErrHardenedOnly
+	}
+	childKey, childChainCode, err := bip32ed.Child(k.key, k.chainCode, i)
+	if err != nil {
+		return nil, err
+	}
+
+	parentFP := bip32.Fingerprint(k.PubKeyBytes())
+	child := NewExtendedKey(childKey[:], childChainCode[:], parentFP,
+		k.depth+1, i, true)
+	child.isEd = true
+	return child, nil
+}
+
+// Children derives count consecutive children, starting at index start.
+//
+// This is equivalent to calling Child for each index in turn, but state which
+// depends only on the parent is computed once and reused, which makes it
+// considerably faster for large batches.
+//
+// Per [BIP32], indices which do not derive a usable child are skipped, so the
+// returned keys may span more than count indices; use ChildNum to recover the
+// index of each. It is an error if the range would run past the end of the
+// non-hardened or the hardened indices.
+func (k *ExtendedKey) Children(start, count uint32) ([]*ExtendedKey, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if k.depth == maxUint8 {
+		return nil, ErrDeriveBeyondMaxDepth
+	}
+	isHardened := start >= HardenedKeyStart
+	if !k.isPrivate && isHardened {
+		return nil, ErrDeriveHardFromPublic
+	}
+	// every index in the range is hardened alike, so one check covers them all
+	err := k.checkPolicy(start)
+	if err != nil {
+		return nil, err
+	}
+	// end is the first index beyond the range in which start falls
+	end := uint64(HardenedKeyStart)
+	if isHardened {
+		end = 1 << 32
+	}
+
+	var derive func(uint32) (*ExtendedKey, error)
+	if k.isEd {
+		derive = k.edChild
 	} else {
-		// Case #3.
-		// Calculate the corresponding intermediate public key for
-		// intermediate private key.
-		ilx, ily := btcec.S256().ScalarBaseMult(il)
-		if ilx.Sign() == 0 || ily.Sign() == 0 {
-			return nil, ErrInvalidChild
+		d, err := newChildDeriver(k)
+		if err != nil {
+			return nil, err
 		}
+		derive = d.child
+	}
 
-		// Convert the serialized compressed parent public key into X
-		// and Y coordinates so it can be added to the intermediate
-		// public key.
-		pubKey, err := btcec.ParsePubKey(k.key, btcec.S256())
+	children := make([]*ExtendedKey, 0, count)
+	for i := uint64(start); len(children) < int(count); i++ {
+		if i >= end {
+			return nil, fmt.Errorf(
+				"deriving %d children from %d: ran out of indices after %d",
+				count, start, len(children),
+			)
+		}
+		child, err := derive(uint32(i))
+		if err == ErrInvalidChild {
+			continue
+		}
 		if err != nil {
-			return nil, errors.Wrap(err, "could not parse public key")
+			return nil, errors.Wrapf(err, "deriving child %d", i)
 		}
-
-		// Add the intermediate public key to the parent public key to
-		// derive the final child key.
-		//
-		// childKey = serP(point(parse256(Il)) + parentKey)
-		childX, childY := btcec.S256().Add(ilx, ily, pubKey.X, pubKey.Y)
-		pk := btcec.PublicKey{Curve: btcec.S256(), X: childX, Y: childY}
-		childKey = pk.SerializeCompressed()
+		child.policy = k.policy
+		children = append(children, child)
 	}
-
-	// The fingerprint of the parent for the derived child is the checksum24
-	// of the SHA256(parentPubKey).
-	parentFP := fingerprint(k.PubKeyBytes())
-	return NewExtendedKey(childKey, childChainCode, parentFP,
-		k.depth+1, i, isPrivate), nil
+	return children, nil
 }
 
 // Public returns a new extended public key from this extended private key.  The
@@ -389,8 +434,12 @@ func (k *ExtendedKey) Public() (*ExtendedKey, error) {
 	// key will simply be the pubkey of the current extended private key.
 	//
 	// This is the function N((k,c)) -> (K, c) from [BIP32].
-	return NewExtendedKey(k.PubKeyBytes(), k.chainCode, k.parentFP,
-		k.depth, k.childNum, false), nil
+	pub := NewExtendedKey(k.PubKeyBytes(), k.chainCode, k.parentFP,
+		k.depth, k.childNum, false)
+	pub.isEd = k.isEd
+	pub.policy = k.policy
+	pub.trailer = append([]byte(nil), k.trailer...)
+	return pub, nil
 }
 
 // HardenedChild returns the n'th hardened child of the given extended key.
@@ -406,7 +455,12 @@ func (k *ExtendedKey) HardenedChild(n uint32) (*ExtendedKey, error) {
 }
 
 // ECPubKey converts the extended key to a btcec public key and returns it.
+//
+// It is an error to call this on an Ed25519 key.
 func (k *ExtendedKey) ECPubKey() (*btcec.PublicKey, error) {
+	if k.isEd {
+		return nil, ErrNotSecp256k1
+	}
 	return btcec.ParsePubKey(k.PubKeyBytes(), btcec.S256())
 }
 
@@ -416,6 +470,9 @@ func (k *ExtendedKey) ECPubKey() (*btcec.PublicKey, error) {
 // extended key (as determined by the IsPrivate function).  The ErrNotPrivExtKey
 // error will be returned if this function is called on a public extended key.
 func (k *ExtendedKey) ECPrivKey() (*btcec.PrivateKey, error) {
+	if k.isEd {
+		return nil, ErrNotSecp256k1
+	}
 	if !k.isPrivate {
 		return nil, ErrNotPrivExtKey
 	}
@@ -468,11 +525,13 @@ func (k *ExtendedKey) extra() []byte {
 	//   parent fingerprint | 3
 	//   child num | 4 | serialized as big-endian uint32
 	//   chain code | 32
-	serializedBytes := make([]byte, 0, extraLen)
+	//   trailer | any | preserved from the key this was parsed from
+	serializedBytes := make([]byte, 0, extraLen+len(k.trailer))
 	serializedBytes = append(serializedBytes, k.depth)
 	serializedBytes = append(serializedBytes, k.parentFP...)
 	serializedBytes = append(serializedBytes, childNumBytes[:]...)
 	serializedBytes = append(serializedBytes, k.chainCode...)
+	serializedBytes = append(serializedBytes, k.trailer...)
 
 	return serializedBytes
 }
@@ -486,6 +545,7 @@ func (k *ExtendedKey) parseExtra(data []byte) error {
 	//   parent fingerprint | 3
 	//   child num | 4 | serialized as big-endian uint32
 	//   chain code | 32
+	//   trailer | any | not interpreted here, but kept for extra()
 	if len(data) < extraLen {
 		return errors.New("cannot parseExtra: too few bytes in data")
 	}
@@ -493,6 +553,10 @@ func (k *ExtendedKey) parseExtra(data []byte) error {
 	k.parentFP = data[1:4]
 	k.childNum = binary.BigEndian.Uint32(data[4:8])
 	k.chainCode = data[8:40]
+	k.trailer = nil
+	if len(data) > extraLen {
+		k.trailer = append([]byte(nil), data[extraLen:]...)
+	}
 
 	return nil
 }
@@ -515,10 +579,14 @@ func (k *ExtendedKey) Zero() {
 	zero(k.pubKey)
 	zero(k.chainCode)
 	zero(k.parentFP)
+	zero(k.trailer)
 	k.key = nil
+	k.trailer = nil
 	k.depth = 0
 	k.childNum = 0
 	k.isPrivate = false
+	k.isEd = false
+	k.policy = nil
 }
 
 // NewMaster creates a new master node for use in creating a hierarchical
@@ -539,6 +607,23 @@ func NewMaster(seed []byte) (*ExtendedKey, error) {
 	return NewExtendedKey(secretKey[:], chainCode[:], parentFP, 0, 0, true), nil
 }
 
+// NewMasterEd creates a new Ed25519 master node per SLIP-0010, for use in
+// creating a hierarchical deterministic key chain. Its requirements on the
+// seed are those of NewMaster, so the same seed may be used for both.
+//
+// Only hardened children of the resulting key can be derived.
+func NewMasterEd(seed []byte) (*ExtendedKey, error) {
+	secretKey, chainCode, err := bip32ed.NewMaster(seed)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get new ed25519 master from seed")
+	}
+
+	parentFP := []byte{0x00, 0x00, 0x00}
+	k := NewExtendedKey(secretKey[:], chainCode[:], parentFP, 0, 0, true)
+	k.isEd = true
+	return k, nil
+}
+
 // GenerateSeed returns a cryptographically secure random seed that can be used
 // as the input for the NewMaster function to generate a new master node.
 //
@@ -559,10 +644,15 @@ func (k *ExtendedKey) Bytes() []byte {
 func (k *ExtendedKey) FromSignatureKey(key signature.Key) (err error) {
 	k.Zero()
 
-	if signature.NameOf(key.Algorithm()) != signature.NameOf(signature.Secp256k1) {
+	switch signature.NameOf(key.Algorithm()) {
+	case signature.NameOf(signature.Secp256k1):
+	case signature.NameOf(signature.Ed25519):
+		k.isEd = true
+	default:
 		err = fmt.Errorf(
-			"ExtendedKey must use %s algorithm; provided key uses %s",
+			"ExtendedKey must use %s or %s algorithm; provided key uses %s",
 			signature.NameOf(signature.Secp256k1),
+			signature.NameOf(signature.Ed25519),
 			signature.NameOf(key.Algorithm()),
 		)
 		return
@@ -570,6 +660,14 @@ func (k *ExtendedKey) FromSignatureKey(key signature.Key) (err error) {
 
 	k.isPrivate = signature.IsPrivate(key)
 	k.key = key.KeyBytes()
+	if k.isEd && k.isPrivate {
+		// the signature package stores the expanded form of ed25519 private
+		// keys, but derivation operates on the seed
+		if len(k.key) < bip32ed.KeyLen {
+			return errors.New("ed25519 private key too short")
+		}
+		k.key = k.key[:bip32ed.KeyLen]
+	}
 	err = k.parseExtra(key.ExtraBytes())
 	if err != nil {
 		return errors.Wrap(err, "could not parse extra")
@@ -589,6 +687,16 @@ func FromSignatureKey(key signature.Key) (ek *ExtendedKey, err error) {
 
 // AsSignatureKey converts this ExtendedKey into a signature.Key instance
 func (k ExtendedKey) AsSignatureKey() (signature.Key, error) {
+	if k.isEd {
+		if k.isPrivate {
+			priv, err := signature.RawPrivateKey(signature.Ed25519, bip32ed.Expand(k.key), k.extra())
+			err = errors.Wrap(err, "could not convert ed25519 private key")
+			return priv, err
+		}
+		pub, err := signature.RawPublicKey(signature.Ed25519, k.key, k.extra())
+		err = errors.Wrap(err, "could not convert ed25519 public key")
+		return pub, err
+	}
 	if k.isPrivate {
 		priv, err := signature.RawPrivateKey(signature.Secp256k1, k.key, k.extra())
 		err = errors.Wrap(err, "could not convert private key")
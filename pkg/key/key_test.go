@@ -11,10 +11,17 @@ package key
 
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/ndau/ndaumath/pkg/signature"
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMaster1(t *testing.T) {
@@ -100,3 +107,357 @@ func TestPubPrv(t *testing.T) {
 	assert.Nil(t, err)
 	checkKeys(t, pvt, pvt)
 }
+
+func TestEdChildren(t *testing.T) {
+	// SLIP-0010 test vector 1 for ed25519, chain m/0H/1H
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	assert.Nil(t, err)
+	master, err := NewMasterEd(seed)
+	assert.Nil(t, err)
+	assert.True(t, master.IsEd())
+	ch0, err := master.Child(HardenedKeyStart + 0)
+	assert.Nil(t, err)
+	ch1, err := ch0.Child(HardenedKeyStart + 1)
+	assert.Nil(t, err)
+	assert.True(t, ch1.IsEd())
+	assert.Equal(t, uint8(2), ch1.Depth())
+	assert.Equal(t, "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2", hex.EncodeToString(ch1.key))
+	assert.Equal(t, "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14", hex.EncodeToString(ch1.chainCode))
+	assert.Equal(t, "1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187", hex.EncodeToString(ch1.PubKeyBytes()))
+
+	// only hardened derivation is possible
+	_, err = ch1.Child(1)
+	assert.Equal(t, ErrHardenedOnly, err)
+	pub, err := ch1.Public()
+	assert.Nil(t, err)
+	assert.True(t, pub.IsEd())
+	_, err = pub.Child(1)
+	assert.Error(t, err)
+	_, err = ch1.ECPrivKey()
+	assert.Equal(t, ErrNotSecp256k1, err)
+}
+
+func TestEdTextRoundtrip(t *testing.T) {
+	master, err := NewMasterEd([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+	pvt, err := master.Child(HardenedKeyStart + 44)
+	assert.Nil(t, err)
+	pub, err := pvt.Public()
+	assert.Nil(t, err)
+	for _, k := range []*ExtendedKey{pvt, pub} {
+		text, err := k.MarshalText()
+		assert.Nil(t, err)
+		k2 := new(ExtendedKey)
+		err = k2.UnmarshalText(text)
+		assert.Nil(t, err)
+		assert.True(t, k2.IsEd())
+		assert.Equal(t, k.IsPrivate(), k2.IsPrivate())
+		assert.Equal(t, k.PubKeyBytes(), k2.PubKeyBytes())
+		assert.Equal(t, k.Depth(), k2.Depth())
+	}
+
+	// a restored private key can continue the derivation
+	text, err := pvt.MarshalText()
+	assert.Nil(t, err)
+	restored := new(ExtendedKey)
+	assert.Nil(t, restored.UnmarshalText(text))
+	want, err := pvt.Child(HardenedKeyStart)
+	assert.Nil(t, err)
+	got, err := restored.Child(HardenedKeyStart)
+	assert.Nil(t, err)
+	assert.Equal(t, want.PubKeyBytes(), got.PubKeyBytes())
+}
+
+func TestExtraTrailerRoundtrip(t *testing.T) {
+	created := time.Unix(1600000000, 0).UTC()
+	for _, newMaster := range []func([]byte) (*ExtendedKey, error){NewMaster, NewMasterEd} {
+		master, err := newMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+		require.NoError(t, err)
+		sk, err := master.AsSignatureKey()
+		require.NoError(t, err)
+		base := sk.(*signature.PrivateKey)
+
+		unknown, err := signature.RawPrivateKey(base.Algorithm(), base.KeyBytes(), append(base.ExtraBytes(), "future data"...))
+		require.NoError(t, err)
+		tagged, err := base.WithUsage(signature.UsageRecovery, created)
+		require.NoError(t, err)
+
+		for _, key := range []*signature.PrivateKey{unknown, tagged} {
+			ek, err := FromSignatureKey(key)
+			require.NoError(t, err)
+			back, err := ek.AsSignatureKey()
+			require.NoError(t, err)
+			require.Equal(t, key.ExtraBytes(), back.ExtraBytes())
+
+			text, err := ek.MarshalText()
+			require.NoError(t, err)
+			ek2 := new(ExtendedKey)
+			require.NoError(t, ek2.UnmarshalText(text))
+			back, err = ek2.AsSignatureKey()
+			require.NoError(t, err)
+			require.Equal(t, key.ExtraBytes(), back.ExtraBytes())
+
+			// the public key keeps the trailer too
+			pub, err := ek.Public()
+			require.NoError(t, err)
+			pk, err := pub.AsSignatureKey()
+			require.NoError(t, err)
+			require.Equal(t, key.ExtraBytes(), pk.ExtraBytes())
+		}
+
+		ek, err := FromSignatureKey(tagged)
+		require.NoError(t, err)
+		back, err := ek.AsSignatureKey()
+		require.NoError(t, err)
+		require.Equal(t, signature.UsageRecovery, back.(signature.UsageKey).Usage())
+	}
+}
+
+func TestBatchChildren(t *testing.T) {
+	master, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+	pub, err := master.Public()
+	assert.Nil(t, err)
+	edMaster, err := NewMasterEd([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name   string
+		parent *ExtendedKey
+		start  uint32
+		count  uint32
+	}{
+		{"private", master, 0, 20},
+		{"private hardened", master, HardenedKeyStart + 44, 5},
+		{"public", pub, 1000, 20},
+		{"ed", edMaster, HardenedKeyStart, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			children, err := tt.parent.Children(tt.start, tt.count)
+			assert.Nil(t, err)
+			assert.Equal(t, int(tt.count), len(children))
+			for n, child := range children {
+				// no index in these ranges derives an invalid child, so
+				// none are skipped
+				i := tt.start + uint32(n)
+				assert.Equal(t, i, child.ChildNum())
+				want, err := tt.parent.Child(i)
+				assert.Nil(t, err)
+				wantText, err := want.MarshalText()
+				assert.Nil(t, err)
+				gotText, err := child.MarshalText()
+				assert.Nil(t, err)
+				assert.Equal(t, string(wantText), string(gotText))
+			}
+		})
+	}
+}
+
+func TestBatchChildrenErrors(t *testing.T) {
+	master, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+	pub, err := master.Public()
+	assert.Nil(t, err)
+
+	children, err := master.Children(0, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, children)
+
+	_, err = pub.Children(HardenedKeyStart, 1)
+	assert.Equal(t, ErrDeriveHardFromPublic, err)
+
+	// the range may not run from normal into hardened indices, or wrap
+	_, err = master.Children(HardenedKeyStart-2, 3)
+	assert.Error(t, err)
+	_, err = master.Children(1<<32-2, 3)
+	assert.Error(t, err)
+}
+
+func TestPublicChildren(t *testing.T) {
+	master, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+	pub, err := master.Public()
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		parent  *ExtendedKey
+		start   uint32
+		count   uint32
+		workers int
+	}{
+		{"public", pub, 1000, 20, 1},
+		{"private", master, 0, 20, 1},
+		{"several chunks", pub, 7, 3*publicChunk + 5, 3},
+		{"more workers than chunks", pub, 0, 5, 8},
+		{"default workers", pub, 0, 2*publicChunk + 1, 0},
+		{"last indices", pub, HardenedKeyStart - 3, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := pub.Children(tt.start, tt.count)
+			assert.Nil(t, err)
+			got, err := tt.parent.PublicChildren(tt.start, tt.count, tt.workers)
+			assert.Nil(t, err)
+			assert.Equal(t, len(want), len(got))
+			for n := range want {
+				assert.False(t, got[n].IsPrivate())
+				wantText, err := want[n].MarshalText()
+				assert.Nil(t, err)
+				gotText, err := got[n].MarshalText()
+				assert.Nil(t, err)
+				assert.Equal(t, string(wantText), string(gotText))
+			}
+		})
+	}
+}
+
+func TestPublicChildrenErrors(t *testing.T) {
+	master, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+	pub, err := master.Public()
+	assert.Nil(t, err)
+	edMaster, err := NewMasterEd([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	assert.Nil(t, err)
+
+	children, err := pub.PublicChildren(0, 0, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, children)
+
+	_, err = edMaster.PublicChildren(0, 1, 0)
+	assert.Equal(t, ErrHardenedOnly, err)
+
+	// the range may not include hardened indices, even from a private key
+	_, err = master.PublicChildren(HardenedKeyStart, 1, 0)
+	assert.Error(t, err)
+	_, err = pub.PublicChildren(HardenedKeyStart-2, 3, 0)
+	assert.Error(t, err)
+
+	// the parent's policy applies, and follows the children
+	policy := Policy{RequireHardenedBelowDepth: 1}
+	_, err = master.WithPolicy(policy).PublicChildren(0, 1, 0)
+	assert.Equal(t, ErrPolicyRequiresHardened, errors.Cause(err))
+	_, err = pub.WithPolicy(Policy{ForbidPublicDerivation: true}).PublicChildren(0, 1, 0)
+	assert.Equal(t, ErrPolicyForbidsPublicDerivation, err)
+	children, err = master.WithPolicy(Policy{ForbidPublicDerivation: true}).PublicChildren(0, 1, 0)
+	assert.Nil(t, err)
+	got, ok := children[0].Policy()
+	assert.True(t, ok)
+	assert.Equal(t, Policy{ForbidPublicDerivation: true}, got)
+}
+
+func benchmarkChildren(b *testing.B, parent *ExtendedKey, batch bool) {
+	const count = 100
+	for n := 0; n < b.N; n++ {
+		if batch {
+			_, err := parent.Children(0, count)
+			if err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+		for i := uint32(0); i < count; i++ {
+			_, err := parent.Child(i)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkChildPrivate(b *testing.B) {
+	master, _ := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	benchmarkChildren(b, master, false)
+}
+
+func BenchmarkChildrenPrivate(b *testing.B) {
+	master, _ := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	benchmarkChildren(b, master, true)
+}
+
+func BenchmarkChildPublic(b *testing.B) {
+	master, _ := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	pub, _ := master.Public()
+	benchmarkChildren(b, pub, false)
+}
+
+func BenchmarkChildrenPublic(b *testing.B) {
+	master, _ := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	pub, _ := master.Public()
+	benchmarkChildren(b, pub, true)
+}
+
+func BenchmarkPublicChildren(b *testing.B) {
+	master, _ := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	pub, _ := master.Public()
+	const count = 100
+	for n := 0; n < b.N; n++ {
+		_, err := pub.PublicChildren(0, count, 0)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRecoverPublic(t *testing.T) {
+	k, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("message"))
+
+	for _, path := range []string{"/1", "/44'/20036'/100/1", "/44'/20036'/100/2"} {
+		t.Run(path, func(t *testing.T) {
+			child, err := k.DeriveFrom("/", path)
+			require.NoError(t, err)
+			sig, err := child.SignRecoverable(hash[:])
+			require.NoError(t, err)
+			require.Len(t, sig, RecoverableSigLen)
+
+			pub, err := RecoverPublic(hash[:], sig)
+			require.NoError(t, err)
+			expect, err := child.SPubKey()
+			require.NoError(t, err)
+			require.Equal(t, expect.KeyBytes(), pub.KeyBytes())
+
+			// a different message recovers a different key
+			other := sha256.Sum256([]byte("other message"))
+			pub, err = RecoverPublic(other[:], sig)
+			if err == nil {
+				require.NotEqual(t, expect.KeyBytes(), pub.KeyBytes())
+			}
+		})
+	}
+}
+
+func TestRecoverPublicErrors(t *testing.T) {
+	k, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	hash := sha256.Sum256([]byte("message"))
+	sig, err := k.SignRecoverable(hash[:])
+	require.NoError(t, err)
+
+	_, err = RecoverPublic(hash[:31], sig)
+	require.Error(t, err)
+	_, err = RecoverPublic(hash[:], sig[:64])
+	require.Error(t, err)
+
+	uncompressed := append([]byte(nil), sig...)
+	uncompressed[0] -= 4 // the header of an uncompressed key's signature
+	_, err = RecoverPublic(hash[:], uncompressed)
+	require.Error(t, err)
+
+	zero := make([]byte, RecoverableSigLen)
+	zero[0] = secp256k1.CompactHeaderMin
+	_, err = RecoverPublic(hash[:], zero)
+	require.Error(t, err)
+
+	pub, err := k.Public()
+	require.NoError(t, err)
+	_, err = pub.SignRecoverable(hash[:])
+	require.Error(t, err)
+
+	ed, err := NewMasterEd([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	_, err = ed.SignRecoverable(hash[:])
+	require.Error(t, err)
+}
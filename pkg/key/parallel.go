@@ -0,0 +1,152 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+)
+
+// publicChunk is the number of consecutive children each worker derives at
+// a time
+const publicChunk = 256
+
+// PublicChildren derives the public keys of count consecutive non-hardened
+// children, starting at index start, using up to workers goroutines. If
+// workers is not positive, GOMAXPROCS goroutines are used.
+//
+// This is for generating deposit addresses at scale. The children are those
+// which Children would derive from the public key of k, in the same order,
+// and k may be private or public: only its public key is used. Each child
+// costs one scalar base multiplication, which uses btcec's precomputed
+// tables of multiples of the base point, and one point addition; those are
+// spread over the workers, each of which prepares its own derivation state
+// once.
+//
+// It is an error if the range would not fit within the non-hardened indices.
+// Ed25519 keys have no public derivation, so ErrHardenedOnly is returned for
+// them.
+func (k *ExtendedKey) PublicChildren(start, count uint32, workers int) ([]*ExtendedKey, error) {
+	if count == 0 {
+		return nil, nil
+	}
+	if k.isEd {
+		return nil, ErrHardenedOnly
+	}
+	if k.depth == maxUint8 {
+		return nil, ErrDeriveBeyondMaxDepth
+	}
+	if uint64(start)+uint64(count) > HardenedKeyStart {
+		return nil, fmt.Errorf(
+			"deriving %d public children from %d: range includes hardened indices",
+			count, start,
+		)
+	}
+	err := k.checkPolicy(start)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := k.Public()
+	if err != nil {
+		return nil, err
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := int((count + publicChunk - 1) / publicChunk)
+	if workers > chunks {
+		workers = chunks
+	}
+	// load the precomputed tables before the workers need them
+	btcec.S256()
+
+	// children[n] is the child at start+n, or nil if that index is invalid
+	children := make([]*ExtendedKey, count)
+	jobs := make(chan uint32, chunks)
+	for c := uint32(0); c < count; c += publicChunk {
+		jobs <- c
+	}
+	close(jobs)
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			d, err := newChildDeriver(pub)
+			if err != nil {
+				errs[w] = err
+				return
+			}
+			for c := range jobs {
+				end := c + publicChunk
+				if end > count {
+					end = count
+				}
+				for n := c; n < end; n++ {
+					child, err := d.child(start + n)
+					if err == ErrInvalidChild {
+						continue
+					}
+					if err != nil {
+						errs[w] = errors.Wrapf(err, "deriving child %d", start+n)
+						return
+					}
+					child.policy = k.policy
+					children[n] = child
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// per [BIP32], invalid children are skipped; make up any shortfall from
+	// the indices after the range, as Children would have
+	valid := children[:0]
+	for _, child := range children {
+		if child != nil {
+			valid = append(valid, child)
+		}
+	}
+	if len(valid) < int(count) {
+		d, err := newChildDeriver(pub)
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(start) + uint64(count); len(valid) < int(count); i++ {
+			if i >= HardenedKeyStart {
+				return nil, fmt.Errorf(
+					"deriving %d public children from %d: ran out of indices after %d",
+					count, start, len(valid),
+				)
+			}
+			child, err := d.child(uint32(i))
+			if err == ErrInvalidChild {
+				continue
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "deriving child %d", i)
+			}
+			child.policy = k.policy
+			valid = append(valid, child)
+		}
+	}
+	return valid, nil
+}
@@ -12,60 +12,153 @@ package key
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
-type pathElement struct {
-	id     uint32
-	harden bool
+// PathElement is a single step of a derivation path.
+//
+// ID is the child index without the hardening offset; Hardened indicates
+// whether the step is derived as a hardened child.
+type PathElement struct {
+	ID       uint32
+	Hardened bool
+}
+
+// Index returns the child index of this element as used by Child: hardened
+// elements have HardenedKeyStart added to their ID.
+func (e PathElement) Index() uint32 {
+	if e.Hardened {
+		return e.ID + HardenedKeyStart
+	}
+	return e.ID
+}
+
+// String renders this element in path notation, i.e. "44'" or "7"
+func (e PathElement) String() string {
+	s := strconv.FormatUint(uint64(e.ID), 10)
+	if e.Hardened {
+		s += "'"
+	}
+	return s
 }
 
-type path []pathElement
+// Path is a derivation path: the sequence of child derivations from some
+// root key to a descendant key.
+//
+// The empty path is the root itself.
+type Path []PathElement
+
+var (
+	pathValidRE = regexp.MustCompile("^(/([0-9]+)'?)+$")
+	pathParseRE = regexp.MustCompile("/([0-9]+)('?)")
+)
 
-func newPath(s string) (path, error) {
+// ParsePath parses a path string such as "/44'/20036'/100/1".
+//
+// Whitespace is ignored. "/" is the root path. Apart from the root, each
+// slash must be followed by an index, which may be followed by an apostrophe
+// to indicate hardening. Indices must be less than HardenedKeyStart.
+func ParsePath(s string) (Path, error) {
 	// remove all whitespace
 	s = strings.Replace(s, " ", "", -1)
 	// treat root specially
 	if s == "/" {
-		return path{}, nil
+		return Path{}, nil
 	}
 	// now validate the path
 	// note that other than the pure root marker that we already handled,
 	// the numeric part after the slash is not optional
-	valpat := regexp.MustCompile("^(/([0-9]+)'?)+$")
-	if !valpat.MatchString(s) {
+	if !pathValidRE.MatchString(s) {
 		return nil, errors.New("Not a valid path string")
 	}
 
-	parsepat := regexp.MustCompile("/([0-9]+)('?)")
-	saa := parsepat.FindAllStringSubmatch(s, -1)
+	saa := pathParseRE.FindAllStringSubmatch(s, -1)
 	// saa now has one entry for each path element, and
 	// for each entry it has the 0th element as the whole path string,
 	// the first as the path ID, and the second as either
 	// an apostrophe or an empty string.
-	p := make(path, len(saa))
+	p := make(Path, len(saa))
 	for i := range saa {
-		var err error
 		n, err := strconv.ParseUint(saa[i][1], 10, 32)
 		if err != nil {
 			return nil, err
 		}
-		p[i].id = uint32(n)
-		p[i].harden = saa[i][2] == "'"
+		if n >= HardenedKeyStart {
+			return nil, fmt.Errorf("path index %d out of range", n)
+		}
+		p[i].ID = uint32(n)
+		p[i].Hardened = saa[i][2] == "'"
 	}
 	return p, nil
 }
 
-func (p path) isParentOf(c path) bool {
+// String renders the path in the notation accepted by ParsePath
+func (p Path) String() string {
+	if len(p) == 0 {
+		return "/"
+	}
+	var b strings.Builder
+	for _, e := range p {
+		b.WriteByte('/')
+		b.WriteString(e.String())
+	}
+	return b.String()
+}
+
+// Append returns a new path consisting of this path followed by the
+// supplied elements. The receiver is never modified.
+func (p Path) Append(elements ...PathElement) Path {
+	out := make(Path, 0, len(p)+len(elements))
+	out = append(out, p...)
+	return append(out, elements...)
+}
+
+// Child returns a new path extending this one with a single non-hardened step
+func (p Path) Child(id uint32) Path {
+	return p.Append(PathElement{ID: id})
+}
+
+// HardenedChild returns a new path extending this one with a single hardened step
+func (p Path) HardenedChild(id uint32) Path {
+	return p.Append(PathElement{ID: id, Hardened: true})
+}
+
+// IsParentOf is true when c is a strict descendant of p
+func (p Path) IsParentOf(c Path) bool {
 	// if the parent is not shorter than the purported child, it can't be a parent
 	if len(c) <= len(p) {
 		return false
 	}
 	// everything up to the length of the parent has to be the same
 	for i := range p {
-		if c[i].id != p[i].id || c[i].harden != p[i].harden {
+		if c[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasHardened is true when any element of the path is hardened.
+//
+// Such paths cannot be derived from a public key.
+func (p Path) HasHardened() bool {
+	for _, e := range p {
+		if e.Hardened {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFullyHardened is true when every element of the path is hardened.
+//
+// The root path is trivially fully hardened.
+func (p Path) IsFullyHardened() bool {
+	for _, e := range p {
+		if !e.Hardened {
 			return false
 		}
 	}
@@ -78,26 +171,35 @@ func (p path) isParentOf(c path) bool {
 // Note that the parent's known path is simply believed -- we have no mechanism to
 // check that it's true.
 func (k *ExtendedKey) DeriveFrom(parentPath, childPath string) (*ExtendedKey, error) {
-	ppath, err := newPath(parentPath)
+	ppath, err := ParsePath(parentPath)
 	if err != nil {
 		return nil, err
 	}
-	cpath, err := newPath(childPath)
+	cpath, err := ParsePath(childPath)
 	if err != nil {
 		return nil, err
 	}
-	if !ppath.isParentOf(cpath) {
+	return k.DerivePath(ppath, cpath)
+}
+
+// DerivePath is DeriveFrom, operating on already-parsed paths.
+//
+// Note that the parent's known path is simply believed -- we have no mechanism to
+// check that it's true.
+func (k *ExtendedKey) DerivePath(parentPath, childPath Path) (*ExtendedKey, error) {
+	if !parentPath.IsParentOf(childPath) {
 		return nil, errors.New("child is not descended from parent")
 	}
-	// if we get here we know that ppath is a subset of cpath so we can trim cpath
-	cpath = cpath[len(ppath):]
+	// if we get here we know that parentPath is a prefix of childPath so we can trim it
+	rel := childPath[len(parentPath):]
 
 	// now iterate. Note we never assign to *k, so the origin pointer is unchanged.
-	for _, e := range cpath {
-		if e.harden {
-			k, err = k.HardenedChild(e.id)
+	var err error
+	for _, e := range rel {
+		if e.Hardened {
+			k, err = k.HardenedChild(e.ID)
 		} else {
-			k, err = k.Child(e.id)
+			k, err = k.Child(e.ID)
 		}
 		if err != nil {
 			return nil, err
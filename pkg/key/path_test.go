@@ -13,44 +13,131 @@ package key
 import (
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
-func Test_newPath(t *testing.T) {
+func TestParsePath(t *testing.T) {
 	tests := []struct {
 		name    string
 		s       string
-		want    path
+		want    Path
 		wantErr bool
 	}{
-		{"root", "/", path{}, false},
-		{"1 level", "/123", path{pathElement{123, false}}, false},
-		{"1 level hardened", "/123'", path{pathElement{123, true}}, false},
-		{"3 levels", "/123/4/567890", path{
-			pathElement{123, false},
-			pathElement{4, false},
-			pathElement{567890, false},
+		{"root", "/", Path{}, false},
+		{"1 level", "/123", Path{PathElement{123, false}}, false},
+		{"1 level hardened", "/123'", Path{PathElement{123, true}}, false},
+		{"3 levels", "/123/4/567890", Path{
+			PathElement{123, false},
+			PathElement{4, false},
+			PathElement{567890, false},
+		}, false},
+		{"3 levels w/hardened", "/123'/4'/567890", Path{
+			PathElement{123, true},
+			PathElement{4, true},
+			PathElement{567890, false},
 		}, false},
-		{"3 levels w/hardened", "/123'/4'/567890", path{
-			pathElement{123, true},
-			pathElement{4, true},
-			pathElement{567890, false},
+		{"whitespace", "/ 44' / 1", Path{
+			PathElement{44, true},
+			PathElement{1, false},
 		}, false},
 		{"bad path 1", "/foo", nil, true},
 		{"bad path 2", "/'", nil, true},
 		{"bad path 3", "/123/123749327234979", nil, true},
 		{"bad path 4", "/foo//bar", nil, true},
 		{"bad path 5", "//", nil, true},
+		{"index in hardened range", "/2147483648", nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := newPath(tt.s)
+			got, err := ParsePath(tt.s)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("newPath() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("ParsePath() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("newPath() = %v, want %v", got, tt.want)
+				t.Errorf("ParsePath() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestPathStringRoundtrip(t *testing.T) {
+	for _, s := range []string{"/", "/1", "/44'", "/44'/20036'/100/10000", "/0/0'/0"} {
+		t.Run(s, func(t *testing.T) {
+			p, err := ParsePath(s)
+			require.NoError(t, err)
+			require.Equal(t, s, p.String())
+		})
+	}
+}
+
+func TestPathAppend(t *testing.T) {
+	root := Path{}
+	p := root.HardenedChild(44).HardenedChild(20036).Child(100)
+	require.Equal(t, "/44'/20036'/100", p.String())
+	require.Empty(t, root)
+
+	// appending to a shared prefix must not alias
+	a := p.Child(1)
+	b := p.Child(2)
+	require.Equal(t, "/44'/20036'/100/1", a.String())
+	require.Equal(t, "/44'/20036'/100/2", b.String())
+}
+
+func TestPathIsParentOf(t *testing.T) {
+	tests := []struct {
+		parent string
+		child  string
+		want   bool
+	}{
+		{"/", "/1", true},
+		{"/", "/", false},
+		{"/1", "/1", false},
+		{"/1", "/1/2", true},
+		{"/1'", "/1/2", false},
+		{"/1/2", "/1", false},
+		{"/44'/20036'", "/44'/20036'/100/1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.parent+" "+tt.child, func(t *testing.T) {
+			p, err := ParsePath(tt.parent)
+			require.NoError(t, err)
+			c, err := ParsePath(tt.child)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, p.IsParentOf(c))
+		})
+	}
+}
+
+func TestPathHardened(t *testing.T) {
+	p, err := ParsePath("/44'/20036'")
+	require.NoError(t, err)
+	require.True(t, p.HasHardened())
+	require.True(t, p.IsFullyHardened())
+
+	p = p.Child(3)
+	require.True(t, p.HasHardened())
+	require.False(t, p.IsFullyHardened())
+	require.Equal(t, uint32(3), p[2].Index())
+	require.Equal(t, uint32(44+HardenedKeyStart), p[0].Index())
+
+	require.False(t, Path{}.HasHardened())
+	require.True(t, Path{}.IsFullyHardened())
+}
+
+func TestDerivePathMatchesDeriveFrom(t *testing.T) {
+	root, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+
+	fromString, err := root.DeriveFrom("/", "/44'/20036'/100/1")
+	require.NoError(t, err)
+
+	child := Path{}.HardenedChild(44).HardenedChild(20036).Child(100).Child(1)
+	fromPath, err := root.DerivePath(Path{}, child)
+	require.NoError(t, err)
+	require.Equal(t, fromString, fromPath)
+
+	_, err = root.DerivePath(child, Path{})
+	require.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrPolicyRequiresHardened describes an error in which the caller
+	// attempted a non-hardened derivation which the key's Policy forbids.
+	ErrPolicyRequiresHardened = errors.New(
+		"policy requires hardened derivation at this depth")
+
+	// ErrPolicyForbidsPublicDerivation describes an error in which the
+	// caller attempted to derive a child of a public extended key whose
+	// Policy forbids it.
+	ErrPolicyForbidsPublicDerivation = errors.New(
+		"policy forbids derivation from public keys")
+)
+
+// A Policy restricts the children which may be derived from an extended key.
+//
+// Custodial deployments can attach a Policy to a root key with WithPolicy to
+// guarantee that sensitive branches are never derived non-hardened by mistake.
+// The policy is enforced by Child, Children, PublicChildren, HardenedChild,
+// DeriveFrom, and DerivePath, and is inherited by every key derived from the
+// key to which it is attached, and by its Public key.
+//
+// A Policy is not part of the serialized form of a key: a key parsed from
+// text has no policy until one is attached.
+type Policy struct {
+	// RequireHardenedBelowDepth requires every child of a key whose depth is
+	// less than this to be hardened. For example, 3 requires the first three
+	// levels of a path such as /44'/20036'/100'/10/1 to be hardened.
+	RequireHardenedBelowDepth int
+	// ForbidPublicDerivation forbids deriving any child of a public key.
+	ForbidPublicDerivation bool
+}
+
+// check returns an error if the policy forbids deriving child i of k
+func (p *Policy) check(k *ExtendedKey, i uint32) error {
+	if p == nil {
+		return nil
+	}
+	if p.ForbidPublicDerivation && !k.isPrivate {
+		return ErrPolicyForbidsPublicDerivation
+	}
+	if int(k.depth) < p.RequireHardenedBelowDepth && i < HardenedKeyStart {
+		return errors.Wrapf(
+			ErrPolicyRequiresHardened,
+			"deriving child %d of key at depth %d", i, k.depth,
+		)
+	}
+	return nil
+}
+
+// checkPolicy returns an error if k's policy forbids deriving child i
+func (k *ExtendedKey) checkPolicy(i uint32) error {
+	return k.policy.check(k, i)
+}
+
+// WithPolicy returns a copy of k to which the given policy is attached.
+//
+// k itself is unchanged; the copy shares its key material.
+func (k *ExtendedKey) WithPolicy(p Policy) *ExtendedKey {
+	out := *k
+	out.policy = &p
+	return &out
+}
+
+// Policy returns the policy attached to k, and whether there is one.
+func (k *ExtendedKey) Policy() (Policy, bool) {
+	if k.policy == nil {
+		return Policy{}, false
+	}
+	return *k.policy, true
+}
@@ -0,0 +1,84 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyRequireHardened(t *testing.T) {
+	root, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	policy := Policy{RequireHardenedBelowDepth: 3}
+	guarded := root.WithPolicy(policy)
+
+	got, ok := guarded.Policy()
+	require.True(t, ok)
+	require.Equal(t, policy, got)
+	_, ok = root.Policy()
+	require.False(t, ok)
+
+	_, err = guarded.Child(44)
+	require.Equal(t, ErrPolicyRequiresHardened, errors.Cause(err))
+	_, err = guarded.DeriveFrom("/", "/44'/20036'/100/1")
+	require.Equal(t, ErrPolicyRequiresHardened, errors.Cause(err))
+	_, err = guarded.Children(0, 10)
+	require.Equal(t, ErrPolicyRequiresHardened, errors.Cause(err))
+
+	// the unguarded root is unaffected
+	_, err = root.Child(44)
+	require.NoError(t, err)
+
+	// past the guarded depth, anything goes, and derived keys are unchanged
+	want, err := root.DeriveFrom("/", "/44'/20036'/100'/10/1")
+	require.NoError(t, err)
+	child, err := guarded.DeriveFrom("/", "/44'/20036'/100'/10/1")
+	require.NoError(t, err)
+	require.Equal(t, want.key, child.key)
+	got, ok = child.Policy()
+	require.True(t, ok)
+	require.Equal(t, policy, got)
+}
+
+func TestPolicyForbidPublicDerivation(t *testing.T) {
+	root, err := NewMaster([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	guarded := root.WithPolicy(Policy{ForbidPublicDerivation: true})
+
+	account, err := guarded.HardenedChild(44)
+	require.NoError(t, err)
+	_, err = account.Child(1)
+	require.NoError(t, err)
+
+	// the policy follows the key to its public form
+	pub, err := account.Public()
+	require.NoError(t, err)
+	_, err = pub.Child(1)
+	require.Equal(t, ErrPolicyForbidsPublicDerivation, err)
+	_, err = pub.Children(0, 2)
+	require.Equal(t, ErrPolicyForbidsPublicDerivation, err)
+}
+
+func TestPolicyEd(t *testing.T) {
+	root, err := NewMasterEd([]byte("abcdefghijklmnopqrstuvwxyz123456"))
+	require.NoError(t, err)
+	guarded := root.WithPolicy(Policy{RequireHardenedBelowDepth: 1})
+
+	child, err := guarded.HardenedChild(44)
+	require.NoError(t, err)
+	_, ok := child.Policy()
+	require.True(t, ok)
+	_, err = guarded.Child(44)
+	require.Equal(t, ErrPolicyRequiresHardened, errors.Cause(err))
+}
@@ -0,0 +1,66 @@
+package key
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signature"
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
+	"github.com/pkg/errors"
+)
+
+// A recoverable signature is a compact secp256k1 signature, in the format
+// of the Secp256k1Recoverable signature algorithm, but of a hash which the
+// caller supplies.
+const (
+	// RecoverableSigLen is the length of a recoverable signature
+	RecoverableSigLen = secp256k1.CompactSignatureSize
+	// HashLen is the length of the message hashes which may be signed
+	// recoverably
+	HashLen = 32
+)
+
+// ErrBadRecoverableSig describes an error in which a recoverable signature
+// is malformed, or no public key can be recovered from it.
+var ErrBadRecoverableSig = errors.New("invalid recoverable signature")
+
+// SignRecoverable signs a 32-byte message hash with the extended key,
+// producing a signature from which the signer's public key can be recovered
+// with RecoverPublic.
+//
+// The key must be a private secp256k1 key.
+func (k *ExtendedKey) SignRecoverable(msgHash []byte) ([]byte, error) {
+	if len(msgHash) != HashLen {
+		return nil, fmt.Errorf("message hash must be %d bytes; got %d", HashLen, len(msgHash))
+	}
+	priv, err := k.ECPrivKey()
+	if err != nil {
+		return nil, err
+	}
+	return secp256k1.SignCompact(priv, msgHash)
+}
+
+// RecoverPublic recovers the public key which made a recoverable signature,
+// as produced by SignRecoverable, of a 32-byte message hash.
+//
+// Any valid signature yields some public key, so the caller must compare the
+// result, or its address, with the expected signer's.
+func RecoverPublic(msgHash, compactSig []byte) (*signature.PublicKey, error) {
+	if len(msgHash) != HashLen {
+		return nil, fmt.Errorf("message hash must be %d bytes; got %d", HashLen, len(msgHash))
+	}
+	pub, err := secp256k1.RecoverCompact(msgHash, compactSig)
+	if err != nil {
+		return nil, errors.Wrap(ErrBadRecoverableSig, err.Error())
+	}
+	return signature.RawPublicKey(signature.Secp256k1, pub, nil)
+}
@@ -10,7 +10,39 @@ package keyaddr
 // - -- --- ---- -----
 
 
+import (
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/address"
+)
+
 // Address is an Ndau Address, derived from a public key.
 type Address struct {
 	Address string
 }
+
+// addressScheme may precede an address written as a URI
+const addressScheme = "ndau:"
+
+// NormalizeAddress cleans up an address as a user might enter, paste, or
+// scan it, validates it, and returns its canonical form.
+//
+// Surrounding whitespace is removed, as is an "ndau:" or "ndau://" URI
+// prefix and any query or fragment following the address; the address may
+// be in any case. Clients should call this rather than cleaning up addresses
+// themselves, so that every client accepts the same input.
+func NormalizeAddress(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= len(addressScheme) && strings.EqualFold(s[:len(addressScheme)], addressScheme) {
+		s = strings.TrimPrefix(s[len(addressScheme):], "//")
+		if end := strings.IndexAny(s, "?#"); end >= 0 {
+			s = s[:end]
+		}
+		s = strings.TrimSuffix(s, "/")
+	}
+	a, err := address.Validate(s)
+	if err != nil {
+		return "", wrapError(err, BadAddress, "error normalizing address")
+	}
+	return a.String(), nil
+}
@@ -11,12 +11,18 @@ package keyaddr
 
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/constants"
 	"github.com/ndau/ndaumath/pkg/key"
+	"github.com/ndau/ndaumath/pkg/signature"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,6 +119,69 @@ func TestWordsFromPrefix(t *testing.T) {
 	}
 }
 
+func TestValidatePartialMnemonic(t *testing.T) {
+	const valid12 = "abandon amount liar amount expire adjust cage candy arch gather drum bundle"
+	tests := []struct {
+		name          string
+		words         string
+		wantRemaining int
+		wantErr       bool
+	}{
+		{"empty", "", 12, false},
+		{"unfinished word", "abandon amo", 11, false},
+		{"finished word", "abandon amount", 10, false},
+		{"word then space", "abandon amount ", 10, false},
+		// act is a word, as well as the beginning of action
+		{"word beginning words", "abandon act", 10, false},
+		{"bad unfinished word", "abandon xyz", 0, true},
+		{"bad word", "abandon xyz amount", 0, true},
+		{"complete", valid12, 0, false},
+		{"complete with whitespace", "  " + strings.Replace(valid12, " ", "\t", 3) + "\n", 0, false},
+		{"unfinished after complete", valid12 + " ab", 3, false},
+		{"bad checksum", strings.Replace(valid12, "bundle", "buyer", 1), 3, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, remaining, err := ValidatePartialMnemonic("en", tt.words)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.False(t, ok)
+				require.Equal(t, BadMnemonic, CodeOf(err))
+				return
+			}
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tt.wantRemaining, remaining)
+
+			got, err := RemainingMnemonicWords("en", tt.words)
+			require.NoError(t, err)
+			require.Equal(t, remaining, got)
+		})
+	}
+
+	_, _, err := ValidatePartialMnemonic("xx", "abandon")
+	require.Error(t, err)
+	_, err = RemainingMnemonicWords("en", "abandon xyz")
+	require.Error(t, err)
+}
+
+func TestNewSeed(t *testing.T) {
+	for strength, nwords := range map[int]int{128: 12, 160: 15, 192: 18, 224: 21, 256: 24} {
+		seed, err := NewSeed(strength)
+		require.NoError(t, err)
+		w, err := WordsFromBytes("en", seed)
+		require.NoError(t, err)
+		require.Len(t, strings.Split(w, " "), nwords)
+		b, err := WordsToBytes("en", w)
+		require.NoError(t, err)
+		require.Equal(t, seed, b)
+		_, err = NewKey(seed)
+		require.NoError(t, err)
+	}
+	_, err := NewSeed(100)
+	require.Error(t, err)
+}
+
 func TestNewKey(t *testing.T) {
 	type args struct {
 		seed string
@@ -142,6 +211,45 @@ func TestNewKey(t *testing.T) {
 	}
 }
 
+func TestNewEdKey(t *testing.T) {
+	// seed of SLIP-0010 test vector 1
+	const seed = "AAECAwQFBgcICQoLDA0ODw=="
+	master, err := NewEdKey(seed)
+	require.NoError(t, err)
+
+	// the ed25519 master must not collide with the secp256k1 master
+	secp, err := NewKey(seed)
+	require.NoError(t, err)
+	require.NotEqual(t, secp.Key, master.Key)
+
+	_, err = master.Child(0)
+	require.Error(t, err, "ed25519 keys support only hardened derivation")
+
+	ch0, err := master.HardenedChild(0)
+	require.NoError(t, err)
+	ch1, err := ch0.HardenedChild(1)
+	require.NoError(t, err)
+
+	sig, err := ch1.SignWith(AlgorithmEd25519, EncodingHex, "01020304")
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEd25519, sig.Algorithm)
+	_, err = ch1.SignWith(AlgorithmSecp256k1, EncodingHex, "01020304")
+	require.Error(t, err)
+
+	pub, err := ch1.ToPublicKey()
+	require.NoError(t, err)
+	require.Equal(t,
+		"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187",
+		hex.EncodeToString(pub.KeyBytes()),
+	)
+	s, err := sig.ToSignature()
+	require.NoError(t, err)
+	require.True(t, s.Verify([]byte{1, 2, 3, 4}, pub))
+
+	_, err = NewEdKey("AQIDBA==")
+	require.Error(t, err, "too-short seed must fail")
+}
+
 func TestKey_ToPublic(t *testing.T) {
 	pvtkey := "npvta8jaftcjebc56pvxgs8w2448fibvc4yqeub8b49b7k4tdg7t5dsdhayzi569eaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxachhw8sfiuejtf"
 	pubkey := "npuba4jaftckeebzgm7usrcx9jxve8rhst5uejqqtzdtjvhdeswdyzvhn22k98kq25iaaaaaaaaaaaapqhv86syt9pwwpm97n5dgixcmr3sc7ai4km65t9r4wt4s4kywai6fkiae5jkc"
@@ -301,7 +409,7 @@ func TestKey_Sign(t *testing.T) {
 		{"basic",
 			fields{"npvta8jaftcjebc56pvxgs8w2448fibvc4yqeub8b49b7k4tdg7t5dsdhayzi569eaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxachhw8sfiuejtf"},
 			args{"AQIDBA=="},
-			&Signature{"aujaftchgbcseiiay7mr4bc69rgj3g4dnf82ijp8t22rnstjerrbwujy5mkbp2382vmseiahffbgsf6aujtn6vs5m3jhnm7qt952f59xemarjrcycphty726ybcgx82x"},
+			&Signature{"aujaftchgbcseiiay7mr4bc69rgj3g4dnf82ijp8t22rnstjerrbwujy5mkbp2382vmseiahffbgsf6aujtn6vs5m3jhnm7qt952f59xemarjrcycphty726ybcgx82x", AlgorithmSecp256k1},
 			false},
 		{"public key should error",
 			fields{"npuba4jaftckeebzgm7usrcx9jxve8rhst5uejqqtzdtjvhdeswdyzvhn22k98kq25iaaaaaaaaaaaapqhv86syt9pwwpm97n5dgixcmr3sc7ai4km65t9r4wt4s4kywai6fkiae5jkc"},
@@ -316,7 +424,7 @@ func TestKey_Sign(t *testing.T) {
 		{"different key should gen diff sig",
 			fields{"npvta8jaftcjebe8sxbuvxcmh6xw37wuam8y2tmzachdzqh24mhjyr4j5pxgzw93aap98fg2aaaaaenphqxyh7nh2zhjfugk3a9xvqwkcarfau8239ykec4h69kzkcs8dx2ek3uwekhx"},
 			args{"AQIDBA=="},
-			&Signature{"ayjaftcggbcaeibatkbh6whxa5qvica5jp7btt3v44z6mtzcyuwad8xifbsnb3rzp6bcan54rfdkmvif8x4z9p5gwrx24f4qx9ukfx2k5u8rjkfgq5fmpmparr9zkyw9"},
+			&Signature{"ayjaftcggbcaeibatkbh6whxa5qvica5jp7btt3v44z6mtzcyuwad8xifbsnb3rzp6bcan54rfdkmvif8x4z9p5gwrx24f4qx9ukfx2k5u8rjkfgq5fmpmparr9zkyw9", AlgorithmSecp256k1},
 			false},
 		{"bad decode should error",
 			fields{"npvta8jaftcjebc56pvxgs8w2448fibvc4yqeub8b49b7k4tdg7t5dsdhayzi569eaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxachhw8sfiuejtf"},
@@ -326,7 +434,7 @@ func TestKey_Sign(t *testing.T) {
 		{"real life test",
 			fields{"npvta8jaftcjed4m8juti4sdgku42kq4g6nckgsprvexjb29jrewmznzshyatbzp6ceik9yaaaaaahvccndjymyem3tknrbsikfwrhfiwr6xg4g5md3gicyzd7adcmn4yn94rgjrgp4r"},
 			args{"bnB1YmE0amFmdGNrZWViNjJ1YWFqOG41eGh1YnJoYjY4dWM2ZXR4bnltYjJqZWNwdnZ4YXZnZGEzYjRhZm1lcm4yMmV1d3JtZ2FhYWFhYXZoN3p4Y2d6ZnA0ZTd3bnozMjI5N3I4emd5N2V1OXRubW15MmRyODhkNHd4ejJ0bWppeWZ5ZDl6aW5hOHYAAAAAAAAAAW5kYXFmZ2Y5bnNkNTZlaGV0OWNxemZneTQ3cHd1YjM0ZDNjYWJha2l6ejN6ejJ4Zm5wdWJhNGphZnRja2VlYnZqYTV5c2VtZmIyZm16cjRrMnFjd2NlZWo3M3ZrYmQ2dzc2M2E1djZjYzZ1N3VkZGNoM2lpdGJtODJhYWFhYWE4bmlqc3BnM3EydHJnZmp0NmdiYml5dDY2eGN0OXV3NWk1cG5yZTNhazQ2cndhbmpwdms5NnNrd3ZwbjNp"},
-			&Signature{"ayjaftcggbcaeiatiugceq4kkh6fwmwrnrzgqqc78xecd9u95djmxwngmip2wm6ut2bca7ghwhh2x7ftdies7df6zrbz8dh7r9br3bzxu8kri34jtrjfuks5yutxr2c3"},
+			&Signature{"ayjaftcggbcaeiatiugceq4kkh6fwmwrnrzgqqc78xecd9u95djmxwngmip2wm6ut2bca7ghwhh2x7ftdies7df6zrbz8dh7r9br3bzxu8kri34jtrjfuks5yutxr2c3", AlgorithmSecp256k1},
 			false},
 	}
 	for _, tt := range tests {
@@ -346,6 +454,68 @@ func TestKey_Sign(t *testing.T) {
 	}
 }
 
+func TestKey_SignWith(t *testing.T) {
+	const secpKey = "npvta8jaftcjebc56pvxgs8w2448fibvc4yqeub8b49b7k4tdg7t5dsdhayzi569eaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxachhw8sfiuejtf"
+	public, private, err := signature.Generate(signature.Ed25519, nil)
+	require.NoError(t, err)
+	edKey, err := private.MarshalString()
+	require.NoError(t, err)
+
+	type args struct {
+		alg      string
+		encoding string
+		payload  string
+	}
+	tests := []struct {
+		name    string
+		key     string
+		args    args
+		wantErr bool
+	}{
+		{"secp base64", secpKey, args{AlgorithmSecp256k1, EncodingBase64, "AQIDBA=="}, false},
+		{"secp hex", secpKey, args{AlgorithmSecp256k1, EncodingHex, "01020304"}, false},
+		{"secp text", secpKey, args{AlgorithmSecp256k1, EncodingText, "\x01\x02\x03\x04"}, false},
+		{"ed25519 base64", edKey, args{AlgorithmEd25519, EncodingBase64, "AQIDBA=="}, false},
+		{"ed25519 hex", edKey, args{AlgorithmEd25519, EncodingHex, "01020304"}, false},
+		{"algorithm mismatch", secpKey, args{AlgorithmEd25519, EncodingBase64, "AQIDBA=="}, true},
+		{"algorithm mismatch ed", edKey, args{AlgorithmSecp256k1, EncodingBase64, "AQIDBA=="}, true},
+		{"unknown algorithm", secpKey, args{"rsa", EncodingBase64, "AQIDBA=="}, true},
+		{"unknown encoding", secpKey, args{AlgorithmSecp256k1, "base58", "AQIDBA=="}, true},
+		{"bad hex", secpKey, args{AlgorithmSecp256k1, EncodingHex, "0x01"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &Key{Key: tt.key}
+			got, err := k.SignWith(tt.args.alg, tt.args.encoding, tt.args.payload)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.args.alg, got.Algorithm)
+
+			sig, err := got.ToSignature()
+			require.NoError(t, err)
+			var pub signature.PublicKey
+			if tt.args.alg == AlgorithmEd25519 {
+				pub = public
+			} else {
+				pub, err = k.ToPublicKey()
+				require.NoError(t, err)
+			}
+			require.True(t, sig.Verify([]byte{1, 2, 3, 4}, pub))
+		})
+	}
+
+	// every encoding of the same payload produces the same signature
+	k := &Key{Key: secpKey}
+	b64, err := k.Sign("AQIDBA==")
+	require.NoError(t, err)
+	hexsig, err := k.SignWith(AlgorithmSecp256k1, EncodingHex, "01020304")
+	require.NoError(t, err)
+	require.Equal(t, b64, hexsig)
+}
+
 func TestKey_NdauAddress(t *testing.T) {
 	type fields struct {
 		Key string
@@ -624,3 +794,476 @@ func TestDebugAPIProblem(t *testing.T) {
 		})
 	}
 }
+
+func TestExportImportWallet(t *testing.T) {
+	root, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+
+	specs := []AccountSpec{
+		{Name: "savings", Path: "/44'/20036'/100/1"},
+		{Name: "spending", Path: "/44'/20036'/100/2", IncludePrivate: true},
+	}
+	doc, err := ExportWallet(root.Key, specs)
+	require.NoError(t, err)
+	require.NotContains(t, doc, root.Key)
+
+	wallet, err := ImportWallet(doc)
+	require.NoError(t, err)
+	require.Equal(t, WalletVersion, wallet.Version)
+	require.Len(t, wallet.Accounts, 2)
+	for i, account := range wallet.Accounts {
+		require.Equal(t, specs[i].Name, account.Name)
+		require.Equal(t, specs[i].Path, account.Path)
+
+		derived, err := DeriveFrom(root.Key, "/", specs[i].Path)
+		require.NoError(t, err)
+		public, err := derived.ToPublic()
+		require.NoError(t, err)
+		addr, err := derived.NdauAddress()
+		require.NoError(t, err)
+		require.Equal(t, public.Key, account.Public)
+		require.Equal(t, addr.Address, account.Address)
+		if specs[i].IncludePrivate {
+			require.Equal(t, derived.Key, account.Private)
+		} else {
+			require.Empty(t, account.Private)
+			require.NotContains(t, doc, derived.Key)
+		}
+	}
+}
+
+func TestExportWalletErrors(t *testing.T) {
+	root, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	public, err := root.ToPublic()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		root     string
+		accounts []AccountSpec
+	}{
+		{"bad root", "npvtgarbage", []AccountSpec{{Path: "/1"}}},
+		{"public root", public.Key, []AccountSpec{{Path: "/1"}}},
+		{"bad path", root.Key, []AccountSpec{{Path: "44'/1"}}},
+		{"duplicate path", root.Key, []AccountSpec{{Path: "/1/2"}, {Path: "/1 /2"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExportWallet(tt.root, tt.accounts)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestImportWalletErrors(t *testing.T) {
+	root, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	other, err := NewKey("AQIDBAUGBwgJCgsMDQ4PEA==")
+	require.NoError(t, err)
+
+	valid, err := ExportWallet(root.Key, []AccountSpec{{Path: "/1", IncludePrivate: true}})
+	require.NoError(t, err)
+	otherDoc, err := ExportWallet(other.Key, []AccountSpec{{Path: "/1", IncludePrivate: true}})
+	require.NoError(t, err)
+	wallet, err := ImportWallet(valid)
+	require.NoError(t, err)
+	otherWallet, err := ImportWallet(otherDoc)
+	require.NoError(t, err)
+
+	tamper := func(f func(*WalletAccount)) string {
+		w := *wallet
+		w.Accounts = []WalletAccount{wallet.Accounts[0]}
+		f(&w.Accounts[0])
+		doc, err := json.Marshal(w)
+		require.NoError(t, err)
+		return string(doc)
+	}
+	tests := []struct {
+		name string
+		doc  string
+	}{
+		{"not json", "{"},
+		{"bad version", `{"version": 2, "accounts": []}`},
+		{"wrong address", tamper(func(a *WalletAccount) { a.Address = otherWallet.Accounts[0].Address })},
+		{"wrong private", tamper(func(a *WalletAccount) { a.Private = otherWallet.Accounts[0].Private })},
+		{"private as public", tamper(func(a *WalletAccount) { a.Public = a.Private })},
+		{"bad path", tamper(func(a *WalletAccount) { a.Path = "1" })},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ImportWallet(tt.doc)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOwnershipChallenge(t *testing.T) {
+	const secpKey = "npvta8jaftcjebc56pvxgs8w2448fibvc4yqeub8b49b7k4tdg7t5dsdhayzi569eaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxachhw8sfiuejtf"
+	const secpPub = "npuba4jaftckeebzgm7usrcx9jxve8rhst5uejqqtzdtjvhdeswdyzvhn22k98kq25iaaaaaaaaaaaapqhv86syt9pwwpm97n5dgixcmr3sc7ai4km65t9r4wt4s4kywai6fkiae5jkc"
+	const secpAddr = "ndad79yux8we7vk7dgvkqjwnkdhme57piydekb9bkbc6r7uj"
+	const challenge = "deposit 1234 to exchange"
+
+	sig, err := SignOwnershipChallenge(secpKey, challenge)
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmSecp256k1, sig.Algorithm)
+
+	ok, err := VerifyOwnershipChallenge(secpAddr, challenge, sig.Signature, secpPub)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// the prefixed message is what is signed
+	s, err := sig.ToSignature()
+	require.NoError(t, err)
+	k := &Key{Key: secpPub}
+	pk, err := k.ToPublicKey()
+	require.NoError(t, err)
+	require.True(t, s.Verify([]byte("ndau signed message:\n"+challenge), pk))
+	require.False(t, s.Verify([]byte(challenge), pk))
+
+	// an ordinary signature of the bare challenge proves nothing
+	plain, err := (&Key{Key: secpKey}).SignWith(AlgorithmSecp256k1, EncodingText, challenge)
+	require.NoError(t, err)
+	ok, err = VerifyOwnershipChallenge(secpAddr, challenge, plain.Signature, secpPub)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// ed25519 keys work too
+	edPublic, edPrivate, err := signature.Generate(signature.Ed25519, nil)
+	require.NoError(t, err)
+	edKey, err := edPrivate.MarshalString()
+	require.NoError(t, err)
+	edPub, err := edPublic.MarshalString()
+	require.NoError(t, err)
+	edAddr, err := address.Generate(address.KindUser, edPublic.KeyBytes())
+	require.NoError(t, err)
+	edSig, err := SignOwnershipChallenge(edKey, challenge)
+	require.NoError(t, err)
+	ok, err = VerifyOwnershipChallenge(edAddr.String(), challenge, edSig.Signature, edPub)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	tests := []struct {
+		name      string
+		addr      string
+		challenge string
+		sig       string
+		pubkey    string
+		wantErr   bool
+	}{
+		{"other challenge", secpAddr, "deposit 1235 to exchange", sig.Signature, secpPub, false},
+		{"other address", edAddr.String(), challenge, sig.Signature, secpPub, false},
+		{"other key", secpAddr, challenge, sig.Signature, edPub, false},
+		{"other signer", edAddr.String(), challenge, sig.Signature, edPub, false},
+		{"bad address", "ndad79yux8we7vk7dgvkqjwnkdhme57piydekb9bkbc6r7ua", challenge, sig.Signature, secpPub, true},
+		{"bad signature", secpAddr, challenge, "abc", secpPub, true},
+		{"bad key", secpAddr, challenge, sig.Signature, "npubabc", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, err := VerifyOwnershipChallenge(tt.addr, tt.challenge, tt.sig, tt.pubkey)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.False(t, ok)
+		})
+	}
+
+	_, err = SignOwnershipChallenge(secpPub, challenge)
+	require.Error(t, err)
+}
+
+func TestKey_AddressOfKind(t *testing.T) {
+	const public = "npuba4jaftckeebzgm7usrcx9jxve8rhst5uejqqtzdtjvhdeswdyzvhn22k98kq25iaaaaaaaaaaaapqhv86syt9pwwpm97n5dgixcmr3sc7ai4km65t9r4wt4s4kywai6fkiae5jkc"
+	k := &Key{Key: public}
+	user, err := k.NdauAddress()
+	require.NoError(t, err)
+
+	seen := make(map[string]bool)
+	for _, info := range address.Kinds() {
+		if info.Reserved {
+			continue
+		}
+		for _, name := range []string{info.Name, strings.ToUpper(info.Name), string(info.Byte)} {
+			t.Run(name, func(t *testing.T) {
+				got, err := k.AddressOfKind(name)
+				require.NoError(t, err)
+				a, err := address.Validate(got.Address)
+				require.NoError(t, err)
+				require.Equal(t, info.Byte, a.Kind())
+				if info.Byte == address.KindUser {
+					require.Equal(t, user, got)
+				}
+			})
+		}
+		got, err := k.AddressOfKind(info.Name)
+		require.NoError(t, err)
+		require.False(t, seen[got.Address], "addresses of each kind must differ")
+		seen[got.Address] = true
+	}
+
+	for _, name := range []string{"", "usr", "xyz", "!"} {
+		_, err := k.AddressOfKind(name)
+		require.Error(t, err, name)
+	}
+	require.NoError(t, address.RegisterKind(address.KindInfo{Byte: 'r', Name: "reservedtest", Reserved: true}))
+	_, err = k.AddressOfKind("reservedtest")
+	require.Error(t, err)
+	_, err = (&Key{Key: "npubbad"}).AddressOfKind("user")
+	require.Error(t, err)
+}
+
+func TestKey_AddressOfKindOn(t *testing.T) {
+	const public = "npuba4jaftckeebzgm7usrcx9jxve8rhst5uejqqtzdtjvhdeswdyzvhn22k98kq25iaaaaaaaaaaaapqhv86syt9pwwpm97n5dgixcmr3sc7ai4km65t9r4wt4s4kywai6fkiae5jkc"
+	k := &Key{Key: public}
+	for _, kind := range []byte{address.KindUser, address.KindExchange, address.KindBPC} {
+		main, err := k.AddressOfKind(string(kind))
+		require.NoError(t, err)
+		for _, id := range []string{"nd", "ND", "mainnet", "MainNet"} {
+			got, err := k.AddressOfKindOn(string(kind), id)
+			require.NoError(t, err)
+			require.Equal(t, main, got)
+		}
+		for _, id := range []string{"tn", "TN", "testnet"} {
+			got, err := k.AddressOfKindOn(string(kind), id)
+			require.NoError(t, err)
+			a, err := address.ValidateForNetwork(got.Address, constants.TestNet)
+			require.NoError(t, err)
+			require.Equal(t, kind, a.Kind())
+			require.NotEqual(t, main, got)
+		}
+	}
+
+	for _, id := range []string{"", "n", "xx", "devnet"} {
+		_, err := k.AddressOfKindOn("user", id)
+		require.Error(t, err, id)
+		require.Equal(t, BadArgument, CodeOf(err), id)
+	}
+	_, err := k.AddressOfKindOn("xyz", "tn")
+	require.Error(t, err)
+}
+
+func TestErrorCodes(t *testing.T) {
+	const seed = "AAECAwQFBgcICQoLDA0ODw=="
+	master, err := NewKey(seed)
+	require.NoError(t, err)
+	public, err := master.ToPublic()
+	require.NoError(t, err)
+	edMaster, err := NewEdKey(seed)
+	require.NoError(t, err)
+
+	mustErr := func(_ interface{}, err error) error {
+		require.Error(t, err)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"bad base64 seed", mustErr(NewKey("not base64!")), BadEncoding},
+		{"short seed", mustErr(NewKey("AQIDBA==")), BadSeed},
+		{"bad strength", mustErr(NewSeed(100)), BadSeed},
+		{"bad key", mustErr(FromString("npvtnotakey")), BadKey},
+		{"bad path", mustErr(DeriveFrom(master.Key, "/", "/zero/one")), BadPath},
+		{"hardened from public", mustErr(public.HardenedChild(0)), HardenedFromPublic},
+		{"hardened only", mustErr(edMaster.Child(0)), HardenedOnly},
+		{"negative index", mustErr(master.Child(-1)), BadIndex},
+		{"sign with public", mustErr(public.Sign("AQIDBA==")), BadKey},
+		{"wrong algorithm", mustErr(master.SignWith(AlgorithmEd25519, EncodingHex, "01")), WrongAlgorithm},
+		{"unknown encoding", mustErr(master.SignWith(AlgorithmSecp256k1, "rot13", "01")), BadArgument},
+		{"unknown kind", mustErr(master.AddressOfKind("nonesuch")), BadArgument},
+		{"bad mnemonic", mustErr(WordsToBytes("en", "not a real mnemonic")), BadMnemonic},
+		{"bad wallet", mustErr(ImportWallet("{")), BadWallet},
+		{"export from public", mustErr(ExportWallet(public.Key, nil)), NotPrivate},
+		{"bad address", mustErr(VerifyOwnershipChallenge("ndanotanaddress", "c", "", "")), BadAddress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.IsType(t, &Error{}, tt.err)
+			require.Equal(t, tt.want, CodeOf(tt.err), tt.err.Error())
+			require.Equal(t, tt.want, CodeOfMessage(tt.err.Error()))
+			require.Equal(t, tt.want, CodeOfMessage("wrapped: "+tt.err.Error()))
+		})
+	}
+
+	require.Equal(t, 0, CodeOf(nil))
+	require.Equal(t, Unknown, CodeOf(fmt.Errorf("foreign")))
+	require.Equal(t, 0, CodeOfMessage("foreign"))
+	require.Equal(t, 0, CodeOfMessage("keyaddr error x: foreign"))
+}
+
+func TestRecoverPublic(t *testing.T) {
+	master, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	child, err := master.HardenedChild(44)
+	require.NoError(t, err)
+	public, err := child.ToPublic()
+	require.NoError(t, err)
+	addr, err := child.NdauAddress()
+	require.NoError(t, err)
+
+	const msg = "AQIDBAUGBwg="
+	sig, err := child.SignRecoverable(msg)
+	require.NoError(t, err)
+
+	recovered, err := RecoverPublic(msg, sig)
+	require.NoError(t, err)
+	recoveredKey, err := signature.ParsePublicKey(recovered)
+	require.NoError(t, err)
+	expectKey, err := public.ToPublicKey()
+	require.NoError(t, err)
+	require.Equal(t, expectKey.KeyBytes(), recoveredKey.KeyBytes())
+	recoveredAddr, err := RecoverAddress(msg, sig)
+	require.NoError(t, err)
+	require.Equal(t, addr.Address, recoveredAddr.Address)
+
+	// another message recovers another signer, if any
+	other, err := RecoverAddress("CAcGBQQDAgE=", sig)
+	if err == nil {
+		require.NotEqual(t, addr.Address, other.Address)
+	}
+
+	_, err = public.SignRecoverable(msg)
+	require.Equal(t, NotPrivate, CodeOf(err))
+	_, err = child.SignRecoverable("not base64!")
+	require.Equal(t, BadEncoding, CodeOf(err))
+	_, err = RecoverPublic(msg, "AQID")
+	require.Equal(t, BadSignature, CodeOf(err))
+	_, err = RecoverPublic(msg, "not base64!")
+	require.Equal(t, BadEncoding, CodeOf(err))
+}
+
+func TestSignBatch(t *testing.T) {
+	k, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	public, err := k.ToPublic()
+	require.NoError(t, err)
+	pub, err := public.ToPublicKey()
+	require.NoError(t, err)
+
+	msgs := []string{"AQIDBA==", "", "bmRhdSBpcyBncmVhdAo="}
+	sigs, err := SignBatch(k.Key, msgs)
+	require.NoError(t, err)
+	require.Len(t, sigs, len(msgs))
+	for idx, msgstr := range msgs {
+		msg, err := base64.StdEncoding.DecodeString(msgstr)
+		require.NoError(t, err)
+		sig, err := sigs[idx].ToSignature()
+		require.NoError(t, err)
+		require.True(t, pub.Verify(msg, sig), msgstr)
+		require.Equal(t, AlgorithmSecp256k1, sigs[idx].Algorithm)
+	}
+
+	sigs, err = SignBatch(k.Key, nil)
+	require.NoError(t, err)
+	require.Empty(t, sigs)
+
+	sigsJSON, err := SignBatchJSON(k.Key, `["AQIDBA==", ""]`)
+	require.NoError(t, err)
+	var texts []string
+	require.NoError(t, json.Unmarshal([]byte(sigsJSON), &texts))
+	require.Len(t, texts, 2)
+	for idx, msgstr := range []string{"AQIDBA==", ""} {
+		msg, err := base64.StdEncoding.DecodeString(msgstr)
+		require.NoError(t, err)
+		sig, err := Signature{Signature: texts[idx]}.ToSignature()
+		require.NoError(t, err)
+		require.True(t, pub.Verify(msg, sig), msgstr)
+	}
+
+	_, err = SignBatch(public.Key, msgs)
+	require.Equal(t, BadKey, CodeOf(err))
+	_, err = SignBatch(k.Key, []string{"AQIDBA==", "not base64!"})
+	require.Equal(t, BadEncoding, CodeOf(err))
+	_, err = SignBatchJSON(k.Key, `"AQIDBA=="`)
+	require.Equal(t, BadArgument, CodeOf(err))
+}
+
+func TestKey_Details(t *testing.T) {
+	master, err := NewKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	details, err := master.Details()
+	require.NoError(t, err)
+	addr, err := master.NdauAddress()
+	require.NoError(t, err)
+	require.Equal(t, &KeyDetails{
+		Depth:             0,
+		ChildIndex:        0,
+		Hardened:          false,
+		ParentFingerprint: "000000",
+		Algorithm:         AlgorithmSecp256k1,
+		IsPrivate:         true,
+		Address:           addr.Address,
+	}, details)
+
+	account, err := master.HardenedChild(44)
+	require.NoError(t, err)
+	details, err = account.Details()
+	require.NoError(t, err)
+	require.Equal(t, 1, details.Depth)
+	require.Equal(t, int64(44), details.ChildIndex)
+	require.True(t, details.Hardened)
+	require.Len(t, details.ParentFingerprint, 6)
+	require.NotEqual(t, "000000", details.ParentFingerprint)
+
+	child, err := account.Child(3)
+	require.NoError(t, err)
+	public, err := child.ToPublic()
+	require.NoError(t, err)
+	details, err = public.Details()
+	require.NoError(t, err)
+	addr, err = child.NdauAddress()
+	require.NoError(t, err)
+	require.Equal(t, 2, details.Depth)
+	require.Equal(t, int64(3), details.ChildIndex)
+	require.False(t, details.Hardened)
+	require.False(t, details.IsPrivate)
+	require.Equal(t, addr.Address, details.Address)
+
+	ed, err := NewEdKey("AAECAwQFBgcICQoLDA0ODw==")
+	require.NoError(t, err)
+	details, err = ed.Details()
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmEd25519, details.Algorithm)
+
+	_, err = (&Key{Key: "npubaaaaaaaaaaaaadmt69zefwr5pfdk99mg23ufiu58nazicguu9g6r58xeqwguxxacga5vf83ihtk9w43urhv2i73cezhi5t2w3vtuikb5m3vynnfr9fhnpxzbg7xx"}).Details()
+	require.Equal(t, BadKey, CodeOf(err))
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	const canonical = "ndad79yux8we7vk7dgvkqjwnkdhme57piydekb9bkbc6r7uj"
+	upper := strings.ToUpper(canonical)
+	for _, s := range []string{
+		canonical,
+		upper,
+		"  " + canonical + "\n",
+		"ndau:" + canonical,
+		"NDAU:" + upper,
+		"ndau://" + canonical,
+		"ndau://" + canonical + "/",
+		"ndau:" + canonical + "?amount=1.5&memo=rent",
+		"ndau:" + canonical + "#note",
+		"\tndau:" + upper + " ",
+	} {
+		got, err := NormalizeAddress(s)
+		require.NoError(t, err, "%q", s)
+		require.Equal(t, canonical, got, "%q", s)
+	}
+
+	for _, s := range []string{
+		"",
+		"ndau:",
+		canonical[:len(canonical)-1] + "a",
+		canonical + "?amount=1",
+		"bitcoin:" + canonical,
+		canonical[:10] + " " + canonical[10:],
+	} {
+		_, err := NormalizeAddress(s)
+		require.Error(t, err, "%q", s)
+		require.Equal(t, BadAddress, CodeOf(err), "%q", s)
+	}
+}
@@ -0,0 +1,75 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signature"
+)
+
+// SignBatch signs each of a list of messages with the given private key, as
+// Key.Sign does; each message must be the standard base64 encoding of its
+// bytes.
+//
+// The key is parsed only once, so this is much faster than signing the
+// messages one at a time across the mobile bridge. The signatures are in the
+// same order as the messages. If any message can't be signed, no signatures
+// are returned.
+func SignBatch(key string, msgsB64 []string) ([]Signature, error) {
+	pk, err := signature.ParsePrivateKey(key)
+	if err != nil {
+		return nil, wrapError(err, BadKey, "error getting private key")
+	}
+	if have := signature.NameOf(pk.Algorithm()); have != AlgorithmSecp256k1 {
+		return nil, newError(WrongAlgorithm, "key algorithm is %s, not %s", have, AlgorithmSecp256k1)
+	}
+
+	sigs := make([]Signature, 0, len(msgsB64))
+	for idx, msgstr := range msgsB64 {
+		msg, err := base64.StdEncoding.DecodeString(msgstr)
+		if err != nil {
+			return nil, wrapError(err, BadEncoding, fmt.Sprintf("message %d: error decoding string", idx))
+		}
+		sig, err := SignatureFrom(pk.Sign(msg))
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, *sig)
+	}
+	return sigs, nil
+}
+
+// SignBatchJSON is SignBatch for callers, such as gomobile, which can't pass
+// lists: msgsJSON is a JSON array of base64-encoded messages, and the result
+// is a JSON array of the text of their signatures.
+func SignBatchJSON(key, msgsJSON string) (string, error) {
+	var msgs []string
+	err := json.Unmarshal([]byte(msgsJSON), &msgs)
+	if err != nil {
+		return "", wrapError(err, BadArgument, "decoding messages")
+	}
+	sigs, err := SignBatch(key, msgs)
+	if err != nil {
+		return "", err
+	}
+	texts := make([]string, 0, len(sigs))
+	for _, sig := range sigs {
+		texts = append(texts, sig.Signature)
+	}
+	out, err := json.Marshal(texts)
+	if err != nil {
+		return "", wrapError(err, Unknown, "encoding signatures")
+	}
+	return string(out), nil
+}
@@ -0,0 +1,164 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/key"
+	"github.com/pkg/errors"
+)
+
+// These are the codes of keyaddr Errors.
+//
+// They are stable: codes may be added, but an existing code's meaning never
+// changes, so that wallets can map them to localized messages. They are
+// untyped integers so that gomobile can export them.
+const (
+	// Unknown is any failure not otherwise classified
+	Unknown = 1
+	// BadEncoding means that an argument was not correctly encoded, for
+	// example as base64 or hex
+	BadEncoding = 2
+	// BadKey means that a key could not be parsed
+	BadKey = 3
+	// NotPrivate means that a private key was required, but a public key was
+	// supplied
+	NotPrivate = 4
+	// BadPath means that a derivation path could not be parsed or followed
+	BadPath = 5
+	// HardenedFromPublic means that hardened derivation was attempted from a
+	// public key
+	HardenedFromPublic = 6
+	// HardenedOnly means that non-hardened derivation was attempted from a
+	// key, such as an ed25519 key, which supports only hardened derivation
+	HardenedOnly = 7
+	// BadIndex means that a child index was negative or otherwise unusable
+	BadIndex = 8
+	// BadSeed means that a seed was of an unusable length or strength
+	BadSeed = 9
+	// WrongAlgorithm means that a key's algorithm was not the one expected
+	WrongAlgorithm = 10
+	// BadAddress means that an address could not be validated or generated
+	BadAddress = 11
+	// BadSignature means that a signature could not be parsed
+	BadSignature = 12
+	// BadArgument means that an argument, such as the name of an encoding or
+	// of an address kind, was not one of its permitted values
+	BadArgument = 13
+	// BadWallet means that a wallet document was malformed or inconsistent
+	BadWallet = 14
+	// BadMnemonic means that a mnemonic phrase could not be decoded
+	BadMnemonic = 15
+)
+
+// Error is the error type returned by keyaddr functions
+//
+// Its text includes its code, as "keyaddr error <code>: <message>", so that
+// consumers which receive errors only as strings, such as apps built with
+// gomobile, can recover the code with CodeOfMessage.
+type Error struct {
+	Code    int
+	Message string
+}
+
+var _ error = (*Error)(nil)
+
+// errorTag precedes the code in the text of every Error
+const errorTag = "keyaddr error "
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s%d: %s", errorTag, e.Code, e.Message)
+}
+
+// newError creates an Error with the given code
+func newError(code int, format string, args ...interface{}) error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// wrapError returns an Error describing err in the given context
+//
+// If err is an Error, or one of the errors of the key package which has a
+// corresponding code, it keeps that code; otherwise it has the code given.
+// It returns nil if err is nil.
+func wrapError(err error, code int, context string) error {
+	if err == nil {
+		return nil
+	}
+	var msg string
+	if e, ok := errors.Cause(err).(*Error); ok {
+		msg = e.Message
+	} else {
+		msg = err.Error()
+	}
+	if context != "" {
+		msg = context + ": " + msg
+	}
+	return &Error{Code: classify(err, code), Message: msg}
+}
+
+// classify returns the code of err, or fallback if it has none
+func classify(err error, fallback int) int {
+	switch cause := errors.Cause(err); cause {
+	case key.ErrDeriveHardFromPublic:
+		return HardenedFromPublic
+	case key.ErrHardenedOnly:
+		return HardenedOnly
+	case key.ErrNotPrivExtKey:
+		return NotPrivate
+	case key.ErrNotSecp256k1:
+		return WrongAlgorithm
+	case key.ErrBadRecoverableSig:
+		return BadSignature
+	case key.ErrInvalidChild, key.ErrDeriveBeyondMaxDepth:
+		return BadIndex
+	case key.ErrUnusableSeed, key.ErrInvalidSeedLen:
+		return BadSeed
+	default:
+		if e, ok := cause.(*Error); ok {
+			return e.Code
+		}
+		return fallback
+	}
+}
+
+// CodeOf returns the code of a keyaddr Error
+//
+// It returns 0 if err is nil, and Unknown if err is not an Error.
+func CodeOf(err error) int {
+	if err == nil {
+		return 0
+	}
+	return classify(err, Unknown)
+}
+
+// CodeOfMessage returns the code of the Error whose text is message
+//
+// This is for consumers which receive errors only as strings. It returns 0
+// if message is not the text of an Error.
+func CodeOfMessage(message string) int {
+	idx := strings.Index(message, errorTag)
+	if idx < 0 {
+		return 0
+	}
+	rest := message[idx+len(errorTag):]
+	end := strings.IndexByte(rest, ':')
+	if end < 0 {
+		return 0
+	}
+	code, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return code
+}
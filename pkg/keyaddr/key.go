@@ -31,10 +31,14 @@ package keyaddr
 
 import (
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
 
 	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/constants"
 	"github.com/ndau/ndaumath/pkg/key"
-	"github.com/pkg/errors"
+	"github.com/ndau/ndaumath/pkg/signature"
 )
 
 // Key is the object that contains a public or private key
@@ -45,14 +49,34 @@ type Key struct {
 // NewKey takes a seed (an array of bytes encoded as a base64 string) and creates a private master
 // key from it. The key is returned as a string representation of the key;
 // it is converted to and from the internal representation by its member functions.
+//
+// Security-conscious users may prefer a seed of greater strength than the
+// 128 bits of a 12-word recovery phrase: see NewSeed.
 func NewKey(seedstr string) (*Key, error) {
 	seed, err := base64.StdEncoding.DecodeString(seedstr)
 	if err != nil {
-		return nil, errors.Wrap(err, "error decoding base64 string")
+		return nil, wrapError(err, BadEncoding, "error decoding base64 string")
 	}
 	mk, err := key.NewMaster([]byte(seed))
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating new master")
+		return nil, wrapError(err, BadSeed, "error creating new master")
+	}
+	return KeyFromExtended(mk)
+}
+
+// NewEdKey takes a seed (an array of bytes encoded as a base64 string) and
+// creates an Ed25519 private master key from it, per SLIP-0010. The same
+// seed may be used with NewKey to produce a secp256k1 master key.
+//
+// Only hardened children of the resulting key can be derived.
+func NewEdKey(seedstr string) (*Key, error) {
+	seed, err := base64.StdEncoding.DecodeString(seedstr)
+	if err != nil {
+		return nil, wrapError(err, BadEncoding, "error decoding base64 string")
+	}
+	mk, err := key.NewMasterEd([]byte(seed))
+	if err != nil {
+		return nil, wrapError(err, BadSeed, "error creating new ed25519 master")
 	}
 	return KeyFromExtended(mk)
 }
@@ -67,7 +91,7 @@ func FromString(s string) (*Key, error) {
 		if nerr == nil {
 			return key, nil
 		}
-		return nil, errors.Wrap(nerr, "couldn't unmarshal extended key from bytes: error also trying old string method")
+		return nil, wrapError(nerr, BadKey, "couldn't unmarshal extended key from bytes: error also trying old string method")
 	}
 
 	// re-marshal for reasons?
@@ -81,7 +105,7 @@ func FromString(s string) (*Key, error) {
 func FromOldString(s string) (*Key, error) {
 	ekey, err := key.FromOldSerialization(s)
 	if err != nil {
-		return nil, errors.Wrap(err, "error parsing old key serialization format")
+		return nil, wrapError(err, BadKey, "error parsing old key serialization format")
 	}
 	return KeyFromExtended(ekey)
 }
@@ -93,15 +117,15 @@ func FromOldString(s string) (*Key, error) {
 func DeriveFrom(parentKey string, parentPath, childPath string) (*Key, error) {
 	k, err := FromString(parentKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "error getting key from string")
+		return nil, wrapError(err, BadKey, "error getting key from string")
 	}
 	e, err := k.ToExtended()
 	if err != nil {
-		return nil, errors.Wrap(err, "")
+		return nil, wrapError(err, BadKey, "")
 	}
 	e, err = e.DeriveFrom(parentPath, childPath)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadPath, "")
 	}
 	return KeyFromExtended(e)
 }
@@ -113,11 +137,11 @@ func DeriveFrom(parentKey string, parentPath, childPath string) (*Key, error) {
 func (k *Key) ToPublic() (*Key, error) {
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadKey, "")
 	}
 	nk, err := ekey.Public()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, Unknown, "")
 	}
 	return KeyFromExtended(nk)
 }
@@ -128,16 +152,16 @@ func (k *Key) ToPublic() (*Key, error) {
 // It is an error if the given key is a hardened key.
 func (k *Key) Child(n int32) (*Key, error) {
 	if n < 0 {
-		return nil, errors.New("child index cannot be negative")
+		return nil, newError(BadIndex, "child index cannot be negative")
 	}
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadKey, "")
 	}
 	ndx := uint32(n)
 	nk, err := ekey.Child(ndx)
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, Unknown, "")
 	}
 	return KeyFromExtended(nk)
 }
@@ -150,35 +174,81 @@ func (k *Key) Child(n int32) (*Key, error) {
 // It is an error if the given key is already a hardened key.
 func (k *Key) HardenedChild(n int32) (*Key, error) {
 	if n < 0 {
-		return nil, errors.New("child index cannot be negative")
+		return nil, newError(BadIndex, "child index cannot be negative")
 	}
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadKey, "")
 	}
 	nk, err := ekey.HardenedChild(uint32(n))
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, Unknown, "")
 	}
 	return KeyFromExtended(nk)
 }
 
+// These are the algorithms which SignWith understands
+const (
+	AlgorithmSecp256k1 = "secp256k1"
+	AlgorithmEd25519   = "ed25519"
+)
+
+// These are the payload encodings which SignWith understands
+const (
+	// EncodingBase64 is the standard base64 encoding of the payload bytes
+	EncodingBase64 = "base64"
+	// EncodingHex is the hexadecimal encoding of the payload bytes
+	EncodingHex = "hex"
+	// EncodingText signs the bytes of the payload string itself
+	EncodingText = "text"
+)
+
+// decodePayload converts a payload string into the bytes it represents
+func decodePayload(encoding, payload string) ([]byte, error) {
+	switch encoding {
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(payload)
+	case EncodingHex:
+		return hex.DecodeString(payload)
+	case EncodingText:
+		return []byte(payload), nil
+	default:
+		return nil, newError(BadArgument, "unknown payload encoding: %q", encoding)
+	}
+}
+
 // Sign uses the given key to sign a message; the message must be the
 // standard base64 encoding of the bytes of the message.
 // It returns a signature object.
 // The key must be a private key.
+//
+// It is equivalent to SignWith(AlgorithmSecp256k1, EncodingBase64, msgstr).
 func (k *Key) Sign(msgstr string) (*Signature, error) {
-	msg, err := base64.StdEncoding.DecodeString(msgstr)
+	return k.SignWith(AlgorithmSecp256k1, EncodingBase64, msgstr)
+}
+
+// SignWith uses the given key to sign a payload.
+//
+// alg names the algorithm the key is expected to use: one of the Algorithm
+// constants. It is an error if the key is of any other algorithm. Keys
+// derived from this package's extended keys are always secp256k1; ed25519
+// keys must be supplied in the serialization of the signature package.
+//
+// encoding describes how the payload is encoded: one of the Encoding
+// constants.
+//
+// The key must be a private key.
+func (k *Key) SignWith(alg, encoding, payload string) (*Signature, error) {
+	msg, err := decodePayload(encoding, payload)
 	if err != nil {
-		return nil, errors.Wrap(err, "error decoding string")
+		return nil, wrapError(err, BadEncoding, "error decoding string")
 	}
-	ekey, err := k.ToExtended()
+	pk, err := signature.ParsePrivateKey(k.Key)
 	if err != nil {
-		return nil, errors.Wrap(err, "error converting to extended")
+		return nil, wrapError(err, BadKey, "error getting private key")
 	}
-	pk, err := ekey.SPrivKey()
-	if err != nil {
-		return nil, errors.Wrap(err, "error getting private key")
+	if have := signature.NameOf(pk.Algorithm()); have != alg {
+		return nil, newError(WrongAlgorithm, "key algorithm is %s, not %s", have, alg)
 	}
 	sig := pk.Sign(msg)
 	return SignatureFrom(sig)
@@ -188,16 +258,82 @@ func (k *Key) Sign(msgstr string) (*Signature, error) {
 // Key can be either public or private; if it is private it will be
 // converted to a public key first.
 func (k *Key) NdauAddress() (*Address, error) {
-	kind := address.KindUser
-	ekey, err := k.ToExtended()
+	return k.addressOn(constants.MainNet, address.KindUser)
+}
+
+// AddressOfKind returns the ndau address of the given kind associated with
+// the given key. kindName is the name of any registered address kind, such
+// as "user", "exchange", or "bpc", or its single kind character.
+//
+// As for NdauAddress, the key can be either public or private. NdauAddress
+// is equivalent to AddressOfKind("user"). The address is on the main
+// network; AddressOfKind is equivalent to AddressOfKindOn(kindName, "nd").
+func (k *Key) AddressOfKind(kindName string) (*Address, error) {
+	return k.AddressOfKindOn(kindName, "nd")
+}
+
+// AddressOfKindOn returns the address of the given kind associated with the
+// given key, as AddressOfKind does, on the network given by chainID.
+//
+// chainID is either the address prefix of a network, "nd" or "tn", or its
+// name, "mainnet" or "testnet", in any case. The kind is looked up before
+// the network, so an unknown kind is reported as such whatever the chainID.
+func (k *Key) AddressOfKindOn(kindName, chainID string) (*Address, error) {
+	kind, err := kindNamed(kindName)
 	if err != nil {
 		return nil, err
 	}
-
-	a, err := address.Generate(kind, ekey.PubKeyBytes())
+	net, err := networkOf(chainID)
 	if err != nil {
 		return nil, err
 	}
+	return k.addressOn(net, kind)
+}
+
+// kindNamed returns the registered, unreserved address kind with the given
+// name or kind character
+func kindNamed(kindName string) (byte, error) {
+	name := strings.ToLower(kindName)
+	for _, info := range address.Kinds() {
+		if info.Name != name && string(info.Byte) != name {
+			continue
+		}
+		if info.Reserved {
+			return 0, newError(BadArgument, "address kind %s is reserved", info.Name)
+		}
+		return info.Byte, nil
+	}
+	return 0, newError(BadArgument, "unknown address kind: %q", kindName)
+}
+
+// networkOf returns the network identified by chainID, which is either the
+// network's address prefix or its name
+func networkOf(chainID string) (constants.Network, error) {
+	id := strings.ToLower(chainID)
+	for net, name := range constants.NetworkNames {
+		prefix, err := address.NetworkPrefix(net)
+		if err != nil {
+			continue
+		}
+		if id == prefix || id == name {
+			return net, nil
+		}
+	}
+	return 0, newError(BadArgument, "unknown chain id: %q", chainID)
+}
+
+// addressOn returns the address of the given kind associated with the key
+// on the given network
+func (k *Key) addressOn(net constants.Network, kind byte) (*Address, error) {
+	ekey, err := k.ToExtended()
+	if err != nil {
+		return nil, wrapError(err, BadKey, "")
+	}
+
+	a, err := address.GenerateForNetwork(net, kind, ekey.PubKeyBytes())
+	if err != nil {
+		return nil, wrapError(err, BadAddress, "")
+	}
 
 	return &Address{a.String()}, nil
 }
@@ -207,7 +343,54 @@ func (k *Key) NdauAddress() (*Address, error) {
 func (k *Key) IsPrivate() (bool, error) {
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return false, err
+		return false, wrapError(err, BadKey, "")
 	}
 	return ekey.IsPrivate(), nil
 }
+
+// KeyDetails describes an extended key, for display.
+//
+// ChildIndex is the index at which the key was derived from its parent, less
+// HardenedKeyStart if Hardened. ParentFingerprint is the hex of the
+// fingerprint of the parent key, or "000000" for a master key. Address is the
+// key's user address, as from NdauAddress.
+type KeyDetails struct {
+	Depth             int
+	ChildIndex        int64
+	Hardened          bool
+	ParentFingerprint string
+	Algorithm         string
+	IsPrivate         bool
+	Address           string
+}
+
+// Details returns the metadata of the given key.
+func (k *Key) Details() (*KeyDetails, error) {
+	ekey, err := k.ToExtended()
+	if err != nil {
+		return nil, wrapError(err, BadKey, "")
+	}
+	addr, err := k.NdauAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	index := ekey.ChildNum()
+	hardened := index >= key.HardenedKeyStart
+	if hardened {
+		index -= key.HardenedKeyStart
+	}
+	algorithm := AlgorithmSecp256k1
+	if ekey.IsEd() {
+		algorithm = AlgorithmEd25519
+	}
+	return &KeyDetails{
+		Depth:             int(ekey.Depth()),
+		ChildIndex:        int64(index),
+		Hardened:          hardened,
+		ParentFingerprint: fmt.Sprintf("%06x", ekey.ParentFingerprint()),
+		Algorithm:         algorithm,
+		IsPrivate:         ekey.IsPrivate(),
+		Address:           addr.Address,
+	}, nil
+}
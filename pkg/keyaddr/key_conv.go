@@ -13,14 +13,13 @@ package keyaddr
 import (
 	"github.com/ndau/ndaumath/pkg/key"
 	"github.com/ndau/ndaumath/pkg/signature"
-	"github.com/pkg/errors"
 )
 
 // KeyFromExtended constructs a `*Key` from a `*key.ExtendedKey`
 func KeyFromExtended(k *key.ExtendedKey) (*Key, error) {
 	kb, err := k.MarshalText()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadKey, "marshalling")
 	}
 	return &Key{Key: string(kb)}, nil
 }
@@ -29,12 +28,12 @@ func KeyFromExtended(k *key.ExtendedKey) (*Key, error) {
 func KeyFromPublic(k signature.PublicKey) (*Key, error) {
 	text, err := k.MarshalText()
 	if err != nil {
-		return nil, errors.Wrap(err, "marshalling into text")
+		return nil, wrapError(err, BadKey, "marshalling into text")
 	}
 	ekey := new(key.ExtendedKey)
 	err = ekey.UnmarshalText(text)
 	if err != nil {
-		return nil, errors.Wrap(err, "unmarshalling into ekey")
+		return nil, wrapError(err, BadKey, "unmarshalling into ekey")
 	}
 	return KeyFromExtended(ekey)
 }
@@ -43,12 +42,12 @@ func KeyFromPublic(k signature.PublicKey) (*Key, error) {
 func KeyFromPrivate(k signature.PrivateKey) (*Key, error) {
 	text, err := k.MarshalText()
 	if err != nil {
-		return nil, errors.Wrap(err, "marshalling into text")
+		return nil, wrapError(err, BadKey, "marshalling into text")
 	}
 	ekey := new(key.ExtendedKey)
 	err = ekey.UnmarshalText(text)
 	if err != nil {
-		return nil, errors.Wrap(err, "unmarshalling into ekey")
+		return nil, wrapError(err, BadKey, "unmarshalling into ekey")
 	}
 	return KeyFromExtended(ekey)
 }
@@ -57,7 +56,7 @@ func KeyFromPrivate(k signature.PrivateKey) (*Key, error) {
 func (k Key) ToExtended() (*key.ExtendedKey, error) {
 	ekey := new(key.ExtendedKey)
 	err := ekey.UnmarshalText([]byte(k.Key))
-	return ekey, err
+	return ekey, wrapError(err, BadKey, "")
 }
 
 // ToPublicKey constructs a `signature.PublicKey` from a `*Key`
@@ -65,18 +64,18 @@ func (k *Key) ToPublicKey() (signature.PublicKey, error) {
 	out := signature.PublicKey{}
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return out, errors.Wrap(err, "converting to extendedkey")
+		return out, wrapError(err, BadKey, "converting to extendedkey")
 	}
 	pub, err := ekey.Public()
 	if err != nil {
-		return out, errors.Wrap(err, "making public")
+		return out, wrapError(err, Unknown, "making public")
 	}
 	text, err := pub.MarshalText()
 	if err != nil {
-		return out, errors.Wrap(err, "marshalling")
+		return out, wrapError(err, BadKey, "marshalling")
 	}
 	err = out.UnmarshalText(text)
-	return out, err
+	return out, wrapError(err, BadKey, "unmarshalling")
 }
 
 // ToPrivateKey constructs a `signature.PrivateKey` from a `*Key`
@@ -84,15 +83,15 @@ func (k *Key) ToPrivateKey() (signature.PrivateKey, error) {
 	out := signature.PrivateKey{}
 	ekey, err := k.ToExtended()
 	if err != nil {
-		return out, errors.Wrap(err, "converting to extendedkey")
+		return out, wrapError(err, BadKey, "converting to extendedkey")
 	}
 	if !ekey.IsPrivate() {
-		return out, errors.New("cannot convert public key to private key")
+		return out, newError(NotPrivate, "cannot convert public key to private key")
 	}
 	text, err := ekey.MarshalText()
 	if err != nil {
-		return out, errors.Wrap(err, "marshalling")
+		return out, wrapError(err, BadKey, "marshalling")
 	}
 	err = out.UnmarshalText(text)
-	return out, err
+	return out, wrapError(err, BadKey, "unmarshalling")
 }
@@ -0,0 +1,70 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/signature"
+)
+
+// OwnershipPrefix is prepended to every ownership challenge before it is
+// signed, so that a signed challenge can never be mistaken for a signed
+// transaction.
+const OwnershipPrefix = "ndau signed message:\n"
+
+// ownershipMessage returns the bytes actually signed for a challenge
+func ownershipMessage(challenge string) []byte {
+	return []byte(OwnershipPrefix + challenge)
+}
+
+// SignOwnershipChallenge signs a challenge, such as one issued by an exchange
+// to prove ownership of a deposit address, with the given private key.
+//
+// The message signed is OwnershipPrefix followed by the text of the challenge.
+func SignOwnershipChallenge(key, challenge string) (*Signature, error) {
+	pk, err := signature.ParsePrivateKey(key)
+	if err != nil {
+		return nil, wrapError(err, BadKey, "error getting private key")
+	}
+	sig := pk.Sign(ownershipMessage(challenge))
+	return SignatureFrom(sig)
+}
+
+// VerifyOwnershipChallenge verifies that sig is a signature of challenge, as
+// produced by SignOwnershipChallenge, made by the private key corresponding
+// to pubkey, and that pubkey is the key of addr.
+//
+// sig is the text of a signature, as in Signature.Signature. It returns an
+// error if any argument can't be parsed; otherwise it returns whether the
+// ownership is proven.
+func VerifyOwnershipChallenge(addr, challenge, sig, pubkey string) (bool, error) {
+	a, err := address.Validate(addr)
+	if err != nil {
+		return false, wrapError(err, BadAddress, "error validating address")
+	}
+	pk, err := signature.ParsePublicKey(pubkey)
+	if err != nil {
+		return false, wrapError(err, BadKey, "error getting public key")
+	}
+	s, err := Signature{Signature: sig}.ToSignature()
+	if err != nil {
+		return false, wrapError(err, BadSignature, "error getting signature")
+	}
+
+	keyAddr, err := address.Generate(a.Kind(), pk.KeyBytes())
+	if err != nil {
+		return false, wrapError(err, BadAddress, "error generating address of public key")
+	}
+	if keyAddr.String() != a.String() {
+		return false, nil
+	}
+	return pk.Verify(ownershipMessage(challenge), s), nil
+}
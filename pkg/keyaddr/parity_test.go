@@ -0,0 +1,242 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// wasmDir is the WASM wrapper of this package. It builds only for js/wasm,
+// so the parity tests read its source rather than importing it.
+const wasmDir = "../../cmd/keyaddr"
+
+// wasmNames maps the Go API to the names of WASM functions which don't
+// follow from the Go name by lower-casing its first letter.
+var wasmNames = map[string]string{
+	"Key.Details":   "keyDetails",
+	"SignBatchJSON": "signBatch",
+}
+
+// goOnly lists the parts of the Go API which deliberately have no WASM
+// function, and why.
+var goOnly = map[string]string{
+	"CodeOf":                 "JS errors carry their code as a property",
+	"CodeOfMessage":          "JS errors carry their code as a property",
+	"KeyFromExtended":        "takes a Go type",
+	"KeyFromPrivate":         "takes a Go type",
+	"KeyFromPublic":          "takes a Go type",
+	"SignatureFrom":          "takes a Go type",
+	"SignBatch":              "the form of SignBatchJSON for Go callers",
+	"RemainingMnemonicWords": "the form of ValidatePartialMnemonic for gomobile",
+	"Key.ToExtended":         "returns a Go type",
+	"Key.ToPrivateKey":       "returns a Go type",
+	"Key.ToPublicKey":        "returns a Go type",
+}
+
+// wasmOnly lists the WASM functions which are not part of the Go API.
+var wasmOnly = map[string]string{
+	"exit": "stops the WASM module",
+}
+
+// goAPI returns the number of arguments of each function of the Go API,
+// counting the receiver of methods of Key
+func goAPI(t *testing.T) map[string]int {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	api := make(map[string]int)
+	fset := token.NewFileSet()
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || !fn.Name.IsExported() {
+				continue
+			}
+			name := fn.Name.Name
+			args := fn.Type.Params.NumFields()
+			if fn.Recv != nil {
+				recv := fn.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); !ok || ident.Name != "Key" {
+					continue
+				}
+				name = "Key." + name
+				args++
+			}
+			api[name] = args
+		}
+	}
+	return api
+}
+
+// wasmAPI returns the number of arguments of each function registered by
+// the WASM wrapper, not counting the callback, and the parts of the Go API
+// each one calls
+func wasmAPI(t *testing.T) (map[string]int, map[string]map[string]bool) {
+	fset := token.NewFileSet()
+
+	// the functions registered in main
+	main, err := parser.ParseFile(fset, filepath.Join(wasmDir, "main.go"), nil, 0)
+	require.NoError(t, err)
+	registered := make(map[string]string)
+	ast.Inspect(main, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		key, ok := kv.Key.(*ast.BasicLit)
+		call, isCall := kv.Value.(*ast.CallExpr)
+		if !ok || !isCall || len(call.Args) != 1 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "FuncOf" {
+			return true
+		}
+		name, err := strconv.Unquote(key.Value)
+		require.NoError(t, err)
+		handler, ok := call.Args[0].(*ast.Ident)
+		require.True(t, ok, "handler of %s", name)
+		registered[name] = handler.Name
+		return true
+	})
+	require.NotEmpty(t, registered)
+
+	// the arguments each handler expects
+	handlers, err := parser.ParseFile(fset, filepath.Join(wasmDir, "handlers.go"), nil, 0)
+	require.NoError(t, err)
+	expects := make(map[string]int)
+	calls := make(map[string]map[string]bool)
+	for _, decl := range handlers.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		calls[fn.Name.Name] = make(map[string]bool)
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "keyaddr" {
+				calls[fn.Name.Name][sel.Sel.Name] = true
+			} else {
+				calls[fn.Name.Name]["Key."+sel.Sel.Name] = true
+			}
+			return true
+		})
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 3 {
+				return true
+			}
+			if ident, ok := call.Fun.(*ast.Ident); !ok || ident.Name != "handleArgs" {
+				return true
+			}
+			count, ok := call.Args[1].(*ast.BasicLit)
+			require.True(t, ok, "argument count of %s", fn.Name.Name)
+			source, ok := call.Args[2].(*ast.BasicLit)
+			require.True(t, ok, "source of %s", fn.Name.Name)
+			name, err := strconv.Unquote(source.Value)
+			require.NoError(t, err)
+			require.Equal(t, fn.Name.Name, name, "handleArgs source")
+			expects[fn.Name.Name], err = strconv.Atoi(count.Value)
+			require.NoError(t, err)
+			return false
+		})
+	}
+
+	api := make(map[string]int)
+	for name, handler := range registered {
+		require.Equal(t, name, handler, "registered name of handler")
+		count, ok := expects[handler]
+		require.True(t, ok, "handler %s doesn't call handleArgs", handler)
+		api[name] = count
+	}
+	return api, calls
+}
+
+func wasmName(goName string) string {
+	if name, ok := wasmNames[goName]; ok {
+		return name
+	}
+	goName = strings.TrimPrefix(goName, "Key.")
+	return strings.ToLower(goName[:1]) + goName[1:]
+}
+
+func TestWASMParity(t *testing.T) {
+	goFuncs := goAPI(t)
+	wasmFuncs, calls := wasmAPI(t)
+
+	// the loop below consumes wasmFuncs, so goOnly is checked against the
+	// complete set. A WASM function claimed by another Go function, as
+	// signBatch is by SignBatchJSON, isn't that of a goOnly function.
+	allWASM := make(map[string]bool, len(wasmFuncs))
+	for name := range wasmFuncs {
+		allWASM[name] = true
+	}
+	claimed := make(map[string]bool, len(wasmNames))
+	for goName, name := range wasmNames {
+		claimed[name] = goOnly[goName] == ""
+	}
+	for name := range goOnly {
+		wname := wasmName(name)
+		require.False(t, allWASM[wname] && !claimed[wname], "%s is listed in goOnly but has WASM function %s", name, wname)
+	}
+
+	var missing []string
+	for name, args := range goFuncs {
+		if _, ok := goOnly[name]; ok {
+			continue
+		}
+		wname := wasmName(name)
+		wargs, ok := wasmFuncs[wname]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		require.Equal(t, args, wargs, "arguments of %s and %s", name, wname)
+		// the handler delegates to the Go function, so the two agree on
+		// more than their arguments
+		require.True(t, calls[wname][name], "%s doesn't call %s", wname, name)
+		delete(wasmFuncs, wname)
+	}
+	sort.Strings(missing)
+	require.Empty(t, missing, "Go functions without WASM functions")
+
+	for name := range wasmOnly {
+		delete(wasmFuncs, name)
+	}
+	require.Empty(t, wasmFuncs, "WASM functions without Go functions")
+
+	for name := range goOnly {
+		_, ok := goFuncs[name]
+		require.True(t, ok, "goOnly lists %s, which doesn't exist", name)
+	}
+}
@@ -0,0 +1,98 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/key"
+	"github.com/ndau/ndaumath/pkg/signature"
+)
+
+// Recoverable signatures let light clients learn the signer of a message
+// without the public key being transmitted alongside it. Messages are
+// hashed with SHA-256 before signing, exactly as for ordinary secp256k1
+// signatures.
+
+// SignRecoverable uses the given key to sign a message, which must be the
+// standard base64 encoding of the bytes of the message, such that the
+// signer's public key can be recovered from the signature.
+//
+// It returns the standard base64 encoding of the signature. The key must be
+// a private secp256k1 key.
+func (k *Key) SignRecoverable(msgstr string) (string, error) {
+	msg, err := base64.StdEncoding.DecodeString(msgstr)
+	if err != nil {
+		return "", wrapError(err, BadEncoding, "error decoding base64 string")
+	}
+	ekey, err := k.ToExtended()
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(msg)
+	sig, err := ekey.SignRecoverable(hash[:])
+	if err != nil {
+		return "", wrapError(err, BadKey, "error signing")
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
+// RecoverPublic returns the public key which signed a message with
+// SignRecoverable. Both message and signature are standard base64 strings.
+//
+// The key is returned in the serialization of the signature package: a
+// signature carries no chain code, so the key can't be extended. Any
+// well-formed signature yields some public key: the caller must check that it
+// is the one expected, for example by comparing its address.
+func RecoverPublic(msgstr, sig string) (string, error) {
+	pub, err := recoverPublic(msgstr, sig)
+	if err != nil {
+		return "", err
+	}
+	text, err := pub.MarshalString()
+	if err != nil {
+		return "", wrapError(err, BadKey, "error marshalling public key")
+	}
+	return text, nil
+}
+
+// RecoverAddress returns the ndau address of the key which signed a message
+// with SignRecoverable, as RecoverPublic does for its public key.
+func RecoverAddress(msgstr, sig string) (*Address, error) {
+	pub, err := recoverPublic(msgstr, sig)
+	if err != nil {
+		return nil, err
+	}
+	a, err := address.Generate(address.KindUser, pub.KeyBytes())
+	if err != nil {
+		return nil, wrapError(err, BadAddress, "")
+	}
+	return &Address{a.String()}, nil
+}
+
+func recoverPublic(msgstr, sig string) (*signature.PublicKey, error) {
+	msg, err := base64.StdEncoding.DecodeString(msgstr)
+	if err != nil {
+		return nil, wrapError(err, BadEncoding, "error decoding base64 message")
+	}
+	sigB, err := base64.StdEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, wrapError(err, BadEncoding, "error decoding base64 signature")
+	}
+	hash := sha256.Sum256(msg)
+	pub, err := key.RecoverPublic(hash[:], sigB)
+	if err != nil {
+		return nil, wrapError(err, BadSignature, "error recovering public key")
+	}
+	return pub, nil
+}
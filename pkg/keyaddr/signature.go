@@ -15,21 +15,26 @@ import "github.com/ndau/ndaumath/pkg/signature"
 // Signature is the result of signing a block of data with a key.
 type Signature struct {
 	Signature string
+	// Algorithm names the algorithm which produced the signature
+	Algorithm string
 }
 
 // SignatureFrom converts a `signature.Signature` into a `*Signature`
 func SignatureFrom(sig signature.Signature) (*Signature, error) {
 	sigB, err := sig.MarshalText()
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err, BadSignature, "")
 	}
 
-	return &Signature{string(sigB)}, nil
+	return &Signature{
+		Signature: string(sigB),
+		Algorithm: signature.NameOf(sig.Algorithm()),
+	}, nil
 }
 
 // ToSignature converts a `Signature` into a `signature.Signature`
 func (s Signature) ToSignature() (signature.Signature, error) {
 	sig := signature.Signature{}
 	err := sig.UnmarshalText([]byte(s.Signature))
-	return sig, err
+	return sig, wrapError(err, BadSignature, "")
 }
@@ -0,0 +1,182 @@
+package keyaddr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/key"
+)
+
+// WalletVersion is the version of the wallet interchange format written by
+// ExportWallet.
+const WalletVersion = 1
+
+// AccountSpec describes an account to be exported by ExportWallet
+type AccountSpec struct {
+	// Name is an optional human-readable label for the account
+	Name string
+	// Path is the derivation path of the account from the root key,
+	// i.e. "/44'/20036'/100/1"
+	Path string
+	// IncludePrivate causes the account's private key to be exported.
+	//
+	// Anyone with the exported document can then spend from the account,
+	// so this should only be set when moving a wallet between devices
+	// which both hold the mnemonic anyway.
+	IncludePrivate bool
+}
+
+// Wallet is the wallet interchange document
+type Wallet struct {
+	Version  int             `json:"version"`
+	Accounts []WalletAccount `json:"accounts"`
+}
+
+// WalletAccount is a single derived account within a Wallet
+type WalletAccount struct {
+	Name    string `json:"name,omitempty"`
+	Path    string `json:"path"`
+	Public  string `json:"public"`
+	Address string `json:"address"`
+	Private string `json:"private,omitempty"`
+}
+
+// ExportWallet derives each of the listed accounts from the root private
+// key, and returns a JSON document describing them.
+//
+// Each account in the document has its path, public key, and address.
+// Private keys are only included for accounts whose IncludePrivate flag is
+// set. The root key itself is never included.
+func ExportWallet(rootKey string, accounts []AccountSpec) (string, error) {
+	root, err := FromString(rootKey)
+	if err != nil {
+		return "", wrapError(err, BadKey, "parsing root key")
+	}
+	private, err := root.IsPrivate()
+	if err != nil {
+		return "", err
+	}
+	if !private {
+		return "", newError(NotPrivate, "root key must be a private key")
+	}
+
+	seen := make(map[string]struct{}, len(accounts))
+	wallet := Wallet{
+		Version:  WalletVersion,
+		Accounts: make([]WalletAccount, 0, len(accounts)),
+	}
+	for _, spec := range accounts {
+		path, err := key.ParsePath(spec.Path)
+		if err != nil {
+			return "", wrapError(err, BadPath, fmt.Sprintf("account %q: parsing path", spec.Path))
+		}
+		if _, dup := seen[path.String()]; dup {
+			return "", newError(BadArgument, "account %q: duplicate path", spec.Path)
+		}
+		seen[path.String()] = struct{}{}
+
+		derived, err := DeriveFrom(root.Key, "/", path.String())
+		if err != nil {
+			return "", wrapError(err, BadPath, fmt.Sprintf("account %s: deriving key", path))
+		}
+		public, err := derived.ToPublic()
+		if err != nil {
+			return "", wrapError(err, Unknown, fmt.Sprintf("account %s: getting public key", path))
+		}
+		addr, err := public.NdauAddress()
+		if err != nil {
+			return "", wrapError(err, BadAddress, fmt.Sprintf("account %s: generating address", path))
+		}
+
+		account := WalletAccount{
+			Name:    spec.Name,
+			Path:    path.String(),
+			Public:  public.Key,
+			Address: addr.Address,
+		}
+		if spec.IncludePrivate {
+			account.Private = derived.Key
+		}
+		wallet.Accounts = append(wallet.Accounts, account)
+	}
+
+	doc, err := json.MarshalIndent(wallet, "", "  ")
+	if err != nil {
+		return "", wrapError(err, Unknown, "encoding wallet")
+	}
+	return string(doc), nil
+}
+
+// ImportWallet parses a JSON document produced by ExportWallet.
+//
+// Every account is checked for consistency: its address must be that of its
+// public key, and its private key, if present, must match its public key.
+func ImportWallet(doc string) (*Wallet, error) {
+	wallet := new(Wallet)
+	err := json.Unmarshal([]byte(doc), wallet)
+	if err != nil {
+		return nil, wrapError(err, BadWallet, "decoding wallet")
+	}
+	if wallet.Version != WalletVersion {
+		return nil, newError(BadWallet, "unsupported wallet version %d", wallet.Version)
+	}
+
+	for idx, account := range wallet.Accounts {
+		err = account.check()
+		if err != nil {
+			return nil, wrapError(err, BadWallet, fmt.Sprintf("account %d (%s)", idx, account.Path))
+		}
+	}
+	return wallet, nil
+}
+
+// check that the parts of an account agree
+func (a WalletAccount) check() error {
+	if _, err := key.ParsePath(a.Path); err != nil {
+		return wrapError(err, BadWallet, "parsing path")
+	}
+
+	public, err := FromString(a.Public)
+	if err != nil {
+		return wrapError(err, BadWallet, "parsing public key")
+	}
+	isPrivate, err := public.IsPrivate()
+	if err != nil {
+		return err
+	}
+	if isPrivate {
+		return newError(BadWallet, "public key field holds a private key")
+	}
+	addr, err := public.NdauAddress()
+	if err != nil {
+		return wrapError(err, BadWallet, "generating address")
+	}
+	if addr.Address != a.Address {
+		return newError(BadWallet, "address %s does not match public key", a.Address)
+	}
+
+	if a.Private != "" {
+		private, err := FromString(a.Private)
+		if err != nil {
+			return wrapError(err, BadWallet, "parsing private key")
+		}
+		derived, err := private.ToPublic()
+		if err != nil {
+			return wrapError(err, BadWallet, "getting public key of private key")
+		}
+		if derived.Key != public.Key {
+			return newError(BadWallet, "private key does not match public key")
+		}
+	}
+	return nil
+}
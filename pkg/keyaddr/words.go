@@ -13,24 +13,38 @@ package keyaddr
 import (
 	"encoding/base64"
 	"strings"
+	"unicode"
 
 	"github.com/ndau/ndaumath/pkg/words"
 )
 
 // WordsFromBytes takes an array of bytes and converts it to a space-separated list of
-// words that act as a mnemonic. A 16-byte input array will generate a list of 12 words.
+// words that act as a mnemonic. A 16-byte input array will generate a list of 12 words,
+// and a 32-byte input array a list of 24 words.
 func WordsFromBytes(lang string, data string) (string, error) {
 	b, err := base64.StdEncoding.DecodeString(data)
 	if err != nil {
-		return "", err
+		return "", wrapError(err, BadEncoding, "")
 	}
 	sa, err := words.FromBytes(lang, b)
 	if err != nil {
-		return "", err
+		return "", wrapError(err, BadArgument, "")
 	}
 	return strings.Join(sa, " "), nil
 }
 
+// NewSeed generates random bytes of the given strength, in bits, encoded as a
+// base64 string. The strength must be 128, 160, 192, 224, or 256; the seed then
+// converts with WordsFromBytes to a recovery phrase of 12, 15, 18, 21, or 24
+// words respectively, and can be passed directly to NewKey or NewEdKey.
+func NewSeed(strength int) (string, error) {
+	b, err := words.GenerateEntropy(words.Strength(strength))
+	if err != nil {
+		return "", wrapError(err, BadSeed, "")
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
 // WordsToBytes takes a space-separated list of words and generates the set of bytes
 // from which it was generated (or an error). The bytes are encoded as a base64 string
 // using standard base64 encoding, as defined in RFC 4648.
@@ -38,7 +52,7 @@ func WordsToBytes(lang string, w string) (string, error) {
 	wordlist := strings.Split(w, " ")
 	b, err := words.ToBytes(lang, wordlist)
 	if err != nil {
-		return "", err
+		return "", wrapError(err, BadMnemonic, "")
 	}
 	return base64.StdEncoding.EncodeToString(b), nil
 }
@@ -49,3 +63,50 @@ func WordsToBytes(lang string, w string) (string, error) {
 func WordsFromPrefix(lang string, prefix string, max int) string {
 	return words.FromPrefix(lang, prefix, max)
 }
+
+// ValidatePartialMnemonic checks a mnemonic phrase as it is entered, so that
+// a wallet can flag a mistaken word as soon as it is entered, rather than
+// when the checksum fails at the end.
+//
+// Every word must be in the wordlist. If wordsSoFar doesn't end with a space,
+// its last word may be incomplete: it is accepted if it begins a word of the
+// wordlist, and counts towards expectedRemaining. The checksum can only be
+// checked once the words are as many as those of a complete mnemonic; if it
+// fails there, the words may yet begin a longer one.
+//
+// ok reports whether the words can begin a valid mnemonic; if not, err says
+// why. expectedRemaining is the least number of words still to be entered to
+// complete a valid mnemonic, and is 0 once the mnemonic is complete.
+func ValidatePartialMnemonic(lang string, wordsSoFar string) (ok bool, expectedRemaining int, err error) {
+	wordlist := strings.Fields(wordsSoFar)
+	// an unfinished last word need only begin a word, and is still to be
+	// entered
+	var unfinished string
+	if n := len(wordlist); n > 0 && strings.TrimRightFunc(wordsSoFar, unicode.IsSpace) == wordsSoFar {
+		// the wordlist is sorted, so a word is the first word it begins
+		first := words.FromPrefix(lang, wordlist[n-1], 1)
+		if first != "" && first != wordlist[n-1] {
+			unfinished = first
+			wordlist = wordlist[:n-1]
+		}
+	}
+	expectedRemaining, err = words.ValidatePartial(lang, wordlist)
+	if err == nil && unfinished != "" && expectedRemaining == 0 {
+		// the words are a complete mnemonic without the unfinished one, so
+		// it can only begin a longer mnemonic
+		expectedRemaining, err = words.ValidatePartial(lang, append(wordlist, unfinished))
+		expectedRemaining++
+	}
+	if err != nil {
+		return false, 0, wrapError(err, BadMnemonic, "")
+	}
+	return true, expectedRemaining, nil
+}
+
+// RemainingMnemonicWords is ValidatePartialMnemonic for callers, such as
+// gomobile, which can't return three values: it returns expectedRemaining,
+// and an error if the words can't begin a valid mnemonic.
+func RemainingMnemonicWords(lang string, wordsSoFar string) (int, error) {
+	_, remaining, err := ValidatePartialMnemonic(lang, wordsSoFar)
+	return remaining, err
+}
@@ -12,12 +12,54 @@ package ndauerr
 
 import "errors"
 
+// These errors are shared by the math packages, so callers can tell failure
+// modes apart with errors.Is rather than by matching strings.
+//
+// Some errors are kinds of others: errors.Is reports ErrUnderflow and ErrMath
+// as ErrOverflow, too, as it does errors made with Specialize.
+
 // ErrOverflow is returned when a math operation would overflow a 64-bit value
 var ErrOverflow = errors.New("overflow error")
 
+// ErrUnderflow is returned when the result of a math operation would be less
+// than the least value of its type: less than 0 for unsigned values, or than
+// math.MinInt64 for signed ones. It is a kind of ErrOverflow.
+var ErrUnderflow = Specialize(ErrOverflow, "underflow error")
+
 // ErrDivideByZero is returned when a math operation would cause division by zero
 var ErrDivideByZero = errors.New("divide by zero error")
 
 // ErrMath is returned when the result of a decimal math operation could not be converted
-// back to a uint64
-var ErrMath = errors.New("overflow error")
+// back to a uint64. It is a kind of ErrOverflow.
+var ErrMath = Specialize(ErrOverflow, "overflow error")
+
+// ErrLengthMismatch is returned when a math operation over slices is given
+// slices of differing lengths
+var ErrLengthMismatch = errors.New("slice length mismatch")
+
+// ErrEmpty is returned when a math operation which needs at least one value
+// is given an empty slice
+var ErrEmpty = errors.New("empty slice")
+
+// kindError is an error which errors.Is also reports as its parent
+type kindError struct {
+	msg    string
+	parent error
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.parent
+}
+
+// Specialize returns a new error with the given message, which is a kind of
+// parent: errors.Is reports it both as itself and as parent.
+//
+// It is for packages which define their own errors for particular failures,
+// such as an overflowing EAI factor, which are still overflows.
+func Specialize(parent error, msg string) error {
+	return &kindError{msg: msg, parent: parent}
+}
@@ -0,0 +1,51 @@
+package ndauerr
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestKinds(t *testing.T) {
+	special := Specialize(ErrUnderflow, "special underflow")
+	tests := []struct {
+		name  string
+		err   error
+		is    []error
+		isNot []error
+	}{
+		{"overflow", ErrOverflow, []error{ErrOverflow}, []error{ErrUnderflow, ErrMath, ErrDivideByZero}},
+		{"underflow", ErrUnderflow, []error{ErrUnderflow, ErrOverflow}, []error{ErrMath, ErrDivideByZero}},
+		{"math", ErrMath, []error{ErrMath, ErrOverflow}, []error{ErrUnderflow, ErrDivideByZero}},
+		{"divide by zero", ErrDivideByZero, []error{ErrDivideByZero}, []error{ErrOverflow}},
+		{"specialized", special, []error{special, ErrUnderflow, ErrOverflow}, []error{ErrMath}},
+		{"wrapped", fmt.Errorf("context: %w", ErrUnderflow), []error{ErrUnderflow, ErrOverflow}, []error{ErrMath}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, target := range tt.is {
+				if !errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%q, %q) = false, want true", tt.err, target)
+				}
+			}
+			for _, target := range tt.isNot {
+				if errors.Is(tt.err, target) {
+					t.Errorf("errors.Is(%q, %q) = true, want false", tt.err, target)
+				}
+			}
+		})
+	}
+	if special.Error() != "special underflow" {
+		t.Errorf("Specialize() message = %q", special.Error())
+	}
+}
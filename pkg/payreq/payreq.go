@@ -0,0 +1,202 @@
+package payreq
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+	"encoding/binary"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/ndau/ndaumath/pkg/signature"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// Prefix begins the text of every payment request, distinguishing it from
+// addresses, keys, and signatures.
+const Prefix = "ndpay"
+
+// Version is the version of the payment request encoding written by this
+// package. Requests of any other version are rejected.
+const Version byte = 1
+
+// MaxMemoLength is the maximum length of a memo, in bytes
+const MaxMemoLength = 100
+
+// addrBytes is the length of the b32-decoded form of an address
+const addrBytes = address.AddrLength * 5 / 8
+
+var (
+	// ErrNegativeAmount is returned when a request's amount is negative
+	ErrNegativeAmount = errors.New("payment request amount must not be negative")
+
+	// ErrMemoTooLong is returned when a request's memo exceeds MaxMemoLength
+	ErrMemoTooLong = errors.New("payment request memo too long")
+
+	// ErrMemoInvalid is returned when a request's memo is not valid UTF-8
+	ErrMemoInvalid = errors.New("payment request memo must be valid UTF-8")
+
+	// ErrChecksum is returned when a payment request fails its checksum
+	ErrChecksum = errors.New("payment request checksum failure")
+
+	// ErrMalformed is returned when a payment request passes its checksum
+	// but cannot be decoded
+	ErrMalformed = errors.New("malformed payment request")
+)
+
+// A Request asks for a payment of Amount to Address, with an optional memo.
+//
+// An Amount of 0 leaves the amount to the payer.
+type Request struct {
+	Address address.Address
+	Amount  types.Ndau
+	Memo    string
+}
+
+var _ encoding.TextMarshaler = (*Request)(nil)
+var _ encoding.TextUnmarshaler = (*Request)(nil)
+
+// New creates a payment request, checking its fields
+func New(addr address.Address, amount types.Ndau, memo string) (Request, error) {
+	r := Request{Address: addr, Amount: amount, Memo: memo}
+	return r, r.validate()
+}
+
+func (r Request) validate() error {
+	if err := r.Address.Revalidate(); err != nil {
+		return errors.Wrap(err, "payment request address")
+	}
+	if r.Amount < 0 {
+		return ErrNegativeAmount
+	}
+	if len(r.Memo) > MaxMemoLength {
+		return errors.Wrapf(ErrMemoTooLong, "%d bytes; max %d", len(r.Memo), MaxMemoLength)
+	}
+	if !utf8.ValidString(r.Memo) {
+		return ErrMemoInvalid
+	}
+	return nil
+}
+
+// Marshal serializes the request to its compact binary form, without
+// checksum.
+//
+// The binary form is the version byte, the decoded address, the amount
+// as a uvarint, and the bytes of the memo, which run to the end.
+func (r Request) Marshal() ([]byte, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	addr, err := b32.Decode(r.Address.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding address")
+	}
+	out := make([]byte, 0, 1+len(addr)+binary.MaxVarintLen64+len(r.Memo))
+	out = append(out, Version)
+	out = append(out, addr...)
+	var amt [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(amt[:], uint64(r.Amount))
+	out = append(out, amt[:n]...)
+	out = append(out, r.Memo...)
+	return out, nil
+}
+
+// Unmarshal deserializes a request from its compact binary form
+func (r *Request) Unmarshal(data []byte) error {
+	if len(data) < 1+addrBytes+1 {
+		return errors.Wrap(ErrMalformed, "too short")
+	}
+	if data[0] != Version {
+		return errors.Wrapf(ErrMalformed, "unknown version %d", data[0])
+	}
+	addr, err := address.Validate(b32.Encode(data[1 : 1+addrBytes]))
+	if err != nil {
+		return errors.Wrap(err, "payment request address")
+	}
+	data = data[1+addrBytes:]
+	amt, n := binary.Uvarint(data)
+	if n <= 0 {
+		return errors.Wrap(ErrMalformed, "bad amount")
+	}
+	// every request has exactly one encoding, so the amount must have been
+	// written in as few bytes as possible
+	var minimal [binary.MaxVarintLen64]byte
+	if binary.PutUvarint(minimal[:], amt) != n {
+		return errors.Wrap(ErrMalformed, "amount not minimally encoded")
+	}
+	req := Request{
+		Address: addr,
+		Amount:  types.Ndau(amt),
+		Memo:    string(data[n:]),
+	}
+	if err := req.validate(); err != nil {
+		return err
+	}
+	*r = req
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+//
+// The text is Prefix followed by the b32 encoding of the checksummed binary
+// form. It is never padded.
+func (r Request) MarshalText() ([]byte, error) {
+	data, err := r.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(Prefix + b32.Encode(signature.AddChecksum(data))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+//
+// Case is insignificant, so this also accepts the QR form.
+func (r *Request) UnmarshalText(text []byte) error {
+	s := strings.ToLower(string(text))
+	if !strings.HasPrefix(s, Prefix) {
+		return errors.Wrapf(ErrMalformed, "must start with %q", Prefix)
+	}
+	data, err := b32.Decode(s[len(Prefix):])
+	if err != nil {
+		return errors.Wrap(err, "decoding payment request")
+	}
+	data, ok := signature.CheckChecksum(data)
+	if !ok {
+		return ErrChecksum
+	}
+	return r.Unmarshal(data)
+}
+
+// Encode returns the text form of the request
+func (r Request) Encode() (string, error) {
+	text, err := r.MarshalText()
+	return string(text), err
+}
+
+// QR returns the text form of the request in upper case.
+//
+// Every character of the upper case form is in the alphanumeric character
+// set of QR codes, which encodes far more compactly than byte mode.
+// Parse accepts it as it is.
+func (r Request) QR() (string, error) {
+	s, err := r.Encode()
+	return strings.ToUpper(s), err
+}
+
+// Parse parses the text form of a payment request, in either case
+func Parse(s string) (Request, error) {
+	var r Request
+	err := r.UnmarshalText([]byte(s))
+	return r, err
+}
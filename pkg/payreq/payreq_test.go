@@ -0,0 +1,166 @@
+package payreq
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/address"
+	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func testAddress(t *testing.T) address.Address {
+	addr, err := address.Generate(address.KindUser, []byte("payment request test key"))
+	require.NoError(t, err)
+	return addr
+}
+
+func TestRoundtrip(t *testing.T) {
+	addr := testAddress(t)
+	cases := []struct {
+		name   string
+		amount types.Ndau
+		memo   string
+	}{
+		{"no amount", 0, ""},
+		{"napu", 1, ""},
+		{"one ndau", constants.NapuPerNdau, ""},
+		{"max", math.MaxInt64, ""},
+		{"memo", 12 * constants.NapuPerNdau, "invoice 1234"},
+		{"unicode memo", 5, "café ☕"},
+		{"long memo", 5, strings.Repeat("m", MaxMemoLength)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := New(addr, tc.amount, tc.memo)
+			require.NoError(t, err)
+			s, err := r.Encode()
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(s, Prefix))
+			require.NotContains(t, s, "=")
+
+			got, err := Parse(s)
+			require.NoError(t, err)
+			require.Equal(t, r, got)
+
+			qr, err := r.QR()
+			require.NoError(t, err)
+			require.Equal(t, strings.ToUpper(s), qr)
+			got, err = Parse(qr)
+			require.NoError(t, err)
+			require.Equal(t, r, got)
+		})
+	}
+}
+
+func TestQRAlphanumeric(t *testing.T) {
+	// the QR alphanumeric character set
+	const alnum = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+	r, err := New(testAddress(t), 1234567, "memo")
+	require.NoError(t, err)
+	qr, err := r.QR()
+	require.NoError(t, err)
+	for _, c := range qr {
+		require.Contains(t, alnum, string(c))
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	addr := testAddress(t)
+
+	_, err := New(addr, -1, "")
+	require.Equal(t, ErrNegativeAmount, errors.Cause(err))
+
+	_, err = New(addr, 1, strings.Repeat("m", MaxMemoLength+1))
+	require.Equal(t, ErrMemoTooLong, errors.Cause(err))
+
+	_, err = New(addr, 1, "\xff")
+	require.Equal(t, ErrMemoInvalid, errors.Cause(err))
+
+	_, err = New(address.Address{}, 1, "")
+	require.Error(t, err)
+
+	_, err = Request{Address: addr, Amount: -1}.Encode()
+	require.Equal(t, ErrNegativeAmount, errors.Cause(err))
+}
+
+func TestParseInvalid(t *testing.T) {
+	r, err := New(testAddress(t), 1000, "hello")
+	require.NoError(t, err)
+	s, err := r.Encode()
+	require.NoError(t, err)
+
+	_, err = Parse("")
+	require.Equal(t, ErrMalformed, errors.Cause(err))
+
+	_, err = Parse(s[len(Prefix):])
+	require.Equal(t, ErrMalformed, errors.Cause(err))
+
+	_, err = Parse(r.Address.String())
+	require.Error(t, err)
+
+	// change a single character of the body
+	body := []byte(s)
+	i := len(Prefix) + 10
+	if body[i] == 'a' {
+		body[i] = 'b'
+	} else {
+		body[i] = 'a'
+	}
+	_, err = Parse(string(body))
+	require.Equal(t, ErrChecksum, errors.Cause(err))
+
+	_, err = Parse(s[:len(s)-8])
+	require.Error(t, err)
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	r, err := New(testAddress(t), 300, "memo")
+	require.NoError(t, err)
+	data, err := r.Marshal()
+	require.NoError(t, err)
+
+	var got Request
+	require.NoError(t, got.Unmarshal(data))
+	require.Equal(t, r, got)
+
+	bad := append([]byte{}, data...)
+	bad[0] = Version + 1
+	require.Equal(t, ErrMalformed, errors.Cause(got.Unmarshal(bad)))
+
+	require.Equal(t, ErrMalformed, errors.Cause(got.Unmarshal(data[:1+addrBytes])))
+
+	// an address with a bad checksum
+	bad = append([]byte{}, data...)
+	bad[5] ^= 1
+	require.Error(t, got.Unmarshal(bad))
+
+	// 300 takes two bytes; write it in three
+	bad = append([]byte{}, data[:1+addrBytes]...)
+	bad = append(bad, 0xac, 0x82, 0x00)
+	require.Equal(t, ErrMalformed, errors.Cause(got.Unmarshal(bad)))
+
+	// an amount which overflows types.Ndau
+	bad = append([]byte{}, data[:1+addrBytes]...)
+	bad = append(bad, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01)
+	require.Equal(t, ErrNegativeAmount, errors.Cause(got.Unmarshal(bad)))
+
+	// the address is stored in its decoded form
+	ab, err := b32.Decode(r.Address.String())
+	require.NoError(t, err)
+	require.Equal(t, ab, data[1:1+addrBytes])
+}
@@ -0,0 +1,125 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/signed"
+	"github.com/pkg/errors"
+)
+
+// ExchangeRateDenominator is the denominator of an ExchangeRate: rates are
+// expressed in parts per billion.
+const ExchangeRateDenominator = 1000000000
+
+// exchangeRateDigits is the number of decimal digits of ExchangeRateDenominator
+const exchangeRateDigits = 9
+
+// nanocentDigits is the number of decimal digits of Dollar
+const nanocentDigits = 11
+
+// An ExchangeRate is the number of units of a foreign currency worth one
+// USD, in parts per ExchangeRateDenominator.
+//
+// For example, if one USD buys 0.92 EUR, the USD to EUR rate is 920000000.
+type ExchangeRate int64
+
+// ParseExchangeRate parses a decimal number of foreign units per USD, such
+// as "0.92" or "149.5", with at most 9 digits after the decimal point
+func ParseExchangeRate(s string) (ExchangeRate, error) {
+	s = strings.TrimSpace(s)
+	units, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		units, frac = s[:i], s[i+1:]
+		if frac == "" {
+			return 0, fmt.Errorf("'%s' doesn't look like an exchange rate", s)
+		}
+	}
+	if units == "" || len(frac) > exchangeRateDigits || strings.ContainsAny(units+frac, "+-") {
+		return 0, fmt.Errorf("'%s' doesn't look like an exchange rate", s)
+	}
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "parsing exchange rate units: "+units)
+	}
+	f := int64(0)
+	if frac != "" {
+		f, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return 0, errors.Wrap(err, "parsing exchange rate fraction: "+frac)
+		}
+		for i := len(frac); i < exchangeRateDigits; i++ {
+			f *= 10
+		}
+	}
+	if u > (1<<63-1-f)/ExchangeRateDenominator {
+		return 0, fmt.Errorf("exchange rate '%s' is too large", s)
+	}
+	return ExchangeRate(u*ExchangeRateDenominator + f), nil
+}
+
+// String formats the rate as a decimal number of foreign units per USD
+func (r ExchangeRate) String() string {
+	sign := ""
+	v := int64(r)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	s := fmt.Sprintf("%s%d", sign, v/ExchangeRateDenominator)
+	if frac := v % ExchangeRateDenominator; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", frac), "0")
+	}
+	return s
+}
+
+// A ForeignAmount is one billionth of one hundredth of one unit of a
+// foreign currency.
+//
+// It has the same scale as a Nanocent, so that an amount converted at a
+// rate of exactly 1 is unchanged. Which currency it is denominated in is the
+// caller's to track.
+type ForeignAmount int64
+
+// Convert a USD amount into a foreign currency at the given rate
+//
+// The result is rounded half-even to the nearest ForeignAmount, so every
+// caller converts the same amount at the same rate to the same result. It is
+// an error if the rate is not positive, or if the result overflows.
+func Convert(amount Nanocent, rate ExchangeRate) (ForeignAmount, error) {
+	if rate <= 0 {
+		return 0, fmt.Errorf("exchange rate must be positive; got %s", rate)
+	}
+	converted, err := signed.MulDivRound(int64(amount), int64(rate), ExchangeRateDenominator, signed.HalfEven)
+	if err != nil {
+		return 0, errors.Wrapf(err, "converting %d nanocents at %s", amount, rate)
+	}
+	return ForeignAmount(converted), nil
+}
+
+// MinorUnits rounds the amount half-even to a whole number of units with the
+// given number of decimal digits: 0 for whole units, as for JPY, or 2 for
+// hundredths, as for EUR.
+//
+// This is the amount to display. At most 11 digits are supported.
+func (f ForeignAmount) MinorUnits(digits uint) (int64, error) {
+	if digits > nanocentDigits {
+		return 0, fmt.Errorf("at most %d decimal digits are supported; got %d", nanocentDigits, digits)
+	}
+	divisor := int64(1)
+	for i := digits; i < nanocentDigits; i++ {
+		divisor *= 10
+	}
+	return signed.MulDivRound(int64(f), 1, divisor, signed.HalfEven)
+}
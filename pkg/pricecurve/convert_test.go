@@ -0,0 +1,142 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	gomath "math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExchangeRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ExchangeRate
+		wantErr bool
+	}{
+		{"1", ExchangeRateDenominator, false},
+		{"0.92", 920000000, false},
+		{" 149.5 ", 149500000000, false},
+		{"0.000000001", 1, false},
+		{"25000", 25000 * ExchangeRateDenominator, false},
+		{"0", 0, false},
+		{"0.0000000001", 0, true},
+		{"", 0, true},
+		{".5", 0, true},
+		{"1.", 0, true},
+		{"-1", 0, true},
+		{"+1", 0, true},
+		{"1.-5", 0, true},
+		{"one", 0, true},
+		{"9223372037", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseExchangeRate(tt.in)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExchangeRateString(t *testing.T) {
+	for _, s := range []string{"0", "1", "0.92", "149.5", "0.000000001", "25000"} {
+		rate, err := ParseExchangeRate(s)
+		require.NoError(t, err)
+		require.Equal(t, s, rate.String())
+	}
+	require.Equal(t, "-1.25", ExchangeRate(-1250000000).String())
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Nanocent
+		rate   ExchangeRate
+		want   ForeignAmount
+	}{
+		{"identity", 12345 * Dollar, ExchangeRateDenominator, 12345 * Dollar},
+		{"zero", 0, 920000000, 0},
+		{"eur", 17 * Dollar, 920000000, 1564 * Dollar / 100},
+		{"jpy", Dollar, 149500000000, 149*Dollar + Dollar/2},
+		{"negative", -17 * Dollar, 920000000, -1564 * Dollar / 100},
+		// 5 * 0.5 = 2.5 and 7 * 0.5 = 3.5: ties go to the even neighbour
+		{"tie down", 5, ExchangeRateDenominator / 2, 2},
+		{"tie up", 7, ExchangeRateDenominator / 2, 4},
+		{"negative tie", -5, ExchangeRateDenominator / 2, -2},
+		{"below half", 1, ExchangeRateDenominator/2 - 1, 0},
+		{"above half", 1, ExchangeRateDenominator/2 + 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.amount, tt.rate)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	_, err := Convert(Dollar, 0)
+	require.Error(t, err)
+	_, err = Convert(Dollar, -ExchangeRateDenominator)
+	require.Error(t, err)
+	_, err = Convert(gomath.MaxInt64, 2*ExchangeRateDenominator)
+	require.Error(t, err)
+}
+
+func TestConvertTargetPrice(t *testing.T) {
+	// converting the canonical price is exact to within half a unit
+	price, err := PriceAtUnit(0)
+	require.NoError(t, err)
+	rate, err := ParseExchangeRate("0.923456789")
+	require.NoError(t, err)
+	got, err := Convert(price, rate)
+	require.NoError(t, err)
+	want := float64(price) * 0.923456789
+	require.InDelta(t, want, float64(got), 0.5+want*1e-15)
+}
+
+func TestMinorUnits(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  ForeignAmount
+		digits  uint
+		want    int64
+		wantErr bool
+	}{
+		{"cents", 1564 * Dollar / 100, 2, 1564, false},
+		{"whole", 149 * Dollar, 0, 149, false},
+		{"tie to even", 149*Dollar + Dollar/2, 0, 150, false},
+		{"tie down to even", 148*Dollar + Dollar/2, 0, 148, false},
+		{"negative tie", -(148*Dollar + Dollar/2), 0, -148, false},
+		{"above tie", 148*Dollar + Dollar/2 + 1, 0, 149, false},
+		{"half a cent", Dollar / 200, 2, 0, false},
+		{"all digits", 12345, 11, 12345, false},
+		{"too many digits", 12345, 12, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.amount.MinorUnits(tt.digits)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
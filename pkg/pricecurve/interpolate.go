@@ -0,0 +1,182 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ndau/ndaumath/pkg/signed"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// An IssuanceSnapshot records the total quantity of ndau issued at a moment
+type IssuanceSnapshot struct {
+	At     types.Timestamp
+	Issued types.Ndau
+}
+
+// InterpolateIssuance estimates the quantity of ndau issued at a moment
+// between two snapshots of issuance, assuming that issuance grew linearly
+// between them.
+//
+// The estimate truncates toward issuedAtT0. It is an error for t1 to
+// precede t0, for at to lie outside [t0, t1], or for either quantity to be
+// negative. If t0 == t1, the snapshots must agree.
+func InterpolateIssuance(issuedAtT0, issuedAtT1 types.Ndau, t0, t1, at types.Timestamp) (types.Ndau, error) {
+	if issuedAtT0 < 0 || issuedAtT1 < 0 {
+		return 0, fmt.Errorf("issued quantity must not be negative; got %d and %d", issuedAtT0, issuedAtT1)
+	}
+	if t1 < t0 {
+		return 0, fmt.Errorf("snapshot at %s precedes snapshot at %s", t1, t0)
+	}
+	if at < t0 || at > t1 {
+		return 0, fmt.Errorf("%s is outside the snapshots [%s, %s]", at, t0, t1)
+	}
+	if t0 == t1 {
+		if issuedAtT0 != issuedAtT1 {
+			return 0, fmt.Errorf("simultaneous snapshots disagree: %d and %d", issuedAtT0, issuedAtT1)
+		}
+		return issuedAtT0, nil
+	}
+
+	// both quantities are non-negative, so their difference can't overflow;
+	// nor can the span, as timestamps are never negative
+	delta := int64(issuedAtT1 - issuedAtT0)
+	step, err := signed.MulDiv(delta, int64(at.Since(t0)), int64(t1.Since(t0)))
+	if err != nil {
+		return 0, errors.Wrap(err, "interpolating issuance")
+	}
+	return issuedAtT0 + types.Ndau(step), nil
+}
+
+// InterpolatePrice estimates the target price at a moment between two
+// snapshots of issuance, on the default curve.
+//
+// See Curve.InterpolatePrice.
+func InterpolatePrice(issuedAtT0, issuedAtT1 types.Ndau, t0, t1, at types.Timestamp) (Nanocent, error) {
+	return defaultCurve.InterpolatePrice(issuedAtT0, issuedAtT1, t0, t1, at)
+}
+
+// InterpolatePrice estimates the target price at a moment between two
+// snapshots of issuance: the target price of the quantity estimated by
+// InterpolateIssuance.
+//
+// Only integer math is used, so every caller computes the same price for
+// the same inputs.
+func (c *Curve) InterpolatePrice(issuedAtT0, issuedAtT1 types.Ndau, t0, t1, at types.Timestamp) (Nanocent, error) {
+	issued, err := InterpolateIssuance(issuedAtT0, issuedAtT1, t0, t1, at)
+	if err != nil {
+		return 0, err
+	}
+	return c.TargetPrice(issued)
+}
+
+// PriceAt estimates the target price at a moment from a history of
+// issuance snapshots, on the default curve.
+//
+// See Curve.PriceAt.
+func PriceAt(history []IssuanceSnapshot, at types.Timestamp) (Nanocent, error) {
+	return defaultCurve.PriceAt(history, at)
+}
+
+// PriceAt estimates the target price at a moment from a history of
+// issuance snapshots, interpolating between the snapshots either side of it.
+//
+// The history must be in increasing order of At. It is an error for at to
+// lie outside the span of the history.
+func (c *Curve) PriceAt(history []IssuanceSnapshot, at types.Timestamp) (Nanocent, error) {
+	err := checkHistory(history)
+	if err != nil {
+		return 0, err
+	}
+	return c.priceAt(history, at)
+}
+
+// priceAt is PriceAt, for a history already checked
+func (c *Curve) priceAt(history []IssuanceSnapshot, at types.Timestamp) (Nanocent, error) {
+	s0, s1, err := bracket(history, at)
+	if err != nil {
+		return 0, err
+	}
+	return c.InterpolatePrice(s0.Issued, s1.Issued, s0.At, s1.At, at)
+}
+
+// PriceSeries estimates the target price at each of n moments, starting at
+// start and spaced by step, from a history of issuance snapshots, on the
+// default curve.
+//
+// See Curve.PriceSeries.
+func PriceSeries(history []IssuanceSnapshot, start types.Timestamp, step types.Duration, n int) ([]Nanocent, error) {
+	return defaultCurve.PriceSeries(history, start, step, n)
+}
+
+// PriceSeries estimates the target price at each of n moments, starting at
+// start and spaced by step, from a history of issuance snapshots.
+//
+// This is the series from which to draw a chart of the price. Every moment
+// must lie within the span of the history.
+func (c *Curve) PriceSeries(history []IssuanceSnapshot, start types.Timestamp, step types.Duration, n int) ([]Nanocent, error) {
+	if n < 0 {
+		return nil, fmt.Errorf("series length must not be negative; got %d", n)
+	}
+	if step <= 0 && n > 1 {
+		return nil, fmt.Errorf("series step must be positive; got %s", step)
+	}
+	err := checkHistory(history)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Nanocent, 0, n)
+	at := start
+	for i := 0; i < n; i++ {
+		price, err := c.priceAt(history, at)
+		if err != nil {
+			return nil, errors.Wrapf(err, "point %d", i)
+		}
+		out = append(out, price)
+		at = at.Add(step)
+	}
+	return out, nil
+}
+
+// checkHistory returns an error unless a history is non-empty and in order
+func checkHistory(history []IssuanceSnapshot) error {
+	if len(history) == 0 {
+		return errors.New("issuance history is empty")
+	}
+	for i := 1; i < len(history); i++ {
+		if history[i].At < history[i-1].At {
+			return fmt.Errorf("issuance history is out of order at snapshot %d", i)
+		}
+	}
+	return nil
+}
+
+// bracket finds the snapshots either side of at in a checked history
+//
+// If at falls exactly on a snapshot, both returned snapshots are that one.
+func bracket(history []IssuanceSnapshot, at types.Timestamp) (s0, s1 IssuanceSnapshot, err error) {
+	// the first snapshot at or after at
+	i := sort.Search(len(history), func(i int) bool {
+		return history[i].At >= at
+	})
+	switch {
+	case i == len(history) || (i == 0 && history[0].At > at):
+		return s0, s1, fmt.Errorf("%s is outside the issuance history [%s, %s]",
+			at, history[0].At, history[len(history)-1].At)
+	case history[i].At == at:
+		return history[i], history[i], nil
+	default:
+		return history[i-1], history[i], nil
+	}
+}
@@ -0,0 +1,151 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolateIssuance(t *testing.T) {
+	const ndau = constants.QuantaPerUnit
+	const t0, t1 = types.Timestamp(1000), types.Timestamp(2000)
+	tests := []struct {
+		name   string
+		i0, i1 types.Ndau
+		at     types.Timestamp
+		want   types.Ndau
+	}{
+		{"start", 10 * ndau, 20 * ndau, t0, 10 * ndau},
+		{"end", 10 * ndau, 20 * ndau, t1, 20 * ndau},
+		{"middle", 10 * ndau, 20 * ndau, 1500, 15 * ndau},
+		{"quarter", 0, 4 * ndau, 1250, ndau},
+		{"truncates", 0, 3, 1500, 1},
+		{"flat", 7, 7, 1700, 7},
+		{"decreasing", 20, 10, 1500, 15},
+		{"decreasing truncates", 3, 0, 1500, 2},
+		{"large", 0, math.MaxInt64, 1500, math.MaxInt64 / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InterpolateIssuance(tt.i0, tt.i1, t0, t1, tt.at)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInterpolateIssuanceErrors(t *testing.T) {
+	_, err := InterpolateIssuance(-1, 10, 0, 10, 5)
+	require.Error(t, err)
+	_, err = InterpolateIssuance(0, 10, 10, 0, 5)
+	require.Error(t, err)
+	_, err = InterpolateIssuance(0, 10, 0, 10, 11)
+	require.Error(t, err)
+	_, err = InterpolateIssuance(0, 10, 5, 10, 4)
+	require.Error(t, err)
+	_, err = InterpolateIssuance(0, 10, 5, 5, 5)
+	require.Error(t, err)
+
+	got, err := InterpolateIssuance(10, 10, 5, 5, 5)
+	require.NoError(t, err)
+	require.Equal(t, types.Ndau(10), got)
+}
+
+func TestInterpolatePrice(t *testing.T) {
+	const block = SaleBlockQty * constants.QuantaPerUnit
+	// a day in which issuance crossed three sale blocks
+	t0 := types.Timestamp(0)
+	t1 := t0.Add(types.Day)
+	for i := int64(0); i <= 24; i++ {
+		at := t0.Add(types.Duration(i) * types.Hour)
+		got, err := InterpolatePrice(0, 3*block, t0, t1, at)
+		require.NoError(t, err)
+		want, err := TargetPrice(types.Ndau(i*3*block/24))
+		require.NoError(t, err)
+		require.Equal(t, want, got, "hour %d", i)
+	}
+
+	_, err := InterpolatePrice(0, block, t0, t1, t1.Add(1))
+	require.Error(t, err)
+}
+
+func TestPriceAt(t *testing.T) {
+	const block = SaleBlockQty * constants.QuantaPerUnit
+	history := []IssuanceSnapshot{
+		{At: 100, Issued: 0},
+		{At: 200, Issued: 10 * block},
+		{At: 200, Issued: 10 * block},
+		{At: 400, Issued: 12 * block},
+	}
+	tests := []struct {
+		at     types.Timestamp
+		issued types.Ndau
+	}{
+		{100, 0},
+		{150, 5 * block},
+		{200, 10 * block},
+		{300, 11 * block},
+		{400, 12 * block},
+	}
+	for _, tt := range tests {
+		t.Run(tt.at.String(), func(t *testing.T) {
+			got, err := PriceAt(history, tt.at)
+			require.NoError(t, err)
+			want, err := TargetPrice(tt.issued)
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+		})
+	}
+
+	_, err := PriceAt(history, 99)
+	require.Error(t, err)
+	_, err = PriceAt(history, 401)
+	require.Error(t, err)
+	_, err = PriceAt(nil, 100)
+	require.Error(t, err)
+	_, err = PriceAt([]IssuanceSnapshot{{At: 200}, {At: 100}}, 150)
+	require.Error(t, err)
+}
+
+func TestPriceSeries(t *testing.T) {
+	const block = SaleBlockQty * constants.QuantaPerUnit
+	history := []IssuanceSnapshot{
+		{At: 0, Issued: 0},
+		{At: 1000, Issued: 1000 * block},
+	}
+	series, err := PriceSeries(history, 0, 100, 11)
+	require.NoError(t, err)
+	require.Len(t, series, 11)
+	for i, price := range series {
+		want, err := TargetPrice(types.Ndau(i) * 100 * block)
+		require.NoError(t, err)
+		require.Equal(t, want, price, "point %d", i)
+		if i > 0 {
+			require.True(t, price > series[i-1], "point %d", i)
+		}
+	}
+
+	series, err = PriceSeries(history, 0, 0, 1)
+	require.NoError(t, err)
+	require.Len(t, series, 1)
+
+	_, err = PriceSeries(history, 0, 0, 2)
+	require.Error(t, err)
+	_, err = PriceSeries(history, 0, 100, -1)
+	require.Error(t, err)
+	_, err = PriceSeries(history, 0, 100, 12)
+	require.Error(t, err)
+}
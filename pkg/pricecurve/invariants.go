@@ -0,0 +1,95 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// continuityTolerance is the amount by which the step in price across a phase
+// boundary may exceed the steps either side of it, allowing for truncation.
+const continuityTolerance Nanocent = 1
+
+// CheckInvariants returns an error if the prices of the curve are not
+// well-behaved, so that a proposed CurveParams can be validated before it is
+// adopted. The price of every sale block through Phase3End is computed, and:
+//
+//   - must be computable without overflow, and positive
+//   - must be no less than the price of the block before it
+//   - must be no greater than FinalPrice
+//
+// The curve must also be continuous at each boundary between phases during
+// which ndau are sold: the start of each doubling within phase 1, and the start
+// of phase 2. There, the step in price from the previous block must not exceed
+// the greater of the steps before and after it by more than 1 nanocent. The
+// step to FinalPrice at Phase3End, after which no more ndau are sold, need
+// only be nondecreasing.
+func CheckInvariants(c *Curve) error {
+	p := c.params
+	end := p.Phase3End
+
+	// boundaries are the first blocks of the phases in which ndau are sold.
+	// Blocks 0 and 1 are the first two doublings themselves, so the first
+	// boundary within phase 1 is at block 2^2.
+	boundaries := make(map[uint64]bool)
+	for dblock := 2; dblock < len(p.Doublings) && pow2(dblock) <= p.Phase1End; dblock++ {
+		boundaries[pow2(dblock)] = true
+	}
+	if p.Phase1End+1 < end {
+		boundaries[p.Phase1End+1] = true
+	}
+
+	// step is the step in price to the current block, and prevStep and
+	// stepBefore are the ones to the previous two
+	var prev, step, prevStep, stepBefore Nanocent
+	for block := uint64(0); block <= end; block++ {
+		price, err := c.priceAtBlock(block)
+		if err != nil {
+			return errors.Wrapf(err, "pricing block %d", block)
+		}
+		if price <= 0 {
+			return fmt.Errorf("price of block %d must be positive; got %d", block, price)
+		}
+		if price > p.FinalPrice {
+			return fmt.Errorf(
+				"price of block %d (%d) exceeds the final price (%d)",
+				block, price, p.FinalPrice,
+			)
+		}
+		if block > 0 {
+			stepBefore, prevStep, step = prevStep, step, price-prev
+			if step < 0 {
+				return fmt.Errorf("price falls from %d to %d at block %d", prev, price, block)
+			}
+			if boundaries[block-1] {
+				// the step across the boundary was the previous one, and there
+				// is no step after it if the next block is at the final price
+				across, before, after := prevStep, stepBefore, step
+				if block == end {
+					after = 0
+				}
+				if before < after {
+					before = after
+				}
+				if across > before+continuityTolerance {
+					return fmt.Errorf(
+						"price jumps by %d at block %d; the neighbouring steps are at most %d",
+						across, block-1, before,
+					)
+				}
+			}
+		}
+		prev = price
+	}
+	return nil
+}
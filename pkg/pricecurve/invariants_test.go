@@ -0,0 +1,101 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckInvariantsStandardCurves(t *testing.T) {
+	require.NoError(t, CheckInvariants(defaultCurve))
+
+	// the old curve reaches its top doubling a block late, so it steps into
+	// phase 2 a little more steeply than either phase steps
+	err := CheckInvariants(curve10000)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "block 10001")
+}
+
+// linearParams returns a curve which is $1 throughout phase 1, then rises by
+// $1 per block from $2
+func linearParams() CurveParams {
+	return CurveParams{
+		BlockQty:         10,
+		Phase1End:        10,
+		Phase3End:        50,
+		Doublings:        []Nanocent{Dollar, Dollar},
+		Ratio:            1,
+		RatioDenominator: 1,
+		Phase23Terms: []CubicTerm{
+			{Coefficient: -9, PreDivisor: 1, Divisor: 1},
+			{Coefficient: 1, PreDivisor: 1, Divisor: 1},
+		},
+		FinalPrice: 100 * Dollar,
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(p *CurveParams)
+		wantErr bool
+	}{
+		{"valid", func(p *CurveParams) {}, false},
+		{"jump to final price", func(p *CurveParams) { p.FinalPrice = 1000 * Dollar }, false},
+		{"final price at last block", func(p *CurveParams) { p.FinalPrice = 40 * Dollar }, false},
+		{"final price below last block", func(p *CurveParams) { p.FinalPrice = 39 * Dollar }, true},
+		{"jump at phase 2", func(p *CurveParams) { p.Phase23Terms[0].Coefficient = -8 }, true},
+		{"within tolerance at phase 2", func(p *CurveParams) {
+			p.Doublings = []Nanocent{Dollar - 1, Dollar - 1}
+		}, false},
+		{"beyond tolerance at phase 2", func(p *CurveParams) {
+			p.Doublings = []Nanocent{Dollar - 2, Dollar - 2}
+		}, true},
+		{"falls at phase 2", func(p *CurveParams) { p.Doublings = []Nanocent{Dollar, 3 * Dollar} }, true},
+		{"not positive", func(p *CurveParams) { p.Phase23Terms[0].Coefficient = -20 }, true},
+		{"overflow", func(p *CurveParams) {
+			p.Phase23Terms = append(p.Phase23Terms, CubicTerm{Coefficient: 1 << 40, PreDivisor: 1, Divisor: 1})
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := linearParams()
+			tt.modify(&p)
+			curve, err := CurveFromParams(p)
+			require.NoError(t, err)
+			err = CheckInvariants(curve)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckInvariantsDoublings(t *testing.T) {
+	p := DefaultCurveParams()
+	p.Doublings[12] *= 2
+	curve, err := CurveFromParams(p)
+	require.NoError(t, err)
+	err = CheckInvariants(curve)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "block 4096")
+
+	// the last doubling is too small, so the price falls there
+	p = DefaultCurveParams()
+	p.Doublings[13] = p.Doublings[12]
+	curve, err = CurveFromParams(p)
+	require.NoError(t, err)
+	require.Error(t, CheckInvariants(curve))
+}
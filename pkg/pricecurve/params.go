@@ -0,0 +1,423 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sync"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/signed"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+//go:generate msgp
+//msgp:ignore Curve
+
+// A CubicTerm is a single term of the phase 2/3 polynomial, in whole dollars.
+//
+// The term of power n is computed in integer math as
+//
+//	trunc(trunc(x^n / PreDivisor) * Coefficient / Divisor)
+//
+// where x is the sale block. Splitting the divisor lets large powers be
+// reduced before the multiplication, avoiding overflow; because each division
+// truncates, the split is part of the definition of the curve and is
+// preserved exactly.
+type CubicTerm struct {
+	Coefficient int64
+	PreDivisor  int64
+	Divisor     int64
+}
+
+// CurveParams captures the complete state of a price curve, such that it can
+// be stored in a system variable and reconstructed with CurveFromParams.
+//
+// Sale blocks up to and including Phase1End are priced in phase 1: the price
+// starts from the entry in Doublings corresponding to the highest power of 2
+// not exceeding the block, and is multiplied by Ratio/RatioDenominator once
+// for each block past that point.
+//
+// Sale blocks after Phase1End and before Phase3End are priced by the
+// polynomial in Phase23Terms, where the term at index n has power n.
+//
+// From Phase3End onwards, the price is constant at FinalPrice.
+type CurveParams struct {
+	BlockQty         int64
+	Phase1End        uint64
+	Phase3End        uint64
+	Doublings        []Nanocent
+	Ratio            int64
+	RatioDenominator int64
+	Phase23Terms     []CubicTerm
+	FinalPrice       Nanocent
+}
+
+// DefaultCurveParams returns the parameters of the standard price curve, as
+// used by PriceAtUnit.
+func DefaultCurveParams() CurveParams {
+	return CurveParams{
+		BlockQty:  SaleBlockQty,
+		Phase1End: phaseBlocks,
+		Phase3End: phaseBlocks * 3,
+		// To prevent excessive error, we pre-compute a table of doublings, and
+		// work from there. The 14 entries in this table are the prices of ndau when
+		// 2 ^ (2 ^ ((N - 1) * 14 / 9999)) have been sold, where N = 1 to 14.
+		//
+		// To verify this table in python:
+		//
+		// >>> denom = 100000000000
+		// >>> [round(denom * 2 ** (((2 ** n) - 1)*14/9999)) for n in range(14)]
+		// [
+		//	100000000000, 100097097419, 100291575187, 100681665003, 101466402368,
+		//  103054274072, 106304953285, 113117158227, 128079155775, 164201982670,
+		//  269884708015, 729084792015, 5320807694887, 283384837710463,
+		// ]
+		//
+		// Note that the final value differs by 1 from the python-calculated
+		// value. We're using Wolfram Alpha as the authoritative source for high-
+		// precision mathematics, and it comes up with this value:
+		//
+		// https://www.wolframalpha.com/input/?i=d%3D100000000000;+n%3D13;+round(d+*+2+%5E+(((2+**+n)+-+1)*14%2F9999))
+		Doublings: []Nanocent{
+			100000000000, 100097097419, 100291575187, 100681665003, 101466402368,
+			103054274072, 106304953285, 113117158227, 128079155775, 164201982670,
+			269884708015, 729084792015, 5320807694887, 283384837710462,
+		},
+		Ratio:            1000970974193617,
+		RatioDenominator: 1000000000000000,
+		Phase23Terms:     phase23Terms(),
+		FinalPrice:       50045083 * (Dollar / 100),
+	}
+}
+
+// params10000 returns the parameters of the old price curve, based on a
+// transition point of 10000, as used by PriceAtUnit10000.
+func params10000() CurveParams {
+	p := DefaultCurveParams()
+	// >>> denom = 100000000000
+	// >>> [round(denom * 2 ** (((2 ** n) - 1)*14/10000)) for n in range(14)]
+	p.Doublings = []Nanocent{
+		100000000000, 100097087704, 100291545986, 100681596605, 101466254658,
+		103053964027, 106304303320, 113115764023, 128075986132, 164193839650,
+		269857914525, 728939964968, 5318693514199, 283159653540666,
+	}
+	p.Ratio = 1000970877049078
+	return p
+}
+
+// phase23Terms returns the cubic curvefit for phases 2 and 3:
+//
+//	y = -41633 - 8.286618*x + 0.00167424*x^2 - 2.654015e-8*x^3
+//
+// The third-order term divides by 10^7 twice: once before and once after
+// applying its coefficient.
+func phase23Terms() []CubicTerm {
+	return []CubicTerm{
+		{Coefficient: -41633, PreDivisor: 1, Divisor: 1},
+		{Coefficient: -8286618, PreDivisor: 1, Divisor: 1000000},
+		{Coefficient: 167424, PreDivisor: 1, Divisor: 100000000},
+		{Coefficient: -2654015, PreDivisor: 10000000, Divisor: 10000000},
+	}
+}
+
+// Validate returns an error if the params cannot describe a price curve
+func (p CurveParams) Validate() error {
+	if p.BlockQty <= 0 || p.BlockQty > math.MaxInt64/constants.QuantaPerUnit {
+		return fmt.Errorf("block quantity out of range: %d", p.BlockQty)
+	}
+	if p.Phase1End < 1 {
+		return errors.New("phase 1 must contain at least one block")
+	}
+	if p.Phase3End <= p.Phase1End {
+		return fmt.Errorf(
+			"phase 3 end (%d) must follow phase 1 end (%d)",
+			p.Phase3End, p.Phase1End,
+		)
+	}
+	if len(p.Doublings) < 2 {
+		return fmt.Errorf("need at least 2 doublings; got %d", len(p.Doublings))
+	}
+	if len(p.Doublings) >= 64 {
+		return fmt.Errorf("too many doublings: %d", len(p.Doublings))
+	}
+	for i, d := range p.Doublings {
+		if d <= 0 {
+			return fmt.Errorf("doubling %d must be positive; got %d", i, d)
+		}
+	}
+	if p.Ratio <= 0 {
+		return fmt.Errorf("ratio must be positive; got %d", p.Ratio)
+	}
+	if p.RatioDenominator <= 0 {
+		return fmt.Errorf("ratio denominator must be positive; got %d", p.RatioDenominator)
+	}
+	if len(p.Phase23Terms) == 0 {
+		return errors.New("phase 2/3 polynomial has no terms")
+	}
+	for i, t := range p.Phase23Terms {
+		if t.PreDivisor == 0 || t.Divisor == 0 {
+			return fmt.Errorf("phase 2/3 term %d has a zero divisor", i)
+		}
+	}
+	if p.FinalPrice < 0 {
+		return fmt.Errorf("final price must not be negative; got %d", p.FinalPrice)
+	}
+	return nil
+}
+
+// maxPhase1Table is the greatest number of phase 1 prices a Curve caches.
+const maxPhase1Table = 1 << 16
+
+// A Curve computes prices according to a set of CurveParams.
+type Curve struct {
+	params CurveParams
+
+	// phase1Table caches the price of each phase 1 block, built on first use.
+	phase1Once  sync.Once
+	phase1Table []Nanocent
+}
+
+// CurveFromParams constructs a Curve from the given params.
+//
+// The params are copied; later modification of the caller's value does not
+// affect the curve.
+func CurveFromParams(p CurveParams) (*Curve, error) {
+	err := p.Validate()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid curve params")
+	}
+	c := Curve{params: p}
+	c.params.Doublings = append([]Nanocent(nil), p.Doublings...)
+	c.params.Phase23Terms = append([]CubicTerm(nil), p.Phase23Terms...)
+	return &c, nil
+}
+
+// Params returns a copy of the params from which this curve was constructed
+func (c *Curve) Params() CurveParams {
+	p := c.params
+	p.Doublings = append([]Nanocent(nil), c.params.Doublings...)
+	p.Phase23Terms = append([]CubicTerm(nil), c.params.Phase23Terms...)
+	return p
+}
+
+// blockQty returns the number of napu in a sale block
+func (c *Curve) blockQty() types.Ndau {
+	return types.Ndau(c.params.BlockQty * constants.QuantaPerUnit)
+}
+
+// PriceAtUnit returns the price of the next ndau given the number already sold
+func (c *Curve) PriceAtUnit(nunitsSold types.Ndau) (Nanocent, error) {
+	return c.priceAtBlock(uint64(nunitsSold / c.blockQty()))
+}
+
+// priceAtBlock returns the price of each ndau in a sale block
+func (c *Curve) priceAtBlock(block uint64) (Nanocent, error) {
+	if block <= c.params.Phase1End {
+		return c.phase1(block)
+	}
+
+	if block < c.params.Phase3End {
+		return c.phase23(int64(block))
+	}
+
+	// after the end of phase 3 we don't sell any more ndau so just return the
+	// final price
+	return c.params.FinalPrice, nil
+}
+
+// TargetPrice returns the target price implied by the curve, given the total
+// quantity of ndau issued: the price at which the next ndau would be issued.
+//
+// It is an error for the quantity issued to be negative.
+func (c *Curve) TargetPrice(issued types.Ndau) (Nanocent, error) {
+	if issued < 0 {
+		return 0, fmt.Errorf("issued quantity must not be negative; got %d", issued)
+	}
+	return c.PriceAtUnit(issued)
+}
+
+// phase1 returns the price of a phase 1 block.
+//
+// Each application of the ratio truncates, so the price of a block can't be
+// computed by raising the ratio to a power: the rounding would differ. Instead,
+// the prices of every block of phase 1 are computed once, step by step, and
+// looked up thereafter.
+func (c *Curve) phase1(block uint64) (Nanocent, error) {
+	c.phase1Once.Do(c.buildPhase1Table)
+	if block < uint64(len(c.phase1Table)) {
+		return c.phase1Table[block], nil
+	}
+	return c.phase1Walk(block)
+}
+
+// buildPhase1Table populates phase1Table.
+//
+// The table stops short if a price overflows, or if phase 1 is too long to
+// cache; prices past its end are computed by phase1Walk.
+func (c *Curve) buildPhase1Table() {
+	n := c.params.Phase1End + 1
+	if n > maxPhase1Table {
+		n = maxPhase1Table
+	}
+	doublings := c.params.Doublings
+	table := make([]Nanocent, 0, n)
+	for block := uint64(0); block < n; block++ {
+		if block <= 1 {
+			table = append(table, doublings[int(block)])
+			continue
+		}
+		// the price steps on from the previous block, except at a power of 2,
+		// where it steps on from the doubling
+		prev := table[block-1]
+		if dblock := bits.Len64(block) - 1; block == pow2(dblock) && dblock < len(doublings) {
+			prev = doublings[dblock]
+		}
+		price, err := c.applyRatio(prev)
+		if err != nil {
+			break
+		}
+		table = append(table, price)
+	}
+	c.phase1Table = table
+}
+
+// phase1Walk computes the price of a phase 1 block without the table.
+func (c *Curve) phase1Walk(block uint64) (out Nanocent, err error) {
+	doublings := c.params.Doublings
+	if block <= 1 {
+		return doublings[int(block)], nil
+	}
+
+	// find the appropriate doubling for this block to get the base price.
+	// linearly search the list; it's faster than binary for lists of this size.
+	var dblock int
+	for dblock, out = range doublings {
+		if block >= pow2(dblock) && block < pow2(dblock+1) {
+			break
+		}
+	}
+
+	// now out has our base number. From this point, we need to apply a
+	// constant ratio, however many times are required by the difference
+	// between the block and the dblock
+	for i := uint64(0); i <= (block - pow2(dblock)); i++ {
+		out, err = c.applyRatio(out)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return
+}
+
+// applyRatio applies the phase 1 ratio once to a price.
+func (c *Curve) applyRatio(price Nanocent) (Nanocent, error) {
+	out, err := signed.MulDiv(
+		int64(price),
+		c.params.Ratio,
+		c.params.RatioDenominator,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, "applying phase 1 ratio")
+	}
+	return Nanocent(out), nil
+}
+
+func (c *Curve) phase23(block int64) (Nanocent, error) {
+	var total int64
+	power := int64(1)
+	for n, t := range c.params.Phase23Terms {
+		if n > 0 {
+			var err error
+			power, err = signed.Mul(power, block)
+			if err != nil {
+				return 0, errors.Wrapf(err, "order%d power", n)
+			}
+		}
+		term, err := signed.Div(power, t.PreDivisor)
+		if err != nil {
+			return 0, errors.Wrapf(err, "order%d phase 1", n)
+		}
+		term, err = signed.MulDiv(term, t.Coefficient, t.Divisor)
+		if err != nil {
+			return 0, errors.Wrapf(err, "order%d phase 2", n)
+		}
+		term, err = signed.Mul(term, Dollar)
+		if err != nil {
+			return 0, errors.Wrapf(err, "order%d", n)
+		}
+		total, err = signed.Add(total, term)
+		if err != nil {
+			return 0, errors.Wrapf(err, "order%d sum", n)
+		}
+	}
+	return Nanocent(total), nil
+}
+
+// UnitAtPrice does a binary search over the sale blocks, returning the
+// quantity sold at the start of the last block whose price is below the
+// given price, or 0 if there is no such block.
+func (c *Curve) UnitAtPrice(price Nanocent) (types.Ndau, error) {
+	high := int64(c.params.Phase3End)
+	low := int64(0)
+	guess := high / 2
+	for high-low > 1 {
+		p, err := c.PriceAtUnit(types.Ndau(guess) * c.blockQty())
+		if err != nil {
+			return 0, errors.Wrap(err, "getting price of block")
+		}
+		if p >= price {
+			high = guess
+		} else {
+			low = guess
+		}
+		guess = (high + low) / 2
+	}
+	return types.Ndau(guess) * c.blockQty(), nil
+}
+
+// TotalPriceFor returns the total price for a group of ndau given the
+// amount to be purchased and the number already sold. The numbers passed in
+// are integer number of napu NOT ndau.
+//
+// Purchases may span sale blocks; each portion is charged at the price of the
+// block it falls in. Fractional ndau are charged pro rata, truncating any
+// fraction of a nanocent.
+func (c *Curve) TotalPriceFor(numNdau, alreadySold types.Ndau) (Nanocent, error) {
+	numPerBlock := c.blockQty()
+	var total int64
+	for numNdau > 0 {
+		price, err := c.PriceAtUnit(alreadySold)
+		if err != nil {
+			return 0, errors.Wrap(err, "getting price of block")
+		}
+		qty := numPerBlock - alreadySold%numPerBlock
+		if numNdau < qty {
+			qty = numNdau
+		}
+
+		cost, err := signed.MulDiv(int64(price), int64(qty), constants.QuantaPerUnit)
+		if err != nil {
+			return 0, errors.Wrap(err, "pricing block")
+		}
+		total, err = signed.Add(total, cost)
+		if err != nil {
+			return 0, errors.Wrap(err, "summing total price")
+		}
+
+		numNdau -= qty
+		alreadySold += qty
+	}
+	return Nanocent(total), nil
+}
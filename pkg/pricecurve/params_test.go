@@ -0,0 +1,198 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurveParamsRoundtrip(t *testing.T) {
+	for _, p := range []CurveParams{DefaultCurveParams(), params10000()} {
+		bts, err := p.MarshalMsg(nil)
+		require.NoError(t, err)
+
+		var got CurveParams
+		leftover, err := got.UnmarshalMsg(bts)
+		require.NoError(t, err)
+		require.Empty(t, leftover)
+		require.Equal(t, p, got)
+
+		// a curve rebuilt from the serialized params prices identically
+		curve, err := CurveFromParams(got)
+		require.NoError(t, err)
+		for _, block := range []int{0, 1, 2, 714, 9999, 10000, 10001, 14100, 29999, 30000, 40000} {
+			t.Run(fmt.Sprint(block), func(t *testing.T) {
+				var want Nanocent
+				if p.Ratio == DefaultCurveParams().Ratio {
+					want, err = PriceAtUnit(blockStart(block))
+				} else {
+					want, err = PriceAtUnit10000(blockStart(block))
+				}
+				require.NoError(t, err)
+				price, err := curve.PriceAtUnit(blockStart(block))
+				require.NoError(t, err)
+				require.Equal(t, want, price)
+			})
+		}
+	}
+}
+
+func TestCurveFromParamsCopies(t *testing.T) {
+	p := DefaultCurveParams()
+	curve, err := CurveFromParams(p)
+	require.NoError(t, err)
+	want, err := curve.PriceAtUnit(blockStart(5000))
+	require.NoError(t, err)
+
+	p.Doublings[12] = 1
+	p.Phase23Terms[0].Coefficient = 0
+	got, err := curve.PriceAtUnit(blockStart(5000))
+	require.NoError(t, err)
+	require.Equal(t, want, got)
+	require.Equal(t, DefaultCurveParams(), curve.Params())
+}
+
+func TestCurveFromParamsCustom(t *testing.T) {
+	// $1 through phase 1, then $2 + $1 per block, capped at $100
+	p := CurveParams{
+		BlockQty:         10,
+		Phase1End:        10,
+		Phase3End:        50,
+		Doublings:        []Nanocent{Dollar, Dollar},
+		Ratio:            1,
+		RatioDenominator: 1,
+		Phase23Terms: []CubicTerm{
+			{Coefficient: 2, PreDivisor: 1, Divisor: 1},
+			{Coefficient: 1, PreDivisor: 1, Divisor: 1},
+		},
+		FinalPrice: 100 * Dollar,
+	}
+	curve, err := CurveFromParams(p)
+	require.NoError(t, err)
+
+	const napuPerBlock = types.Ndau(10 * constants.QuantaPerUnit)
+	tests := []struct {
+		block types.Ndau
+		want  Nanocent
+	}{
+		{0, Dollar},
+		{10, Dollar},
+		{11, 13 * Dollar},
+		{49, 51 * Dollar},
+		{50, 100 * Dollar},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.block), func(t *testing.T) {
+			got, err := curve.PriceAtUnit(napuPerBlock*tt.block + napuPerBlock/2)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	total, err := curve.TotalPriceFor(2*napuPerBlock, 10*napuPerBlock)
+	require.NoError(t, err)
+	require.Equal(t, Nanocent(10*Dollar+130*Dollar), total)
+
+	unit, err := curve.UnitAtPrice(20 * Dollar)
+	require.NoError(t, err)
+	require.Equal(t, 17*napuPerBlock, unit)
+}
+
+func TestPhase1TableMatchesWalk(t *testing.T) {
+	few := DefaultCurveParams()
+	few.Doublings = few.Doublings[:3]
+	long := DefaultCurveParams()
+	long.Phase1End = maxPhase1Table + 100
+	long.Phase3End = long.Phase1End * 3
+	long.Ratio = long.RatioDenominator + 1
+
+	// walking is quadratic, so only the default curve is checked exhaustively
+	for _, tt := range []struct {
+		name string
+		p    CurveParams
+		step uint64
+	}{
+		{"default", DefaultCurveParams(), 1},
+		{"10000", params10000(), 97},
+		{"few doublings", few, 97},
+		{"long", long, 997},
+	} {
+		p := tt.p
+		t.Run(tt.name, func(t *testing.T) {
+			curve, err := CurveFromParams(p)
+			require.NoError(t, err)
+			var blocks []uint64
+			for block := uint64(0); block <= p.Phase1End && block <= phaseBlocks; block += tt.step {
+				blocks = append(blocks, block)
+			}
+			blocks = append(blocks, phaseBlocks-1, phaseBlocks)
+			if p.Phase1End > phaseBlocks {
+				blocks = append(blocks, maxPhase1Table-1, maxPhase1Table, p.Phase1End)
+			}
+			for _, block := range blocks {
+				want, err := curve.phase1Walk(block)
+				require.NoError(t, err)
+				got, err := curve.phase1(block)
+				require.NoError(t, err)
+				require.Equal(t, want, got, "block %d", block)
+			}
+		})
+	}
+}
+
+func TestPhase1Overflow(t *testing.T) {
+	p := DefaultCurveParams()
+	p.Ratio = 2 * p.RatioDenominator
+	curve, err := CurveFromParams(p)
+	require.NoError(t, err)
+
+	// the price doubles with each block, so overflows part way through the
+	// doubling from block 64
+	_, err = curve.phase1(70)
+	require.NoError(t, err)
+	_, err = curve.phase1(200)
+	require.Error(t, err)
+	_, err = curve.phase1(256)
+	require.NoError(t, err)
+}
+
+func TestCurveFromParamsInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*CurveParams)
+	}{
+		{"zero block qty", func(p *CurveParams) { p.BlockQty = 0 }},
+		{"huge block qty", func(p *CurveParams) { p.BlockQty = 1 << 62 }},
+		{"empty phase 1", func(p *CurveParams) { p.Phase1End = 0 }},
+		{"phase 3 before phase 1", func(p *CurveParams) { p.Phase3End = p.Phase1End }},
+		{"one doubling", func(p *CurveParams) { p.Doublings = p.Doublings[:1] }},
+		{"negative doubling", func(p *CurveParams) { p.Doublings[3] = -1 }},
+		{"zero ratio", func(p *CurveParams) { p.Ratio = 0 }},
+		{"zero ratio denominator", func(p *CurveParams) { p.RatioDenominator = 0 }},
+		{"no terms", func(p *CurveParams) { p.Phase23Terms = nil }},
+		{"zero predivisor", func(p *CurveParams) { p.Phase23Terms[3].PreDivisor = 0 }},
+		{"zero divisor", func(p *CurveParams) { p.Phase23Terms[1].Divisor = 0 }},
+		{"negative final price", func(p *CurveParams) { p.FinalPrice = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultCurveParams()
+			tt.modify(&p)
+			_, err := CurveFromParams(p)
+			require.Error(t, err)
+		})
+	}
+}
@@ -23,6 +23,31 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// floatPriceAtUnit is the original floating-point model of the price curve.
+//
+// It is no longer used to compute prices, but remains useful as a reference
+// against which to check the integer implementation.
+func floatPriceAtUnit(nunitsSold types.Ndau) float64 {
+	ndauSold := float64(nunitsSold / constants.QuantaPerUnit)
+	saleBlock := ndauSold / SaleBlockQty
+
+	if saleBlock < phaseBlocks*1 {
+		return math.Pow(2.0, saleBlock*14/9999)
+	}
+
+	if saleBlock < phaseBlocks*3 {
+		const d = -2.654015e-8
+		const c = 0.00167424
+		const b = -8.286618
+		const a = -41633
+		x := saleBlock
+
+		return d*math.Pow(x, 3) + c*math.Pow(x, 2) + b*x + a
+	}
+
+	return 500450.83
+}
+
 func Test_ApproxPriceAtUnit(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -30,12 +55,12 @@ func Test_ApproxPriceAtUnit(t *testing.T) {
 		want       float64
 	}{
 		{"0", 0, 1.00},
-		{"1", 1, 1.000000970503574},
-		{"1000", 1000, 1.0009709741936168},
-		{"714214", 714214, 1.9999994454268752},
-		{"714215", 714215, 2.000001386433485},
-		{"9,999,000", 9999000, 16384},
-		{"15,000,000", 15000000, 121198.72375},
+		{"1", 1, 1.00},
+		{"1000", 1000, 1.00097097419},
+		{"714214", 714214, 1.9995841122},
+		{"715000", 715000, 2.00152565677},
+		{"9,999,000", 9999000, 16383.99999998351},
+		{"15,000,000", 15000000, 121199},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -55,7 +80,7 @@ func Test_UnitAtPrice(t *testing.T) {
 		{"1", 1.0, 0},
 		{"2", 2.0, 714000},
 		{"16.90", 16.90, 2913000},
-		{"16384", 16384, 9998000},
+		{"16384", 16384, 9999000},
 		{"100000", 100000, 14100000},
 	}
 	for _, tt := range tests {
@@ -79,8 +104,8 @@ func TestTotalPriceFor(t *testing.T) {
 	}{
 		{"first ndau", args{100000000, 0}, 1},
 		{"first block", args{100000000000, 0}, 1000},
-		{"second block", args{100000000000, 100000000000}, 1000.9709741936168},
-		{"ten blocks at start", args{1000000000000, 0}, 10043.807166082466},
+		{"second block", args{100000000000, 100000000000}, 1000.97097419},
+		{"ten blocks at start", args{1000000000000, 0}, 10043.80716601},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -119,7 +144,7 @@ func TestPhase1(t *testing.T) {
 
 	for block := uint64(0); block < 10000; block++ {
 		sold := block * constants.QuantaPerUnit * SaleBlockQty
-		apau := ApproxPriceAtUnit(types.Ndau(sold))
+		apau := floatPriceAtUnit(types.Ndau(sold))
 		pau := phase1(block, true)
 		paud := float64(pau) / float64(Dollar)
 
@@ -165,7 +190,7 @@ func TestPhase23(t *testing.T) {
 
 	for block := int64(10000); block < 30000; block++ {
 		sold := block * constants.QuantaPerUnit * SaleBlockQty
-		apau := ApproxPriceAtUnit(types.Ndau(sold))
+		apau := floatPriceAtUnit(types.Ndau(sold))
 		pau, err := phase23(block)
 		require.NoError(t, err)
 		paud := float64(pau) / float64(Dollar)
@@ -182,3 +207,111 @@ func TestPhase23(t *testing.T) {
 		})
 	}
 }
+
+func TestTotalPriceForNanocents(t *testing.T) {
+	type args struct {
+		numNdau     types.Ndau
+		alreadySold types.Ndau
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    Nanocent
+		wantErr bool
+	}{
+		{"nothing", args{0, 0}, 0, false},
+		{"first ndau", args{100000000, 0}, 1 * Dollar, false},
+		{"fractional ndau", args{50000000, 0}, Dollar / 2, false},
+		{"first block", args{100000000000, 0}, 1000 * Dollar, false},
+		{"second block", args{100000000000, 100000000000}, 1000 * 100097097419, false},
+		{"spanning blocks", args{100000000000, 50000000000}, 500*Dollar + 500*100097097419, false},
+		{"single napu at end of block", args{1, 99999999999}, 1000, false},
+		{"overflow", args{100000000000, 29000 * 100000000000}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TotalPriceFor(tt.args.numNdau, tt.args.alreadySold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TotalPriceFor() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("TotalPriceFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitAtPriceNanocents(t *testing.T) {
+	for _, block := range []int{1, 2, 714, 715, 9999, 10000, 14100, 29998} {
+		t.Run(fmt.Sprint(block), func(t *testing.T) {
+			price, err := PriceAtUnit(blockStart(block))
+			require.NoError(t, err)
+			// the block at exactly the given price is not below it, so we
+			// expect the one before
+			got, err := UnitAtPrice(price)
+			require.NoError(t, err)
+			require.Equal(t, blockStart(block-1), got)
+			// but the smallest price increase pushes us into this block
+			got, err = UnitAtPrice(price + 1)
+			require.NoError(t, err)
+			require.Equal(t, blockStart(block), got)
+		})
+	}
+}
+
+func TestApproxMatchesNanocents(t *testing.T) {
+	// below 2^53 nanocents, float64 is exact to the nanocent
+	const exactLimit = Nanocent(1) << 53
+	for block := 0; block <= phaseBlocks*3; block++ {
+		sold := blockStart(block)
+		price, err := PriceAtUnit(sold)
+		require.NoError(t, err)
+		approx := ApproxPriceAtUnit(sold)
+
+		diff := Nanocent(math.Round(approx*Dollar)) - price
+		if diff < 0 {
+			diff = -diff
+		}
+		if price < exactLimit {
+			require.True(t, diff <= 1, "block %d: %d != %f", block, price, approx)
+		} else {
+			require.InEpsilon(t, float64(price), approx*Dollar, 1e-15, "block %d", block)
+		}
+	}
+}
+
+func TestTargetPrice(t *testing.T) {
+	for _, block := range []int{0, 1, 2, 1000, 5000, 9998, 9999, 10000, 10001, 15000, 29999, 30000, 40000} {
+		for _, offset := range []types.Ndau{0, 1, blockStart(1) - 1} {
+			issued := blockStart(block) + offset
+			got, err := TargetPrice(issued)
+			require.NoError(t, err)
+			want, err := PriceAtUnit9999(issued)
+			require.NoError(t, err)
+			require.Equal(t, want, got, "block %d + %d", block, offset)
+
+			got, err = TargetPrice10000(issued)
+			require.NoError(t, err)
+			want, err = PriceAtUnit10000(issued)
+			require.NoError(t, err)
+			require.Equal(t, want, got, "block %d + %d", block, offset)
+		}
+	}
+
+	// the variants differ within phase 1
+	p9999, err := TargetPrice(blockStart(5000))
+	require.NoError(t, err)
+	p10000, err := TargetPrice10000(blockStart(5000))
+	require.NoError(t, err)
+	require.NotEqual(t, p9999, p10000)
+
+	price, err := TargetPrice(0)
+	require.NoError(t, err)
+	require.Equal(t, Nanocent(Dollar), price)
+
+	_, err = TargetPrice(-1)
+	require.Error(t, err)
+	_, err = TargetPrice10000(-1)
+	require.Error(t, err)
+}
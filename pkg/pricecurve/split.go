@@ -0,0 +1,132 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signed"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+//go:generate msgp
+
+// SplitDenominator is the denominator of the shares of a SplitTier: shares
+// are expressed in parts per million.
+const SplitDenominator = 1000000
+
+// A SplitTier gives the shares of the proceeds of sales made from a given
+// position on the curve onwards.
+//
+// Operations and MarketMaker are the shares allocated to operations and to
+// the market maker, in parts per SplitDenominator. The endowment receives
+// the rest.
+type SplitTier struct {
+	From        types.Ndau
+	Operations  int64
+	MarketMaker int64
+}
+
+// SplitParams describe how the proceeds of ndau sales are split between the
+// endowment, operations, and the market maker, such that they can be stored
+// in a system variable.
+//
+// Tiers are listed in increasing order of From; the first must begin at 0.
+// The tier in effect for a sale is the last whose From does not exceed the
+// number of ndau issued before the sale.
+type SplitParams struct {
+	Tiers []SplitTier
+}
+
+// Validate returns an error if the params cannot describe a split
+func (p SplitParams) Validate() error {
+	if len(p.Tiers) == 0 {
+		return errors.New("split params have no tiers")
+	}
+	if p.Tiers[0].From != 0 {
+		return fmt.Errorf("first split tier must begin at 0; got %d", p.Tiers[0].From)
+	}
+	for i, t := range p.Tiers {
+		if i > 0 && t.From <= p.Tiers[i-1].From {
+			return fmt.Errorf("split tier %d does not follow tier %d", i, i-1)
+		}
+		if t.Operations < 0 || t.MarketMaker < 0 {
+			return fmt.Errorf("split tier %d has a negative share", i)
+		}
+		if t.Operations+t.MarketMaker > SplitDenominator {
+			return fmt.Errorf(
+				"split tier %d allocates more than the whole: %d/%d",
+				i, t.Operations+t.MarketMaker, SplitDenominator,
+			)
+		}
+	}
+	return nil
+}
+
+// tierAt returns the tier in effect when issued ndau have been issued
+func (p SplitParams) tierAt(issued types.Ndau) SplitTier {
+	tier := p.Tiers[0]
+	for _, t := range p.Tiers[1:] {
+		if t.From > issued {
+			break
+		}
+		tier = t
+	}
+	return tier
+}
+
+// A ProceedsSplit is the allocation of the proceeds of a single purchase.
+//
+// Endowment, Operations, and MarketMaker always sum exactly to Purchase.
+type ProceedsSplit struct {
+	Purchase    Nanocent
+	Endowment   Nanocent
+	Operations  Nanocent
+	MarketMaker Nanocent
+}
+
+// SplitProceeds computes the allocation of a purchase of ndau costing
+// purchase, made when issued ndau have already been issued.
+//
+// The tier in effect at issued applies to the whole purchase, even if the
+// purchase extends past the start of the next tier. The operations and
+// market maker allocations truncate; the endowment receives what remains, so
+// no nanocent is lost or created.
+func SplitProceeds(p SplitParams, purchase Nanocent, issued types.Ndau) (ProceedsSplit, error) {
+	err := p.Validate()
+	if err != nil {
+		return ProceedsSplit{}, errors.Wrap(err, "invalid split params")
+	}
+	if purchase < 0 {
+		return ProceedsSplit{}, fmt.Errorf("purchase must not be negative; got %d", purchase)
+	}
+	if issued < 0 {
+		return ProceedsSplit{}, fmt.Errorf("issued ndau must not be negative; got %d", issued)
+	}
+	tier := p.tierAt(issued)
+
+	// the shares never exceed the denominator, so these can't overflow
+	ops, _, err := signed.MulDivRem(int64(purchase), tier.Operations, SplitDenominator)
+	if err != nil {
+		return ProceedsSplit{}, errors.Wrap(err, "operations share")
+	}
+	mm, _, err := signed.MulDivRem(int64(purchase), tier.MarketMaker, SplitDenominator)
+	if err != nil {
+		return ProceedsSplit{}, errors.Wrap(err, "market maker share")
+	}
+	return ProceedsSplit{
+		Purchase:    purchase,
+		Endowment:   purchase - Nanocent(ops) - Nanocent(mm),
+		Operations:  Nanocent(ops),
+		MarketMaker: Nanocent(mm),
+	}, nil
+}
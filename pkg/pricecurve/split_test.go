@@ -0,0 +1,96 @@
+package pricecurve
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func testSplitParams() SplitParams {
+	return SplitParams{Tiers: []SplitTier{
+		{From: 0, Operations: 100000, MarketMaker: 50000},
+		{From: 1000000 * constants.QuantaPerUnit, Operations: 75000, MarketMaker: 25000},
+	}}
+}
+
+func TestSplitProceeds(t *testing.T) {
+	p := testSplitParams()
+	tests := []struct {
+		name     string
+		purchase Nanocent
+		issued   types.Ndau
+		want     ProceedsSplit
+	}{
+		{"first tier", 100 * Dollar, 0, ProceedsSplit{100 * Dollar, 85 * Dollar, 10 * Dollar, 5 * Dollar}},
+		{"second tier", 100 * Dollar, 1000000 * constants.QuantaPerUnit, ProceedsSplit{100 * Dollar, 90 * Dollar, 15 * Dollar / 2, 5 * Dollar / 2}},
+		{"just before second tier", 100 * Dollar, 1000000*constants.QuantaPerUnit - 1, ProceedsSplit{100 * Dollar, 85 * Dollar, 10 * Dollar, 5 * Dollar}},
+		// 10% of 19 truncates to 1, and 5% to 0
+		{"dust to endowment", 19, 0, ProceedsSplit{19, 18, 1, 0}},
+		{"zero", 0, 0, ProceedsSplit{0, 0, 0, 0}},
+		{"max", math.MaxInt64, 0, ProceedsSplit{math.MaxInt64, 7839866231326559437, 922337203685477580, 461168601842738790}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitProceeds(p, tt.purchase, tt.issued)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, got.Purchase, got.Endowment+got.Operations+got.MarketMaker)
+
+			bts, err := got.MarshalMsg(nil)
+			require.NoError(t, err)
+			var rt ProceedsSplit
+			_, err = rt.UnmarshalMsg(bts)
+			require.NoError(t, err)
+			require.Equal(t, got, rt)
+		})
+	}
+}
+
+func TestSplitProceedsErrors(t *testing.T) {
+	_, err := SplitProceeds(testSplitParams(), -1, 0)
+	require.Error(t, err)
+	_, err = SplitProceeds(testSplitParams(), 1, -1)
+	require.Error(t, err)
+	_, err = SplitProceeds(SplitParams{}, 1, 0)
+	require.Error(t, err)
+}
+
+func TestSplitParamsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   []SplitTier
+		wantErr bool
+	}{
+		{"valid", testSplitParams().Tiers, false},
+		{"all to endowment", []SplitTier{{}}, false},
+		{"none to endowment", []SplitTier{{Operations: SplitDenominator / 2, MarketMaker: SplitDenominator / 2}}, false},
+		{"empty", nil, true},
+		{"late start", []SplitTier{{From: 1}}, true},
+		{"out of order", []SplitTier{{From: 0}, {From: 5}, {From: 5}}, true},
+		{"negative", []SplitTier{{Operations: -1}}, true},
+		{"more than whole", []SplitTier{{Operations: SplitDenominator, MarketMaker: 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SplitParams{Tiers: tt.tiers}.Validate()
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
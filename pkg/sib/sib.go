@@ -0,0 +1,142 @@
+package sib
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/eai"
+	"github.com/ndau/ndaumath/pkg/pricecurve"
+	"github.com/ndau/ndaumath/pkg/signed"
+	math "github.com/ndau/ndaumath/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// The Stabilization Incentive Burn (SIB) is a fraction of each transfer which
+// is burned while the market price of ndau is sufficiently far below the
+// target price implied by the price curve.
+//
+// Given the ratio of market to target price, the SIB rate is
+//
+//	0                                  if ratio >= Threshold
+//	(100% - ratio) * Multiplier        otherwise
+//
+// clamped to [Floor, Ceiling] whenever it is not 0. All computation is in
+// integer math, truncating at each step, so that every node computes exactly
+// the same rate.
+
+//go:generate msgp
+
+// Params are the parameters of the SIB calculation
+type Params struct {
+	// Threshold is the ratio of market to target price below which the SIB
+	// is in effect
+	Threshold eai.Rate
+	// Multiplier scales the shortfall of the market price to the SIB rate
+	Multiplier eai.Rate
+	// Floor is the least SIB rate in effect, when the SIB is in effect at all
+	Floor eai.Rate
+	// Ceiling is the greatest SIB rate in effect
+	Ceiling eai.Rate
+}
+
+// DefaultParams returns the SIB parameters of the spec
+//
+// The SIB goes into effect when the market price falls below 95% of the
+// target price, at half of the shortfall, and never exceeds 50%.
+func DefaultParams() Params {
+	return Params{
+		Threshold:  eai.RateFromPercent(95),
+		Multiplier: eai.RateFromPercent(50),
+		Floor:      0,
+		Ceiling:    eai.RateFromPercent(50),
+	}
+}
+
+// Validate returns an error if these parameters can't compute a SIB rate
+func (p Params) Validate() error {
+	full := eai.Rate(constants.RateDenominator)
+	if p.Threshold <= 0 || p.Threshold > full {
+		return fmt.Errorf("threshold must be in (0, %d]; got %d", full, p.Threshold)
+	}
+	if p.Multiplier < 0 {
+		return fmt.Errorf("multiplier must not be negative; got %d", p.Multiplier)
+	}
+	if p.Floor < 0 {
+		return fmt.Errorf("floor must not be negative; got %d", p.Floor)
+	}
+	if p.Ceiling < p.Floor || p.Ceiling > full {
+		return fmt.Errorf("ceiling must be in [%d, %d]; got %d", p.Floor, full, p.Ceiling)
+	}
+	return nil
+}
+
+// Rate computes the SIB rate for the given market and target prices
+//
+// The target price is normally pricecurve.TargetPrice of the total quantity
+// of ndau issued. It is an error if the target price is not positive, or if the market price
+// is negative.
+func (p Params) Rate(market, target pricecurve.Nanocent) (eai.Rate, error) {
+	err := p.Validate()
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid SIB params")
+	}
+	if target <= 0 {
+		return 0, fmt.Errorf("target price must be positive; got %d", target)
+	}
+	if market < 0 {
+		return 0, fmt.Errorf("market price must not be negative; got %d", market)
+	}
+
+	if market >= target {
+		// the threshold is at most 1, so there's no SIB. Returning early
+		// also keeps the ratio from overflowing when market is far above
+		// target.
+		return 0, nil
+	}
+	ratio, err := signed.MulDiv(int64(market), constants.RateDenominator, int64(target))
+	if err != nil {
+		return 0, errors.Wrap(err, "market to target ratio")
+	}
+	if eai.Rate(ratio) >= p.Threshold {
+		return 0, nil
+	}
+	rate, err := signed.MulDiv(constants.RateDenominator-ratio, int64(p.Multiplier), constants.RateDenominator)
+	if err != nil {
+		return 0, errors.Wrap(err, "scaling shortfall")
+	}
+
+	r := eai.Rate(rate)
+	if r < p.Floor {
+		r = p.Floor
+	}
+	if r > p.Ceiling {
+		r = p.Ceiling
+	}
+	return r, nil
+}
+
+// Rate computes the SIB rate for the given market and target prices, using
+// the default parameters
+func Rate(market, target pricecurve.Nanocent) (eai.Rate, error) {
+	return DefaultParams().Rate(market, target)
+}
+
+// Burn computes the quantity of ndau burned by the SIB from a transfer of qty
+// at the given SIB rate, truncated towards zero
+func Burn(qty math.Ndau, rate eai.Rate) (math.Ndau, error) {
+	if rate < 0 || rate > eai.Rate(constants.RateDenominator) {
+		return 0, fmt.Errorf("SIB rate must be in [0, %d]; got %d", constants.RateDenominator, rate)
+	}
+	burn, err := signed.MulDiv(int64(qty), int64(rate), constants.RateDenominator)
+	return math.Ndau(burn), err
+}
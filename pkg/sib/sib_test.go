@@ -0,0 +1,143 @@
+package sib
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/eai"
+	"github.com/ndau/ndaumath/pkg/pricecurve"
+	"github.com/stretchr/testify/require"
+)
+
+func dollars(t *testing.T, s string) pricecurve.Nanocent {
+	nc, err := pricecurve.ParseDollars(s)
+	require.NoError(t, err)
+	return nc
+}
+
+func rate(t *testing.T, s string) eai.Rate {
+	r, err := eai.ParseRate(s)
+	require.NoError(t, err)
+	return r
+}
+
+func TestDefaultRate(t *testing.T) {
+	// golden vectors: these must never change, or nodes will disagree about
+	// the SIB of historical transactions
+	tests := []struct {
+		market string
+		target string
+		want   string
+	}{
+		{"10.00", "10.00", "0%"},
+		{"12.00", "10.00", "0%"},
+		{"9.50", "10.00", "0%"},
+		{"9.49", "10.00", "2.55%"},
+		{"8.00", "10.00", "10%"},
+		{"5.00", "10.00", "25%"},
+		{"0.00", "10.00", "50%"},
+		{"1.00", "3.00", "33.3333333333%"},
+		{"2.00", "3.00", "16.6666666667%"},
+		{"16.77", "17.38", "0%"},
+		{"15.00", "17.38", "6.8469505178%"},
+		{"0.00000000001", "0.00000000002", "25%"},
+		{"9000000.00", "10000000.00", "5%"},
+		{"90000000.00", "0.00000000001", "0%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.market+"/"+tt.target, func(t *testing.T) {
+			got, err := Rate(dollars(t, tt.market), dollars(t, tt.target))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestClamps(t *testing.T) {
+	p := Params{
+		Threshold:  eai.RateFromPercent(100),
+		Multiplier: eai.RateFromPercent(50),
+		Floor:      eai.RateFromPercent(1),
+		Ceiling:    eai.RateFromPercent(20),
+	}
+	tests := []struct {
+		market string
+		want   string
+	}{
+		{"10.00", "0%"},
+		{"9.99", "1%"},
+		{"9.00", "5%"},
+		{"6.00", "20%"},
+		{"0.00", "20%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.market, func(t *testing.T) {
+			got, err := p.Rate(dollars(t, tt.market), dollars(t, "10.00"))
+			require.NoError(t, err)
+			require.Equal(t, rate(t, tt.want), got)
+		})
+	}
+}
+
+func TestRateErrors(t *testing.T) {
+	full := eai.Rate(constants.RateDenominator)
+	valid := DefaultParams()
+	require.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		edit   func(p *Params)
+		market pricecurve.Nanocent
+		target pricecurve.Nanocent
+	}{
+		{"zero target", nil, 1, 0},
+		{"negative target", nil, 1, -1},
+		{"negative market", nil, -1, 1},
+		{"zero threshold", func(p *Params) { p.Threshold = 0 }, 1, 2},
+		{"threshold above 100%", func(p *Params) { p.Threshold = full + 1 }, 1, 2},
+		{"negative multiplier", func(p *Params) { p.Multiplier = -1 }, 1, 2},
+		{"negative floor", func(p *Params) { p.Floor = -1 }, 1, 2},
+		{"ceiling below floor", func(p *Params) { p.Floor, p.Ceiling = 2, 1 }, 1, 2},
+		{"ceiling above 100%", func(p *Params) { p.Ceiling = full + 1 }, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultParams()
+			if tt.edit != nil {
+				tt.edit(&p)
+			}
+			_, err := p.Rate(tt.market, tt.target)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBurn(t *testing.T) {
+	got, err := Burn(100*constants.NapuPerNdau, rate(t, "10%"))
+	require.NoError(t, err)
+	require.Equal(t, int64(10*constants.NapuPerNdau), int64(got))
+
+	// truncated towards zero
+	got, err = Burn(3, rate(t, "50%"))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), int64(got))
+
+	got, err = Burn(3, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), int64(got))
+
+	_, err = Burn(3, -1)
+	require.Error(t, err)
+	_, err = Burn(3, eai.Rate(constants.RateDenominator)+1)
+	require.Error(t, err)
+}
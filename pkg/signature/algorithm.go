@@ -76,17 +76,28 @@ func marshal(al Algorithm, data []byte) (serialized []byte, err error) {
 
 // shallow copy an interface from an example struct
 // https://stackoverflow.com/a/22948379/504550
+//
+// The copy retains the example's fields, so that parameterized algorithms
+// such as Hybrid survive deserialization.
 func cloneAl(original Algorithm) Algorithm {
 	val := reflect.ValueOf(original)
 	if val.Kind() == reflect.Ptr {
 		val = reflect.Indirect(val)
 	}
-	return reflect.New(val.Type()).Interface().(Algorithm)
+	clone := reflect.New(val.Type())
+	clone.Elem().Set(val)
+	return clone.Interface().(Algorithm)
 }
 
 // Unmarshal the serialized binary data into an Algorithm instance and
 // the originally supplied data.
+//
+// The data may be of any format version which FormatVersion recognizes.
 func unmarshal(serialized []byte) (al Algorithm, data []byte, err error) {
+	serialized, err = stripFormatVersion(serialized)
+	if err != nil {
+		return nil, nil, err
+	}
 	container := IdentifiedData{}
 	leftovers, err := container.UnmarshalMsg(serialized)
 	if err != nil {
@@ -95,16 +106,29 @@ func unmarshal(serialized []byte) (al Algorithm, data []byte, err error) {
 	if len(leftovers) > 0 {
 		return nil, nil, errors.New("Leftovers present after deserialization")
 	}
-	return cloneAl(idMap[container.Algorithm]), container.Data, nil
+	al, err = algorithmOf(container.Algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+	return al, container.Data, nil
 }
 
+// unmarshalWithLeftovers unmarshals the v0 serialization at the start of
+// serialized, returning whatever follows it.
+//
+// Only v0 is read, so that a key or signature embedded in a msgp struct has
+// exactly one encoding.
 func unmarshalWithLeftovers(serialized []byte) (al Algorithm, data, leftovers []byte, err error) {
 	container := IdentifiedData{}
 	leftovers, err = container.UnmarshalMsg(serialized)
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	return cloneAl(idMap[container.Algorithm]), container.Data, leftovers, nil
+	al, err = algorithmOf(container.Algorithm)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return al, container.Data, leftovers, nil
 }
 
 // Generate a high-level keypair
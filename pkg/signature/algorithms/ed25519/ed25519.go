@@ -11,6 +11,7 @@ package ed25519
 
 
 import (
+	stded25519 "crypto/ed25519"
 	"io"
 
 	impl "golang.org/x/crypto/ed25519"
@@ -58,3 +59,29 @@ func (ed25519) Verify(public, message, sig []byte) bool {
 func (ed25519) Public(private []byte) []byte {
 	return impl.PrivateKey(private).Public().(impl.PublicKey)
 }
+
+// SignWithContext implements signature.ContextAlgorithm, using Ed25519ctx
+// (RFC 8032 section 5.1)
+//
+// It returns nil if the context is empty or longer than 255 bytes, which
+// Ed25519ctx does not permit.
+func (ed25519) SignWithContext(private []byte, context string, message []byte) []byte {
+	if context == "" || len(private) != stded25519.PrivateKeySize {
+		return nil
+	}
+	sig, err := stded25519.PrivateKey(private).Sign(nil, message, &stded25519.Options{Context: context})
+	if err != nil {
+		return nil
+	}
+	return sig
+}
+
+// VerifyWithContext implements signature.ContextAlgorithm, using Ed25519ctx
+// (RFC 8032 section 5.1)
+func (ed25519) VerifyWithContext(public []byte, context string, message, sig []byte) bool {
+	if context == "" || len(public) != stded25519.PublicKeySize {
+		return false
+	}
+	err := stded25519.VerifyWithOptions(stded25519.PublicKey(public), message, sig, &stded25519.Options{Context: context})
+	return err == nil
+}
@@ -0,0 +1,127 @@
+package ed25519ph
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto"
+	impl "crypto/ed25519"
+	"crypto/sha512"
+	"hash"
+	"io"
+)
+
+// Ed25519ph is pre-hashed Ed25519 (RFC 8032 section 5.1): the message is
+// hashed with SHA-512, and the digest signed, so that a message of any size
+// can be hashed as it streams past instead of being held in memory.
+//
+// Its keys are the same as Ed25519's, but its signatures are not: a
+// signature made by one does not verify with the other.
+//
+// Never edit this; it would be a const if go were smarter
+var Ed25519ph = ed25519ph{}
+
+type ed25519ph struct{}
+
+// PublicKeySize implements Algorithm
+func (ed25519ph) PublicKeySize() int {
+	return impl.PublicKeySize
+}
+
+// PrivateKeySize implements Algorithm
+func (ed25519ph) PrivateKeySize() int {
+	return impl.PrivateKeySize
+}
+
+// SignatureSize implements Algorithm
+func (ed25519ph) SignatureSize() int {
+	return impl.SignatureSize
+}
+
+// Generate implements Algorithm
+func (ed25519ph) Generate(rand io.Reader) (public, private []byte, err error) {
+	return impl.GenerateKey(rand)
+}
+
+// Public implements Algorithm
+func (ed25519ph) Public(private []byte) []byte {
+	return impl.PrivateKey(private).Public().(impl.PublicKey)
+}
+
+// Sign implements Algorithm
+func (e ed25519ph) Sign(private, message []byte) []byte {
+	digest := sha512.Sum512(message)
+	return e.SignDigest(private, digest[:])
+}
+
+// Verify implements Algorithm
+func (e ed25519ph) Verify(public, message, sig []byte) bool {
+	digest := sha512.Sum512(message)
+	return e.VerifyDigest(public, digest[:], sig)
+}
+
+// NewHash implements signature.PrehashAlgorithm: messages are hashed with
+// SHA-512
+func (ed25519ph) NewHash() hash.Hash {
+	return sha512.New()
+}
+
+// SignDigest implements signature.PrehashAlgorithm
+//
+// It returns nil if the digest is not a SHA-512 digest.
+func (ed25519ph) SignDigest(private, digest []byte) []byte {
+	return sign(private, digest, "")
+}
+
+// VerifyDigest implements signature.PrehashAlgorithm
+func (ed25519ph) VerifyDigest(public, digest, sig []byte) bool {
+	return verify(public, digest, sig, "")
+}
+
+// SignWithContext implements signature.ContextAlgorithm, using Ed25519ph
+// with a context (RFC 8032 section 5.1)
+//
+// It returns nil if the context is empty or longer than 255 bytes.
+func (ed25519ph) SignWithContext(private []byte, context string, message []byte) []byte {
+	if context == "" {
+		return nil
+	}
+	digest := sha512.Sum512(message)
+	return sign(private, digest[:], context)
+}
+
+// VerifyWithContext implements signature.ContextAlgorithm, using Ed25519ph
+// with a context (RFC 8032 section 5.1)
+func (ed25519ph) VerifyWithContext(public []byte, context string, message, sig []byte) bool {
+	if context == "" {
+		return false
+	}
+	digest := sha512.Sum512(message)
+	return verify(public, digest[:], sig, context)
+}
+
+func sign(private, digest []byte, context string) []byte {
+	if len(private) != impl.PrivateKeySize || len(digest) != sha512.Size {
+		return nil
+	}
+	sig, err := impl.PrivateKey(private).Sign(nil, digest, &impl.Options{Hash: crypto.SHA512, Context: context})
+	if err != nil {
+		return nil
+	}
+	return sig
+}
+
+func verify(public, digest, sig []byte, context string) bool {
+	if len(public) != impl.PublicKeySize || len(digest) != sha512.Size {
+		return false
+	}
+	err := impl.VerifyWithOptions(impl.PublicKey(public), digest, sig, &impl.Options{Hash: crypto.SHA512, Context: context})
+	return err == nil
+}
@@ -0,0 +1,153 @@
+package secp256k1
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+)
+
+// Other standards, notably JOSE and COSE, serialize secp256k1 keys and
+// signatures differently than we do. These functions convert between them,
+// and between our raw keys and the standard library's ecdsa types.
+
+// coordLen is the length in bytes of a curve coordinate or scalar
+const coordLen = 32
+
+// fixed returns the big-endian bytes of n, left-padded to coordLen
+func fixed(n *big.Int) []byte {
+	b := n.Bytes()
+	out := make([]byte, coordLen)
+	copy(out[coordLen-len(b):], b)
+	return out
+}
+
+// CompactFromDER converts a DER signature, as produced by Sign, into the
+// fixed-length R || S form used by JWS and COSE.
+func CompactFromDER(der []byte) ([]byte, error) {
+	sig, err := btcec.ParseDERSignature(der, btcec.S256())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing DER signature")
+	}
+	return append(fixed(sig.R), fixed(sig.S)...), nil
+}
+
+// DERFromCompact converts a fixed-length R || S signature into the DER form
+// expected by Verify.
+func DERFromCompact(compact []byte) ([]byte, error) {
+	if len(compact) != 2*coordLen {
+		return nil, errors.New("compact signature must be 64 bytes")
+	}
+	sig := btcec.Signature{
+		R: new(big.Int).SetBytes(compact[:coordLen]),
+		S: new(big.Int).SetBytes(compact[coordLen:]),
+	}
+	return sig.Serialize(), nil
+}
+
+// PublicPoint returns the affine coordinates of a compressed public key
+func PublicPoint(public []byte) (x, y []byte, err error) {
+	pub, err := btcec.ParsePubKey(public, btcec.S256())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "parsing public key")
+	}
+	return fixed(pub.X), fixed(pub.Y), nil
+}
+
+// PublicFromPoint returns the compressed public key at the given affine
+// coordinates, which must lie on the curve.
+func PublicFromPoint(x, y []byte) ([]byte, error) {
+	if len(x) != coordLen || len(y) != coordLen {
+		return nil, errors.New("coordinates must be 32 bytes")
+	}
+	pub := btcec.PublicKey{
+		Curve: btcec.S256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}
+	if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// IsCurve is true if c has the parameters of secp256k1.
+//
+// Other libraries, notably Ethereum's, ship their own implementations of the
+// curve, so we compare parameters rather than identity.
+func IsCurve(c elliptic.Curve) bool {
+	if c == nil {
+		return false
+	}
+	p, want := c.Params(), btcec.S256().Params()
+	return p.P.Cmp(want.P) == 0 &&
+		p.N.Cmp(want.N) == 0 &&
+		p.B.Cmp(want.B) == 0 &&
+		p.Gx.Cmp(want.Gx) == 0 &&
+		p.Gy.Cmp(want.Gy) == 0
+}
+
+// ToECDSA converts a raw private key into an ecdsa.PrivateKey on btcec's
+// implementation of the curve.
+func ToECDSA(private []byte) (*ecdsa.PrivateKey, error) {
+	if len(private) != btcec.PrivKeyBytesLen {
+		return nil, errors.New("private key must be 32 bytes")
+	}
+	d := new(big.Int).SetBytes(private)
+	if d.Sign() == 0 || d.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("private key is out of range")
+	}
+	priv, _ := btcec.PrivKeyFromBytes(btcec.S256(), private)
+	return priv.ToECDSA(), nil
+}
+
+// FromECDSA converts an ecdsa.PrivateKey on secp256k1 into a raw private key
+func FromECDSA(priv *ecdsa.PrivateKey) ([]byte, error) {
+	if priv == nil || priv.D == nil {
+		return nil, errors.New("nil private key")
+	}
+	if !IsCurve(priv.Curve) {
+		return nil, errors.New("private key is not on secp256k1")
+	}
+	if priv.D.Sign() <= 0 || priv.D.Cmp(btcec.S256().N) >= 0 {
+		return nil, errors.New("private key is out of range")
+	}
+	return fixed(priv.D), nil
+}
+
+// ToECDSAPublic converts a compressed public key into an ecdsa.PublicKey on
+// btcec's implementation of the curve.
+func ToECDSAPublic(public []byte) (*ecdsa.PublicKey, error) {
+	pub, err := btcec.ParsePubKey(public, btcec.S256())
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing public key")
+	}
+	return pub.ToECDSA(), nil
+}
+
+// FromECDSAPublic converts an ecdsa.PublicKey on secp256k1 into a compressed
+// public key
+func FromECDSAPublic(pub *ecdsa.PublicKey) ([]byte, error) {
+	if pub == nil || pub.X == nil || pub.Y == nil {
+		return nil, errors.New("nil public key")
+	}
+	if !IsCurve(pub.Curve) {
+		return nil, errors.New("public key is not on secp256k1")
+	}
+	if !btcec.S256().IsOnCurve(pub.X, pub.Y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	return (*btcec.PublicKey)(pub).SerializeCompressed(), nil
+}
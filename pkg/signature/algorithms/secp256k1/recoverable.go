@@ -0,0 +1,130 @@
+package secp256k1
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Secp256k1Recoverable signs with secp256k1 keys, but produces compact
+// signatures from which the signer's public key can be recovered.
+//
+// A compact signature is 65 bytes, as made by Bitcoin's signmessage: a header
+// byte, followed by the 32-byte R and S values. The header byte is 27 plus
+// the recovery ID, plus 4 because the signer's public key is compressed, as
+// ours always are. The message is hashed with SHA-256, as for Secp256k1.
+//
+// This is not the format of Ethereum signatures, which put the recovery byte
+// last, as 27 or 28, and are made over the Keccak-256 hash of the message:
+// Ethereum tools can neither verify these signatures nor recover their keys.
+//
+// Keys are identical to those of Secp256k1.
+//
+// Never edit this; it would be a const if go were smarter
+var Secp256k1Recoverable = secp256k1Recoverable{}
+
+type secp256k1Recoverable struct{}
+
+const (
+	// CompactSignatureSize is the size in bytes of a compact signature
+	CompactSignatureSize = 65
+	// CompactHeaderMin is the least header byte of a compact signature by a
+	// compressed key: 27, plus 4 for the compressed key
+	CompactHeaderMin = 27 + 4
+	// CompactHeaderMax is the greatest header byte of a compact signature by
+	// a compressed key, whose recovery ID is 3
+	CompactHeaderMax = CompactHeaderMin + 3
+)
+
+// SignCompact signs a 32-byte hash with a private key, producing a compact
+// signature
+func SignCompact(private *btcec.PrivateKey, hash []byte) ([]byte, error) {
+	return btcec.SignCompact(btcec.S256(), private, hash, true)
+}
+
+// RecoverCompact returns the compressed public key which made a compact
+// signature of a 32-byte hash
+//
+// Only signatures by compressed keys, whose header bytes lie between
+// CompactHeaderMin and CompactHeaderMax, are accepted.
+func RecoverCompact(hash, signature []byte) ([]byte, error) {
+	if len(signature) != CompactSignatureSize {
+		return nil, fmt.Errorf("compact signature must be %d bytes; got %d", CompactSignatureSize, len(signature))
+	}
+	if signature[0] < CompactHeaderMin || signature[0] > CompactHeaderMax {
+		return nil, fmt.Errorf("unsupported compact signature header %d", signature[0])
+	}
+	pub, _, err := btcec.RecoverCompact(btcec.S256(), signature, hash)
+	if err != nil {
+		return nil, err
+	}
+	return pub.SerializeCompressed(), nil
+}
+
+// PublicKeySize is the size in bytes of this algorithm's public keys
+func (secp256k1Recoverable) PublicKeySize() int {
+	return Secp256k1.PublicKeySize()
+}
+
+// PrivateKeySize is the size in bytes of this algorithm's private keys
+func (secp256k1Recoverable) PrivateKeySize() int {
+	return Secp256k1.PrivateKeySize()
+}
+
+// SignatureSize is the size in bytes of this algorithm's signatures
+func (secp256k1Recoverable) SignatureSize() int {
+	return CompactSignatureSize
+}
+
+// Generate creates a new keypair
+func (secp256k1Recoverable) Generate(rand io.Reader) (public, private []byte, err error) {
+	return Secp256k1.Generate(rand)
+}
+
+// Sign signs the message with privateKey and returns a signature
+func (secp256k1Recoverable) Sign(private, message []byte) []byte {
+	ecPriv, _ := btcec.PrivKeyFromBytes(btcec.S256(), private)
+	sig, err := SignCompact(ecPriv, hash(message))
+	if err != nil {
+		// as with Secp256k1.Sign, this only happens for a vanishingly
+		// unlikely combination of key and message
+		panic(err)
+	}
+	return sig
+}
+
+// Verify verifies a message's signature
+//
+// Return true if the signature is valid
+func (a secp256k1Recoverable) Verify(public, message, signature []byte) bool {
+	recovered, err := a.Recover(message, signature)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(recovered, public)
+}
+
+// Public generates a public key when given a private key
+func (secp256k1Recoverable) Public(private []byte) []byte {
+	return Secp256k1.Public(private)
+}
+
+// Recover returns the public key which made a signature of the message
+//
+// Any well-formed signature yields some public key, so the caller must
+// compare the result with the expected signer's.
+func (secp256k1Recoverable) Recover(message, signature []byte) ([]byte, error) {
+	return RecoverCompact(hash(message), signature)
+}
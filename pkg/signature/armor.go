@@ -0,0 +1,373 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ASCII armor, in the style of OpenPGP (RFC 4880 section 6.2), for keys and
+// signatures which must survive being pasted into email, chat, or a terminal.
+// An armored block looks like this:
+//
+//	-----BEGIN NDAU PUBLIC KEY-----
+//	Algorithm: ed25519
+//	Fingerprint: fbc436cbad1a250f
+//	Comment: alice's laptop
+//
+//	kgHEISAlCNs/I/4zvAyytWD712Hme6sjUZ8Ig4T6ZRX34kwvuQ==
+//	=/Fwx
+//	-----END NDAU PUBLIC KEY-----
+//
+// The body is the base64 encoding of the binary serialization of the key or
+// signature, followed by its CRC-24.
+
+// armored block types
+const (
+	ArmorTypePublicKey  = "NDAU PUBLIC KEY"
+	ArmorTypePrivateKey = "NDAU PRIVATE KEY"
+	ArmorTypeSignature  = "NDAU SIGNATURE"
+)
+
+// armor header names
+const (
+	HeaderAlgorithm   = "Algorithm"
+	HeaderFingerprint = "Fingerprint"
+	HeaderComment     = "Comment"
+)
+
+// headerOrder is the order in which known headers are written; others follow
+// in lexical order
+var headerOrder = []string{HeaderAlgorithm, HeaderFingerprint, HeaderComment}
+
+const (
+	armorDashes    = "-----"
+	armorLineWidth = 64
+	crc24Init      = 0xb704ce
+	crc24Poly      = 0x1864cfb
+)
+
+// crc24 computes the OpenPGP CRC-24 of data
+func crc24(data []byte) uint32 {
+	crc := uint32(crc24Init)
+	for _, b := range data {
+		crc ^= uint32(b) << 16
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if crc&0x1000000 != 0 {
+				crc ^= crc24Poly
+			}
+		}
+	}
+	return crc & 0xffffff
+}
+
+// Fingerprint returns a short identifier for a public key
+//
+// It is the hex encoding of the first 8 bytes of the SHA-256 hash of the key
+// bytes. Extra data does not contribute.
+func Fingerprint(key PublicKey) string {
+	sum := sha256.Sum256(key.KeyBytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+// An Armored block holds ASCII-armored data
+type Armored struct {
+	Type    string
+	Headers map[string]string
+	Data    []byte
+}
+
+// ensure Armored implements encoding.Text(Un)Marshaler
+var _ encoding.TextMarshaler = (*Armored)(nil)
+var _ encoding.TextUnmarshaler = (*Armored)(nil)
+
+func newArmored(blockType string, al Algorithm, data []byte, comment string) *Armored {
+	a := Armored{
+		Type:    blockType,
+		Headers: map[string]string{HeaderAlgorithm: NameOf(al)},
+		Data:    data,
+	}
+	if comment != "" {
+		a.Headers[HeaderComment] = comment
+	}
+	return &a
+}
+
+// ArmorPublicKey armors a public key
+func ArmorPublicKey(key PublicKey, comment string) (*Armored, error) {
+	data, err := key.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling public key")
+	}
+	a := newArmored(ArmorTypePublicKey, key.Algorithm(), data, comment)
+	a.Headers[HeaderFingerprint] = Fingerprint(key)
+	return a, nil
+}
+
+// ArmorPrivateKey armors a private key
+//
+// The fingerprint is that of the corresponding public key.
+func ArmorPrivateKey(key PrivateKey, comment string) (*Armored, error) {
+	data, err := key.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling private key")
+	}
+	public, err := publicOf(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "computing public key")
+	}
+	a := newArmored(ArmorTypePrivateKey, key.Algorithm(), data, comment)
+	a.Headers[HeaderFingerprint] = Fingerprint(*public)
+	return a, nil
+}
+
+// ArmorSignature armors a signature
+//
+// If signer is not nil, its fingerprint is included.
+func ArmorSignature(sig Signature, signer *PublicKey, comment string) (*Armored, error) {
+	data, err := sig.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshaling signature")
+	}
+	a := newArmored(ArmorTypeSignature, sig.Algorithm(), data, comment)
+	if signer != nil {
+		a.Headers[HeaderFingerprint] = Fingerprint(*signer)
+	}
+	return a, nil
+}
+
+// validHeader is true if the header can be written without ambiguity
+func validHeader(k, v string) bool {
+	return k != "" &&
+		!strings.ContainsAny(k, ":\r\n") &&
+		!strings.ContainsAny(v, "\r\n") &&
+		strings.TrimSpace(k) == k &&
+		strings.TrimSpace(v) == v
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a Armored) MarshalText() ([]byte, error) {
+	if a.Type == "" || strings.ContainsAny(a.Type, "-\r\n") {
+		return nil, fmt.Errorf("invalid armor type %q", a.Type)
+	}
+	keys := make([]string, 0, len(a.Headers))
+	for k, v := range a.Headers {
+		if !validHeader(k, v) {
+			return nil, fmt.Errorf("invalid armor header %q: %q", k, v)
+		}
+		keys = append(keys, k)
+	}
+	rank := func(k string) int {
+		for i, known := range headerOrder {
+			if k == known {
+				return i
+			}
+		}
+		return len(headerOrder)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank(keys[i]), rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%sBEGIN %s%s\n", armorDashes, a.Type, armorDashes)
+	for _, k := range keys {
+		fmt.Fprintf(&out, "%s: %s\n", k, a.Headers[k])
+	}
+	out.WriteByte('\n')
+	body := base64.StdEncoding.EncodeToString(a.Data)
+	for len(body) > armorLineWidth {
+		out.WriteString(body[:armorLineWidth])
+		out.WriteByte('\n')
+		body = body[armorLineWidth:]
+	}
+	if body != "" {
+		out.WriteString(body)
+		out.WriteByte('\n')
+	}
+	crc := crc24(a.Data)
+	out.WriteByte('=')
+	out.WriteString(base64.StdEncoding.EncodeToString([]byte{byte(crc >> 16), byte(crc >> 8), byte(crc)}))
+	out.WriteByte('\n')
+	fmt.Fprintf(&out, "%sEND %s%s\n", armorDashes, a.Type, armorDashes)
+	return out.Bytes(), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+//
+// Whitespace surrounding the block and at the ends of lines is ignored, as
+// are carriage returns. The CRC-24 is required and must match.
+func (a *Armored) UnmarshalText(text []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(text)), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	begin := lines[0]
+	if !strings.HasPrefix(begin, armorDashes+"BEGIN ") || !strings.HasSuffix(begin, armorDashes) ||
+		len(begin) <= len(armorDashes+"BEGIN ")+len(armorDashes) {
+		return errors.New("armor: missing BEGIN line")
+	}
+	blockType := begin[len(armorDashes+"BEGIN ") : len(begin)-len(armorDashes)]
+	end := armorDashes + "END " + blockType + armorDashes
+	if len(lines) < 3 || lines[len(lines)-1] != end {
+		return fmt.Errorf("armor: missing %q", end)
+	}
+	lines = lines[1 : len(lines)-1]
+
+	headers := make(map[string]string)
+	for len(lines) > 0 && lines[0] != "" {
+		idx := strings.Index(lines[0], ":")
+		if idx < 0 {
+			// no blank line: this is the body
+			if len(headers) == 0 {
+				break
+			}
+			return fmt.Errorf("armor: malformed header %q", lines[0])
+		}
+		headers[strings.TrimSpace(lines[0][:idx])] = strings.TrimSpace(lines[0][idx+1:])
+		lines = lines[1:]
+	}
+	if len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+
+	if len(lines) == 0 || !strings.HasPrefix(lines[len(lines)-1], "=") {
+		return errors.New("armor: missing checksum")
+	}
+	sum, err := base64.StdEncoding.DecodeString(lines[len(lines)-1][1:])
+	if err != nil || len(sum) != 3 {
+		return errors.New("armor: malformed checksum")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(lines[:len(lines)-1], ""))
+	if err != nil {
+		return errors.Wrap(err, "armor: decoding body")
+	}
+	if crc24(data) != uint32(sum[0])<<16|uint32(sum[1])<<8|uint32(sum[2]) {
+		return errors.New("armor: checksum mismatch")
+	}
+
+	a.Type = blockType
+	a.Headers = headers
+	a.Data = data
+	return nil
+}
+
+// Dearmor parses an armored block
+func Dearmor(text string) (*Armored, error) {
+	a := new(Armored)
+	err := a.UnmarshalText([]byte(text))
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// String returns the armored text, or an empty string if it is invalid
+func (a Armored) String() string {
+	text, err := a.MarshalText()
+	if err != nil {
+		return ""
+	}
+	return string(text)
+}
+
+// check ensures that the block has the expected type, and that its algorithm
+// header, if present, matches al
+func (a Armored) check(blockType string, al Algorithm) error {
+	if a.Type != blockType {
+		return fmt.Errorf("armor: expected %s, got %s", blockType, a.Type)
+	}
+	if name, ok := a.Headers[HeaderAlgorithm]; ok && name != NameOf(al) {
+		return fmt.Errorf("armor: algorithm header %s does not match data (%s)", name, NameOf(al))
+	}
+	return nil
+}
+
+// checkFingerprint ensures the fingerprint header, if present, matches key
+func (a Armored) checkFingerprint(key PublicKey) error {
+	if fp, ok := a.Headers[HeaderFingerprint]; ok && fp != Fingerprint(key) {
+		return errors.New("armor: fingerprint does not match key")
+	}
+	return nil
+}
+
+// PublicKey returns the armored public key
+func (a Armored) PublicKey() (*PublicKey, error) {
+	key := new(PublicKey)
+	err := key.Unmarshal(a.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling public key")
+	}
+	err = a.check(ArmorTypePublicKey, key.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	err = a.checkFingerprint(*key)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PrivateKey returns the armored private key
+func (a Armored) PrivateKey() (*PrivateKey, error) {
+	key := new(PrivateKey)
+	err := key.Unmarshal(a.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling private key")
+	}
+	err = a.check(ArmorTypePrivateKey, key.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	public, err := publicOf(*key)
+	if err != nil {
+		return nil, errors.Wrap(err, "computing public key")
+	}
+	err = a.checkFingerprint(*public)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Signature returns the armored signature
+//
+// The signer's fingerprint can't be checked without the signer's key:
+// verifying the signature with that key establishes everything that the
+// fingerprint would.
+func (a Armored) Signature() (*Signature, error) {
+	sig := new(Signature)
+	err := sig.Unmarshal(a.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling signature")
+	}
+	err = a.check(ArmorTypeSignature, sig.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
@@ -0,0 +1,175 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCRC24(t *testing.T) {
+	// the OpenPGP CRC-24 check value
+	require.Equal(t, uint32(0x21cf02), crc24([]byte("123456789")))
+	require.Equal(t, uint32(crc24Init), crc24(nil))
+}
+
+func TestArmorRoundtrip(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			sig := private.Sign([]byte("armored message"))
+
+			pubArmor, err := ArmorPublicKey(public, "test: public")
+			require.NoError(t, err)
+			pvtArmor, err := ArmorPrivateKey(private, "")
+			require.NoError(t, err)
+			sigArmor, err := ArmorSignature(sig, &public, "detached")
+			require.NoError(t, err)
+
+			for _, a := range []*Armored{pubArmor, pvtArmor, sigArmor} {
+				require.Equal(t, NameOf(al), a.Headers[HeaderAlgorithm])
+				require.Equal(t, Fingerprint(public), a.Headers[HeaderFingerprint])
+
+				text, err := a.MarshalText()
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(string(text), "-----BEGIN "+a.Type+"-----\nAlgorithm: "))
+
+				got, err := Dearmor(string(text))
+				require.NoError(t, err)
+				require.Equal(t, a, got)
+			}
+
+			gotPub, err := pubArmor.PublicKey()
+			require.NoError(t, err)
+			require.Equal(t, public.FullString(), gotPub.FullString())
+			require.Equal(t, "test: public", pubArmor.Headers[HeaderComment])
+
+			gotPvt, err := pvtArmor.PrivateKey()
+			require.NoError(t, err)
+			require.Equal(t, private.FullString(), gotPvt.FullString())
+			_, hasComment := pvtArmor.Headers[HeaderComment]
+			require.False(t, hasComment)
+
+			gotSig, err := sigArmor.Signature()
+			require.NoError(t, err)
+			require.True(t, gotSig.Verify([]byte("armored message"), public))
+
+			// the accessors check the block type
+			_, err = pubArmor.PrivateKey()
+			require.Error(t, err)
+			_, err = sigArmor.PublicKey()
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestArmorLayout(t *testing.T) {
+	a := Armored{
+		Type: ArmorTypeSignature,
+		Headers: map[string]string{
+			HeaderComment:   "c",
+			"Version":       "1",
+			HeaderAlgorithm: "a",
+			"Hash":          "h",
+		},
+		Data: make([]byte, 100),
+	}
+	text, err := a.MarshalText()
+	require.NoError(t, err)
+	lines := strings.Split(string(text), "\n")
+	require.Equal(t, []string{
+		"-----BEGIN NDAU SIGNATURE-----",
+		"Algorithm: a",
+		"Comment: c",
+		"Hash: h",
+		"Version: 1",
+		"",
+		strings.Repeat("A", 64),
+		strings.Repeat("A", 64),
+		"AAAAAA==",
+	}, lines[:9])
+	require.True(t, strings.HasPrefix(lines[9], "="))
+	require.Equal(t, "-----END NDAU SIGNATURE-----", lines[10])
+	require.Equal(t, "", lines[11])
+}
+
+func TestDearmorTolerance(t *testing.T) {
+	public, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	a, err := ArmorPublicKey(public, "")
+	require.NoError(t, err)
+	text := a.String()
+
+	// surrounding whitespace, indentation, and CRLF line endings are fine
+	messy := "\n\n  " + strings.Replace(text, "\n", "  \r\n  ", -1) + "\n"
+	got, err := Dearmor(messy)
+	require.NoError(t, err)
+	require.Equal(t, a, got)
+}
+
+func TestDearmorErrors(t *testing.T) {
+	public, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	a, err := ArmorPublicKey(public, "")
+	require.NoError(t, err)
+	text := a.String()
+	lines := strings.Split(text, "\n")
+	body := lines[4]
+
+	flipped := []byte(body)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"empty", ""},
+		{"garbage", "not armor at all"},
+		{"no end", strings.Join(lines[:len(lines)-2], "\n")},
+		{"mismatched end", strings.Replace(text, "END NDAU PUBLIC KEY", "END NDAU SIGNATURE", 1)},
+		{"no checksum", strings.Replace(text, lines[5]+"\n", "", 1)},
+		{"bad checksum", strings.Replace(text, lines[5], "=AAAA", 1)},
+		{"corrupt body", strings.Replace(text, body, string(flipped), 1)},
+		{"bad base64", strings.Replace(text, body, "!!"+body[2:], 1)},
+		{"malformed header", strings.Replace(text, "Fingerprint: ", "Fingerprint ", 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Dearmor(tt.text)
+			require.Error(t, err)
+		})
+	}
+
+	// headers which disagree with the data are rejected
+	for _, h := range []string{HeaderAlgorithm, HeaderFingerprint} {
+		t.Run("wrong "+h, func(t *testing.T) {
+			got, err := Dearmor(text)
+			require.NoError(t, err)
+			got.Headers[h] = "wrong"
+			_, err = got.PublicKey()
+			require.Error(t, err)
+		})
+	}
+
+	// and headers which can't be written unambiguously are refused
+	bad := *a
+	bad.Headers = map[string]string{"Comment": "two\nlines"}
+	_, err = bad.MarshalText()
+	require.Error(t, err)
+	require.Equal(t, "", bad.String())
+}
@@ -0,0 +1,137 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// msgp doesn't specify the order of map entries, and implementations differ:
+// Go's generated code writes struct fields in declaration order and
+// map[string] entries in random order, while JS libraries use insertion
+// order. Signatures over msgp must therefore be made over a canonical form.
+
+// canonicalMaxDepth limits the nesting of canonicalized values
+const canonicalMaxDepth = 32
+
+// Canonicalize re-encodes a single msgp value with the entries of every map,
+// at any depth, sorted by the bytes of the canonical encodings of their keys.
+//
+// String keys are therefore ordered first by length, then lexically, as in
+// canonical CBOR. Map and array headers are rewritten in their shortest
+// form; all other values, including extensions, are copied unchanged. It is
+// an error if the input is not exactly one msgp value, or if any map
+// contains duplicate keys.
+//
+// Any two implementations which encode the same data with the same scalar
+// encodings produce identical canonical bytes, whatever their map ordering.
+func Canonicalize(msgpBytes []byte) ([]byte, error) {
+	out, leftovers, err := canonicalize(nil, msgpBytes, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(leftovers) > 0 {
+		return nil, errors.New("Leftovers present after canonicalization")
+	}
+	return out, nil
+}
+
+// CanonicalMsg returns the canonical msgp serialization of m
+//
+// It is equivalent to marshalling m and calling Canonicalize on the result.
+func CanonicalMsg(m msgp.Marshaler) ([]byte, error) {
+	b, err := m.MarshalMsg(nil)
+	if err != nil {
+		return nil, err
+	}
+	return Canonicalize(b)
+}
+
+// canonicalize appends the canonical form of the first value of in to out
+func canonicalize(out, in []byte, depth int) (canonical, leftovers []byte, err error) {
+	if depth > canonicalMaxDepth {
+		return nil, nil, errors.New("msgp value nested too deeply")
+	}
+	if len(in) == 0 {
+		return nil, nil, msgp.ErrShortBytes
+	}
+
+	switch msgp.NextType(in) {
+	case msgp.MapType:
+		var sz uint32
+		sz, in, err = msgp.ReadMapHeaderBytes(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		type entry struct{ key, value []byte }
+		entries := make([]entry, 0, minUint32(sz, uint32(len(in)/2)))
+		for i := uint32(0); i < sz; i++ {
+			var e entry
+			e.key, in, err = canonicalize(nil, in, depth+1)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "map key")
+			}
+			e.value, in, err = canonicalize(nil, in, depth+1)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "map value")
+			}
+			entries = append(entries, e)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+		out = msgp.AppendMapHeader(out, sz)
+		for i, e := range entries {
+			if i > 0 && bytes.Equal(entries[i-1].key, e.key) {
+				return nil, nil, errors.New("duplicate map key")
+			}
+			out = append(out, e.key...)
+			out = append(out, e.value...)
+		}
+		return out, in, nil
+
+	case msgp.ArrayType:
+		var sz uint32
+		sz, in, err = msgp.ReadArrayHeaderBytes(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = msgp.AppendArrayHeader(out, sz)
+		for i := uint32(0); i < sz; i++ {
+			out, in, err = canonicalize(out, in, depth+1)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "array element %d", i)
+			}
+		}
+		return out, in, nil
+
+	case msgp.InvalidType:
+		return nil, nil, msgp.InvalidPrefixError(in[0])
+
+	default:
+		leftovers, err = msgp.Skip(in)
+		if err != nil {
+			return nil, nil, err
+		}
+		return append(out, in[:len(in)-len(leftovers)]...), leftovers, nil
+	}
+}
+
+func minUint32(a, b uint32) uint32 {
+	if a < b {
+		return a
+	}
+	return b
+}
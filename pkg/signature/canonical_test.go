@@ -0,0 +1,154 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// appendMap appends a map of string keys to uint values, in the given order
+func appendMap(b []byte, keys ...string) []byte {
+	b = msgp.AppendMapHeader(b, uint32(len(keys)))
+	for i, k := range keys {
+		b = msgp.AppendString(b, k)
+		b = msgp.AppendUint(b, uint(i))
+	}
+	return b
+}
+
+func TestCanonicalizeSortsMaps(t *testing.T) {
+	// the same map, with its entries in two different orders
+	m1 := msgp.AppendMapHeader(nil, 3)
+	m1 = msgp.AppendString(m1, "b")
+	m1 = msgp.AppendInt(m1, 2)
+	m1 = msgp.AppendString(m1, "aa")
+	m1 = msgp.AppendInt(m1, 3)
+	m1 = msgp.AppendString(m1, "a")
+	m1 = msgp.AppendInt(m1, 1)
+
+	m2 := msgp.AppendMapHeader(nil, 3)
+	m2 = msgp.AppendString(m2, "aa")
+	m2 = msgp.AppendInt(m2, 3)
+	m2 = msgp.AppendString(m2, "a")
+	m2 = msgp.AppendInt(m2, 1)
+	m2 = msgp.AppendString(m2, "b")
+	m2 = msgp.AppendInt(m2, 2)
+
+	c1, err := Canonicalize(m1)
+	require.NoError(t, err)
+	c2, err := Canonicalize(m2)
+	require.NoError(t, err)
+	require.Equal(t, c1, c2)
+
+	expect := msgp.AppendMapHeader(nil, 3)
+	expect = msgp.AppendString(expect, "a")
+	expect = msgp.AppendInt(expect, 1)
+	expect = msgp.AppendString(expect, "b")
+	expect = msgp.AppendInt(expect, 2)
+	expect = msgp.AppendString(expect, "aa")
+	expect = msgp.AppendInt(expect, 3)
+	require.Equal(t, expect, c1)
+
+	// canonicalization is idempotent
+	c3, err := Canonicalize(c1)
+	require.NoError(t, err)
+	require.Equal(t, c1, c3)
+}
+
+func TestCanonicalizeNested(t *testing.T) {
+	// [ {y, x}, "s", {z: {b, a}} ]
+	in := msgp.AppendArrayHeader(nil, 3)
+	in = appendMap(in, "y", "x")
+	in = msgp.AppendString(in, "s")
+	in = msgp.AppendMapHeader(in, 1)
+	in = msgp.AppendString(in, "z")
+	in = appendMap(in, "b", "a")
+
+	got, err := Canonicalize(in)
+	require.NoError(t, err)
+
+	expect := msgp.AppendArrayHeader(nil, 3)
+	expect = msgp.AppendMapHeader(expect, 2)
+	expect = msgp.AppendString(expect, "x")
+	expect = msgp.AppendUint(expect, 1)
+	expect = msgp.AppendString(expect, "y")
+	expect = msgp.AppendUint(expect, 0)
+	expect = msgp.AppendString(expect, "s")
+	expect = msgp.AppendMapHeader(expect, 1)
+	expect = msgp.AppendString(expect, "z")
+	expect = msgp.AppendMapHeader(expect, 2)
+	expect = msgp.AppendString(expect, "a")
+	expect = msgp.AppendUint(expect, 1)
+	expect = msgp.AppendString(expect, "b")
+	expect = msgp.AppendUint(expect, 0)
+	require.Equal(t, expect, got)
+}
+
+func TestCanonicalizeShortensHeaders(t *testing.T) {
+	// a map32 header for an empty map
+	got, err := Canonicalize([]byte{0xdf, 0, 0, 0, 0})
+	require.NoError(t, err)
+	require.Equal(t, msgp.AppendMapHeader(nil, 0), got)
+}
+
+func TestCanonicalizeScalars(t *testing.T) {
+	for _, in := range [][]byte{
+		msgp.AppendNil(nil),
+		msgp.AppendInt64(nil, -12345678),
+		msgp.AppendFloat64(nil, 1.5),
+		msgp.AppendBytes(nil, []byte{1, 2, 3}),
+		msgp.AppendString(nil, "ndau"),
+	} {
+		got, err := Canonicalize(in)
+		require.NoError(t, err)
+		require.Equal(t, in, got)
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	deep := []byte{}
+	for i := 0; i <= canonicalMaxDepth+1; i++ {
+		deep = msgp.AppendArrayHeader(deep, 1)
+	}
+	deep = msgp.AppendNil(deep)
+
+	for name, in := range map[string][]byte{
+		"empty":         nil,
+		"invalid":       {0xc1},
+		"leftovers":     append(msgp.AppendNil(nil), 0xc0),
+		"short map":     msgp.AppendMapHeader(nil, 2),
+		"short array":   msgp.AppendArrayHeader(nil, 1),
+		"short string":  msgp.AppendString(nil, "ndau")[:3],
+		"duplicate key": appendMap(nil, "a", "a"),
+		"too deep":      deep,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Canonicalize(in)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCanonicalMsg(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	sig := private.Sign([]byte("message"))
+	b, err := sig.MarshalMsg(nil)
+	require.NoError(t, err)
+
+	// signatures contain no maps, so are already canonical
+	got, err := CanonicalMsg(sig)
+	require.NoError(t, err)
+	require.Equal(t, b, got)
+}
@@ -0,0 +1,230 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/binary"
+	gomath "math"
+
+	"github.com/pkg/errors"
+)
+
+// This is a minimal CBOR (RFC 7049) implementation: just enough to read and
+// write COSE structures. It supports integers, byte and text strings, arrays,
+// maps, tags, and the simple values false, true, and null. Only definite
+// lengths are supported.
+
+const (
+	cborUint   byte = 0
+	cborNegInt byte = 1
+	cborBytes  byte = 2
+	cborText   byte = 3
+	cborArray  byte = 4
+	cborMap    byte = 5
+	cborTag    byte = 6
+	cborSimple byte = 7
+)
+
+// cborMaxDepth limits the nesting of decoded values
+const cborMaxDepth = 16
+
+// a cborTagged value is a tag number together with its content
+type cborTagged struct {
+	Number  uint64
+	Content interface{}
+}
+
+func cborAppendHead(out []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(out, major|byte(n))
+	case n <= gomath.MaxUint8:
+		return append(out, major|24, byte(n))
+	case n <= gomath.MaxUint16:
+		out = append(out, major|25, 0, 0)
+		binary.BigEndian.PutUint16(out[len(out)-2:], uint16(n))
+		return out
+	case n <= gomath.MaxUint32:
+		out = append(out, major|26, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(out[len(out)-4:], uint32(n))
+		return out
+	default:
+		out = append(out, major|27, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(out[len(out)-8:], n)
+		return out
+	}
+}
+
+func cborAppendInt(out []byte, n int64) []byte {
+	if n < 0 {
+		return cborAppendHead(out, cborNegInt, uint64(-(n + 1)))
+	}
+	return cborAppendHead(out, cborUint, uint64(n))
+}
+
+func cborAppendBytes(out []byte, b []byte) []byte {
+	return append(cborAppendHead(out, cborBytes, uint64(len(b))), b...)
+}
+
+func cborAppendText(out []byte, s string) []byte {
+	return append(cborAppendHead(out, cborText, uint64(len(s))), s...)
+}
+
+// cborDecode decodes a single value, which must consume all of data
+func cborDecode(data []byte) (interface{}, error) {
+	r := cborReader{data: data}
+	v, err := r.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.data) > 0 {
+		return nil, errors.New("cbor: trailing data")
+	}
+	return v, nil
+}
+
+type cborReader struct {
+	data []byte
+}
+
+func (r *cborReader) take(n uint64) ([]byte, error) {
+	if n > uint64(len(r.data)) {
+		return nil, errors.New("cbor: unexpected end of data")
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out, nil
+}
+
+func (r *cborReader) head() (major, info byte, n uint64, err error) {
+	b, err := r.take(1)
+	if err != nil {
+		return
+	}
+	major, info = b[0]>>5, b[0]&0x1f
+	var extra []byte
+	switch {
+	case info < 24:
+		n = uint64(info)
+	case info == 24:
+		extra, err = r.take(1)
+		if err == nil {
+			n = uint64(extra[0])
+		}
+	case info == 25:
+		extra, err = r.take(2)
+		if err == nil {
+			n = uint64(binary.BigEndian.Uint16(extra))
+		}
+	case info == 26:
+		extra, err = r.take(4)
+		if err == nil {
+			n = uint64(binary.BigEndian.Uint32(extra))
+		}
+	case info == 27:
+		extra, err = r.take(8)
+		if err == nil {
+			n = binary.BigEndian.Uint64(extra)
+		}
+	default:
+		err = errors.New("cbor: indefinite lengths are not supported")
+	}
+	return
+}
+
+func (r *cborReader) value(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errors.New("cbor: nested too deeply")
+	}
+	major, info, n, err := r.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUint, cborNegInt:
+		if n > gomath.MaxInt64 {
+			return nil, errors.New("cbor: integer overflows int64")
+		}
+		if major == cborNegInt {
+			return -1 - int64(n), nil
+		}
+		return int64(n), nil
+	case cborBytes:
+		b, err := r.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case cborText:
+		b, err := r.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborArray:
+		// every item takes at least one byte, which bounds the allocation
+		if n > uint64(len(r.data)) {
+			return nil, errors.New("cbor: unexpected end of data")
+		}
+		out := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := r.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, item)
+		}
+		return out, nil
+	case cborMap:
+		if n > uint64(len(r.data)) {
+			return nil, errors.New("cbor: unexpected end of data")
+		}
+		out := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			k, err := r.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, string:
+			default:
+				return nil, errors.New("cbor: map keys must be integers or text")
+			}
+			v, err := r.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := out[k]; dup {
+				return nil, errors.New("cbor: duplicate map key")
+			}
+			out[k] = v
+		}
+		return out, nil
+	case cborTag:
+		content, err := r.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return cborTagged{Number: n, Content: content}, nil
+	default: // cborSimple
+		switch info {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		default:
+			return nil, errors.New("cbor: unsupported simple or float value")
+		}
+	}
+}
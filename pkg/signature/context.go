@@ -0,0 +1,121 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+// Context-separated signatures bind a signature to the purpose for which it
+// was made, so that a signature made for one protocol can't be replayed as
+// valid in another which happens to sign the same bytes.
+//
+// Algorithms which natively support domain separation, such as Ed25519 with
+// Ed25519ctx, do so by implementing ContextAlgorithm. For all others, the
+// message actually signed is a tagged hash, as in BIP-340:
+//
+//	SHA-256(SHA-256(tag) || SHA-256(tag) || message)
+//
+// where tag is "ndau/" followed by the context name.
+
+// MaxContextLen is the greatest permitted length, in bytes, of a context name
+const MaxContextLen = 255
+
+// contextTagPrefix prefixes context names in tagged hashes
+const contextTagPrefix = "ndau/"
+
+// A ContextAlgorithm is an Algorithm which natively supports domain
+// separation
+type ContextAlgorithm interface {
+	// SignWithContext signs the message with privateKey in the given context
+	// and returns a signature, or nil if the context is unsupported
+	SignWithContext(private []byte, context string, message []byte) []byte
+	// VerifyWithContext verifies a message's signature in the given context
+	//
+	// Return true if the signature is valid
+	VerifyWithContext(public []byte, context string, message, sig []byte) bool
+}
+
+// checkContext returns an error if ctx is not a usable context name
+func checkContext(ctx string) error {
+	if ctx == "" {
+		return fmt.Errorf("context must not be empty")
+	}
+	if len(ctx) > MaxContextLen {
+		return fmt.Errorf("context must not exceed %d bytes; got %d", MaxContextLen, len(ctx))
+	}
+	return nil
+}
+
+// taggedHash returns the message to sign in ctx, for algorithms which are not
+// ContextAlgorithms
+func taggedHash(ctx string, message []byte) []byte {
+	tag := sha256.Sum256([]byte(contextTagPrefix + ctx))
+	h := sha256.New()
+	h.Write(tag[:])
+	h.Write(tag[:])
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// SignWithContext signs the supplied message in the given context
+//
+// The context name is embedded in the signature's extra data. The signature
+// verifies only with VerifyWithContext, in the same context.
+func (key PrivateKey) SignWithContext(ctx string, message []byte) (Signature, error) {
+	err := checkContext(ctx)
+	if err != nil {
+		return Signature{}, err
+	}
+	al := key.Algorithm()
+	var data []byte
+	if cal, ok := al.(ContextAlgorithm); ok {
+		data = cal.SignWithContext(key.key, ctx, message)
+		if data == nil {
+			return Signature{}, fmt.Errorf("%s could not sign in context %q", NameOf(al), ctx)
+		}
+	} else {
+		data = al.Sign(key.key, taggedHash(ctx, message))
+	}
+	return Signature{
+		algorithm: al,
+		data:      data,
+		extra:     []byte(ctx),
+	}, nil
+}
+
+// VerifyWithContext verifies the supplied message with the given signature,
+// which must have been made in the given context
+func (key PublicKey) VerifyWithContext(ctx string, message []byte, sig Signature) bool {
+	if checkContext(ctx) != nil || sig.Context() != ctx {
+		return false
+	}
+	if NameOf(key.Algorithm()) != NameOf(sig.algorithm) {
+		return false
+	}
+	al := key.Algorithm()
+	if cal, ok := al.(ContextAlgorithm); ok {
+		return cal.VerifyWithContext(key.key, ctx, message, sig.data)
+	}
+	return al.Verify(key.key, taggedHash(ctx, message), sig.data)
+}
+
+// VerifyWithContext is a convenience function to verify from a signature
+func (signature Signature) VerifyWithContext(ctx string, message []byte, key PublicKey) bool {
+	return key.VerifyWithContext(ctx, message, signature)
+}
+
+// Context returns the name of the context in which the signature was made,
+// or an empty string if it was made without one
+func (signature Signature) Context() string {
+	return string(signature.extra)
+}
@@ -0,0 +1,108 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	stded25519 "crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextRoundtrip(t *testing.T) {
+	msg := []byte("ownership challenge")
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+
+			sig, err := private.SignWithContext("ndau-test", msg)
+			require.NoError(t, err)
+			require.Equal(t, "ndau-test", sig.Context())
+			require.True(t, public.VerifyWithContext("ndau-test", msg, sig))
+			require.True(t, sig.VerifyWithContext("ndau-test", msg, public))
+
+			require.False(t, public.VerifyWithContext("ndau-other", msg, sig))
+			require.False(t, public.VerifyWithContext("ndau-test", []byte("something else"), sig))
+
+			// context and plain signatures are never interchangeable
+			require.False(t, public.Verify(msg, sig))
+			plain := private.Sign(msg)
+			require.Equal(t, "", plain.Context())
+			require.False(t, public.VerifyWithContext("ndau-test", msg, plain))
+			require.False(t, public.VerifyWithContext("", msg, plain))
+
+			// the context survives serialization
+			bytes, err := sig.Marshal()
+			require.NoError(t, err)
+			var sig2 Signature
+			require.NoError(t, sig2.Unmarshal(bytes))
+			require.Equal(t, "ndau-test", sig2.Context())
+			require.True(t, public.VerifyWithContext("ndau-test", msg, sig2))
+
+			msgp, err := sig.MarshalMsg(nil)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(msgp), sig.Msgsize())
+			var sig3 Signature
+			leftovers, err := sig3.UnmarshalMsg(msgp)
+			require.NoError(t, err)
+			require.Empty(t, leftovers)
+			require.True(t, public.VerifyWithContext("ndau-test", msg, sig3))
+
+			text, err := sig.MarshalText()
+			require.NoError(t, err)
+			var sig4 Signature
+			require.NoError(t, sig4.UnmarshalText(text))
+			require.True(t, public.VerifyWithContext("ndau-test", msg, sig4))
+		})
+	}
+}
+
+func TestContextPlainSerializationUnchanged(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	sig := private.Sign([]byte("message"))
+
+	bytes, err := sig.Marshal()
+	require.NoError(t, err)
+	expect, err := marshal(Ed25519, sig.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, expect, bytes)
+}
+
+func TestContextEd25519ctx(t *testing.T) {
+	public, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	msg := []byte("message")
+	sig, err := private.SignWithContext("ndau-test", msg)
+	require.NoError(t, err)
+
+	// the signature is a standard Ed25519ctx signature
+	err = stded25519.VerifyWithOptions(
+		stded25519.PublicKey(public.KeyBytes()), msg, sig.Bytes(),
+		&stded25519.Options{Context: "ndau-test"},
+	)
+	require.NoError(t, err)
+}
+
+func TestContextInvalid(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	msg := []byte("message")
+
+	_, err = private.SignWithContext("", msg)
+	require.Error(t, err)
+	_, err = private.SignWithContext(strings.Repeat("x", MaxContextLen+1), msg)
+	require.Error(t, err)
+	_, err = private.SignWithContext(strings.Repeat("x", MaxContextLen), msg)
+	require.NoError(t, err)
+}
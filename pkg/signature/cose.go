@@ -0,0 +1,211 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// COSE_Sign1 messages, per RFC 8152.
+//
+// Algorithm identifiers come from RFC 8152 (EdDSA) and RFC 8812 (ES256K).
+//
+// As with JWS, a COSE signature covers its protected header, so SignCOSE signs
+// the COSE Sig_structure directly rather than converting an existing Signature.
+
+const (
+	coseTagSign1 = 18
+
+	coseHeaderAlg = 1
+	coseHeaderKid = 4
+
+	coseAlgEdDSA  = -8
+	coseAlgES256K = -47
+)
+
+func coseAlg(al Algorithm) (int64, error) {
+	switch {
+	case SameAlgorithm(al, Ed25519):
+		return coseAlgEdDSA, nil
+	case SameAlgorithm(al, Secp256k1):
+		return coseAlgES256K, nil
+	default:
+		return 0, fmt.Errorf("algorithm %s has no COSE equivalent", NameOf(al))
+	}
+}
+
+func coseAlgorithm(alg int64) (Algorithm, error) {
+	switch alg {
+	case coseAlgEdDSA:
+		return Ed25519, nil
+	case coseAlgES256K:
+		return Secp256k1, nil
+	default:
+		return nil, fmt.Errorf("unsupported COSE algorithm %d", alg)
+	}
+}
+
+// coseSigStructure builds the bytes which are actually signed
+func coseSigStructure(protected, payload []byte) []byte {
+	out := cborAppendHead(nil, cborArray, 4)
+	out = cborAppendText(out, "Signature1")
+	out = cborAppendBytes(out, protected)
+	out = cborAppendBytes(out, nil) // external_aad
+	return cborAppendBytes(out, payload)
+}
+
+// SignCOSE signs payload, returning a tagged COSE_Sign1 message.
+//
+// The unprotected header's kid is the signer's public key in its ndau text
+// form.
+func SignCOSE(key PrivateKey, payload []byte) ([]byte, error) {
+	alg, err := coseAlg(key.Algorithm())
+	if err != nil {
+		return nil, err
+	}
+	public, err := publicOf(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "computing public key")
+	}
+	kid, err := public.MarshalText()
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling public key")
+	}
+
+	protected := cborAppendHead(nil, cborMap, 1)
+	protected = cborAppendInt(protected, coseHeaderAlg)
+	protected = cborAppendInt(protected, alg)
+
+	sig, err := foreignSignature(key.Sign(coseSigStructure(protected, payload)))
+	if err != nil {
+		return nil, err
+	}
+
+	out := cborAppendHead(nil, cborTag, coseTagSign1)
+	out = cborAppendHead(out, cborArray, 4)
+	out = cborAppendBytes(out, protected)
+	out = cborAppendHead(out, cborMap, 1)
+	out = cborAppendInt(out, coseHeaderKid)
+	out = cborAppendBytes(out, kid)
+	out = cborAppendBytes(out, payload)
+	return cborAppendBytes(out, sig), nil
+}
+
+// A COSESign1 is a parsed COSE_Sign1 message
+type COSESign1 struct {
+	Payload   []byte
+	Signature Signature
+	// PublicKey is the key the kid header claims signed this message, if any.
+	//
+	// It is not authenticated: anyone can claim any key. Callers should
+	// Verify against a key they already trust.
+	PublicKey *PublicKey
+
+	protected []byte
+}
+
+// ParseCOSE parses a COSE_Sign1 message, tagged or untagged.
+//
+// It does not verify the signature; use COSESign1.Verify for that.
+// Messages with detached payloads are not supported.
+func ParseCOSE(data []byte) (*COSESign1, error) {
+	v, err := cborDecode(data)
+	if err != nil {
+		return nil, err
+	}
+	if tagged, ok := v.(cborTagged); ok {
+		if tagged.Number != coseTagSign1 {
+			return nil, fmt.Errorf("unexpected CBOR tag %d", tagged.Number)
+		}
+		v = tagged.Content
+	}
+	msg, ok := v.([]interface{})
+	if !ok || len(msg) != 4 {
+		return nil, errors.New("COSE_Sign1 must be an array of 4 items")
+	}
+	protected, ok := msg[0].([]byte)
+	if !ok {
+		return nil, errors.New("protected header must be a byte string")
+	}
+	unprotected, ok := msg[1].(map[interface{}]interface{})
+	if !ok {
+		return nil, errors.New("unprotected header must be a map")
+	}
+	payload, ok := msg[2].([]byte)
+	if !ok {
+		return nil, errors.New("payload must be an attached byte string")
+	}
+	sigdata, ok := msg[3].([]byte)
+	if !ok {
+		return nil, errors.New("signature must be a byte string")
+	}
+
+	headers := make(map[interface{}]interface{})
+	if len(protected) > 0 {
+		ph, err := cborDecode(protected)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding protected header")
+		}
+		phm, ok := ph.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New("protected header must encode a map")
+		}
+		for k, v := range phm {
+			headers[k] = v
+		}
+	}
+	for k, v := range unprotected {
+		if _, dup := headers[k]; dup {
+			return nil, errors.New("header appears in both protected and unprotected buckets")
+		}
+		headers[k] = v
+	}
+	if _, unprotectedAlg := unprotected[int64(coseHeaderAlg)]; unprotectedAlg {
+		return nil, errors.New("alg must be protected")
+	}
+
+	alg, ok := headers[int64(coseHeaderAlg)].(int64)
+	if !ok {
+		return nil, errors.New("missing or non-integer alg header")
+	}
+	al, err := coseAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := nativeSignature(al, sigdata)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting signature")
+	}
+
+	c := COSESign1{
+		Payload:   payload,
+		Signature: *sig,
+		protected: protected,
+	}
+	if kid, ok := headers[int64(coseHeaderKid)].([]byte); ok {
+		// other signers are free to use kid for their own purposes, so
+		// only use it if it's an ndau key
+		if pub, err := ParsePublicKey(string(kid)); err == nil {
+			if !SameAlgorithm(pub.Algorithm(), al) {
+				return nil, errors.New("kid algorithm does not match alg")
+			}
+			c.PublicKey = pub
+		}
+	}
+	return &c, nil
+}
+
+// Verify returns true if the message was signed by key
+func (c COSESign1) Verify(key PublicKey) bool {
+	return c.Signature.Verify(coseSigStructure(c.protected, c.Payload), key)
+}
@@ -0,0 +1,124 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCOSERoundtrip(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			payload := []byte("This is the content.")
+
+			msg, err := SignCOSE(private, payload)
+			require.NoError(t, err)
+			// tag 18
+			require.Equal(t, byte(0xd2), msg[0])
+
+			c, err := ParseCOSE(msg)
+			require.NoError(t, err)
+			require.Equal(t, payload, c.Payload)
+			require.NotNil(t, c.PublicKey)
+			require.True(t, keysEqual(public, *c.PublicKey))
+			require.True(t, c.Verify(public))
+
+			other, _, err := Generate(al, nil)
+			require.NoError(t, err)
+			require.False(t, c.Verify(other))
+
+			// untagged messages are also accepted
+			untagged, err := ParseCOSE(msg[1:])
+			require.NoError(t, err)
+			require.True(t, untagged.Verify(public))
+
+			c.Payload = []byte("This is other content.")
+			require.False(t, c.Verify(public))
+		})
+	}
+}
+
+func TestCOSEEncoding(t *testing.T) {
+	// check the headers against hand-encoded CBOR
+	_, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	msg, err := SignCOSE(private, []byte{})
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0xd2,                         // tag 18
+		0x84,                         // array(4)
+		0x44, 0xa1, 0x01, 0x38, 0x2e, // bstr(4): {1: -47}
+		0xa1, 0x04, // map(1), key 4
+	}, msg[:9])
+}
+
+func TestParseCOSEErrors(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	msg, err := SignCOSE(private, []byte("payload"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"truncated", msg[:len(msg)-1]},
+		{"trailing data", append(append([]byte{}, msg...), 0)},
+		{"wrong tag", append([]byte{0xd1}, msg[1:]...)},
+		{"not an array", []byte{0xa0}},
+		{"short array", []byte{0x83, 0x40, 0xa0, 0x40}},
+		{"missing alg", []byte{0x84, 0x40, 0xa0, 0x40, 0x40}},
+		{"unprotected alg", []byte{0x84, 0x40, 0xa1, 0x01, 0x27, 0x40, 0x40}},
+		{"unsupported alg", []byte{0x84, 0x43, 0xa1, 0x01, 0x26, 0xa0, 0x40, 0x40}},
+		{"detached payload", []byte{0x84, 0x43, 0xa1, 0x01, 0x27, 0xa0, 0xf6, 0x40}},
+		{"bad signature length", []byte{0x84, 0x43, 0xa1, 0x01, 0x27, 0xa0, 0x40, 0x41, 0x00}},
+		{"indefinite length", []byte{0x9f, 0xff}},
+		{"huge array", []byte{0x9b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}},
+		{"deep nesting", []byte{0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x81, 0x80}},
+		{"float", []byte{0xf9, 0x00, 0x00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseCOSE(tt.data)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCBORInts(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want []byte
+	}{
+		{0, []byte{0x00}},
+		{23, []byte{0x17}},
+		{24, []byte{0x18, 0x18}},
+		{1000, []byte{0x19, 0x03, 0xe8}},
+		{1000000, []byte{0x1a, 0x00, 0x0f, 0x42, 0x40}},
+		{1000000000000, []byte{0x1b, 0x00, 0x00, 0x00, 0xe8, 0xd4, 0xa5, 0x10, 0x00}},
+		{-1, []byte{0x20}},
+		{-8, []byte{0x27}},
+		{-47, []byte{0x38, 0x2e}},
+		{-1000, []byte{0x39, 0x03, 0xe7}},
+	}
+	for _, tt := range tests {
+		got := cborAppendInt(nil, tt.n)
+		require.Equal(t, tt.want, got, "encoding %d", tt.n)
+		v, err := cborDecode(got)
+		require.NoError(t, err)
+		require.Equal(t, tt.n, v)
+	}
+}
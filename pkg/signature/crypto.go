@@ -0,0 +1,193 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/pkg/errors"
+)
+
+// PrivateKey.Sign signs whole messages, so a PrivateKey can't itself be a
+// crypto.Signer, which signs digests. Instead, these functions convert our keys
+// to and from the standard library's types, and those of btcec, so that they
+// can be used with TLS client authentication, x509 certificate requests, and
+// the like. As with the ecdsa conversions, extra data is dropped on the way
+// out, and must be passed back in to be preserved.
+//
+// Note that the standard library's x509 and tls packages don't support
+// secp256k1; only ed25519 keys are of use there.
+
+// Signer returns the key as a crypto.Signer: a crypto/ed25519.PrivateKey
+// for an ed25519 key, or an *ecdsa.PrivateKey for a secp256k1 key
+//
+// The signer's Public method returns the key which CryptoPublicKey returns for
+// the matching PublicKey. Extra data is not preserved.
+func (key PrivateKey) Signer() (crypto.Signer, error) {
+	switch {
+	case SameAlgorithm(key.Algorithm(), Ed25519):
+		return ToEd25519(&key)
+	case SameAlgorithm(key.Algorithm(), Secp256k1):
+		return ToECDSA(&key)
+	default:
+		return nil, fmt.Errorf("algorithm %s has no crypto.Signer", NameOf(key.Algorithm()))
+	}
+}
+
+// CryptoPublicKey returns the key as a crypto.PublicKey: a
+// crypto/ed25519.PublicKey for an ed25519 key, or an *ecdsa.PublicKey for a
+// secp256k1 key
+//
+// Extra data is not preserved.
+func (key PublicKey) CryptoPublicKey() (crypto.PublicKey, error) {
+	switch {
+	case SameAlgorithm(key.Algorithm(), Ed25519):
+		return ToEd25519Public(&key)
+	case SameAlgorithm(key.Algorithm(), Secp256k1):
+		return ToECDSAPublic(&key)
+	default:
+		return nil, fmt.Errorf("algorithm %s has no crypto.PublicKey", NameOf(key.Algorithm()))
+	}
+}
+
+// FromCryptoPrivateKey converts a crypto/ed25519.PrivateKey, an
+// *ecdsa.PrivateKey on secp256k1, or a *btcec.PrivateKey into a PrivateKey
+//
+// The key takes the given extra data, which may be nil.
+func FromCryptoPrivateKey(priv crypto.PrivateKey, extra []byte) (*PrivateKey, error) {
+	switch k := priv.(type) {
+	case stded25519.PrivateKey:
+		return FromEd25519(k, extra)
+	case *ecdsa.PrivateKey:
+		return FromECDSA(k, extra)
+	case *btcec.PrivateKey:
+		return FromBTCEC(k, extra)
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", priv)
+	}
+}
+
+// FromCryptoPublicKey converts a crypto/ed25519.PublicKey, an
+// *ecdsa.PublicKey on secp256k1, or a *btcec.PublicKey into a PublicKey
+//
+// The key takes the given extra data, which may be nil.
+func FromCryptoPublicKey(pub crypto.PublicKey, extra []byte) (*PublicKey, error) {
+	switch k := pub.(type) {
+	case stded25519.PublicKey:
+		return FromEd25519Public(k, extra)
+	case *ecdsa.PublicKey:
+		return FromECDSAPublic(k, extra)
+	case *btcec.PublicKey:
+		return FromBTCECPublic(k, extra)
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+func requireEd25519(al Algorithm) error {
+	if !SameAlgorithm(al, Ed25519) {
+		return fmt.Errorf("algorithm %s is not ed25519", NameOf(al))
+	}
+	return nil
+}
+
+// ToEd25519 converts an ed25519 private key into a crypto/ed25519.PrivateKey
+//
+// Extra data is not preserved.
+func ToEd25519(priv *PrivateKey) (stded25519.PrivateKey, error) {
+	if priv == nil {
+		return nil, errors.New("nil private key")
+	}
+	if err := requireEd25519(priv.Algorithm()); err != nil {
+		return nil, err
+	}
+	return append(stded25519.PrivateKey(nil), priv.key...), nil
+}
+
+// FromEd25519 converts a crypto/ed25519.PrivateKey into a PrivateKey
+//
+// The key takes the given extra data, which may be nil.
+func FromEd25519(priv stded25519.PrivateKey, extra []byte) (*PrivateKey, error) {
+	if len(priv) != stded25519.PrivateKeySize {
+		return nil, fmt.Errorf("ed25519 private key must be %d bytes", stded25519.PrivateKeySize)
+	}
+	return RawPrivateKey(Ed25519, append([]byte(nil), priv...), copyExtra(extra))
+}
+
+// ToEd25519Public converts an ed25519 public key into a
+// crypto/ed25519.PublicKey
+//
+// Extra data is not preserved.
+func ToEd25519Public(pub *PublicKey) (stded25519.PublicKey, error) {
+	if pub == nil {
+		return nil, errors.New("nil public key")
+	}
+	if err := requireEd25519(pub.Algorithm()); err != nil {
+		return nil, err
+	}
+	return append(stded25519.PublicKey(nil), pub.key...), nil
+}
+
+// FromEd25519Public converts a crypto/ed25519.PublicKey into a PublicKey
+//
+// The key takes the given extra data, which may be nil.
+func FromEd25519Public(pub stded25519.PublicKey, extra []byte) (*PublicKey, error) {
+	if len(pub) != stded25519.PublicKeySize {
+		return nil, fmt.Errorf("ed25519 public key must be %d bytes", stded25519.PublicKeySize)
+	}
+	return RawPublicKey(Ed25519, append([]byte(nil), pub...), copyExtra(extra))
+}
+
+// ToBTCEC converts a secp256k1 private key into a btcec.PrivateKey
+//
+// Extra data is not preserved.
+func ToBTCEC(priv *PrivateKey) (*btcec.PrivateKey, error) {
+	ecPriv, err := ToECDSA(priv)
+	if err != nil {
+		return nil, err
+	}
+	return (*btcec.PrivateKey)(ecPriv), nil
+}
+
+// FromBTCEC converts a btcec.PrivateKey into a PrivateKey
+//
+// The key takes the given extra data, which may be nil.
+func FromBTCEC(priv *btcec.PrivateKey, extra []byte) (*PrivateKey, error) {
+	if priv == nil {
+		return nil, errors.New("nil private key")
+	}
+	return FromECDSA(priv.ToECDSA(), extra)
+}
+
+// ToBTCECPublic converts a secp256k1 public key into a btcec.PublicKey
+//
+// Extra data is not preserved.
+func ToBTCECPublic(pub *PublicKey) (*btcec.PublicKey, error) {
+	ecPub, err := ToECDSAPublic(pub)
+	if err != nil {
+		return nil, err
+	}
+	return (*btcec.PublicKey)(ecPub), nil
+}
+
+// FromBTCECPublic converts a btcec.PublicKey into a PublicKey
+//
+// The key takes the given extra data, which may be nil.
+func FromBTCECPublic(pub *btcec.PublicKey, extra []byte) (*PublicKey, error) {
+	if pub == nil {
+		return nil, errors.New("nil public key")
+	}
+	return FromECDSAPublic(pub.ToECDSA(), extra)
+}
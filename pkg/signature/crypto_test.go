@@ -0,0 +1,179 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	stded25519 "crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerEd25519(t *testing.T) {
+	public, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	message := []byte("interoperable message")
+
+	signer, err := private.Signer()
+	require.NoError(t, err)
+	cryptoPub, err := public.CryptoPublicKey()
+	require.NoError(t, err)
+	require.Equal(t, cryptoPub, signer.Public())
+
+	// ed25519 signs whole messages, so the signer's signature is native
+	sigBytes, err := signer.Sign(rand.Reader, message, crypto.Hash(0))
+	require.NoError(t, err)
+	sig, err := RawSignature(Ed25519, sigBytes)
+	require.NoError(t, err)
+	require.True(t, public.Verify(message, *sig))
+	native := private.Sign(message)
+	require.True(t, stded25519.Verify(cryptoPub.(stded25519.PublicKey), message, native.Bytes()))
+
+	// the signer can sign certificate requests
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "ndau node"},
+	}, signer)
+	require.NoError(t, err)
+	req, err := x509.ParseCertificateRequest(csr)
+	require.NoError(t, err)
+	require.NoError(t, req.CheckSignature())
+	fromCSR, err := FromCryptoPublicKey(req.PublicKey, nil)
+	require.NoError(t, err)
+	require.Equal(t, public.FullString(), fromCSR.FullString())
+}
+
+func TestSignerSecp256k1(t *testing.T) {
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	message := []byte("interoperable message")
+	digest := sha256.Sum256(message)
+
+	signer, err := private.Signer()
+	require.NoError(t, err)
+	cryptoPub, err := public.CryptoPublicKey()
+	require.NoError(t, err)
+	require.Equal(t, cryptoPub, signer.Public())
+
+	// secp256k1 signs the sha256 digest of the message, in DER
+	sigBytes, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	require.NoError(t, err)
+	sig, err := RawSignature(Secp256k1, sigBytes)
+	require.NoError(t, err)
+	require.True(t, public.Verify(message, *sig))
+	native := private.Sign(message)
+	require.True(t, ecdsa.VerifyASN1(cryptoPub.(*ecdsa.PublicKey), digest[:], native.Bytes()))
+}
+
+func TestCryptoRoundtrip(t *testing.T) {
+	extra := bytes.Repeat([]byte{0xcc}, 40)
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			withExtra, err := RawPrivateKey(al, private.KeyBytes(), extra)
+			require.NoError(t, err)
+
+			signer, err := withExtra.Signer()
+			require.NoError(t, err)
+			restored, err := FromCryptoPrivateKey(signer, withExtra.ExtraBytes())
+			require.NoError(t, err)
+			require.Equal(t, withExtra.FullString(), restored.FullString())
+			bare, err := FromCryptoPrivateKey(signer, nil)
+			require.NoError(t, err)
+			require.Equal(t, private.FullString(), bare.FullString())
+
+			cryptoPub, err := public.CryptoPublicKey()
+			require.NoError(t, err)
+			restoredPub, err := FromCryptoPublicKey(cryptoPub, nil)
+			require.NoError(t, err)
+			require.Equal(t, public.FullString(), restoredPub.FullString())
+		})
+	}
+
+	// the converted key doesn't alias ours
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	stdPriv, err := ToEd25519(&private)
+	require.NoError(t, err)
+	stdPriv[0]++
+	require.NotEqual(t, []byte(stdPriv), private.KeyBytes())
+}
+
+func TestBTCECRoundtrip(t *testing.T) {
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	message := []byte("interoperable message")
+	digest := sha256.Sum256(message)
+
+	btcPriv, err := ToBTCEC(&private)
+	require.NoError(t, err)
+	btcPub, err := ToBTCECPublic(&public)
+	require.NoError(t, err)
+	require.Equal(t, public.KeyBytes(), btcPub.SerializeCompressed())
+
+	btcSig, err := btcPriv.Sign(digest[:])
+	require.NoError(t, err)
+	sig, err := RawSignature(Secp256k1, btcSig.Serialize())
+	require.NoError(t, err)
+	require.True(t, public.Verify(message, *sig))
+
+	priv2, err := FromBTCEC(btcPriv, nil)
+	require.NoError(t, err)
+	require.Equal(t, private.FullString(), priv2.FullString())
+	pub2, err := FromCryptoPublicKey(btcPub, nil)
+	require.NoError(t, err)
+	require.Equal(t, public.FullString(), pub2.FullString())
+}
+
+func TestCryptoWrongAlgorithm(t *testing.T) {
+	edPublic, edPrivate, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	secpPublic, secpPrivate, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+
+	_, err = ToEd25519(&secpPrivate)
+	require.Error(t, err)
+	_, err = ToEd25519Public(&secpPublic)
+	require.Error(t, err)
+	_, err = ToBTCEC(&edPrivate)
+	require.Error(t, err)
+	_, err = ToBTCECPublic(&edPublic)
+	require.Error(t, err)
+	_, err = ToEd25519(nil)
+	require.Error(t, err)
+	_, err = FromBTCEC(nil, nil)
+	require.Error(t, err)
+	_, err = FromEd25519(stded25519.PrivateKey{1, 2, 3}, nil)
+	require.Error(t, err)
+	_, err = FromEd25519Public(stded25519.PublicKey{1, 2, 3}, nil)
+	require.Error(t, err)
+	_, err = FromCryptoPrivateKey("not a key", nil)
+	require.Error(t, err)
+	_, err = FromCryptoPublicKey(&btcec.PrivateKey{}, nil)
+	require.Error(t, err)
+
+	nullPublic, err := RawPublicKey(Null, nil, nil)
+	require.NoError(t, err)
+	nullPrivate, err := RawPrivateKey(Null, nil, nil)
+	require.NoError(t, err)
+	_, err = nullPrivate.Signer()
+	require.Error(t, err)
+	_, err = nullPublic.CryptoPublicKey()
+	require.Error(t, err)
+}
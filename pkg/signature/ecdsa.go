@@ -0,0 +1,92 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
+	"github.com/pkg/errors"
+)
+
+// Secp256k1 is also the curve used by Ethereum, so tooling built around the
+// standard library's ecdsa types can use our keys directly. These functions
+// convert between the two.
+//
+// ecdsa keys have no room for the extra data that our keys may carry; keys
+// derived from extended keys use it for their HD chain data. Converting to
+// ecdsa drops it. To convert back without losing it, pass the original key's
+// ExtraBytes to FromECDSA or FromECDSAPublic; pass nil for a key with no
+// extra data.
+
+func requireSecp256k1(al Algorithm) error {
+	if !SameAlgorithm(al, Secp256k1) {
+		return fmt.Errorf("algorithm %s is not secp256k1", NameOf(al))
+	}
+	return nil
+}
+
+// ToECDSA converts a secp256k1 private key into an ecdsa.PrivateKey
+//
+// Extra data is not preserved.
+func ToECDSA(priv *PrivateKey) (*ecdsa.PrivateKey, error) {
+	if priv == nil {
+		return nil, errors.New("nil private key")
+	}
+	if err := requireSecp256k1(priv.Algorithm()); err != nil {
+		return nil, err
+	}
+	return secp256k1.ToECDSA(priv.key)
+}
+
+// FromECDSA converts an ecdsa.PrivateKey on secp256k1 into a PrivateKey
+//
+// The key takes the given extra data, which may be nil.
+func FromECDSA(priv *ecdsa.PrivateKey, extra []byte) (*PrivateKey, error) {
+	key, err := secp256k1.FromECDSA(priv)
+	if err != nil {
+		return nil, err
+	}
+	return RawPrivateKey(Secp256k1, key, copyExtra(extra))
+}
+
+// ToECDSAPublic converts a secp256k1 public key into an ecdsa.PublicKey
+//
+// Extra data is not preserved.
+func ToECDSAPublic(pub *PublicKey) (*ecdsa.PublicKey, error) {
+	if pub == nil {
+		return nil, errors.New("nil public key")
+	}
+	if err := requireSecp256k1(pub.Algorithm()); err != nil {
+		return nil, err
+	}
+	return secp256k1.ToECDSAPublic(pub.key)
+}
+
+// FromECDSAPublic converts an ecdsa.PublicKey on secp256k1 into a PublicKey
+//
+// The key takes the given extra data, which may be nil.
+func FromECDSAPublic(pub *ecdsa.PublicKey, extra []byte) (*PublicKey, error) {
+	key, err := secp256k1.FromECDSAPublic(pub)
+	if err != nil {
+		return nil, err
+	}
+	return RawPublicKey(Secp256k1, key, copyExtra(extra))
+}
+
+// copyExtra copies extra data so that the new key does not alias the caller's
+func copyExtra(extra []byte) []byte {
+	if len(extra) == 0 {
+		return nil
+	}
+	return append([]byte(nil), extra...)
+}
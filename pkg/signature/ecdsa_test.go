@@ -0,0 +1,113 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/stretchr/testify/require"
+)
+
+func TestECDSARoundtrip(t *testing.T) {
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	message := []byte("interoperable message")
+	digest := sha256.Sum256(message)
+
+	ecPriv, err := ToECDSA(&private)
+	require.NoError(t, err)
+	ecPub, err := ToECDSAPublic(&public)
+	require.NoError(t, err)
+	require.Equal(t, ecPriv.PublicKey.X, ecPub.X)
+	require.Equal(t, ecPriv.PublicKey.Y, ecPub.Y)
+
+	// a native signature verifies with the standard library
+	nativeSig := private.Sign(message)
+	native, err := btcec.ParseDERSignature(nativeSig.Bytes(), btcec.S256())
+	require.NoError(t, err)
+	require.True(t, ecdsa.Verify(ecPub, digest[:], native.R, native.S))
+
+	// and a standard library signature verifies natively
+	r, s, err := ecdsa.Sign(rand.Reader, ecPriv, digest[:])
+	require.NoError(t, err)
+	sig, err := RawSignature(Secp256k1, (&btcec.Signature{R: r, S: s}).Serialize())
+	require.NoError(t, err)
+	require.True(t, public.Verify(message, *sig))
+
+	priv2, err := FromECDSA(ecPriv, private.ExtraBytes())
+	require.NoError(t, err)
+	require.Equal(t, private.FullString(), priv2.FullString())
+	pub2, err := FromECDSAPublic(ecPub, public.ExtraBytes())
+	require.NoError(t, err)
+	require.Equal(t, public.FullString(), pub2.FullString())
+}
+
+func TestECDSAExtraPreservation(t *testing.T) {
+	_, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	extra := bytes.Repeat([]byte{0xcc}, 40)
+	withExtra, err := RawPrivateKey(Secp256k1, private.KeyBytes(), extra)
+	require.NoError(t, err)
+
+	ecPriv, err := ToECDSA(withExtra)
+	require.NoError(t, err)
+
+	// extra data survives exactly when it is passed back in
+	restored, err := FromECDSA(ecPriv, withExtra.ExtraBytes())
+	require.NoError(t, err)
+	require.Equal(t, withExtra.FullString(), restored.FullString())
+	require.Equal(t, extra, restored.ExtraBytes())
+
+	bare, err := FromECDSA(ecPriv, nil)
+	require.NoError(t, err)
+	require.Empty(t, bare.ExtraBytes())
+	require.Equal(t, withExtra.KeyBytes(), bare.KeyBytes())
+
+	// the restored key does not alias the caller's buffer
+	extra[0] = 0
+	require.Equal(t, byte(0xcc), restored.ExtraBytes()[0])
+}
+
+func TestECDSAErrors(t *testing.T) {
+	edPublic, edPrivate, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	_, err = ToECDSA(&edPrivate)
+	require.Error(t, err)
+	_, err = ToECDSAPublic(&edPublic)
+	require.Error(t, err)
+	_, err = ToECDSA(nil)
+	require.Error(t, err)
+	_, err = ToECDSAPublic(nil)
+	require.Error(t, err)
+
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, err = FromECDSA(p256, nil)
+	require.Error(t, err)
+	_, err = FromECDSAPublic(&p256.PublicKey, nil)
+	require.Error(t, err)
+	_, err = FromECDSA(nil, nil)
+	require.Error(t, err)
+	_, err = FromECDSAPublic(nil, nil)
+	require.Error(t, err)
+
+	// a point which is not on the curve
+	offCurve := ecdsa.PublicKey{Curve: btcec.S256(), X: big.NewInt(1), Y: big.NewInt(1)}
+	_, err = FromECDSAPublic(&offCurve, nil)
+	require.Error(t, err)
+}
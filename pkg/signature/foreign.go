@@ -0,0 +1,54 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
+	"github.com/pkg/errors"
+)
+
+// This file contains conversions shared by the JOSE and COSE formats, both of
+// which represent signatures differently from our native algorithms.
+
+// publicOf computes the public key matching a private key
+func publicOf(key PrivateKey) (*PublicKey, error) {
+	al := key.Algorithm()
+	if al == nil {
+		return nil, errors.New("private key has no algorithm")
+	}
+	return RawPublicKey(al, al.Public(key.KeyBytes()), nil)
+}
+
+// foreignSignature converts a signature into the form used by JOSE and COSE
+func foreignSignature(sig Signature) ([]byte, error) {
+	switch {
+	case SameAlgorithm(sig.Algorithm(), Ed25519):
+		return sig.data, nil
+	case SameAlgorithm(sig.Algorithm(), Secp256k1):
+		return secp256k1.CompactFromDER(sig.data)
+	default:
+		return nil, fmt.Errorf("algorithm %s has no foreign signature form", NameOf(sig.Algorithm()))
+	}
+}
+
+// nativeSignature converts a signature from the form used by JOSE and COSE
+func nativeSignature(al Algorithm, data []byte) (*Signature, error) {
+	if SameAlgorithm(al, Secp256k1) {
+		var err error
+		data, err = secp256k1.DERFromCompact(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return RawSignature(al, data)
+}
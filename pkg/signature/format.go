@@ -0,0 +1,107 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// These are the versions of the binary serialization of keys and signatures.
+//
+// A v0 serialization is a bare msgp array, as written by Marshal. Every later
+// version prefixes the serialization with its version number, in a single
+// byte. As a msgp array never begins with a byte from 0x01 to 0x7f, the
+// version of any serialization can be told from its first byte.
+//
+// All versions are read, but Marshal continues to write v0, so that the
+// serializations of keys and signatures already stored, and anything hashed
+// over them, are unchanged. Use ToFormat to write another version.
+const (
+	// FormatV0 is the original, unversioned serialization
+	FormatV0 uint8 = 0
+	// FormatV1 is a version byte, followed by a v0 serialization
+	FormatV1 uint8 = 1
+
+	// LatestFormat is the most recent version which this package can read
+	LatestFormat = FormatV1
+
+	// maxFormatVersion is the greatest version which fits in the prefix byte
+	maxFormatVersion = 0x7f
+)
+
+// FormatVersion returns the version of the serialization of a key or
+// signature.
+//
+// It returns an error if the data is not a serialization of any version,
+// or if it is of a version more recent than LatestFormat.
+func FormatVersion(data []byte) (uint8, error) {
+	if len(data) == 0 {
+		return 0, errors.New("empty serialization")
+	}
+	if msgp.NextType(data) == msgp.ArrayType {
+		return FormatV0, nil
+	}
+	version := data[0]
+	if version == 0 || version > maxFormatVersion {
+		return 0, fmt.Errorf("unrecognized serialization: begins with 0x%02x", version)
+	}
+	if version > LatestFormat {
+		return 0, fmt.Errorf("unsupported serialization format version %d", version)
+	}
+	return version, nil
+}
+
+// stripFormatVersion returns the v0 serialization within data
+func stripFormatVersion(data []byte) ([]byte, error) {
+	version, err := FormatVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == FormatV0 {
+		return data, nil
+	}
+	return data[1:], nil
+}
+
+// ToFormat converts the serialization of a key or signature to the given
+// version.
+//
+// The data may be of any version which FormatVersion recognizes.
+func ToFormat(data []byte, version uint8) ([]byte, error) {
+	if version > LatestFormat {
+		return nil, fmt.Errorf("unsupported serialization format version %d", version)
+	}
+	v0, err := stripFormatVersion(data)
+	if err != nil {
+		return nil, err
+	}
+	if version == FormatV0 {
+		return v0, nil
+	}
+	return append([]byte{version}, v0...), nil
+}
+
+// requireTextFormat returns an error unless data is a v0 serialization.
+//
+// Text forms are always of v0, so that each key or signature has exactly one.
+func requireTextFormat(data []byte) error {
+	version, err := FormatVersion(data)
+	if err != nil {
+		return err
+	}
+	if version != FormatV0 {
+		return fmt.Errorf("text form must use serialization format v0; got v%d", version)
+	}
+	return nil
+}
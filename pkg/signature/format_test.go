@@ -0,0 +1,128 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/stretchr/testify/require"
+)
+
+// toV1 returns the v1 serialization of m
+func toV1(t *testing.T, m interface{ Marshal() ([]byte, error) }) []byte {
+	v0, err := m.Marshal()
+	require.NoError(t, err)
+	version, err := FormatVersion(v0)
+	require.NoError(t, err)
+	require.Equal(t, FormatV0, version)
+
+	v1, err := ToFormat(v0, FormatV1)
+	require.NoError(t, err)
+	require.Equal(t, append([]byte{FormatV1}, v0...), v1)
+	version, err = FormatVersion(v1)
+	require.NoError(t, err)
+	require.Equal(t, FormatV1, version)
+
+	back, err := ToFormat(v1, FormatV0)
+	require.NoError(t, err)
+	require.Equal(t, v0, back)
+	return v1
+}
+
+func requireSameSignature(t *testing.T, want, got Signature) {
+	require.True(t, SameAlgorithm(want.Algorithm(), got.Algorithm()))
+	require.Equal(t, want.Bytes(), got.Bytes())
+	require.Equal(t, want.Context(), got.Context())
+}
+
+func TestFormatV1(t *testing.T) {
+	message := []byte("message")
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+
+			var pk PublicKey
+			require.NoError(t, pk.Unmarshal(toV1(t, public)))
+			require.Equal(t, public.KeyBytes(), pk.KeyBytes())
+
+			var sk PrivateKey
+			require.NoError(t, sk.Unmarshal(toV1(t, private)))
+			require.Equal(t, private.KeyBytes(), sk.KeyBytes())
+
+			ctxSig, err := private.SignWithContext("ctx", message)
+			require.NoError(t, err)
+			for _, sig := range []Signature{private.Sign(message), ctxSig} {
+				var got Signature
+				require.NoError(t, got.Unmarshal(toV1(t, sig)))
+				requireSameSignature(t, sig, got)
+
+				// embedded in a larger msgp stream, only v0 is accepted, so
+				// that the stream has exactly one encoding
+				_, err := new(Signature).UnmarshalMsg(append(toV1(t, sig), 0xc0))
+				require.Error(t, err)
+				v0, err := sig.MarshalMsg(nil)
+				require.NoError(t, err)
+				leftover, err := got.UnmarshalMsg(append(v0, 0xc0))
+				require.NoError(t, err)
+				require.Equal(t, []byte{0xc0}, leftover)
+				requireSameSignature(t, sig, got)
+			}
+
+			// and likewise for keys
+			_, err = new(PublicKey).UnmarshalMsg(toV1(t, public))
+			require.Error(t, err)
+			_, err = new(PrivateKey).UnmarshalMsg(toV1(t, private))
+			require.Error(t, err)
+			v0, err := public.MarshalMsg(nil)
+			require.NoError(t, err)
+			leftover, err := pk.UnmarshalMsg(append(v0, 0xc0))
+			require.NoError(t, err)
+			require.Equal(t, []byte{0xc0}, leftover)
+			require.Equal(t, public.KeyBytes(), pk.KeyBytes())
+
+			// text forms require v0
+			text := b32.Encode(AddChecksum(toV1(t, public)))
+			var tk PublicKey
+			require.Error(t, tk.UnmarshalText([]byte(PublicKeyPrefix+text)))
+			sig := private.Sign(message)
+			text = b32.Encode(AddChecksum(toV1(t, sig)))
+			require.Error(t, new(Signature).UnmarshalText([]byte(text)))
+		})
+	}
+}
+
+func TestFormatVersionErrors(t *testing.T) {
+	public, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	v0, err := public.Marshal()
+	require.NoError(t, err)
+
+	for name, data := range map[string][]byte{
+		"empty":       nil,
+		"not msgp":    append([]byte{0xc0}, v0...),
+		"future":      append([]byte{LatestFormat + 1}, v0...),
+		"out of band": append([]byte{0x80}, v0...),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := FormatVersion(data)
+			require.Error(t, err)
+			_, err = ToFormat(data, FormatV0)
+			require.Error(t, err)
+			var pk PublicKey
+			require.Error(t, pk.Unmarshal(data))
+		})
+	}
+
+	_, err = ToFormat(v0, LatestFormat+1)
+	require.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// hybridSigLenSize is the size of the length prefix of a hybrid signature
+const hybridSigLenSize = 2
+
+// A Hybrid is an Algorithm composed of two other Algorithms.
+//
+// Its keys are the concatenation of a key of each; its signatures contain a
+// signature by each, and both must verify for the hybrid signature to
+// verify. This means that a hybrid signature cannot be forged unless both
+// component algorithms are broken.
+//
+// The intended use is to pair a classical algorithm with a candidate
+// post-quantum one, such as Ed25519 with Dilithium2, so that long-lived keys
+// have a migration path which does not depend on the soundness of the new
+// algorithm alone. No post-quantum algorithm is built into this package;
+// one must be supplied as an Algorithm.
+//
+// A Hybrid must be registered with RegisterAlgorithm before its keys and
+// signatures can be serialized. Hybrids are identified by their components,
+// not by their type, so hybrids of different components may be registered
+// independently.
+//
+// Hybrid keys may exceed the capacity of a single byte length prefix, so
+// they are serialized with a two byte length prefix.
+//
+// A hybrid signature is the big-endian uint16 length of the First signature,
+// followed by the First signature, followed by the Second signature.
+type Hybrid struct {
+	First  Algorithm
+	Second Algorithm
+}
+
+// static assertions
+var _ Algorithm = (*Hybrid)(nil)
+var _ keyLengthPrefixer = (*Hybrid)(nil)
+
+// NewHybrid creates a Hybrid of the given Algorithms
+func NewHybrid(first, second Algorithm) Hybrid {
+	return Hybrid{First: first, Second: second}
+}
+
+// hybridIdentity distinguishes Hybrids in the algorithm registry
+type hybridIdentity struct {
+	first, second interface{}
+}
+
+func (h Hybrid) identity() interface{} {
+	return hybridIdentity{identityOf(h.First), identityOf(h.Second)}
+}
+
+// defaultName is the name of an unregistered Hybrid
+func (h Hybrid) defaultName() string {
+	return NameOf(h.First) + "+" + NameOf(h.Second)
+}
+
+// KeyLengthPrefixSize is the size in bytes of the length prefix of
+// serialized hybrid keys
+func (Hybrid) KeyLengthPrefixSize() int {
+	return 2
+}
+
+// PublicKeySize is the size in bytes of this algorithm's public keys
+func (h Hybrid) PublicKeySize() int {
+	return h.First.PublicKeySize() + h.Second.PublicKeySize()
+}
+
+// PrivateKeySize is the size in bytes of this algorithm's private keys
+func (h Hybrid) PrivateKeySize() int {
+	return h.First.PrivateKeySize() + h.Second.PrivateKeySize()
+}
+
+// SignatureSize is the size in bytes of this algorithm's signatures
+//
+// If either component has variably-sized signatures, so does the hybrid.
+func (h Hybrid) SignatureSize() int {
+	s1 := h.First.SignatureSize()
+	s2 := h.Second.SignatureSize()
+	if s1 < 0 || s2 < 0 {
+		return -1
+	}
+	return hybridSigLenSize + s1 + s2
+}
+
+// splitPrivate returns the component private keys, or false if the
+// private key is the wrong size
+func (h Hybrid) splitPrivate(private []byte) ([]byte, []byte, bool) {
+	if len(private) != h.PrivateKeySize() {
+		return nil, nil, false
+	}
+	split := h.First.PrivateKeySize()
+	return private[:split], private[split:], true
+}
+
+// splitPublic returns the component public keys, or false if the public
+// key is the wrong size
+func (h Hybrid) splitPublic(public []byte) ([]byte, []byte, bool) {
+	if len(public) != h.PublicKeySize() {
+		return nil, nil, false
+	}
+	split := h.First.PublicKeySize()
+	return public[:split], public[split:], true
+}
+
+// Public generates a public key when given a private key
+func (h Hybrid) Public(private []byte) []byte {
+	p1, p2, ok := h.splitPrivate(private)
+	if !ok {
+		return nil
+	}
+	return concat(h.First.Public(p1), h.Second.Public(p2))
+}
+
+// Generate creates a new keypair
+//
+// Each component keypair is generated in turn from rand.
+func (h Hybrid) Generate(rand io.Reader) (public, private []byte, err error) {
+	pub1, priv1, err := h.First.Generate(rand)
+	if err != nil {
+		return
+	}
+	pub2, priv2, err := h.Second.Generate(rand)
+	if err != nil {
+		return
+	}
+	return concat(pub1, pub2), concat(priv1, priv2), nil
+}
+
+// Sign signs the message with privateKey and returns a signature
+func (h Hybrid) Sign(private, message []byte) []byte {
+	p1, p2, ok := h.splitPrivate(private)
+	if !ok {
+		return nil
+	}
+	s1 := h.First.Sign(p1, message)
+	s2 := h.Second.Sign(p2, message)
+	if len(s1) > 0xffff {
+		return nil
+	}
+	prefix := make([]byte, hybridSigLenSize)
+	binary.BigEndian.PutUint16(prefix, uint16(len(s1)))
+	return concat(prefix, s1, s2)
+}
+
+// Verify verifies a message's signature
+//
+// Return true if both component signatures are valid
+func (h Hybrid) Verify(public, message, sig []byte) bool {
+	p1, p2, ok := h.splitPublic(public)
+	if !ok || len(sig) < hybridSigLenSize {
+		return false
+	}
+	split := hybridSigLenSize + int(binary.BigEndian.Uint16(sig))
+	if len(sig) < split {
+		return false
+	}
+	return h.First.Verify(p1, message, sig[hybridSigLenSize:split]) &&
+		h.Second.Verify(p2, message, sig[split:])
+}
+
+func concat(parts ...[]byte) []byte {
+	var size int
+	for _, part := range parts {
+		size += len(part)
+	}
+	out := make([]byte, 0, size)
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHybrid(t *testing.T) {
+	// exercise hybrids with one fixed-size and one variably-sized component,
+	// and one wide enough to need a two byte key length prefix
+	wide := NewHybrid(NewHybrid(Ed25519, Ed25519), NewHybrid(Ed25519, Ed25519))
+	require.True(t, wide.PrivateKeySize() > 0xff)
+
+	hybrids := []struct {
+		id   AlgorithmID
+		name string
+		al   Hybrid
+	}{
+		{210, "ed25519+secp256k1", NewHybrid(Ed25519, Secp256k1)},
+		{211, "ed25519x4", wide},
+	}
+	for _, h := range hybrids {
+		t.Run(h.name, func(t *testing.T) {
+			require.NoError(t, RegisterAlgorithm(h.id, h.name, h.al))
+			require.Equal(t, h.name, NameOf(h.al))
+
+			public, private, err := Generate(h.al, nil)
+			require.NoError(t, err)
+			require.Len(t, public.KeyBytes(), h.al.PublicKeySize())
+			require.Len(t, private.KeyBytes(), h.al.PrivateKeySize())
+			require.Equal(t, public.KeyBytes(), h.al.Public(private.KeyBytes()))
+
+			msg := []byte("endowment transfer")
+			sig := private.Sign(msg)
+			require.True(t, public.Verify(msg, sig))
+			require.False(t, public.Verify([]byte("something else"), sig))
+
+			// the keys and signature survive serialization
+			pubText, err := public.MarshalText()
+			require.NoError(t, err)
+			var public2 PublicKey
+			require.NoError(t, public2.UnmarshalText(pubText))
+			require.Equal(t, public.KeyBytes(), public2.KeyBytes())
+			require.Equal(t, h.name, NameOf(public2.Algorithm()))
+
+			privText, err := private.MarshalText()
+			require.NoError(t, err)
+			var private2 PrivateKey
+			require.NoError(t, private2.UnmarshalText(privText))
+			require.Equal(t, private.KeyBytes(), private2.KeyBytes())
+
+			sigText, err := sig.MarshalText()
+			require.NoError(t, err)
+			var sig2 Signature
+			require.NoError(t, sig2.UnmarshalText(sigText))
+			require.True(t, public2.Verify(msg, sig2))
+			require.True(t, public2.Verify(msg, private2.Sign(msg)))
+		})
+	}
+}
+
+func TestHybridRequiresBoth(t *testing.T) {
+	h := NewHybrid(Ed25519, Secp256k1)
+	public, private, err := h.Generate(nil)
+	require.NoError(t, err)
+	msg := []byte("message")
+	sig := h.Sign(private, msg)
+	require.True(t, h.Verify(public, msg, sig))
+
+	split := hybridSigLenSize + Ed25519.SignatureSize()
+	require.Equal(t, []byte{0, byte(Ed25519.SignatureSize())}, sig[:hybridSigLenSize])
+
+	// corrupting either component signature invalidates the whole
+	for _, ix := range []int{hybridSigLenSize, split + 5} {
+		bad := append([]byte{}, sig...)
+		bad[ix] ^= 0x01
+		require.False(t, h.Verify(public, msg, bad))
+	}
+
+	// a valid signature from only one component does not suffice
+	ed := Ed25519.Sign(private[:Ed25519.PrivateKeySize()], msg)
+	require.False(t, h.Verify(public, msg, ed))
+	require.False(t, h.Verify(public, msg, sig[:split]))
+
+	// malformed inputs are rejected rather than panicking
+	require.False(t, h.Verify(public, msg, nil))
+	require.False(t, h.Verify(public, msg, []byte{0xff, 0xff, 0}))
+	require.False(t, h.Verify(public[1:], msg, sig))
+	require.Nil(t, h.Sign(private[1:], msg))
+	require.Nil(t, h.Public(private[1:]))
+}
+
+func TestHybridIdentity(t *testing.T) {
+	// hybrids are distinguished by components, not type
+	a := NewHybrid(Ed25519, Null)
+	b := NewHybrid(Null, Ed25519)
+	require.NoError(t, RegisterAlgorithm(220, "ed25519+null", a))
+	require.NoError(t, RegisterAlgorithm(221, "null+ed25519", b))
+	require.Error(t, RegisterAlgorithm(222, "again", a))
+	require.Equal(t, "ed25519+null", NameOf(&a))
+	require.False(t, SameAlgorithm(a, b))
+	require.True(t, SameAlgorithm(a, NewHybrid(Ed25519, Null)))
+
+	// unregistered hybrids have a descriptive name but cannot be serialized
+	c := NewHybrid(Secp256k1, Secp256k1)
+	require.Equal(t, "secp256k1+secp256k1", NameOf(c))
+	_, err := idOf(c)
+	require.Error(t, err)
+}
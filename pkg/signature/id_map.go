@@ -13,8 +13,10 @@ package signature
 import (
 	"fmt"
 	"reflect"
+	"sync"
 
 	"github.com/ndau/ndaumath/pkg/signature/algorithms/ed25519"
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/ed25519ph"
 	"github.com/ndau/ndaumath/pkg/signature/algorithms/null"
 	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
 	"github.com/pkg/errors"
@@ -22,61 +24,159 @@ import (
 
 // re-export package-native algorithms
 var (
-	Ed25519   = ed25519.Ed25519
-	Secp256k1 = secp256k1.Secp256k1
-	Null      = null.Null
+	Ed25519              = ed25519.Ed25519
+	Secp256k1            = secp256k1.Secp256k1
+	Secp256k1Recoverable = secp256k1.Secp256k1Recoverable
+	Null                 = null.Null
+	Ed25519ph            = ed25519ph.Ed25519ph
 )
 
-var idMap map[AlgorithmID]Algorithm
-var idNameMap map[string]AlgorithmID
+// ed25519phID is reserved for Ed25519ph, which is not registered by default
+//
+// A node must not accept keys or signatures of a new algorithm until the
+// network has decided to activate it, so registering one is an explicit
+// step. ID 5 is likewise reserved for Ed448, which has no implementation yet.
+const ed25519phID = AlgorithmID(4)
+
+// ActivateEd25519ph registers Ed25519ph under its reserved ID, so that its
+// keys and signatures can be serialized and parsed.
+//
+// Chain code calls this once the network has activated Ed25519ph; tools may
+// call it at startup. Repeated calls are harmless.
+func ActivateEd25519ph() error {
+	return register(ed25519phID, "ed25519ph", Ed25519ph)
+}
+
+// builtinLimit is the first algorithm ID available for external registration.
+//
+// All IDs below it are reserved for canonical implementations.
+const builtinLimit = AlgorithmID(128)
+
+// the registry is read on every (de)serialization, but written only at
+// registration time, which is normally during init
+var (
+	registryLock sync.RWMutex
+	idMap        map[AlgorithmID]Algorithm
+	idNameMap    map[string]AlgorithmID
+	// identityNameMap maps the identity of each algorithm to its name
+	identityNameMap map[interface{}]string
+)
 
 func init() {
-	idMap = map[AlgorithmID]Algorithm{
-		AlgorithmID(0): Null,
-		AlgorithmID(1): Ed25519,
-		AlgorithmID(2): Secp256k1,
+	idMap = make(map[AlgorithmID]Algorithm)
+	idNameMap = make(map[string]AlgorithmID)
+	identityNameMap = make(map[interface{}]string)
+
+	for _, builtin := range []struct {
+		id   AlgorithmID
+		name string
+		al   Algorithm
+	}{
+		{0, "null", Null},
+		{1, "ed25519", Ed25519},
+		{2, "secp256k1", Secp256k1},
+		{3, "secp256k1-recoverable", Secp256k1Recoverable},
+	} {
+		err := register(builtin.id, builtin.name, builtin.al)
+		if err != nil {
+			panic(err)
+		}
 	}
-	buildNameMap()
 }
 
 // RegisterAlgorithm makes it possible to serialize and deserialize custom Algorithms
 //
-// If you build a custom Algorithm, you probably want to call this in an init function
+// If you build a custom Algorithm, you probably want to call this in an init function.
 // All IDs < 128 are reserved for canonical implementations.
-func RegisterAlgorithm(id AlgorithmID, al Algorithm) error {
-	if id < AlgorithmID(128) {
-		return fmt.Errorf("Reserved algorithm id %d < 128", id)
-	}
-	if existing, exists := idMap[id]; exists {
-		existingName := NameOf(existing)
-		if existingName != NameOf(al) {
-			return fmt.Errorf("ID %d already in use by %s", id, existingName)
-		}
+//
+// The name is returned by NameOf for this algorithm. It must not be empty.
+//
+// It is an error if the ID, the name, or the algorithm is already registered
+// to something else. Algorithms are identified by their type, except for
+// Hybrids, which are identified by their components. Repeating an identical registration is
+// harmless.
+func RegisterAlgorithm(id AlgorithmID, name string, al Algorithm) error {
+	if id < builtinLimit {
+		return fmt.Errorf("Reserved algorithm id %d < %d", id, builtinLimit)
+	}
+	return register(id, name, al)
+}
+
+// register an algorithm, checking for collisions
+func register(id AlgorithmID, name string, al Algorithm) error {
+	if al == nil {
+		return errors.New("cannot register nil algorithm")
+	}
+	if name == "" {
+		return errors.New("algorithm name must not be empty")
+	}
+	ident := identityOf(al)
+
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	existing, idExists := idMap[id]
+	if idExists && identityOf(existing) != ident {
+		return fmt.Errorf("ID %d already in use by %s", id, identityNameMap[identityOf(existing)])
+	}
+	if existingID, nameExists := idNameMap[name]; nameExists && existingID != id {
+		return fmt.Errorf("name %s already in use by ID %d", name, existingID)
+	}
+	if existingName, identExists := identityNameMap[ident]; identExists && existingName != name {
+		return fmt.Errorf("algorithm already registered as %s", existingName)
+	}
+	if idExists {
+		// an identical registration: the checks above ensure that the ID,
+		// name, and identity all agree
+		return nil
 	}
 
 	idMap[id] = al
-	buildNameMap()
+	idNameMap[name] = id
+	identityNameMap[ident] = name
 	return nil
 }
 
-// idNameMap is always the inverse of IdMap
-func buildNameMap() {
-	var id AlgorithmID
-	var example Algorithm
-	idNameMap = make(map[string]AlgorithmID)
-	for id, example = range idMap {
-		idNameMap[reflect.TypeOf(example).Name()] = id
+// algorithmType returns the underlying concrete type of an Algorithm
+func algorithmType(al Algorithm) reflect.Type {
+	ty := reflect.TypeOf(al)
+	// arbitrary-depth dereference
+	for ty.Kind() == reflect.Interface || ty.Kind() == reflect.Ptr {
+		ty = ty.Elem()
 	}
+	return ty
+}
+
+// identityOf returns a comparable value which distinguishes an Algorithm
+// from all others
+func identityOf(al Algorithm) interface{} {
+	switch h := al.(type) {
+	case Hybrid:
+		return h.identity()
+	case *Hybrid:
+		return h.identity()
+	}
+	return algorithmType(al)
 }
 
 // NameOf returns the name of an Algorithm
+//
+// Registered algorithms have the name with which they were registered.
+// Otherwise, the name of the algorithm's type is used.
 func NameOf(al Algorithm) string {
-	ty := reflect.TypeOf(al)
-	// arbitrary-depth dereference
-	for ty.Kind() == reflect.Interface || ty.Kind() == reflect.Ptr {
-		ty = ty.Elem()
+	registryLock.RLock()
+	name, ok := identityNameMap[identityOf(al)]
+	registryLock.RUnlock()
+	if ok {
+		return name
+	}
+	switch h := al.(type) {
+	case Hybrid:
+		return h.defaultName()
+	case *Hybrid:
+		return h.defaultName()
 	}
-	return ty.Name()
+	return algorithmType(al).Name()
 }
 
 // Get the ID associated with an Algorithm type
@@ -89,9 +189,22 @@ func idOf(al Algorithm) (AlgorithmID, error) {
 	if len(alName) == 0 {
 		return 0, errors.New("anonymous types are not Algorithms")
 	}
+	registryLock.RLock()
 	id, hasID := idNameMap[alName]
+	registryLock.RUnlock()
 	if hasID {
 		return id, nil
 	}
 	return 0, fmt.Errorf("Supplied algorithm `%s` not in `idMap`", alName)
 }
+
+// Get a fresh instance of the Algorithm associated with an ID
+func algorithmOf(id AlgorithmID) (Algorithm, error) {
+	registryLock.RLock()
+	al, ok := idMap[id]
+	registryLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm id %d", id)
+	}
+	return cloneAl(al), nil
+}
@@ -0,0 +1,122 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// toyAlgorithm is an insecure Algorithm whose public and private keys are
+// identical; it exists only to exercise external registration
+type toyAlgorithm struct{}
+
+func (toyAlgorithm) PublicKeySize() int  { return 32 }
+func (toyAlgorithm) PrivateKeySize() int { return 32 }
+func (toyAlgorithm) SignatureSize() int  { return sha256.Size }
+
+func (toyAlgorithm) Public(private []byte) []byte {
+	return append([]byte{}, private...)
+}
+
+func (toyAlgorithm) Generate(rand io.Reader) (public, private []byte, err error) {
+	private = make([]byte, 32)
+	_, err = io.ReadFull(rand, private)
+	return append([]byte{}, private...), private, err
+}
+
+func (toyAlgorithm) Sign(private, message []byte) []byte {
+	sum := sha256.Sum256(append(append([]byte{}, private...), message...))
+	return sum[:]
+}
+
+func (a toyAlgorithm) Verify(public, message, sig []byte) bool {
+	return bytes.Equal(a.Sign(public, message), sig)
+}
+
+// otherToyAlgorithm is distinct from toyAlgorithm only in its type
+type otherToyAlgorithm struct{ toyAlgorithm }
+
+func TestRegisterAlgorithm(t *testing.T) {
+	const id = AlgorithmID(200)
+	err := RegisterAlgorithm(id, "toy", toyAlgorithm{})
+	require.NoError(t, err)
+
+	// repeating the identical registration is fine
+	err = RegisterAlgorithm(id, "toy", &toyAlgorithm{})
+	require.NoError(t, err)
+
+	require.Equal(t, "toy", NameOf(toyAlgorithm{}))
+	require.Equal(t, "toy", NameOf(&toyAlgorithm{}))
+
+	// registered algorithms roundtrip through serialization
+	public, private, err := Generate(toyAlgorithm{}, bytes.NewReader(make([]byte, 32)))
+	require.NoError(t, err)
+	sig := private.Sign([]byte("message"))
+	text, err := sig.MarshalText()
+	require.NoError(t, err)
+	var sig2 Signature
+	err = sig2.UnmarshalText(text)
+	require.NoError(t, err)
+	require.Equal(t, "toy", NameOf(sig2.Algorithm()))
+	require.True(t, public.Verify([]byte("message"), sig2))
+
+	// built-in serialization is unaffected
+	id0, err := idOf(Ed25519)
+	require.NoError(t, err)
+	require.Equal(t, AlgorithmID(1), id0)
+}
+
+func TestRegisterAlgorithmCollisions(t *testing.T) {
+	const id = AlgorithmID(201)
+	require.NoError(t, RegisterAlgorithm(id, "other toy", otherToyAlgorithm{}))
+
+	tests := []struct {
+		name   string
+		id     AlgorithmID
+		alName string
+		al     Algorithm
+	}{
+		{"reserved id", 3, "fresh", toyAlgorithm{}},
+		{"builtin id", 1, "fresh", toyAlgorithm{}},
+		{"id in use", id, "fresh", toyAlgorithm{}},
+		{"name in use", 202, "other toy", toyAlgorithm{}},
+		{"builtin name", 202, "ed25519", toyAlgorithm{}},
+		{"type in use", 202, "renamed", otherToyAlgorithm{}},
+		{"builtin type", 202, "renamed", Secp256k1},
+		{"empty name", 202, "", toyAlgorithm{}},
+		{"nil", 202, "fresh", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RegisterAlgorithm(tt.id, tt.alName, tt.al)
+			require.Error(t, err)
+		})
+	}
+	// failed registrations must not have left any trace
+	_, err := algorithmOf(202)
+	require.Error(t, err)
+	require.Equal(t, "ed25519", NameOf(Ed25519))
+}
+
+func TestUnknownAlgorithmID(t *testing.T) {
+	container := IdentifiedData{Algorithm: 250, Data: []byte{1, 2, 3}}
+	data, err := container.MarshalMsg(nil)
+	require.NoError(t, err)
+	_, _, err = unmarshal(data)
+	require.Error(t, err)
+	_, _, _, err = unmarshalWithLeftovers(data)
+	require.Error(t, err)
+}
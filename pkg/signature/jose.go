@@ -0,0 +1,230 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/signature/algorithms/secp256k1"
+	"github.com/pkg/errors"
+)
+
+// JWS compact serialization, per RFC 7515.
+//
+// Algorithm names come from RFC 8037 (EdDSA) and RFC 8812 (ES256K).
+//
+// A JWS signature covers its header as well as its payload, so it is not
+// possible to convert an existing Signature over a bare message into a JWS.
+// Instead, SignJWS signs the JWS signing input directly.
+
+var b64url = base64.RawURLEncoding
+
+// jwk is a JSON Web Key, per RFC 7517, restricted to the public key types
+// we support
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+}
+
+type jwsHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	JWK *jwk   `json:"jwk,omitempty"`
+}
+
+func jwsAlg(al Algorithm) (string, error) {
+	switch {
+	case SameAlgorithm(al, Ed25519):
+		return "EdDSA", nil
+	case SameAlgorithm(al, Secp256k1):
+		return "ES256K", nil
+	default:
+		return "", fmt.Errorf("algorithm %s has no JWS equivalent", NameOf(al))
+	}
+}
+
+func jwsAlgorithm(alg string) (Algorithm, error) {
+	switch alg {
+	case "EdDSA":
+		return Ed25519, nil
+	case "ES256K":
+		return Secp256k1, nil
+	default:
+		return nil, fmt.Errorf("unsupported JWS algorithm %q", alg)
+	}
+}
+
+func toJWK(key PublicKey) (*jwk, error) {
+	switch {
+	case SameAlgorithm(key.Algorithm(), Ed25519):
+		return &jwk{Kty: "OKP", Crv: "Ed25519", X: b64url.EncodeToString(key.key)}, nil
+	case SameAlgorithm(key.Algorithm(), Secp256k1):
+		x, y, err := secp256k1.PublicPoint(key.key)
+		if err != nil {
+			return nil, err
+		}
+		return &jwk{
+			Kty: "EC",
+			Crv: "secp256k1",
+			X:   b64url.EncodeToString(x),
+			Y:   b64url.EncodeToString(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("algorithm %s has no JWK equivalent", NameOf(key.Algorithm()))
+	}
+}
+
+func fromJWK(j jwk) (*PublicKey, error) {
+	x, err := b64url.DecodeString(j.X)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding jwk x")
+	}
+	switch {
+	case j.Kty == "OKP" && j.Crv == "Ed25519":
+		return RawPublicKey(Ed25519, x, nil)
+	case j.Kty == "EC" && j.Crv == "secp256k1":
+		y, err := b64url.DecodeString(j.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "decoding jwk y")
+		}
+		pub, err := secp256k1.PublicFromPoint(x, y)
+		if err != nil {
+			return nil, err
+		}
+		return RawPublicKey(Secp256k1, pub, nil)
+	default:
+		return nil, fmt.Errorf("unsupported jwk: kty %q crv %q", j.Kty, j.Crv)
+	}
+}
+
+// SignJWS signs payload, returning a JWS compact serialization.
+//
+// The protected header embeds the signer's public key both as a JWK, for
+// standard tooling, and as its ndau text form in the kid field.
+func SignJWS(key PrivateKey, payload []byte) (string, error) {
+	alg, err := jwsAlg(key.Algorithm())
+	if err != nil {
+		return "", err
+	}
+	public, err := publicOf(key)
+	if err != nil {
+		return "", errors.Wrap(err, "computing public key")
+	}
+	header := jwsHeader{Alg: alg}
+	header.JWK, err = toJWK(*public)
+	if err != nil {
+		return "", err
+	}
+	header.Kid, err = public.MarshalString()
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling public key")
+	}
+	hjs, err := json.Marshal(header)
+	if err != nil {
+		return "", errors.Wrap(err, "marshalling header")
+	}
+
+	input := b64url.EncodeToString(hjs) + "." + b64url.EncodeToString(payload)
+	sig, err := foreignSignature(key.Sign([]byte(input)))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + b64url.EncodeToString(sig), nil
+}
+
+// A JWS is a parsed JWS compact serialization
+type JWS struct {
+	Payload   []byte
+	Signature Signature
+	// PublicKey is the key the header claims signed this JWS, if any.
+	//
+	// It is not authenticated: anyone can claim any key. Callers should
+	// Verify against a key they already trust.
+	PublicKey *PublicKey
+
+	signingInput []byte
+}
+
+// ParseJWS parses a JWS compact serialization.
+//
+// It does not verify the signature; use JWS.Verify for that.
+func ParseJWS(token string) (*JWS, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("JWS compact serialization must have 3 parts")
+	}
+	hjs, err := b64url.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding header")
+	}
+	var header jwsHeader
+	err = json.Unmarshal(hjs, &header)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing header")
+	}
+	al, err := jwsAlgorithm(header.Alg)
+	if err != nil {
+		return nil, err
+	}
+
+	jws := JWS{signingInput: []byte(parts[0] + "." + parts[1])}
+	jws.Payload, err = b64url.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding payload")
+	}
+	sigdata, err := b64url.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding signature")
+	}
+	sig, err := nativeSignature(al, sigdata)
+	if err != nil {
+		return nil, errors.Wrap(err, "converting signature")
+	}
+	jws.Signature = *sig
+
+	if header.JWK != nil {
+		jws.PublicKey, err = fromJWK(*header.JWK)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if header.Kid != "" {
+		// other signers are free to use kid for their own purposes, so
+		// only use it if it's an ndau key
+		if kid, err := ParsePublicKey(header.Kid); err == nil {
+			if jws.PublicKey != nil && !keysEqual(*jws.PublicKey, *kid) {
+				return nil, errors.New("jwk and kid name different keys")
+			}
+			jws.PublicKey = kid
+		}
+	}
+	if jws.PublicKey != nil && !SameAlgorithm(jws.PublicKey.Algorithm(), al) {
+		return nil, errors.New("header key algorithm does not match alg")
+	}
+	return &jws, nil
+}
+
+// Verify returns true if the JWS was signed by key
+func (jws JWS) Verify(key PublicKey) bool {
+	return jws.Signature.Verify(jws.signingInput, key)
+}
+
+// keysEqual is true if the keys have the same algorithm and key bytes
+//
+// Extra data is ignored: it isn't part of the public key proper.
+func keysEqual(a, b PublicKey) bool {
+	return SameAlgorithm(a.Algorithm(), b.Algorithm()) && string(a.key) == string(b.key)
+}
@@ -0,0 +1,137 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWSRoundtrip(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			payload := []byte(`{"hello":"world"}`)
+
+			token, err := SignJWS(private, payload)
+			require.NoError(t, err)
+			require.Equal(t, 2, strings.Count(token, "."))
+
+			jws, err := ParseJWS(token)
+			require.NoError(t, err)
+			require.Equal(t, payload, jws.Payload)
+			require.NotNil(t, jws.PublicKey)
+			require.True(t, keysEqual(public, *jws.PublicKey))
+			require.True(t, jws.Verify(public))
+
+			// a different key must not verify
+			other, _, err := Generate(al, nil)
+			require.NoError(t, err)
+			require.False(t, jws.Verify(other))
+
+			// nor may a different payload
+			parts := strings.Split(token, ".")
+			parts[1] = b64url.EncodeToString([]byte(`{"hello":"mars"}`))
+			tampered, err := ParseJWS(strings.Join(parts, "."))
+			require.NoError(t, err)
+			require.False(t, tampered.Verify(public))
+		})
+	}
+}
+
+func TestJWSHeader(t *testing.T) {
+	tests := []struct {
+		al  Algorithm
+		alg string
+		kty string
+		crv string
+	}{
+		{Ed25519, "EdDSA", "OKP", "Ed25519"},
+		{Secp256k1, "ES256K", "EC", "secp256k1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			public, private, err := Generate(tt.al, nil)
+			require.NoError(t, err)
+			token, err := SignJWS(private, []byte("payload"))
+			require.NoError(t, err)
+
+			hjs, err := b64url.DecodeString(strings.Split(token, ".")[0])
+			require.NoError(t, err)
+			var header jwsHeader
+			require.NoError(t, json.Unmarshal(hjs, &header))
+			require.Equal(t, tt.alg, header.Alg)
+			require.NotNil(t, header.JWK)
+			require.Equal(t, tt.kty, header.JWK.Kty)
+			require.Equal(t, tt.crv, header.JWK.Crv)
+			pubs, err := public.MarshalString()
+			require.NoError(t, err)
+			require.Equal(t, pubs, header.Kid)
+
+			// the signature is in the standard fixed-length form
+			sig, err := b64url.DecodeString(strings.Split(token, ".")[2])
+			require.NoError(t, err)
+			require.Len(t, sig, 64)
+		})
+	}
+}
+
+func TestJWSRFC8037Vector(t *testing.T) {
+	// RFC 8037, appendix A.4
+	x, err := b64url.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	require.NoError(t, err)
+	public, err := RawPublicKey(Ed25519, x, nil)
+	require.NoError(t, err)
+
+	token := "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc.hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+	jws, err := ParseJWS(token)
+	require.NoError(t, err)
+	require.Equal(t, "Example of Ed25519 signing", string(jws.Payload))
+	require.Nil(t, jws.PublicKey)
+	require.True(t, jws.Verify(*public))
+}
+
+func TestParseJWSErrors(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	token, err := SignJWS(private, []byte("payload"))
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	header := func(s string) string {
+		return b64url.EncodeToString([]byte(s)) + "." + parts[1] + "." + parts[2]
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"too few parts", parts[0] + "." + parts[1]},
+		{"too many parts", token + ".x"},
+		{"bad header encoding", "!!." + parts[1] + "." + parts[2]},
+		{"bad header json", header("{")},
+		{"unsupported alg", header(`{"alg":"HS256"}`)},
+		{"none alg", header(`{"alg":"none"}`)},
+		{"bad signature length", parts[0] + "." + parts[1] + "." + b64url.EncodeToString([]byte("short"))},
+		{"jwk algorithm mismatch", header(`{"alg":"ES256K","jwk":{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}}`)},
+		{"unsupported jwk", header(`{"alg":"EdDSA","jwk":{"kty":"RSA","crv":"","x":""}}`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseJWS(tt.token)
+			require.Error(t, err)
+		})
+	}
+}
@@ -35,14 +35,36 @@ type keyBase struct {
 	extra     []byte
 }
 
+// A keyLengthPrefixer is an Algorithm whose keys may be too long for the
+// default single byte length prefix of packed keys
+type keyLengthPrefixer interface {
+	// KeyLengthPrefixSize is the size in bytes of the big-endian length
+	// prefix of packed keys.
+	KeyLengthPrefixSize() int
+}
+
+// keyLengthPrefixSize returns the size of the length prefix for packed keys
+// of the given algorithm
+func keyLengthPrefixSize(al Algorithm) int {
+	if klp, ok := al.(keyLengthPrefixer); ok {
+		if size := klp.KeyLengthPrefixSize(); size > 0 && size <= 8 {
+			return size
+		}
+	}
+	return 1
+}
+
 func (key keyBase) pack() ([]byte, error) {
-	if len(key.key) > 0xff { // capacity of single byte
-		return nil, errors.New("can't pack keys of length > 0xff")
+	prefix := keyLengthPrefixSize(key.Algorithm())
+	if prefix < 8 && uint64(len(key.key)) >= uint64(1)<<uint(8*prefix) {
+		return nil, fmt.Errorf("can't pack keys of length > capacity of %d byte prefix", prefix)
 	}
-	out := make([]byte, 1+len(key.key)+len(key.extra))
-	out[0] = byte(len(key.key))
-	split := 1 + len(key.key)
-	copied := copy(out[1:split], key.key)
+	out := make([]byte, prefix+len(key.key)+len(key.extra))
+	for i := 0; i < prefix; i++ {
+		out[i] = byte(len(key.key) >> uint(8*(prefix-1-i)))
+	}
+	split := prefix + len(key.key)
+	copied := copy(out[prefix:split], key.key)
 	if copied != len(key.key) {
 		return nil, errors.New("pack: failed to copy full key data")
 	}
@@ -57,14 +79,21 @@ func (key *keyBase) unpack(data []byte) error {
 	if len(data) == 0 {
 		return nil
 	}
-	lk := int(data[0])
-	split := 1 + lk
-	if len(data) < split {
+	prefix := keyLengthPrefixSize(key.Algorithm())
+	if len(data) < prefix {
+		return errors.New("can't unpack: too few bytes")
+	}
+	var lk uint64
+	for _, b := range data[:prefix] {
+		lk = lk<<8 | uint64(b)
+	}
+	if uint64(len(data)-prefix) < lk {
 		return errors.New("can't unpack: too few bytes")
 	}
+	split := prefix + int(lk)
 	key.key = make([]byte, lk)
-	copied := copy(key.key, data[1:split])
-	if copied != lk {
+	copied := copy(key.key, data[prefix:split])
+	if copied != int(lk) {
 		return errors.New("unpack: failed to copy full key data")
 	}
 	le := len(data) - split
@@ -110,6 +139,8 @@ func (key keyBase) MarshalMsg(in []byte) (out []byte, err error) {
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
+//
+// It reads only the v0 format, which is what MarshalMsg writes.
 func (key *keyBase) UnmarshalMsg(in []byte) (leftover []byte, err error) {
 	var al Algorithm
 	var db []byte
@@ -161,6 +192,10 @@ func (key *keyBase) UnmarshalText(text []byte) error {
 	if !checksumOk {
 		return errors.New("key unmarshal failure: bad checksum")
 	}
+	err = requireTextFormat(bytes)
+	if err != nil {
+		return errors.Wrap(err, "key unmarshal failure")
+	}
 	return key.Unmarshal(bytes)
 }
 
@@ -225,11 +260,33 @@ func (key *keyBase) Truncate() {
 	key.extra = nil
 }
 
+// zeroizeHook, when set, is called by Zeroize with every buffer it has wiped,
+// before they are released. It exists so that tests can verify that the
+// buffers really are zero.
+var zeroizeHook func(buffers ...[]byte)
+
+// wipe overwrites the buffers with zeros, then calls zeroizeHook
+func wipe(buffers ...[]byte) {
+	for _, b := range buffers {
+		for i := range b {
+			b[i] = 0
+		}
+	}
+	if zeroizeHook != nil {
+		zeroizeHook(buffers...)
+	}
+}
+
 // Zeroize removes all data from this key
 //
+// The key data and extra data are overwritten with zeros before they are
+// released, so that no copy of them lingers in memory awaiting garbage
+// collection. Any slice returned by KeyBytes or ExtraBytes is zeroed too.
+//
 // This is a destructive operation which cannot be undone; make copies
 // first if you need to.
 func (key *keyBase) Zeroize() {
+	wipe(key.key, key.extra)
 	key.algorithm = nil
 	key.key = nil
 	key.extra = nil
@@ -0,0 +1,71 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"hash"
+)
+
+// A PrehashAlgorithm is an Algorithm which signs a digest of the message
+// rather than the message itself, such as Ed25519ph.
+//
+// A large message can then be signed without holding it in memory: write it
+// to the algorithm's hash as it streams past, and sign the digest with
+// SignDigest. Signing the message with Sign is equivalent.
+type PrehashAlgorithm interface {
+	// NewHash returns a new instance of the hash with which messages are
+	// digested
+	NewHash() hash.Hash
+	// SignDigest signs the digest with privateKey and returns a signature,
+	// or nil if the digest is not of the right size
+	SignDigest(private, digest []byte) []byte
+	// VerifyDigest verifies the signature of a message with the given digest
+	//
+	// Return true if the signature is valid
+	VerifyDigest(public, digest, sig []byte) bool
+}
+
+// SignDigest signs the message whose digest is supplied
+//
+// The key's algorithm must be a PrehashAlgorithm, and the digest must be
+// of its hash. The signature verifies with Verify, given the message, as
+// well as with VerifyDigest.
+func (key PrivateKey) SignDigest(digest []byte) (Signature, error) {
+	al := key.Algorithm()
+	pal, ok := al.(PrehashAlgorithm)
+	if !ok {
+		return Signature{}, fmt.Errorf("%s does not sign digests", NameOf(al))
+	}
+	data := pal.SignDigest(key.key, digest)
+	if data == nil {
+		return Signature{}, fmt.Errorf("%s could not sign a digest of %d bytes", NameOf(al), len(digest))
+	}
+	return Signature{
+		algorithm: al,
+		data:      data,
+	}, nil
+}
+
+// VerifyDigest verifies the signature of the message whose digest is
+// supplied
+//
+// It is false if the key's algorithm is not a PrehashAlgorithm.
+func (key PublicKey) VerifyDigest(digest []byte, sig Signature) bool {
+	if len(sig.extra) > 0 || NameOf(key.Algorithm()) != NameOf(sig.algorithm) {
+		return false
+	}
+	pal, ok := key.Algorithm().(PrehashAlgorithm)
+	if !ok {
+		return false
+	}
+	return pal.VerifyDigest(key.key, digest, sig.data)
+}
@@ -0,0 +1,182 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	stded25519 "crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// test vector from RFC 8032 section 7.3
+func TestEd25519phVector(t *testing.T) {
+	seed, err := hex.DecodeString("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42")
+	require.NoError(t, err)
+	private, err := RawPrivateKey(Ed25519ph, stded25519.NewKeyFromSeed(seed), nil)
+	require.NoError(t, err)
+	public, err := RawPublicKey(Ed25519ph, Ed25519ph.Public(private.KeyBytes()), nil)
+	require.NoError(t, err)
+	require.Equal(t, "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf", hex.EncodeToString(public.KeyBytes()))
+
+	const want = "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406"
+	msg := []byte("abc")
+	sig := private.Sign(msg)
+	require.Equal(t, want, hex.EncodeToString(sig.Bytes()))
+	require.True(t, public.Verify(msg, sig))
+
+	// signing the digest is equivalent
+	h := Ed25519ph.NewHash()
+	h.Write(msg)
+	digest := h.Sum(nil)
+	sig, err = private.SignDigest(digest)
+	require.NoError(t, err)
+	require.Equal(t, want, hex.EncodeToString(sig.Bytes()))
+	require.True(t, public.VerifyDigest(digest, sig))
+	require.True(t, public.Verify(msg, sig))
+	require.False(t, public.VerifyDigest(digest[1:], sig))
+}
+
+func TestPrehashStreaming(t *testing.T) {
+	public, private, err := Generate(Ed25519ph, nil)
+	require.NoError(t, err)
+
+	// a message too large to want in memory, written in pieces
+	h := Ed25519ph.NewHash()
+	chunk := []byte(strings.Repeat("ndau", 1024))
+	for i := 0; i < 256; i++ {
+		h.Write(chunk)
+	}
+	digest := h.Sum(nil)
+	sig, err := private.SignDigest(digest)
+	require.NoError(t, err)
+	require.True(t, public.VerifyDigest(digest, sig))
+	require.True(t, public.Verify([]byte(strings.Repeat(string(chunk), 256)), sig))
+
+	_, err = private.SignDigest(digest[:32])
+	require.Error(t, err)
+
+	// algorithms without pre-hashing don't sign digests
+	edPublic, edPrivate, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	_, err = edPrivate.SignDigest(digest)
+	require.Error(t, err)
+	require.False(t, edPublic.VerifyDigest(digest, sig))
+}
+
+func TestEd25519phDistinct(t *testing.T) {
+	// Ed25519ph keys are Ed25519 keys, but the signatures don't mix
+	seed := make([]byte, stded25519.SeedSize)
+	key := stded25519.NewKeyFromSeed(seed)
+	edPrivate, err := RawPrivateKey(Ed25519, key, nil)
+	require.NoError(t, err)
+	phPrivate, err := RawPrivateKey(Ed25519ph, key, nil)
+	require.NoError(t, err)
+	edPublic, err := RawPublicKey(Ed25519, Ed25519.Public(key), nil)
+	require.NoError(t, err)
+	phPublic, err := RawPublicKey(Ed25519ph, Ed25519ph.Public(key), nil)
+	require.NoError(t, err)
+
+	msg := []byte("message")
+	require.False(t, SameAlgorithm(Ed25519, Ed25519ph))
+	require.False(t, edPublic.Verify(msg, phPrivate.Sign(msg)))
+	require.False(t, phPublic.Verify(msg, edPrivate.Sign(msg)))
+	phSig := phPrivate.Sign(msg)
+	require.False(t, Ed25519.Verify(edPublic.KeyBytes(), msg, phSig.Bytes()))
+}
+
+// deactivateEd25519ph undoes ActivateEd25519ph
+func deactivateEd25519ph() {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	delete(idMap, ed25519phID)
+	delete(idNameMap, "ed25519ph")
+	delete(identityNameMap, identityOf(Ed25519ph))
+}
+
+func TestNewAlgorithmRegistration(t *testing.T) {
+	// Ed25519ph is not registered by default
+	public, private, err := Generate(Ed25519ph, nil)
+	require.NoError(t, err)
+	_, err = public.Marshal()
+	require.Error(t, err)
+	_, err = private.MarshalText()
+	require.Error(t, err)
+
+	// but it still signs and verifies
+	msg := []byte("message")
+	require.True(t, public.Verify(msg, private.Sign(msg)))
+
+	require.NoError(t, ActivateEd25519ph())
+	defer deactivateEd25519ph()
+	require.NoError(t, ActivateEd25519ph(), "activation is repeatable")
+	_, err = public.Marshal()
+	require.NoError(t, err)
+
+	// the reserved ID can't be taken by others
+	require.Error(t, RegisterAlgorithm(ed25519phID, "ed25519ph", Ed25519ph))
+}
+
+func TestNewAlgorithmSerialization(t *testing.T) {
+	require.NoError(t, ActivateEd25519ph())
+	defer deactivateEd25519ph()
+
+	tests := []struct {
+		al            Algorithm
+		publicPrefix  string
+		privatePrefix string
+	}{
+		{Ed25519ph, "9204c42120", "9204c44140"},
+	}
+	for _, tt := range tests {
+		t.Run(NameOf(tt.al), func(t *testing.T) {
+			public, private, err := Generate(tt.al, nil)
+			require.NoError(t, err)
+
+			bytes, err := public.Marshal()
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(hex.EncodeToString(bytes), tt.publicPrefix))
+			bytes, err = private.Marshal()
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(hex.EncodeToString(bytes), tt.privatePrefix))
+
+			text, err := public.MarshalText()
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(string(text), PublicKeyPrefix))
+			public2, err := ParsePublicKey(string(text))
+			require.NoError(t, err)
+			require.True(t, SameAlgorithm(tt.al, public2.Algorithm()))
+
+			text, err = private.MarshalText()
+			require.NoError(t, err)
+			private2, err := ParsePrivateKey(string(text))
+			require.NoError(t, err)
+			require.True(t, private.EqualConstantTime(*private2))
+
+			msg := []byte("message")
+			sig := private2.Sign(msg)
+			require.Len(t, sig.Bytes(), tt.al.SignatureSize())
+			text, err = sig.MarshalText()
+			require.NoError(t, err)
+			var sig2 Signature
+			require.NoError(t, sig2.UnmarshalText(text))
+			require.True(t, public2.Verify(msg, sig2))
+
+			// both support native contexts
+			sig, err = private.SignWithContext("ndau-test", msg)
+			require.NoError(t, err)
+			require.True(t, public.VerifyWithContext("ndau-test", msg, sig))
+			require.False(t, public.Verify(msg, sig))
+		})
+	}
+}
@@ -12,6 +12,7 @@ package signature
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"fmt"
 	"strings"
 
@@ -77,6 +78,21 @@ func (key PrivateKey) Sign(message []byte) Signature {
 	}
 }
 
+// EqualConstantTime is true when both keys have the same algorithm, key data,
+// and extra data.
+//
+// The time it takes depends only on the lengths of the data, never on its
+// contents, so it is safe to use to compare secret keys.
+func (key PrivateKey) EqualConstantTime(other PrivateKey) bool {
+	sameAlgorithm := 0
+	if NameOf(key.Algorithm()) == NameOf(other.Algorithm()) {
+		sameAlgorithm = 1
+	}
+	return sameAlgorithm&
+		subtle.ConstantTimeCompare(key.key, other.key)&
+		subtle.ConstantTimeCompare(key.extra, other.extra) == 1
+}
+
 // Unmarshal unmarshals the serialized bytes into the PrivateKey pointer
 func (key *PrivateKey) Unmarshal(serialized []byte) error {
 	err := key.keyBase.Unmarshal(serialized)
@@ -82,7 +82,12 @@ func (key PublicKey) Size() int {
 }
 
 // Verify the supplied message with the given signature
+//
+// Signatures made in a context never verify here: see VerifyWithContext.
 func (key PublicKey) Verify(message []byte, sig Signature) bool {
+	if len(sig.extra) > 0 {
+		return false
+	}
 	if NameOf(key.Algorithm()) != NameOf(sig.algorithm) {
 		return false
 	}
@@ -0,0 +1,94 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RecoverableSignaturePrefix always prefixes recoverable signatures in text
+// serialization, so that they can't be mistaken for ordinary signatures.
+const RecoverableSignaturePrefix = "nrsg"
+
+// isRecoverable is true when signatures of al are recoverable
+func isRecoverable(al Algorithm) bool {
+	return SameAlgorithm(al, Secp256k1Recoverable)
+}
+
+// trimRecoverablePrefix removes RecoverableSignaturePrefix from the text of
+// a signature, if present, and reports whether it was
+//
+// Like the rest of the text, the prefix is case-insensitive.
+func trimRecoverablePrefix(s string) (string, bool) {
+	lp := len(RecoverableSignaturePrefix)
+	if len(s) >= lp && strings.EqualFold(s[:lp], RecoverableSignaturePrefix) {
+		return s[lp:], true
+	}
+	return s, false
+}
+
+// SignRecoverable signs the supplied message with a secp256k1 key, producing
+// a compact signature from which the public key can be recovered
+//
+// The signature's algorithm is Secp256k1Recoverable. It verifies with the
+// key's public key only through VerifyRecoverable: PublicKey.Verify rejects
+// it, as it does any signature whose algorithm differs from the key's.
+func (key PrivateKey) SignRecoverable(message []byte) (Signature, error) {
+	if err := requireSecp256k1(key.Algorithm()); err != nil {
+		return Signature{}, err
+	}
+	return Signature{
+		algorithm: Secp256k1Recoverable,
+		data:      Secp256k1Recoverable.Sign(key.key, message),
+	}, nil
+}
+
+// VerifyRecoverable verifies a recoverable signature, as made by
+// SignRecoverable, of the message with the secp256k1 public key of its
+// signer
+//
+// Any other signature, or key, never verifies here.
+func (key PublicKey) VerifyRecoverable(message []byte, sig Signature) bool {
+	if len(sig.extra) > 0 || !isRecoverable(sig.algorithm) {
+		return false
+	}
+	if !SameAlgorithm(key.Algorithm(), Secp256k1) {
+		return false
+	}
+	return sig.algorithm.Verify(key.key, message, sig.data)
+}
+
+// VerifyRecoverable verifies a recoverable signature of the message with
+// the signer's public key: see PublicKey.VerifyRecoverable
+func (signature Signature) VerifyRecoverable(message []byte, key PublicKey) bool {
+	return key.VerifyRecoverable(message, signature)
+}
+
+// Recover returns the public key which made this signature of the message
+//
+// Only recoverable signatures, as made by SignRecoverable, can be recovered.
+// The key is a Secp256k1 public key with no extra data. Any well-formed
+// signature yields some public key, so the caller must compare the result,
+// or its address, with the expected signer's.
+func (signature Signature) Recover(message []byte) (*PublicKey, error) {
+	if !isRecoverable(signature.algorithm) {
+		return nil, fmt.Errorf("signatures of %s are not recoverable", NameOf(signature.algorithm))
+	}
+	if len(signature.extra) > 0 {
+		return nil, fmt.Errorf("signatures in a context are not recoverable")
+	}
+	public, err := Secp256k1Recoverable.Recover(message, signature.data)
+	if err != nil {
+		return nil, err
+	}
+	return RawPublicKey(Secp256k1, public, nil)
+}
@@ -0,0 +1,130 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignRecoverable(t *testing.T) {
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	other, _, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+
+	for i := 0; i < 32; i++ {
+		message := make([]byte, i*8)
+		rand.Read(message)
+
+		sig, err := private.SignRecoverable(message)
+		require.NoError(t, err)
+		require.True(t, SameAlgorithm(Secp256k1Recoverable, sig.Algorithm()))
+		require.Len(t, sig.Bytes(), Secp256k1Recoverable.SignatureSize())
+
+		require.True(t, sig.VerifyRecoverable(message, public))
+		require.False(t, sig.VerifyRecoverable(message, other))
+		require.False(t, sig.VerifyRecoverable(append(message, 0), public))
+		// ordinary verification never accepts a recoverable signature
+		require.False(t, sig.Verify(message, public))
+		require.False(t, public.Verify(message, sig))
+
+		recovered, err := sig.Recover(message)
+		require.NoError(t, err)
+		require.True(t, SameAlgorithm(Secp256k1, recovered.Algorithm()))
+		require.Equal(t, public.KeyBytes(), recovered.KeyBytes())
+
+		recovered, err = sig.Recover(append(message, 0))
+		if err == nil {
+			require.NotEqual(t, public.KeyBytes(), recovered.KeyBytes())
+		}
+	}
+}
+
+func TestSignRecoverableRequiresSecp256k1(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	_, err = private.SignRecoverable([]byte("message"))
+	require.Error(t, err)
+}
+
+func TestRecoverUnrecoverable(t *testing.T) {
+	message := []byte("message")
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+
+	_, err = private.Sign(message).Recover(message)
+	require.Error(t, err)
+	require.False(t, private.Sign(message).VerifyRecoverable(message, public))
+
+	sig, err := private.SignWithContext("ndau-test-context", message)
+	require.NoError(t, err)
+	_, err = sig.Recover(message)
+	require.Error(t, err)
+	require.False(t, sig.VerifyRecoverable(message, public))
+
+	sig, err = private.SignRecoverable(message)
+	require.NoError(t, err)
+	sig.data[0] = 27
+	_, err = sig.Recover(message)
+	require.Error(t, err)
+}
+
+func TestRecoverableSignatureText(t *testing.T) {
+	message := []byte("message")
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+
+	sig, err := private.SignRecoverable(message)
+	require.NoError(t, err)
+	text, err := sig.MarshalString()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(text, RecoverableSignaturePrefix), text)
+	require.True(t, MaybeSignature(text), text)
+	require.True(t, MaybeSignature(strings.ToUpper(text)), text)
+
+	for _, s := range []string{text, strings.ToUpper(text)} {
+		parsed, err := ParseSignature(s)
+		require.NoError(t, err)
+		require.True(t, SameAlgorithm(sig.Algorithm(), parsed.Algorithm()))
+		require.Equal(t, sig.Bytes(), parsed.Bytes())
+		require.True(t, parsed.VerifyRecoverable(message, public))
+	}
+
+	// each signature has exactly one text form
+	_, err = ParseSignature(strings.TrimPrefix(text, RecoverableSignaturePrefix))
+	require.Error(t, err)
+	unrecoverable := private.Sign(message)
+	plain, err := unrecoverable.MarshalString()
+	require.NoError(t, err)
+	_, err = ParseSignature(RecoverableSignaturePrefix + plain)
+	require.Error(t, err)
+}
+
+func TestRecoverableSignatureRoundtrip(t *testing.T) {
+	message := []byte("message")
+	_, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	sig, err := private.SignRecoverable(message)
+	require.NoError(t, err)
+
+	data, err := sig.MarshalMsg(nil)
+	require.NoError(t, err)
+	var got Signature
+	leftover, err := got.UnmarshalMsg(data)
+	require.NoError(t, err)
+	require.Empty(t, leftover)
+	require.True(t, SameAlgorithm(sig.Algorithm(), got.Algorithm()))
+	require.Equal(t, sig.Bytes(), got.Bytes())
+}
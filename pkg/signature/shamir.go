@@ -0,0 +1,288 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/b32"
+	"github.com/pkg/errors"
+)
+
+//go:generate msgp
+//msgp:tuple Share
+
+// SharePrefix always prefixes shares of private keys in text serialization
+const SharePrefix = "nshr"
+
+// ShareVersion is the version of the shares written by SplitKey.
+//
+// Shares of any other version are rejected.
+const ShareVersion uint8 = 1
+
+// MaxShares is the greatest number of shares into which a key can be split
+const MaxShares = 255
+
+var (
+	// ErrSplitParams is returned when a key can't be split as requested
+	ErrSplitParams = errors.New("invalid key split parameters")
+
+	// ErrNotEnoughShares is returned when combining fewer shares than the
+	// threshold of the split
+	ErrNotEnoughShares = errors.New("not enough shares")
+
+	// ErrMismatchedShares is returned when combining shares which are not
+	// from the same split of a key
+	ErrMismatchedShares = errors.New("shares are not from the same split")
+
+	// ErrDuplicateShare is returned when combining a share more than once
+	ErrDuplicateShare = errors.New("duplicate share")
+
+	// ErrBadShares is returned when shares combine to something other than
+	// the key which was split, because at least one of them is corrupt
+	ErrBadShares = errors.New("shares do not combine to a key")
+)
+
+// A Share is one of the shares into which SplitKey splits a private key.
+//
+// Any Threshold shares of the same split recombine to the key; fewer reveal
+// nothing about it. Shares of the same split have the same Group, which is
+// chosen at random, and distinct, non-zero Indices.
+//
+// Shares are as secret as the key itself.
+type Share struct {
+	Version   uint8
+	Group     uint32
+	Threshold uint8
+	Index     uint8
+	Data      []byte
+}
+
+// SplitKey splits a private key into n shares by Shamir's secret sharing
+// over GF(256), such that any k of them recombine to it.
+//
+// The key is split in its binary serialization, with a checksum, so its
+// algorithm and extra data are recovered with it, and CombineShares can
+// detect a corrupt share. It is an error unless 2 <= k <= n <= MaxShares.
+func SplitKey(priv *PrivateKey, n, k int) ([]Share, error) {
+	if priv == nil {
+		return nil, errors.Wrap(ErrSplitParams, "nil key")
+	}
+	if k < 2 || k > n || n > MaxShares {
+		return nil, errors.Wrapf(ErrSplitParams, "need 2 <= k <= n <= %d; have k=%d, n=%d", MaxShares, k, n)
+	}
+	serialized, err := priv.Marshal()
+	if err != nil {
+		return nil, errors.Wrap(err, "serializing key")
+	}
+	secret := AddChecksum(serialized)
+	defer wipe(serialized, secret)
+
+	var group [4]byte
+	if _, err = io.ReadFull(rand.Reader, group[:]); err != nil {
+		return nil, errors.Wrap(err, "choosing share group")
+	}
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{
+			Version:   ShareVersion,
+			Group:     binary.BigEndian.Uint32(group[:]),
+			Threshold: uint8(k),
+			Index:     uint8(i + 1),
+			Data:      make([]byte, len(secret)),
+		}
+	}
+
+	// each byte of the secret is the constant term of its own polynomial of
+	// degree k-1, whose other coefficients are random; each share holds the
+	// value of each polynomial at its index
+	coefficients := make([]byte, k)
+	defer wipe(coefficients)
+	for b, s := range secret {
+		coefficients[0] = s
+		if _, err = io.ReadFull(rand.Reader, coefficients[1:]); err != nil {
+			return nil, errors.Wrap(err, "choosing polynomial")
+		}
+		for i := range shares {
+			shares[i].Data[b] = gfEval(coefficients, shares[i].Index)
+		}
+	}
+	return shares, nil
+}
+
+// CombineShares recombines shares produced by SplitKey into the private key
+// which was split.
+//
+// At least Threshold shares of the same split are required; any more are
+// also used, and so must also be intact.
+func CombineShares(shares []Share) (*PrivateKey, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	first := shares[0]
+	if first.Version != ShareVersion {
+		return nil, errors.Wrapf(ErrMismatchedShares, "unknown share version %d", first.Version)
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, errors.Wrapf(ErrNotEnoughShares, "have %d; need %d", len(shares), first.Threshold)
+	}
+	seen := make(map[uint8]bool, len(shares))
+	for _, s := range shares {
+		if s.Version != first.Version || s.Group != first.Group ||
+			s.Threshold != first.Threshold || len(s.Data) != len(first.Data) {
+			return nil, ErrMismatchedShares
+		}
+		if s.Index == 0 {
+			return nil, errors.Wrap(ErrBadShares, "share index 0")
+		}
+		if seen[s.Index] {
+			return nil, errors.Wrapf(ErrDuplicateShare, "index %d", s.Index)
+		}
+		seen[s.Index] = true
+	}
+
+	// the Lagrange basis polynomials of the shares, evaluated at 0
+	basis := make([]byte, len(shares))
+	for j, sj := range shares {
+		basis[j] = 1
+		for m, sm := range shares {
+			if m != j {
+				basis[j] = gfMul(basis[j], gfDiv(sm.Index, sm.Index^sj.Index))
+			}
+		}
+	}
+	secret := make([]byte, len(first.Data))
+	defer wipe(secret)
+	for b := range secret {
+		for j, s := range shares {
+			secret[b] ^= gfMul(basis[j], s.Data[b])
+		}
+	}
+
+	serialized, ok := CheckChecksum(secret)
+	if !ok {
+		return nil, errors.Wrap(ErrBadShares, "bad checksum")
+	}
+	key := new(PrivateKey)
+	if err := key.Unmarshal(serialized); err != nil {
+		return nil, errors.Wrapf(ErrBadShares, "unmarshaling key: %s", err)
+	}
+	// Unmarshal copies the key data, so the secret can be wiped
+	return key, nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+//
+// Shares encode like keys: a human-readable prefix, then the b32 encoding
+// of the binary serialization with a checksum, so it's easy to tell
+// whether or not a share was received correctly.
+func (s Share) MarshalText() ([]byte, error) {
+	bytes, err := s.MarshalMsg(nil)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(SharePrefix + b32.Encode(AddChecksum(bytes))), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Share) UnmarshalText(text []byte) error {
+	str := strings.ToLower(string(text))
+	if !strings.HasPrefix(str, SharePrefix) {
+		return errors.Errorf("share must begin with %q", SharePrefix)
+	}
+	bytes, err := b32.Decode(str[len(SharePrefix):])
+	if err != nil {
+		return errors.Wrap(err, "share unmarshal failure")
+	}
+	bytes, checksumOk := CheckChecksum(bytes)
+	if !checksumOk {
+		return errors.New("share unmarshal failure: bad checksum")
+	}
+	var share Share
+	leftover, err := share.UnmarshalMsg(bytes)
+	if err != nil {
+		return errors.Wrap(err, "share unmarshal failure")
+	}
+	if len(leftover) > 0 {
+		return errors.New("share unmarshal failure: trailing data")
+	}
+	if share.Version != ShareVersion {
+		return errors.Errorf("share unmarshal failure: unknown version %d", share.Version)
+	}
+	*s = share
+	return nil
+}
+
+// ParseShare parses the text serialization of a share
+func ParseShare(s string) (*Share, error) {
+	share := new(Share)
+	err := share.UnmarshalText([]byte(s))
+	if err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// Zeroize removes all data from this share
+func (s *Share) Zeroize() {
+	if s == nil {
+		return
+	}
+	wipe(s.Data)
+	*s = Share{}
+}
+
+// Arithmetic in GF(256), modulo the AES polynomial x^8 + x^4 + x^3 + x + 1.
+//
+// Addition and subtraction are both xor. None of these functions branch on
+// or index by their arguments, so their timing reveals nothing of the
+// secret.
+
+// gfMul multiplies in GF(256)
+func gfMul(a, b byte) (product byte) {
+	for i := 0; i < 8; i++ {
+		product ^= -(b & 1) & a
+		a = a<<1 ^ (0x1b & -(a >> 7))
+		b >>= 1
+	}
+	return product
+}
+
+// gfInv returns the multiplicative inverse in GF(256): a^254, since a^255 = 1.
+//
+// The inverse of 0 is 0.
+func gfInv(a byte) byte {
+	// 254 = 0b11111110
+	out := byte(1)
+	for i := 0; i < 7; i++ {
+		a = gfMul(a, a)
+		out = gfMul(out, a)
+	}
+	return out
+}
+
+// gfDiv divides in GF(256)
+func gfDiv(a, b byte) byte {
+	return gfMul(a, gfInv(b))
+}
+
+// gfEval evaluates the polynomial with the given coefficients, lowest power
+// first, at x
+func gfEval(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return y
+}
@@ -0,0 +1,184 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGF256(t *testing.T) {
+	// the worked example of FIPS 197 section 4.2
+	require.Equal(t, byte(0xc1), gfMul(0x57, 0x83))
+	require.Equal(t, byte(0xfe), gfMul(0x57, 0x13))
+	require.Equal(t, byte(0), gfInv(0))
+	for a := 1; a < 256; a++ {
+		require.Equal(t, byte(1), gfMul(byte(a), gfInv(byte(a))), "a=%d", a)
+		require.Equal(t, byte(a), gfDiv(gfMul(byte(a), 0x35), 0x35), "a=%d", a)
+		require.Equal(t, byte(0), gfMul(byte(a), 0))
+	}
+}
+
+// subsets returns every subset of size k of the shares
+func subsets(shares []Share, k int) [][]Share {
+	if k == 0 {
+		return [][]Share{nil}
+	}
+	if len(shares) < k {
+		return nil
+	}
+	var out [][]Share
+	for _, rest := range subsets(shares[1:], k-1) {
+		out = append(out, append([]Share{shares[0]}, rest...))
+	}
+	return append(out, subsets(shares[1:], k)...)
+}
+
+func TestSplitKey(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			_, generated, err := Generate(al, nil)
+			require.NoError(t, err)
+			private, err := generated.WithUsage(UsageRecovery, time.Now())
+			require.NoError(t, err)
+
+			shares, err := SplitKey(private, 5, 3)
+			require.NoError(t, err)
+			require.Len(t, shares, 5)
+			for i, s := range shares {
+				require.Equal(t, uint8(i+1), s.Index)
+				require.Equal(t, uint8(3), s.Threshold)
+				require.Equal(t, shares[0].Group, s.Group)
+			}
+
+			for k := 3; k <= 5; k++ {
+				for _, subset := range subsets(shares, k) {
+					combined, err := CombineShares(subset)
+					require.NoError(t, err)
+					require.True(t, private.EqualConstantTime(*combined))
+				}
+			}
+			// order doesn't matter
+			combined, err := CombineShares([]Share{shares[4], shares[0], shares[2]})
+			require.NoError(t, err)
+			require.True(t, private.EqualConstantTime(*combined))
+
+			for _, subset := range subsets(shares, 2) {
+				_, err := CombineShares(subset)
+				require.Equal(t, ErrNotEnoughShares, errors.Cause(err))
+			}
+		})
+	}
+}
+
+func TestSplitKeyParams(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+
+	for _, p := range []struct{ n, k int }{{1, 1}, {5, 1}, {2, 3}, {MaxShares + 1, 2}, {0, 0}} {
+		_, err := SplitKey(&private, p.n, p.k)
+		require.Equal(t, ErrSplitParams, errors.Cause(err), "n=%d k=%d", p.n, p.k)
+	}
+	_, err = SplitKey(nil, 3, 2)
+	require.Equal(t, ErrSplitParams, errors.Cause(err))
+
+	shares, err := SplitKey(&private, MaxShares, MaxShares)
+	require.NoError(t, err)
+	combined, err := CombineShares(shares)
+	require.NoError(t, err)
+	require.True(t, private.EqualConstantTime(*combined))
+}
+
+func TestCombineSharesErrors(t *testing.T) {
+	_, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	shares, err := SplitKey(&private, 3, 2)
+	require.NoError(t, err)
+	other, err := SplitKey(&private, 3, 2)
+	require.NoError(t, err)
+
+	_, err = CombineShares(nil)
+	require.Equal(t, ErrNotEnoughShares, errors.Cause(err))
+
+	_, err = CombineShares([]Share{shares[0], shares[0]})
+	require.Equal(t, ErrDuplicateShare, errors.Cause(err))
+
+	_, err = CombineShares([]Share{shares[0], other[1]})
+	require.Equal(t, ErrMismatchedShares, errors.Cause(err))
+
+	corrupt := shares[1]
+	corrupt.Data = append([]byte(nil), corrupt.Data...)
+	corrupt.Data[3] ^= 0x10
+	_, err = CombineShares([]Share{shares[0], corrupt})
+	require.Equal(t, ErrBadShares, errors.Cause(err))
+
+	zero := shares[1]
+	zero.Index = 0
+	_, err = CombineShares([]Share{shares[0], zero})
+	require.Equal(t, ErrBadShares, errors.Cause(err))
+
+	future := shares[0]
+	future.Version = ShareVersion + 1
+	_, err = CombineShares([]Share{future, shares[1]})
+	require.Equal(t, ErrMismatchedShares, errors.Cause(err))
+}
+
+func TestShareText(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	shares, err := SplitKey(&private, 3, 2)
+	require.NoError(t, err)
+
+	var parsed []Share
+	for _, s := range shares {
+		text, err := s.MarshalText()
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(text), SharePrefix))
+
+		got, err := ParseShare(strings.ToUpper(string(text)))
+		require.NoError(t, err)
+		require.Equal(t, s, *got)
+		parsed = append(parsed, *got)
+	}
+	combined, err := CombineShares(parsed[1:])
+	require.NoError(t, err)
+	require.True(t, private.EqualConstantTime(*combined))
+
+	text, err := shares[0].MarshalText()
+	require.NoError(t, err)
+	_, err = ParseShare(string(text[len(SharePrefix):]))
+	require.Error(t, err)
+	// change a character in the middle of the share
+	i := len(text) / 2
+	if text[i] == 'a' {
+		text[i] = 'b'
+	} else {
+		text[i] = 'a'
+	}
+	_, err = ParseShare(string(text))
+	require.Error(t, err)
+}
+
+func TestShareZeroize(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	shares, err := SplitKey(&private, 2, 2)
+	require.NoError(t, err)
+	data := shares[0].Data
+	shares[0].Zeroize()
+	require.Equal(t, Share{}, shares[0])
+	require.Equal(t, make([]byte, len(data)), data)
+	(*Share)(nil).Zeroize()
+}
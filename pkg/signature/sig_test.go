@@ -13,6 +13,7 @@ package signature
 import (
 	"crypto/rand"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -157,3 +158,64 @@ func TestUnmarshal(t *testing.T) {
 	err = k2.UnmarshalText([]byte(pvtkbytes))
 	fmt.Println(err)
 }
+
+func TestMaybeSignature(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			for i := 0; i < 64; i++ {
+				message := make([]byte, i)
+				rand.Read(message)
+				for _, ctx := range []string{"", "c", "ndau-test-context"} {
+					var sig Signature
+					if ctx == "" {
+						sig = private.Sign(message)
+					} else {
+						sig, err = private.SignWithContext(ctx, message)
+						require.NoError(t, err)
+					}
+					text, err := sig.MarshalString()
+					require.NoError(t, err)
+					require.True(t, MaybeSignature(text), text)
+					require.True(t, MaybeSignature(strings.ToUpper(text)), text)
+
+					parsed, err := ParseSignature(text)
+					require.NoError(t, err)
+					require.True(t, SameAlgorithm(al, parsed.Algorithm()))
+					require.Equal(t, ctx, parsed.Context())
+				}
+			}
+
+			pubText, err := public.MarshalString()
+			require.NoError(t, err)
+			require.False(t, MaybeSignature(pubText))
+			pvtText, err := private.MarshalString()
+			require.NoError(t, err)
+			require.False(t, MaybeSignature(pvtText))
+		})
+	}
+
+	t.Run("recoverable", func(t *testing.T) {
+		_, private, err := Generate(Secp256k1, nil)
+		require.NoError(t, err)
+		for i := 0; i < 64; i++ {
+			message := make([]byte, i)
+			rand.Read(message)
+			sig, err := private.SignRecoverable(message)
+			require.NoError(t, err)
+			text, err := sig.MarshalString()
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(text, RecoverableSignaturePrefix), text)
+			require.True(t, MaybeSignature(text), text)
+			require.True(t, MaybeSignature(strings.ToUpper(text)), text)
+		}
+	})
+
+	for _, s := range []string{
+		"", "aqjs", "ndaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "aajaaaaa", "aqkaaaaa",
+		"nrsg", "nrsgaqjs", "nrsgaajaaaaa", "nrsgaqkaaaaa", "nrsnaqjaaaaa", "nrsgnrsgaqjaaaaa",
+	} {
+		require.False(t, MaybeSignature(s), s)
+	}
+}
@@ -12,6 +12,7 @@ package signature
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ndau/ndaumath/pkg/b32"
 	"github.com/pkg/errors"
@@ -24,11 +25,20 @@ var _ msgp.Unmarshaler = (*Signature)(nil)
 var _ msgp.Sizer = (*Signature)(nil)
 
 // A Signature is a byte slice with known algorithm type
+//
+// Signatures made with SignWithContext also carry extra data: the name of the
+// context. A signature without extra data serializes exactly as an
+// IdentifiedData; one with extra data serializes as a three-element tuple of
+// algorithm, data, and extra data.
 type Signature struct {
 	algorithm Algorithm
 	data      []byte
+	extra     []byte
 }
 
+// sigFieldsExtra is the tuple length of a signature with extra data
+const sigFieldsExtra = 3
+
 // Algorithm gets the signature's algorithm
 func (signature Signature) Algorithm() Algorithm {
 	return signature.algorithm
@@ -58,19 +68,79 @@ func (signature Signature) Size() int {
 // Marshal marshals the signature into a serialized binary format
 // which includes a type byte for the algorithm.
 func (signature Signature) Marshal() (serialized []byte, err error) {
-	return marshal(signature.algorithm, signature.data)
+	if len(signature.extra) == 0 {
+		return marshal(signature.algorithm, signature.data)
+	}
+	id, err := idOf(signature.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	serialized = msgp.AppendArrayHeader(nil, sigFieldsExtra)
+	serialized = msgp.AppendUint8(serialized, uint8(id))
+	serialized = msgp.AppendBytes(serialized, signature.data)
+	serialized = msgp.AppendBytes(serialized, signature.extra)
+	return serialized, nil
+}
+
+// unmarshalSignature unmarshals the v0 serialization of a signature, with
+// or without extra data
+func unmarshalSignature(serialized []byte) (al Algorithm, data, extra, leftovers []byte, err error) {
+	fields, leftovers, err := msgp.ReadArrayHeaderBytes(serialized)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if fields != sigFieldsExtra {
+		al, data, leftovers, err = unmarshalWithLeftovers(serialized)
+		return al, data, nil, leftovers, err
+	}
+
+	var id uint8
+	id, leftovers, err = msgp.ReadUint8Bytes(leftovers)
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "reading algorithm")
+	}
+	data, leftovers, err = msgp.ReadBytesBytes(leftovers, nil)
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "reading data")
+	}
+	extra, leftovers, err = msgp.ReadBytesBytes(leftovers, nil)
+	if err != nil {
+		return nil, nil, nil, nil, errors.Wrap(err, "reading extra")
+	}
+	if len(extra) == 0 {
+		// the extra data is only written when present, so that every
+		// signature has exactly one serialization
+		return nil, nil, nil, nil, errors.New("signature extra data present but empty")
+	}
+	al, err = algorithmOf(AlgorithmID(id))
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	return al, data, extra, leftovers, nil
 }
 
 // Unmarshal unmarshals the serialized binary data into the supplied signature instance
+//
+// The data may be of any format version which FormatVersion recognizes.
 func (signature *Signature) Unmarshal(serialized []byte) error {
-	al, b, err := unmarshal(serialized)
-	ss := al.SignatureSize()
-	if err == nil && ss >= 0 && len(b) != ss {
-		err = fmt.Errorf("Wrong size signature: expect len %d, have %d", al.SignatureSize(), len(b))
+	serialized, err := stripFormatVersion(serialized)
+	if err != nil {
+		return err
+	}
+	al, b, extra, leftovers, err := unmarshalSignature(serialized)
+	if err == nil && len(leftovers) > 0 {
+		err = errors.New("Leftovers present after deserialization")
+	}
+	if err == nil {
+		ss := al.SignatureSize()
+		if ss >= 0 && len(b) != ss {
+			err = fmt.Errorf("Wrong size signature: expect len %d, have %d", al.SignatureSize(), len(b))
+		}
 	}
 	if err == nil {
 		signature.algorithm = al
 		signature.data = b
+		signature.extra = extra
 	}
 	return err
 }
@@ -90,13 +160,16 @@ func (signature Signature) MarshalMsg(in []byte) (out []byte, err error) {
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
+//
+// It reads only the v0 format, which is what MarshalMsg writes.
 func (signature *Signature) UnmarshalMsg(in []byte) (leftover []byte, err error) {
 	var al Algorithm
-	var b []byte
-	al, b, leftover, err = unmarshalWithLeftovers(in)
+	var b, extra []byte
+	al, b, extra, leftover, err = unmarshalSignature(in)
 	if err == nil {
 		signature.algorithm = al
 		signature.data = b
+		signature.extra = extra
 	}
 	return
 }
@@ -109,6 +182,9 @@ func (signature *Signature) UnmarshalMsg(in []byte) (leftover []byte, err error)
 // have the same size.
 func (signature *Signature) Msgsize() (s int) {
 	s = 1 + msgp.Uint8Size + msgp.BytesPrefixSize + len(signature.data)
+	if len(signature.extra) > 0 {
+		s += msgp.BytesPrefixSize + len(signature.extra)
+	}
 	return
 }
 
@@ -117,24 +193,47 @@ func (signature *Signature) Bytes() []byte {
 	return signature.data
 }
 
+// Zeroize removes all data from this signature
+//
+// As with keys, the data is overwritten with zeros before it is released.
+//
+// This is a destructive operation which cannot be undone; make copies
+// first if you need to.
+func (signature *Signature) Zeroize() {
+	if signature == nil {
+		return
+	}
+	wipe(signature.data, signature.extra)
+	signature.algorithm = nil
+	signature.data = nil
+	signature.extra = nil
+}
+
 // MarshalText implements encoding.TextMarshaler
 //
 // This marshaller uses a custom b32 encoding which is case-insensitive and
 // lacks certain confusing pairs, for ease of human-friendly handling.
 // For the same reason, it embeds a checksum, so it's easy to tell whether
 // or not it was received correctly.
+//
+// Recoverable signatures are prefixed with RecoverableSignaturePrefix.
 func (signature Signature) MarshalText() ([]byte, error) {
 	bytes, err := signature.Marshal()
 	if err != nil {
 		return nil, err
 	}
 	bytes = AddChecksum(bytes)
-	return []byte(b32.Encode(bytes)), nil
+	text := b32.Encode(bytes)
+	if isRecoverable(signature.algorithm) {
+		text = RecoverableSignaturePrefix + text
+	}
+	return []byte(text), nil
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
 func (signature *Signature) UnmarshalText(text []byte) error {
-	bytes, err := b32.Decode(string(text))
+	s, prefixed := trimRecoverablePrefix(string(text))
+	bytes, err := b32.Decode(s)
 	if err != nil {
 		return err
 	}
@@ -143,7 +242,24 @@ func (signature *Signature) UnmarshalText(text []byte) error {
 	if !checksumOk {
 		return errors.New("signature unmarshal failure: bad checksum")
 	}
-	return signature.Unmarshal(bytes)
+	err = requireTextFormat(bytes)
+	if err != nil {
+		return errors.Wrap(err, "signature unmarshal failure")
+	}
+	var sig Signature
+	err = sig.Unmarshal(bytes)
+	if err != nil {
+		return err
+	}
+	if prefixed != isRecoverable(sig.algorithm) {
+		// every signature has exactly one text form
+		return fmt.Errorf(
+			"signature unmarshal failure: %s signature must be prefixed with %q iff recoverable",
+			NameOf(sig.algorithm), RecoverableSignaturePrefix,
+		)
+	}
+	*signature = sig
+	return nil
 }
 
 // MarshalString is like MarshalText, but to a string
@@ -156,6 +272,28 @@ func (signature *Signature) MarshalString() (string, error) {
 	return string(t), err
 }
 
+// MaybeSignature provides a fast way to check whether a string looks like
+// it might be an ndau signature.
+//
+// Unlike keys, signatures have no human-readable prefix, but the text of
+// every signature begins alike: its first byte is the checksum width, from 3
+// to 7, and its second is the header of a two- or three-element array. Those
+// fix the first three characters.
+//
+// Recoverable signatures are the same, after RecoverableSignaturePrefix.
+//
+// As with MaybePublic, this allows some false positives, but no false
+// negatives. To get a definitive answer, use ParseSignature.
+func MaybeSignature(s string) bool {
+	s, _ = trimRecoverablePrefix(s)
+	// the b32 encoding of the checksummed data is never padded
+	if len(s) < 8 || len(s)%8 != 0 {
+		return false
+	}
+	s = strings.ToLower(s[:3])
+	return s[0] == 'a' && strings.IndexByte("quy48", s[1]) >= 0 && s[2] == 'j'
+}
+
 // ParseSignature parses a string representation of a signature, if possible
 func ParseSignature(s string) (*Signature, error) {
 	key := new(Signature)
@@ -0,0 +1,156 @@
+package signaturetest
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/ndau/ndaumath/pkg/signature"
+)
+
+// The signaturetest package generates keys for tests: the same keys on every
+// run, so that fixtures throughout the ndau test suites can share them
+// instead of each keeping their own.
+//
+// Every key derives from a seed which is plain text, so none of them is in
+// any way secret. They must never be used outside of tests.
+
+// domain separates the key seeds of this package from any other hash
+const domain = "ndau signaturetest v1"
+
+// A KeyPair is a public key and its private key
+type KeyPair struct {
+	Public  signature.PublicKey
+	Private signature.PrivateKey
+}
+
+// Keys are keypairs of each algorithm, derived from the same seed and index
+type Keys struct {
+	Ed25519   KeyPair
+	Secp256k1 KeyPair
+}
+
+// For returns the keypair of the given algorithm
+//
+// It panics if the algorithm is neither Ed25519 nor Secp256k1.
+func (k Keys) For(al signature.Algorithm) KeyPair {
+	switch {
+	case signature.SameAlgorithm(al, signature.Ed25519):
+		return k.Ed25519
+	case signature.SameAlgorithm(al, signature.Secp256k1):
+		return k.Secp256k1
+	}
+	panic(fmt.Sprintf("signaturetest: no keys for algorithm %s", signature.NameOf(al)))
+}
+
+// stream is an endless deterministic io.Reader: SHA-256 in counter mode
+type stream struct {
+	key     [sha256.Size]byte
+	counter uint64
+	buf     []byte
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.buf) == 0 {
+			var block [sha256.Size + 8]byte
+			copy(block[:], s.key[:])
+			binary.BigEndian.PutUint64(block[sha256.Size:], s.counter)
+			s.counter++
+			sum := sha256.Sum256(block[:])
+			s.buf = sum[:]
+		}
+		c := copy(p[n:], s.buf)
+		s.buf = s.buf[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// newStream returns the random stream from which the key of the given
+// algorithm, seed, and index is generated
+func newStream(al signature.Algorithm, seed string, index int) *stream {
+	h := sha256.New()
+	for _, part := range []string{domain, signature.NameOf(al), seed} {
+		// lengths prefix each part, so that no two inputs run together
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(part)))
+		h.Write(length[:])
+		h.Write([]byte(part))
+	}
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(index))
+	h.Write(idx[:])
+
+	s := new(stream)
+	h.Sum(s.key[:0])
+	return s
+}
+
+// DeterministicKeyPair returns the keypair of the given algorithm for the
+// seed and index
+//
+// It returns the same keypair for the same arguments on every run.
+func DeterministicKeyPair(al signature.Algorithm, seed string, index int) (KeyPair, error) {
+	if index < 0 {
+		return KeyPair{}, fmt.Errorf("index must not be negative; got %d", index)
+	}
+	public, private, err := signature.Generate(al, newStream(al, seed, index))
+	if err != nil {
+		return KeyPair{}, err
+	}
+	return KeyPair{Public: public, Private: private}, nil
+}
+
+// mustKeyPair is DeterministicKeyPair, for algorithms which can't fail
+func mustKeyPair(al signature.Algorithm, seed string, index int) KeyPair {
+	kp, err := DeterministicKeyPair(al, seed, index)
+	if err != nil {
+		panic(fmt.Sprintf("signaturetest: generating %s key: %s", signature.NameOf(al), err))
+	}
+	return kp
+}
+
+// DeterministicKeys returns n sets of keys derived from the seed
+//
+// The keys at each index are the same whatever n is, so a test which later
+// needs more keys keeps those it already had. Different seeds give unrelated
+// keys. It panics if n is negative.
+func DeterministicKeys(seed string, n int) []Keys {
+	if n < 0 {
+		panic(fmt.Sprintf("signaturetest: negative number of keys %d", n))
+	}
+	keys := make([]Keys, n)
+	for i := range keys {
+		keys[i] = Keys{
+			Ed25519:   mustKeyPair(signature.Ed25519, seed, i),
+			Secp256k1: mustKeyPair(signature.Secp256k1, seed, i),
+		}
+	}
+	return keys
+}
+
+// Persona returns the keys of a named test actor
+//
+// Any name may be used; the same name always gives the same keys.
+func Persona(name string) Keys {
+	return DeterministicKeys("persona:"+name, 1)[0]
+}
+
+// These well-known personas are those used throughout the ndau test suites
+var (
+	Alice      = Persona("alice")
+	Bob        = Persona("bob")
+	Validator1 = Persona("validator1")
+)
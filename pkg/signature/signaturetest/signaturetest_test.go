@@ -0,0 +1,107 @@
+package signaturetest
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/signature"
+	"github.com/stretchr/testify/require"
+)
+
+// requirePublic checks the text form of a public key
+func requirePublic(t *testing.T, want string, key signature.PublicKey) {
+	got, err := key.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, want, string(got))
+}
+
+// These keys must never change: downstream fixtures depend on them
+func TestKeysAreStable(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      Keys
+		ed25519   string
+		secp256k1 string
+	}{
+		{
+			"alice", Alice,
+			"npuba8jadtbbea5a6cjztfn7npyjpa368mjekwwzarpsg2au6fbihe7bf4vp3caw9pshbe3xcikn",
+			"npuba4jaftbceebynmh8uguvqwh3wtknjfvxfsa4f9qeeh5esefn8td4c27qcv7kjj57axmk8w7m",
+		},
+		{
+			"bob", Bob,
+			"npuba8jadtbbea4wjtxp9tbbs36fsdm3ryvfsmyia6fnywdvdbdur4r2unh8b867icups9afiq83",
+			"npuba4jaftbceeb3y9griddtsnvaxwjw244g9v5pqwmz7zpt9j52bk4u9febifr2a7485z2u84zv",
+		},
+		{
+			"validator1", Validator1,
+			"npuba8jadtbbeca46ykuceyqziyudsx7xrun95pgfszycw8hd8hi5jxhmidujbukfzdk6hpd4a3w",
+			"npuba4jaftbceebwdy35sz8i2unymfjw5vpdxsskmkxbyzq9bbd3wqhse3kjrcdp6gw9px9q5uhs",
+		},
+		{
+			"seeded", DeterministicKeys("fixture", 2)[1],
+			"npuba8jadtbbedu69bddqq4xw7feitky66h5b8wr3b77n6nnzbiui7326jus9jrgdbpicmcfge6v",
+			"npuba4jaftbceebufptg78pkch6n6e6a9be595a47iaijusfpebvqyhig5zpsvbitwq2tnpvqea9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirePublic(t, tt.ed25519, tt.keys.Ed25519.Public)
+			requirePublic(t, tt.secp256k1, tt.keys.Secp256k1.Public)
+		})
+	}
+}
+
+func TestKeysSign(t *testing.T) {
+	message := []byte("signed by a test key")
+	for _, keys := range DeterministicKeys("sign", 3) {
+		for _, al := range []signature.Algorithm{signature.Ed25519, signature.Secp256k1} {
+			kp := keys.For(al)
+			require.True(t, signature.SameAlgorithm(al, kp.Public.Algorithm()))
+			require.True(t, kp.Public.Verify(message, kp.Private.Sign(message)))
+		}
+	}
+}
+
+func TestDeterministicKeys(t *testing.T) {
+	// more keys extend, rather than replace, fewer
+	few := DeterministicKeys("fixture", 2)
+	more := DeterministicKeys("fixture", 5)
+	require.Equal(t, few, more[:2])
+	require.Empty(t, DeterministicKeys("fixture", 0))
+
+	// every key is distinct, across indices, seeds, and personas
+	seen := make(map[string]bool)
+	all := append(more, DeterministicKeys("other", 5)...)
+	all = append(all, Alice, Bob, Validator1)
+	for _, keys := range all {
+		for _, kp := range []KeyPair{keys.Ed25519, keys.Secp256k1} {
+			text, err := kp.Public.MarshalText()
+			require.NoError(t, err)
+			require.False(t, seen[string(text)], "duplicate key %s", text)
+			seen[string(text)] = true
+		}
+	}
+
+	require.Equal(t, Alice, Persona("alice"))
+	require.Panics(t, func() { DeterministicKeys("fixture", -1) })
+}
+
+func TestDeterministicKeyPair(t *testing.T) {
+	kp, err := DeterministicKeyPair(signature.Secp256k1, "fixture", 1)
+	require.NoError(t, err)
+	require.Equal(t, DeterministicKeys("fixture", 2)[1].Secp256k1, kp)
+
+	_, err = DeterministicKeyPair(signature.Ed25519, "fixture", -1)
+	require.Error(t, err)
+	require.Panics(t, func() { Alice.For(signature.Null) })
+}
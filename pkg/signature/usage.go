@@ -0,0 +1,217 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A KeyUsage says what a key is for
+//
+// A key's usage is recorded in its extra data, so it is part of every
+// serialization of the key, and of anything which commits to those, such as
+// the hash of an account's validation keys.
+type KeyUsage uint8
+
+// A UsageKey is a key which may be tagged with its usage, as PublicKey and
+// PrivateKey may
+//
+// It is separate from Key so that implementations of Key needn't support
+// usage tags; RequireUsage treats keys which don't implement it as
+// untagged.
+type UsageKey interface {
+	Key
+	Usage() KeyUsage
+}
+
+var _ UsageKey = (*PublicKey)(nil)
+var _ UsageKey = (*PrivateKey)(nil)
+
+// These are the usages with which a key may be tagged
+const (
+	// UsageNone means the key has no usage tag
+	UsageNone KeyUsage = iota
+	// UsageOwnership keys prove ownership of an account
+	UsageOwnership
+	// UsageValidation keys validate an account's transactions
+	UsageValidation
+	// UsageRecovery keys recover an account which has lost its other keys
+	UsageRecovery
+	// UsageNode keys belong to nodes of the network
+	UsageNode
+
+	maxUsage = UsageNode
+)
+
+// UsageVersion is the version of the usage tag written by WithUsage.
+//
+// Tags of any other version are not recognized: keys with them have
+// UsageNone.
+const UsageVersion byte = 1
+
+// The usage tag is a framed trailer appended to the end of a key's extra
+// data, so that it follows extra data of any other kind, such as the
+// derivation data of an extended key. It is read from the end: the last
+// byte gives the length of the tag's body, which precedes the magic bytes.
+//
+//   field | width (bytes) | notes
+//   ------|-------|------
+//   version | 1 | UsageVersion
+//   usage | 1 | a KeyUsage other than UsageNone
+//   created | 8 | microseconds since the Unix epoch, as big-endian int64
+//   magic | 2 | usageMagic
+//   length | 1 | the width of the body, before the magic: usageBodyLen
+//
+// Because the length is explicit, a tag of a later version, whose body may
+// be longer, can still be removed or replaced.
+const (
+	usageBodyLen  = 1 + 1 + 8
+	usageFrameLen = 2 + 1
+	usageBlockLen = usageBodyLen + usageFrameLen
+)
+
+var usageMagic = []byte{'u', 'k'}
+
+var (
+	// ErrUnknownUsage is returned when tagging a key with an undefined usage
+	ErrUnknownUsage = errors.New("unknown key usage")
+
+	// ErrWrongUsage is returned by RequireUsage when a key is not tagged
+	// with the required usage
+	ErrWrongUsage = errors.New("key has wrong usage")
+)
+
+func (u KeyUsage) String() string {
+	switch u {
+	case UsageNone:
+		return "none"
+	case UsageOwnership:
+		return "ownership"
+	case UsageValidation:
+		return "validation"
+	case UsageRecovery:
+		return "recovery"
+	case UsageNode:
+		return "node"
+	default:
+		return fmt.Sprintf("usage(%d)", uint8(u))
+	}
+}
+
+// parseUsage finds the usage tag at the end of extra data
+//
+// It returns the extra data preceding the tag, if the data ends with a
+// framed tag of any version, and ok false unless that tag is recognized.
+func parseUsage(extra []byte) (rest []byte, usage KeyUsage, created int64, ok bool) {
+	if len(extra) < usageFrameLen {
+		return extra, UsageNone, 0, false
+	}
+	frame := len(extra) - usageFrameLen
+	if !bytes.Equal(extra[frame:frame+2], usageMagic) {
+		return extra, UsageNone, 0, false
+	}
+	bodyLen := int(extra[len(extra)-1])
+	if bodyLen == 0 || bodyLen > frame {
+		return extra, UsageNone, 0, false
+	}
+	split := frame - bodyLen
+	rest, body := extra[:split], extra[split:frame]
+	if body[0] != UsageVersion || bodyLen != usageBodyLen {
+		return rest, UsageNone, 0, false
+	}
+	usage = KeyUsage(body[1])
+	if usage == UsageNone || usage > maxUsage {
+		return rest, UsageNone, 0, false
+	}
+	created = int64(binary.BigEndian.Uint64(body[2:]))
+	return rest, usage, created, true
+}
+
+// Usage returns the usage with which the key is tagged, or UsageNone
+func (key keyBase) Usage() KeyUsage {
+	_, usage, _, _ := parseUsage(key.extra)
+	return usage
+}
+
+// UsageCreated returns the creation time recorded with the key's usage tag.
+//
+// ok is false if the key has no usage tag.
+func (key keyBase) UsageCreated() (created time.Time, ok bool) {
+	_, _, micros, ok := parseUsage(key.extra)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, micros*int64(time.Microsecond/time.Nanosecond)).UTC(), true
+}
+
+// withUsage returns a copy of the extra data of the key, tagged with the
+// given usage in place of any existing tag
+//
+// UsageNone removes the tag.
+func (key keyBase) withUsage(usage KeyUsage, created time.Time) ([]byte, error) {
+	if usage > maxUsage {
+		return nil, errors.Wrap(ErrUnknownUsage, usage.String())
+	}
+	rest, _, _, _ := parseUsage(key.extra)
+	extra := make([]byte, len(rest), len(rest)+usageBlockLen)
+	copy(extra, rest)
+	if usage == UsageNone {
+		return extra, nil
+	}
+	var micros [8]byte
+	binary.BigEndian.PutUint64(micros[:], uint64(created.UnixNano()/int64(time.Microsecond)))
+	extra = append(extra, UsageVersion, byte(usage))
+	extra = append(extra, micros[:]...)
+	extra = append(extra, usageMagic...)
+	extra = append(extra, usageBodyLen)
+	return extra, nil
+}
+
+// WithUsage returns a copy of this key tagged with the given usage and
+// creation time, in place of any existing tag. UsageNone removes the tag.
+func (key PublicKey) WithUsage(usage KeyUsage, created time.Time) (*PublicKey, error) {
+	extra, err := key.withUsage(usage, created)
+	if err != nil {
+		return nil, err
+	}
+	return RawPublicKey(key.Algorithm(), append([]byte(nil), key.key...), extra)
+}
+
+// WithUsage returns a copy of this key tagged with the given usage and
+// creation time, in place of any existing tag. UsageNone removes the tag.
+func (key PrivateKey) WithUsage(usage KeyUsage, created time.Time) (*PrivateKey, error) {
+	extra, err := key.withUsage(usage, created)
+	if err != nil {
+		return nil, err
+	}
+	return RawPrivateKey(key.Algorithm(), append([]byte(nil), key.key...), extra)
+}
+
+// RequireUsage returns an error unless the key is tagged with the given usage
+//
+// This is what validation rules should use to insist that, for example, only
+// validation keys are set as an account's validation keys. Keys which are not
+// UsageKeys have UsageNone.
+func RequireUsage(key Key, usage KeyUsage) error {
+	have := UsageNone
+	if uk, ok := key.(UsageKey); ok {
+		have = uk.Usage()
+	}
+	if have != usage {
+		return errors.Wrapf(ErrWrongUsage, "want %s, have %s", usage, have)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyUsage(t *testing.T) {
+	created := time.Date(2020, 3, 14, 15, 9, 26, 535897000, time.UTC)
+	public, private, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+	require.Equal(t, UsageNone, public.Usage())
+	_, ok := public.UsageCreated()
+	require.False(t, ok)
+
+	for _, usage := range []KeyUsage{UsageOwnership, UsageValidation, UsageRecovery, UsageNode} {
+		t.Run(usage.String(), func(t *testing.T) {
+			pub, err := public.WithUsage(usage, created)
+			require.NoError(t, err)
+			require.Equal(t, usage, pub.Usage())
+			have, ok := pub.UsageCreated()
+			require.True(t, ok)
+			require.Equal(t, created, have)
+			require.NoError(t, RequireUsage(pub, usage))
+			require.Equal(t, UsageNone, public.Usage(), "original key must be unchanged")
+
+			// the tag survives serialization
+			text, err := pub.MarshalText()
+			require.NoError(t, err)
+			pub2, err := ParsePublicKey(string(text))
+			require.NoError(t, err)
+			require.Equal(t, usage, pub2.Usage())
+
+			pvt, err := private.WithUsage(usage, created)
+			require.NoError(t, err)
+			text, err = pvt.MarshalText()
+			require.NoError(t, err)
+			pvt2, err := ParsePrivateKey(string(text))
+			require.NoError(t, err)
+			require.Equal(t, usage, pvt2.Usage())
+
+			// the tag doesn't affect signing
+			msg := []byte("tagged")
+			require.True(t, pub2.Verify(msg, pvt2.Sign(msg)))
+		})
+	}
+}
+
+func TestKeyUsageReplace(t *testing.T) {
+	created := time.Unix(1600000000, 0).UTC()
+	extra := []byte("derivation data")
+	generated, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	public, err := RawPublicKey(generated.Algorithm(), generated.KeyBytes(), extra)
+	require.NoError(t, err)
+
+	tagged, err := public.WithUsage(UsageValidation, created)
+	require.NoError(t, err)
+	require.Equal(t, extra, tagged.ExtraBytes()[:len(extra)])
+	require.Len(t, tagged.ExtraBytes(), len(extra)+usageBlockLen)
+
+	retagged, err := tagged.WithUsage(UsageNode, created.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, UsageNode, retagged.Usage())
+	require.Len(t, retagged.ExtraBytes(), len(extra)+usageBlockLen)
+	have, _ := retagged.UsageCreated()
+	require.Equal(t, created.Add(time.Hour), have)
+
+	untagged, err := retagged.WithUsage(UsageNone, created)
+	require.NoError(t, err)
+	require.Equal(t, UsageNone, untagged.Usage())
+	require.Equal(t, extra, untagged.ExtraBytes())
+
+	_, err = public.WithUsage(maxUsage+1, created)
+	require.Equal(t, ErrUnknownUsage, errors.Cause(err))
+}
+
+func TestKeyUsageUnrecognized(t *testing.T) {
+	public, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	tagged, err := public.WithUsage(UsageRecovery, time.Now())
+	require.NoError(t, err)
+
+	// offsets from the end of the extra data
+	const (
+		version = usageBlockLen
+		usage   = usageBlockLen - 1
+		magic   = usageFrameLen
+		length  = 1
+	)
+	for name, corrupt := range map[string]func([]byte){
+		"magic":       func(b []byte) { b[len(b)-magic] ^= 1 },
+		"version":     func(b []byte) { b[len(b)-version] = UsageVersion + 1 },
+		"none":        func(b []byte) { b[len(b)-usage] = byte(UsageNone) },
+		"unknown":     func(b []byte) { b[len(b)-usage] = byte(maxUsage + 1) },
+		"zero length": func(b []byte) { b[len(b)-length] = 0 },
+		"short":       func(b []byte) { b[len(b)-length] = usageBodyLen - 1 },
+		"overlong":    func(b []byte) { b[len(b)-length] = usageBodyLen + 1 },
+	} {
+		t.Run(name, func(t *testing.T) {
+			extra := append([]byte(nil), tagged.ExtraBytes()...)
+			corrupt(extra)
+			k, err := RawPublicKey(tagged.Algorithm(), tagged.KeyBytes(), extra)
+			require.NoError(t, err)
+			require.Equal(t, UsageNone, k.Usage())
+			err = RequireUsage(k, UsageRecovery)
+			require.Equal(t, ErrWrongUsage, errors.Cause(err))
+		})
+	}
+}
+
+func TestKeyUsageFraming(t *testing.T) {
+	created := time.Unix(1600000000, 0).UTC()
+	generated, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+
+	// a tag of a later version, with a longer body, is framed, so it is
+	// replaced rather than left behind the new tag
+	extra := []byte("derivation data")
+	later := append(append([]byte(nil), extra...), UsageVersion+1, byte(UsageNode))
+	later = append(later, make([]byte, 20)...)
+	later = append(later, usageMagic...)
+	later = append(later, 22)
+	public, err := RawPublicKey(generated.Algorithm(), generated.KeyBytes(), later)
+	require.NoError(t, err)
+	require.Equal(t, UsageNone, public.Usage())
+	tagged, err := public.WithUsage(UsageValidation, created)
+	require.NoError(t, err)
+	require.Equal(t, UsageValidation, tagged.Usage())
+	require.Equal(t, extra, tagged.ExtraBytes()[:len(extra)])
+	require.Len(t, tagged.ExtraBytes(), len(extra)+usageBlockLen)
+
+	// data which merely ends like a frame, but whose length exceeds it,
+	// is left alone
+	for _, extra := range [][]byte{[]byte("uk"), []byte("xuk\x09"), []byte("uk\x0a")} {
+		public, err := RawPublicKey(generated.Algorithm(), generated.KeyBytes(), extra)
+		require.NoError(t, err)
+		require.Equal(t, UsageNone, public.Usage())
+		tagged, err := public.WithUsage(UsageRecovery, created)
+		require.NoError(t, err)
+		require.Equal(t, extra, tagged.ExtraBytes()[:len(extra)])
+		untagged, err := tagged.WithUsage(UsageNone, created)
+		require.NoError(t, err)
+		require.Equal(t, extra, untagged.ExtraBytes())
+	}
+}
+
+// untaggable is a Key which doesn't support usage tags
+type untaggable struct {
+	Key
+}
+
+func TestRequireUsageOptional(t *testing.T) {
+	public, _, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	tagged, err := public.WithUsage(UsageNode, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, RequireUsage(tagged, UsageNode))
+
+	var k Key = untaggable{tagged}
+	_, ok := k.(UsageKey)
+	require.False(t, ok)
+	require.NoError(t, RequireUsage(k, UsageNone))
+	require.Equal(t, ErrWrongUsage, errors.Cause(RequireUsage(k, UsageNode)))
+}
@@ -0,0 +1,159 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"sync/atomic"
+)
+
+// A VerifyCache remembers successful signature verifications, so that
+// verifying the same signature of the same message by the same key again,
+// as happens when a transaction is checked on entry to the mempool and again
+// in a block, skips the curve math.
+//
+// It holds at most a fixed number of entries, evicting the least recently
+// used. Only successful verifications are cached: otherwise a flood of bad
+// signatures could evict the good ones. Entries are keyed by a hash of the
+// key, the message, and the signature, so the cache holds no messages.
+//
+// A VerifyCache is safe for concurrent use.
+type VerifyCache struct {
+	// accessed atomically; first, for 64-bit alignment on 32-bit platforms
+	hits   uint64
+	misses uint64
+
+	capacity int
+
+	lock    sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	order   *list.List // front is most recently used
+}
+
+// VerifyCacheStats are counters of a VerifyCache's use
+type VerifyCacheStats struct {
+	// Hits is the number of verifications answered by the cache
+	Hits uint64
+	// Misses is the number of verifications which did the curve math
+	Misses uint64
+	// Len is the number of entries in the cache
+	Len int
+}
+
+// NewVerifyCache creates a VerifyCache holding at most capacity entries
+//
+// A capacity less than 1 is treated as 1.
+func NewVerifyCache(capacity int) *VerifyCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &VerifyCache{
+		capacity: capacity,
+		entries:  make(map[[sha256.Size]byte]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// verifyCacheKey hashes the parts of a verification
+//
+// Each part is length-prefixed, so that no two verifications share a key.
+func verifyCacheKey(key PublicKey, message []byte, sig Signature) (k [sha256.Size]byte, ok bool) {
+	keyBytes, err := key.Marshal()
+	if err != nil {
+		return k, false
+	}
+	sigBytes, err := sig.Marshal()
+	if err != nil {
+		return k, false
+	}
+	msgHash := sha256.Sum256(message)
+
+	h := sha256.New()
+	var n [8]byte
+	for _, part := range [][]byte{keyBytes, msgHash[:], sigBytes} {
+		binary.BigEndian.PutUint64(n[:], uint64(len(part)))
+		h.Write(n[:])
+		h.Write(part)
+	}
+	h.Sum(k[:0])
+	return k, true
+}
+
+// lookup is true if the key is cached, marking it most recently used
+func (c *VerifyCache) lookup(k [sha256.Size]byte) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	elem, ok := c.entries[k]
+	if ok {
+		c.order.MoveToFront(elem)
+	}
+	return ok
+}
+
+// add caches the key, evicting the least recently used entry if full
+func (c *VerifyCache) add(k [sha256.Size]byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if elem, ok := c.entries[k]; ok {
+		// another goroutine verified the same signature meanwhile
+		c.order.MoveToFront(elem)
+		return
+	}
+	if c.order.Len() >= c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.([sha256.Size]byte))
+	}
+	c.entries[k] = c.order.PushFront(k)
+}
+
+// Verify is PublicKey.Verify, consulting and updating the cache
+func (c *VerifyCache) Verify(key PublicKey, message []byte, sig Signature) bool {
+	k, ok := verifyCacheKey(key, message, sig)
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return key.Verify(message, sig)
+	}
+	if c.lookup(k) {
+		atomic.AddUint64(&c.hits, 1)
+		return true
+	}
+	atomic.AddUint64(&c.misses, 1)
+	if !key.Verify(message, sig) {
+		return false
+	}
+	c.add(k)
+	return true
+}
+
+// Stats returns the counters of the cache's use
+func (c *VerifyCache) Stats() VerifyCacheStats {
+	c.lock.Lock()
+	length := c.order.Len()
+	c.lock.Unlock()
+	return VerifyCacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+		Len:    length,
+	}
+}
+
+// VerifyCached verifies the supplied message, as Verify does, consulting and
+// updating the cache. A nil cache is never consulted.
+func (key PublicKey) VerifyCached(message []byte, sig Signature, cache *VerifyCache) bool {
+	if cache == nil {
+		return key.Verify(message, sig)
+	}
+	return cache.Verify(key, message, sig)
+}
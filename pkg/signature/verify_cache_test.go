@@ -0,0 +1,110 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyCache(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			msg := []byte("cached message")
+			sig := private.Sign(msg)
+			cache := NewVerifyCache(10)
+
+			require.True(t, public.VerifyCached(msg, sig, cache))
+			require.Equal(t, VerifyCacheStats{Misses: 1, Len: 1}, cache.Stats())
+			require.True(t, public.VerifyCached(msg, sig, cache))
+			require.Equal(t, VerifyCacheStats{Hits: 1, Misses: 1, Len: 1}, cache.Stats())
+
+			// failures are never cached
+			other := []byte("other message")
+			require.False(t, public.VerifyCached(other, sig, cache))
+			require.False(t, public.VerifyCached(other, sig, cache))
+			require.Equal(t, VerifyCacheStats{Hits: 1, Misses: 3, Len: 1}, cache.Stats())
+
+			// nor does an entry answer for another key
+			public2, _, err := Generate(al, nil)
+			require.NoError(t, err)
+			require.False(t, public2.VerifyCached(msg, sig, cache))
+
+			// a nil cache just verifies
+			require.True(t, public.VerifyCached(msg, sig, nil))
+		})
+	}
+}
+
+func TestVerifyCacheEviction(t *testing.T) {
+	public, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	msgs := make([][]byte, 4)
+	sigs := make([]Signature, len(msgs))
+	for i := range msgs {
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i] = private.Sign(msgs[i])
+	}
+	cache := NewVerifyCache(3)
+	for i := 0; i < 3; i++ {
+		require.True(t, cache.Verify(public, msgs[i], sigs[i]))
+	}
+	// use 0, so that 1 is the least recently used
+	require.True(t, cache.Verify(public, msgs[0], sigs[0]))
+	require.True(t, cache.Verify(public, msgs[3], sigs[3]))
+	require.Equal(t, VerifyCacheStats{Hits: 1, Misses: 4, Len: 3}, cache.Stats())
+
+	for _, i := range []int{0, 2, 3} {
+		require.True(t, cache.Verify(public, msgs[i], sigs[i]))
+	}
+	require.Equal(t, uint64(4), cache.Stats().Hits)
+	require.True(t, cache.Verify(public, msgs[1], sigs[1]))
+	require.Equal(t, uint64(5), cache.Stats().Misses)
+	require.Equal(t, 3, cache.Stats().Len)
+
+	require.Equal(t, 1, NewVerifyCache(0).capacity)
+}
+
+func TestVerifyCacheConcurrent(t *testing.T) {
+	public, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	msgs := make([][]byte, 8)
+	sigs := make([]Signature, len(msgs))
+	for i := range msgs {
+		msgs[i] = []byte(fmt.Sprintf("message %d", i))
+		sigs[i] = private.Sign(msgs[i])
+	}
+	cache := NewVerifyCache(4)
+
+	const workers, rounds = 8, 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for r := 0; r < rounds; r++ {
+				i := (w + r) % len(msgs)
+				if !public.VerifyCached(msgs[i], sigs[i], cache) {
+					t.Errorf("verification %d failed", i)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	stats := cache.Stats()
+	require.Equal(t, uint64(workers*rounds), stats.Hits+stats.Misses)
+	require.Equal(t, 4, stats.Len)
+}
@@ -0,0 +1,127 @@
+package signature
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// captureWiped installs a zeroizeHook recording every buffer wiped; call
+// the returned function to remove it
+func captureWiped() (*[][]byte, func()) {
+	var wiped [][]byte
+	zeroizeHook = func(buffers ...[]byte) {
+		wiped = append(wiped, buffers...)
+	}
+	return &wiped, func() { zeroizeHook = nil }
+}
+
+func requireAllZero(t *testing.T, buffers [][]byte) {
+	t.Helper()
+	for _, b := range buffers {
+		for i := range b {
+			require.Zero(t, b[i], "byte %d of %x", i, b)
+		}
+	}
+}
+
+func TestZeroizeWipesKeys(t *testing.T) {
+	for _, al := range []Algorithm{Ed25519, Secp256k1} {
+		t.Run(NameOf(al), func(t *testing.T) {
+			public, private, err := Generate(al, nil)
+			require.NoError(t, err)
+			privX, err := RawPrivateKey(al, private.KeyBytes(), []byte("chain code"))
+			require.NoError(t, err)
+			pubX, err := RawPublicKey(al, public.KeyBytes(), []byte("chain code"))
+			require.NoError(t, err)
+
+			for _, kt := range []struct {
+				k    Key
+				size int
+			}{
+				{privX, al.PrivateKeySize()},
+				{pubX, al.PublicKeySize()},
+			} {
+				keyBytes, extra := kt.k.KeyBytes(), kt.k.ExtraBytes()
+				require.NotEqual(t, make([]byte, len(keyBytes)), keyBytes)
+
+				wiped, restore := captureWiped()
+				kt.k.Zeroize()
+				restore()
+				require.Len(t, *wiped, 2)
+				require.Len(t, (*wiped)[0], kt.size)
+				require.Len(t, (*wiped)[1], len("chain code"))
+				requireAllZero(t, *wiped)
+				// slices taken before the wipe no longer reveal the key
+				requireAllZero(t, [][]byte{keyBytes, extra})
+				require.Equal(t, Null, kt.k.Algorithm())
+				require.Empty(t, kt.k.KeyBytes())
+				require.Empty(t, kt.k.ExtraBytes())
+			}
+		})
+	}
+}
+
+func TestZeroizeWipesSignatures(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	for _, sig := range []Signature{
+		private.Sign([]byte("message")),
+		func() Signature {
+			sig, err := private.SignWithContext("ndau-test", []byte("message"))
+			require.NoError(t, err)
+			return sig
+		}(),
+	} {
+		data := sig.Bytes()
+		wiped, restore := captureWiped()
+		sig.Zeroize()
+		restore()
+		require.Len(t, *wiped, 2)
+		require.Len(t, (*wiped)[0], Ed25519.SignatureSize())
+		requireAllZero(t, *wiped)
+		requireAllZero(t, [][]byte{data})
+		require.Nil(t, sig.Algorithm())
+		require.Empty(t, sig.Bytes())
+		require.Empty(t, sig.Context())
+	}
+
+	var nilSig *Signature
+	require.NotPanics(t, nilSig.Zeroize)
+}
+
+func TestEqualConstantTime(t *testing.T) {
+	_, private, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	_, other, err := Generate(Ed25519, nil)
+	require.NoError(t, err)
+	_, secp, err := Generate(Secp256k1, nil)
+	require.NoError(t, err)
+
+	same, err := RawPrivateKey(Ed25519, append([]byte(nil), private.KeyBytes()...), nil)
+	require.NoError(t, err)
+	withExtra, err := RawPrivateKey(Ed25519, private.KeyBytes(), []byte{1})
+	require.NoError(t, err)
+	secpBytes, err := RawPrivateKey(Secp256k1, append([]byte(nil), secp.KeyBytes()...), nil)
+	require.NoError(t, err)
+	var zero PrivateKey
+
+	require.True(t, private.EqualConstantTime(private))
+	require.True(t, private.EqualConstantTime(*same))
+	require.True(t, secp.EqualConstantTime(*secpBytes))
+	require.True(t, zero.EqualConstantTime(PrivateKey{}))
+	require.False(t, private.EqualConstantTime(other))
+	require.False(t, private.EqualConstantTime(*withExtra))
+	require.False(t, private.EqualConstantTime(secp))
+	require.False(t, private.EqualConstantTime(zero))
+}
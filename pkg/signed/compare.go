@@ -0,0 +1,102 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// These don't branch on their arguments, so they take the same time whatever
+// the values compared, and can be inlined.
+
+// ltMask returns -1 (all bits set) if a < b, and 0 otherwise
+func ltMask(a, b int64) int64 {
+	d := a - b
+	// the sign of a - b, corrected for overflow: Hacker's Delight, 2-12
+	return (d ^ ((a ^ b) & (d ^ a))) >> 63
+}
+
+// Compare returns -1 if a < b, 1 if a > b, and 0 if they are equal
+func Compare(a, b int64) int {
+	return int(ltMask(a, b) - ltMask(b, a))
+}
+
+// lesser returns the lesser of a and b
+func lesser(a, b int64) int64 {
+	return b ^ ((a ^ b) & ltMask(a, b))
+}
+
+// greater returns the greater of a and b
+func greater(a, b int64) int64 {
+	return a ^ ((a ^ b) & ltMask(a, b))
+}
+
+// Clamp returns v, limited to the range [lo, hi]
+//
+// If lo > hi, it returns hi.
+func Clamp(v, lo, hi int64) int64 {
+	return lesser(greater(v, lo), hi)
+}
+
+// Min returns the least of values
+//
+// Errors if values is empty.
+func Min(values []int64) (int64, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	min := values[0]
+	for _, v := range values[1:] {
+		min = lesser(min, v)
+	}
+	return min, nil
+}
+
+// Max returns the greatest of values
+//
+// Errors if values is empty.
+func Max(values []int64) (int64, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	max := values[0]
+	for _, v := range values[1:] {
+		max = greater(max, v)
+	}
+	return max, nil
+}
+
+// abs returns the absolute value of v, except that it returns MinInt64 for
+// MinInt64, which has no positive counterpart
+func abs(v int64) int64 {
+	y := v >> 63       // sign extended, so this is either -1 (0xFFF...) or 0
+	return (v ^ y) - y // twos complement if it was negative
+}
+
+// Abs returns the absolute value of v
+//
+// Errors with ndauerr.ErrOverflow if v is MinInt64, whose absolute value
+// doesn't fit in an int64.
+func Abs(v int64) (int64, error) {
+	a := abs(v)
+	if a < 0 {
+		return 0, ndauerr.ErrOverflow
+	}
+	return a, nil
+}
+
+// AbsSaturating returns the absolute value of v, or MaxInt64 if v is
+// MinInt64, whose absolute value doesn't fit in an int64
+func AbsSaturating(v int64) int64 {
+	a := abs(v)
+	// only abs(MinInt64) is negative, and flipping its bits gives MaxInt64
+	return a ^ (a >> 63)
+}
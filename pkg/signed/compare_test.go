@@ -0,0 +1,146 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// extremes are the values most likely to trip up bit tricks
+var extremes = []int64{math.MinInt64, math.MinInt64 + 1, -2, -1, 0, 1, 2, math.MaxInt64 - 1, math.MaxInt64}
+
+// checkCompare checks the comparisons of a and b against Go's operators
+func checkCompare(t *testing.T, a, b int64) {
+	want := 0
+	switch {
+	case a < b:
+		want = -1
+	case a > b:
+		want = 1
+	}
+	if got := Compare(a, b); got != want {
+		t.Errorf("Compare(%d, %d) = %d, want %d", a, b, got, want)
+	}
+	wantLesser, wantGreater := a, b
+	if b < a {
+		wantLesser, wantGreater = b, a
+	}
+	if got := lesser(a, b); got != wantLesser {
+		t.Errorf("lesser(%d, %d) = %d, want %d", a, b, got, wantLesser)
+	}
+	if got := greater(a, b); got != wantGreater {
+		t.Errorf("greater(%d, %d) = %d, want %d", a, b, got, wantGreater)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	for _, a := range extremes {
+		for _, b := range extremes {
+			checkCompare(t, a, b)
+		}
+	}
+}
+
+func FuzzCompare(f *testing.F) {
+	f.Add(int64(math.MinInt64), int64(math.MaxInt64))
+	f.Add(int64(-1), int64(1))
+	f.Add(int64(7), int64(7))
+	f.Fuzz(checkCompare)
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		name      string
+		v, lo, hi int64
+		want      int64
+	}{
+		{"within", 5, 0, 10, 5},
+		{"below", -5, 0, 10, 0},
+		{"above", 15, 0, 10, 10},
+		{"at lo", 0, 0, 10, 0},
+		{"at hi", 10, 0, 10, 10},
+		{"min to negative range", math.MinInt64, -10, -1, -10},
+		{"max to full range", math.MaxInt64, math.MinInt64, math.MaxInt64, math.MaxInt64},
+		{"empty range", 5, 3, 3, 3},
+		{"inverted range", 5, 10, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Clamp(tt.v, tt.lo, tt.hi); got != tt.want {
+				t.Errorf("Clamp(%d, %d, %d) = %d, want %d", tt.v, tt.lo, tt.hi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []int64
+		min, max int64
+	}{
+		{"single", []int64{3}, 3, 3},
+		{"mixed", []int64{3, -7, 12, 0}, -7, 12},
+		{"extremes", extremes, math.MinInt64, math.MaxInt64},
+		{"repeated", []int64{2, 2, 2}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			min, err := Min(tt.values)
+			if err != nil || min != tt.min {
+				t.Errorf("Min() = %d, %v; want %d", min, err, tt.min)
+			}
+			max, err := Max(tt.values)
+			if err != nil || max != tt.max {
+				t.Errorf("Max() = %d, %v; want %d", max, err, tt.max)
+			}
+		})
+	}
+
+	if _, err := Min(nil); err != ndauerr.ErrEmpty {
+		t.Errorf("Min(nil) error = %v, want %v", err, ndauerr.ErrEmpty)
+	}
+	if _, err := Max(nil); err != ndauerr.ErrEmpty {
+		t.Errorf("Max(nil) error = %v, want %v", err, ndauerr.ErrEmpty)
+	}
+}
+
+func TestAbs(t *testing.T) {
+	tests := []struct {
+		v          int64
+		want       int64
+		wantErr    bool
+		saturating int64
+	}{
+		{0, 0, false, 0},
+		{1, 1, false, 1},
+		{-1, 1, false, 1},
+		{-101, 101, false, 101},
+		{math.MaxInt64, math.MaxInt64, false, math.MaxInt64},
+		{math.MinInt64 + 1, math.MaxInt64, false, math.MaxInt64},
+		{math.MinInt64, 0, true, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := Abs(tt.v)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Abs(%d) = %d, %v; want %d, error %t", tt.v, got, err, tt.want, tt.wantErr)
+		}
+		if err != nil && err != ndauerr.ErrOverflow {
+			t.Errorf("Abs(%d) error = %v, want %v", tt.v, err, ndauerr.ErrOverflow)
+		}
+		if got := AbsSaturating(tt.v); got != tt.saturating {
+			t.Errorf("AbsSaturating(%d) = %d, want %d", tt.v, got, tt.saturating)
+		}
+	}
+}
@@ -11,10 +11,20 @@ package signed
 
 
 import (
+	"math/big"
+
 	"github.com/ericlagergren/decimal"
 	"github.com/ndau/ndaumath/pkg/ndauerr"
 )
 
+// rangeError returns the error for a result which doesn't fit in an int64
+func rangeError(x *decimal.Big) error {
+	if x.Sign() < 0 {
+		return ndauerr.ErrUnderflow
+	}
+	return ndauerr.ErrOverflow
+}
+
 // Add adds two int64s and errors if there is an overflow
 func Add(a, b int64) (int64, error) {
 	x := decimal.WithContext(decimal.Context128).SetMantScale(a, 0)
@@ -22,7 +32,7 @@ func Add(a, b int64) (int64, error) {
 	x.Add(x, y)
 	ret, ok := x.Int64()
 	if !ok {
-		return 0, ndauerr.ErrOverflow
+		return 0, rangeError(x)
 	}
 	return ret, nil
 }
@@ -34,7 +44,7 @@ func Sub(a, b int64) (int64, error) {
 	x.Sub(x, y)
 	ret, ok := x.Int64()
 	if !ok {
-		return 0, ndauerr.ErrOverflow
+		return 0, rangeError(x)
 	}
 	return ret, nil
 }
@@ -46,7 +56,7 @@ func Mul(a, b int64) (int64, error) {
 	x.Mul(x, y)
 	ret, ok := x.Int64()
 	if !ok {
-		return 0, ndauerr.ErrOverflow
+		return 0, rangeError(x)
 	}
 	return ret, nil
 }
@@ -108,19 +118,41 @@ func DivMod(a, b int64) (int64, int64, error) {
 // MulDiv multiplies a int64 value by the ratio n/d without overflowing the int64,
 // provided that the final result does not overflow. Returns error if the result
 // cannot be converted back to int64.
+//
+// The product is computed exactly: a 128-bit decimal context holds only 34
+// digits, which is too few for the product of two large int64s, and rounding
+// it can change the quotient.
+//
+// Compatibility: MulDiv used to compute the product in such a context, so
+// for products of more than 34 digits, its results may differ from those of
+// earlier versions by a few units. Consensus callers don't reach that range
+// with any real input: Duration.UpdateWeightedAverageAge, for example,
+// multiplies an age by a balance, and even at a balance of a billion ndau,
+// the product exceeds 34 digits only for ages of more than 3000 years.
 func MulDiv(v, n, d int64) (int64, error) {
+	q, _, err := MulDivRem(v, n, d)
+	return q, err
+}
+
+// MulDivRem multiplies a int64 value by the ratio n/d, as MulDiv does, and
+// also returns the remainder, such that quot*d + rem == v*n exactly.
+//
+// The quotient truncates towards zero, exactly as for MulDiv, so the
+// remainder has the sign of v*n, and its magnitude is less than that of d;
+// callers which round should look at its sign, not only whether it is
+// zero. Returns an error if the quotient overflows.
+func MulDivRem(v, n, d int64) (quot, rem int64, err error) {
 	if d == 0 {
-		return 0, ndauerr.ErrDivideByZero
+		return 0, 0, ndauerr.ErrDivideByZero
 	}
 
-	x := decimal.WithContext(decimal.Context128).SetMantScale(v, 0)
-	y := decimal.WithContext(decimal.Context128).SetMantScale(n, 0)
-	z := decimal.WithContext(decimal.Context128).SetMantScale(d, 0)
-	x.Mul(x, y)
-	x.QuoInt(x, z)
-	ret, ok := x.Int64()
-	if !ok {
-		return 0, ndauerr.ErrOverflow
+	q := big.NewInt(v)
+	q.Mul(q, big.NewInt(n))
+	r := new(big.Int)
+	q.QuoRem(q, big.NewInt(d), r)
+	if !q.IsInt64() {
+		return 0, 0, ndauerr.ErrOverflow
 	}
-	return ret, nil
+	// |r| < |d|, so r always fits
+	return q.Int64(), r.Int64(), nil
 }
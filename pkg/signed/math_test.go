@@ -11,11 +11,14 @@ package signed
 
 
 import (
+	"errors"
 	"math"
 	"math/big"
 	"math/rand"
 	"testing"
 	"time"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
 )
 
 func TestAdd(t *testing.T) {
@@ -252,6 +255,7 @@ func TestMulDiv(t *testing.T) {
 		{"approximate with ratio > 1", args{147, 155, 132}, 172, false},
 		{"too big with ratio > 1", args{math.MaxInt64, 1557470289173674194, 132472461857540763}, 0, true},
 		{"too big with ratio < 1", args{math.MaxInt64, 132472461857540763, 1557470289173674194}, 784504724644480276, false},
+		{"product beyond 34 digits", args{math.MaxInt64, math.MaxInt64 - 2, math.MaxInt64 - 1}, math.MaxInt64 - 2, false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -267,44 +271,147 @@ func TestMulDiv(t *testing.T) {
 	}
 }
 
-func bigmuldiv(a, b, c int64) int64 {
-	x := big.NewInt(0).SetInt64(a)
-	y := big.NewInt(0).SetInt64(b)
-	z := big.NewInt(0).SetInt64(c)
-	r := big.NewInt(0)
-	q := big.NewInt(0)
-	r.Mul(x, y)
-	q.Div(r, z)
-	return q.Int64()
+// FuzzMulDiv compares MulDiv against big.Int arithmetic
+func FuzzMulDiv(f *testing.F) {
+	f.Add(int64(0), int64(0), int64(1))
+	f.Add(int64(80), int64(2), int64(5))
+	f.Add(int64(-83), int64(2), int64(5))
+	f.Add(int64(83), int64(2), int64(-5))
+	f.Add(int64(math.MaxInt64), int64(math.MaxInt64-2), int64(math.MaxInt64-1))
+	f.Add(int64(math.MaxInt64), int64(2), int64(1))
+	f.Add(int64(math.MinInt64), int64(1), int64(-1))
+	f.Add(int64(1), int64(1), int64(0))
+	f.Fuzz(func(t *testing.T, a, b, c int64) {
+		p, err := MulDiv(a, b, c)
+		if c == 0 {
+			if err == nil {
+				t.Errorf("MulDiv(%d, %d, 0) = %d; want error", a, b, p)
+			}
+			return
+		}
+		x := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		x.Quo(x, big.NewInt(c))
+		if x.IsInt64() != (err == nil) || (err == nil && p != x.Int64()) {
+			t.Errorf("MulDiv(%d, %d, %d) = %d, %v; want %s", a, b, c, p, err, x)
+		}
+	})
 }
 
-func compareOne(r *rand.Rand, t *testing.T) {
-	a := r.Int63()
-	b := r.Int63()
-	c := r.Int63()
-	if b > c {
-		b, c = c, b
-	}
-	p, err := MulDiv(a, b, c)
-	if err != nil {
-		t.Error(err)
+func TestMulDivRem(t *testing.T) {
+	tests := []struct {
+		name    string
+		v, n, d int64
+		quot    int64
+		rem     int64
+		wantErr bool
+	}{
+		{"exact", 80, 2, 5, 32, 0, false},
+		{"remainder", 83, 2, 5, 33, 1, false},
+		{"negative v", -83, 2, 5, -33, -1, false},
+		{"negative d", 83, 2, -5, -33, 1, false},
+		{"both negative", -83, 2, -5, 33, -1, false},
+		{"large intermediate", math.MaxInt64, math.MaxInt64 - 2, math.MaxInt64 - 1, math.MaxInt64 - 2, math.MaxInt64 - 2, false},
+		{"divide by zero", 1, 1, 0, 0, 0, true},
+		{"overflow", math.MaxInt64, 2, 1, 0, 0, true},
 	}
-	q := bigmuldiv(a, b, c)
-	if p != q {
-		t.Errorf("muldiv didn't match results from big.Int: (%v %v %v) %v != %v", a, b, c, p, q)
-		t.Errorf("a=%x b=%x\n", a, b)
-		a /= 10
-		b /= 10
-		c /= 10
-		p, _ = MulDiv(a, b, c)
-		q := bigmuldiv(a, b, c)
-		t.Errorf("/10: (%v %v %v) %v == %v", a, b, c, p, q)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quot, rem, err := MulDivRem(tt.v, tt.n, tt.d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MulDivRem() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if quot != tt.quot || rem != tt.rem {
+				t.Errorf("MulDivRem() = %d, %d, want %d, %d", quot, rem, tt.quot, tt.rem)
+			}
+		})
 	}
 }
 
-func TestMulDivFuzz(t *testing.T) {
+func TestMulDivRemFuzz(t *testing.T) {
 	r := rand.New(rand.NewSource(time.Now().Unix()))
 	for i := 0; i < 10000; i++ {
-		compareOne(r, t)
+		v, n, d := int64(r.Uint64()), int64(r.Uint64()), int64(r.Uint64())
+		if r.Intn(2) == 0 {
+			n >>= uint(r.Intn(64))
+		}
+		if d == 0 {
+			continue
+		}
+		quot, rem, err := MulDivRem(v, n, d)
+		mulDiv, mdErr := MulDiv(v, n, d)
+		if (err == nil) != (mdErr == nil) {
+			t.Fatalf("MulDivRem(%d, %d, %d) error = %v; MulDiv error = %v", v, n, d, err, mdErr)
+		}
+		if err != nil {
+			continue
+		}
+		if quot != mulDiv {
+			t.Errorf("MulDivRem(%d, %d, %d) quotient %d != MulDiv %d", v, n, d, quot, mulDiv)
+		}
+		// quot*d + rem == v*n
+		lhs := new(big.Int).Mul(big.NewInt(quot), big.NewInt(d))
+		lhs.Add(lhs, big.NewInt(rem))
+		rhs := new(big.Int).Mul(big.NewInt(v), big.NewInt(n))
+		if lhs.Cmp(rhs) != 0 {
+			t.Errorf("MulDivRem(%d, %d, %d) = %d, %d does not reconstruct the product", v, n, d, quot, rem)
+		}
+	}
+}
+
+func TestMulDivRemConservation(t *testing.T) {
+	// distribute a total among shares without losing any dust, by carrying
+	// each remainder into the next share
+	const total = int64(1000000007)
+	shares := []int64{3, 7, 11, 13, 17}
+	var denom int64
+	for _, s := range shares {
+		denom += s
+	}
+	var distributed, carry int64
+	for _, s := range shares {
+		quot, rem, err := MulDivRem(total, s, denom)
+		if err != nil {
+			t.Fatal(err)
+		}
+		carry += rem
+		quot += carry / denom
+		carry %= denom
+		distributed += quot
+	}
+	if distributed != total {
+		t.Errorf("distributed %d of %d", distributed, total)
+	}
+}
+
+func TestErrorKinds(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (int64, error)
+		want error
+	}{
+		{"add overflow", func() (int64, error) { return Add(math.MaxInt64, 1) }, ndauerr.ErrOverflow},
+		{"add underflow", func() (int64, error) { return Add(math.MinInt64, -1) }, ndauerr.ErrUnderflow},
+		{"sub overflow", func() (int64, error) { return Sub(math.MaxInt64, -1) }, ndauerr.ErrOverflow},
+		{"sub underflow", func() (int64, error) { return Sub(math.MinInt64, 1) }, ndauerr.ErrUnderflow},
+		{"mul overflow", func() (int64, error) { return Mul(math.MinInt64, -1) }, ndauerr.ErrOverflow},
+		{"mul underflow", func() (int64, error) { return Mul(math.MaxInt64, -2) }, ndauerr.ErrUnderflow},
+		{"div by zero", func() (int64, error) { return Div(1, 0) }, ndauerr.ErrDivideByZero},
+		{"muldiv by zero", func() (int64, error) { return MulDiv(1, 1, 0) }, ndauerr.ErrDivideByZero},
+		{"muldiv overflow", func() (int64, error) { return MulDiv(math.MaxInt64, 2, 1) }, ndauerr.ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.op()
+			if err != tt.want {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("errors.Is(%v, %v) = false", err, tt.want)
+			}
+		})
 	}
 }
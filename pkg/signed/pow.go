@@ -0,0 +1,102 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// PowChecked returns base raised to the power exp, and errors if the result
+// overflows an int64.
+//
+// It takes O(log exp) multiplications. It is an error if exp is negative.
+func PowChecked(base, exp int64) (int64, error) {
+	if exp < 0 {
+		return 0, errors.New("exponent must not be negative")
+	}
+	result := int64(1)
+	var err error
+	for exp > 0 {
+		if exp&1 == 1 {
+			result, err = Mul(result, base)
+			if err != nil {
+				return 0, err
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			// squaring the base may overflow even when the result would not,
+			// but only if the remaining exponent would overflow it anyway
+			base, err = Mul(base, base)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// maxGeometricBits limits the size of the intermediate values of
+// GeometricSum
+const maxGeometricBits = 1 << 20
+
+// GeometricSum returns the sum of the n terms of the geometric series
+//
+//	base + base*r + base*r^2 + ... + base*r^(n-1)
+//
+// where r = ratioNum/ratioDen.
+//
+// The sum is computed exactly in closed form, then truncated towards zero,
+// like MulDiv. This differs from summing the terms as each is truncated in
+// turn. It errors if ratioDen is 0, if n is negative, if the result
+// overflows an int64, or if the exact intermediate values would be
+// impractically large: n times the bit length of the ratio's larger part
+// may not exceed 2^20.
+func GeometricSum(base, ratioNum, ratioDen, n int64) (int64, error) {
+	if ratioDen == 0 {
+		return 0, ndauerr.ErrDivideByZero
+	}
+	if n < 0 {
+		return 0, errors.New("number of terms must not be negative")
+	}
+	if n == 0 || base == 0 {
+		return 0, nil
+	}
+	if ratioNum == ratioDen {
+		return Mul(base, n)
+	}
+
+	num := big.NewInt(ratioNum)
+	den := big.NewInt(ratioDen)
+	bits := num.BitLen()
+	if den.BitLen() > bits {
+		bits = den.BitLen()
+	}
+	if n > maxGeometricBits/int64(bits) {
+		return 0, errors.New("too many terms")
+	}
+
+	// base * (num^n - den^n) / (den^(n-1) * (num - den))
+	bn := big.NewInt(n)
+	numerator := new(big.Int).Exp(num, bn, nil)
+	numerator.Sub(numerator, new(big.Int).Exp(den, bn, nil))
+	numerator.Mul(numerator, big.NewInt(base))
+	denominator := new(big.Int).Exp(den, bn.Sub(bn, big.NewInt(1)), nil)
+	denominator.Mul(denominator, num.Sub(num, den))
+	numerator.Quo(numerator, denominator)
+	if !numerator.IsInt64() {
+		return 0, ndauerr.ErrOverflow
+	}
+	return numerator.Int64(), nil
+}
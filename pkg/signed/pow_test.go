@@ -0,0 +1,127 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestPowChecked(t *testing.T) {
+	tests := []struct {
+		name      string
+		base, exp int64
+		want      int64
+		wantErr   bool
+	}{
+		{"zero exponent", 7, 0, 1, false},
+		{"zero to zero", 0, 0, 1, false},
+		{"zero", 0, 5, 0, false},
+		{"one", 1, math.MaxInt64, 1, false},
+		{"minus one even", -1, 1 << 40, 1, false},
+		{"minus one odd", -1, 1<<40 + 1, -1, false},
+		{"simple", 3, 4, 81, false},
+		{"negative base", -3, 3, -27, false},
+		{"ten", 10, 18, 1000000000000000000, false},
+		{"ten overflow", 10, 19, 0, true},
+		{"max power of two", 2, 62, 1 << 62, false},
+		{"power of two overflow", 2, 63, 0, true},
+		{"min", -2, 63, math.MinInt64, false},
+		{"huge exponent", 2, math.MaxInt64, 0, true},
+		{"negative exponent", 2, -1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PowChecked(tt.base, tt.exp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PowChecked() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("PowChecked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPowCheckedFuzz(t *testing.T) {
+	for i := 0; i < 10000; i++ {
+		// 400^7 fits in an int64, so the naive product can't overflow
+		base := rand.Int63n(801) - 400
+		exp := rand.Int63n(8)
+		want := int64(1)
+		for j := int64(0); j < exp; j++ {
+			want *= base
+		}
+		got, err := PowChecked(base, exp)
+		if err != nil || got != want {
+			t.Fatalf("PowChecked(%d, %d) = %d, %v; want %d", base, exp, got, err, want)
+		}
+	}
+}
+
+func TestGeometricSum(t *testing.T) {
+	tests := []struct {
+		name              string
+		base, num, den, n int64
+		want              int64
+		wantErr           bool
+	}{
+		{"no terms", 5, 2, 1, 0, 0, false},
+		{"one term", 5, 2, 1, 1, 5, false},
+		{"doubling", 1, 2, 1, 10, 1023, false},
+		{"unit ratio", 7, 3, 3, 100, 700, false},
+		{"halving", 1024, 1, 2, 10, 2046, false},
+		{"exact then truncated", 100, 1, 3, 3, 144, false},
+		{"zero ratio", 9, 0, 5, 10, 9, false},
+		{"alternating", 5, -1, 1, 5, 5, false},
+		{"alternating even", 5, -1, 1, 4, 0, false},
+		{"negative base", -1, 2, 1, 10, -1023, false},
+		{"negative denominator", 1, 2, -1, 3, 3, false},
+		{"max", 1, 2, 1, 63, math.MaxInt64, false},
+		{"overflow", 1, 2, 1, 64, 0, true},
+		{"unit ratio overflow", math.MaxInt64, 1, 1, 2, 0, true},
+		{"zero denominator", 1, 1, 0, 1, 0, true},
+		{"negative terms", 1, 2, 1, -1, 0, true},
+		{"too many terms", 1, 1, 2, 1 << 40, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GeometricSum(tt.base, tt.num, tt.den, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GeometricSum() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GeometricSum() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeometricSumMatchesIntegerSeries(t *testing.T) {
+	// with integer ratios, no term is ever truncated, so the closed form
+	// must match the naive sum exactly
+	for i := 0; i < 1000; i++ {
+		base := rand.Int63n(2001) - 1000
+		ratio := rand.Int63n(11) - 5
+		n := rand.Int63n(10)
+		want, term := int64(0), base
+		for j := int64(0); j < n; j++ {
+			want += term
+			term *= ratio
+		}
+		got, err := GeometricSum(base, ratio, 1, n)
+		if err != nil || got != want {
+			t.Fatalf("GeometricSum(%d, %d, 1, %d) = %d, %v; want %d", base, ratio, n, got, err, want)
+		}
+	}
+}
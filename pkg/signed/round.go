@@ -0,0 +1,97 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// RoundingMode determines how the inexact result of a division is rounded
+// to an integer.
+type RoundingMode int
+
+// These are the supported rounding modes.
+const (
+	// Truncate rounds towards zero. This is what MulDiv does.
+	Truncate RoundingMode = iota
+	// HalfEven rounds to the nearest integer, and ties to the even integer.
+	HalfEven
+	// HalfUp rounds to the nearest integer, and ties away from zero.
+	HalfUp
+	// Ceil rounds towards positive infinity.
+	Ceil
+	// Floor rounds towards negative infinity.
+	Floor
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case Truncate:
+		return "Truncate"
+	case HalfEven:
+		return "HalfEven"
+	case HalfUp:
+		return "HalfUp"
+	case Ceil:
+		return "Ceil"
+	case Floor:
+		return "Floor"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// MulDivRound multiplies a int64 value by the ratio n/d without overflowing
+// the int64, provided that the final result does not overflow, and rounds
+// the result according to mode.
+func MulDivRound(v, n, d int64, mode RoundingMode) (int64, error) {
+	if d == 0 {
+		return 0, ndauerr.ErrDivideByZero
+	}
+
+	q := big.NewInt(v)
+	q.Mul(q, big.NewInt(n))
+	denom := big.NewInt(d)
+	r := new(big.Int)
+	// QuoRem truncates towards zero, so q is already the Truncate result
+	q.QuoRem(q, denom, r)
+
+	if r.Sign() != 0 {
+		// the sign of the exact result; q may be 0, so it can't be used
+		sign := r.Sign() * denom.Sign()
+		// how the magnitude of the remainder compares to half the denominator
+		half := r.Lsh(r.Abs(r), 1).Cmp(denom.Abs(denom))
+		away := false
+		switch mode {
+		case Truncate:
+		case Ceil:
+			away = sign > 0
+		case Floor:
+			away = sign < 0
+		case HalfUp:
+			away = half >= 0
+		case HalfEven:
+			away = half > 0 || (half == 0 && q.Bit(0) == 1)
+		default:
+			return 0, fmt.Errorf("unknown rounding mode %s", mode)
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(sign)))
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, ndauerr.ErrOverflow
+	}
+	return q.Int64(), nil
+}
@@ -0,0 +1,84 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMulDivRound(t *testing.T) {
+	type args struct {
+		v, n, d int64
+	}
+	// results listed in order Truncate, HalfEven, HalfUp, Ceil, Floor
+	tests := []struct {
+		name string
+		args args
+		want [5]int64
+	}{
+		{"exact", args{10, 3, 5}, [5]int64{6, 6, 6, 6, 6}},
+		{"2.5", args{5, 1, 2}, [5]int64{2, 2, 3, 3, 2}},
+		{"3.5", args{7, 1, 2}, [5]int64{3, 4, 4, 4, 3}},
+		{"2.6", args{13, 1, 5}, [5]int64{2, 3, 3, 3, 2}},
+		{"-2.5", args{-5, 1, 2}, [5]int64{-2, -2, -3, -2, -3}},
+		{"-3.5", args{7, -1, 2}, [5]int64{-3, -4, -4, -3, -4}},
+		{"-2.4", args{12, 1, -5}, [5]int64{-2, -2, -2, -2, -3}},
+		{"-2.6", args{-13, -1, -5}, [5]int64{-2, -3, -3, -2, -3}},
+		{"0.5", args{1, 1, 2}, [5]int64{0, 0, 1, 1, 0}},
+		{"-0.5", args{-1, 1, 2}, [5]int64{0, 0, -1, 0, -1}},
+		{"-0.2", args{-1, 1, 5}, [5]int64{0, 0, 0, 0, -1}},
+		{"positive from negatives", args{-5, -1, 2}, [5]int64{2, 2, 3, 3, 2}},
+	}
+	for _, tt := range tests {
+		for mode := Truncate; mode <= Floor; mode++ {
+			t.Run(tt.name+" "+mode.String(), func(t *testing.T) {
+				got, err := MulDivRound(tt.args.v, tt.args.n, tt.args.d, mode)
+				if err != nil {
+					t.Errorf("MulDivRound() error = %v", err)
+					return
+				}
+				if got != tt.want[mode] {
+					t.Errorf("MulDivRound() = %v, want %v", got, tt.want[mode])
+				}
+			})
+		}
+	}
+}
+
+func TestMulDivRoundMatchesMulDiv(t *testing.T) {
+	for _, v := range []int64{0, 1, -7, 1000003, math.MinInt32, math.MaxInt64} {
+		for _, n := range []int64{1, -3, 99999989} {
+			for _, d := range []int64{1, 7, -100000000, math.MaxInt64} {
+				want, werr := MulDiv(v, n, d)
+				got, gerr := MulDivRound(v, n, d, Truncate)
+				if (werr != nil) != (gerr != nil) || got != want {
+					t.Errorf("MulDivRound(%d, %d, %d, Truncate) = %d, %v; MulDiv = %d, %v", v, n, d, got, gerr, want, werr)
+				}
+			}
+		}
+	}
+}
+
+func TestMulDivRoundErrors(t *testing.T) {
+	if _, err := MulDivRound(1, 1, 0, HalfEven); err == nil {
+		t.Error("expected divide by zero")
+	}
+	if _, err := MulDivRound(math.MaxInt64, 2, 1, HalfEven); err == nil {
+		t.Error("expected overflow")
+	}
+	if _, err := MulDivRound(math.MinInt64, -1, 1, Truncate); err == nil {
+		t.Error("expected overflow")
+	}
+	if _, err := MulDivRound(1, 1, 2, RoundingMode(99)); err == nil {
+		t.Error("expected unknown mode")
+	}
+}
@@ -0,0 +1,84 @@
+package signed
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSumChecked(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []int64
+		want    int64
+		wantErr bool
+	}{
+		{"nil", nil, 0, false},
+		{"single", []int64{7}, 7, false},
+		{"simple", []int64{1, 2, 3, 4}, 10, false},
+		{"mixed signs", []int64{10, -3, -20}, -13, false},
+		{"max", []int64{math.MaxInt64 - 1, 1}, math.MaxInt64, false},
+		{"min", []int64{math.MinInt64 + 1, -1}, math.MinInt64, false},
+		{"overflow", []int64{math.MaxInt64, 1}, 0, true},
+		{"underflow", []int64{math.MinInt64, -1}, 0, true},
+		{"intermediate overflow recovers", []int64{math.MaxInt64, math.MaxInt64, -math.MaxInt64}, math.MaxInt64, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SumChecked(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SumChecked() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SumChecked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotProduct(t *testing.T) {
+	type args struct {
+		a     []int64
+		b     []int64
+		denom int64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    int64
+		wantErr bool
+	}{
+		{"empty", args{nil, nil, 1}, 0, false},
+		{"simple", args{[]int64{1, 2, 3}, []int64{4, 5, 6}, 1}, 32, false},
+		{"with denominator", args{[]int64{1, 2, 3}, []int64{4, 5, 6}, 5}, 6, false},
+		{"negative truncated towards zero", args{[]int64{-1, -2, -3}, []int64{4, 5, 6}, 5}, -6, false},
+		{"no intermediate truncation", args{[]int64{1, 1, 1}, []int64{1, 1, 1}, 2}, 1, false},
+		{"products beyond int64", args{[]int64{math.MaxInt64, math.MaxInt64}, []int64{math.MaxInt64, math.MaxInt64}, math.MaxInt64}, 0, true},
+		{"products beyond int64 scaled back", args{[]int64{math.MaxInt64, math.MaxInt64}, []int64{1000, 1000}, 4000}, math.MaxInt64 / 2, false},
+		{"overflow", args{[]int64{math.MaxInt64}, []int64{2}, 1}, 0, true},
+		{"length mismatch", args{[]int64{1, 2}, []int64{1}, 1}, 0, true},
+		{"divide by zero", args{[]int64{1}, []int64{1}, 0}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DotProduct(tt.args.a, tt.args.b, tt.args.denom)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DotProduct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DotProduct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// These helpers operate on sequences of block times, oldest first. Validator
+// clocks are never perfectly synchronized, so a block may carry a timestamp
+// slightly earlier than its predecessor's; the median of recent block times
+// is a clock which is robust to a minority of skewed or dishonest validators.
+
+// CheckMonotonic returns an error unless times is nondecreasing, within
+// tolerance.
+//
+// Each timestamp may precede the latest timestamp before it by at most
+// tolerance. It is compared with the latest, rather than the immediately
+// preceding, timestamp so that a sequence can't drift backwards by tolerance
+// at every step. A tolerance of 0 requires times to be nondecreasing.
+func CheckMonotonic(times []Timestamp, tolerance Duration) error {
+	if tolerance < 0 {
+		return fmt.Errorf("tolerance must not be negative; got %d", tolerance)
+	}
+	if len(times) == 0 {
+		return nil
+	}
+	latest := times[0]
+	for i, t := range times[1:] {
+		if latest.Since(t) > tolerance {
+			return fmt.Errorf(
+				"timestamp %d (%s) precedes latest prior timestamp (%s) by more than %s",
+				i+1, t, latest, tolerance,
+			)
+		}
+		if t > latest {
+			latest = t
+		}
+	}
+	return nil
+}
+
+// MedianTimestamp returns the median of the last n of times
+//
+// If there are fewer than n times, it is the median of all of them. For an
+// even number of times, it is the midpoint of the middle two, truncated
+// towards the earlier. times is not modified, and need not be sorted.
+// Errors if times is empty or n is not positive.
+func MedianTimestamp(times []Timestamp, n int) (Timestamp, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("n must be positive; got %d", n)
+	}
+	if len(times) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	if len(times) > n {
+		times = times[len(times)-n:]
+	}
+	sorted := append([]Timestamp(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid], nil
+	}
+	// hi - lo always fits in a uint64, so the midpoint can't overflow
+	lo, hi := sorted[mid-1], sorted[mid]
+	return lo + Timestamp((uint64(hi)-uint64(lo))/2), nil
+}
@@ -0,0 +1,90 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+func TestCheckMonotonic(t *testing.T) {
+	tests := []struct {
+		name      string
+		times     []Timestamp
+		tolerance Duration
+		wantErr   bool
+	}{
+		{"empty", nil, 0, false},
+		{"single", []Timestamp{5}, 0, false},
+		{"increasing", []Timestamp{1, 2, 3}, 0, false},
+		{"repeated", []Timestamp{1, 1, 1}, 0, false},
+		{"decreasing", []Timestamp{1, 3, 2}, 0, true},
+		{"within tolerance", []Timestamp{10, 8, 12}, 2, false},
+		{"beyond tolerance", []Timestamp{10, 7, 12}, 2, true},
+		{"no drift", []Timestamp{10, 9, 8, 7}, 2, true},
+		{"measured from latest", []Timestamp{10, 12, 9}, 2, true},
+		{"negative tolerance", []Timestamp{1, 2}, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckMonotonic(tt.times, tt.tolerance)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckMonotonic() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMedianTimestamp(t *testing.T) {
+	tests := []struct {
+		name    string
+		times   []Timestamp
+		n       int
+		want    Timestamp
+		wantErr bool
+	}{
+		{"empty", nil, 3, 0, true},
+		{"zero n", []Timestamp{1}, 0, 0, true},
+		{"single", []Timestamp{7}, 11, 7, false},
+		{"odd", []Timestamp{5, 1, 4, 2, 3}, 5, 3, false},
+		{"even", []Timestamp{4, 1, 3, 2}, 4, 2, false},
+		{"last n", []Timestamp{100, 100, 1, 2, 3}, 3, 2, false},
+		{"fewer than n", []Timestamp{1, 2, 3}, 11, 2, false},
+		{"extremes", []Timestamp{0, math.MaxInt64}, 2, math.MaxInt64 / 2, false},
+		{"top", []Timestamp{math.MaxInt64 - 1, math.MaxInt64}, 2, math.MaxInt64 - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := append([]Timestamp(nil), tt.times...)
+			got, err := MedianTimestamp(tt.times, tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MedianTimestamp() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("MedianTimestamp() = %d, want %d", got, tt.want)
+			}
+			for i := range orig {
+				if tt.times[i] != orig[i] {
+					t.Errorf("input was modified: %v, want %v", tt.times, orig)
+					break
+				}
+			}
+		})
+	}
+
+	_, err := MedianTimestamp(nil, 1)
+	if err != ndauerr.ErrEmpty {
+		t.Errorf("MedianTimestamp(nil) error = %v, want %v", err, ndauerr.ErrEmpty)
+	}
+}
@@ -0,0 +1,121 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"encoding"
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// A BoundedDuration is a Duration which must lie within [Min, Max].
+//
+// It is intended for decoding system variables safely: set the bounds, then
+// decode into it. Decoding fails, leaving Value unchanged, if the decoded
+// duration is out of bounds.
+//
+// Only the Value is serialized, exactly as a plain Duration; the bounds are
+// never read from or written to the wire.
+type BoundedDuration struct {
+	Value Duration
+	Min   Duration
+	Max   Duration
+}
+
+// ensure BoundedDuration implements encoding.Text(Un)Marshaler and the msgp interfaces
+var _ encoding.TextMarshaler = (*BoundedDuration)(nil)
+var _ encoding.TextUnmarshaler = (*BoundedDuration)(nil)
+var _ msgp.Marshaler = (*BoundedDuration)(nil)
+var _ msgp.Unmarshaler = (*BoundedDuration)(nil)
+var _ msgp.Encodable = (*BoundedDuration)(nil)
+var _ msgp.Decodable = (*BoundedDuration)(nil)
+var _ msgp.Sizer = (*BoundedDuration)(nil)
+
+// Check returns an error if d is not within the bounds
+func (b BoundedDuration) Check(d Duration) error {
+	if b.Min > b.Max {
+		return fmt.Errorf("invalid bounds: min %s exceeds max %s", b.Min, b.Max)
+	}
+	if d < b.Min || d > b.Max {
+		return fmt.Errorf("duration %s out of bounds [%s, %s]", d, b.Min, b.Max)
+	}
+	return nil
+}
+
+// Set the Value to d, if it is within the bounds
+func (b *BoundedDuration) Set(d Duration) error {
+	err := b.Check(d)
+	if err != nil {
+		return err
+	}
+	b.Value = d
+	return nil
+}
+
+// String represents the Value as a human-readable string
+func (b BoundedDuration) String() string {
+	return b.Value.String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b BoundedDuration) MarshalText() ([]byte, error) {
+	return b.Value.MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *BoundedDuration) UnmarshalText(text []byte) error {
+	var d Duration
+	err := d.UnmarshalText(text)
+	if err != nil {
+		return err
+	}
+	return b.Set(d)
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (b BoundedDuration) MarshalMsg(in []byte) ([]byte, error) {
+	return b.Value.MarshalMsg(in)
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (b *BoundedDuration) UnmarshalMsg(in []byte) ([]byte, error) {
+	var d Duration
+	leftover, err := d.UnmarshalMsg(in)
+	if err != nil {
+		return in, err
+	}
+	err = b.Set(d)
+	if err != nil {
+		return in, err
+	}
+	return leftover, nil
+}
+
+// EncodeMsg implements msgp.Encodable
+func (b BoundedDuration) EncodeMsg(en *msgp.Writer) error {
+	return b.Value.EncodeMsg(en)
+}
+
+// DecodeMsg implements msgp.Decodable
+func (b *BoundedDuration) DecodeMsg(dc *msgp.Reader) error {
+	var d Duration
+	err := d.DecodeMsg(dc)
+	if err != nil {
+		return err
+	}
+	return b.Set(d)
+}
+
+// Msgsize implements msgp.Sizer
+func (b BoundedDuration) Msgsize() int {
+	return b.Value.Msgsize()
+}
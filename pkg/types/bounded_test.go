@@ -0,0 +1,97 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestBoundedDurationMsgp(t *testing.T) {
+	bounds := BoundedDuration{Min: Day, Max: Year}
+	tests := []struct {
+		name    string
+		value   Duration
+		wantErr bool
+	}{
+		{"min", Day, false},
+		{"max", Year, false},
+		{"within", 3 * Month, false},
+		{"below", Day - 1, true},
+		{"above", Year + 1, true},
+		{"negative", -Day, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// serialize a plain duration, as a system variable would be
+			bts, err := tt.value.MarshalMsg(nil)
+			require.NoError(t, err)
+
+			b := bounds
+			b.Value = Duration(7 * Day)
+			leftover, err := b.UnmarshalMsg(bts)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, Duration(7*Day), b.Value)
+			} else {
+				require.NoError(t, err)
+				require.Empty(t, leftover)
+				require.Equal(t, tt.value, b.Value)
+
+				// the bounded form serializes exactly as the plain one
+				out, err := b.MarshalMsg(nil)
+				require.NoError(t, err)
+				require.Equal(t, bts, out)
+			}
+
+			// the streaming decoder agrees
+			s := bounds
+			err = s.DecodeMsg(msgp.NewReader(bytes.NewReader(bts)))
+			require.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestBoundedDurationText(t *testing.T) {
+	b := BoundedDuration{Min: Day, Max: Year}
+	require.NoError(t, b.UnmarshalText([]byte("3m")))
+	require.Equal(t, Duration(3*Month), b.Value)
+	text, err := b.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "3m", string(text))
+
+	require.Error(t, b.UnmarshalText([]byte("2y")))
+	require.Error(t, b.UnmarshalText([]byte("not a duration")))
+	require.Equal(t, Duration(3*Month), b.Value)
+
+	// JSON uses the text form
+	var sv struct {
+		Period BoundedDuration
+	}
+	sv.Period = BoundedDuration{Min: Day, Max: Month}
+	require.Error(t, json.Unmarshal([]byte(`{"Period": "1y"}`), &sv))
+	require.NoError(t, json.Unmarshal([]byte(`{"Period": "10d"}`), &sv))
+	require.Equal(t, Duration(10*Day), sv.Period.Value)
+}
+
+func TestBoundedDurationInvalidBounds(t *testing.T) {
+	b := BoundedDuration{Min: Year, Max: Day}
+	require.Error(t, b.Set(Month))
+
+	// the zero value admits only a zero duration
+	var zero BoundedDuration
+	require.NoError(t, zero.Set(0))
+	require.Error(t, zero.Set(1))
+}
@@ -15,12 +15,21 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
-	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
 	"github.com/ndau/ndaumath/pkg/signed"
 )
 
+// errDurationFormat is returned when a duration string is malformed
+var errDurationFormat = errors.New("invalid duration format")
+
+// errWAAOverflow is returned when a weighted average age overflows
+var errWAAOverflow = ndauerr.Specialize(ndauerr.ErrOverflow, "Duration overflow in UpdateWeightedAverageAge")
+
 //go:generate msgp -tests=0
 
 // A Duration is the difference between two Timestamps.
@@ -52,60 +61,124 @@ func (d Duration) TimeDuration() time.Duration {
 //
 // There is no `w` symbol for weeks; use multiples of days
 // or months instead.
+//
+// It accepts exactly the strings matched by constants.DurationFormat, but
+// doesn't use the regular expression: system variables hold many durations,
+// and this is much faster.
 func ParseDuration(s string) (Duration, error) {
-	match := constants.DurationRE.FindStringSubmatch(s)
-	if match == nil {
-		return Duration(0), fmt.Errorf("invalid duration format")
+	var neg bool
+	if strings.HasPrefix(s, "-") {
+		neg = true
+		s = s[1:]
 	}
-
-	// get match groups by name:
-	// https://stackoverflow.com/a/20751656/504550
-	result := make(map[string]string)
-	for i, name := range constants.DurationRE.SubexpNames() {
-		if i != 0 && name != "" {
-			result[name] = match[i]
-		}
+	if r, size := utf8.DecodeRuneInString(s); foldsTo(r, 'p') {
+		s = s[size:]
 	}
 
 	duration := Duration(0)
-	addTime := func(name string, unit uint64) error {
-		if result[name] != "" {
-			value, err := strconv.ParseUint(result[name], 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid integer: %s", result[name])
+	fields := dateFields
+	next := 0
+	inTime := false
+	for s != "" {
+		digits := 0
+		for digits < len(s) && s[digits] >= '0' && s[digits] <= '9' {
+			digits++
+		}
+		r, size := utf8.DecodeRuneInString(s[digits:])
+		if digits == 0 {
+			// only the t separating the date from the time needs no digits
+			if inTime || !foldsTo(r, 't') {
+				return Duration(0), errDurationFormat
 			}
-			duration += Duration(value * unit)
+			fields, next, inTime = timeFields, 0, true
+			s = s[size:]
+			continue
 		}
-		return nil
-	}
 
-	if err := addTime("years", Year); err != nil {
-		return Duration(0), err
-	}
-	if err := addTime("months", Month); err != nil {
-		return Duration(0), err
+		// the fields must appear in order, each at most once
+		found := false
+		for ; next < len(fields); next++ {
+			if fields[next].symbol(r) {
+				found = true
+				break
+			}
+		}
+		if !found || (fields[next].maxDigits > 0 && digits > fields[next].maxDigits) {
+			return Duration(0), errDurationFormat
+		}
+		value, err := strconv.ParseUint(s[:digits], 10, 64)
+		if err != nil {
+			return Duration(0), fmt.Errorf("invalid integer: %s", s[:digits])
+		}
+		duration += Duration(value * fields[next].unit)
+		s = s[digits+size:]
+		if fields[next].suffix != 0 {
+			if r, size := utf8.DecodeRuneInString(s); foldsTo(r, fields[next].suffix) {
+				s = s[size:]
+			}
+		}
+		next++
 	}
-	if err := addTime("days", Day); err != nil {
-		return Duration(0), err
+
+	if neg {
+		duration = -duration
 	}
-	if err := addTime("hours", Hour); err != nil {
-		return Duration(0), err
+
+	return duration, nil
+}
+
+// A durationField is one of the numbered fields of a duration string
+type durationField struct {
+	// symbol reports whether a rune is the field's symbol
+	symbol func(rune) bool
+	// maxDigits is the most digits the field may have, or 0 if unlimited
+	maxDigits int
+	unit      uint64
+	// suffix is an optional rune which may follow the symbol, or 0
+	suffix rune
+}
+
+func symbol(c rune) func(rune) bool {
+	return func(r rune) bool { return foldsTo(r, c) }
+}
+
+// The fields of durations, in the order in which they must appear: dateFields
+// precede the t, and timeFields follow it.
+var (
+	dateFields = []durationField{
+		{symbol: symbol('y'), unit: Year},
+		{symbol: symbol('m'), maxDigits: 2, unit: Month},
+		{symbol: symbol('d'), maxDigits: 2, unit: Day},
 	}
-	if err := addTime("minutes", Minute); err != nil {
-		return Duration(0), err
+	timeFields = []durationField{
+		{symbol: symbol('h'), maxDigits: 2, unit: Hour},
+		{symbol: symbol('m'), maxDigits: 2, unit: Minute},
+		{symbol: symbol('s'), maxDigits: 2, unit: Second},
+		{
+			symbol:    func(r rune) bool { return foldsTo(r, 'u') || foldsTo(r, 'μ') },
+			maxDigits: 6,
+			unit:      Microsecond,
+			suffix:    's',
+		},
 	}
-	if err := addTime("seconds", Second); err != nil {
-		return Duration(0), err
+)
+
+// foldsTo is true if r is c, ignoring case just as the case-insensitive
+// DurationFormat does: by simple case folding, so that for example the long
+// s ſ is an s.
+func foldsTo(r, c rune) bool {
+	if r == c {
+		return true
 	}
-	if err := addTime("micros", Microsecond); err != nil {
-		return Duration(0), err
+	if r < utf8.RuneSelf && c < utf8.RuneSelf {
+		return 'a' <= c && c <= 'z' && r == c-'a'+'A'
 	}
-
-	if result["neg"] != "" {
-		duration = -duration
+	for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+		if r == f {
+			return true
+		}
 	}
-
-	return duration, nil
+	return false
 }
 
 // String represents a Duration as a human-readable string
@@ -168,7 +241,7 @@ func (d *Duration) UpdateWeightedAverageAge(
 		if nb > 0 {
 			waa, err = signed.MulDiv(dur, pb, nb)
 			if err != nil {
-				return errors.New("Duration overflow in UpdateWeightedAverageAge")
+				return errWAAOverflow
 			}
 		}
 	}
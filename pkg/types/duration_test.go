@@ -12,7 +12,9 @@ package types
 
 import (
 	"fmt"
-	"math/rand"
+	"math"
+	"math/big"
+	"strconv"
 	"testing"
 
 	"github.com/ndau/ndaumath/pkg/constants"
@@ -118,6 +120,122 @@ func TestParseDuration(t *testing.T) {
 	}
 }
 
+// parseDurationRE is the original implementation of ParseDuration, by regular
+// expression, which ParseDuration must agree with exactly
+func parseDurationRE(s string) (Duration, error) {
+	match := constants.DurationRE.FindStringSubmatch(s)
+	if match == nil {
+		return Duration(0), fmt.Errorf("invalid duration format")
+	}
+
+	// get match groups by name:
+	// https://stackoverflow.com/a/20751656/504550
+	result := make(map[string]string)
+	for i, name := range constants.DurationRE.SubexpNames() {
+		if i != 0 && name != "" {
+			result[name] = match[i]
+		}
+	}
+
+	duration := Duration(0)
+	addTime := func(name string, unit uint64) error {
+		if result[name] != "" {
+			value, err := strconv.ParseUint(result[name], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid integer: %s", result[name])
+			}
+			duration += Duration(value * unit)
+		}
+		return nil
+	}
+
+	for _, field := range []struct {
+		name string
+		unit uint64
+	}{
+		{"years", Year}, {"months", Month}, {"days", Day},
+		{"hours", Hour}, {"minutes", Minute}, {"seconds", Second}, {"micros", Microsecond},
+	} {
+		if err := addTime(field.name, field.unit); err != nil {
+			return Duration(0), err
+		}
+	}
+
+	if result["neg"] != "" {
+		duration = -duration
+	}
+
+	return duration, nil
+}
+
+// checkParseDuration checks ParseDuration against parseDurationRE
+func checkParseDuration(t *testing.T, s string) {
+	want, wantErr := parseDurationRE(s)
+	got, err := ParseDuration(s)
+	if (err != nil) != (wantErr != nil) || got != want {
+		t.Errorf("ParseDuration(%q) = %d, %v; regexp gives %d, %v", s, got, err, want, wantErr)
+		return
+	}
+	if err != nil && err.Error() != wantErr.Error() {
+		t.Errorf("ParseDuration(%q) error = %q; regexp gives %q", s, err, wantErr)
+	}
+}
+
+// durationCorpus are strings which stretch the duration format
+var durationCorpus = []string{
+	"", "-", "p", "P", "-p", "p-", "pp", "t", "T", "pt", "-pt", "tt", "t-1s",
+	"1y", "1Y", "01y", "18446744073709551615y", "18446744073709551616y", "99999999999999999999y",
+	"1m", "12m", "123m", "1d", "1m1d", "1d1m", "1d1y", "1y1y", "1m1m",
+	"t1h", "t1m", "t1s", "t1h1m1s", "t1s1m", "t1m1h", "1h", "1s", "t1d", "t1y",
+	"t1u", "t1us", "t1uS", "t1U", "t1μ", "t1Μ", "t1µ", "t1μs", "t1µs", "t1uss", "t1usu",
+	"t1234567u", "t123456u", "t1s1u", "t1u1s", "t1ſ", "t1uſ", "1y t1s", " 1y", "1y ",
+	"1", "y", "t1", "1yt", "1y2m3dt", "p1y2m3dt4h5m6s7us", "-P1Y2M3DT4H5M6S7US",
+	"1y\n", "t1s\x00", "\xff", "1\xffy", "p\u0130", "t1\u212a",
+}
+
+func TestParseDurationMatchesRE(t *testing.T) {
+	for _, s := range durationCorpus {
+		checkParseDuration(t, s)
+	}
+	// every duration's string parses back identically
+	for _, d := range []Duration{0, 1, -1, Second, 59 * Minute, Day + 1, 13 * Month, math.MaxInt64, math.MinInt64 + 1} {
+		checkParseDuration(t, d.String())
+	}
+}
+
+func FuzzParseDurationMatchesRE(f *testing.F) {
+	for _, s := range durationCorpus {
+		f.Add(s)
+	}
+	f.Fuzz(checkParseDuration)
+}
+
+func BenchmarkParseDuration(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseDuration("p1y2m3dt4h5m6s7us")
+	}
+}
+
+func BenchmarkParseDurationRE(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = parseDurationRE("p1y2m3dt4h5m6s7us")
+	}
+}
+
+func BenchmarkDuration_String(b *testing.B) {
+	d := Duration(36993906000007)
+	for i := 0; i < b.N; i++ {
+		_ = d.String()
+	}
+}
+
+func BenchmarkDuration_UpdateWeightedAverageAge(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		d := Duration(30 * Day)
+		_ = d.UpdateWeightedAverageAge(Day, 1000*constants.QuantaPerUnit, 5000*constants.QuantaPerUnit)
+	}
+}
+
 // MarshalText not tested because it's trivial
 func TestDuration_UnmarshalText(t *testing.T) {
 	d0 := Duration(0)
@@ -146,74 +264,64 @@ func TestDuration_UnmarshalText(t *testing.T) {
 	}
 }
 
-// randomDuration returns a Duration weighted toward short timestamps
-func randomDuration() Duration {
-	x := 1.0 / (rand.Float64() * 1000)
-	return Duration(x*1000000+1) * Millisecond
-}
-
-func randomQuantity() Ndau {
-	x := 1.0 / (rand.Float64() * 10000)
-	n := Ndau(x*100000) * 1000
-	n += Ndau(rand.Intn(5) * 100000000)
-	return n
-}
-
-// TestDuration_UpdateWeightedAverageAge_Fuzz proves that the
+// FuzzDuration_UpdateWeightedAverageAge proves that the
 // UpdateWeightedAverageAge function is not robust across performing
-// calculations in different order for small values. It constructs a sample WAA
-// value and updates it twice, with two different quantities, at the same
-// timestamp. This is reflective of what happens when a single CreditEAI
-// transaction diverts EAI from two different accounts into the same target
-// account. Unfortunately, when the amounts are small and the times are small,
-// this calculation might be off slightly (so far we've only seen it differ by 1
-// microsecond). This causes a hash mismatch if different nodes do the
-// calculation in different order -- so we added code to CreditEAI's Apply
-// function to sort the list of accounts before iteration.
-func TestDuration_UpdateWeightedAverageAge_Fuzz(t *testing.T) {
-	ntests := 100
-	failureCount := 0
-	for i := 0; i < ntests; i++ {
-		dur := randomDuration()
-		prev := randomQuantity()
-
-		xfer1 := randomQuantity()
-		xfer2 := randomQuantity()
-
-		waaA := randomDuration()
-		waaB := waaA
-
-		bal := prev
-		err := waaA.UpdateWeightedAverageAge(dur, xfer1, bal)
-		if err != nil {
-			t.Errorf("UpdateWeightedAverageAge(1) returned err: %s", err.Error())
+// calculations in different order for small values, but that the difference
+// is bounded. It updates a WAA value twice, with two different quantities, at
+// the same timestamp, in both orders. This is reflective of what happens when
+// a single CreditEAI transaction diverts EAI from two different accounts into
+// the same target account. Unfortunately, when the amounts are small and the
+// times are small, this calculation might be off slightly. This causes a hash
+// mismatch if different nodes do the calculation in different order -- so we
+// added code to CreditEAI's Apply function to sort the list of accounts before
+// iteration.
+//
+// Each update truncates once, and the second scales the error of the first by
+// a factor no greater than 1, so either order gives the exact result, or one
+// microsecond less.
+func FuzzDuration_UpdateWeightedAverageAge(f *testing.F) {
+	// these seeds are known to differ by order
+	f.Add(int64(9648000), int64(1362000), int64(300020000), int64(300013000), int64(400022000))
+	f.Add(int64(4111000), int64(1456000), int64(300011000), int64(100010000), int64(18000))
+	f.Add(int64(1049000), int64(2073000), int64(100022000), int64(200013000), int64(200038000))
+	f.Add(int64(0), int64(Day), int64(0), int64(0), int64(100))
+	f.Add(int64(Year), int64(0), int64(math.MaxInt64 / 4), int64(math.MaxInt64 / 4), int64(math.MaxInt64 / 4))
+	f.Fuzz(func(t *testing.T, waa, dur, prev, xfer1, xfer2 int64) {
+		if waa < 0 || dur < 0 || prev < 0 || xfer1 < 0 || xfer2 < 0 {
+			t.Skip()
 		}
-		bal += xfer1
-		err = waaA.UpdateWeightedAverageAge(0, xfer2, bal)
-		if err != nil {
-			t.Errorf("UpdateWeightedAverageAge(2) returned err: %s", err.Error())
+		total := new(big.Int).Add(big.NewInt(prev), big.NewInt(xfer1))
+		total.Add(total, big.NewInt(xfer2))
+		if waa > math.MaxInt64-dur || !total.IsInt64() {
+			t.Skip()
 		}
 
-		bal = prev
-		err = waaB.UpdateWeightedAverageAge(dur, xfer2, bal)
-		if err != nil {
-			t.Errorf("UpdateWeightedAverageAge(3) returned err: %s", err.Error())
-		}
-		bal += xfer2
-		err = waaB.UpdateWeightedAverageAge(0, xfer1, bal)
-		if err != nil {
-			t.Errorf("UpdateWeightedAverageAge(4) returned err: %s", err.Error())
+		update := func(first, second int64) Duration {
+			d := Duration(waa)
+			err := d.UpdateWeightedAverageAge(Duration(dur), Ndau(first), Ndau(prev))
+			require.NoError(t, err)
+			err = d.UpdateWeightedAverageAge(0, Ndau(second), Ndau(prev+first))
+			require.NoError(t, err)
+			return d
 		}
+		waaA := update(xfer1, xfer2)
+		waaB := update(xfer2, xfer1)
 
-		if waaA != waaB {
-			failureCount++
-			t.Logf("UpdateWeightedAverageAge didn't match (expected):\n %d (%s) != %d (%s)\n dur=%d(%s) prev=%d, xfer1=%d, xfer2=%d\n",
-				waaA, waaA, waaB, waaB, dur, dur, prev, xfer1, xfer2)
+		if total.Sign() == 0 {
+			require.Equal(t, Duration(waa+dur), waaA)
+			require.Equal(t, waaA, waaB)
+			return
 		}
-	}
-	if failureCount == 0 || failureCount > ntests/2 {
-		t.Errorf("UpdateWeightedAverageAge had a different number of failures than expected -- got %d, should have been 1-%d", failureCount, ntests/2)
-	}
+		exact := new(big.Int).Mul(big.NewInt(waa+dur), big.NewInt(prev))
+		exact.Quo(exact, total)
+		for _, got := range []Duration{waaA, waaB} {
+			diff := exact.Int64() - int64(got)
+			if diff < 0 || diff > 1 {
+				t.Errorf("UpdateWeightedAverageAge: got %d, want %s or one less (waa=%d dur=%d prev=%d xfer1=%d xfer2=%d)",
+					got, exact, waa, dur, prev, xfer1, xfer2)
+			}
+		}
+	})
 }
 
 func TestWAAUpdateCalculation(t *testing.T) {
@@ -262,3 +370,20 @@ func TestWAAUpdateCalculation(t *testing.T) {
 	t.Log("real WAA", newWAA)
 	require.LessOrEqual(t, int64(acctCreation.Add(newWAA)), int64(blockTime))
 }
+
+// FuzzParseDuration checks that every duration which parses survives a
+// roundtrip through its String form
+func FuzzParseDuration(f *testing.F) {
+	for _, s := range []string{"", "t0s", "t1s", "1m", "t1m", "p1y2m3dt4h5m6s", "P1Y2M3DT4H5M6S", "-1y", "t1us", "1y2m"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		d, err := ParseDuration(s)
+		if err != nil {
+			return
+		}
+		got, err := ParseDuration(d.String())
+		require.NoError(t, err, "parsing %q, the String of %q", d.String(), s)
+		require.Equal(t, d, got, "roundtrip of %q through %q", s, d.String())
+	})
+}
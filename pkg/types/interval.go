@@ -0,0 +1,108 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"errors"
+	"fmt"
+)
+
+//go:generate msgp -tests=0
+
+//msgp:tuple Interval
+
+// An Interval is a span of time between two Timestamps.
+//
+// Intervals are half-open: the Start is included, but the End is not. This
+// means that adjacent intervals, where one's End is the other's Start, never
+// overlap, and that every Timestamp falls into exactly one of a sequence of
+// adjacent intervals.
+//
+// An Interval whose End is not after its Start is empty: it contains nothing
+// and overlaps nothing.
+type Interval struct {
+	Start Timestamp
+	End   Timestamp
+}
+
+// NewInterval creates an Interval, ensuring that it is well-formed
+func NewInterval(start, end Timestamp) (Interval, error) {
+	if end < start {
+		return Interval{}, errors.New("interval end precedes start")
+	}
+	return Interval{Start: start, End: end}, nil
+}
+
+// IntervalFor creates an Interval beginning at start and lasting for d
+func IntervalFor(start Timestamp, d Duration) (Interval, error) {
+	if d < 0 {
+		return Interval{}, errors.New("interval duration must not be negative")
+	}
+	return Interval{Start: start, End: start.Add(d)}, nil
+}
+
+// IsEmpty is true when the interval contains no Timestamps
+func (i Interval) IsEmpty() bool {
+	return i.End <= i.Start
+}
+
+// Duration returns the length of this Interval
+//
+// Empty intervals have a duration of 0.
+func (i Interval) Duration() Duration {
+	if i.IsEmpty() {
+		return 0
+	}
+	return i.End.Since(i.Start)
+}
+
+// Contains is true when t is within this Interval
+//
+// As intervals are half-open, the Start is contained but the End is not.
+func (i Interval) Contains(t Timestamp) bool {
+	return i.Start <= t && t < i.End
+}
+
+// Covers is true when every Timestamp in o is also in this Interval
+//
+// Every interval covers the empty interval.
+func (i Interval) Covers(o Interval) bool {
+	if o.IsEmpty() {
+		return true
+	}
+	return i.Start <= o.Start && o.End <= i.End
+}
+
+// Overlaps is true when at least one Timestamp is in both Intervals
+func (i Interval) Overlaps(o Interval) bool {
+	return !i.Intersect(o).IsEmpty()
+}
+
+// Intersect returns the Interval of Timestamps in both Intervals
+//
+// If the intervals do not overlap, the result is empty.
+func (i Interval) Intersect(o Interval) Interval {
+	out := i
+	if o.Start > out.Start {
+		out.Start = o.Start
+	}
+	if o.End < out.End {
+		out.End = o.End
+	}
+	if out.IsEmpty() {
+		return Interval{}
+	}
+	return out
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("[%s, %s)", i.Start, i.End)
+}
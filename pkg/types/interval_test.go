@@ -0,0 +1,115 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewInterval(t *testing.T) {
+	i, err := NewInterval(10, 20)
+	require.NoError(t, err)
+	require.Equal(t, Interval{10, 20}, i)
+
+	i, err = NewInterval(10, 10)
+	require.NoError(t, err)
+	require.True(t, i.IsEmpty())
+
+	_, err = NewInterval(20, 10)
+	require.Error(t, err)
+
+	i, err = IntervalFor(10, 5)
+	require.NoError(t, err)
+	require.Equal(t, Interval{10, 15}, i)
+	require.Equal(t, Duration(5), i.Duration())
+
+	_, err = IntervalFor(10, -5)
+	require.Error(t, err)
+}
+
+func TestInterval_Contains(t *testing.T) {
+	i := Interval{10, 20}
+	tests := []struct {
+		t    Timestamp
+		want bool
+	}{
+		{9, false},
+		{10, true},
+		{15, true},
+		{19, true},
+		{20, false},
+		{21, false},
+	}
+	for _, tt := range tests {
+		if got := i.Contains(tt.t); got != tt.want {
+			t.Errorf("Interval.Contains(%d) = %v, want %v", tt.t, got, tt.want)
+		}
+	}
+	require.False(t, Interval{10, 10}.Contains(10))
+}
+
+func TestInterval_Overlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Interval
+		overlaps bool
+		want     Interval
+	}{
+		{"disjoint", Interval{0, 10}, Interval{20, 30}, false, Interval{}},
+		{"adjacent", Interval{0, 10}, Interval{10, 20}, false, Interval{}},
+		{"partial", Interval{0, 10}, Interval{5, 20}, true, Interval{5, 10}},
+		{"nested", Interval{0, 30}, Interval{10, 20}, true, Interval{10, 20}},
+		{"identical", Interval{0, 10}, Interval{0, 10}, true, Interval{0, 10}},
+		{"single microsecond", Interval{0, 11}, Interval{10, 20}, true, Interval{10, 11}},
+		{"empty within", Interval{0, 10}, Interval{5, 5}, false, Interval{}},
+		{"inverted", Interval{0, 10}, Interval{8, 2}, false, Interval{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// overlap and intersection are symmetric
+			for _, pair := range [][2]Interval{{tt.a, tt.b}, {tt.b, tt.a}} {
+				require.Equal(t, tt.overlaps, pair[0].Overlaps(pair[1]))
+				require.Equal(t, tt.want, pair[0].Intersect(pair[1]))
+			}
+		})
+	}
+}
+
+func TestInterval_Covers(t *testing.T) {
+	i := Interval{10, 20}
+	require.True(t, i.Covers(i))
+	require.True(t, i.Covers(Interval{12, 18}))
+	require.True(t, i.Covers(Interval{10, 11}))
+	require.True(t, i.Covers(Interval{19, 20}))
+	require.True(t, i.Covers(Interval{50, 50}))
+	require.False(t, i.Covers(Interval{9, 20}))
+	require.False(t, i.Covers(Interval{10, 21}))
+	require.False(t, Interval{10, 10}.Covers(Interval{10, 11}))
+}
+
+func TestInterval_Duration(t *testing.T) {
+	require.Equal(t, Duration(10), Interval{10, 20}.Duration())
+	require.Equal(t, Duration(0), Interval{10, 10}.Duration())
+	require.Equal(t, Duration(0), Interval{20, 10}.Duration())
+}
+
+func TestInterval_Msgp(t *testing.T) {
+	i := Interval{Start: 1234, End: 5678}
+	bts, err := i.MarshalMsg(nil)
+	require.NoError(t, err)
+	var got Interval
+	left, err := got.UnmarshalMsg(bts)
+	require.NoError(t, err)
+	require.Empty(t, left)
+	require.Equal(t, i, got)
+}
@@ -15,8 +15,10 @@ import (
 	gomath "math"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
 	"github.com/ndau/ndaumath/pkg/signed"
 	"github.com/pkg/errors"
 )
@@ -41,30 +43,48 @@ func (n Ndau) Sub(other Ndau) (Ndau, error) {
 }
 
 // Abs returns the absolute value without converting to float
-// NOTE THAT THIS FAILS IN THE CASE WHERE n == MinInt64 (this
-// value acts as much like -0 as it does MinInt). In particular,
-// the value consists of only the negative (high) bit and the
-// rest are zeros.
 //
-// As quantities on the blockchain can't be negative, we are going
-// to ignore this case in favor of simplicity.
-//
-// In particular, this function a) can be inlined, and b) has no
-// conditionals.
+// The absolute value of MinInt64 doesn't fit in an Ndau, so Abs saturates,
+// returning MaxInt64. As quantities on the blockchain can't be negative,
+// this should never matter; use signed.Abs where it must be detected.
 func (n Ndau) Abs() Ndau {
-	y := n >> 63       // sign extended, so this is either -1 (0xFFF...) or 0
-	return (n ^ y) - y // twos complement if it was negative
+	return Ndau(signed.AbsSaturating(int64(n)))
 }
 
 // Compare is the sorting operator; it returns -1 if n < rhs, 1 if n > rhs,
 // and 0 if they are equal.
 func (n Ndau) Compare(rhs Ndau) int {
-	if n < rhs {
-		return -1
-	} else if n > rhs {
-		return 1
+	return signed.Compare(int64(n), int64(rhs))
+}
+
+// DefaultRounding is the rounding mode of Round: to nearest, with ties going
+// to even, as everywhere the spec rounds quantities of ndau
+const DefaultRounding = signed.HalfEven
+
+// RoundTo rounds n to a whole multiple of unit, such as constants.Millindau,
+// according to mode.
+//
+// Floor and Ceil round towards negative and positive infinity, and Truncate
+// towards zero. It is an error if unit is not positive, or if the result
+// overflows.
+func (n Ndau) RoundTo(unit Ndau, mode signed.RoundingMode) (Ndau, error) {
+	if unit <= 0 {
+		return 0, fmt.Errorf("rounding unit must be positive; got %d napu", unit)
 	}
-	return 0
+	q, err := signed.MulDivRound(int64(n), 1, int64(unit), mode)
+	if err != nil {
+		return 0, err
+	}
+	r, err := signed.Mul(q, int64(unit))
+	return Ndau(r), err
+}
+
+// Round rounds n to a whole multiple of unit with DefaultRounding
+//
+// This agrees with Format: a quantity rounded to constants.Millindau
+// formats with 3 places exactly as the unrounded quantity does.
+func (n Ndau) Round(unit Ndau) (Ndau, error) {
+	return n.RoundTo(unit, DefaultRounding)
 }
 
 // String returns the value of n formatted in a standard format, as if it is a
@@ -72,10 +92,12 @@ func (n Ndau) Compare(rhs Ndau) int {
 // are suppressed.
 func (n Ndau) String() string {
 	var sign int64 = 1
+	// uint64 handles MinInt64 correctly, unlike Abs
+	na := uint64(n)
 	if n < 0 {
 		sign = -1
+		na = -na
 	}
-	na := n.Abs()
 	ndau := na / constants.NapuPerNdau
 	napu := na % constants.NapuPerNdau
 	if napu == 0 {
@@ -94,6 +116,9 @@ var (
 	ndaure     *regexp.Regexp
 )
 
+// errNdauOverflow is returned when a parsed quantity of ndau overflows
+var errNdauOverflow = ndauerr.Specialize(ndauerr.ErrOverflow, "ndau quantity overflows")
+
 func init() {
 	// fracdigits: how many digits go behind the decimal?
 	// computed here so that if constants.NapuPerNdau ever changes,
@@ -108,46 +133,123 @@ func init() {
 // a decimal number into a quantity of Ndau, without ever going through an
 // intermediate floating-point step in which it may lose precision or behave
 // nondeterministically.
+//
+// In addition to the form String produces, it accepts:
+//   - '_' between digits, as a separator: "1_000"
+//   - a "napu" suffix, for a quantity expressed in napu: "150napu"
+//   - an exponent: "1.5e3", "25e-8"
+//
+// The value must be an exact number of napu; nothing is ever rounded. As
+// ever, an empty or all-whitespace string is 0.
+//
+// This is equivalent to ParseNdauWith(s, false).
 func ParseNdau(s string) (Ndau, error) {
-	match := ndaure.FindStringSubmatch(s)
-	result := make(map[string]string)
-	for i, name := range ndaure.SubexpNames() {
-		if i != 0 && name != "" && i < len(match) {
-			result[name] = match[i]
+	return ParseNdauWith(s, false)
+}
+
+// ParseNdauWith parses a quantity of ndau, as ParseNdau.
+//
+// If strict is set, it accepts only the plain decimal form produced by
+// String, with at most 8 decimal places: no separators, units, or exponents.
+func ParseNdauWith(s string, strict bool) (Ndau, error) {
+	if strings.TrimSpace(s) == "" {
+		return 0, nil
+	}
+	if strict {
+		if !ndaure.MatchString(s) {
+			return 0, errors.New("failed to parse ndau")
 		}
+		return parseNdau(strings.TrimSpace(s), fracdigits)
 	}
 
-	wholes, ok := result["whole"]
-	if !ok {
-		return 0, errors.New("failed to parse ndau")
+	s = strings.TrimSpace(s)
+	places := fracdigits
+	if ls := len(s) - len("napu"); ls >= 0 && strings.EqualFold(s[ls:], "napu") {
+		s = strings.TrimSpace(s[:ls])
+		places = 0
 	}
-	if wholes == "" {
-		wholes = "0"
+
+	mantissa := s
+	exponent := 0
+	if idx := strings.IndexAny(s, "eE"); idx >= 0 {
+		mantissa = s[:idx]
+		var err error
+		exponent, err = strconv.Atoi(s[idx+1:])
+		if err != nil {
+			return 0, errors.Wrap(err, "parsing exponent")
+		}
 	}
-	whole, err := strconv.ParseUint(wholes, 10, 64)
-	if err != nil {
-		return 0, errors.Wrap(err, "parsing ndau")
+
+	for i := 0; i < len(mantissa); i++ {
+		if mantissa[i] == '_' && (i == 0 || i == len(mantissa)-1 ||
+			!isDigit(mantissa[i-1]) || !isDigit(mantissa[i+1])) {
+			return 0, errors.New("'_' must separate digits")
+		}
+	}
+	mantissa = strings.Replace(mantissa, "_", "", -1)
+
+	if exponent > maxExponent || exponent < -maxExponent {
+		return 0, errors.New("exponent out of range")
 	}
+	return parseNdau(mantissa, places+exponent)
+}
+
+// maxExponent is the largest exponent ParseNdau accepts; it comfortably
+// exceeds any which could produce a valid quantity from a sane mantissa
+const maxExponent = 1000
 
-	out := Ndau(whole) * constants.NapuPerNdau
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
 
-	fracs, ok := result["frac"]
-	if ok {
-		if len(fracs) > fracdigits {
-			fracs = fracs[:fracdigits]
-		} else if len(fracs) < fracdigits {
-			iters := fracdigits - len(fracs)
-			for i := 0; i < iters; i++ {
-				fracs += "0"
-			}
+// parseNdau parses an unsigned decimal number, with optional fractional part,
+// and returns it times 10^shift.
+//
+// It is an error if the result is not an integer or does not fit in an Ndau.
+func parseNdau(s string, shift int) (Ndau, error) {
+	digits := s
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		digits = s[:idx] + s[idx+1:]
+		shift -= len(s) - idx - 1
+	}
+	if digits == "" {
+		return 0, errors.New("no digits")
+	}
+	for i := 0; i < len(digits); i++ {
+		if !isDigit(digits[i]) {
+			return 0, fmt.Errorf("unexpected character %q", digits[i])
 		}
+	}
 
-		frac, err := strconv.ParseUint(fracs, 10, 64)
-		if err != nil {
-			return 0, errors.Wrap(err, "parsing frac component")
+	if shift < 0 {
+		// the digits shifted out must all be zero
+		cut := len(digits) + shift
+		if cut < 0 {
+			cut = 0
 		}
-		out += Ndau(frac)
+		if strings.Trim(digits[cut:], "0") != "" {
+			return 0, errors.New("ndau quantity is not an exact number of napu")
+		}
+		digits = digits[:cut]
+		shift = 0
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		return 0, nil
 	}
 
-	return out, nil
+	v, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return 0, errNdauOverflow
+	}
+	for ; shift > 0; shift-- {
+		if v > gomath.MaxInt64/10 {
+			return 0, errNdauOverflow
+		}
+		v *= 10
+	}
+	if v > gomath.MaxInt64 {
+		return 0, errNdauOverflow
+	}
+	return Ndau(v), nil
 }
@@ -0,0 +1,144 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// BigNdau is a quantity of ndau of arbitrary size.
+//
+// It is intended for offline audits, such as summing the balances of every
+// account on the chain, where intermediate values may exceed the range of
+// Ndau. Nothing on the chain should use it: convert the result back with
+// Ndau, which errors if it doesn't fit.
+//
+// A BigNdau is immutable: its methods return new values, so it may be
+// copied and shared freely. The zero value is 0.
+type BigNdau struct {
+	v *big.Int
+}
+
+// NewBigNdau creates a BigNdau from an Ndau
+func NewBigNdau(n Ndau) BigNdau {
+	return BigNdau{v: big.NewInt(int64(n))}
+}
+
+// BigNdauFromInt creates a BigNdau from a quantity of napu.
+//
+// The value is copied; later modification of i does not affect it.
+func BigNdauFromInt(i *big.Int) BigNdau {
+	if i == nil {
+		return BigNdau{}
+	}
+	return BigNdau{v: new(big.Int).Set(i)}
+}
+
+// int returns the value for reading; it must not be modified
+func (b BigNdau) int() *big.Int {
+	if b.v == nil {
+		return new(big.Int)
+	}
+	return b.v
+}
+
+// Int returns a copy of the value, in napu
+func (b BigNdau) Int() *big.Int {
+	return new(big.Int).Set(b.int())
+}
+
+// Ndau converts the value back to an Ndau.
+//
+// It returns ndauerr.ErrOverflow or ndauerr.ErrUnderflow if the value is out
+// of range.
+func (b BigNdau) Ndau() (Ndau, error) {
+	v := b.int()
+	if !v.IsInt64() {
+		if v.Sign() < 0 {
+			return 0, ndauerr.ErrUnderflow
+		}
+		return 0, ndauerr.ErrOverflow
+	}
+	return Ndau(v.Int64()), nil
+}
+
+// Add returns b + other
+func (b BigNdau) Add(other BigNdau) BigNdau {
+	return BigNdau{v: new(big.Int).Add(b.int(), other.int())}
+}
+
+// AddNdau returns b + n
+func (b BigNdau) AddNdau(n Ndau) BigNdau {
+	return BigNdau{v: new(big.Int).Add(b.int(), big.NewInt(int64(n)))}
+}
+
+// Sub returns b - other
+func (b BigNdau) Sub(other BigNdau) BigNdau {
+	return BigNdau{v: new(big.Int).Sub(b.int(), other.int())}
+}
+
+// SubNdau returns b - n
+func (b BigNdau) SubNdau(n Ndau) BigNdau {
+	return BigNdau{v: new(big.Int).Sub(b.int(), big.NewInt(int64(n)))}
+}
+
+// MulDiv returns b * n / d, truncated toward zero as signed.MulDiv is.
+//
+// It returns ndauerr.ErrDivideByZero if d is 0.
+func (b BigNdau) MulDiv(n, d int64) (BigNdau, error) {
+	if d == 0 {
+		return BigNdau{}, ndauerr.ErrDivideByZero
+	}
+	v := new(big.Int).Mul(b.int(), big.NewInt(n))
+	v.Quo(v, big.NewInt(d))
+	return BigNdau{v: v}, nil
+}
+
+// Sign returns -1, 0, or 1 as b is negative, zero, or positive
+func (b BigNdau) Sign() int {
+	return b.int().Sign()
+}
+
+// Compare is the sorting operator; it returns -1 if b < rhs, 1 if b > rhs,
+// and 0 if they are equal.
+func (b BigNdau) Compare(rhs BigNdau) int {
+	return b.int().Cmp(rhs.int())
+}
+
+// String returns the value of b formatted as Ndau.String formats: as a
+// decimal value of ndau, with trailing zeros suppressed.
+func (b BigNdau) String() string {
+	v := b.int()
+	ndau, napu := new(big.Int).QuoRem(
+		new(big.Int).Abs(v),
+		big.NewInt(constants.NapuPerNdau),
+		new(big.Int),
+	)
+	sign := ""
+	if v.Sign() < 0 {
+		sign = "-"
+	}
+	if napu.Sign() == 0 {
+		return sign + ndau.String()
+	}
+	s := fmt.Sprintf("%s%s.%08d", sign, ndau, napu.Int64())
+	return strings.TrimRight(s, "0")
+}
+
+// SumBigNdau returns the exact sum of values, however large
+func SumBigNdau(values []Ndau) BigNdau {
+	return BigNdau{v: sumNdau(values)}
+}
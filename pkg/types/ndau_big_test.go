@@ -0,0 +1,108 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigNdauRoundtrip(t *testing.T) {
+	for _, tt := range []struct {
+		n    Ndau
+		want string
+	}{
+		{0, "0"},
+		{1, "0.00000001"},
+		{-1, "-0.00000001"},
+		{constants.NapuPerNdau, "1"},
+		{150000000, "1.5"},
+		{-12345678901, "-123.45678901"},
+		{maxNdau, "92233720368.54775807"},
+		{minNdau, "-92233720368.54775808"},
+	} {
+		got, err := NewBigNdau(tt.n).Ndau()
+		require.NoError(t, err)
+		require.Equal(t, tt.n, got)
+		require.Equal(t, tt.want, NewBigNdau(tt.n).String())
+	}
+
+	var zero BigNdau
+	require.Equal(t, 0, zero.Sign())
+	require.Equal(t, "0", zero.String())
+	got, err := zero.Ndau()
+	require.NoError(t, err)
+	require.Equal(t, Ndau(0), got)
+	require.Equal(t, NewBigNdau(7), zero.AddNdau(7))
+}
+
+func TestBigNdauBeyondNdau(t *testing.T) {
+	// the sum of many maximal balances
+	balances := []Ndau{maxNdau, maxNdau, maxNdau, 5}
+	sum := SumBigNdau(balances)
+	want := new(big.Int).Mul(big.NewInt(int64(maxNdau)), big.NewInt(3))
+	want.Add(want, big.NewInt(5))
+	require.Equal(t, want, sum.Int())
+	require.Equal(t, "276701161105.64327426", sum.String())
+	_, err := sum.Ndau()
+	require.Equal(t, ndauerr.ErrOverflow, err)
+
+	// the mean fits, though the sum doesn't
+	mean, err := sum.MulDiv(1, int64(len(balances)))
+	require.NoError(t, err)
+	got, err := mean.Ndau()
+	require.NoError(t, err)
+	require.Equal(t, Ndau(new(big.Int).Quo(want, big.NewInt(4)).Int64()), got)
+
+	// and subtracting the balances again returns to 0
+	for _, b := range balances {
+		sum = sum.SubNdau(b)
+	}
+	require.Equal(t, 0, sum.Sign())
+
+	low := NewBigNdau(minNdau).SubNdau(1)
+	require.Equal(t, -1, low.Sign())
+	_, err = low.Ndau()
+	require.Equal(t, ndauerr.ErrUnderflow, err)
+}
+
+func TestBigNdauArithmetic(t *testing.T) {
+	a := NewBigNdau(maxNdau)
+	b := NewBigNdau(3)
+
+	sum := a.Add(b)
+	require.Equal(t, 1, sum.Compare(a))
+	require.Equal(t, -1, a.Compare(sum))
+	require.Equal(t, 0, sum.Sub(b).Compare(a))
+	require.Equal(t, a, sum.Sub(b))
+
+	// operations don't modify their operands
+	require.Equal(t, big.NewInt(int64(maxNdau)), a.Int())
+	i := a.Int()
+	i.SetInt64(0)
+	require.Equal(t, big.NewInt(int64(maxNdau)), a.Int())
+	require.Equal(t, big.NewInt(3), BigNdauFromInt(big.NewInt(3)).Int())
+	require.Equal(t, 0, BigNdauFromInt(nil).Sign())
+
+	// MulDiv truncates toward zero, and its intermediate product may be large
+	got, err := a.MulDiv(int64(maxNdau), int64(maxNdau))
+	require.NoError(t, err)
+	require.Equal(t, a, got)
+	got, err = NewBigNdau(-7).MulDiv(1, 2)
+	require.NoError(t, err)
+	require.Equal(t, NewBigNdau(-3), got)
+	_, err = a.MulDiv(1, 0)
+	require.Equal(t, ndauerr.ErrDivideByZero, err)
+}
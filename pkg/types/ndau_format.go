@@ -0,0 +1,266 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/pkg/errors"
+)
+
+// Unit selects the unit in which Format expresses a quantity
+type Unit int
+
+// These are the units Format understands
+const (
+	// UnitNone formats as ndau, with no suffix
+	UnitNone Unit = iota
+	// UnitNdau formats as ndau, with a " ndau" suffix
+	UnitNdau
+	// UnitNapu formats as an integer number of napu, with a " napu" suffix
+	UnitNapu
+)
+
+// VariablePlaces formats with as many decimal places as are required to
+// represent the value exactly, as String does.
+const VariablePlaces = -1
+
+// FormatOptions control how Format renders a quantity of Ndau
+type FormatOptions struct {
+	// Separator is inserted between each group of three digits of the whole
+	// part. If empty, digits are not grouped.
+	Separator string
+	// Decimal separates the whole and fractional parts. If empty, "." is used.
+	Decimal string
+	// Places is the fixed number of decimal places to display, or
+	// VariablePlaces. Values with more precision than this are rounded to
+	// nearest, with ties going to even. It is ignored for UnitNapu.
+	Places int
+	// Unit is the unit in which the value is expressed
+	Unit Unit
+}
+
+// Format returns the value of n formatted according to opts.
+//
+// Format never converts to float, so any two callers using the same options
+// will always see the same result.
+func (n Ndau) Format(opts FormatOptions) string {
+	// uint64 handles MinInt64 correctly, unlike Abs
+	u := uint64(n)
+	sign := ""
+	if n < 0 {
+		u = uint64(-n)
+		sign = "-"
+	}
+
+	if opts.Unit == UnitNapu {
+		return sign + group(strconv.FormatUint(u, 10), opts.Separator) + " napu"
+	}
+
+	decimal := opts.Decimal
+	if decimal == "" {
+		decimal = "."
+	}
+
+	var whole, frac string
+	if opts.Places < 0 || opts.Places >= fracdigits {
+		whole = strconv.FormatUint(u/constants.NapuPerNdau, 10)
+		frac = strconv.FormatUint(u%constants.NapuPerNdau, 10)
+		frac = strings.Repeat("0", fracdigits-len(frac)) + frac
+		if opts.Places < 0 {
+			frac = strings.TrimRight(frac, "0")
+		} else {
+			frac += strings.Repeat("0", opts.Places-fracdigits)
+		}
+	} else {
+		scale := pow10(fracdigits - opts.Places)
+		q, r := u/scale, u%scale
+		if r*2 > scale || (r*2 == scale && q%2 == 1) {
+			q++
+		}
+		unit := pow10(opts.Places)
+		whole = strconv.FormatUint(q/unit, 10)
+		if opts.Places > 0 {
+			frac = strconv.FormatUint(q%unit, 10)
+			frac = strings.Repeat("0", opts.Places-len(frac)) + frac
+		}
+	}
+	if whole == "0" && strings.Trim(frac, "0") == "" {
+		// don't display negative zero after rounding
+		sign = ""
+	}
+
+	s := sign + group(whole, opts.Separator)
+	if frac != "" {
+		s += decimal + frac
+	}
+	if opts.Unit == UnitNdau {
+		s += " ndau"
+	}
+	return s
+}
+
+func pow10(n int) uint64 {
+	out := uint64(1)
+	for i := 0; i < n; i++ {
+		out *= 10
+	}
+	return out
+}
+
+// group inserts sep between each group of three digits, counting from the right
+func group(digits, sep string) string {
+	if sep == "" || len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	lead := len(digits) % 3
+	if lead > 0 {
+		b.WriteString(digits[:lead])
+	}
+	for i := lead; i < len(digits); i += 3 {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
+
+// ParseNdauLenient parses a quantity of ndau as a human might write it.
+//
+// In addition to everything ParseNdau accepts, it permits:
+//   - a leading sign
+//   - ',', ' ', or '_' between groups of three digits of the whole part, as
+//     thousands separators; a number may use only one of them
+//   - a trailing "ndau" unit, in any case
+//
+// Quantities with a trailing "napu" must be integers.
+//
+// The decimal mark is always '.', so a decimal comma, as in "1,5", is an
+// error rather than a thousands separator. It inverts Format for all options
+// which use the default Decimal; ParseNdauFormatted inverts the others.
+func ParseNdauLenient(s string) (Ndau, error) {
+	return parseGrouped(s, ".", []string{",", " ", "_"})
+}
+
+// ParseNdauFormatted parses a quantity of ndau as Format writes it with
+// opts, so that it inverts Format for all options.
+//
+// It accepts what ParseNdauLenient does, except that the decimal mark is
+// opts.Decimal, and the only thousands separator is opts.Separator, if any.
+// Any number of places, and either unit, are accepted, except that under
+// UnitNapu, which Format writes without a decimal mark, there is none.
+func ParseNdauFormatted(s string, opts FormatOptions) (Ndau, error) {
+	decimal := opts.Decimal
+	if decimal == "" {
+		decimal = "."
+	}
+	if opts.Unit == UnitNapu {
+		decimal = ""
+	}
+	if opts.Separator == decimal {
+		return 0, errors.New("thousands separator and decimal mark must differ")
+	}
+	var separators []string
+	if opts.Separator != "" {
+		separators = []string{opts.Separator}
+	}
+	return parseGrouped(s, decimal, separators)
+}
+
+// parseGrouped parses a quantity of ndau whose whole part may be grouped by
+// one of separators, and which uses the given decimal mark, if any
+func parseGrouped(s, decimal string, separators []string) (Ndau, error) {
+	s = strings.TrimSpace(s)
+	napu := false
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "ndau"):
+		s = strings.TrimSpace(s[:len(s)-len("ndau")])
+	case strings.HasSuffix(lower, "napu"):
+		s = strings.TrimSpace(s[:len(s)-len("napu")])
+		napu = true
+	}
+
+	negative := false
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	whole, frac := s, ""
+	idx := -1
+	if decimal != "" {
+		idx = strings.Index(s, decimal)
+	}
+	if idx >= 0 {
+		whole, frac = s[:idx], s[idx+len(decimal):]
+	}
+	whole, err := ungroup(whole, separators)
+	if err != nil {
+		return 0, err
+	}
+	if decimal != "." && strings.Contains(whole, ".") {
+		return 0, errors.New("unexpected '.': the decimal mark is " + strconv.Quote(decimal))
+	}
+	s = whole
+	if idx >= 0 {
+		s += "." + frac
+	}
+	if len(s) == 0 {
+		return 0, errors.New("no digits")
+	}
+
+	if napu {
+		s += "napu"
+	}
+	out, err := ParseNdau(s)
+	if err != nil {
+		return 0, err
+	}
+
+	if negative {
+		out = -out
+	}
+	return out, nil
+}
+
+// ungroup removes the thousands separators from the whole part of a number,
+// checking that they group its digits in threes
+//
+// Only one of separators may appear. If none does, whole is returned as it is.
+func ungroup(whole string, separators []string) (string, error) {
+	sep := ""
+	for _, candidate := range separators {
+		if strings.Contains(whole, candidate) {
+			if sep != "" {
+				return "", errors.New("mixed thousands separators")
+			}
+			sep = candidate
+		}
+	}
+	if sep == "" {
+		return whole, nil
+	}
+	groups := strings.Split(whole, sep)
+	for idx, g := range groups {
+		if strings.Trim(g, "0123456789") != "" || g == "" {
+			return "", errors.New("misplaced thousands separator")
+		}
+		if (idx == 0 && len(g) > 3) || (idx > 0 && len(g) != 3) {
+			return "", errors.New("thousands separators must group digits in threes")
+		}
+	}
+	return strings.Join(groups, ""), nil
+}
@@ -0,0 +1,135 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNdau_Format(t *testing.T) {
+	grouped := FormatOptions{Separator: ",", Places: VariablePlaces}
+	tests := []struct {
+		name string
+		n    Ndau
+		opts FormatOptions
+		want string
+	}{
+		{"zero value opts", 123456789, FormatOptions{}, "1"},
+		{"variable matches String", 123456789, FormatOptions{Places: VariablePlaces}, Ndau(123456789).String()},
+		{"variable whole", 500000000, FormatOptions{Places: VariablePlaces}, "5"},
+		{"grouped", 123456789012345678, grouped, "1,234,567,890.12345678"},
+		{"grouped exactly 3", 12300000000, grouped, "123"},
+		{"grouped 4", 123400000000, grouped, "1,234"},
+		{"grouped negative", -123400000000, grouped, "-1,234"},
+		{"fixed 2", 123456789, FormatOptions{Places: 2}, "1.23"},
+		{"fixed 2 rounds up", 123556789, FormatOptions{Places: 2}, "1.24"},
+		{"fixed 2 tie to even down", 112500000, FormatOptions{Places: 2}, "1.12"},
+		{"fixed 2 tie to even up", 113500000, FormatOptions{Places: 2}, "1.14"},
+		{"fixed 0 carries", 199999999, FormatOptions{Places: 0}, "2"},
+		{"fixed 2 carries into grouping", 99999999999, FormatOptions{Separator: ",", Places: 2}, "1,000.00"},
+		{"fixed 8", 100000000, FormatOptions{Places: 8}, "1.00000000"},
+		{"fixed 10 pads", 100000001, FormatOptions{Places: 10}, "1.0000000100"},
+		{"negative rounds to zero", -1, FormatOptions{Places: 2}, "0.00"},
+		{"european", 123456789012, FormatOptions{Separator: ".", Decimal: ",", Places: 2}, "1.234,57"},
+		{"ndau suffix", 150000000, FormatOptions{Places: VariablePlaces, Unit: UnitNdau}, "1.5 ndau"},
+		{"napu", 123456789, FormatOptions{Separator: ",", Places: 2, Unit: UnitNapu}, "123,456,789 napu"},
+		{"max", math.MaxInt64, grouped, "92,233,720,368.54775807"},
+		{"min", math.MinInt64, grouped, "-92,233,720,368.54775808"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.n.Format(tt.opts))
+		})
+	}
+}
+
+func TestRoundAgreesWithFormat(t *testing.T) {
+	// a fee rounded to thousandths displays the same whether the wallet
+	// formats the exact fee or the chain's rounded one
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	values := []Ndau{0, 1, -1, 112500000, 113500000, -112500000, 99999999999, math.MaxInt64 / 2}
+	for i := 0; i < 1000; i++ {
+		values = append(values, Ndau(r.Int63n(1000*constants.NapuPerNdau)-500*constants.NapuPerNdau))
+	}
+	for _, places := range []int{0, 2, 3, 6} {
+		unit := Ndau(1)
+		for i := places; i < fracdigits; i++ {
+			unit *= 10
+		}
+		opts := FormatOptions{Places: places}
+		for _, n := range values {
+			rounded, err := n.Round(unit)
+			require.NoError(t, err)
+			require.Equal(t, n.Format(opts), rounded.Format(opts), "%d to %d places", n, places)
+		}
+	}
+}
+
+func TestParseNdauLenient(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Ndau
+		wantErr bool
+	}{
+		{"plain", "1.5", 150000000, false},
+		{"grouped with unit", "1,234.5 ndau", 123450000000, false},
+		{"unit without space", "2ndau", 200000000, false},
+		{"unit case", "2 NDAU", 200000000, false},
+		{"underscores", "1_000", 100000000000, false},
+		{"spaces", " 1 000 000 ", 100000000000000, false},
+		{"negative", "-1.5", -150000000, false},
+		{"positive", "+1.5", 150000000, false},
+		{"napu", "1,234 napu", 1234, false},
+		{"fractional napu", "1.5 napu", 0, true},
+		{"empty", "", 0, true},
+		{"only unit", "ndau", 0, true},
+		{"leading separator", ",123", 0, true},
+		{"trailing separator", "123,", 0, true},
+		{"too many places", "1.123456789", 0, true},
+		{"garbage", "one ndau", 0, true},
+		{"max", "92,233,720,368.54775807", math.MaxInt64, false},
+		{"overflow", "92,233,720,368.54775808", 0, true},
+		{"overflow whole", "92,233,720,369", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNdauLenient(tt.s)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFormatParseRoundtrip(t *testing.T) {
+	for _, n := range []Ndau{0, 1, -1, 123456789, -987654321012, math.MaxInt64} {
+		for _, opts := range []FormatOptions{
+			{Places: VariablePlaces},
+			{Separator: ",", Places: VariablePlaces, Unit: UnitNdau},
+			{Separator: " ", Places: 8},
+			{Separator: "_", Unit: UnitNapu},
+		} {
+			got, err := ParseNdauLenient(n.Format(opts))
+			require.NoError(t, err, n.Format(opts))
+			require.Equal(t, n, got, n.Format(opts))
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"strconv"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+)
+
+// redactedFigures is the number of significant figures Redacted displays
+const redactedFigures = 2
+
+// Redacted returns an approximation of n suitable for logging where the exact
+// quantity must not be disclosed, such as "≈12 ndau" or "≈3400 ndau".
+//
+// The quantity is rounded to whole ndau, keeping at most two significant
+// figures, with halves rounding away from zero. Nonzero quantities of less
+// than half an ndau are shown as "<1 ndau" or ">-1 ndau", so that they are
+// not mistaken for zero.
+func (n Ndau) Redacted() string {
+	// uint64 handles MinInt64 correctly, unlike Abs
+	u := uint64(n)
+	sign := ""
+	if n < 0 {
+		u = -u
+		sign = "-"
+	}
+	if u == 0 {
+		return "0 ndau"
+	}
+
+	digits := len(strconv.FormatUint(u/constants.NapuPerNdau, 10))
+	unit := uint64(constants.NapuPerNdau)
+	if digits > redactedFigures {
+		unit *= pow10(digits - redactedFigures)
+	}
+	rounded := (u + unit/2) / unit * unit / constants.NapuPerNdau
+	if rounded == 0 {
+		if n < 0 {
+			return ">-1 ndau"
+		}
+		return "<1 ndau"
+	}
+	return "≈" + sign + strconv.FormatUint(rounded, 10) + " ndau"
+}
+
+// The constant-time helpers below compare quantities without branching on
+// their values, so that services handling sensitive balances don't leak
+// their magnitudes through timing. As with crypto/subtle, predicates return
+// 1 for true and 0 for false.
+
+// ConstantTimeEq returns 1 if n == rhs and 0 otherwise, in constant time.
+func (n Ndau) ConstantTimeEq(rhs Ndau) int {
+	d := uint64(n ^ rhs)
+	// the high bit of d|-d is set iff d is nonzero
+	return int(((d | -d) >> 63) ^ 1)
+}
+
+// ConstantTimeLess returns 1 if n < rhs and 0 otherwise, in constant time.
+func (n Ndau) ConstantTimeLess(rhs Ndau) int {
+	x, y := int64(n), int64(rhs)
+	// x - y may overflow; where the signs of x and y differ, the sign of x
+	// decides instead (Hacker's Delight, section 2-12)
+	d := x - y
+	return int(uint64(d^((x^y)&(d^x))) >> 63)
+}
+
+// ConstantTimeCompare is like Compare, but runs in constant time: it returns
+// -1 if n < rhs, 1 if n > rhs, and 0 if they are equal.
+func (n Ndau) ConstantTimeCompare(rhs Ndau) int {
+	return rhs.ConstantTimeLess(n) - n.ConstantTimeLess(rhs)
+}
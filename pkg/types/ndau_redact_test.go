@@ -0,0 +1,76 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+)
+
+func TestNdauRedacted(t *testing.T) {
+	const ndau = constants.NapuPerNdau
+	tests := []struct {
+		n    Ndau
+		want string
+	}{
+		{0, "0 ndau"},
+		{1, "<1 ndau"},
+		{ndau/2 - 1, "<1 ndau"},
+		{-1, ">-1 ndau"},
+		{ndau / 2, "≈1 ndau"},
+		{ndau, "≈1 ndau"},
+		{12*ndau + 34567890, "≈12 ndau"},
+		{12*ndau + 50000000, "≈13 ndau"},
+		{-12 * ndau, "≈-12 ndau"},
+		{99*ndau + 60000000, "≈100 ndau"},
+		{3449 * ndau, "≈3400 ndau"},
+		{3450 * ndau, "≈3500 ndau"},
+		{-3450 * ndau, "≈-3500 ndau"},
+		{123456789 * ndau, "≈120000000 ndau"},
+		{maxNdau, "≈92000000000 ndau"},
+		{minNdau, "≈-92000000000 ndau"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(int64(tt.n)), func(t *testing.T) {
+			if got := tt.n.Redacted(); got != tt.want {
+				t.Errorf("Ndau(%d).Redacted() = %q, want %q", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNdauConstantTime(t *testing.T) {
+	values := []Ndau{
+		minNdau, minNdau + 1, -constants.NapuPerNdau, -2, -1,
+		0, 1, 2, constants.NapuPerNdau, maxNdau - 1, maxNdau,
+	}
+	b2i := func(b bool) int {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	for _, a := range values {
+		for _, b := range values {
+			if got, want := a.ConstantTimeEq(b), b2i(a == b); got != want {
+				t.Errorf("%d.ConstantTimeEq(%d) = %d, want %d", a, b, got, want)
+			}
+			if got, want := a.ConstantTimeLess(b), b2i(a < b); got != want {
+				t.Errorf("%d.ConstantTimeLess(%d) = %d, want %d", a, b, got, want)
+			}
+			if got, want := a.ConstantTimeCompare(b), a.Compare(b); got != want {
+				t.Errorf("%d.ConstantTimeCompare(%d) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// These helpers summarize slices of Ndau. As with signed.SumChecked, sums
+// accumulate in a big.Int, so intermediate values may exceed the int64 range:
+// only the final result needs to fit in an Ndau. In particular, the mean
+// of any non-empty slice is always representable, even when its sum is not.
+
+// sumNdau returns the exact sum of values
+func sumNdau(values []Ndau) *big.Int {
+	sum := new(big.Int)
+	term := new(big.Int)
+	for _, v := range values {
+		sum.Add(sum, term.SetInt64(int64(v)))
+	}
+	return sum
+}
+
+// SumNdau returns the sum of all values, and errors if the result overflows
+func SumNdau(values []Ndau) (Ndau, error) {
+	sum := sumNdau(values)
+	if !sum.IsInt64() {
+		return 0, ndauerr.ErrOverflow
+	}
+	return Ndau(sum.Int64()), nil
+}
+
+// MeanNdau returns the arithmetic mean of values, truncated towards zero
+//
+// Errors if values is empty.
+func MeanNdau(values []Ndau) (Ndau, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	sum := sumNdau(values)
+	sum.Quo(sum, big.NewInt(int64(len(values))))
+	return Ndau(sum.Int64()), nil
+}
+
+// MedianNdau returns the median of values
+//
+// For an even number of values, it is the mean of the middle two, truncated
+// towards zero. values is not modified. Errors if values is empty.
+func MedianNdau(values []Ndau) (Ndau, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	sorted := append([]Ndau(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid], nil
+	}
+	return MeanNdau(sorted[mid-1 : mid+1])
+}
+
+// MaxNdau returns the greatest of values
+//
+// Errors if values is empty.
+func MaxNdau(values []Ndau) (Ndau, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	max := values[0]
+	for _, v := range values[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	return max, nil
+}
+
+// MinNdau returns the least of values
+//
+// Errors if values is empty.
+func MinNdau(values []Ndau) (Ndau, error) {
+	if len(values) == 0 {
+		return 0, ndauerr.ErrEmpty
+	}
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min, nil
+}
@@ -0,0 +1,110 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+const (
+	maxNdau = Ndau(math.MaxInt64)
+	minNdau = Ndau(math.MinInt64)
+)
+
+func TestNdauStats(t *testing.T) {
+	type result struct {
+		want Ndau
+		err  error
+	}
+	tests := []struct {
+		name   string
+		values []Ndau
+		sum    result
+		mean   result
+		median result
+		max    result
+		min    result
+	}{
+		{
+			"empty", nil,
+			result{0, nil},
+			result{0, ndauerr.ErrEmpty},
+			result{0, ndauerr.ErrEmpty},
+			result{0, ndauerr.ErrEmpty},
+			result{0, ndauerr.ErrEmpty},
+		},
+		{
+			"single", []Ndau{7},
+			result{7, nil}, result{7, nil}, result{7, nil}, result{7, nil}, result{7, nil},
+		},
+		{
+			"odd", []Ndau{5, 1, 4, 2, 3},
+			result{15, nil}, result{3, nil}, result{3, nil}, result{5, nil}, result{1, nil},
+		},
+		{
+			"even", []Ndau{4, 1, 3, 2},
+			result{10, nil}, result{2, nil}, result{2, nil}, result{4, nil}, result{1, nil},
+		},
+		{
+			"negative mean truncates towards zero", []Ndau{-4, -1},
+			result{-5, nil}, result{-2, nil}, result{-2, nil}, result{-1, nil}, result{-4, nil},
+		},
+		{
+			"sum overflows", []Ndau{maxNdau, maxNdau - 2},
+			result{0, ndauerr.ErrOverflow}, result{maxNdau - 1, nil}, result{maxNdau - 1, nil},
+			result{maxNdau, nil}, result{maxNdau - 2, nil},
+		},
+		{
+			"sum underflows", []Ndau{minNdau, minNdau, minNdau},
+			result{0, ndauerr.ErrOverflow}, result{minNdau, nil}, result{minNdau, nil},
+			result{minNdau, nil}, result{minNdau, nil},
+		},
+		{
+			"intermediate overflow recovers", []Ndau{maxNdau, maxNdau, -maxNdau},
+			result{maxNdau, nil}, result{maxNdau / 3, nil}, result{maxNdau, nil},
+			result{maxNdau, nil}, result{-maxNdau, nil},
+		},
+		{
+			"extremes", []Ndau{minNdau, maxNdau},
+			result{-1, nil}, result{0, nil}, result{0, nil}, result{maxNdau, nil}, result{minNdau, nil},
+		},
+	}
+	check := func(t *testing.T, name string, f func([]Ndau) (Ndau, error), values []Ndau, expect result) {
+		t.Helper()
+		got, err := f(values)
+		if err != expect.err {
+			t.Errorf("%s() error = %v, want %v", name, err, expect.err)
+			return
+		}
+		if got != expect.want {
+			t.Errorf("%s() = %d, want %d", name, got, expect.want)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orig := append([]Ndau(nil), tt.values...)
+			check(t, "SumNdau", SumNdau, tt.values, tt.sum)
+			check(t, "MeanNdau", MeanNdau, tt.values, tt.mean)
+			check(t, "MedianNdau", MedianNdau, tt.values, tt.median)
+			check(t, "MaxNdau", MaxNdau, tt.values, tt.max)
+			check(t, "MinNdau", MinNdau, tt.values, tt.min)
+			for i := range orig {
+				if tt.values[i] != orig[i] {
+					t.Errorf("input was modified: %v, want %v", tt.values, orig)
+					break
+				}
+			}
+		})
+	}
+}
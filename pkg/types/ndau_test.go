@@ -11,10 +11,14 @@ package types
 
 
 import (
+	"errors"
 	"math"
+	"strings"
 	"testing"
 
 	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+	"github.com/ndau/ndaumath/pkg/signed"
 )
 
 func TestNdau_Add(t *testing.T) {
@@ -45,9 +49,9 @@ func TestNdau_Add(t *testing.T) {
 				return
 			}
 			if err != nil {
-				s := err.Error()
-				if s != "overflow error" {
-					t.Errorf("Error type was wrong, got %s, wanted overflow error", s)
+				// below MinInt64 is an underflow, which is a kind of overflow
+				if !errors.Is(err, ndauerr.ErrOverflow) {
+					t.Errorf("Error type was wrong, got %s, wanted overflow error", err)
 				}
 				return
 			}
@@ -105,8 +109,8 @@ func TestNdau_Abs(t *testing.T) {
 		{"b", 100, 100},
 		{"c", -101, 101},
 		{"d", Ndau(int64(math.MaxInt64)), Ndau(int64(math.MaxInt64))},
-		// explicitly test for the abs(MinInt) case which returns MinInt again
-		{"e", Ndau(int64(math.MinInt64)), Ndau(int64(math.MinInt64))},
+		// explicitly test for the abs(MinInt) case, which saturates
+		{"e", Ndau(int64(math.MinInt64)), Ndau(int64(math.MaxInt64))},
 		{"f", -1, 1},
 		{"g", 0, 0},
 	}
@@ -163,6 +167,7 @@ func TestNdau_String(t *testing.T) {
 		{"f", -17 * constants.QuantaPerUnit, "-17"},
 		{"g", -17*constants.QuantaPerUnit - 1234, "-17.00001234"},
 		{"h", 100, "0.000001"},
+		{"i", math.MinInt64, "-92233720368.54775808"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -173,6 +178,67 @@ func TestNdau_String(t *testing.T) {
 	}
 }
 
+func TestNdau_RoundTo(t *testing.T) {
+	const milli = constants.Millindau
+	tests := []struct {
+		name    string
+		n       Ndau
+		unit    Ndau
+		mode    signed.RoundingMode
+		want    Ndau
+		wantErr bool
+	}{
+		{"exact", 3 * milli, milli, signed.HalfEven, 3 * milli, false},
+		{"below half", 3*milli + milli/2 - 1, milli, signed.HalfEven, 3 * milli, false},
+		{"above half", 3*milli + milli/2 + 1, milli, signed.HalfEven, 4 * milli, false},
+		{"tie to even up", 3*milli + milli/2, milli, signed.HalfEven, 4 * milli, false},
+		{"tie to even down", 2*milli + milli/2, milli, signed.HalfEven, 2 * milli, false},
+		{"negative tie", -(2*milli + milli/2), milli, signed.HalfEven, -2 * milli, false},
+		{"half up tie", 2*milli + milli/2, milli, signed.HalfUp, 3 * milli, false},
+		{"floor", 2*milli + 1, milli, signed.Floor, 2 * milli, false},
+		{"floor negative", -2*milli - 1, milli, signed.Floor, -3 * milli, false},
+		{"ceil", 2*milli + 1, milli, signed.Ceil, 3 * milli, false},
+		{"ceil negative", -2*milli - 1, milli, signed.Ceil, -2 * milli, false},
+		{"truncate negative", -2*milli - 1, milli, signed.Truncate, -2 * milli, false},
+		{"micro", 123456789, constants.Microndau, signed.HalfEven, 123456800, false},
+		{"ndau", 150000000, constants.NapuPerNdau, signed.HalfEven, 200000000, false},
+		{"napu", 123456789, 1, signed.HalfEven, 123456789, false},
+		{"zero unit", 1, 0, signed.HalfEven, 0, true},
+		{"negative unit", 1, -milli, signed.HalfEven, 0, true},
+		{"overflow", math.MaxInt64, constants.NapuPerNdau, signed.Ceil, 0, true},
+		{"max floors", math.MaxInt64, constants.NapuPerNdau, signed.Floor, math.MaxInt64 / constants.NapuPerNdau * constants.NapuPerNdau, false},
+		{"bad mode", 1, milli, signed.RoundingMode(99), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.n.RoundTo(tt.unit, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ndau.RoundTo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Ndau.RoundTo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNdau_Round(t *testing.T) {
+	for _, n := range []Ndau{0, 1, -1, 12345678, 12350000, 12250000, -12250000, math.MaxInt64 / 2} {
+		want, err := n.RoundTo(constants.Millindau, signed.HalfEven)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := n.Round(constants.Millindau)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Ndau(%d).Round() = %v, want %v", n, got, want)
+		}
+	}
+}
+
 func ndauize(n int) Ndau {
 	return Ndau(n * constants.NapuPerNdau)
 }
@@ -200,6 +266,40 @@ func TestParseNdau(t *testing.T) {
 		{"0.001t", "0.001", ndauize(1) / 1000, false},
 		{"too much precision", "1.000000001", ndauize(0), true},
 		{"bare leading decimal", ".1", ndauize(1) / 10, false},
+		{"empty", "", 0, false},
+		{"blank", " \t\n", 0, false},
+		{"bare decimal", ".", 0, true},
+		{"underscores", "1_000", ndauize(1000), false},
+		{"fractional underscores", "0.001_5", 150000, false},
+		{"leading underscore", "_1", 0, true},
+		{"trailing underscore", "1_", 0, true},
+		{"double underscore", "1__000", 0, true},
+		{"underscore at decimal", "1_.5", 0, true},
+		{"napu", "150napu", Ndau(150), false},
+		{"napu with space", "150 napu", Ndau(150), false},
+		{"napu case", "150NAPU", Ndau(150), false},
+		{"fractional napu", "1.5napu", 0, true},
+		{"only napu", "napu", 0, true},
+		{"exponent", "1.5e3", ndauize(1500), false},
+		{"capital exponent", "2E2", ndauize(200), false},
+		{"positive exponent", "2e+2", ndauize(200), false},
+		{"negative exponent", "25e-8", Ndau(25), false},
+		{"exact exponent", "1.000000001e1", Ndau(1000000001), false},
+		{"trailing zeros", "1.000000000", ndauize(1), false},
+		{"inexact exponent", "1e-9", 0, true},
+		{"zero with huge exponent", "0e999", 0, false},
+		{"napu exponent", "15e2napu", Ndau(1500), false},
+		{"inexact napu exponent", "15e-1napu", 0, true},
+		{"empty exponent", "1e", 0, true},
+		{"bad exponent", "1e1_0", 0, true},
+		{"huge exponent", "1e1000000", 0, true},
+		{"sign", "-1", 0, true},
+		{"max", "92233720368.54775807", Ndau(math.MaxInt64), false},
+		{"overflow", "92233720368.54775808", 0, true},
+		{"overflow whole", "92233720369", 0, true},
+		{"overflow exponent", "1e11", 0, true},
+		{"long mantissa", "100000000000000000000e-20", ndauize(1), false},
+		{"overflow digits", "100000000000000000000", 0, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -208,9 +308,153 @@ func TestParseNdau(t *testing.T) {
 				t.Errorf("ParseNdau() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if isOverflow := strings.HasPrefix(tt.name, "overflow"); isOverflow != errors.Is(err, ndauerr.ErrOverflow) {
+				t.Errorf("ParseNdau() error = %v; is overflow: %v, want %v", err, !isOverflow, isOverflow)
+			}
 			if got != tt.want {
 				t.Errorf("ParseNdau() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestParseNdauWith(t *testing.T) {
+	for _, in := range []string{"1", " 1.5 ", "1000.00000000", ".1", "", "  "} {
+		strict, err := ParseNdauWith(in, true)
+		if err != nil {
+			t.Errorf("ParseNdauWith(%q, true) error = %v", in, err)
+		}
+		lax, _ := ParseNdauWith(in, false)
+		if strict != lax {
+			t.Errorf("ParseNdauWith(%q, true) = %v, want %v", in, strict, lax)
+		}
+	}
+	for _, in := range []string{".", "1_000", "150napu", "1e3", "1.000000000", "92233720369"} {
+		if _, err := ParseNdauWith(in, true); err == nil {
+			t.Errorf("ParseNdauWith(%q, true) did not error", in)
+		}
+	}
+}
+
+// FuzzParseNdau checks that every quantity which parses, in any form, survives
+// a roundtrip through its String form in strict mode
+func FuzzParseNdau(f *testing.F) {
+	for _, s := range []string{"1", "1000.00000000", "0.5", "0.00000001", "-1.5", "1_000", "150napu", "1.5e3", "25e-8", "92233720368.54775807", "1%"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := ParseNdau(s)
+		if err != nil {
+			return
+		}
+		got, err := ParseNdauWith(n.String(), true)
+		if err != nil {
+			t.Fatalf("parsing %q, the String of %q: %s", n.String(), s, err)
+		}
+		if got != n {
+			t.Errorf("roundtrip of %q through %q: got %d, want %d", s, n.String(), got, n)
+		}
+	})
+}
+
+func TestParseNdauLenientGrouping(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    Ndau
+		wantErr bool
+	}{
+		{"grouped", "1,234,567", 123456700000000, false},
+		{"grouped with decimals", "1,234.5 ndau", 123450000000, false},
+		{"short first group", "12 345", 1234500000000, false},
+		{"decimal comma", "1,5 ndau", 0, true},
+		{"short group", "12,34", 0, true},
+		{"long group", "1,2345", 0, true},
+		{"long first group", "1234,567", 0, true},
+		{"adjacent separators", "1,,000", 0, true},
+		{"single digit groups", "1 2 3", 0, true},
+		{"mixed separators", "1,000 000", 0, true},
+		{"mixed with underscore", "1_000,000", 0, true},
+		{"separator before decimal", "1,000,.5", 0, true},
+		{"separator in fraction", "1.000,5", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNdauLenient(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNdauLenient(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseNdauLenient(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNdauFormatted(t *testing.T) {
+	european := FormatOptions{Separator: ".", Decimal: ",", Places: 2}
+	swiss := FormatOptions{Separator: "'", Places: VariablePlaces, Unit: UnitNdau}
+	tests := []struct {
+		name    string
+		s       string
+		opts    FormatOptions
+		want    Ndau
+		wantErr bool
+	}{
+		{"european", "1.234,57", european, 123457000000, false},
+		{"european ungrouped", "1234,57", european, 123457000000, false},
+		{"european decimal point", "1.5", european, 0, true},
+		{"european bad group", "1.23,5", european, 0, true},
+		{"european comma as separator", "1,234,567", european, 0, true},
+		{"swiss", "1'234.5 ndau", swiss, 123450000000, false},
+		{"swiss rejects comma", "1,234.5", swiss, 0, true},
+		{"no separator", "1,234", FormatOptions{}, 0, true},
+		{"same marks", "1,234", FormatOptions{Separator: ",", Decimal: ","}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNdauFormatted(tt.s, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseNdauFormatted(%q) error = %v, wantErr %v", tt.s, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseNdauFormatted(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+
+	for _, n := range []Ndau{0, 1, -1, 123456789, -987654321012, math.MaxInt64} {
+		for _, opts := range []FormatOptions{
+			{Places: VariablePlaces},
+			european,
+			{Separator: " ", Decimal: ",", Places: 8, Unit: UnitNdau},
+			swiss,
+			{Separator: ".", Unit: UnitNapu},
+		} {
+			want, err := n.RoundTo(formatUnit(opts), signed.HalfEven)
+			if err != nil {
+				// Format rounds the greatest values past MaxInt64
+				continue
+			}
+			s := n.Format(opts)
+			got, err := ParseNdauFormatted(s, opts)
+			if err != nil {
+				t.Errorf("ParseNdauFormatted(%q) error = %v", s, err)
+				continue
+			}
+			if got != want {
+				t.Errorf("ParseNdauFormatted(%q) = %v, want %v", s, got, want)
+			}
+		}
+	}
+}
+
+// formatUnit returns the unit to which Format rounds under opts
+func formatUnit(opts FormatOptions) Ndau {
+	if opts.Unit == UnitNapu || opts.Places < 0 || opts.Places >= fracdigits {
+		return 1
+	}
+	return Ndau(pow10(fracdigits - opts.Places))
+}
@@ -0,0 +1,98 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// Timestamp, Duration, and Ndau are stored in SQL databases as integer
+// columns holding their native units: microseconds since the epoch,
+// microseconds, and napu respectively.
+
+var _ sql.Scanner = (*Timestamp)(nil)
+var _ driver.Valuer = (*Timestamp)(nil)
+var _ sql.Scanner = (*Duration)(nil)
+var _ driver.Valuer = (*Duration)(nil)
+var _ sql.Scanner = (*Ndau)(nil)
+var _ driver.Valuer = (*Ndau)(nil)
+
+// scanInt64 converts a value read from a database into an int64
+//
+// Integer columns are usually returned as int64, but some drivers return
+// them as text.
+func scanInt64(src interface{}, typename string) (int64, error) {
+	switch v := src.(type) {
+	case int64:
+		return v, nil
+	case []byte:
+		return parseScanned(string(v), typename)
+	case string:
+		return parseScanned(v, typename)
+	case nil:
+		return 0, fmt.Errorf("cannot scan NULL into %s", typename)
+	default:
+		return 0, fmt.Errorf("cannot scan %T into %s", src, typename)
+	}
+}
+
+func parseScanned(s, typename string) (int64, error) {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("cannot scan %q into %s: %s", s, typename, err)
+	}
+	return i, nil
+}
+
+// Value implements driver.Valuer
+func (t Timestamp) Value() (driver.Value, error) {
+	return int64(t), nil
+}
+
+// Scan implements sql.Scanner
+func (t *Timestamp) Scan(src interface{}) error {
+	i, err := scanInt64(src, "Timestamp")
+	if err == nil {
+		*t = Timestamp(i)
+	}
+	return err
+}
+
+// Value implements driver.Valuer
+func (d Duration) Value() (driver.Value, error) {
+	return int64(d), nil
+}
+
+// Scan implements sql.Scanner
+func (d *Duration) Scan(src interface{}) error {
+	i, err := scanInt64(src, "Duration")
+	if err == nil {
+		*d = Duration(i)
+	}
+	return err
+}
+
+// Value implements driver.Valuer
+func (n Ndau) Value() (driver.Value, error) {
+	return int64(n), nil
+}
+
+// Scan implements sql.Scanner
+func (n *Ndau) Scan(src interface{}) error {
+	i, err := scanInt64(src, "Ndau")
+	if err == nil {
+		*n = Ndau(i)
+	}
+	return err
+}
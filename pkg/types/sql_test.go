@@ -0,0 +1,88 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLRoundtrip(t *testing.T) {
+	ts := Timestamp(1234567890123456)
+	d := Duration(-3 * Day)
+	n := Ndau(12345 * constants.NapuPerNdau)
+	tests := []struct {
+		name  string
+		value driver.Valuer
+		into  sql.Scanner
+	}{
+		{"timestamp", ts, new(Timestamp)},
+		{"duration", d, new(Duration)},
+		{"ndau", n, new(Ndau)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := tt.value.Value()
+			require.NoError(t, err)
+			require.IsType(t, int64(0), v)
+			require.True(t, driver.IsValue(v))
+
+			require.NoError(t, tt.into.Scan(v))
+			require.Equal(t, tt.value, deref(tt.into))
+		})
+	}
+}
+
+func deref(s sql.Scanner) interface{} {
+	switch v := s.(type) {
+	case *Timestamp:
+		return *v
+	case *Duration:
+		return *v
+	case *Ndau:
+		return *v
+	}
+	return nil
+}
+
+func TestSQLScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Ndau
+		wantErr bool
+	}{
+		{"int64", int64(-42), -42, false},
+		{"bytes", []byte("42"), 42, false},
+		{"string", "42", 42, false},
+		{"null", nil, 0, true},
+		{"float", 4.2, 0, true},
+		{"bad text", "4.2", 0, true},
+		{"bad bytes", []byte("many"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := Ndau(7)
+			err := n.Scan(tt.src)
+			if tt.wantErr {
+				require.Error(t, err)
+				require.Equal(t, Ndau(7), n, "failed scan must not modify value")
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, n)
+		})
+	}
+}
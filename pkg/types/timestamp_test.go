@@ -11,6 +11,8 @@ package types
 
 
 import (
+	"math"
+	"math/big"
 	"testing"
 	"time"
 
@@ -226,3 +228,76 @@ func TestTimestamp_UnmarshalText(t *testing.T) {
 		})
 	}
 }
+
+// FuzzParseTimestamp checks that every timestamp which parses is within
+// range and survives a roundtrip through its String form
+func FuzzParseTimestamp(f *testing.F) {
+	for _, s := range []string{"2000-01-01T00:00:00.000000Z", "2000-01-18T14:21:00.000000Z", "1992-01-01T00:00:00Z", "2000-01-18T14:21:00Z", "2019-12-10T20:26:53.194866Z", "BLAH"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		ts, err := ParseTimestamp(s)
+		if err != nil {
+			return
+		}
+		require.True(t, ts >= constants.MinTimestamp, "%q parsed to %d", s, ts)
+		got, err := ParseTimestamp(ts.String())
+		require.NoError(t, err, "parsing %q, the String of %q", ts.String(), s)
+		require.Equal(t, ts, got, "roundtrip of %q through %q", s, ts.String())
+	})
+}
+
+// FuzzTimestampArithmetic checks that Add and Sub saturate rather than
+// wrapping, and are exact when they don't saturate
+func FuzzTimestampArithmetic(f *testing.F) {
+	f.Add(int64(0), int64(0))
+	f.Add(int64(0), int64(-1))
+	f.Add(int64(constants.MaxTimestamp), int64(1))
+	f.Add(int64(constants.MaxTimestamp), int64(math.MinInt64))
+	f.Add(int64(1000000), int64(Day))
+	f.Fuzz(func(t *testing.T, ts, d int64) {
+		if ts < constants.MinTimestamp {
+			t.Skip()
+		}
+		tm := Timestamp(ts)
+		dur := Duration(d)
+		exact := new(big.Int).Add(big.NewInt(ts), big.NewInt(d))
+		check := func(op string, got Timestamp, exact *big.Int) {
+			switch {
+			case exact.Sign() < 0:
+				require.Equal(t, Timestamp(constants.MinTimestamp), got, "%d %s %d", ts, op, d)
+			case !exact.IsInt64():
+				require.Equal(t, Timestamp(constants.MaxTimestamp), got, "%d %s %d", ts, op, d)
+			default:
+				require.Equal(t, Timestamp(exact.Int64()), got, "%d %s %d", ts, op, d)
+				require.Equal(t, exact.Int64()-ts, int64(got.Since(tm)), "%d %s %d", ts, op, d)
+			}
+		}
+		check("+", tm.Add(dur), exact)
+		check("-", tm.Sub(dur), exact.Sub(big.NewInt(ts), big.NewInt(d)))
+	})
+}
+
+func BenchmarkParseTimestamp(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = ParseTimestamp("2018-07-10T20:01:02.000000Z")
+	}
+}
+
+func BenchmarkTimestamp_String(b *testing.B) {
+	ts := Timestamp(584582462000000)
+	for i := 0; i < b.N; i++ {
+		_ = ts.String()
+	}
+}
+
+// benchSince keeps the compiler from optimizing away benchmarked arithmetic
+var benchSince Duration
+
+func BenchmarkTimestamp_Arithmetic(b *testing.B) {
+	ts := Timestamp(584582462000000)
+	for i := 0; i < b.N; i++ {
+		d := Duration(i)
+		benchSince += ts.Add(d).Sub(d / 2).Since(ts)
+	}
+}
@@ -0,0 +1,93 @@
+package typesbson
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/ndau/ndaumath/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// This package stores types.Timestamp, types.Duration, and types.Ndau in
+// BSON, so that the types package itself needn't depend on the mongo driver.
+//
+// They are stored as int64 values holding their native units: microseconds
+// since the epoch, microseconds, and napu respectively. They are
+// deliberately not stored as BSON datetimes, which have only millisecond
+// precision.
+
+var (
+	tTimestamp = reflect.TypeOf(types.Timestamp(0))
+	tDuration  = reflect.TypeOf(types.Duration(0))
+	tNdau      = reflect.TypeOf(types.Ndau(0))
+)
+
+// Register registers encoders and decoders for Timestamp, Duration, and Ndau
+// with rb, and returns it.
+func Register(rb *bsoncodec.RegistryBuilder) *bsoncodec.RegistryBuilder {
+	for _, t := range []reflect.Type{tTimestamp, tDuration, tNdau} {
+		rb.RegisterTypeEncoder(t, int64Encoder(t))
+		rb.RegisterTypeDecoder(t, int64Decoder(t))
+	}
+	return rb
+}
+
+// Registry is the default BSON registry with the ndau types registered.
+//
+// Pass it to bson.MarshalWithRegistry and bson.UnmarshalWithRegistry, or
+// to the mongo client with options.Client().SetRegistry.
+var Registry = Register(bson.NewRegistryBuilder()).Build()
+
+// int64Encoder encodes a value of type t as an int64
+func int64Encoder(t reflect.Type) bsoncodec.ValueEncoderFunc {
+	return func(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+		if !val.IsValid() || val.Type() != t {
+			return bsoncodec.ValueEncoderError{Name: t.Name() + "EncodeValue", Types: []reflect.Type{t}, Received: val}
+		}
+		return vw.WriteInt64(val.Int())
+	}
+}
+
+// int64Decoder decodes a value of type t from an integer
+//
+// Values are written as int64, but int32 is also accepted, as other tools
+// writing to the same collection may store small integers that way.
+func int64Decoder(t reflect.Type) bsoncodec.ValueDecoderFunc {
+	return func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+		if !val.CanSet() || val.Type() != t {
+			return bsoncodec.ValueDecoderError{Name: t.Name() + "DecodeValue", Types: []reflect.Type{t}, Received: val}
+		}
+		var i int64
+		switch vr.Type() {
+		case bsontype.Int64:
+			i64, err := vr.ReadInt64()
+			if err != nil {
+				return err
+			}
+			i = i64
+		case bsontype.Int32:
+			i32, err := vr.ReadInt32()
+			if err != nil {
+				return err
+			}
+			i = int64(i32)
+		default:
+			return fmt.Errorf("cannot unmarshal BSON %s into %s", vr.Type(), t.Name())
+		}
+		val.SetInt(i)
+		return nil
+	}
+}
@@ -0,0 +1,66 @@
+package typesbson
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/types"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type bsonRecord struct {
+	Timestamp types.Timestamp `bson:"timestamp"`
+	Duration  types.Duration  `bson:"duration"`
+	Balance   types.Ndau      `bson:"balance"`
+	Fee       *types.Ndau     `bson:"fee"`
+}
+
+func TestBSONRoundtrip(t *testing.T) {
+	fee := types.Ndau(3)
+	rec := bsonRecord{
+		Timestamp: types.Timestamp(1234567890123456),
+		Duration:  types.Duration(-3 * types.Day),
+		Balance:   types.Ndau(12345 * constants.NapuPerNdau),
+		Fee:       &fee,
+	}
+	data, err := bson.MarshalWithRegistry(Registry, rec)
+	require.NoError(t, err)
+
+	// the stored representation is plain int64 native units
+	var raw bson.M
+	require.NoError(t, bson.Unmarshal(data, &raw))
+	require.Equal(t, int64(rec.Timestamp), raw["timestamp"])
+	require.Equal(t, int64(rec.Duration), raw["duration"])
+	require.Equal(t, int64(rec.Balance), raw["balance"])
+	require.Equal(t, int64(fee), raw["fee"])
+
+	var got bsonRecord
+	require.NoError(t, bson.UnmarshalWithRegistry(Registry, data, &got))
+	require.Equal(t, rec, got)
+}
+
+func TestBSONUnmarshalInt32(t *testing.T) {
+	data, err := bson.Marshal(bson.M{"balance": int32(42)})
+	require.NoError(t, err)
+	var got bsonRecord
+	require.NoError(t, bson.UnmarshalWithRegistry(Registry, data, &got))
+	require.Equal(t, types.Ndau(42), got.Balance)
+}
+
+func TestBSONUnmarshalWrongType(t *testing.T) {
+	data, err := bson.Marshal(bson.M{"balance": "42"})
+	require.NoError(t, err)
+	var got bsonRecord
+	require.Error(t, bson.UnmarshalWithRegistry(Registry, data, &got))
+}
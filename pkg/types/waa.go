@@ -0,0 +1,74 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// A BalanceEvent is a change to the balance of an account at a moment
+//
+// Qty is positive for transfers in, and negative for transfers out. An event
+// of 0 Qty changes nothing but the time of the last update.
+type BalanceEvent struct {
+	At  Timestamp
+	Qty Ndau
+}
+
+// ComputeWAA recomputes the weighted average age of an account as of a
+// moment from scratch, given the history of changes to its balance.
+//
+// The account is taken to be empty, with a weighted average age of 0, until
+// the first event. Each event is applied with UpdateWeightedAverageAge, as on
+// the chain, with the time since the previous event and the balance before
+// it; then the time from the last event to asOf is added. Because it depends
+// only on the history, this gives auditors a ground truth against which to
+// check a stored weighted average age: one which drifted because its last
+// update time was not kept, as described in duration_test.go, differs from
+// it.
+//
+// The events must be in order of At, which must not follow asOf, and the
+// balance must never become negative. An empty history gives 0.
+func ComputeWAA(events []BalanceEvent, asOf Timestamp) (Duration, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	var waa Duration
+	var balance Ndau
+	last := events[0].At
+	for i, ev := range events {
+		if ev.At < last {
+			return 0, fmt.Errorf("event %d at %s precedes event %d at %s", i, ev.At, i-1, last)
+		}
+		err := waa.UpdateWeightedAverageAge(ev.At.Since(last), ev.Qty, balance)
+		if err != nil {
+			return 0, errors.Wrapf(err, "event %d", i)
+		}
+		balance, err = balance.Add(ev.Qty)
+		if err != nil {
+			return 0, errors.Wrapf(err, "event %d", i)
+		}
+		if balance < 0 {
+			return 0, fmt.Errorf("event %d leaves a negative balance: %s", i, balance)
+		}
+		last = ev.At
+	}
+	if asOf < last {
+		return 0, fmt.Errorf("%s precedes the last event, at %s", asOf, last)
+	}
+	err := waa.UpdateWeightedAverageAge(asOf.Since(last), 0, balance)
+	if err != nil {
+		return 0, errors.Wrap(err, "advancing to "+asOf.String())
+	}
+	return waa, nil
+}
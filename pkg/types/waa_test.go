@@ -0,0 +1,138 @@
+package types
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeWAA(t *testing.T) {
+	// the canonical data of TestDuration_UpdateWeightedAverageAge, as a
+	// history of transfers
+	start := Timestamp(Year)
+	day := func(n int) Timestamp {
+		return start.Add(Duration(n * Day))
+	}
+	ndau := func(n int) Ndau {
+		return Ndau(n * constants.QuantaPerUnit)
+	}
+	history := []BalanceEvent{
+		{day(0), ndau(100)},
+		{day(30), 0},
+		{day(30), ndau(50)},
+		{day(40), ndau(-50)},
+		{day(60), ndau(100)},
+		{day(80), ndau(-200)},
+		{day(100), ndau(100)},
+	}
+
+	tests := []struct {
+		name   string
+		events int
+		asOf   Timestamp
+		want   Duration
+	}{
+		{"no events", 0, day(10), 0},
+		{"first deposit", 1, day(0), 0},
+		{"aging", 1, day(30), 30 * Day},
+		{"transfer in", 3, day(30), 20 * Day},
+		{"withdraw", 4, day(40), 30 * Day},
+		{"transfer in again", 5, day(60), 25 * Day},
+		{"withdraw everything", 6, day(80), 45 * Day},
+		{"empty account ages", 6, day(90), 55 * Day},
+		{"start again", 7, day(100), 0},
+		{"after the last event", 7, day(110), 10 * Day},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ComputeWAA(history[:tt.events], tt.asOf)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestComputeWAAMatchesIncremental(t *testing.T) {
+	// applying the updates as they happen gives the same WAA, if the time
+	// of the last update is kept properly
+	start := Timestamp(Year)
+	history := []BalanceEvent{
+		{start, 9305537700000},
+		{start.Add(3 * Hour), 1},
+		{start.Add(5*Hour + 17*Second), 100000000},
+		{start.Add(Day), -200000000},
+		{start.Add(Day + 3*Microsecond), 7},
+	}
+	asOf := start.Add(2 * Day)
+
+	var waa Duration
+	var balance Ndau
+	last := start
+	for _, ev := range history {
+		require.NoError(t, waa.UpdateWeightedAverageAge(ev.At.Since(last), ev.Qty, balance))
+		balance += ev.Qty
+		last = ev.At
+	}
+	require.NoError(t, waa.UpdateWeightedAverageAge(asOf.Since(last), 0, balance))
+
+	got, err := ComputeWAA(history, asOf)
+	require.NoError(t, err)
+	require.Equal(t, waa, got)
+}
+
+func TestComputeWAADetectsDrift(t *testing.T) {
+	// the lastWAAUpdate bug of TestWAAUpdateCalculation: when the time of
+	// the last update was not kept, the time before it was counted again,
+	// and the WAA could exceed the age of the account
+	created := Timestamp(Year)
+	history := []BalanceEvent{
+		{created, 9305537700000},
+		{created.Add(2 * Hour), 0},
+	}
+	asOf := created.Add(3*Hour + 30*Minute)
+
+	var drifted Duration
+	require.NoError(t, drifted.UpdateWeightedAverageAge(0, history[0].Qty, 0))
+	require.NoError(t, drifted.UpdateWeightedAverageAge(2*Hour, 0, history[0].Qty))
+	// the bug: lastWAAUpdate stayed at created
+	require.NoError(t, drifted.UpdateWeightedAverageAge(asOf.Since(created), 0, history[0].Qty))
+	require.Greater(t, int64(drifted), int64(asOf.Since(created)))
+
+	truth, err := ComputeWAA(history, asOf)
+	require.NoError(t, err)
+	require.Equal(t, asOf.Since(created), truth)
+	require.NotEqual(t, drifted, truth)
+}
+
+func TestComputeWAAErrors(t *testing.T) {
+	start := Timestamp(Year)
+	tests := []struct {
+		name   string
+		events []BalanceEvent
+		asOf   Timestamp
+	}{
+		{"out of order", []BalanceEvent{{start.Add(Day), 1}, {start, 1}}, start.Add(Day)},
+		{"negative balance", []BalanceEvent{{start, 10}, {start.Add(Day), -11}}, start.Add(Day)},
+		{"withdraw first", []BalanceEvent{{start, -1}}, start},
+		{"asOf before last event", []BalanceEvent{{start, 10}, {start.Add(Day), 1}}, start},
+		{"balance overflow", []BalanceEvent{{start, math.MaxInt64}, {start, 1}}, start},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ComputeWAA(tt.events, tt.asOf)
+			require.Error(t, err)
+		})
+	}
+}
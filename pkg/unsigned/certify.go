@@ -0,0 +1,102 @@
+package unsigned
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// CertifyExpFrac returns a bound on the relative error of ExpFrac(n, denom)
+// for every numerator n in [0, maxInput], compared to the exact value of
+// denom * e ^ (n / denom).
+//
+// Unlike comparing results against a decimal reference, which only shows
+// the error of the inputs tried, the bound is guaranteed: it follows from
+// the error which each step of the series can introduce, computed exactly.
+// It is an error if ExpFrac can fail for some input in the domain.
+//
+// ExpFrac computes (with both inputs scaled by 10) the terms
+// p_k = floor(p_{k-1} * x), so each product falls short of its exact value
+// by at most e_k = e_{k-1} * x + 1, and each term p_k / k! is truncated by
+// less than 1 more. Once k! overflows, the wrapped factorial can make a term
+// too large; each such excess is bounded by the term's value at maxInput.
+// Products only grow with the input, so the bounds at maxInput hold for
+// every smaller input.
+func CertifyExpFrac(maxInput, denom uint64) (*big.Rat, error) {
+	if denom == 0 {
+		return nil, errors.New("denominator must be positive")
+	}
+	if denom > math.MaxUint64/20 {
+		return nil, errors.New("denominator too large")
+	}
+	if maxInput > denom {
+		return nil, errors.New("fraction must be between 0 and 1")
+	}
+	// scaled exactly as ExpFrac scales them
+	a, d := 10*maxInput, 10*denom
+	x := new(big.Rat).SetFrac(new(big.Int).SetUint64(a), new(big.Int).SetUint64(d))
+	one := big.NewRat(1, 1)
+
+	// under bounds how far the computed sum may fall short of the exact
+	// sum, and over how far it may exceed it
+	under, over := new(big.Rat), new(big.Rat)
+	// e bounds the shortfall of the current product, and exact is its
+	// exact value at maxInput: d * x^k
+	e := new(big.Rat)
+	exact := new(big.Rat).SetUint64(a)
+	factorial := big.NewInt(1)
+	product := a
+	fact := uint64(1)
+	term := new(big.Rat)
+	var err error
+	for i := uint64(2); product != 0; i++ {
+		product, err = MulDiv(product, a, d)
+		if err != nil {
+			return nil, err
+		}
+		e.Mul(e, x).Add(e, one)
+		exact.Mul(exact, x)
+		factorial.Mul(factorial, new(big.Int).SetUint64(i))
+		fact *= i
+		if fact == 0 {
+			return nil, errors.New("factorial overflows to 0")
+		}
+		if factorial.IsUint64() {
+			// the term is truncated by less than one, and computed from a
+			// product which falls short by at most e
+			term.SetFrac(e.Num(), new(big.Int).Mul(e.Denom(), factorial))
+			under.Add(under, term.Add(term, one))
+		} else {
+			// the computed term can't be negative, so it falls short by at
+			// most its exact value, and exceeds it by at most its computed
+			// value
+			term.SetFrac(exact.Num(), new(big.Int).Mul(exact.Denom(), factorial))
+			under.Add(under, term)
+			over.Add(over, term.SetUint64(product/fact))
+		}
+	}
+	// the exact terms after the last are each at most e, over factorials
+	// whose reciprocals sum to less than 2 / k! for the last k
+	tail := new(big.Rat).Mul(e, big.NewRat(2, 1))
+	tail.Quo(tail, new(big.Rat).SetInt(factorial))
+	under.Add(under, tail)
+
+	// the result is (sum + 5) / 10, truncated, so it is within
+	// max(under, over) / 10 + 1/2 of the exact value, which is at least denom
+	bound := under
+	if over.Cmp(under) > 0 {
+		bound = over
+	}
+	bound.Quo(bound, big.NewRat(10, 1)).Add(bound, big.NewRat(1, 2))
+	return bound.Quo(bound, new(big.Rat).SetUint64(denom)), nil
+}
@@ -11,7 +11,10 @@ package unsigned
 
 
 import (
+	"math/big"
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/ericlagergren/decimal"
 	dmath "github.com/ericlagergren/decimal/math"
@@ -81,6 +84,73 @@ func TestExpFrac(t *testing.T) {
 	}
 }
 
+func TestCertifyExpFrac(t *testing.T) {
+	const denom = 1000000000000
+	// the greatest input for which ExpFrac succeeds with this denominator
+	const limit = 637858346856
+
+	bound, err := CertifyExpFrac(limit, denom)
+	if err != nil {
+		t.Fatalf("CertifyExpFrac() error = %v", err)
+	}
+	// ExpFrac is accurate to within a few units of the denominator
+	if bound.Cmp(big.NewRat(10, denom)) > 0 {
+		t.Errorf("CertifyExpFrac() = %v, want at most 10/%d", bound, denom)
+	}
+
+	// the bound must hold for the inputs we can check. want is rounded,
+	// so it may itself be up to 1/2 from the exact value.
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	inputs := []uint64{0, 1, 2, denom / 1000000, denom / 100, limit / 2, limit - 1, limit}
+	for i := 0; i < 100; i++ {
+		inputs = append(inputs, uint64(r.Int63n(limit+1)))
+	}
+	slack := new(big.Rat).Mul(bound, big.NewRat(denom, 1))
+	slack.Add(slack, big.NewRat(1, 2))
+	for _, n := range inputs {
+		got, err := ExpFrac(n, denom)
+		if err != nil {
+			t.Fatalf("ExpFrac(%d) error = %v", n, err)
+		}
+		want := bigexp(n, denom)
+		diff := new(big.Rat).SetInt64(int64(got) - int64(want))
+		if diff.Abs(diff).Cmp(slack) > 0 {
+			t.Errorf("ExpFrac(%d) = %d, want %d within %s", n, got, want, slack.FloatString(3))
+		}
+	}
+
+	// a bound for a domain also bounds every smaller domain
+	smaller, err := CertifyExpFrac(limit/2, denom)
+	if err != nil {
+		t.Fatalf("CertifyExpFrac() error = %v", err)
+	}
+	if smaller.Cmp(bound) > 0 {
+		t.Errorf("CertifyExpFrac(%d) = %v, more than %v", limit/2, smaller, bound)
+	}
+}
+
+func TestCertifyExpFracErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxInput uint64
+		denom    uint64
+	}{
+		{"zero denom", 0, 0},
+		{"denom too large", 1, 1 << 62},
+		{"a>b", 150000000, 100000000},
+		{"factorial overflows", 637858346857, 1000000000000},
+		{"x is 1", 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bound, err := CertifyExpFrac(tt.maxInput, tt.denom)
+			if err == nil {
+				t.Errorf("CertifyExpFrac() = %v, want error", bound)
+			}
+		})
+	}
+}
+
 // this prevents optimization of the return value
 var v uint64
 
@@ -95,3 +165,9 @@ func BenchmarkBigExp(b *testing.B) {
 		v = bigexp(15000000, 100000000)
 	}
 }
+
+func BenchmarkCertifyExpFrac(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		_, _ = CertifyExpFrac(637858346856, 1000000000000)
+	}
+}
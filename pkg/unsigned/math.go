@@ -11,6 +11,8 @@ package unsigned
 
 
 import (
+	"math/bits"
+
 	"github.com/ericlagergren/decimal"
 	"github.com/ndau/ndaumath/pkg/ndauerr"
 )
@@ -32,16 +34,17 @@ func Add(a, b uint64) (uint64, error) {
 	return ret, nil
 }
 
-// Sub adds two uint64s and errors if there is an overflow
+// Sub subtracts two uint64s and errors with ErrUnderflow if the result would
+// be negative
+//
+// It once returned ErrOverflow. ErrUnderflow is a kind of ErrOverflow, so
+// errors.Is(err, ndauerr.ErrOverflow) still holds, but err == ErrOverflow
+// doesn't.
 func Sub(a, b uint64) (uint64, error) {
-	x := makeDecimal(a)
-	y := makeDecimal(b)
-	x.Sub(x, y)
-	ret, ok := x.Uint64()
-	if !ok {
-		return 0, ndauerr.ErrOverflow
+	if b > a {
+		return 0, ndauerr.ErrUnderflow
 	}
-	return ret, nil
+	return a - b, nil
 }
 
 // Mul multiplies two uint64s and errors if there is an overflow
@@ -113,19 +116,63 @@ func DivMod(a, b uint64) (uint64, uint64, error) {
 // MulDiv multiplies a uint64 value by the ratio n/d without overflowing the uint64,
 // provided that the final result does not overflow. Returns error if the result
 // cannot be converted back to uint64.
+//
+// The product is computed exactly in 128 bits, so the result is always the
+// exact quotient, truncated.
+//
+// Compatibility: MulDiv used to compute products of 2^112 or more in a
+// 128-bit decimal context, which holds only 34 digits. Where d is at most
+// 10^34 / 2^64, about 5.4e14, the results are identical, as any product of
+// more than 34 digits overflows the quotient either way; that covers every
+// consensus caller, which divides by constants.RateDenominator or by a year.
+// For greater divisors, results may differ from those of earlier versions.
 func MulDiv(v, n, d uint64) (uint64, error) {
 	if d == 0 {
 		return 0, ndauerr.ErrDivideByZero
 	}
 
-	x := makeDecimal(v)
-	y := makeDecimal(n)
-	z := makeDecimal(d)
-	x.Mul(x, y)
-	x.QuoInt(x, z)
-	ret, ok := x.Uint64()
-	if !ok {
+	hi, lo := bits.Mul64(v, n)
+	if hi >= d {
+		// the quotient is at least 2^64
 		return 0, ndauerr.ErrOverflow
 	}
-	return ret, nil
+	q, _ := bits.Div64(hi, lo, d)
+	return q, nil
+}
+
+// MulDiv128 multiplies a uint64 value by the ratio n/d, returning the full
+// 128-bit quotient as its high and low words.
+//
+// Unlike MulDiv, it never overflows, so it suits callers which can consume
+// 128-bit results. The quotient is exact, truncated.
+func MulDiv128(v, n, d uint64) (hi, lo uint64, err error) {
+	if d == 0 {
+		return 0, 0, ndauerr.ErrDivideByZero
+	}
+
+	ph, pl := bits.Mul64(v, n)
+	// dividing the high word first ensures the remainder is less than d,
+	// as bits.Div64 requires
+	hi, rem := ph/d, ph%d
+	lo, _ = bits.Div64(rem, pl, d)
+	return hi, lo, nil
+}
+
+// MulDivRem multiplies a uint64 value by the ratio n/d, as MulDiv does, and
+// also returns the remainder, such that quot*d + rem == v*n exactly.
+//
+// Carrying the remainder forward lets callers which divide a quantity in
+// several steps, such as distributing EAI among co-stakers, account for
+// every napu. Returns an error if the quotient overflows.
+func MulDivRem(v, n, d uint64) (quot, rem uint64, err error) {
+	if d == 0 {
+		return 0, 0, ndauerr.ErrDivideByZero
+	}
+
+	hi, lo := bits.Mul64(v, n)
+	if hi >= d {
+		return 0, 0, ndauerr.ErrOverflow
+	}
+	quot, rem = bits.Div64(hi, lo, d)
+	return quot, rem, nil
 }
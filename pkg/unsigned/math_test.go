@@ -11,13 +11,17 @@ package unsigned
 
 
 import (
+	"errors"
 	"math"
 	"math/big"
+	"math/bits"
 	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/ericlagergren/decimal"
+	"github.com/ndau/ndaumath/pkg/constants"
+	"github.com/ndau/ndaumath/pkg/ndauerr"
 )
 
 func TestAdd(t *testing.T) {
@@ -77,6 +81,12 @@ func TestSub(t *testing.T) {
 				t.Errorf("Sub() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if err != nil && err != ndauerr.ErrUnderflow {
+				t.Errorf("Sub() error = %v, want %v", err, ndauerr.ErrUnderflow)
+			}
+			if err != nil && !errors.Is(err, ndauerr.ErrOverflow) {
+				t.Errorf("Sub() error = %v, not a kind of %v", err, ndauerr.ErrOverflow)
+			}
 			if got != tt.want {
 				t.Errorf("Sub() = %v, want %v", got, tt.want)
 			}
@@ -263,35 +273,44 @@ func bigmuldiv(a, b, c uint64) uint64 {
 	return q.Uint64()
 }
 
-func compareOne(r *rand.Rand, t *testing.T) {
-	// make sure they're never negative
-	a := r.Uint64() //& 0x7FFFFFFFFFFFFFFF
-	b := r.Uint64() //& 0x7FFFFFFFFFFFFFFF
-	c := r.Uint64() //& 0x7FFFFFFFFFFFFFFF
-	if b > c {
-		b, c = c, b
-	}
-	p, err := MulDiv(a, b, c)
-	if err != nil {
-		t.Error(err)
-	}
-	q := bigmuldiv(a, b, c)
-	if p != q {
-		t.Errorf("muldiv didn't match results from big.Int: (%v %v %v) %v != %v", a, b, c, p, q)
-		t.Errorf("a=%x b=%x\n", a, b)
-		a /= 10
-		b /= 10
-		c /= 10
-		p, _ = MulDiv(a, b, c)
-		q := bigmuldiv(a, b, c)
-		t.Errorf("/10: (%v %v %v) %v == %v", a, b, c, p, q)
-	}
+// FuzzMulDiv compares MulDiv against big.Int arithmetic
+func FuzzMulDiv(f *testing.F) {
+	f.Add(uint64(0), uint64(0), uint64(1))
+	f.Add(uint64(80), uint64(2), uint64(5))
+	f.Add(uint64(math.MaxUint64), uint64(math.MaxUint64-2), uint64(math.MaxUint64-1))
+	f.Add(uint64(math.MaxUint64), uint64(2), uint64(1))
+	f.Add(uint64(1), uint64(1), uint64(0))
+	f.Add(uint64(0x1234567890abcdef), uint64(0x7edcba0987654321), uint64(0xfedcba0987654321))
+	f.Fuzz(func(t *testing.T, a, b, c uint64) {
+		p, err := MulDiv(a, b, c)
+		if c == 0 {
+			if err == nil {
+				t.Errorf("MulDiv(%d, %d, 0) = %d; want error", a, b, p)
+			}
+			return
+		}
+		x := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+		x.Quo(x, new(big.Int).SetUint64(c))
+		if x.IsUint64() != (err == nil) || (err == nil && p != x.Uint64()) {
+			t.Errorf("MulDiv(%d, %d, %d) = %d, %v; want %s", a, b, c, p, err, x)
+		}
+	})
 }
 
-func TestMulDivFuzz(t *testing.T) {
+func TestMulDivMagnitudesFuzz(t *testing.T) {
+	// most products of random uint64s overflow the quotient; varying the
+	// magnitudes exercises both sides of that boundary
 	r := rand.New(rand.NewSource(time.Now().Unix()))
 	for i := 0; i < 10000; i++ {
-		compareOne(r, t)
+		a := r.Uint64() >> uint(r.Intn(64))
+		b := r.Uint64() >> uint(r.Intn(64))
+		c := r.Uint64()>>uint(r.Intn(64)) | 1
+		x := new(big.Int).Mul(new(big.Int).SetUint64(a), new(big.Int).SetUint64(b))
+		x.Quo(x, new(big.Int).SetUint64(c))
+		p, err := MulDiv(a, b, c)
+		if x.IsUint64() != (err == nil) || (err == nil && p != x.Uint64()) {
+			t.Errorf("MulDiv(%d, %d, %d) = %d, %v; want %s", a, b, c, p, err, x)
+		}
 	}
 }
 
@@ -308,3 +327,250 @@ func TestConversion(t *testing.T) {
 		t.Error("bug in decimal library (https://github.com/ericlagergren/decimal/issues/104) remains but makeDecimal has already been nerfed")
 	}
 }
+
+func TestMulDivRem(t *testing.T) {
+	tests := []struct {
+		name    string
+		v, n, d uint64
+		quot    uint64
+		rem     uint64
+		wantErr bool
+	}{
+		{"exact", 80, 2, 5, 32, 0, false},
+		{"remainder", 83, 2, 5, 33, 1, false},
+		{"large intermediate", math.MaxUint64, math.MaxUint64 - 2, math.MaxUint64 - 1, math.MaxUint64 - 2, math.MaxUint64 - 2, false},
+		{"divide by zero", 1, 1, 0, 0, 0, true},
+		{"overflow", math.MaxUint64, 2, 1, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quot, rem, err := MulDivRem(tt.v, tt.n, tt.d)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("MulDivRem() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if quot != tt.quot || rem != tt.rem {
+				t.Errorf("MulDivRem() = %d, %d, want %d, %d", quot, rem, tt.quot, tt.rem)
+			}
+		})
+	}
+}
+
+func TestMulDivRemFuzz(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v := r.Uint64() >> uint(r.Intn(64))
+		n := r.Uint64() >> uint(r.Intn(64))
+		d := r.Uint64()>>uint(r.Intn(64)) | 1
+		quot, rem, err := MulDivRem(v, n, d)
+		mulDiv, mdErr := MulDiv(v, n, d)
+		if (err == nil) != (mdErr == nil) {
+			t.Fatalf("MulDivRem(%d, %d, %d) error = %v; MulDiv error = %v", v, n, d, err, mdErr)
+		}
+		if err != nil {
+			continue
+		}
+		if quot != mulDiv {
+			t.Errorf("MulDivRem(%d, %d, %d) quotient %d != MulDiv %d", v, n, d, quot, mulDiv)
+		}
+		lhs := new(big.Int).Mul(new(big.Int).SetUint64(quot), new(big.Int).SetUint64(d))
+		lhs.Add(lhs, new(big.Int).SetUint64(rem))
+		rhs := new(big.Int).Mul(new(big.Int).SetUint64(v), new(big.Int).SetUint64(n))
+		if lhs.Cmp(rhs) != 0 || rem >= d {
+			t.Errorf("MulDivRem(%d, %d, %d) = %d, %d does not reconstruct the product", v, n, d, quot, rem)
+		}
+	}
+}
+
+// edgeValues returns values near the boundaries which 128-bit multiplication
+// and division must handle
+func edgeValues() []uint64 {
+	values := []uint64{0, 1, 2, 3, 5, 7, 10, 100, 1000000007}
+	for _, shift := range []uint{8, 16, 31, 32, 33, 48, 63} {
+		p := uint64(1) << shift
+		values = append(values, p-1, p, p+1)
+	}
+	return append(values, math.MaxUint64-1, math.MaxUint64, math.MaxUint32*math.MaxUint32)
+}
+
+func TestMulDivExhaustiveEdges(t *testing.T) {
+	values := edgeValues()
+	for _, v := range values {
+		for _, n := range values {
+			product := new(big.Int).Mul(new(big.Int).SetUint64(v), new(big.Int).SetUint64(n))
+			for _, d := range values {
+				if d == 0 {
+					if _, err := MulDiv(v, n, d); err != ndauerr.ErrDivideByZero {
+						t.Errorf("MulDiv(%d, %d, 0) error = %v", v, n, err)
+					}
+					if _, _, err := MulDiv128(v, n, d); err != ndauerr.ErrDivideByZero {
+						t.Errorf("MulDiv128(%d, %d, 0) error = %v", v, n, err)
+					}
+					continue
+				}
+				want, wantRem := new(big.Int).QuoRem(product, new(big.Int).SetUint64(d), new(big.Int))
+
+				hi, lo, err := MulDiv128(v, n, d)
+				if err != nil {
+					t.Fatalf("MulDiv128(%d, %d, %d) error = %v", v, n, d, err)
+				}
+				got := new(big.Int).Lsh(new(big.Int).SetUint64(hi), 64)
+				got.Or(got, new(big.Int).SetUint64(lo))
+				if got.Cmp(want) != 0 {
+					t.Errorf("MulDiv128(%d, %d, %d) = %s; want %s", v, n, d, got, want)
+				}
+
+				q, err := MulDiv(v, n, d)
+				quot, rem, remErr := MulDivRem(v, n, d)
+				if !want.IsUint64() {
+					if err != ndauerr.ErrOverflow || remErr != ndauerr.ErrOverflow {
+						t.Errorf("MulDiv(%d, %d, %d) errors = %v, %v; want overflow", v, n, d, err, remErr)
+					}
+					continue
+				}
+				if err != nil || remErr != nil {
+					t.Errorf("MulDiv(%d, %d, %d) errors = %v, %v", v, n, d, err, remErr)
+					continue
+				}
+				if q != want.Uint64() || quot != q || rem != wantRem.Uint64() {
+					t.Errorf("MulDiv(%d, %d, %d) = %d; MulDivRem = %d, %d; want %s, %s", v, n, d, q, quot, rem, want, wantRem)
+				}
+			}
+		}
+	}
+}
+
+// decimalMulDiv is MulDiv as it was before it computed all products
+// exactly: products of 2^112 or more were computed in a 128-bit decimal
+// context, which holds only 34 digits.
+func decimalMulDiv(v, n, d uint64) (uint64, error) {
+	if d == 0 {
+		return 0, ndauerr.ErrDivideByZero
+	}
+	if hi, lo := bits.Mul64(v, n); hi < 1<<48 {
+		if hi >= d {
+			return 0, ndauerr.ErrOverflow
+		}
+		q, _ := bits.Div64(hi, lo, d)
+		return q, nil
+	}
+	x := makeDecimal(v)
+	x.Mul(x, makeDecimal(n))
+	x.QuoInt(x, makeDecimal(d))
+	ret, ok := x.Uint64()
+	if !ok {
+		return 0, ndauerr.ErrOverflow
+	}
+	return ret, nil
+}
+
+// maxUnchangedDivisor is the greatest divisor for which MulDiv is certain to
+// agree with decimalMulDiv: dividing a product of 10^34 or more by it, even
+// rounded to 34 digits, overflows the quotient.
+const maxUnchangedDivisor = 542101086242752
+
+func TestMulDivUnchangedForConsensusDivisors(t *testing.T) {
+	bound := new(big.Int).Exp(big.NewInt(10), big.NewInt(34), nil)
+	bound.Rsh(bound, 64)
+	if bound.Uint64() != maxUnchangedDivisor {
+		t.Fatalf("10^34 / 2^64 = %s, not %d", bound, uint64(maxUnchangedDivisor))
+	}
+
+	// the consensus divisors: the EAI factor denominator, and types.Year,
+	// which can't be imported here
+	const year = 365 * 24 * 60 * 60 * 1000000
+	divisors := []uint64{constants.RateDenominator, year, maxUnchangedDivisor}
+
+	values := edgeValues()
+	// values whose products straddle 10^34, and the quotient overflow
+	for _, p := range []uint64{1e17, 8e16, 3e16} {
+		for _, delta := range []uint64{0, 1, 7, 1e9} {
+			values = append(values, p-delta, p+delta)
+		}
+	}
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 200; i++ {
+		values = append(values, r.Uint64()>>uint(r.Intn(64)))
+	}
+	for _, d := range divisors {
+		for _, v := range values {
+			for _, n := range values {
+				got, err := MulDiv(v, n, d)
+				want, wantErr := decimalMulDiv(v, n, d)
+				if err != wantErr || got != want {
+					t.Fatalf("MulDiv(%d, %d, %d) = %d, %v; historically %d, %v", v, n, d, got, err, want, wantErr)
+				}
+			}
+		}
+	}
+
+	// beyond the bound, rounding the product can change the quotient
+	const v = 1e17 + 1
+	if got, _ := MulDiv(v, v, v); got != v {
+		t.Errorf("MulDiv(%d, %d, %d) = %d", uint64(v), uint64(v), uint64(v), got)
+	}
+	if got, _ := decimalMulDiv(v, v, v); got == v {
+		t.Errorf("decimalMulDiv(%d, %d, %d) is exact; the test no longer covers a difference", uint64(v), uint64(v), uint64(v))
+	}
+}
+
+func TestMulDiv128Fuzz(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		v := r.Uint64() >> uint(r.Intn(64))
+		n := r.Uint64() >> uint(r.Intn(64))
+		d := r.Uint64()>>uint(r.Intn(64)) | 1
+		want := new(big.Int).Mul(new(big.Int).SetUint64(v), new(big.Int).SetUint64(n))
+		want.Quo(want, new(big.Int).SetUint64(d))
+
+		hi, lo, err := MulDiv128(v, n, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := new(big.Int).Lsh(new(big.Int).SetUint64(hi), 64)
+		got.Or(got, new(big.Int).SetUint64(lo))
+		if got.Cmp(want) != 0 {
+			t.Errorf("MulDiv128(%d, %d, %d) = %s; want %s", v, n, d, got, want)
+		}
+	}
+}
+
+// sink prevents optimization of benchmarked results; the inputs are
+// variables so that the compiler can't fold the calculations
+var (
+	sink                     uint64
+	benchV, benchN, benchD   uint64 = 1234567890123456789, 1000970974193617, 1000000000000000
+	benchSmallV, benchSmallD uint64 = 123456789, 7
+	benchMax                 uint64 = math.MaxUint64
+)
+
+func BenchmarkMulDiv(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink, _ = MulDiv(benchV, benchN, benchD)
+	}
+}
+
+func BenchmarkMulDivSmall(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink, _ = MulDiv(benchSmallV, 3, benchSmallD)
+	}
+}
+
+func BenchmarkMulDiv128(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink, _, _ = MulDiv128(benchMax, benchMax, 3)
+	}
+}
+
+func BenchmarkMulDivBig(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sink = bigmuldiv(benchV, benchN, benchD)
+	}
+}
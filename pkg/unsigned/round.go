@@ -0,0 +1,103 @@
+package unsigned
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// RoundingMode determines how the inexact result of a division is rounded
+// to an integer.
+type RoundingMode int
+
+// These are the supported rounding modes.
+const (
+	// Truncate rounds towards zero. This is what MulDiv does.
+	Truncate RoundingMode = iota
+	// HalfEven rounds to the nearest integer, and ties to the even integer.
+	HalfEven
+	// HalfUp rounds to the nearest integer, and ties away from zero.
+	HalfUp
+	// Ceil rounds towards positive infinity.
+	Ceil
+	// Floor rounds towards negative infinity.
+	Floor
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case Truncate:
+		return "Truncate"
+	case HalfEven:
+		return "HalfEven"
+	case HalfUp:
+		return "HalfUp"
+	case Ceil:
+		return "Ceil"
+	case Floor:
+		return "Floor"
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+// MulDivRound multiplies a uint64 value by the ratio n/d without overflowing
+// the uint64, provided that the final result does not overflow, and rounds
+// the result according to mode.
+//
+// As the result is never negative, Floor is equivalent to Truncate, and
+// HalfUp rounds ties upwards.
+func MulDivRound(v, n, d uint64, mode RoundingMode) (uint64, error) {
+	if d == 0 {
+		return 0, ndauerr.ErrDivideByZero
+	}
+
+	// the 128-bit product can't overflow, and the quotient fits in a uint64
+	// exactly when the high word of the product is less than d
+	hi, lo := bits.Mul64(v, n)
+	if hi >= d {
+		return 0, ndauerr.ErrOverflow
+	}
+	q, r := bits.Div64(hi, lo, d)
+
+	if r != 0 {
+		// how the remainder compares to half the denominator; d-r can't
+		// overflow as 2*r could
+		half := 0
+		if r > d-r {
+			half = 1
+		} else if r < d-r {
+			half = -1
+		}
+		roundUp := false
+		switch mode {
+		case Truncate, Floor:
+		case Ceil:
+			roundUp = true
+		case HalfUp:
+			roundUp = half >= 0
+		case HalfEven:
+			roundUp = half > 0 || (half == 0 && q&1 == 1)
+		default:
+			return 0, fmt.Errorf("unknown rounding mode %s", mode)
+		}
+		if roundUp {
+			if q == math.MaxUint64 {
+				return 0, ndauerr.ErrOverflow
+			}
+			q++
+		}
+	}
+	return q, nil
+}
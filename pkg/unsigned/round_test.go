@@ -0,0 +1,117 @@
+package unsigned
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestMulDivRound(t *testing.T) {
+	type args struct {
+		v, n, d uint64
+	}
+	// results listed in order Truncate, HalfEven, HalfUp, Ceil, Floor
+	tests := []struct {
+		name string
+		args args
+		want [5]uint64
+	}{
+		{"exact", args{10, 3, 5}, [5]uint64{6, 6, 6, 6, 6}},
+		{"2.5", args{5, 1, 2}, [5]uint64{2, 2, 3, 3, 2}},
+		{"3.5", args{7, 1, 2}, [5]uint64{3, 4, 4, 4, 3}},
+		{"2.4", args{12, 1, 5}, [5]uint64{2, 2, 2, 3, 2}},
+		{"2.6", args{13, 1, 5}, [5]uint64{2, 3, 3, 3, 2}},
+		{"0.5", args{1, 1, 2}, [5]uint64{0, 0, 1, 1, 0}},
+		{"wide intermediate", args{math.MaxUint64, 3, 6}, [5]uint64{math.MaxUint64 / 2, math.MaxUint64/2 + 1, math.MaxUint64/2 + 1, math.MaxUint64/2 + 1, math.MaxUint64 / 2}},
+	}
+	for _, tt := range tests {
+		for mode := Truncate; mode <= Floor; mode++ {
+			t.Run(tt.name+" "+mode.String(), func(t *testing.T) {
+				got, err := MulDivRound(tt.args.v, tt.args.n, tt.args.d, mode)
+				if err != nil {
+					t.Errorf("MulDivRound() error = %v", err)
+					return
+				}
+				if got != tt.want[mode] {
+					t.Errorf("MulDivRound() = %v, want %v", got, tt.want[mode])
+				}
+			})
+		}
+	}
+}
+
+func TestMulDivRoundMatchesMulDiv(t *testing.T) {
+	for _, v := range []uint64{0, 1, 7, 1000003, math.MaxUint32, math.MaxUint64} {
+		for _, n := range []uint64{1, 3, 99999989} {
+			for _, d := range []uint64{1, 7, 100000000, math.MaxUint64} {
+				want, werr := MulDiv(v, n, d)
+				got, gerr := MulDivRound(v, n, d, Truncate)
+				if (werr != nil) != (gerr != nil) || got != want {
+					t.Errorf("MulDivRound(%d, %d, %d, Truncate) = %d, %v; MulDiv = %d, %v", v, n, d, got, gerr, want, werr)
+				}
+			}
+		}
+	}
+}
+
+func TestMulDivRoundErrors(t *testing.T) {
+	if _, err := MulDivRound(1, 1, 0, HalfEven); err == nil {
+		t.Error("expected divide by zero")
+	}
+	if _, err := MulDivRound(math.MaxUint64, 2, 1, HalfEven); err == nil {
+		t.Error("expected overflow")
+	}
+	// rounding up may itself overflow
+	if _, err := MulDivRound(math.MaxUint64, 3, 2, Ceil); err == nil {
+		t.Error("expected overflow")
+	}
+	if _, err := MulDivRound(1, 1, 2, RoundingMode(99)); err == nil {
+		t.Error("expected unknown mode")
+	}
+}
+
+// bigMulDivRound is a straightforward reference implementation of MulDivRound
+func bigMulDivRound(v, n, d uint64, mode RoundingMode) (*big.Int, bool) {
+	q := new(big.Int).SetUint64(v)
+	q.Mul(q, new(big.Int).SetUint64(n))
+	denom := new(big.Int).SetUint64(d)
+	r := new(big.Int)
+	q.QuoRem(q, denom, r)
+	if r.Sign() != 0 {
+		half := r.Lsh(r, 1).Cmp(denom)
+		if mode == Ceil || (mode == HalfUp && half >= 0) ||
+			(mode == HalfEven && (half > 0 || (half == 0 && q.Bit(0) == 1))) {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q, q.IsUint64()
+}
+
+func TestMulDivRoundFuzz(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().Unix()))
+	for i := 0; i < 10000; i++ {
+		// vary the magnitudes so that all paths are exercised
+		v := r.Uint64() >> uint(r.Intn(64))
+		n := r.Uint64() >> uint(r.Intn(64))
+		d := r.Uint64()>>uint(r.Intn(64)) | 1
+		for _, mode := range []RoundingMode{Truncate, HalfEven, HalfUp, Ceil, Floor} {
+			want, ok := bigMulDivRound(v, n, d, mode)
+			got, err := MulDivRound(v, n, d, mode)
+			if ok != (err == nil) || (ok && got != want.Uint64()) {
+				t.Errorf("MulDivRound(%d, %d, %d, %s) = %d, %v; want %s", v, n, d, mode, got, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package unsigned
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math/big"
+
+	"github.com/ndau/ndaumath/pkg/ndauerr"
+)
+
+// These operate over whole slices so that callers summing many terms don't
+// need to handle an error per term. The accumulation happens in a big.Int,
+// so the only possible overflow is of the final result.
+
+// SumChecked returns the sum of all values, and errors if the result
+// overflows a uint64.
+func SumChecked(values []uint64) (uint64, error) {
+	sum := new(big.Int)
+	term := new(big.Int)
+	for _, v := range values {
+		sum.Add(sum, term.SetUint64(v))
+	}
+	if !sum.IsUint64() {
+		return 0, ndauerr.ErrOverflow
+	}
+	return sum.Uint64(), nil
+}
+
+// DotProduct returns the sum of a[i]*b[i] over all i, divided by denom.
+//
+// The division happens once, after the full sum has been computed, so no
+// precision is lost to intermediate truncation. Errors if the slices differ
+// in length, if denom is 0, or if the result overflows a uint64.
+func DotProduct(a, b []uint64, denom uint64) (uint64, error) {
+	if len(a) != len(b) {
+		return 0, ndauerr.ErrLengthMismatch
+	}
+	if denom == 0 {
+		return 0, ndauerr.ErrDivideByZero
+	}
+	sum := new(big.Int)
+	x := new(big.Int)
+	y := new(big.Int)
+	for i := range a {
+		x.SetUint64(a[i])
+		y.SetUint64(b[i])
+		sum.Add(sum, x.Mul(x, y))
+	}
+	sum.Quo(sum, x.SetUint64(denom))
+	if !sum.IsUint64() {
+		return 0, ndauerr.ErrOverflow
+	}
+	return sum.Uint64(), nil
+}
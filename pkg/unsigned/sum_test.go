@@ -0,0 +1,79 @@
+package unsigned
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSumChecked(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []uint64
+		want    uint64
+		wantErr bool
+	}{
+		{"nil", nil, 0, false},
+		{"single", []uint64{7}, 7, false},
+		{"simple", []uint64{1, 2, 3, 4}, 10, false},
+		{"max", []uint64{math.MaxUint64 - 1, 1}, math.MaxUint64, false},
+		{"overflow", []uint64{math.MaxUint64, 1}, 0, true},
+		{"many terms", []uint64{math.MaxUint32, math.MaxUint32, math.MaxUint32}, 3 * math.MaxUint32, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SumChecked(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SumChecked() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("SumChecked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDotProduct(t *testing.T) {
+	type args struct {
+		a     []uint64
+		b     []uint64
+		denom uint64
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    uint64
+		wantErr bool
+	}{
+		{"empty", args{nil, nil, 1}, 0, false},
+		{"simple", args{[]uint64{1, 2, 3}, []uint64{4, 5, 6}, 1}, 32, false},
+		{"with denominator", args{[]uint64{1, 2, 3}, []uint64{4, 5, 6}, 5}, 6, false},
+		{"no intermediate truncation", args{[]uint64{1, 1, 1}, []uint64{1, 1, 1}, 2}, 1, false},
+		{"products beyond uint64 scaled back", args{[]uint64{math.MaxUint64, math.MaxUint64}, []uint64{1000, 1000}, 2000}, math.MaxUint64, false},
+		{"overflow", args{[]uint64{math.MaxUint64}, []uint64{2}, 1}, 0, true},
+		{"length mismatch", args{[]uint64{1, 2}, []uint64{1}, 1}, 0, true},
+		{"divide by zero", args{[]uint64{1}, []uint64{1}, 0}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DotProduct(tt.args.a, tt.args.b, tt.args.denom)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DotProduct() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("DotProduct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package words
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// ndau mnemonics use the BIP-39 wordlist, but differ from standard BIP-39
+// in two ways:
+//
+// - the checksum is the trailing bits of a CRC-8 of the entropy, where
+//   BIP-39 uses the leading bits of its SHA-256
+// - ndau wallets use the entropy itself as the BIP-32 seed, where BIP-39
+//   stretches the mnemonic into a 64-byte seed with PBKDF2
+//
+// The functions here implement standard BIP-39, so that the entropy of an
+// ndau wallet can be carried to and from standards-compliant tools. Note
+// that those tools will derive different keys from the same entropy, because
+// they derive their seed with SeedBIP39.
+
+// bip39Iterations is the number of PBKDF2 rounds of SeedBIP39
+const bip39Iterations = 2048
+
+// bip39Checksum returns the checksum of BIP-39 for some entropy
+func bip39Checksum(entropy []byte) int {
+	sum := sha256.Sum256(entropy)
+	return getRun(0, len(entropy)/4, sum[:])
+}
+
+// FromEntropyBIP39 generates the standard BIP-39 mnemonic corresponding to
+// some entropy, whose length must be that of one of the Strengths.
+func FromEntropyBIP39(lang string, entropy []byte) ([]string, error) {
+	s := Strength(len(entropy) * 8)
+	err := s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	wordlist, ok := wordlists[lang]
+	if !ok {
+		return nil, errors.New("invalid language code")
+	}
+
+	data := make([]byte, len(entropy)+1)
+	copy(data, entropy)
+	setRun(0, s.ChecksumBits(), data[len(entropy):], bip39Checksum(entropy))
+
+	output := make([]string, s.Words())
+	for w := range output {
+		output[w] = wordlist[nthRun(w, 11, data)]
+	}
+	return output, nil
+}
+
+// ToEntropyBIP39 returns the entropy a standard BIP-39 mnemonic corresponds
+// to.
+//
+// It is an error if the checksum is not that of BIP-39: in particular, an
+// ndau mnemonic will almost always be rejected.
+func ToEntropyBIP39(lang string, s []string) ([]byte, error) {
+	strength, err := StrengthOfWords(len(s))
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := decodeWords(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	entropy := data[:strength.Bytes()]
+	if getRun(int(strength), strength.ChecksumBits(), data) != bip39Checksum(entropy) {
+		return nil, fmt.Errorf("BIP-39 checksum failed for %d-word mnemonic", len(s))
+	}
+	return entropy, nil
+}
+
+// SeedBIP39 returns the 64-byte seed which BIP-39 derives from a mnemonic
+// and passphrase, after checking that the mnemonic is valid.
+//
+// BIP-39 requires the passphrase to be in Unicode normalization form NFKD;
+// that is the caller's responsibility. ASCII passphrases are always NFKD.
+func SeedBIP39(lang string, s []string, passphrase string) ([]byte, error) {
+	_, err := ToEntropyBIP39(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	mnemonic := strings.Join(s, " ")
+	return pbkdf2.Key(
+		[]byte(mnemonic), []byte("mnemonic"+passphrase),
+		bip39Iterations, sha512.Size, sha512.New,
+	), nil
+}
+
+// ToBIP39 converts an ndau mnemonic into the standard BIP-39 mnemonic of the
+// same entropy.
+func ToBIP39(lang string, s []string) ([]string, error) {
+	entropy, err := ToEntropy(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	return FromEntropyBIP39(lang, entropy)
+}
+
+// FromBIP39 converts a standard BIP-39 mnemonic into the ndau mnemonic of the
+// same entropy.
+func FromBIP39(lang string, s []string) ([]string, error) {
+	entropy, err := ToEntropyBIP39(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	return FromEntropy(lang, entropy)
+}
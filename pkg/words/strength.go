@@ -0,0 +1,164 @@
+package words
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// Strength is the number of bits of entropy encoded by a mnemonic.
+//
+// As in BIP-39, each strength has a checksum of one bit per 32 bits of
+// entropy, so that entropy and checksum exactly fill a whole number of words.
+type Strength int
+
+// These are the strengths of BIP-39
+const (
+	Strength128 Strength = 128 // 12 words
+	Strength160 Strength = 160 // 15 words
+	Strength192 Strength = 192 // 18 words
+	Strength224 Strength = 224 // 21 words
+	Strength256 Strength = 256 // 24 words
+)
+
+var strengths = []Strength{Strength128, Strength160, Strength192, Strength224, Strength256}
+
+// Strengths returns all valid strengths, in increasing order
+func Strengths() []Strength {
+	return append([]Strength(nil), strengths...)
+}
+
+// Validate returns an error if s is not one of the Strengths
+func (s Strength) Validate() error {
+	for _, v := range strengths {
+		if s == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid strength %d: must be a multiple of 32 from 128 to 256", s)
+}
+
+// Bytes returns the number of bytes of entropy
+func (s Strength) Bytes() int {
+	return int(s) / 8
+}
+
+// ChecksumBits returns the number of bits of checksum
+func (s Strength) ChecksumBits() int {
+	return int(s) / 32
+}
+
+// Words returns the number of words in a mnemonic
+func (s Strength) Words() int {
+	return (int(s) + s.ChecksumBits()) / 11
+}
+
+// StrengthOfWords returns the strength of a mnemonic of n words
+func StrengthOfWords(n int) (Strength, error) {
+	for _, s := range strengths {
+		if s.Words() == n {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid mnemonic length %d: must be a multiple of 3 from 12 to 24 words", n)
+}
+
+// GenerateEntropy returns cryptographically secure random entropy of the
+// given strength
+func GenerateEntropy(s Strength) ([]byte, error) {
+	err := s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, s.Bytes())
+	_, err = rand.Read(entropy)
+	if err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// FromEntropy generates the mnemonic corresponding to some entropy, whose
+// length must be that of one of the Strengths.
+//
+// It is equivalent to FromBytes, but refuses any other length.
+func FromEntropy(lang string, entropy []byte) ([]string, error) {
+	s := Strength(len(entropy) * 8)
+	err := s.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(lang, entropy)
+}
+
+// ToEntropy returns the entropy a mnemonic corresponds to.
+//
+// Unlike ToBytes, which accepts any number of words and tries each possible
+// checksum length, the number of words must be that of one of the Strengths,
+// which determines the checksum length exactly.
+func ToEntropy(lang string, s []string) ([]byte, error) {
+	strength, err := StrengthOfWords(len(s))
+	if err != nil {
+		return nil, err
+	}
+	data, nbits, err := decodeWords(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	if !checksumOk(data, strength.Bytes(), nbits) {
+		return nil, fmt.Errorf("checksum failed for %d-word mnemonic", len(s))
+	}
+	return data[:strength.Bytes()], nil
+}
+
+// ValidatePartial checks the words of a mnemonic as they are entered, so that
+// a mistake can be caught at the word where it was made, rather than when the
+// checksum fails at the end.
+//
+// Every word must be in the wordlist. The checksum is in the last word of a
+// mnemonic, so it can only be checked when the words entered are as many as
+// those of one of the Strengths; if it fails there, the words may yet begin a
+// longer mnemonic.
+//
+// remaining is the least number of words which must still be entered to
+// complete a mnemonic whose checksum is valid; it is 0 if the words are
+// already such a mnemonic, though they may also begin a longer one. It is an
+// error if no mnemonic can begin with the words.
+func ValidatePartial(lang string, s []string) (remaining int, err error) {
+	if _, ok := wordlists[lang]; !ok {
+		return 0, errors.New("invalid language code")
+	}
+	for n, w := range s {
+		if _, err := lookupWord(lang, w); err != nil {
+			return 0, fmt.Errorf("word %d (%q) is not in the wordlist", n+1, w)
+		}
+	}
+	for _, strength := range strengths {
+		switch {
+		case strength.Words() > len(s):
+			return strength.Words() - len(s), nil
+		case strength.Words() == len(s):
+			data, nbits, err := decodeWords(lang, s)
+			if err != nil {
+				return 0, err
+			}
+			if checksumOk(data, strength.Bytes(), nbits) {
+				return 0, nil
+			}
+		}
+	}
+	if len(s) == strengths[len(strengths)-1].Words() {
+		return 0, fmt.Errorf("checksum failed for %d-word mnemonic", len(s))
+	}
+	return 0, fmt.Errorf("too many words: %d", len(s))
+}
@@ -0,0 +1,194 @@
+package words
+
+// ----- ---- --- -- -
+// Copyright 2020 The Axiom Foundation. All Rights Reserved.
+//
+// Licensed under the Apache License 2.0 (the "License").  You may not use
+// this file except in compliance with the License.  You can obtain a copy
+// in the file LICENSE in the source distribution or at
+// https://www.apache.org/licenses/LICENSE-2.0.txt
+// - -- --- ---- -----
+
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStrength(t *testing.T) {
+	tests := []struct {
+		s        Strength
+		bytes    int
+		checksum int
+		words    int
+	}{
+		{Strength128, 16, 4, 12},
+		{Strength160, 20, 5, 15},
+		{Strength192, 24, 6, 18},
+		{Strength224, 28, 7, 21},
+		{Strength256, 32, 8, 24},
+	}
+	if len(tests) != len(Strengths()) {
+		t.Fatalf("expected %d strengths, got %d", len(tests), len(Strengths()))
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d words", tt.words), func(t *testing.T) {
+			if err := tt.s.Validate(); err != nil {
+				t.Errorf("Validate() = %v", err)
+			}
+			if got := tt.s.Bytes(); got != tt.bytes {
+				t.Errorf("Bytes() = %v, want %v", got, tt.bytes)
+			}
+			if got := tt.s.ChecksumBits(); got != tt.checksum {
+				t.Errorf("ChecksumBits() = %v, want %v", got, tt.checksum)
+			}
+			if got := tt.s.Words(); got != tt.words {
+				t.Errorf("Words() = %v, want %v", got, tt.words)
+			}
+			if got, err := StrengthOfWords(tt.words); err != nil || got != tt.s {
+				t.Errorf("StrengthOfWords() = %v, %v, want %v", got, err, tt.s)
+			}
+
+			// the words generated from valid entropy are the same as from
+			// FromBytes, and round-trip
+			entropy, err := GenerateEntropy(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entropy) != tt.bytes {
+				t.Fatalf("GenerateEntropy() produced %d bytes, want %d", len(entropy), tt.bytes)
+			}
+			mnemonic, err := FromEntropy("en", entropy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fromBytes, err := FromBytes("en", entropy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(mnemonic, fromBytes) {
+				t.Errorf("FromEntropy() = %v, FromBytes() = %v", mnemonic, fromBytes)
+			}
+			if len(mnemonic) != tt.words {
+				t.Errorf("FromEntropy() produced %d words, want %d", len(mnemonic), tt.words)
+			}
+			got, err := ToEntropy("en", mnemonic)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, entropy) {
+				t.Errorf("ToEntropy() = %v, want %v", got, entropy)
+			}
+		})
+	}
+}
+
+func TestInvalidStrength(t *testing.T) {
+	for _, s := range []Strength{0, 64, 127, 129, 144, 288} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("Strength(%d).Validate() succeeded", s)
+		}
+		if _, err := GenerateEntropy(s); err == nil {
+			t.Errorf("GenerateEntropy(%d) succeeded", s)
+		}
+	}
+	for _, n := range []int{0, 3, 11, 13, 16, 27} {
+		if _, err := StrengthOfWords(n); err == nil {
+			t.Errorf("StrengthOfWords(%d) succeeded", n)
+		}
+	}
+	// FromBytes accepts any length, but FromEntropy does not
+	if _, err := FromEntropy("en", []byte{0, 1, 2}); err == nil {
+		t.Error("FromEntropy() accepted 3 bytes")
+	}
+	if _, err := FromEntropy("sp", make([]byte, 16)); err == nil {
+		t.Error("FromEntropy() accepted a bad language")
+	}
+}
+
+func TestToEntropy(t *testing.T) {
+	tests := []struct {
+		name    string
+		words   string
+		want    []byte
+		wantErr bool
+	}{
+		{"12 words", "abandon amount liar amount expire adjust cage candy arch gather drum bundle",
+			[]byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}, false},
+		{"18 words", "forum circle differ help use suspect this dune soon seek swamp joy artefact stone hill guide silver addict",
+			[]byte{91, 165, 36, 247, 53, 142, 251, 181, 184, 50, 32, 207, 88, 99, 108, 188, 64, 207, 172, 154, 235, 60, 200, 192},
+			false},
+		// ToBytes accepts this, but it isn't a valid mnemonic length
+		{"3 words", "abandon amount mom", nil, true},
+		// 16 words can't come from a valid strength
+		{"16 words", "clarify say gorilla brass coach capable shock knock tongue width earn negative floor staff elbow aim",
+			nil, true},
+		{"bad checksum", "abandon amount liar amount expire adjust cage candy arch gather drum buyer", nil, true},
+		{"bad word", "abandon amount liar amount expire adjust cage candy arch gather drum foo", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToEntropy("en", strings.Split(tt.words, " "))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ToEntropy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToEntropy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePartial(t *testing.T) {
+	valid12 := strings.Split("abandon amount liar amount expire adjust cage candy arch gather drum bundle", " ")
+	badChecksum12 := append(append([]string{}, valid12[:11]...), "buyer")
+	valid24, err := FromEntropy("en", make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// find a final word whose checksum fails
+	var badChecksum24 []string
+	for _, w := range _english {
+		candidate := append(append([]string{}, valid24[:23]...), w)
+		if _, err := ToEntropy("en", candidate); err != nil {
+			badChecksum24 = candidate
+			break
+		}
+	}
+
+	tests := []struct {
+		name          string
+		words         []string
+		wantRemaining int
+		wantErr       bool
+	}{
+		{"none", nil, 12, false},
+		{"some", valid12[:5], 7, false},
+		{"complete", valid12, 0, false},
+		{"complete 24", valid24, 0, false},
+		// the words may yet begin a 15-word mnemonic
+		{"bad checksum", badChecksum12, 3, false},
+		{"bad checksum on 24", badChecksum24, 0, true},
+		{"bad word", []string{"abandon", "amount", "foo"}, 0, true},
+		{"bad word after 12", append(append([]string{}, valid12...), "foo"), 0, true},
+		{"too many", append(append([]string{}, valid24...), "abandon"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidatePartial("en", tt.words)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePartial() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.wantRemaining {
+				t.Errorf("ValidatePartial() = %d, want %d", got, tt.wantRemaining)
+			}
+		})
+	}
+	if _, err := ValidatePartial("sp", valid12); err == nil {
+		t.Error("ValidatePartial() accepted a bad language")
+	}
+}
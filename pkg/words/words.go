@@ -177,32 +177,45 @@ func lookupWord(lang, s string) (int, error) {
 	}
 }
 
-// ToBytes returns an array of the bytes a list of words corresponds to.
+// decodeWords returns the bits a list of words corresponds to, packed into
+// bytes, and the number of bits.
 // It can error if lookup of any of the words fails.
-func ToBytes(lang string, s []string) ([]byte, error) {
+func decodeWords(lang string, s []string) ([]byte, int, error) {
 	nbits := len(s) * 11
-	nbytes := nbits / 8
-	resultbytes := nbytes
-	if nbytes*8 < nbits {
-		nbytes++
-	}
-	result := make([]byte, nbytes)
+	result := make([]byte, nextMultipleOf(nbits, 8)/8)
 	for n, w := range s {
 		value, err := lookupWord(lang, w)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 		setRun(n, 11, result, value)
 	}
+	return result, nbits, nil
+}
+
+// checksumOk is true if the bits of data following its first nbytes are the
+// trailing bits of the crc of those bytes
+func checksumOk(data []byte, nbytes, nbits int) bool {
+	runlen := nbits - nbytes*8
+	mask := (1 << uint(runlen)) - 1 // generate runlen 1 bits
+	ckcalc := int(crc8(data[:nbytes])) & mask
+	ckfound := getRun(nbytes*8, runlen, data)
+	return ckcalc == ckfound
+}
+
+// ToBytes returns an array of the bytes a list of words corresponds to.
+// It can error if lookup of any of the words fails.
+func ToBytes(lang string, s []string) ([]byte, error) {
+	result, nbits, err := decodeWords(lang, s)
+	if err != nil {
+		return nil, err
+	}
+	resultbytes := nbits / 8
 
 	// if a shorter version (with a longer crc) is possible, test it first
 	// because if this crc is correct it's more likely to be the right answer
 	for _, rb := range []int{resultbytes - 1, resultbytes} {
-		runlen := nbits - rb*8
-		mask := (1 << uint(runlen)) - 1 // generate runlen 1 bits
-		ckcalc := int(crc8(result[:rb])) & mask
-		ckfound := getRun(rb*8, runlen, result)
-		if ckcalc == ckfound {
+		if rb >= 0 && checksumOk(result, rb, nbits) {
 			return result[:rb], nil
 		}
 	}